@@ -0,0 +1,119 @@
+// Package binjson converts documents between JSON and the binary formats
+// msgpack and CBOR, which are able to represent the same structures with a
+// smaller overhead.
+package binjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Formats lists the binary formats supported by this package.
+var Formats = []string{"msgpack", "cbor"}
+
+var (
+	stringMapType = reflect.TypeOf(map[string]interface{}{})
+	cborDecMode   cbor.DecMode
+)
+
+func init() {
+	var err error
+	if cborDecMode, err = (cbor.DecOptions{
+		DefaultMapType: stringMapType,
+	}).DecMode(); err != nil {
+		panic(err)
+	}
+}
+
+// ToJSON converts a document of a binary format into JSON.
+func ToJSON(format string, b []byte) ([]byte, error) {
+	var v interface{}
+	switch format {
+	case "msgpack":
+		if err := msgpack.Unmarshal(b, &v); err != nil {
+			return nil, err
+		}
+	case "cbor":
+		if err := cborDecMode.Unmarshal(b, &v); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("format not recognised: %v", format)
+	}
+	return json.Marshal(v)
+}
+
+// FromJSON converts a JSON document into a binary format. Numbers that are
+// integers are encoded as such rather than as floats.
+func FromJSON(format string, b []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	v = ConvertNumbers(v)
+	switch format {
+	case "msgpack":
+		return msgpack.Marshal(v)
+	case "cbor":
+		return cbor.Marshal(v)
+	}
+	return nil, fmt.Errorf("format not recognised: %v", format)
+}
+
+// ConvertNumbers replaces the json.Number values of a structured document,
+// such as one decoded with UseNumber, with int64 values when they are integers
+// and float64 values otherwise. Maps and slices are modified in place.
+func ConvertNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = ConvertNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = ConvertNumbers(e)
+		}
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		if f, err := t.Float64(); err == nil {
+			return f
+		}
+	}
+	return v
+}
+
+// FormatFromContentType returns the binary format identified by an HTTP
+// Content-Type header, or false if the content type is not of a supported
+// binary format.
+func FormatFromContentType(contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	switch mediaType {
+	case "application/msgpack", "application/x-msgpack", "application/vnd.msgpack":
+		return "msgpack", true
+	case "application/cbor":
+		return "cbor", true
+	}
+	return "", false
+}
+
+// ContentType returns the HTTP Content-Type of a binary format.
+func ContentType(format string) string {
+	if format == "cbor" {
+		return "application/cbor"
+	}
+	return "application/msgpack"
+}
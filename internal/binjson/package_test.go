@@ -0,0 +1,50 @@
+package binjson
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	input := `{"a":[1,2.5,"three",null,true],"b":{"c":-4}}`
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			b, err := FromJSON(format, []byte(input))
+			require.NoError(t, err)
+
+			out, err := ToJSON(format, b)
+			require.NoError(t, err)
+			assert.JSONEq(t, input, string(out))
+		})
+	}
+}
+
+func TestBadFormat(t *testing.T) {
+	_, err := FromJSON("nope", []byte(`{}`))
+	require.Error(t, err)
+
+	_, err = ToJSON("nope", []byte(`{}`))
+	require.Error(t, err)
+
+	_, err = FromJSON("msgpack", []byte(`not json`))
+	require.Error(t, err)
+}
+
+func TestFormatFromContentType(t *testing.T) {
+	tests := map[string]string{
+		"application/msgpack":              "msgpack",
+		"application/x-msgpack":            "msgpack",
+		"application/vnd.msgpack":          "msgpack",
+		"application/cbor":                 "cbor",
+		"application/cbor; charset=binary": "cbor",
+		"application/json":                 "",
+		"":                                 "",
+	}
+	for ct, exp := range tests {
+		format, ok := FormatFromContentType(ct)
+		assert.Equal(t, exp != "", ok, ct)
+		assert.Equal(t, exp, format, ct)
+	}
+}
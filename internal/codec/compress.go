@@ -0,0 +1,48 @@
+package codec
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressionAlgorithms lists the streamed compression algorithms that can be
+// applied by output components.
+var CompressionAlgorithms = []string{"gzip", "snappy", "zstd"}
+
+// CompressWriter is an io.WriteCloser that compresses data written to it. Calls
+// to Flush write all pending compressed data to the underlying writer, and
+// Close flushes and terminates the compressed stream without closing the
+// underlying writer.
+type CompressWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// NewCompressWriter creates a compressing writer for a given algorithm that
+// writes compressed data to w.
+func NewCompressWriter(algorithm string, w io.Writer) (CompressWriter, error) {
+	switch algorithm {
+	case "gzip":
+		return gzip.NewWriter(w), nil
+	case "snappy":
+		return snappy.NewBufferedWriter(w), nil
+	case "zstd":
+		return zstd.NewWriter(w)
+	}
+	return nil, fmt.Errorf("compression algorithm not recognised: %v", algorithm)
+}
+
+// IsCompressionAlgorithm returns true if the provided string is a supported
+// compression algorithm.
+func IsCompressionAlgorithm(algorithm string) bool {
+	for _, a := range CompressionAlgorithms {
+		if a == algorithm {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package codec
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/types"
+)
+
+// parseLengthPrefixSize returns the number of bytes of the length prefix of a
+// length-prefixed:x codec.
+func parseLengthPrefixSize(codec string) (int, error) {
+	size, err := strconv.Atoi(strings.TrimPrefix(codec, "length-prefixed:"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid prefix size for length-prefixed codec: %w", err)
+	}
+	switch size {
+	case 1, 2, 4, 8:
+		return size, nil
+	}
+	return 0, fmt.Errorf("invalid prefix size for length-prefixed codec, expected 1, 2, 4 or 8: %v", size)
+}
+
+func readLengthPrefix(b []byte) uint64 {
+	switch len(b) {
+	case 1:
+		return uint64(b[0])
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b))
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b))
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+func writeLengthPrefix(b []byte, l uint64) {
+	switch len(b) {
+	case 1:
+		b[0] = byte(l)
+	case 2:
+		binary.BigEndian.PutUint16(b, uint16(l))
+	case 4:
+		binary.BigEndian.PutUint32(b, uint32(l))
+	default:
+		binary.BigEndian.PutUint64(b, l)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type lengthPrefixedReader struct {
+	buf       *bufio.Reader
+	prefix    []byte
+	maxSize   uint64
+	r         io.ReadCloser
+	sourceAck ReaderAckFn
+
+	mut      sync.Mutex
+	finished bool
+	pending  int32
+}
+
+func newLengthPrefixedReader(conf ReaderConfig, r io.ReadCloser, prefixSize int, ackFn ReaderAckFn) (Reader, error) {
+	return &lengthPrefixedReader{
+		buf:       bufio.NewReader(r),
+		prefix:    make([]byte, prefixSize),
+		maxSize:   uint64(conf.MaxScanTokenSize),
+		r:         r,
+		sourceAck: ackOnce(ackFn),
+	}, nil
+}
+
+func (a *lengthPrefixedReader) ack(ctx context.Context, err error) error {
+	a.mut.Lock()
+	a.pending--
+	doAck := a.pending == 0 && a.finished
+	a.mut.Unlock()
+
+	if err != nil {
+		return a.sourceAck(ctx, err)
+	}
+	if doAck {
+		return a.sourceAck(ctx, nil)
+	}
+	return nil
+}
+
+func (a *lengthPrefixedReader) Next(ctx context.Context) ([]types.Part, ReaderAckFn, error) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if a.finished {
+		return nil, nil, io.EOF
+	}
+
+	_, err := io.ReadFull(a.buf, a.prefix)
+	if err == nil {
+		size := readLengthPrefix(a.prefix)
+		if size > a.maxSize {
+			err = fmt.Errorf("message size %v exceeds the maximum of %v", size, a.maxSize)
+		} else {
+			data := make([]byte, size)
+			if _, err = io.ReadFull(a.buf, data); err == nil {
+				a.pending++
+				return []types.Part{message.NewPart(data)}, a.ack, nil
+			}
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+		}
+	}
+
+	if err == io.EOF {
+		a.finished = true
+	} else {
+		_ = a.sourceAck(ctx, err)
+	}
+	return nil, nil, err
+}
+
+func (a *lengthPrefixedReader) Close(ctx context.Context) error {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if !a.finished {
+		_ = a.sourceAck(ctx, errors.New("service shutting down"))
+	}
+	if a.pending == 0 {
+		_ = a.sourceAck(ctx, nil)
+	}
+	return a.r.Close()
+}
+
+//------------------------------------------------------------------------------
+
+var lengthPrefixedWriterConfig = WriterConfig{
+	Append: true,
+}
+
+type lengthPrefixedWriter struct {
+	w          io.WriteCloser
+	prefixSize int
+	maxSize    uint64
+}
+
+func newLengthPrefixedWriter(w io.WriteCloser, prefixSize int) (Writer, error) {
+	maxSize := uint64(1)<<(uint(prefixSize)*8) - 1
+	if prefixSize == 8 {
+		maxSize = ^uint64(0)
+	}
+	return &lengthPrefixedWriter{
+		w:          w,
+		prefixSize: prefixSize,
+		maxSize:    maxSize,
+	}, nil
+}
+
+func (l *lengthPrefixedWriter) Write(ctx context.Context, p types.Part) error {
+	partBytes := p.Get()
+	if uint64(len(partBytes)) > l.maxSize {
+		return fmt.Errorf("message size %v exceeds the maximum of %v for a %v byte length prefix", len(partBytes), l.maxSize, l.prefixSize)
+	}
+	// The prefix and the message are written together so that packet based
+	// connections receive each frame within a single packet.
+	frame := make([]byte, l.prefixSize+len(partBytes))
+	writeLengthPrefix(frame[:l.prefixSize], uint64(len(partBytes)))
+	copy(frame[l.prefixSize:], partBytes)
+	_, err := l.w.Write(frame)
+	return err
+}
+
+func (l *lengthPrefixedWriter) EndBatch() error {
+	return nil
+}
+
+func (l *lengthPrefixedWriter) Close(ctx context.Context) error {
+	return l.w.Close()
+}
@@ -18,6 +18,7 @@ import (
 
 	"github.com/Jeffail/benthos/v3/internal/docs"
 	"github.com/Jeffail/benthos/v3/lib/message"
+	mio "github.com/Jeffail/benthos/v3/lib/message/io"
 	"github.com/Jeffail/benthos/v3/lib/types"
 )
 
@@ -27,10 +28,12 @@ var ReaderDocs = docs.FieldCommon(
 ).HasAnnotatedOptions(
 	"auto", "EXPERIMENTAL: Attempts to derive a codec for each file based on information such as the extension. For example, a .tar.gz file would be consumed with the `gzip/tar` codec. Defaults to all-bytes.",
 	"all-bytes", "Consume the entire file as a single binary message.",
+	"benthos-wire", "Consume a stream of messages written by another Benthos instance with the `benthos-wire` codec, where the parts and metadata of each message are preserved.",
 	"chunker:x", "Consume the file in chunks of a given number of bytes.",
 	"csv", "Consume structured rows as comma separated values, the first row must be a header row.",
 	"delim:x", "Consume the file in segments divided by a custom delimiter.",
 	"gzip", "Decompress a gzip file, this codec should precede another codec, e.g. `gzip/all-bytes`, `gzip/tar`, `gzip/csv`, etc.",
+	"length-prefixed:x", "Consume messages that are each preceded by their length in bytes as a big endian unsigned integer of x bytes, where x is 1, 2, 4 or 8.",
 	"lines", "Consume the file in segments divided by linebreaks.",
 	"multipart", "Consumes the output of another codec and batches messages together. A batch ends when an empty message is consumed. For example, the codec `lines/multipart` could be used to consume multipart messages where an empty line indicates the end of each batch.",
 	"tar", "Parse the file as a tar archive, and consume each file of the archive as a message.",
@@ -217,6 +220,10 @@ func partReader(codec string, conf ReaderConfig) (ReaderConstructor, bool, error
 		}, true, nil
 	case "tar":
 		return newTarReader, true, nil
+	case "benthos-wire":
+		return func(path string, r io.ReadCloser, fn ReaderAckFn) (Reader, error) {
+			return newWireReader(r, fn)
+		}, true, nil
 	}
 	if strings.HasPrefix(codec, "delim:") {
 		by := strings.TrimPrefix(codec, "delim:")
@@ -227,6 +234,15 @@ func partReader(codec string, conf ReaderConfig) (ReaderConstructor, bool, error
 			return newCustomDelimReader(conf, r, by, fn)
 		}, true, nil
 	}
+	if strings.HasPrefix(codec, "length-prefixed:") {
+		prefixSize, err := parseLengthPrefixSize(codec)
+		if err != nil {
+			return nil, false, err
+		}
+		return func(path string, r io.ReadCloser, fn ReaderAckFn) (Reader, error) {
+			return newLengthPrefixedReader(conf, r, prefixSize, fn)
+		}, true, nil
+	}
 	if strings.HasPrefix(codec, "chunker:") {
 		chunkSize, err := strconv.ParseUint(strings.TrimPrefix(codec, "chunker:"), 10, 64)
 		if err != nil {
@@ -729,6 +745,86 @@ func (a *tarReader) Close(ctx context.Context) error {
 
 //------------------------------------------------------------------------------
 
+type wireReader struct {
+	buf       *bufio.Reader
+	r         io.ReadCloser
+	sourceAck ReaderAckFn
+
+	mut      sync.Mutex
+	finished bool
+	pending  int32
+}
+
+func newWireReader(r io.ReadCloser, ackFn ReaderAckFn) (Reader, error) {
+	return &wireReader{
+		buf:       bufio.NewReader(r),
+		r:         r,
+		sourceAck: ackOnce(ackFn),
+	}, nil
+}
+
+func (a *wireReader) ack(ctx context.Context, err error) error {
+	a.mut.Lock()
+	a.pending--
+	doAck := a.pending == 0 && a.finished
+	a.mut.Unlock()
+
+	if err != nil {
+		return a.sourceAck(ctx, err)
+	}
+	if doAck {
+		return a.sourceAck(ctx, nil)
+	}
+	return nil
+}
+
+func (a *wireReader) Next(ctx context.Context) ([]types.Part, ReaderAckFn, error) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	// Streams begin with a header, and appended streams (such as files written
+	// by several writers) may contain further headers between frames.
+	var err error
+	if mio.PeekWireHeader(a.buf) {
+		_, err = mio.ReadWireHeader(a.buf)
+	}
+
+	var msg types.Message
+	if err == nil {
+		if msg, err = mio.ReadWireFrame(a.buf); err == nil {
+			a.pending++
+			parts := make([]types.Part, 0, msg.Len())
+			_ = msg.Iter(func(i int, p types.Part) error {
+				parts = append(parts, p)
+				return nil
+			})
+			return parts, a.ack, nil
+		}
+	}
+
+	if err == io.EOF {
+		a.finished = true
+	} else {
+		_ = a.sourceAck(ctx, err)
+	}
+	return nil, nil, err
+}
+
+func (a *wireReader) Close(ctx context.Context) error {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if !a.finished {
+		_ = a.sourceAck(ctx, errors.New("service shutting down"))
+	}
+	if a.pending == 0 {
+		_ = a.sourceAck(ctx, nil)
+	}
+	return a.r.Close()
+}
+
+//------------------------------------------------------------------------------
+
 type multipartReader struct {
 	child Reader
 }
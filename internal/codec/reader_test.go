@@ -354,6 +354,45 @@ func TestChunkerReader(t *testing.T) {
 	testReaderSuite(t, "chunker:1", "", data)
 }
 
+func TestLengthPrefixedReader(t *testing.T) {
+	data := []byte("\x00\x03foo\x00\x00\x00\x03baz")
+	testReaderSuite(t, "length-prefixed:2", "", data, "foo", "", "baz")
+
+	data = []byte("\x00\x00\x00\x03foo\x00\x00\x00\x03bar")
+	testReaderSuite(t, "length-prefixed:4", "", data, "foo", "bar")
+
+	data = []byte("")
+	testReaderSuite(t, "length-prefixed:1", "", data)
+}
+
+func TestLengthPrefixedReaderErrors(t *testing.T) {
+	for _, codec := range []string{"length-prefixed:", "length-prefixed:3", "length-prefixed:nope"} {
+		_, err := GetReader(codec, NewReaderConfig())
+		assert.Error(t, err, codec)
+	}
+
+	conf := NewReaderConfig()
+	conf.MaxScanTokenSize = 4
+	for input, exp := range map[string]string{
+		"\x05hello": "message size 5 exceeds the maximum of 4",
+		"\x03fo":    "unexpected EOF",
+	} {
+		ctor, err := GetReader("length-prefixed:1", conf)
+		require.NoError(t, err)
+
+		var ackErr error
+		r, err := ctor("", &noopCloser{bytes.NewReader([]byte(input)), false}, func(ctx context.Context, err error) error {
+			ackErr = err
+			return nil
+		})
+		require.NoError(t, err)
+
+		_, _, err = r.Next(context.Background())
+		assert.EqualError(t, err, exp)
+		assert.EqualError(t, ackErr, exp)
+	}
+}
+
 func TestTarReader(t *testing.T) {
 	input := []string{
 		"first document",
@@ -9,17 +9,24 @@ import (
 	"strings"
 
 	"github.com/Jeffail/benthos/v3/internal/docs"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	mio "github.com/Jeffail/benthos/v3/lib/message/io"
 	"github.com/Jeffail/benthos/v3/lib/types"
 )
 
 // WriterDocs is a static field documentation for output codecs.
 var WriterDocs = docs.FieldCommon(
-	"codec", "The way in which the bytes of messages should be written out into the output data stream. It's possible to write lines using a custom delimiter with the `delim:x` codec, where x is the character sequence custom delimiter.", "lines", "delim:\t", "delim:foobar",
+	"codec", "The way in which the bytes of messages should be written out into the output data stream. It's possible to write lines using a custom delimiter with the `delim:x` codec, where x is the character sequence custom delimiter. Output can be compressed by prefixing a codec with a compression algorithm, for example the codec `gzip/lines` writes gzip compressed lines.", "lines", "delim:\t", "delim:foobar", "gzip/lines",
 ).HasAnnotatedOptions(
 	"all-bytes", "Only applicable to file based outputs. Writes each message to a file in full, if the file already exists the old content is deleted.",
 	"append", "Append each message to the output stream without any delimiter or special encoding.",
+	"benthos-wire", "Write messages in a versioned binary format that preserves the parts and metadata of each message, for consumption by another Benthos instance with the `benthos-wire` codec.",
+	"length-prefixed:x", "Write each message preceded by its length in bytes as a big endian unsigned integer of x bytes, where x is 1, 2, 4 or 8.",
 	"lines", "Append each message to the output stream followed by a line break.",
 	"delim:x", "Append each message to the output stream followed by a custom delimiter.",
+	"gzip", "Compress the output stream with gzip, this codec should precede another codec, e.g. `gzip/lines`, `gzip/all-bytes`, etc.",
+	"snappy", "Compress the output stream with the snappy framing format, this codec should precede another codec, e.g. `snappy/lines`.",
+	"zstd", "Compress the output stream with zstd, this codec should precede another codec, e.g. `zstd/lines`.",
 )
 
 //------------------------------------------------------------------------------
@@ -34,6 +41,13 @@ type Writer interface {
 	EndBatch() error
 }
 
+// MessageWriter is implemented by writer codecs that are able to write entire
+// messages in one go, preserving the grouping of parts, which outputs should
+// prefer over writing each part individually.
+type MessageWriter interface {
+	WriteMessage(context.Context, types.Message) error
+}
+
 // WriterConfig contains custom configuration specific to a codec describing how
 // handles should be provided.
 type WriterConfig struct {
@@ -47,6 +61,16 @@ type WriterConstructor func(io.WriteCloser) (Writer, error)
 
 // GetWriter returns a constructor that creates write codecs.
 func GetWriter(codec string) (WriterConstructor, WriterConfig, error) {
+	if i := strings.Index(codec, "/"); i > 0 && IsCompressionAlgorithm(codec[:i]) {
+		algorithm := codec[:i]
+		ctor, conf, err := GetWriter(codec[i+1:])
+		if err != nil {
+			return nil, WriterConfig{}, err
+		}
+		return func(w io.WriteCloser) (Writer, error) {
+			return newCompressedWriter(algorithm, w, ctor)
+		}, conf, nil
+	}
 	switch codec {
 	case "all-bytes":
 		return func(w io.WriteCloser) (Writer, error) {
@@ -58,6 +82,8 @@ func GetWriter(codec string) (WriterConstructor, WriterConfig, error) {
 		}, customDelimConfig, nil
 	case "lines":
 		return newLinesWriter, linesWriterConfig, nil
+	case "benthos-wire":
+		return newWireWriter, wireWriterConfig, nil
 	}
 	if strings.HasPrefix(codec, "delim:") {
 		by := strings.TrimPrefix(codec, "delim:")
@@ -68,6 +94,15 @@ func GetWriter(codec string) (WriterConstructor, WriterConfig, error) {
 			return newCustomDelimWriter(w, by)
 		}, customDelimConfig, nil
 	}
+	if strings.HasPrefix(codec, "length-prefixed:") {
+		prefixSize, err := parseLengthPrefixSize(codec)
+		if err != nil {
+			return nil, WriterConfig{}, err
+		}
+		return func(w io.WriteCloser) (Writer, error) {
+			return newLengthPrefixedWriter(w, prefixSize)
+		}, lengthPrefixedWriterConfig, nil
+	}
 	return nil, WriterConfig{}, fmt.Errorf("codec was not recognised: %v", codec)
 }
 
@@ -166,3 +201,113 @@ func (d *customDelimWriter) EndBatch() error {
 func (d *customDelimWriter) Close(ctx context.Context) error {
 	return d.w.Close()
 }
+
+//------------------------------------------------------------------------------
+
+var wireWriterConfig = WriterConfig{
+	Append: true,
+}
+
+type wireWriter struct {
+	w             io.WriteCloser
+	headerWritten bool
+}
+
+func newWireWriter(w io.WriteCloser) (Writer, error) {
+	return &wireWriter{w: w}, nil
+}
+
+func (w *wireWriter) WriteMessage(ctx context.Context, msg types.Message) error {
+	if !w.headerWritten {
+		if err := mio.WriteWireHeader(w.w); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+	return mio.WriteWireFrame(w.w, msg, mio.WireVersion)
+}
+
+func (w *wireWriter) Write(ctx context.Context, p types.Part) error {
+	msg := message.New(nil)
+	msg.Append(p)
+	return w.WriteMessage(ctx, msg)
+}
+
+func (w *wireWriter) EndBatch() error {
+	return nil
+}
+
+func (w *wireWriter) Close(ctx context.Context) error {
+	return w.w.Close()
+}
+
+//------------------------------------------------------------------------------
+
+type compressedWriteCloser struct {
+	CompressWriter
+	underlying io.WriteCloser
+}
+
+func (c *compressedWriteCloser) Close() error {
+	err := c.CompressWriter.Close()
+	if cerr := c.underlying.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// compressedWriter wraps a codec such that the bytes it writes are compressed.
+// The compressed stream is flushed after each write, since outputs acknowledge
+// messages once they have been written, and when the writer is closed.
+type compressedWriter struct {
+	w     Writer
+	flush func() error
+}
+
+// compressedMessageWriter is a compressedWriter for codecs that are able to
+// write entire messages in one go.
+type compressedMessageWriter struct {
+	*compressedWriter
+	mw MessageWriter
+}
+
+func newCompressedWriter(algorithm string, w io.WriteCloser, ctor WriterConstructor) (Writer, error) {
+	cw, err := NewCompressWriter(algorithm, w)
+	if err != nil {
+		return nil, err
+	}
+	inner, err := ctor(&compressedWriteCloser{CompressWriter: cw, underlying: w})
+	if err != nil {
+		return nil, err
+	}
+	c := &compressedWriter{w: inner, flush: cw.Flush}
+	if mw, ok := inner.(MessageWriter); ok {
+		return &compressedMessageWriter{compressedWriter: c, mw: mw}, nil
+	}
+	return c, nil
+}
+
+func (c *compressedWriter) Write(ctx context.Context, p types.Part) error {
+	if err := c.w.Write(ctx, p); err != nil {
+		return err
+	}
+	return c.flush()
+}
+
+func (c *compressedWriter) EndBatch() error {
+	if err := c.w.EndBatch(); err != nil {
+		return err
+	}
+	return c.flush()
+}
+
+func (c *compressedMessageWriter) WriteMessage(ctx context.Context, msg types.Message) error {
+	if err := c.mw.WriteMessage(ctx, msg); err != nil {
+		return err
+	}
+	return c.flush()
+}
+
+func (c *compressedWriter) Close(ctx context.Context) error {
+	return c.w.Close(ctx)
+}
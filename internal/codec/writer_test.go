@@ -0,0 +1,232 @@
+package codec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bufferCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *bufferCloser) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestCompressedWriters(t *testing.T) {
+	decompressors := map[string]func(b []byte) ([]byte, error){
+		"gzip": func(b []byte) ([]byte, error) {
+			r, err := gzip.NewReader(bytes.NewReader(b))
+			if err != nil {
+				return nil, err
+			}
+			return ioutil.ReadAll(r)
+		},
+		"snappy": func(b []byte) ([]byte, error) {
+			return ioutil.ReadAll(snappy.NewReader(bytes.NewReader(b)))
+		},
+		"zstd": func(b []byte) ([]byte, error) {
+			r, err := zstd.NewReader(bytes.NewReader(b))
+			if err != nil {
+				return nil, err
+			}
+			defer r.Close()
+			return ioutil.ReadAll(r)
+		},
+	}
+
+	for algo, decompress := range decompressors {
+		algo, decompress := algo, decompress
+		t.Run(algo, func(t *testing.T) {
+			ctor, conf, err := GetWriter(algo + "/lines")
+			require.NoError(t, err)
+			assert.True(t, conf.Append)
+
+			buf := &bufferCloser{}
+			w, err := ctor(buf)
+			require.NoError(t, err)
+
+			// Each write must be flushed before the writer is closed
+			require.NoError(t, w.Write(context.Background(), message.NewPart([]byte("foo"))))
+			res, err := decompressPartial(algo, buf.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, "foo\n", string(res))
+
+			require.NoError(t, w.Write(context.Background(), message.NewPart([]byte("bar"))))
+			flushedBefore := buf.Len()
+
+			// The end of a batch must be flushed before the writer is closed
+			require.NoError(t, w.EndBatch())
+			assert.Greater(t, buf.Len(), flushedBefore)
+
+			require.NoError(t, w.Write(context.Background(), message.NewPart([]byte("baz"))))
+			require.NoError(t, w.Close(context.Background()))
+			assert.True(t, buf.closed)
+
+			res, err = decompress(buf.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, "foo\nbar\n\nbaz\n", string(res))
+		})
+	}
+}
+
+// decompressPartial reads the decompressed data of a stream that has been
+// flushed but not yet terminated.
+func decompressPartial(algo string, b []byte) ([]byte, error) {
+	var r io.Reader
+	switch algo {
+	case "gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		r = zr
+	case "snappy":
+		r = snappy.NewReader(bytes.NewReader(b))
+	case "zstd":
+		zr, err := zstd.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	}
+	var out bytes.Buffer
+	_, err := io.Copy(&out, r)
+	if err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return out.Bytes(), err
+}
+
+func TestCompressedMessageWriter(t *testing.T) {
+	ctor, _, err := GetWriter("gzip/benthos-wire")
+	require.NoError(t, err)
+
+	buf := &bufferCloser{}
+	w, err := ctor(buf)
+	require.NoError(t, err)
+
+	mw, ok := w.(MessageWriter)
+	require.True(t, ok)
+	require.NoError(t, mw.WriteMessage(context.Background(), message.New([][]byte{[]byte("foo"), []byte("bar")})))
+	require.NoError(t, w.Close(context.Background()))
+
+	zr, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	rCtor, err := GetReader("benthos-wire", NewReaderConfig())
+	require.NoError(t, err)
+	r, err := rCtor("", ioutil.NopCloser(zr), func(ctx context.Context, err error) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	parts, _, err := r.Next(context.Background())
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+	assert.Equal(t, "foo", string(parts[0].Get()))
+	assert.Equal(t, "bar", string(parts[1].Get()))
+
+	// Lines are written part by part and therefore aren't a message writer.
+	ctor, _, err = GetWriter("gzip/lines")
+	require.NoError(t, err)
+	w, err = ctor(&bufferCloser{})
+	require.NoError(t, err)
+	_, ok = w.(MessageWriter)
+	assert.False(t, ok)
+}
+
+func TestCompressedWriterBadCodec(t *testing.T) {
+	_, _, err := GetWriter("gzip/nope")
+	require.Error(t, err)
+
+	_, _, err = GetWriter("nope/lines")
+	require.Error(t, err)
+}
+
+func TestWireWriterReader(t *testing.T) {
+	ctor, _, err := GetWriter("benthos-wire")
+	require.NoError(t, err)
+
+	buf := &bufferCloser{}
+	w, err := ctor(buf)
+	require.NoError(t, err)
+
+	mw, ok := w.(MessageWriter)
+	require.True(t, ok)
+
+	msg := message.New([][]byte{[]byte("foo"), []byte("bar")})
+	msg.Get(1).Metadata().Set("baz", "buz")
+	require.NoError(t, mw.WriteMessage(context.Background(), msg))
+	require.NoError(t, w.Write(context.Background(), message.NewPart([]byte("qux"))))
+	require.NoError(t, w.Close(context.Background()))
+
+	// Appending a second stream must not prevent the first from being read.
+	w, err = ctor(buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(context.Background(), message.NewPart([]byte("quz"))))
+
+	rCtor, err := GetReader("benthos-wire", NewReaderConfig())
+	require.NoError(t, err)
+
+	acked := false
+	r, err := rCtor("", ioutil.NopCloser(bytes.NewReader(buf.Bytes())), func(ctx context.Context, err error) error {
+		assert.NoError(t, err)
+		acked = true
+		return nil
+	})
+	require.NoError(t, err)
+
+	var results [][]string
+	for {
+		parts, ackFn, err := r.Next(context.Background())
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		var strs []string
+		for _, p := range parts {
+			strs = append(strs, string(p.Get())+":"+p.Metadata().Get("baz"))
+		}
+		results = append(results, strs)
+		require.NoError(t, ackFn(context.Background(), nil))
+	}
+	assert.Equal(t, [][]string{{"foo:", "bar:buz"}, {"qux:"}, {"quz:"}}, results)
+
+	require.NoError(t, r.Close(context.Background()))
+	assert.True(t, acked)
+}
+
+func TestLengthPrefixedWriter(t *testing.T) {
+	ctor, _, err := GetWriter("length-prefixed:2")
+	require.NoError(t, err)
+
+	buf := &bufferCloser{}
+	w, err := ctor(buf)
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write(context.Background(), message.NewPart([]byte("foo"))))
+	require.NoError(t, w.Write(context.Background(), message.NewPart(nil)))
+	require.NoError(t, w.Write(context.Background(), message.NewPart([]byte("bar"))))
+	require.NoError(t, w.EndBatch())
+	assert.Equal(t, "\x00\x03foo\x00\x00\x00\x03bar", buf.String())
+
+	assert.Error(t, w.Write(context.Background(), message.NewPart(make([]byte, 65536))))
+	require.NoError(t, w.Close(context.Background()))
+
+	_, _, err = GetWriter("length-prefixed:5")
+	assert.Error(t, err)
+}
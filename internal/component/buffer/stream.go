@@ -9,6 +9,7 @@ import (
 	"github.com/Jeffail/benthos/v3/internal/shutdown"
 	"github.com/Jeffail/benthos/v3/lib/buffer"
 	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
 	"github.com/Jeffail/benthos/v3/lib/message/tracing"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/response"
@@ -184,6 +185,7 @@ func (m *Stream) outputLoop() {
 
 		// It's possible that the buffer wiped our previous root span.
 		tracing.InitSpans(m.typeStr, msg)
+		message.StampShifted(msg, time.Now())
 
 		mReadCount.Incr(1)
 		m.errThrottle.Reset()
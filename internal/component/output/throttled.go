@@ -0,0 +1,71 @@
+package output
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrThrottled wraps an error returned by an output when the sink indicated
+// that it is throttling writes, optionally along with a period of time that the
+// sink asked to wait before retrying.
+type ErrThrottled struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+// Error returns the Error string.
+func (e ErrThrottled) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e ErrThrottled) Unwrap() error {
+	return e.Err
+}
+
+// IsThrottled returns whether an error returned by an output indicates that
+// the sink is throttling writes, along with the period of time that the sink
+// asked to wait before retrying, which is zero when unknown.
+func IsThrottled(err error) (time.Duration, bool) {
+	var tErr ErrThrottled
+	if errors.As(err, &tErr) {
+		return tErr.RetryAfter, true
+	}
+	return 0, false
+}
+
+// ThrottleReporter is implemented by outputs that receive throttling signals
+// from the sink alongside writes that succeed, such as the throttle time of a
+// Kafka produce response, and therefore cannot signal them with an error. The
+// func must be registered before the output connects.
+type ThrottleReporter interface {
+	OnThrottle(fn func(retryAfter time.Duration))
+}
+
+// IsThrottledHTTPStatus returns whether an HTTP response status code indicates
+// that a server is throttling requests.
+func IsThrottledHTTPStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable
+}
+
+// ParseRetryAfter parses the value of a Retry-After HTTP header, which is
+// either a number of seconds or a date, and returns zero if it is invalid.
+func ParseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsThrottled(t *testing.T) {
+	_, ok := IsThrottled(errors.New("nope"))
+	assert.False(t, ok)
+
+	_, ok = IsThrottled(nil)
+	assert.False(t, ok)
+
+	err := fmt.Errorf("wrapped: %w", ErrThrottled{
+		Err:        errors.New("too many requests"),
+		RetryAfter: time.Second,
+	})
+	d, ok := IsThrottled(err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, d)
+	assert.Equal(t, "wrapped: too many requests", err.Error())
+}
+
+func TestIsThrottledHTTPStatus(t *testing.T) {
+	assert.True(t, IsThrottledHTTPStatus(http.StatusTooManyRequests))
+	assert.True(t, IsThrottledHTTPStatus(http.StatusServiceUnavailable))
+	assert.False(t, IsThrottledHTTPStatus(http.StatusInternalServerError))
+	assert.False(t, IsThrottledHTTPStatus(http.StatusOK))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), ParseRetryAfter(""))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter("nope"))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter("-5"))
+	assert.Equal(t, time.Second*120, ParseRetryAfter("120"))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter("Wed, 21 Oct 2015 07:28:00 GMT"))
+
+	d := ParseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, d > time.Second*50 && d <= time.Minute, d)
+}
@@ -56,6 +56,11 @@ func TestSetOverrideErrors(t *testing.T) {
 			input: "",
 			err:   "invalid set expression ''",
 		},
+		{
+			name:  "bad array",
+			input: "input.kafka.addresses=[ foo",
+			err:   "failed to parse array value",
+		},
 		{
 			name:  "cant set that",
 			input: "input=meow",
@@ -93,6 +98,7 @@ input:
 		"input.kafka.addresses.0=nope1.com",
 		"input.kafka.addresses.1=nope2.com",
 		"input.kafka.topics=justthis",
+		"input.kafka.checkpoint_limit=100",
 		"output.type=kafka",
 		"output.kafka.addresses=nope3.com",
 		"output.kafka.topic=foobar",
@@ -105,12 +111,37 @@ input:
 	assert.Equal(t, "kafka", conf.Input.Type)
 	assert.Equal(t, []string{"nope1.com", "nope2.com"}, conf.Input.Kafka.Addresses)
 	assert.Equal(t, []string{"justthis"}, conf.Input.Kafka.Topics)
+	assert.Equal(t, 100, conf.Input.Kafka.CheckpointLimit)
 
 	assert.Equal(t, "kafka", conf.Output.Type)
 	assert.Equal(t, []string{"nope3.com"}, conf.Output.Kafka.Addresses)
 	assert.Equal(t, "foobar", conf.Output.Kafka.Topic)
 }
 
+func TestSetOverridesOfArrays(t *testing.T) {
+	conf := config.New()
+	rdr := iconfig.NewReader("", nil, iconfig.OptAddOverrides(
+		"input.type=kafka",
+		"input.kafka.addresses=[ nope1.com, nope2.com ]",
+		"input.kafka.topics=[ '[a-z]+' ]",
+		"pipeline.processors.-.bloblang=[ this.a ]",
+		"output.type=switch",
+		"output.switch.cases.-.check=[this.a]",
+	))
+
+	_, err := rdr.Read(&conf)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"nope1.com", "nope2.com"}, conf.Input.Kafka.Addresses)
+	assert.Equal(t, []string{"[a-z]+"}, conf.Input.Kafka.Topics)
+
+	// Values of fields that aren't arrays are never parsed as arrays.
+	require.Len(t, conf.Pipeline.Processors, 1)
+	assert.Equal(t, "[ this.a ]", string(conf.Pipeline.Processors[0].Bloblang))
+	require.Len(t, conf.Output.Switch.Cases, 1)
+	assert.Equal(t, "[this.a]", conf.Output.Switch.Cases[0].Check)
+}
+
 func TestResources(t *testing.T) {
 	dir, err := os.MkdirTemp("", "test_resources")
 	require.NoError(t, err)
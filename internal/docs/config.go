@@ -61,6 +61,28 @@ var labelField = FieldString(
 	return nil
 })
 
+var partsField = FieldAdvanced(
+	"parts", "Overrides how the parts of a message (a batch) are mapped to the payloads written by the output. The mapping is applied after any processors of the output.",
+).WithChildren(
+	FieldString(
+		"mode", "How the parts of a message are mapped to payloads. When empty the output writes messages in its own way.",
+	).HasAnnotatedOptions(
+		"concatenate", "Join the contents of the parts into a single payload, separated by the `delimiter`.",
+		"index", "Write only the part at the position `index`. Messages without a part at that position are dropped.",
+		"each", "Write each part as a separate payload.",
+		"json_array", "Parse each part as a JSON document and write them as a single JSON array. If any part fails to parse it is flagged as having failed and the message is written without mapping.",
+	).HasDefault(""),
+	FieldString("delimiter", "The delimiter placed between parts in the `concatenate` mode.").HasDefault("\n"),
+	FieldInt("index", "The position of the part to write in the `index` mode, where a negative index counts backwards from the end of the message.").HasDefault(0),
+).OmitWhen(func(field, _ interface{}) (string, bool) {
+	if obj, ok := field.(map[string]interface{}); ok {
+		if mode, _ := obj["mode"].(string); mode == "" {
+			return "field parts has no mode and can be removed", true
+		}
+	}
+	return "", false
+}).AtVersion("3.54.0")
+
 func reservedFieldsByType(t Type) map[string]FieldSpec {
 	m := map[string]FieldSpec{
 		"type":   FieldString("type", ""),
@@ -74,6 +96,9 @@ func reservedFieldsByType(t Type) map[string]FieldSpec {
 			return "", false
 		})
 	}
+	if t == TypeOutput {
+		m["parts"] = partsField
+	}
 	if _, isLabelType := map[Type]struct{}{
 		TypeInput:     {},
 		TypeProcessor: {},
@@ -1,8 +1,19 @@
 package docs
 
+import "strings"
+
 // JSONSchema serializes a field spec into a JSON schema structure.
 func (f FieldSpec) JSONSchema() interface{} {
 	spec := map[string]interface{}{}
+	if f.Description != "" {
+		spec["description"] = strings.TrimSpace(f.Description)
+	}
+	if f.Default != nil {
+		spec["default"] = *f.Default
+	}
+	if f.IsDeprecated {
+		spec["deprecated"] = true
+	}
 	switch f.Kind {
 	case Kind2DArray:
 		innerField := f
@@ -36,7 +47,7 @@ func (f FieldSpec) JSONSchema() interface{} {
 			spec["properties"] = f.Children.JSONSchema()
 			var required []string
 			for _, child := range f.Children {
-				if !child.IsOptional && child.Default == nil {
+				if child.isRequired() {
 					required = append(required, child.Name)
 				}
 			}
@@ -67,6 +78,18 @@ func (f FieldSpec) JSONSchema() interface{} {
 	return spec
 }
 
+// isRequired returns whether a field must be present within its parent, which
+// follows the same rules as the linter.
+func (f FieldSpec) isRequired() bool {
+	_, isCore := f.Type.IsCoreComponent()
+	return !f.IsOptional &&
+		f.Default == nil &&
+		!isCore &&
+		f.Kind == KindScalar &&
+		!f.IsDeprecated &&
+		len(f.Children) == 0
+}
+
 // JSONSchema serializes a field spec into a JSON schema structure.
 func (f FieldSpecs) JSONSchema() map[string]interface{} {
 	spec := map[string]interface{}{}
@@ -75,3 +98,88 @@ func (f FieldSpecs) JSONSchema() map[string]interface{} {
 	}
 	return spec
 }
+
+// ConfigJSONSchema serializes the field specs of a config into a JSON schema
+// document, where the fields of each component type reference a definition
+// that accepts any of the provided components of that type.
+func ConfigJSONSchema(spec FieldSpecs, components []ComponentSpec) map[string]interface{} {
+	byType := map[Type][]ComponentSpec{}
+	for _, c := range components {
+		byType[c.Type] = append(byType[c.Type], c)
+	}
+
+	defs := map[string]interface{}{}
+	for _, t := range []Type{
+		TypeBuffer,
+		TypeCache,
+		TypeInput,
+		TypeMetrics,
+		TypeOutput,
+		TypeProcessor,
+		TypeRateLimit,
+		TypeTracer,
+	} {
+		defs[string(t)] = componentsJSONSchema(t, byType[t])
+	}
+
+	root := FieldComponent().WithChildren(spec...).JSONSchema().(map[string]interface{})
+	root["$schema"] = "https://json-schema.org/draft/2019-09/schema"
+	root["$defs"] = defs
+	return root
+}
+
+// componentsJSONSchema returns a JSON schema that accepts the config of any of
+// a list of components of a type, each of which is an object containing a
+// field named after the component, or a type field with its name, along with
+// the fields reserved for that type of component.
+func componentsJSONSchema(t Type, components []ComponentSpec) interface{} {
+	reserved := reservedFieldsByType(t)
+	reservedProps := func() map[string]interface{} {
+		props := map[string]interface{}{}
+		for k, f := range reserved {
+			if k != "type" {
+				props[k] = f.JSONSchema()
+			}
+		}
+		return props
+	}
+
+	// A config containing only reserved fields results in the default
+	// component of the type.
+	alternatives := []interface{}{
+		map[string]interface{}{
+			"type":                 "object",
+			"properties":           reservedProps(),
+			"additionalProperties": false,
+		},
+	}
+	for _, c := range components {
+		props := reservedProps()
+		props["type"] = reserved["type"].JSONSchema()
+		props[c.Name] = c.Config.JSONSchema()
+
+		alt := map[string]interface{}{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": false,
+			"anyOf": []interface{}{
+				map[string]interface{}{
+					"required": []string{c.Name},
+				},
+				map[string]interface{}{
+					"properties": map[string]interface{}{
+						"type": map[string]interface{}{"const": c.Name},
+					},
+					"required": []string{"type"},
+				},
+			},
+		}
+		if summary := strings.TrimSpace(c.Summary); summary != "" {
+			alt["description"] = summary
+		}
+		alternatives = append(alternatives, alt)
+	}
+	return map[string]interface{}{
+		"anyOf": alternatives,
+	}
+}
@@ -0,0 +1,94 @@
+package docs_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/internal/docs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestConfigJSONSchema(t *testing.T) {
+	spec := docs.FieldSpecs{
+		docs.FieldCommon("input", "An input.").HasType(docs.FieldTypeInput),
+		docs.FieldCommon("http", "Some HTTP settings.").WithChildren(
+			docs.FieldString("address", "An address.").HasDefault("0.0.0.0:4195"),
+			docs.FieldBool("enabled", "Whether enabled.").HasDefault(true),
+		),
+		docs.FieldString("shutdown_timeout", "A timeout.").HasDefault("20s"),
+	}
+	components := []docs.ComponentSpec{
+		{
+			Name: "foo",
+			Type: docs.TypeInput,
+			Config: docs.FieldComponent().WithChildren(
+				docs.FieldString("url", "A required URL."),
+				docs.FieldInt("count", "A count.").HasDefault(10),
+			),
+		},
+		{
+			Name:   "bar",
+			Type:   docs.TypeInput,
+			Config: docs.FieldComponent().WithChildren(docs.FieldString("baz", "").HasDefault("")),
+		},
+	}
+
+	schemaBytes, err := json.Marshal(docs.ConfigJSONSchema(spec, components))
+	require.NoError(t, err)
+
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schemaBytes))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		conf  string
+		valid bool
+	}{
+		{
+			name:  "empty config",
+			conf:  `{}`,
+			valid: true,
+		},
+		{
+			name:  "valid component",
+			conf:  `{"input":{"label":"a","foo":{"url":"http://x","count":5},"processors":[]},"http":{"enabled":false}}`,
+			valid: true,
+		},
+		{
+			name:  "component by type field",
+			conf:  `{"input":{"type":"bar","bar":{}}}`,
+			valid: true,
+		},
+		{
+			name: "missing required field",
+			conf: `{"input":{"foo":{"count":5}}}`,
+		},
+		{
+			name: "wrong field type",
+			conf: `{"input":{"foo":{"url":"http://x","count":"nope"}}}`,
+		},
+		{
+			name: "unknown component",
+			conf: `{"input":{"nope":{}}}`,
+		},
+		{
+			name: "unknown field",
+			conf: `{"http":{"nope":true}}`,
+		},
+		{
+			name: "unknown root field",
+			conf: `{"nope":true}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			res, err := schema.Validate(gojsonschema.NewStringLoader(test.conf))
+			require.NoError(t, err)
+			assert.Equal(t, test.valid, res.Valid(), res.Errors())
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -107,6 +108,21 @@ func setYAMLPathCore(docsProvider Provider, coreType Type, root, value *yaml.Nod
 	return nil
 }
 
+// flowSequenceValue parses a plain scalar written as a YAML flow sequence, e.g.
+// `[ foo, bar ]`, which allows an array field to be replaced in its entirety by
+// a value given as a string, such as a --set override. Other values are
+// returned unchanged.
+func flowSequenceValue(value *yaml.Node) (*yaml.Node, error) {
+	if value.Kind != yaml.ScalarNode || value.Style != 0 || !strings.HasPrefix(value.Value, "[") {
+		return value, nil
+	}
+	var seqNode yaml.Node
+	if err := yaml.Unmarshal([]byte(value.Value), &seqNode); err != nil {
+		return nil, fmt.Errorf("failed to parse array value: %w", err)
+	}
+	return unwrapDocumentNode(&seqNode), nil
+}
+
 // SetYAMLPath sets the value of a node within a YAML document identified by a
 // path to a value.
 func (f FieldSpec) SetYAMLPath(docsProvider Provider, root, value *yaml.Node, path ...string) error {
@@ -116,6 +132,10 @@ func (f FieldSpec) SetYAMLPath(docsProvider Provider, root, value *yaml.Node, pa
 	switch f.Kind {
 	case Kind2DArray:
 		if len(path) == 0 {
+			value, err := flowSequenceValue(value)
+			if err != nil {
+				return err
+			}
 			if value.Kind == yaml.SequenceNode {
 				*root = *value
 			} else {
@@ -137,6 +157,10 @@ func (f FieldSpec) SetYAMLPath(docsProvider Provider, root, value *yaml.Node, pa
 		return nil
 	case KindArray:
 		if len(path) == 0 {
+			value, err := flowSequenceValue(value)
+			if err != nil {
+				return err
+			}
 			if value.Kind == yaml.SequenceNode {
 				*root = *value
 			} else {
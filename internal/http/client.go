@@ -2,7 +2,10 @@ package http
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
@@ -10,23 +13,27 @@ import (
 	"net"
 	"net/http"
 	"net/textproto"
-	"net/url"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Jeffail/benthos/v3/internal/bloblang"
 	"github.com/Jeffail/benthos/v3/internal/bloblang/field"
+	"github.com/Jeffail/benthos/v3/internal/component/output"
 	"github.com/Jeffail/benthos/v3/internal/interop"
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/message"
+	mio "github.com/Jeffail/benthos/v3/lib/message/io"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/types"
 	"github.com/Jeffail/benthos/v3/lib/util/http/client"
 	"github.com/Jeffail/benthos/v3/lib/util/throttle"
+	"github.com/klauspost/compress/zstd"
 	"github.com/opentracing/opentracing-go"
 	olog "github.com/opentracing/opentracing-go/log"
+	"golang.org/x/oauth2"
 )
 
 // Client is a component able to send and receive Benthos messages over HTTP.
@@ -42,6 +49,8 @@ type Client struct {
 	host    *field.Expression
 
 	conf          client.Config
+	compression   string
+	wireVersion   int32
 	retryThrottle *throttle.Type
 
 	log   log.Modular
@@ -64,6 +73,8 @@ type Client struct {
 
 	oauthClientCtx    context.Context
 	oauthClientCancel func()
+	releaseTransport  func()
+	roundTripper      http.RoundTripper
 }
 
 // NewClient creates a new http client that sends and receives Benthos messages.
@@ -85,51 +96,6 @@ func NewClient(conf client.Config, opts ...func(*Client)) (*Client, error) {
 		headers:   map[string]*field.Expression{},
 		host:      nil,
 	}
-	h.oauthClientCtx, h.oauthClientCancel = context.WithCancel(context.Background())
-	h.client = conf.OAuth2.Client(h.oauthClientCtx)
-
-	if tout := conf.Timeout; len(tout) > 0 {
-		var err error
-		if h.client.Timeout, err = time.ParseDuration(tout); err != nil {
-			return nil, fmt.Errorf("failed to parse timeout string: %v", err)
-		}
-	}
-
-	if h.conf.TLS.Enabled {
-		tlsConf, err := h.conf.TLS.Get()
-		if err != nil {
-			return nil, err
-		}
-		if tlsConf != nil {
-			if c, ok := http.DefaultTransport.(*http.Transport); ok {
-				cloned := c.Clone()
-				cloned.TLSClientConfig = tlsConf
-				h.client.Transport = cloned
-			} else {
-				h.client.Transport = &http.Transport{
-					TLSClientConfig: tlsConf,
-				}
-			}
-		}
-	}
-
-	if h.conf.ProxyURL != "" {
-		proxyURL, err := url.Parse(h.conf.ProxyURL)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse proxy_url string: %v", err)
-		}
-		if h.client.Transport != nil {
-			if tr, ok := h.client.Transport.(*http.Transport); ok {
-				tr.Proxy = http.ProxyURL(proxyURL)
-			} else {
-				return nil, fmt.Errorf("unable to apply proxy_url to transport, unexpected type %T", h.client.Transport)
-			}
-		} else {
-			h.client.Transport = &http.Transport{
-				Proxy: http.ProxyURL(proxyURL),
-			}
-		}
-	}
 
 	for _, c := range conf.BackoffOn {
 		h.backoffOn[c] = struct{}{}
@@ -157,6 +123,10 @@ func NewClient(conf client.Config, opts ...func(*Client)) (*Client, error) {
 		opt(&h)
 	}
 
+	if h.compression != "" && !isContentEncoding(h.compression) {
+		return nil, fmt.Errorf("compression algorithm not recognised: %v", h.compression)
+	}
+
 	h.mCount = h.stats.GetCounter("count")
 	h.mErr = h.stats.GetCounter("error")
 	h.mErrReq = h.stats.GetCounter("error.request")
@@ -189,12 +159,43 @@ func NewClient(conf client.Config, opts ...func(*Client)) (*Client, error) {
 		}
 	}
 
+	var timeout time.Duration
+	if tout := conf.Timeout; len(tout) > 0 {
+		var err error
+		if timeout, err = time.ParseDuration(tout); err != nil {
+			return nil, fmt.Errorf("failed to parse timeout string: %v", err)
+		}
+	}
+
 	h.retryThrottle = throttle.New(
 		throttle.OptMaxUnthrottledRetries(0),
 		throttle.OptThrottlePeriod(retry),
 		throttle.OptMaxExponentPeriod(maxBackoff),
 	)
 
+	transport, releaseTransport, err := acquireTransport(h.mgr, conf)
+	if err != nil {
+		return nil, err
+	}
+	h.releaseTransport = releaseTransport
+
+	h.oauthClientCtx, h.oauthClientCancel = context.WithCancel(context.Background())
+	if conf.OAuth2.Enabled {
+		// Requests made by the OAuth2 client, including token requests, use
+		// the shared transport as their base.
+		h.oauthClientCtx = context.WithValue(h.oauthClientCtx, oauth2.HTTPClient, &http.Client{
+			Transport: transport,
+		})
+		h.client = conf.OAuth2.Client(h.oauthClientCtx)
+	} else {
+		h.client = conf.OAuth2.Client(h.oauthClientCtx)
+		h.client.Transport = transport
+	}
+	if h.roundTripper != nil {
+		h.client.Transport = h.roundTripper
+	}
+	h.client.Timeout = timeout
+
 	return &h, nil
 }
 
@@ -221,11 +222,28 @@ func OptSetManager(mgr types.Manager) func(*Client) {
 	}
 }
 
+// OptSetCompression sets an algorithm to compress request bodies with, the
+// Content-Encoding header of each request is set accordingly. The algorithm
+// must be one of ContentEncodings.
+func OptSetCompression(algorithm string) func(*Client) {
+	return func(t *Client) {
+		t.compression = algorithm
+	}
+}
+
+// OptSetWireFormat sets the client to send messages in the wire format of
+// Benthos, preserving the parts and metadata of each message.
+func OptSetWireFormat() func(*Client) {
+	return func(t *Client) {
+		t.wireVersion = mio.WireVersion
+	}
+}
+
 // OptSetRoundTripper sets the *client.Transport to use for HTTP requests.
 // NOTE: This setting will override any configured TLS options.
 func OptSetRoundTripper(rt http.RoundTripper) func(*Client) {
 	return func(t *Client) {
-		t.client.Transport = rt
+		t.roundTripper = rt
 	}
 }
 
@@ -288,7 +306,16 @@ func (h *Client) CreateRequest(sendMsg, refMsg types.Message) (req *http.Request
 	var overrideContentType string
 	var body io.Reader
 
-	if sendMsg != nil && sendMsg.Len() == 1 {
+	if wireVersion := int(atomic.LoadInt32(&h.wireVersion)); wireVersion > 0 && sendMsg != nil {
+		var msgBytes []byte
+		if msgBytes, err = mio.MessageToWire(sendMsg, wireVersion); err != nil {
+			return
+		}
+		body = bytes.NewBuffer(msgBytes)
+		overrideContentType = mime.FormatMediaType(mio.WireContentType, map[string]string{
+			"version": strconv.Itoa(wireVersion),
+		})
+	} else if sendMsg != nil && sendMsg.Len() == 1 {
 		if msgBytes := sendMsg.Get(0).Get(); len(msgBytes) > 0 {
 			body = bytes.NewBuffer(msgBytes)
 		}
@@ -318,6 +345,12 @@ func (h *Client) CreateRequest(sendMsg, refMsg types.Message) (req *http.Request
 		body = buf
 	}
 
+	if body != nil && h.compression != "" {
+		if body, err = h.compressBody(body); err != nil {
+			return
+		}
+	}
+
 	url := h.url.String(0, refMsg)
 	if req, err = http.NewRequest(h.conf.Verb, url, body); err != nil {
 		return
@@ -333,11 +366,57 @@ func (h *Client) CreateRequest(sendMsg, refMsg types.Message) (req *http.Request
 		req.Header.Del("Content-Type")
 		req.Header.Add("Content-Type", overrideContentType)
 	}
+	if body != nil && h.compression != "" {
+		req.Header.Set("Content-Encoding", h.compression)
+	}
 
 	err = h.conf.Config.Sign(req)
 	return
 }
 
+// ContentEncodings lists the algorithms that request bodies can be compressed
+// with, each of which is a registered HTTP content coding.
+var ContentEncodings = []string{"gzip", "deflate", "zstd"}
+
+func isContentEncoding(algorithm string) bool {
+	for _, e := range ContentEncodings {
+		if e == algorithm {
+			return true
+		}
+	}
+	return false
+}
+
+func newContentEncoder(algorithm string, w io.Writer) (io.WriteCloser, error) {
+	switch algorithm {
+	case "gzip":
+		return gzip.NewWriter(w), nil
+	case "deflate":
+		// The deflate content coding is the zlib format rather than a raw
+		// deflate stream.
+		return zlib.NewWriter(w), nil
+	case "zstd":
+		return zstd.NewWriter(w)
+	}
+	return nil, fmt.Errorf("compression algorithm not recognised: %v", algorithm)
+}
+
+func (h *Client) compressBody(body io.Reader) (io.Reader, error) {
+	buf := &bytes.Buffer{}
+	w, err := newContentEncoder(h.compression, buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(w, body); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
 // ParseResponse attempts to parse an HTTP response into a 2D slice of bytes.
 func (h *Client) ParseResponse(res *http.Response) (resMsg types.Message, err error) {
 	resMsg = message.New(nil)
@@ -449,6 +528,28 @@ func (h *Client) checkStatus(code int) (succeeded bool, retStrat retryStrategy)
 	return true, noRetry
 }
 
+// negotiateWireVersion switches the version of the wire format used by the
+// client when a server rejects it and advertises the versions it supports.
+func (h *Client) negotiateWireVersion(res *http.Response) {
+	if atomic.LoadInt32(&h.wireVersion) == 0 || res.StatusCode != http.StatusUnsupportedMediaType {
+		return
+	}
+	advertised := res.Header.Get(mio.WireVersionsHeader)
+	if advertised == "" {
+		return
+	}
+	remote, err := mio.ParseWireVersions(advertised)
+	if err == nil {
+		var version int
+		if version, err = mio.NegotiateWireVersion(remote); err == nil {
+			h.log.Infof("Negotiated wire format version %v with server\n", version)
+			atomic.StoreInt32(&h.wireVersion, int32(version))
+			return
+		}
+	}
+	h.log.Errorf("Failed to negotiate wire format version: %v\n", err)
+}
+
 // SendToResponse attempts to create an HTTP request from a provided message,
 // performs it, and then returns the *http.Response, allowing the raw response
 // to be consumed.
@@ -507,6 +608,7 @@ func (h *Client) SendToResponse(ctx context.Context, sendMsg, refMsg types.Messa
 		}
 	} else {
 		h.incrCode(res.StatusCode)
+		h.negotiateWireVersion(res)
 		if resolved, retryStrat := h.checkStatus(res.StatusCode); !resolved {
 			rateLimited = retryStrat == retryBackoff
 			if retryStrat == noRetry {
@@ -540,6 +642,7 @@ func (h *Client) SendToResponse(ctx context.Context, sendMsg, refMsg types.Messa
 		rateLimited = false
 		if res, err = h.client.Do(req.WithContext(ctx)); err == nil {
 			h.incrCode(res.StatusCode)
+			h.negotiateWireVersion(res)
 			if resolved, retryStrat := h.checkStatus(res.StatusCode); !resolved {
 				rateLimited = retryStrat == retryBackoff
 				if retryStrat == noRetry {
@@ -557,6 +660,13 @@ func (h *Client) SendToResponse(ctx context.Context, sendMsg, refMsg types.Messa
 	}
 	if err != nil {
 		logErr(err)
+		var hErr types.ErrUnexpectedHTTPRes
+		if errors.As(err, &hErr) && output.IsThrottledHTTPStatus(hErr.Code) && res != nil {
+			err = output.ErrThrottled{
+				Err:        err,
+				RetryAfter: output.ParseRetryAfter(res.Header.Get("Retry-After")),
+			}
+		}
 		return nil, err
 	}
 
@@ -584,5 +694,6 @@ func (h *Client) Send(ctx context.Context, sendMsg, refMsg types.Message) (types
 // Close the client.
 func (h *Client) Close(ctx context.Context) error {
 	h.oauthClientCancel()
+	h.releaseTransport()
 	return nil
 }
@@ -2,7 +2,10 @@ package http
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -17,11 +20,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Jeffail/benthos/v3/internal/component/output"
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/message"
+	mio "github.com/Jeffail/benthos/v3/lib/message/io"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/types"
 	"github.com/Jeffail/benthos/v3/lib/util/http/client"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -51,6 +57,34 @@ func TestHTTPClientRetries(t *testing.T) {
 	assert.Equal(t, uint32(4), atomic.LoadUint32(&reqCount))
 }
 
+func TestHTTPClientThrottled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	conf := client.NewConfig()
+	conf.URL = ts.URL + "/testpost"
+	conf.NumRetries = 0
+
+	h, err := NewClient(conf)
+	require.NoError(t, err)
+	defer h.Close(context.Background())
+
+	out := message.New([][]byte{[]byte("test")})
+	_, err = h.Send(context.Background(), out, out)
+	require.Error(t, err)
+
+	retryAfter, throttled := output.IsThrottled(err)
+	assert.True(t, throttled)
+	assert.Equal(t, time.Second*5, retryAfter)
+
+	var hErr types.ErrUnexpectedHTTPRes
+	require.True(t, errors.As(err, &hErr))
+	assert.Equal(t, http.StatusTooManyRequests, hErr.Code)
+}
+
 func TestHTTPClientBadRequest(t *testing.T) {
 	conf := client.NewConfig()
 	conf.URL = "htp://notvalid:1111"
@@ -105,6 +139,135 @@ func TestHTTPClientSendBasic(t *testing.T) {
 	}
 }
 
+func TestHTTPClientSendCompressed(t *testing.T) {
+	resultChan := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		gr, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(gr)
+		require.NoError(t, err)
+		resultChan <- string(b)
+	}))
+	defer ts.Close()
+
+	conf := client.NewConfig()
+	conf.URL = ts.URL + "/testpost"
+
+	h, err := NewClient(conf, OptSetCompression("gzip"))
+	require.NoError(t, err)
+	defer h.Close(context.Background())
+
+	out := message.New([][]byte{[]byte("hello world")})
+	_, err = h.Send(context.Background(), out, out)
+	require.NoError(t, err)
+
+	select {
+	case res := <-resultChan:
+		assert.Equal(t, "hello world", res)
+	case <-time.After(time.Second):
+		t.Fatal("Action timed out")
+	}
+
+	_, err = NewClient(conf, OptSetCompression("nope"))
+	require.Error(t, err)
+
+	// Only registered HTTP content codings are supported.
+	_, err = NewClient(conf, OptSetCompression("snappy"))
+	require.Error(t, err)
+}
+
+func TestHTTPClientSendEncodings(t *testing.T) {
+	decoders := map[string]func(r io.Reader) (io.Reader, error){
+		"deflate": func(r io.Reader) (io.Reader, error) {
+			return zlib.NewReader(r)
+		},
+		"zstd": func(r io.Reader) (io.Reader, error) {
+			return zstd.NewReader(r)
+		},
+	}
+
+	for encoding, decoder := range decoders {
+		encoding, decoder := encoding, decoder
+		t.Run(encoding, func(t *testing.T) {
+			resultChan := make(chan string, 1)
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, encoding, r.Header.Get("Content-Encoding"))
+				dr, err := decoder(r.Body)
+				require.NoError(t, err)
+				b, err := ioutil.ReadAll(dr)
+				require.NoError(t, err)
+				resultChan <- string(b)
+			}))
+			defer ts.Close()
+
+			conf := client.NewConfig()
+			conf.URL = ts.URL + "/testpost"
+
+			h, err := NewClient(conf, OptSetCompression(encoding))
+			require.NoError(t, err)
+			defer h.Close(context.Background())
+
+			out := message.New([][]byte{[]byte("hello world")})
+			_, err = h.Send(context.Background(), out, out)
+			require.NoError(t, err)
+
+			select {
+			case res := <-resultChan:
+				assert.Equal(t, "hello world", res)
+			case <-time.After(time.Second):
+				t.Fatal("Action timed out")
+			}
+		})
+	}
+}
+
+func TestHTTPClientSendWireFormat(t *testing.T) {
+	var reqs int32
+	resultChan := make(chan types.Message, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&reqs, 1) == 1 {
+			w.Header().Set(mio.WireVersionsHeader, "7,1")
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		assert.Equal(t, mio.WireContentType, mediaType)
+		assert.Equal(t, "1", params["version"])
+
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		msg, err := mio.MessageFromWire(b)
+		require.NoError(t, err)
+		resultChan <- msg
+	}))
+	defer ts.Close()
+
+	conf := client.NewConfig()
+	conf.URL = ts.URL + "/testpost"
+	conf.Retry = "1ms"
+
+	h, err := NewClient(conf, OptSetWireFormat())
+	require.NoError(t, err)
+	defer h.Close(context.Background())
+
+	out := message.New([][]byte{[]byte("foo"), []byte("bar")})
+	out.Get(1).Metadata().Set("baz", "buz")
+	_, err = h.Send(context.Background(), out, out)
+	require.NoError(t, err)
+
+	select {
+	case res := <-resultChan:
+		require.Equal(t, 2, res.Len())
+		assert.Equal(t, "foo", string(res.Get(0).Get()))
+		assert.Equal(t, "bar", string(res.Get(1).Get()))
+		assert.Equal(t, "buz", res.Get(1).Metadata().Get("baz"))
+	case <-time.After(time.Second):
+		t.Fatal("Action timed out")
+	}
+}
+
 func TestHTTPClientBadContentType(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		b, err := ioutil.ReadAll(r.Body)
@@ -0,0 +1,288 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/interop"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/discovery"
+	"github.com/Jeffail/benthos/v3/lib/util/http/client"
+)
+
+//------------------------------------------------------------------------------
+
+// transportKey returns a string that uniquely identifies the connection
+// related fields of a client config.
+func transportKey(conf client.Config) (string, error) {
+	keyBytes, err := json.Marshal(struct {
+		TLS       interface{} `json:"tls"`
+		ProxyURL  string      `json:"proxy_url"`
+		Transport interface{} `json:"transport"`
+	}{
+		TLS:       conf.TLS,
+		ProxyURL:  conf.ProxyURL,
+		Transport: conf.Transport,
+	})
+	return string(keyBytes), err
+}
+
+// pooledTransport is an HTTP transport that is shared by clients, closing it
+// closes its idle connections.
+type pooledTransport struct {
+	*http.Transport
+}
+
+func (p pooledTransport) Close() error {
+	p.CloseIdleConnections()
+	return nil
+}
+
+// acquireTransport returns an HTTP transport for a client config, which is
+// shared with all other clients of the manager that have matching connection
+// related fields, allowing them to share pools of connections as well as DNS
+// caches. The returned func releases the transport once the client is finished
+// with it, and the transport is closed once released by all of its clients.
+func acquireTransport(mgr types.Manager, conf client.Config) (*http.Transport, func(), error) {
+	key, err := transportKey(conf)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, release, err := interop.AcquireShared(mgr, "http_transport:"+key, func() (io.Closer, error) {
+		t, err := newTransport(conf)
+		if err != nil {
+			return nil, err
+		}
+		return pooledTransport{t}, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return res.(pooledTransport).Transport, release, nil
+}
+
+func newTransport(conf client.Config) (*http.Transport, error) {
+	var t *http.Transport
+	if c, ok := http.DefaultTransport.(*http.Transport); ok {
+		t = c.Clone()
+	} else {
+		t = &http.Transport{}
+	}
+
+	if conf.TLS.Enabled {
+		tlsConf, err := conf.TLS.Get()
+		if err != nil {
+			return nil, err
+		}
+		t.TLSClientConfig = tlsConf
+	}
+
+	if conf.ProxyURL != "" {
+		proxyURL, err := url.Parse(conf.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy_url string: %v", err)
+		}
+		t.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if conf.Transport.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = conf.Transport.MaxIdleConnsPerHost
+		if t.MaxIdleConns > 0 && t.MaxIdleConns < t.MaxIdleConnsPerHost {
+			t.MaxIdleConns = t.MaxIdleConnsPerHost
+		}
+	}
+	if tout := conf.Transport.IdleConnTimeout; len(tout) > 0 {
+		var err error
+		if t.IdleConnTimeout, err = time.ParseDuration(tout); err != nil {
+			return nil, fmt.Errorf("failed to parse idle connection timeout string: %v", err)
+		}
+	}
+	if tout := conf.Transport.DNSCacheTTL; len(tout) > 0 {
+		ttl, err := time.ParseDuration(tout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse dns cache ttl string: %v", err)
+		}
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}
+		t.DialContext = newDNSCache(ttl, net.DefaultResolver.LookupHost).dialContext(dialer)
+	}
+	if len(conf.Transport.Discovery) > 0 {
+		if conf.ProxyURL != "" {
+			return nil, errors.New("transport discovery cannot be used with a proxy_url")
+		}
+		resolver, err := discovery.NewResolver(conf.Transport.Discovery)
+		if err != nil {
+			return nil, err
+		}
+		interval, err := time.ParseDuration(conf.Transport.DiscoveryInterval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse discovery interval string: %v", err)
+		}
+		dial := t.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext
+		}
+		d := &discoveryDialer{
+			resolver: resolver,
+			interval: interval,
+			dial:     dial,
+			onChange: t.CloseIdleConnections,
+		}
+		t.DialContext = d.dialContext
+	}
+	return t, nil
+}
+
+//------------------------------------------------------------------------------
+
+// discoveryDialer dials addresses obtained from a discovery resolver instead of
+// the address requested, rotating through them with each new connection.
+type discoveryDialer struct {
+	resolver *discovery.Resolver
+	interval time.Duration
+	dial     func(ctx context.Context, network, addr string) (net.Conn, error)
+	onChange func()
+
+	next uint32
+
+	mut      sync.Mutex
+	addrs    []string
+	resolved time.Time
+}
+
+// addresses returns the resolved addresses, resolving them again once the
+// interval has passed. When resolution fails the previous addresses continue
+// to be used until the next attempt.
+func (d *discoveryDialer) addresses(ctx context.Context) ([]string, error) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	if d.addrs != nil && time.Since(d.resolved) < d.interval {
+		return d.addrs, nil
+	}
+
+	addrs, err := d.resolver.Resolve(ctx)
+	if err != nil {
+		if d.addrs != nil {
+			d.resolved = time.Now()
+			return d.addrs, nil
+		}
+		return nil, err
+	}
+	if d.addrs != nil && fmt.Sprintf("%v", addrs) != fmt.Sprintf("%v", d.addrs) {
+		// Idle connections to the previous addresses are pooled under the
+		// host of the URL, and would otherwise continue to be reused.
+		go d.onChange()
+	}
+	d.addrs = addrs
+	d.resolved = time.Now()
+	return addrs, nil
+}
+
+func (d *discoveryDialer) dialContext(ctx context.Context, network, _ string) (net.Conn, error) {
+	addrs, err := d.addresses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	start := int(atomic.AddUint32(&d.next, 1))
+	for i := range addrs {
+		var conn net.Conn
+		if conn, err = d.dial(ctx, network, addrs[(start+i)%len(addrs)]); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+//------------------------------------------------------------------------------
+
+type dnsCacheEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// dnsCache caches the results of DNS lookups for a period of time.
+type dnsCache struct {
+	ttl        time.Duration
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+
+	mut     sync.Mutex
+	entries map[string]dnsCacheEntry
+}
+
+func newDNSCache(ttl time.Duration, lookupHost func(ctx context.Context, host string) ([]string, error)) *dnsCache {
+	return &dnsCache{
+		ttl:        ttl,
+		lookupHost: lookupHost,
+		entries:    map[string]dnsCacheEntry{},
+	}
+}
+
+// lookup returns the addresses of a host, either from the cache or from a DNS
+// lookup when the cached addresses have expired.
+func (d *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	d.mut.Lock()
+	entry, exists := d.entries[host]
+	d.mut.Unlock()
+	if exists && time.Now().Before(entry.expires) {
+		return entry.addrs, nil
+	}
+
+	addrs, err := d.lookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mut.Lock()
+	d.entries[host] = dnsCacheEntry{
+		addrs:   addrs,
+		expires: time.Now().Add(d.ttl),
+	}
+	d.mut.Unlock()
+	return addrs, nil
+}
+
+// dialContext returns a dial func that resolves hosts using the cache before
+// dialing each of the addresses in turn until a connection is established.
+func (d *dnsCache) dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		addrs, err := d.lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range addrs {
+			var conn net.Conn
+			if conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(a, port)); err == nil {
+				return conn, nil
+			}
+		}
+		if err == nil {
+			err = fmt.Errorf("no addresses found for host: %v", host)
+		}
+		return nil, err
+	}
+}
+
+//------------------------------------------------------------------------------
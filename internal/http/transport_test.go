@@ -0,0 +1,223 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/discovery"
+	"github.com/Jeffail/benthos/v3/lib/util/http/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sharedMgr is a manager that shares resources by key in the same way as the
+// standard manager, and records when resources are closed.
+type sharedMgr struct {
+	types.DudMgr
+	resources map[string]io.Closer
+	refs      map[string]int
+	closed    []string
+}
+
+func newSharedMgr() *sharedMgr {
+	return &sharedMgr{
+		resources: map[string]io.Closer{},
+		refs:      map[string]int{},
+	}
+}
+
+func (s *sharedMgr) AcquireShared(key string, create func() (io.Closer, error)) (io.Closer, func(), error) {
+	if _, exists := s.resources[key]; !exists {
+		res, err := create()
+		if err != nil {
+			return nil, nil, err
+		}
+		s.resources[key] = res
+	}
+	s.refs[key]++
+	return s.resources[key], func() {
+		if s.refs[key]--; s.refs[key] == 0 {
+			_ = s.resources[key].Close()
+			delete(s.resources, key)
+			s.closed = append(s.closed, key)
+		}
+	}, nil
+}
+
+func TestSharedTransport(t *testing.T) {
+	mgr := newSharedMgr()
+
+	confA := client.NewConfig()
+	confA.URL = "http://foo/a"
+
+	confB := client.NewConfig()
+	confB.URL = "http://bar/b"
+	confB.Verb = "GET"
+
+	confC := client.NewConfig()
+	confC.Transport.MaxIdleConnsPerHost = 5
+
+	tA, releaseA, err := acquireTransport(mgr, confA)
+	require.NoError(t, err)
+	tB, releaseB, err := acquireTransport(mgr, confB)
+	require.NoError(t, err)
+	tC, releaseC, err := acquireTransport(mgr, confC)
+	require.NoError(t, err)
+
+	assert.True(t, tA == tB)
+	assert.False(t, tA == tC)
+	assert.Equal(t, http.DefaultTransport.(*http.Transport).MaxIdleConnsPerHost, tA.MaxIdleConnsPerHost)
+	assert.Equal(t, 90*time.Second, tA.IdleConnTimeout)
+	assert.Equal(t, 5, tC.MaxIdleConnsPerHost)
+
+	// A transport is closed once released by all of its clients.
+	releaseA()
+	assert.Empty(t, mgr.closed)
+	releaseB()
+	assert.Len(t, mgr.closed, 1)
+	releaseC()
+	assert.Len(t, mgr.closed, 2)
+	assert.Empty(t, mgr.resources)
+
+	confD := client.NewConfig()
+	confD.Transport.DNSCacheTTL = "nope"
+	_, _, err = acquireTransport(mgr, confD)
+	assert.Error(t, err)
+	assert.Empty(t, mgr.resources)
+}
+
+func TestUnsharedTransport(t *testing.T) {
+	// Managers that don't support shared resources give each client its own
+	// transport.
+	tA, releaseA, err := acquireTransport(types.NoopMgr(), client.NewConfig())
+	require.NoError(t, err)
+	defer releaseA()
+	tB, releaseB, err := acquireTransport(types.NoopMgr(), client.NewConfig())
+	require.NoError(t, err)
+	defer releaseB()
+
+	assert.False(t, tA == tB)
+}
+
+func TestClientReleasesTransport(t *testing.T) {
+	mgr := newSharedMgr()
+
+	c, err := NewClient(client.NewConfig(), OptSetManager(mgr))
+	require.NoError(t, err)
+	assert.Len(t, mgr.resources, 1)
+
+	require.NoError(t, c.Close(context.Background()))
+	assert.Empty(t, mgr.resources)
+	assert.Len(t, mgr.closed, 1)
+}
+
+func TestDNSCache(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+	defer ts.Close()
+
+	_, port, err := net.SplitHostPort(ts.Listener.Addr().String())
+	require.NoError(t, err)
+
+	lookups := 0
+	cache := newDNSCache(time.Hour, func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		assert.Equal(t, "foo.example", host)
+		return []string{"127.0.0.1"}, nil
+	})
+	dial := cache.dialContext(&net.Dialer{})
+
+	for i := 0; i < 3; i++ {
+		conn, err := dial(context.Background(), "tcp", net.JoinHostPort("foo.example", port))
+		require.NoError(t, err)
+		conn.Close()
+	}
+	assert.Equal(t, 1, lookups)
+
+	cache.ttl = 0
+	cache.entries = map[string]dnsCacheEntry{}
+	for i := 0; i < 2; i++ {
+		conn, err := dial(context.Background(), "tcp", net.JoinHostPort("foo.example", port))
+		require.NoError(t, err)
+		conn.Close()
+	}
+	assert.Equal(t, 3, lookups)
+}
+
+func TestTransportDiscovery(t *testing.T) {
+	hits := make(chan string, 10)
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "example.invalid", r.Host)
+			hits <- name
+		}))
+	}
+	tsA, tsB := newServer("a"), newServer("b")
+	defer tsA.Close()
+	defer tsB.Close()
+
+	conf := client.NewConfig()
+	conf.Transport.Discovery = []string{tsA.Listener.Addr().String(), tsB.Listener.Addr().String()}
+
+	tr, err := newTransport(conf)
+	require.NoError(t, err)
+	tr.DisableKeepAlives = true
+
+	c := &http.Client{Transport: tr}
+	seen := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		res, err := c.Get("http://example.invalid/foo")
+		require.NoError(t, err)
+		res.Body.Close()
+		seen[<-hits] = true
+	}
+	assert.Equal(t, map[string]bool{"a": true, "b": true}, seen)
+
+	conf.ProxyURL = "http://localhost:8080"
+	_, err = newTransport(conf)
+	require.Error(t, err)
+}
+
+func TestDiscoveryDialerKeepsAddresses(t *testing.T) {
+	var failing int32
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&failing) == 1 {
+			http.Error(w, "nope", http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"Node":{"Address":"10.0.0.1"},"Service":{"Port":80}}]`))
+	}))
+	defer consul.Close()
+
+	resolver, err := discovery.NewResolver([]string{"consul://" + consul.Listener.Addr().String() + "/foo"})
+	require.NoError(t, err)
+
+	var dialed []string
+	d := &discoveryDialer{
+		resolver: resolver,
+		interval: time.Nanosecond,
+		dial: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed = append(dialed, addr)
+			return nil, errors.New("refused")
+		},
+		onChange: func() {},
+	}
+
+	_, err = d.dialContext(context.Background(), "tcp", "foo:80")
+	require.EqualError(t, err, "refused")
+
+	atomic.StoreInt32(&failing, 1)
+	_, err = d.dialContext(context.Background(), "tcp", "foo:80")
+	require.EqualError(t, err, "refused")
+
+	assert.Equal(t, []string{"10.0.0.1:80", "10.0.0.1:80"}, dialed)
+}
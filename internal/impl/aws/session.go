@@ -4,6 +4,7 @@ import (
 	"github.com/Jeffail/benthos/v3/public/service"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 )
@@ -29,6 +30,9 @@ func sessionFields() []*service.ConfigField {
 			service.NewStringField("token").
 				Description("The token for the credentials being used, required when using short term credentials.").
 				Default("").Advanced(),
+			service.NewBoolField("from_ec2_role").
+				Description("Use the credentials of a host EC2 machine configured to assume [an IAM role associated with the instance](https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/iam-roles-for-amazon-ec2.html).").
+				Default(false).Advanced(),
 			service.NewStringField("role").
 				Description("A role ARN to assume.").
 				Default("").Advanced(),
@@ -46,7 +50,7 @@ func getSession(parsedConf *service.ParsedConfig, opts ...func(*aws.Config)) (*s
 		awsConf = awsConf.WithRegion(region)
 	}
 	if endpoint, _ := parsedConf.FieldString("endpoint"); endpoint != "" {
-		awsConf = awsConf.WithRegion(endpoint)
+		awsConf = awsConf.WithEndpoint(endpoint)
 	}
 	if profile, _ := parsedConf.FieldString("credentials", "profile"); profile != "" {
 		awsConf = awsConf.WithCredentials(credentials.NewSharedCredentials(
@@ -69,6 +73,10 @@ func getSession(parsedConf *service.ParsedConfig, opts ...func(*aws.Config)) (*s
 		return nil, err
 	}
 
+	if useEC2, _ := parsedConf.FieldBool("credentials", "from_ec2_role"); useEC2 {
+		sess.Config = sess.Config.WithCredentials(ec2rolecreds.NewCredentials(sess))
+	}
+
 	if role, _ := parsedConf.FieldString("credentials", "role"); role != "" {
 		var opts []func(*stscreds.AssumeRoleProvider)
 		if externalID, _ := parsedConf.FieldString("credentials", "role_external_id"); externalID != "" {
@@ -0,0 +1,223 @@
+package beanstalkd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+	"github.com/beanstalkd/go-beanstalk"
+)
+
+func beanstalkdInputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Services").
+		Summary("Reserves jobs from one or more tubes of a beanstalkd server.").
+		Description(`
+Each reserved job is consumed as a message and is deleted from the server once the message has been successfully delivered. If the message is rejected then the job is released back into its tube, where it can be reserved again.
+
+Since a job must be deleted or released within its time-to-run the pipeline should be able to deliver each message within that period, otherwise the server will automatically release the job and it may be delivered more than once.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- beanstalkd_id
+`+"```"+`
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#metadata).`).
+		Field(service.NewStringField("address").
+			Description("The address of the beanstalkd server to connect to.").
+			Example("localhost:11300")).
+		Field(service.NewStringListField("tubes").
+			Description("A list of tubes to watch and reserve jobs from.").
+			Default([]string{"default"})).
+		Field(service.NewStringField("reserve_timeout").
+			Description("The maximum period to wait for a job to become available with each reserve command. Jobs are deleted and released over the same connection and therefore this period also limits how long acknowledgements might be delayed.").
+			Default("1s").
+			Advanced()).
+		Field(service.NewIntField("release_priority").
+			Description("The priority to release jobs back into their tube with when a message is rejected.").
+			Default(1024).
+			Advanced()).
+		Field(service.NewStringField("release_delay").
+			Description("A delay to apply to jobs that are released back into their tube when a message is rejected.").
+			Default("0s").
+			Advanced())
+}
+
+func init() {
+	err := service.RegisterInput(
+		"beanstalkd", beanstalkdInputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			return newBeanstalkdInputFromConfig(conf, mgr.Logger())
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type beanstalkdInput struct {
+	address         string
+	tubes           []string
+	reserveTimeout  time.Duration
+	releasePriority uint32
+	releaseDelay    time.Duration
+
+	log *service.Logger
+
+	connMut sync.Mutex
+	conn    *beanstalk.Conn
+	tubeSet *beanstalk.TubeSet
+}
+
+func newBeanstalkdInputFromConfig(conf *service.ParsedConfig, log *service.Logger) (*beanstalkdInput, error) {
+	b := &beanstalkdInput{log: log}
+
+	var err error
+	if b.address, err = conf.FieldString("address"); err != nil {
+		return nil, err
+	}
+	if b.address == "" {
+		return nil, errors.New("field address must not be empty")
+	}
+	if b.tubes, err = conf.FieldStringList("tubes"); err != nil {
+		return nil, err
+	}
+	if len(b.tubes) == 0 {
+		return nil, errors.New("field tubes must not be empty")
+	}
+	if b.reserveTimeout, err = durationField(conf, "reserve_timeout"); err != nil {
+		return nil, err
+	}
+	if b.releaseDelay, err = durationField(conf, "release_delay"); err != nil {
+		return nil, err
+	}
+	priority, err := conf.FieldInt("release_priority")
+	if err != nil {
+		return nil, err
+	}
+	if priority < 0 {
+		return nil, fmt.Errorf("invalid release_priority: %v", priority)
+	}
+	b.releasePriority = uint32(priority)
+	return b, nil
+}
+
+func durationField(conf *service.ParsedConfig, name string) (time.Duration, error) {
+	str, err := conf.FieldString(name)
+	if err != nil {
+		return 0, err
+	}
+	d, err := time.ParseDuration(str)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse field '%v' as duration: %w", name, err)
+	}
+	return d, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (b *beanstalkdInput) Connect(ctx context.Context) error {
+	b.connMut.Lock()
+	defer b.connMut.Unlock()
+
+	if b.conn != nil {
+		return nil
+	}
+
+	conn, err := beanstalk.Dial("tcp", b.address)
+	if err != nil {
+		return err
+	}
+
+	b.conn = conn
+	b.tubeSet = beanstalk.NewTubeSet(conn, b.tubes...)
+
+	b.log.Infof("Reserving beanstalkd jobs from tubes %v at: %v\n", b.tubes, b.address)
+	return nil
+}
+
+func (b *beanstalkdInput) disconnect() {
+	if b.conn != nil {
+		b.conn.Close()
+		b.conn = nil
+		b.tubeSet = nil
+	}
+}
+
+func (b *beanstalkdInput) reserve() (*beanstalk.Conn, uint64, []byte, error) {
+	b.connMut.Lock()
+	defer b.connMut.Unlock()
+
+	if b.conn == nil {
+		return nil, 0, nil, service.ErrNotConnected
+	}
+
+	id, body, err := b.tubeSet.Reserve(b.reserveTimeout)
+	if err != nil {
+		if errors.Is(err, beanstalk.ErrTimeout) || errors.Is(err, beanstalk.ErrDeadline) {
+			return nil, 0, nil, err
+		}
+		b.log.Errorf("Lost connection due to: %v\n", err)
+		b.disconnect()
+		return nil, 0, nil, service.ErrNotConnected
+	}
+	return b.conn, id, body, nil
+}
+
+func (b *beanstalkdInput) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	var conn *beanstalk.Conn
+	var id uint64
+	var body []byte
+	for {
+		var err error
+		if conn, id, body, err = b.reserve(); err == nil {
+			break
+		}
+		if !errors.Is(err, beanstalk.ErrTimeout) && !errors.Is(err, beanstalk.ErrDeadline) {
+			return nil, nil, err
+		}
+		// The connection lock is released between reserve attempts so that
+		// pending jobs can be deleted or released.
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		default:
+		}
+	}
+
+	msg := service.NewMessage(body)
+	msg.MetaSet("beanstalkd_id", fmt.Sprintf("%v", id))
+
+	return msg, func(ctx context.Context, res error) error {
+		b.connMut.Lock()
+		defer b.connMut.Unlock()
+
+		// Jobs can only be deleted or released by the connection that
+		// reserved them, if we've since reconnected then the server will
+		// have already released the job.
+		if b.conn != conn {
+			return nil
+		}
+		if res != nil {
+			return conn.Release(id, b.releasePriority, b.releaseDelay)
+		}
+		return conn.Delete(id)
+	}, nil
+}
+
+func (b *beanstalkdInput) Close(ctx context.Context) error {
+	b.connMut.Lock()
+	b.disconnect()
+	b.connMut.Unlock()
+	return nil
+}
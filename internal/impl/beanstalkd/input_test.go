@@ -0,0 +1,115 @@
+package beanstalkd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBeanstalkdInputAckAndNack(t *testing.T) {
+	server, addr := runTestServer(t)
+	server.queue("foo", "bar")
+
+	b := &beanstalkdInput{
+		address:         addr,
+		tubes:           []string{"jobs"},
+		releasePriority: 10,
+		releaseDelay:    time.Second * 2,
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, b.Connect(ctx))
+	t.Cleanup(func() {
+		_ = b.Close(context.Background())
+	})
+
+	msg, ackFn, err := b.Read(ctx)
+	require.NoError(t, err)
+	body, err := msg.AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, "foo", string(body))
+	id, _ := msg.MetaGet("beanstalkd_id")
+	assert.Equal(t, "1", id)
+	require.NoError(t, ackFn(ctx, nil))
+
+	msg, nackFn, err := b.Read(ctx)
+	require.NoError(t, err)
+	body, err = msg.AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, "bar", string(body))
+	require.NoError(t, nackFn(ctx, errors.New("nope")))
+
+	assert.Equal(t, []string{
+		"delete 1",
+		"release 2 10 2",
+	}, server.received())
+}
+
+func TestBeanstalkdInputReserveTimeout(t *testing.T) {
+	server, addr := runTestServer(t)
+
+	b := &beanstalkdInput{
+		address: addr,
+		tubes:   []string{"jobs"},
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, b.Connect(ctx))
+	t.Cleanup(func() {
+		_ = b.Close(context.Background())
+	})
+
+	// Reserving continues beyond timeouts until a job is available.
+	go func() {
+		<-time.After(time.Millisecond * 50)
+		server.queue("foo")
+	}()
+
+	msg, _, err := b.Read(ctx)
+	require.NoError(t, err)
+	body, err := msg.AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, "foo", string(body))
+
+	// The read is abandoned once the context is cancelled.
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, _, err = b.Read(cancelledCtx)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestBeanstalkdInputAckAfterReconnect(t *testing.T) {
+	server, addr := runTestServer(t)
+	server.queue("foo")
+
+	b := &beanstalkdInput{
+		address: addr,
+		tubes:   []string{"jobs"},
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, b.Connect(ctx))
+	t.Cleanup(func() {
+		_ = b.Close(context.Background())
+	})
+
+	_, ackFn, err := b.Read(ctx)
+	require.NoError(t, err)
+
+	// Jobs reserved by a previous connection are not deleted by a new one.
+	require.NoError(t, b.Close(ctx))
+	require.NoError(t, b.Connect(ctx))
+	require.NoError(t, ackFn(ctx, nil))
+
+	assert.Empty(t, server.received())
+}
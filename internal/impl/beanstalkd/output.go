@@ -0,0 +1,169 @@
+package beanstalkd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+	"github.com/beanstalkd/go-beanstalk"
+)
+
+func beanstalkdOutputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Services").
+		Summary("Puts messages as jobs into a tube of a beanstalkd server.").
+		Description(`
+A message is only acknowledged once the server has confirmed that the job was put into the tube.`).
+		Field(service.NewStringField("address").
+			Description("The address of the beanstalkd server to connect to.").
+			Example("localhost:11300")).
+		Field(service.NewStringField("tube").
+			Description("The tube to put jobs into.").
+			Default("default")).
+		Field(service.NewIntField("priority").
+			Description("The priority of each job, where jobs with a smaller value are reserved before jobs with a larger value.").
+			Default(1024)).
+		Field(service.NewStringField("delay").
+			Description("An optional period of time to wait before each job is made ready to be reserved.").
+			Default("0s")).
+		Field(service.NewStringField("ttr").
+			Description("The time-to-run of each job, which is the period of time a consumer has to delete a reserved job before the server releases it.").
+			Default("60s").
+			Advanced()).
+		Field(service.NewIntField("max_in_flight").
+			Description("The maximum number of messages to have in flight at a given time. Increase this to improve throughput.").
+			Default(1))
+}
+
+func init() {
+	err := service.RegisterOutput(
+		"beanstalkd", beanstalkdOutputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (out service.Output, maxInFlight int, err error) {
+			if maxInFlight, err = conf.FieldInt("max_in_flight"); err != nil {
+				return
+			}
+			out, err = newBeanstalkdOutputFromConfig(conf, mgr.Logger())
+			return
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type beanstalkdOutput struct {
+	address  string
+	tube     string
+	priority uint32
+	delay    time.Duration
+	ttr      time.Duration
+
+	log *service.Logger
+
+	connMut sync.RWMutex
+	conn    *beanstalk.Conn
+	tubeRef *beanstalk.Tube
+}
+
+func newBeanstalkdOutputFromConfig(conf *service.ParsedConfig, log *service.Logger) (*beanstalkdOutput, error) {
+	b := &beanstalkdOutput{log: log}
+
+	var err error
+	if b.address, err = conf.FieldString("address"); err != nil {
+		return nil, err
+	}
+	if b.address == "" {
+		return nil, errors.New("field address must not be empty")
+	}
+	if b.tube, err = conf.FieldString("tube"); err != nil {
+		return nil, err
+	}
+	if b.delay, err = durationField(conf, "delay"); err != nil {
+		return nil, err
+	}
+	if b.ttr, err = durationField(conf, "ttr"); err != nil {
+		return nil, err
+	}
+	priority, err := conf.FieldInt("priority")
+	if err != nil {
+		return nil, err
+	}
+	if priority < 0 {
+		return nil, fmt.Errorf("invalid priority: %v", priority)
+	}
+	b.priority = uint32(priority)
+	return b, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (b *beanstalkdOutput) Connect(ctx context.Context) error {
+	b.connMut.Lock()
+	defer b.connMut.Unlock()
+
+	if b.conn != nil {
+		return nil
+	}
+
+	conn, err := beanstalk.Dial("tcp", b.address)
+	if err != nil {
+		return err
+	}
+
+	b.conn = conn
+	b.tubeRef = beanstalk.NewTube(conn, b.tube)
+
+	b.log.Infof("Putting beanstalkd jobs into tube %v at: %v\n", b.tube, b.address)
+	return nil
+}
+
+func (b *beanstalkdOutput) Write(ctx context.Context, msg *service.Message) error {
+	b.connMut.RLock()
+	tube := b.tubeRef
+	b.connMut.RUnlock()
+
+	if tube == nil {
+		return service.ErrNotConnected
+	}
+
+	body, err := msg.AsBytes()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tube.Put(body, b.priority, b.delay, b.ttr); err != nil {
+		var connErr beanstalk.ConnError
+		if errors.As(err, &connErr) && errors.Is(connErr.Err, beanstalk.ErrJobTooBig) {
+			return err
+		}
+		b.log.Errorf("Lost connection due to: %v\n", err)
+		b.connMut.Lock()
+		if b.tubeRef == tube {
+			b.conn.Close()
+			b.conn = nil
+			b.tubeRef = nil
+		}
+		b.connMut.Unlock()
+		return service.ErrNotConnected
+	}
+	return nil
+}
+
+func (b *beanstalkdOutput) Close(ctx context.Context) error {
+	b.connMut.Lock()
+	defer b.connMut.Unlock()
+
+	if b.conn != nil {
+		b.conn.Close()
+		b.conn = nil
+		b.tubeRef = nil
+	}
+	return nil
+}
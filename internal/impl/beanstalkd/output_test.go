@@ -0,0 +1,71 @@
+package beanstalkd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBeanstalkdOutputPut(t *testing.T) {
+	server, addr := runTestServer(t)
+
+	b := &beanstalkdOutput{
+		address:  addr,
+		tube:     "jobs",
+		priority: 5,
+		delay:    time.Second * 3,
+		ttr:      time.Minute,
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	assert.Equal(t, service.ErrNotConnected, b.Write(ctx, service.NewMessage([]byte("foo"))))
+
+	require.NoError(t, b.Connect(ctx))
+	t.Cleanup(func() {
+		_ = b.Close(context.Background())
+	})
+
+	require.NoError(t, b.Write(ctx, service.NewMessage([]byte("foo"))))
+	require.NoError(t, b.Write(ctx, service.NewMessage([]byte("hello world"))))
+
+	assert.Equal(t, []string{
+		"put 5 3 60 3",
+		"put 5 3 60 11",
+	}, server.received())
+	assert.Equal(t, []string{"foo", "hello world"}, server.putBodies())
+}
+
+func TestBeanstalkdOutputLostConnection(t *testing.T) {
+	_, addr := runTestServer(t)
+
+	b := &beanstalkdOutput{
+		address: addr,
+		tube:    "jobs",
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, b.Connect(ctx))
+
+	// Writes over a broken connection result in a disconnect.
+	b.connMut.Lock()
+	b.conn.Close()
+	b.connMut.Unlock()
+
+	assert.Equal(t, service.ErrNotConnected, b.Write(ctx, service.NewMessage([]byte("foo"))))
+
+	b.connMut.Lock()
+	assert.Nil(t, b.conn)
+	b.connMut.Unlock()
+
+	require.NoError(t, b.Connect(ctx))
+	require.NoError(t, b.Write(ctx, service.NewMessage([]byte("foo"))))
+	require.NoError(t, b.Close(ctx))
+}
@@ -0,0 +1,3 @@
+// Package beanstalkd contains component implementations for consuming jobs
+// from and putting jobs into beanstalkd work queues.
+package beanstalkd
@@ -0,0 +1,122 @@
+package beanstalkd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testServer is a minimal beanstalkd server that hands out queued jobs to
+// reserve commands and records every other command it receives.
+type testServer struct {
+	mut      sync.Mutex
+	jobs     []string
+	nextID   uint64
+	commands []string
+	puts     []string
+}
+
+func (s *testServer) queue(bodies ...string) {
+	s.mut.Lock()
+	s.jobs = append(s.jobs, bodies...)
+	s.mut.Unlock()
+}
+
+func (s *testServer) received() []string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func (s *testServer) putBodies() []string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return append([]string(nil), s.puts...)
+}
+
+func (s *testServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			return
+		}
+
+		var res string
+		s.mut.Lock()
+		switch args[0] {
+		case "watch", "ignore":
+			res = "WATCHING 1\r\n"
+		case "use":
+			res = fmt.Sprintf("USING %v\r\n", args[1])
+		case "reserve-with-timeout":
+			if len(s.jobs) == 0 {
+				res = "TIMED_OUT\r\n"
+				break
+			}
+			s.nextID++
+			body := s.jobs[0]
+			s.jobs = s.jobs[1:]
+			res = fmt.Sprintf("RESERVED %v %v\r\n%v\r\n", s.nextID, len(body), body)
+		case "delete":
+			s.commands = append(s.commands, strings.TrimSpace(line))
+			res = "DELETED\r\n"
+		case "release":
+			s.commands = append(s.commands, strings.TrimSpace(line))
+			res = "RELEASED\r\n"
+		case "put":
+			s.commands = append(s.commands, strings.TrimSpace(line))
+			size, _ := strconv.Atoi(args[len(args)-1])
+			body := make([]byte, size+2)
+			if _, err := io.ReadFull(r, body); err != nil {
+				s.mut.Unlock()
+				return
+			}
+			s.puts = append(s.puts, string(body[:size]))
+			s.nextID++
+			res = fmt.Sprintf("INSERTED %v\r\n", s.nextID)
+		default:
+			res = "UNKNOWN_COMMAND\r\n"
+		}
+		s.mut.Unlock()
+
+		if _, err := conn.Write([]byte(res)); err != nil {
+			return
+		}
+	}
+}
+
+func runTestServer(t *testing.T) (*testServer, string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ln.Close()
+	})
+
+	s := &testServer{}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.handle(conn)
+		}
+	}()
+	return s, ln.Addr().String()
+}
@@ -0,0 +1,257 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// engineClient is a minimal client for the parts of the Docker Engine API
+// needed in order to discover containers and follow their logs.
+type engineClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+func newEngineClient(host string) (*engineClient, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse host: %w", err)
+	}
+
+	transport := &http.Transport{}
+	baseURL := ""
+
+	switch u.Scheme {
+	case "unix":
+		socketPath := u.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socketPath)
+		}
+		// The host is ignored when dialing a unix socket but must be a valid
+		// value for the request URL.
+		baseURL = "http://docker"
+	case "tcp", "http":
+		baseURL = "http://" + u.Host
+	case "https":
+		baseURL = "https://" + u.Host
+	default:
+		return nil, fmt.Errorf("host scheme not supported: %v", u.Scheme)
+	}
+
+	return &engineClient{
+		baseURL: baseURL,
+		client:  &http.Client{Transport: transport},
+	}, nil
+}
+
+func (e *engineClient) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	reqURL := e.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		return nil, fmt.Errorf("request to %v returned status %v: %s", path, res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return res, nil
+}
+
+func (e *engineClient) getJSON(ctx context.Context, path string, query url.Values, v interface{}) error {
+	res, err := e.get(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+func (e *engineClient) ping(ctx context.Context) error {
+	res, err := e.get(ctx, "/_ping", nil)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+type containerSummary struct {
+	ID      string            `json:"Id"`
+	Names   []string          `json:"Names"`
+	Image   string            `json:"Image"`
+	Created int64             `json:"Created"`
+	Labels  map[string]string `json:"Labels"`
+}
+
+// name returns the primary name of the container without the leading slash.
+func (c containerSummary) name() string {
+	if len(c.Names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+func (e *engineClient) listContainers(ctx context.Context, labels []string) ([]containerSummary, error) {
+	filters := map[string][]string{
+		"status": {"running"},
+	}
+	if len(labels) > 0 {
+		filters["label"] = labels
+	}
+	filtersBytes, err := json.Marshal(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var containers []containerSummary
+	err = e.getJSON(ctx, "/containers/json", url.Values{
+		"filters": []string{string(filtersBytes)},
+	}, &containers)
+	return containers, err
+}
+
+type containerDetails struct {
+	Config struct {
+		Tty bool `json:"Tty"`
+	} `json:"Config"`
+}
+
+func (e *engineClient) inspectContainer(ctx context.Context, id string) (containerDetails, error) {
+	var details containerDetails
+	err := e.getJSON(ctx, "/containers/"+url.PathEscape(id)+"/json", nil, &details)
+	return details, err
+}
+
+// followLogs opens a stream of the logs of a container written after since,
+// which is a unix timestamp.
+func (e *engineClient) followLogs(ctx context.Context, id string, stdout, stderr bool, since int64) (io.ReadCloser, error) {
+	res, err := e.get(ctx, "/containers/"+url.PathEscape(id)+"/logs", url.Values{
+		"follow": []string{"1"},
+		"stdout": []string{strconv.FormatBool(stdout)},
+		"stderr": []string{strconv.FormatBool(stderr)},
+		"since":  []string{strconv.FormatInt(since, 10)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+//------------------------------------------------------------------------------
+
+const (
+	streamStdout = "stdout"
+	streamStderr = "stderr"
+)
+
+// demuxLogs reads a multiplexed log stream, where each frame is prefixed with
+// an eight byte header identifying the stream and the frame size, and calls fn
+// for each complete line. Lines from stdout and stderr are buffered
+// separately as frames do not necessarily align with line boundaries.
+func demuxLogs(r io.Reader, maxLine int, fn func(stream string, line []byte) error) error {
+	var header [8]byte
+	var pending [3][]byte
+
+	flush := func(i int, stream string, all bool) error {
+		for {
+			idx := bytes.IndexByte(pending[i], '\n')
+			if idx < 0 {
+				break
+			}
+			if err := fn(stream, pending[i][:idx]); err != nil {
+				return err
+			}
+			pending[i] = pending[i][idx+1:]
+		}
+		if len(pending[i]) > 0 && (all || len(pending[i]) >= maxLine) {
+			if err := fn(stream, pending[i]); err != nil {
+				return err
+			}
+			pending[i] = nil
+		}
+		return nil
+	}
+
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				if err := flush(1, streamStdout, true); err != nil {
+					return err
+				}
+				return flush(2, streamStderr, true)
+			}
+			return err
+		}
+
+		size := binary.BigEndian.Uint32(header[4:])
+		frame := make([]byte, size)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return err
+		}
+
+		var stream string
+		switch header[0] {
+		case 1:
+			stream = streamStdout
+		case 2:
+			stream = streamStderr
+		default:
+			// Frames for stdin are never expected from a logs request.
+			continue
+		}
+
+		i := int(header[0])
+		pending[i] = append(pending[i], frame...)
+		if err := flush(i, stream, false); err != nil {
+			return err
+		}
+	}
+}
+
+// rawLogs reads a raw log stream, as returned for containers with a TTY, and
+// calls fn for each line. Lines longer than maxLine are split into chunks of
+// maxLine bytes.
+func rawLogs(r io.Reader, maxLine int, fn func(stream string, line []byte) error) error {
+	scanner := bufio.NewScanner(r)
+
+	// The buffer is allowed to grow one byte beyond the maximum line length in
+	// order to tell whether a full buffer ends with a line break.
+	scanner.Buffer(nil, maxLine+1)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if len(data) > maxLine && bytes.IndexByte(data[:maxLine+1], '\n') < 0 {
+			return maxLine, data[:maxLine], nil
+		}
+		return bufio.ScanLines(data, atEOF)
+	})
+	for scanner.Scan() {
+		line := make([]byte, len(scanner.Bytes()))
+		copy(line, scanner.Bytes())
+		if err := fn(streamStdout, line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
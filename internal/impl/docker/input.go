@@ -0,0 +1,334 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+func dockerInputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Services").
+		Summary("Follows the log streams of running Docker containers via the Docker Engine API.").
+		Description(`
+Containers are discovered periodically by listing the running containers of the Docker daemon, optionally filtered by labels. Each discovered container has its logs followed until it stops, and each line written to the logs is consumed as a message.
+
+Logs are consumed from the point at which this input starts, or from the creation of the container if it started afterwards, and therefore lines written to a container before Benthos was running are not consumed. Since the Docker daemon has no concept of acknowledgements log lines cannot be redelivered after a restart.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- docker_container_id
+- docker_container_name
+- docker_container_image
+- docker_stream
+- docker_label_<key> (for each container label)
+`+"```"+`
+
+Container labels are added with the prefix `+"`docker_label_`"+`, and therefore a container labelled `+"`com.example.team=foo`"+` has the metadata field `+"`docker_label_com.example.team`"+` set to `+"`foo`"+`.
+
+The field `+"`docker_stream`"+` is either `+"`stdout` or `stderr`"+`. Containers with a TTY allocated do not separate their streams and therefore all of their lines are labelled `+"`stdout`"+`.
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#metadata).`).
+		Field(service.NewStringField("host").
+			Description("The address of the Docker daemon. Both unix sockets and TCP addresses are supported.").
+			Default("unix:///var/run/docker.sock").
+			Example("unix:///var/run/docker.sock").
+			Example("tcp://localhost:2375")).
+		Field(service.NewStringListField("labels").
+			Description("A list of label selectors, in the form `key` or `key=value`, that a container must match in order to have its logs consumed. When empty the logs of all running containers are consumed.").
+			Default([]string{}).
+			Example([]string{"logging=enabled"}).
+			Example([]string{"com.docker.compose.project=foo", "tier=backend"})).
+		Field(service.NewBoolField("stdout").
+			Description("Whether to consume lines written to the stdout stream of containers.").
+			Default(true).
+			Advanced()).
+		Field(service.NewBoolField("stderr").
+			Description("Whether to consume lines written to the stderr stream of containers.").
+			Default(true).
+			Advanced()).
+		Field(service.NewStringField("discovery_interval").
+			Description("The period between attempts to discover newly started containers.").
+			Default("10s").
+			Advanced()).
+		Field(service.NewIntField("max_buffer").
+			Description("The maximum size of an individual log line, longer lines are split.").
+			Default(1000000).
+			Advanced())
+}
+
+func init() {
+	err := service.RegisterInput(
+		"docker", dockerInputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			return newDockerInputFromConfig(conf, mgr.Logger())
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type dockerInput struct {
+	host              string
+	labels            []string
+	stdout            bool
+	stderr            bool
+	discoveryInterval time.Duration
+	maxBuffer         int
+
+	log *service.Logger
+
+	// The unix timestamp from which to follow logs of containers that were
+	// created before the input started.
+	startedAt int64
+
+	connMut  sync.Mutex
+	client   *engineClient
+	msgChan  chan *service.Message
+	shutdown func()
+	wg       sync.WaitGroup
+
+	// Tracks the containers currently being followed and, for those that
+	// were followed previously, the unix timestamp at which they ended.
+	followMut sync.Mutex
+	following map[string]bool
+	endedAt   map[string]int64
+}
+
+func newDockerInputFromConfig(conf *service.ParsedConfig, log *service.Logger) (*dockerInput, error) {
+	d := &dockerInput{
+		log:       log,
+		following: map[string]bool{},
+		endedAt:   map[string]int64{},
+	}
+
+	var err error
+	if d.host, err = conf.FieldString("host"); err != nil {
+		return nil, err
+	}
+	if d.labels, err = conf.FieldStringList("labels"); err != nil {
+		return nil, err
+	}
+	if d.stdout, err = conf.FieldBool("stdout"); err != nil {
+		return nil, err
+	}
+	if d.stderr, err = conf.FieldBool("stderr"); err != nil {
+		return nil, err
+	}
+	if !d.stdout && !d.stderr {
+		return nil, errors.New("at least one of stdout and stderr must be enabled")
+	}
+	intervalStr, err := conf.FieldString("discovery_interval")
+	if err != nil {
+		return nil, err
+	}
+	if d.discoveryInterval, err = time.ParseDuration(intervalStr); err != nil {
+		return nil, fmt.Errorf("failed to parse discovery_interval: %w", err)
+	}
+	if d.maxBuffer, err = conf.FieldInt("max_buffer"); err != nil {
+		return nil, err
+	}
+	if d.maxBuffer <= 0 {
+		return nil, fmt.Errorf("invalid max_buffer: %v", d.maxBuffer)
+	}
+	if _, err = newEngineClient(d.host); err != nil {
+		return nil, err
+	}
+	d.startedAt = time.Now().Unix()
+	return d, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (d *dockerInput) Connect(ctx context.Context) error {
+	d.connMut.Lock()
+	defer d.connMut.Unlock()
+
+	if d.client != nil {
+		return nil
+	}
+
+	client, err := newEngineClient(d.host)
+	if err != nil {
+		return err
+	}
+	if err := client.ping(ctx); err != nil {
+		return err
+	}
+
+	loopCtx, shutdown := context.WithCancel(context.Background())
+	msgChan := make(chan *service.Message)
+
+	d.client = client
+	d.msgChan = msgChan
+	d.shutdown = shutdown
+
+	d.wg.Add(1)
+	go d.discoveryLoop(loopCtx, client, msgChan)
+
+	d.log.Infof("Following the logs of Docker containers at: %v\n", d.host)
+	return nil
+}
+
+func (d *dockerInput) discoveryLoop(ctx context.Context, client *engineClient, msgChan chan<- *service.Message) {
+	defer d.wg.Done()
+
+	for {
+		containers, err := client.listContainers(ctx, d.labels)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			d.log.Errorf("Failed to list containers: %v\n", err)
+		}
+
+		for _, c := range containers {
+			if since, ok := d.claim(c); ok {
+				d.wg.Add(1)
+				go d.follow(ctx, client, c, since, msgChan)
+			}
+		}
+
+		select {
+		case <-time.After(d.discoveryInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// claim marks a container as being followed and returns the unix timestamp
+// from which its logs should be followed, or false if it is already being
+// followed.
+func (d *dockerInput) claim(c containerSummary) (int64, bool) {
+	d.followMut.Lock()
+	defer d.followMut.Unlock()
+
+	if d.following[c.ID] {
+		return 0, false
+	}
+	d.following[c.ID] = true
+
+	since := d.startedAt
+	if c.Created > since {
+		since = c.Created
+	}
+	if ended, ok := d.endedAt[c.ID]; ok && ended > since {
+		since = ended
+	}
+	return since, true
+}
+
+func (d *dockerInput) release(id string) {
+	d.followMut.Lock()
+	delete(d.following, id)
+	d.endedAt[id] = time.Now().Unix()
+	d.followMut.Unlock()
+}
+
+func (d *dockerInput) follow(ctx context.Context, client *engineClient, c containerSummary, since int64, msgChan chan<- *service.Message) {
+	defer d.wg.Done()
+	defer d.release(c.ID)
+
+	details, err := client.inspectContainer(ctx, c.ID)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.log.Errorf("Failed to inspect container %v: %v\n", c.name(), err)
+		}
+		return
+	}
+
+	logs, err := client.followLogs(ctx, c.ID, d.stdout, d.stderr, since)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.log.Errorf("Failed to follow logs of container %v: %v\n", c.name(), err)
+		}
+		return
+	}
+	defer logs.Close()
+
+	d.log.Debugf("Following logs of container %v\n", c.name())
+
+	emit := func(stream string, line []byte) error {
+		msg := service.NewMessage(line)
+		msg.MetaSet("docker_container_id", c.ID)
+		msg.MetaSet("docker_container_name", c.name())
+		msg.MetaSet("docker_container_image", c.Image)
+		msg.MetaSet("docker_stream", stream)
+		for k, v := range c.Labels {
+			msg.MetaSet("docker_label_"+k, v)
+		}
+		select {
+		case msgChan <- msg:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	if details.Config.Tty {
+		err = rawLogs(logs, d.maxBuffer, emit)
+	} else {
+		err = demuxLogs(logs, d.maxBuffer, emit)
+	}
+	if err != nil && ctx.Err() == nil {
+		d.log.Errorf("Failed to read logs of container %v: %v\n", c.name(), err)
+	}
+	d.log.Debugf("Stopped following logs of container %v\n", c.name())
+}
+
+func (d *dockerInput) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	d.connMut.Lock()
+	msgChan := d.msgChan
+	d.connMut.Unlock()
+
+	if msgChan == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+
+	select {
+	case msg := <-msgChan:
+		return msg, func(ctx context.Context, err error) error {
+			// Log lines cannot be redelivered by the Docker daemon.
+			return nil
+		}, nil
+	case <-ctx.Done():
+	}
+	return nil, nil, ctx.Err()
+}
+
+func (d *dockerInput) Close(ctx context.Context) error {
+	d.connMut.Lock()
+	if d.shutdown != nil {
+		d.shutdown()
+		d.shutdown = nil
+	}
+	d.client = nil
+	d.msgChan = nil
+	d.connMut.Unlock()
+
+	waitChan := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(waitChan)
+	}()
+
+	select {
+	case <-waitChan:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func logFrame(stream byte, data string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(data)))
+	return append(header, data...)
+}
+
+func TestDemuxLogs(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(logFrame(1, "foo\nba"))
+	buf.Write(logFrame(2, "err1\n"))
+	buf.Write(logFrame(1, "r\nbaz"))
+	buf.Write(logFrame(2, "err2"))
+
+	type line struct {
+		stream, content string
+	}
+	var lines []line
+	require.NoError(t, demuxLogs(&buf, 1024, func(stream string, b []byte) error {
+		lines = append(lines, line{stream, string(b)})
+		return nil
+	}))
+
+	assert.Equal(t, []line{
+		{"stdout", "foo"},
+		{"stderr", "err1"},
+		{"stdout", "bar"},
+		{"stdout", "baz"},
+		{"stderr", "err2"},
+	}, lines)
+}
+
+func TestDemuxLogsMaxBuffer(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(logFrame(1, "abcdef"))
+	buf.Write(logFrame(1, "gh\n"))
+
+	var lines []string
+	require.NoError(t, demuxLogs(&buf, 4, func(stream string, b []byte) error {
+		lines = append(lines, string(b))
+		return nil
+	}))
+
+	assert.Equal(t, []string{"abcdef", "gh"}, lines)
+}
+
+func TestRawLogs(t *testing.T) {
+	longLine := strings.Repeat("a", 100000)
+
+	var lines []string
+	require.NoError(t, rawLogs(strings.NewReader("foo\r\n"+longLine+"\nbar"), 65536, func(stream string, b []byte) error {
+		assert.Equal(t, streamStdout, stream)
+		lines = append(lines, string(b))
+		return nil
+	}))
+
+	assert.Equal(t, []string{"foo", longLine[:65536], longLine[65536:], "bar"}, lines)
+}
+
+func TestDockerInputFollowsContainers(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_ping":
+			w.Write([]byte("OK"))
+		case "/containers/json":
+			filters := map[string][]string{}
+			require.NoError(t, json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters))
+			assert.Equal(t, []string{"logging=enabled"}, filters["label"])
+			w.Write([]byte(`[
+  {"Id":"aaa","Names":["/foo"],"Image":"foo:latest","Created":1,"Labels":{"logging":"enabled","docker_stream":"nope"}},
+  {"Id":"bbb","Names":["/bar"],"Image":"bar:latest","Created":1,"Labels":{"logging":"enabled"}}
+]`))
+		case "/containers/aaa/json":
+			w.Write([]byte(`{"Config":{"Tty":false}}`))
+		case "/containers/bbb/json":
+			w.Write([]byte(`{"Config":{"Tty":true}}`))
+		case "/containers/aaa/logs":
+			assert.Equal(t, "1", r.URL.Query().Get("follow"))
+			w.Write(logFrame(1, "hello from foo\n"))
+			w.Write(logFrame(2, "oops from foo\n"))
+		case "/containers/bbb/logs":
+			w.Write([]byte("hello from bar\n"))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	d := &dockerInput{
+		host:              strings.Replace(ts.URL, "http://", "tcp://", 1),
+		labels:            []string{"logging=enabled"},
+		stdout:            true,
+		stderr:            true,
+		discoveryInterval: time.Hour,
+		maxBuffer:         1024,
+		following:         map[string]bool{},
+		endedAt:           map[string]int64{},
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, d.Connect(ctx))
+
+	received := map[string]string{}
+	for i := 0; i < 3; i++ {
+		msg, ackFn, err := d.Read(ctx)
+		require.NoError(t, err)
+		require.NoError(t, ackFn(ctx, nil))
+
+		b, err := msg.AsBytes()
+		require.NoError(t, err)
+
+		name, _ := msg.MetaGet("docker_container_name")
+		stream, _ := msg.MetaGet("docker_stream")
+		label, _ := msg.MetaGet("docker_label_logging")
+		assert.Equal(t, "enabled", label)
+
+		// Labels do not overwrite the fixed metadata fields.
+		if name == "foo" {
+			label, _ = msg.MetaGet("docker_label_docker_stream")
+			assert.Equal(t, "nope", label)
+		}
+
+		received[string(b)] = name + ":" + stream
+	}
+
+	assert.Equal(t, map[string]string{
+		"hello from foo": "foo:stdout",
+		"oops from foo":  "foo:stderr",
+		"hello from bar": "bar:stdout",
+	}, received)
+
+	require.NoError(t, d.Close(ctx))
+}
@@ -0,0 +1,3 @@
+// Package docker contains component implementations for consuming data from
+// the Docker Engine API.
+package docker
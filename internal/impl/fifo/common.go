@@ -0,0 +1,105 @@
+package fifo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+func commonFields(spec *service.ConfigSpec) *service.ConfigSpec {
+	return spec.
+		Field(service.NewStringField("path").
+			Description("The path of the named pipe.").
+			Example("/var/run/legacy.fifo")).
+		Field(service.NewStringField("delimiter").
+			Description("A string that separates messages within the pipe.").
+			Default("\n")).
+		Field(service.NewBoolField("create").
+			Description("Whether to create the named pipe when it does not exist.").
+			Default(false).
+			Advanced()).
+		Field(service.NewBoolField("nonblocking_open").
+			Description("Whether to open the named pipe without waiting for the other end to be opened by another process.").
+			Default(false).
+			Advanced())
+}
+
+type fifoConfig struct {
+	path        string
+	delim       []byte
+	create      bool
+	nonblocking bool
+}
+
+func fifoConfigFromParsed(conf *service.ParsedConfig) (c fifoConfig, err error) {
+	if c.path, err = conf.FieldString("path"); err != nil {
+		return
+	}
+	if c.path == "" {
+		err = errors.New("field path must not be empty")
+		return
+	}
+	var delim string
+	if delim, err = conf.FieldString("delimiter"); err != nil {
+		return
+	}
+	if delim == "" {
+		err = errors.New("field delimiter must not be empty")
+		return
+	}
+	c.delim = []byte(delim)
+	if c.create, err = conf.FieldBool("create"); err != nil {
+		return
+	}
+	c.nonblocking, err = conf.FieldBool("nonblocking_open")
+	return
+}
+
+// open opens the named pipe, creating it first when configured to. Opening a
+// named pipe normally blocks until the other end is also opened, and since
+// this cannot be interrupted a blocking open is abandoned when the context is
+// cancelled, where the pipe is closed again if it is opened later.
+func (c fifoConfig) open(ctx context.Context, flag int) (*os.File, error) {
+	if c.create {
+		if err := mkfifo(c.path); err != nil && !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create named pipe: %w", err)
+		}
+	}
+
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode()&os.ModeNamedPipe == 0 {
+		return nil, fmt.Errorf("file '%v' is not a named pipe", c.path)
+	}
+
+	if c.nonblocking {
+		return os.OpenFile(c.path, flag|openNonblock, 0)
+	}
+
+	type openResult struct {
+		f   *os.File
+		err error
+	}
+	resChan := make(chan openResult, 1)
+	go func() {
+		f, err := os.OpenFile(c.path, flag, 0)
+		resChan <- openResult{f, err}
+	}()
+
+	select {
+	case res := <-resChan:
+		return res.f, res.err
+	case <-ctx.Done():
+		go func() {
+			if res := <-resChan; res.f != nil {
+				res.f.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
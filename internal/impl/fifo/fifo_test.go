@@ -0,0 +1,124 @@
+// +build !windows,!wasm
+
+package fifo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readString(ctx context.Context, t *testing.T, in *fifoInput) string {
+	t.Helper()
+
+	msg, ackFn, err := in.Read(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ackFn(ctx, nil))
+
+	b, err := msg.AsBytes()
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestFifoInputOutput(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	conf := fifoConfig{
+		path:   filepath.Join(t.TempDir(), "pipe"),
+		delim:  []byte("\r\n"),
+		create: true,
+	}
+
+	in := &fifoInput{conf: conf, reopen: true}
+	out := &fifoOutput{conf: conf}
+
+	// Both ends block on open until the other is opened.
+	connErrChan := make(chan error)
+	go func() {
+		connErrChan <- in.Connect(ctx)
+	}()
+	require.NoError(t, out.Connect(ctx))
+	require.NoError(t, <-connErrChan)
+
+	for _, s := range []string{"foo", "bar\nbaz", ""} {
+		require.NoError(t, out.Write(ctx, service.NewMessage([]byte(s))))
+	}
+	assert.Equal(t, "foo", readString(ctx, t, in))
+	assert.Equal(t, "bar\nbaz", readString(ctx, t, in))
+	assert.Equal(t, "", readString(ctx, t, in))
+
+	// Once the writer closes the pipe the input reopens it, which waits for
+	// the next writer.
+	require.NoError(t, out.Close(ctx))
+	_, _, err := in.Read(ctx)
+	assert.Equal(t, service.ErrNotConnected, err)
+
+	go func() {
+		connErrChan <- in.Connect(ctx)
+	}()
+	require.NoError(t, out.Connect(ctx))
+	require.NoError(t, <-connErrChan)
+
+	require.NoError(t, out.Write(ctx, service.NewMessage([]byte("qux"))))
+	assert.Equal(t, "qux", readString(ctx, t, in))
+
+	// Once the reader closes the pipe the output must reconnect.
+	require.NoError(t, in.Close(ctx))
+	assert.Equal(t, service.ErrNotConnected, out.Write(ctx, service.NewMessage([]byte("quz"))))
+	require.NoError(t, out.Close(ctx))
+}
+
+func TestFifoInputNonblockingEOF(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	conf := fifoConfig{
+		path:        filepath.Join(t.TempDir(), "pipe"),
+		delim:       []byte("\n"),
+		create:      true,
+		nonblocking: true,
+	}
+
+	in := &fifoInput{conf: conf, reopen: false, pollInterval: time.Millisecond}
+	require.NoError(t, in.Connect(ctx))
+	defer in.Close(ctx)
+
+	w, err := os.OpenFile(conf.path, os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("foo\nbar"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "foo", readString(ctx, t, in))
+	assert.Equal(t, "bar", readString(ctx, t, in))
+
+	_, _, err = in.Read(ctx)
+	assert.Equal(t, service.ErrEndOfInput, err)
+}
+
+func TestFifoOutputNonblockingNoReader(t *testing.T) {
+	conf := fifoConfig{
+		path:        filepath.Join(t.TempDir(), "pipe"),
+		delim:       []byte("\n"),
+		create:      true,
+		nonblocking: true,
+	}
+
+	out := &fifoOutput{conf: conf}
+	require.Error(t, out.Connect(context.Background()))
+}
+
+func TestFifoNotAPipe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	in := &fifoInput{conf: fifoConfig{path: path, delim: []byte("\n")}}
+	require.EqualError(t, in.Connect(context.Background()), "file '"+path+"' is not a named pipe")
+}
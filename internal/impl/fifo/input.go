@@ -0,0 +1,200 @@
+package fifo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+func fifoInputConfig() *service.ConfigSpec {
+	return commonFields(service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Local").
+		Summary("Reads messages from a named pipe (FIFO), separated by a delimiter.").
+		Description(`
+Many legacy daemons can only write their output to a named pipe. Once all processes writing to the pipe have closed it the pipe is reopened, which waits for the next writer, unless `+"`reopen_on_eof`"+` is disabled in which case the input shuts down.
+
+Opening a named pipe for reading normally waits until another process opens it for writing. With `+"`nonblocking_open`"+` the pipe is opened immediately, and is checked for a writer every `+"`poll_interval`"+`.
+
+A trailing message that isn't followed by a delimiter is emitted once the writers have closed the pipe. Messages can not be redelivered by a named pipe and are therefore retried until they are acknowledged.`)).
+		Field(service.NewBoolField("reopen_on_eof").
+			Description("Whether to reopen the named pipe once all writers have closed it, rather than shutting down.").
+			Default(true)).
+		Field(service.NewStringField("poll_interval").
+			Description("When `nonblocking_open` is enabled, the period to wait before checking for a new writer once all writers have closed the named pipe.").
+			Default("100ms").
+			Advanced())
+}
+
+func init() {
+	err := service.RegisterInput(
+		"fifo", fifoInputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			i, err := newFifoInputFromConfig(conf, mgr.Logger())
+			if err != nil {
+				return nil, err
+			}
+			return service.AutoRetryNacks(i), nil
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type fifoInput struct {
+	conf         fifoConfig
+	reopen       bool
+	pollInterval time.Duration
+
+	log *service.Logger
+
+	mut     sync.Mutex
+	file    *os.File
+	reader  *bufio.Reader
+	partial []byte
+}
+
+func newFifoInputFromConfig(conf *service.ParsedConfig, log *service.Logger) (*fifoInput, error) {
+	f := &fifoInput{log: log}
+
+	var err error
+	if f.conf, err = fifoConfigFromParsed(conf); err != nil {
+		return nil, err
+	}
+	if f.reopen, err = conf.FieldBool("reopen_on_eof"); err != nil {
+		return nil, err
+	}
+	pollStr, err := conf.FieldString("poll_interval")
+	if err != nil {
+		return nil, err
+	}
+	if f.pollInterval, err = time.ParseDuration(pollStr); err != nil {
+		return nil, fmt.Errorf("failed to parse field 'poll_interval' as duration: %w", err)
+	}
+	return f, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (f *fifoInput) Connect(ctx context.Context) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	if f.file != nil {
+		return nil
+	}
+
+	file, err := f.conf.open(ctx, os.O_RDONLY)
+	if err != nil {
+		return err
+	}
+
+	f.file = file
+	f.reader = bufio.NewReader(file)
+
+	f.log.Infof("Reading messages from named pipe: %v\n", f.conf.path)
+	return nil
+}
+
+func (f *fifoInput) disconnect() {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+		f.reader = nil
+	}
+}
+
+// readMessage reads until the next delimiter, returning the message without
+// it. Data read before reaching the end of the pipe is retained and returned
+// along with io.EOF.
+func (f *fifoInput) readMessage(r *bufio.Reader) ([]byte, error) {
+	delimEnd := f.conf.delim[len(f.conf.delim)-1]
+	for {
+		b, err := r.ReadBytes(delimEnd)
+		f.partial = append(f.partial, b...)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.HasSuffix(f.partial, f.conf.delim) {
+			msg := f.partial[:len(f.partial)-len(f.conf.delim)]
+			f.partial = nil
+			return msg, nil
+		}
+	}
+}
+
+func (f *fifoInput) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	for {
+		f.mut.Lock()
+		reader := f.reader
+		f.mut.Unlock()
+
+		if reader == nil {
+			return nil, nil, service.ErrNotConnected
+		}
+
+		// The lock is not held while reading so that a blocked read can be
+		// interrupted by closing the pipe.
+		b, err := f.readMessage(reader)
+		if err == nil {
+			return service.NewMessage(b), func(ctx context.Context, err error) error {
+				return nil
+			}, nil
+		}
+		if !errors.Is(err, io.EOF) {
+			f.partial = nil
+			f.mut.Lock()
+			f.disconnect()
+			f.mut.Unlock()
+			if !errors.Is(err, os.ErrClosed) {
+				f.log.Errorf("Failed to read from named pipe: %v\n", err)
+			}
+			return nil, nil, service.ErrNotConnected
+		}
+
+		// All writers have closed the pipe.
+		if len(f.partial) > 0 {
+			b, f.partial = f.partial, nil
+			return service.NewMessage(b), func(ctx context.Context, err error) error {
+				return nil
+			}, nil
+		}
+		if !f.reopen {
+			return nil, nil, service.ErrEndOfInput
+		}
+		if f.conf.nonblocking {
+			// A pipe opened without blocking can continue to be read once a
+			// new writer opens it.
+			select {
+			case <-time.After(f.pollInterval):
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			}
+			continue
+		}
+
+		f.mut.Lock()
+		f.disconnect()
+		f.mut.Unlock()
+		return nil, nil, service.ErrNotConnected
+	}
+}
+
+func (f *fifoInput) Close(ctx context.Context) error {
+	f.mut.Lock()
+	f.disconnect()
+	f.mut.Unlock()
+	return nil
+}
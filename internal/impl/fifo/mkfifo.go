@@ -0,0 +1,11 @@
+// +build !windows,!wasm
+
+package fifo
+
+import "syscall"
+
+func mkfifo(path string) error {
+	return syscall.Mkfifo(path, 0o600)
+}
+
+const openNonblock = syscall.O_NONBLOCK
@@ -0,0 +1,11 @@
+// +build windows wasm
+
+package fifo
+
+import "errors"
+
+func mkfifo(path string) error {
+	return errors.New("named pipes are not supported on this platform")
+}
+
+const openNonblock = 0
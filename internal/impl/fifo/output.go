@@ -0,0 +1,119 @@
+package fifo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+func fifoOutputConfig() *service.ConfigSpec {
+	return commonFields(service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Local").
+		Summary("Writes messages to a named pipe (FIFO), each followed by a delimiter.").
+		Description(`
+Many legacy daemons can only read their input from a named pipe. When the process reading from the pipe closes it the pipe is reopened, which waits for the next reader, and the message that failed is written again.
+
+Opening a named pipe for writing normally waits until another process opens it for reading. With `+"`nonblocking_open`"+` opening the pipe fails immediately when there is no reader, and is attempted again with a backoff.
+
+Messages no larger than the pipe buffer of the platform (4096 bytes on Linux) are written atomically, and therefore a named pipe should only have multiple writers when messages are guaranteed to be smaller than this.`))
+}
+
+func init() {
+	err := service.RegisterOutput(
+		"fifo", fifoOutputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Output, int, error) {
+			o, err := newFifoOutputFromConfig(conf, mgr.Logger())
+			return o, 1, err
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type fifoOutput struct {
+	conf fifoConfig
+
+	log *service.Logger
+
+	mut  sync.Mutex
+	file *os.File
+}
+
+func newFifoOutputFromConfig(conf *service.ParsedConfig, log *service.Logger) (*fifoOutput, error) {
+	fConf, err := fifoConfigFromParsed(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &fifoOutput{conf: fConf, log: log}, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (f *fifoOutput) Connect(ctx context.Context) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	if f.file != nil {
+		return nil
+	}
+
+	file, err := f.conf.open(ctx, os.O_WRONLY)
+	if err != nil {
+		return err
+	}
+	f.file = file
+
+	f.log.Infof("Writing messages to named pipe: %v\n", f.conf.path)
+	return nil
+}
+
+func (f *fifoOutput) Write(ctx context.Context, msg *service.Message) error {
+	b, err := msg.AsBytes()
+	if err != nil {
+		return err
+	}
+
+	f.mut.Lock()
+	file := f.file
+	f.mut.Unlock()
+
+	if file == nil {
+		return service.ErrNotConnected
+	}
+
+	// The lock is not held while writing so that a write blocked on a full
+	// pipe can be interrupted by closing it.
+	if _, err = file.Write(append(b, f.conf.delim...)); err != nil {
+		f.mut.Lock()
+		if f.file == file {
+			f.file.Close()
+			f.file = nil
+		}
+		f.mut.Unlock()
+		if !errors.Is(err, syscall.EPIPE) && !errors.Is(err, os.ErrClosed) {
+			f.log.Errorf("Failed to write to named pipe: %v\n", err)
+		}
+		return service.ErrNotConnected
+	}
+	return nil
+}
+
+func (f *fifoOutput) Close(ctx context.Context) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+	return nil
+}
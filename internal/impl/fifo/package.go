@@ -0,0 +1,3 @@
+// Package fifo contains component implementations for reading from and writing
+// to named pipes.
+package fifo
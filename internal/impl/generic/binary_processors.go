@@ -0,0 +1,83 @@
+package generic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Jeffail/benthos/v3/internal/binjson"
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+func binaryProcessorConfig(name string) *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Parsing").
+		Summary(fmt.Sprintf("Converts messages between JSON and %v, a binary format that is able to represent the same documents with less overhead.", name)).
+		Description(fmt.Sprintf(`
+This is useful for bandwidth sensitive bridges, where messages can be converted into %v before being sent and converted back into JSON once received.
+
+Binary data within a %v document is converted into a base64 encoded string when converting to JSON.`, name, name)).
+		Field(service.NewStringField("operator").
+			Description(fmt.Sprintf("The operation to perform, where `to_json` converts %v documents into JSON and `from_json` converts JSON documents into %v.", name, name)).
+			Example("to_json").Example("from_json"))
+}
+
+func init() {
+	for format, name := range map[string]string{
+		"msgpack": "MessagePack",
+		"cbor":    "CBOR",
+	} {
+		format := format
+		err := service.RegisterProcessor(
+			format, binaryProcessorConfig(name),
+			func(conf *service.ParsedConfig, mgr *service.Resources) (service.Processor, error) {
+				operator, err := conf.FieldString("operator")
+				if err != nil {
+					return nil, err
+				}
+				return newBinaryProcessor(format, operator)
+			})
+
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type binaryProcessor struct {
+	format  string
+	convert func(format string, b []byte) ([]byte, error)
+}
+
+func newBinaryProcessor(format, operator string) (*binaryProcessor, error) {
+	b := &binaryProcessor{format: format}
+	switch operator {
+	case "to_json":
+		b.convert = binjson.ToJSON
+	case "from_json":
+		b.convert = binjson.FromJSON
+	default:
+		return nil, fmt.Errorf("operator not recognised: %v", operator)
+	}
+	return b, nil
+}
+
+func (b *binaryProcessor) Process(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
+	raw, err := msg.AsBytes()
+	if err != nil {
+		return nil, err
+	}
+	converted, err := b.convert(b.format, raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %v message: %w", b.format, err)
+	}
+	msg.SetBytes(converted)
+	return service.MessageBatch{msg}, nil
+}
+
+func (b *binaryProcessor) Close(ctx context.Context) error {
+	return nil
+}
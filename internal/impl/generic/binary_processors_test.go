@@ -0,0 +1,50 @@
+package generic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryProcessorsRoundTrip(t *testing.T) {
+	input := `{"id":"foo","values":[1,2.5,null,true]}`
+	for _, format := range []string{"msgpack", "cbor"} {
+		t.Run(format, func(t *testing.T) {
+			from, err := newBinaryProcessor(format, "from_json")
+			require.NoError(t, err)
+
+			to, err := newBinaryProcessor(format, "to_json")
+			require.NoError(t, err)
+
+			batch, err := from.Process(context.Background(), service.NewMessage([]byte(input)))
+			require.NoError(t, err)
+			require.Len(t, batch, 1)
+
+			b, err := batch[0].AsBytes()
+			require.NoError(t, err)
+			assert.NotEqual(t, input, string(b))
+
+			batch, err = to.Process(context.Background(), batch[0])
+			require.NoError(t, err)
+			require.Len(t, batch, 1)
+
+			b, err = batch[0].AsBytes()
+			require.NoError(t, err)
+			assert.JSONEq(t, input, string(b))
+		})
+	}
+}
+
+func TestBinaryProcessorsErrors(t *testing.T) {
+	_, err := newBinaryProcessor("msgpack", "nope")
+	require.Error(t, err)
+
+	proc, err := newBinaryProcessor("cbor", "from_json")
+	require.NoError(t, err)
+
+	_, err = proc.Process(context.Background(), service.NewMessage([]byte(`not json`)))
+	require.Error(t, err)
+}
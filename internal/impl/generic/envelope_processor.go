@@ -0,0 +1,146 @@
+package generic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/binjson"
+	"github.com/Jeffail/benthos/v3/public/service"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func envelopeProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Utility").
+		Summary("Wraps the payload of each message within a standard envelope containing the time at which it was wrapped, the hostname of the machine, a stream label and the metadata of the message.").
+		Description(`
+This allows all outputs to receive messages of a uniform schema regardless of the input that they were consumed from. An envelope has the following structure:
+
+`+"```json"+`
+{
+  "timestamp": "2021-06-01T10:00:00.123456789Z",
+  "hostname": "foo",
+  "stream": "bar",
+  "metadata": {
+    "kafka_topic": "baz"
+  },
+  "payload": {"the":"original payload"}
+}
+`+"```"+`
+
+When the payload of a message is a valid JSON document it is embedded within the envelope as a structured value, otherwise it is embedded as a string, or as binary data when the format is `+"`msgpack`"+`.`).
+		Field(service.NewStringField("format").
+			Description("The format in which to serialise the envelope.").
+			Default("json").
+			Example("json").Example("msgpack")).
+		Field(service.NewStringField("stream").
+			Description("A label identifying the stream that messages were consumed by, which is added to each envelope.").
+			Default("").
+			Example("orders"))
+}
+
+func init() {
+	err := service.RegisterProcessor(
+		"envelope", envelopeProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Processor, error) {
+			format, err := conf.FieldString("format")
+			if err != nil {
+				return nil, err
+			}
+			stream, err := conf.FieldString("stream")
+			if err != nil {
+				return nil, err
+			}
+			return newEnvelopeProcessor(format, stream)
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type envelope struct {
+	Timestamp string            `json:"timestamp" msgpack:"timestamp"`
+	Hostname  string            `json:"hostname" msgpack:"hostname"`
+	Stream    string            `json:"stream" msgpack:"stream"`
+	Metadata  map[string]string `json:"metadata" msgpack:"metadata"`
+	Payload   interface{}       `json:"payload" msgpack:"payload"`
+}
+
+type envelopeProcessor struct {
+	marshal  func(v interface{}) ([]byte, error)
+	msgpack  bool
+	stream   string
+	hostname string
+	now      func() time.Time
+}
+
+func newEnvelopeProcessor(format, stream string) (*envelopeProcessor, error) {
+	e := &envelopeProcessor{
+		stream: stream,
+		now:    time.Now,
+	}
+	switch format {
+	case "json":
+		e.marshal = json.Marshal
+	case "msgpack":
+		e.marshal = msgpack.Marshal
+		e.msgpack = true
+	default:
+		return nil, fmt.Errorf("format not recognised: %v", format)
+	}
+	var err error
+	if e.hostname, err = os.Hostname(); err != nil {
+		return nil, fmt.Errorf("failed to obtain hostname: %w", err)
+	}
+	return e, nil
+}
+
+func (e *envelopeProcessor) Process(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
+	raw, err := msg.AsBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	env := envelope{
+		Timestamp: e.now().UTC().Format(time.RFC3339Nano),
+		Hostname:  e.hostname,
+		Stream:    e.stream,
+		Metadata:  map[string]string{},
+	}
+	_ = msg.MetaWalk(func(k, v string) error {
+		env.Metadata[k] = v
+		return nil
+	})
+
+	if structured, err := msg.AsStructured(); err == nil {
+		if e.msgpack {
+			// Numbers are parsed as json.Number values, which would otherwise
+			// be encoded as strings.
+			structured = binjson.ConvertNumbers(structured)
+		}
+		env.Payload = structured
+	} else if e.msgpack {
+		env.Payload = raw
+	} else {
+		env.Payload = string(raw)
+	}
+
+	b, err := e.marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+	msg.SetBytes(b)
+	return service.MessageBatch{msg}, nil
+}
+
+func (e *envelopeProcessor) Close(ctx context.Context) error {
+	return nil
+}
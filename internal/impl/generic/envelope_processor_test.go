@@ -0,0 +1,96 @@
+package generic
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestEnvelopeProcessorJSON(t *testing.T) {
+	proc, err := newEnvelopeProcessor("json", "orders")
+	require.NoError(t, err)
+	proc.hostname = "foohost"
+	proc.now = func() time.Time {
+		return time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	}
+
+	for _, test := range []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    `{"id":"foo"}`,
+			expected: `{"timestamp":"2021-06-01T10:00:00Z","hostname":"foohost","stream":"orders","metadata":{"source":"bar"},"payload":{"id":"foo"}}`,
+		},
+		{
+			input:    `not json`,
+			expected: `{"timestamp":"2021-06-01T10:00:00Z","hostname":"foohost","stream":"orders","metadata":{"source":"bar"},"payload":"not json"}`,
+		},
+	} {
+		msg := service.NewMessage([]byte(test.input))
+		msg.MetaSet("source", "bar")
+
+		batch, err := proc.Process(context.Background(), msg)
+		require.NoError(t, err)
+		require.Len(t, batch, 1)
+
+		b, err := batch[0].AsBytes()
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, string(b))
+	}
+}
+
+func TestEnvelopeProcessorMsgpack(t *testing.T) {
+	proc, err := newEnvelopeProcessor("msgpack", "")
+	require.NoError(t, err)
+
+	batch, err := proc.Process(context.Background(), service.NewMessage([]byte("not json")))
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+
+	b, err := batch[0].AsBytes()
+	require.NoError(t, err)
+
+	var env map[string]interface{}
+	require.NoError(t, msgpack.Unmarshal(b, &env))
+
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	assert.Equal(t, hostname, env["hostname"])
+	assert.Equal(t, "", env["stream"])
+	assert.Equal(t, []byte("not json"), env["payload"])
+}
+
+func TestEnvelopeProcessorMsgpackNumbers(t *testing.T) {
+	proc, err := newEnvelopeProcessor("msgpack", "")
+	require.NoError(t, err)
+
+	batch, err := proc.Process(context.Background(), service.NewMessage([]byte(`{"count":5,"ratio":0.5,"nested":[1,2.5]}`)))
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+
+	b, err := batch[0].AsBytes()
+	require.NoError(t, err)
+
+	var env map[string]interface{}
+	require.NoError(t, msgpack.Unmarshal(b, &env))
+
+	payload, ok := env["payload"].(map[string]interface{})
+	require.True(t, ok, "payload is %T", env["payload"])
+
+	assert.Equal(t, int64(5), payload["count"])
+	assert.Equal(t, 0.5, payload["ratio"])
+	assert.Equal(t, []interface{}{int64(1), 2.5}, payload["nested"])
+}
+
+func TestEnvelopeProcessorBadFormat(t *testing.T) {
+	_, err := newEnvelopeProcessor("nope", "")
+	assert.EqualError(t, err, "format not recognised: nope")
+}
@@ -0,0 +1,103 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+func faultProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Utility").
+		Summary("Injects configurable errors and latency into a pipeline, allowing you to test the error handling of a config before real failures occur.").
+		Description(`
+Messages selected for an error are left unchanged but flagged as failed, and can be caught using the error handling methods outlined [here](/docs/configuration/error_handling). In order to inject faults into the writes of an output use the `+"[`fault` output](/docs/components/outputs/fault)"+` instead.
+
+This processor is intended for testing and should not be used in production.`).
+		Field(service.NewFloatField("error_percentage").
+			Description("The percentage of messages, from 0 to 100, to flag with an error.").
+			Default(0.0)).
+		Field(service.NewStringField("latency").
+			Description("An optional duration string of latency to add to the processing of each message.").
+			Default("").
+			Example("100ms").Example("1s"))
+}
+
+func init() {
+	err := service.RegisterProcessor(
+		"fault", faultProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Processor, error) {
+			errorPct, err := conf.FieldFloat("error_percentage")
+			if err != nil {
+				return nil, err
+			}
+			latencyStr, err := conf.FieldString("latency")
+			if err != nil {
+				return nil, err
+			}
+			return newFaultProcessor(errorPct, latencyStr)
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+var errFaultInjected = errors.New("injected fault")
+
+type faultProcessor struct {
+	errorPct float64
+	latency  time.Duration
+
+	randMut sync.Mutex
+	rand    *rand.Rand
+}
+
+func newFaultProcessor(errorPct float64, latencyStr string) (*faultProcessor, error) {
+	if errorPct < 0 || errorPct > 100 {
+		return nil, errors.New("error_percentage must be between 0 and 100")
+	}
+	var latency time.Duration
+	if latencyStr != "" {
+		var err error
+		if latency, err = time.ParseDuration(latencyStr); err != nil {
+			return nil, err
+		}
+	}
+	return &faultProcessor{
+		errorPct: errorPct,
+		latency:  latency,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+func (f *faultProcessor) Process(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
+	if f.latency > 0 {
+		select {
+		case <-time.After(f.latency):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	f.randMut.Lock()
+	fail := f.errorPct > 0 && f.rand.Float64()*100 < f.errorPct
+	f.randMut.Unlock()
+
+	if fail {
+		return nil, errFaultInjected
+	}
+	return service.MessageBatch{msg}, nil
+}
+
+func (f *faultProcessor) Close(ctx context.Context) error {
+	return nil
+}
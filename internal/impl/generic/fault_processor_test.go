@@ -0,0 +1,32 @@
+package generic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaultProcessor(t *testing.T) {
+	proc, err := newFaultProcessor(100, "")
+	require.NoError(t, err)
+
+	_, err = proc.Process(context.Background(), service.NewMessage([]byte("foo")))
+	assert.Equal(t, errFaultInjected, err)
+
+	proc, err = newFaultProcessor(0, "1ms")
+	require.NoError(t, err)
+
+	batch, err := proc.Process(context.Background(), service.NewMessage([]byte("foo")))
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+
+	b, err := batch[0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, "foo", string(b))
+
+	_, err = newFaultProcessor(101, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,424 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/bloblang/query"
+	"github.com/Jeffail/benthos/v3/public/bloblang"
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+func joinProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Windowing").
+		Summary("Correlates the messages of two tagged sources by a key within a window of time, emitting a merged record for each pair, and emitting records that were not matched once the window expires.").
+		Description(`
+Messages consumed by this processor are tagged as belonging to either the `+"[`left`](#left) or [`right`](#right)"+` source with the `+"[`source`](#source)"+` field, a common example being request and response events. Each message is held within a cache resource until a message of the opposite source with the same `+"[`key`](#key)"+` arrives, at which point a single merged record is emitted in their place of the form:
+
+`+"```json"+`
+{
+  "key": "foo",
+  "request": {"id":"foo","path":"/things"},
+  "response": {"id":"foo","status":200}
+}
+`+"```"+`
+
+Where the field names `+"`request` and `response`"+` are the values of `+"`left` and `right`"+` respectively. The contents of each message must be a JSON document, and the metadata of the merged record is that of the message that completed the pair.
+
+When multiple messages of the same source and key are waiting they are matched in the order they arrived. Messages are only matched when their timestamps are within the `+"[`timeout`](#timeout)"+` of each other.
+
+## Unmatched Records
+
+Once a message has waited for longer than the `+"`timeout`"+` it is emitted as a record containing only the source that arrived. The metadata field `+"`join_matched`"+` is set to `+"`true`"+` on merged records and `+"`false`"+` on unmatched records, which can be used to route them with a `+"[`switch` output](/docs/components/outputs/switch)"+`.
+
+This processor has no timer of its own, instead the progress of time is measured by the timestamps of the messages it processes. A message has expired once a message is processed with a timestamp beyond its own plus the timeout, and therefore when the stream of messages stops the final unmatched records are not emitted until more messages arrive.
+
+## Persistence
+
+Messages waiting to be matched, and the latest timestamp seen, are stored within the configured `+"[cache resource](/docs/components/caches/about)"+` rather than in memory, and therefore when the cache is persistent (such as `+"`redis`"+`) they survive restarts of the service. Messages are acknowledged once they have been stored within the cache.
+
+Multiple processors can share a cache as long as they each have a distinct `+"`cache_key_prefix`"+`.`).
+		Field(service.NewStringField("cache").
+			Description("The [cache resource](/docs/components/caches/about) to store messages waiting to be matched within.")).
+		Field(service.NewInterpolatedStringField("key").
+			Description("An interpolated string resulting in the key that correlates messages of each source.").
+			Example(`${! json("request_id") }`)).
+		Field(service.NewInterpolatedStringField("source").
+			Description("An interpolated string resulting in the source of each message, which must match either the value of `left` or `right`.").
+			Example(`${! meta("source") }`)).
+		Field(service.NewStringField("left").
+			Description("The name of the first source, which is also the field of merged records that contains its message.").
+			Example("request")).
+		Field(service.NewStringField("right").
+			Description("The name of the second source, which is also the field of merged records that contains its message.").
+			Example("response")).
+		Field(service.NewStringField("timeout").
+			Description("A duration string describing the maximum length of time between the timestamps of two messages for them to be matched, after which a message is emitted unmatched.").
+			Example("30s").Example("5m")).
+		Field(service.NewBloblangField("timestamp_mapping").
+			Description("A [Bloblang mapping](/docs/guides/bloblang/about) that provides the timestamp of each message. The result must either be a numerical unix time in seconds, or a string in ISO 8601 format. By default the time at which the message is processed is used.").
+			Default("root = now()").
+			Example("root = this.created_at")).
+		Field(service.NewStringField("cache_key_prefix").
+			Description("A prefix added to the keys of all entries stored within the cache.").
+			Default("join_").
+			Advanced()).
+		Example("Requests and Responses", `Given request and response events consumed from two different Kafka topics, each with a `+"`request_id`"+` field, we can tag each event with its source and emit a record for each request combined with its response, or alone if no response arrives within a minute:`,
+			`
+input:
+  broker:
+    inputs:
+      - kafka:
+          addresses: [ TODO ]
+          topics: [ requests ]
+          consumer_group: joiner
+        processors:
+          - bloblang: meta source = "request"
+      - kafka:
+          addresses: [ TODO ]
+          topics: [ responses ]
+          consumer_group: joiner
+        processors:
+          - bloblang: meta source = "response"
+
+pipeline:
+  processors:
+    - join:
+        cache: pending
+        key: ${! json("request_id") }
+        source: ${! meta("source") }
+        left: request
+        right: response
+        timeout: 1m
+
+cache_resources:
+  - label: pending
+    redis:
+      url: tcp://localhost:6379
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterProcessor(
+		"join", joinProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Processor, error) {
+			cacheName, err := conf.FieldString("cache")
+			if err != nil {
+				return nil, err
+			}
+			key, err := conf.FieldInterpolatedString("key")
+			if err != nil {
+				return nil, err
+			}
+			source, err := conf.FieldInterpolatedString("source")
+			if err != nil {
+				return nil, err
+			}
+			left, err := conf.FieldString("left")
+			if err != nil {
+				return nil, err
+			}
+			right, err := conf.FieldString("right")
+			if err != nil {
+				return nil, err
+			}
+			if left == right || left == "" || right == "" || left == "key" || right == "key" {
+				return nil, fmt.Errorf("left and right must be distinct names other than 'key', got '%v' and '%v'", left, right)
+			}
+			timeout, err := getDuration(conf, true, "timeout")
+			if err != nil {
+				return nil, err
+			}
+			tsMapping, err := conf.FieldBloblang("timestamp_mapping")
+			if err != nil {
+				return nil, err
+			}
+			prefix, err := conf.FieldString("cache_key_prefix")
+			if err != nil {
+				return nil, err
+			}
+			return newJoinProcessor(
+				func(ctx context.Context, fn func(c service.Cache)) error {
+					return mgr.AccessCache(ctx, cacheName, fn)
+				},
+				cacheName+"\x00"+prefix, prefix,
+				key, source, left, right, tsMapping, timeout,
+			), nil
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type joinEntry struct {
+	Timestamp int64       `json:"ts"`
+	Doc       interface{} `json:"doc"`
+}
+
+type joinPending struct {
+	Left  []joinEntry `json:"left,omitempty"`
+	Right []joinEntry `json:"right,omitempty"`
+}
+
+// joinIndexKey records a key with pending messages along with the earliest
+// timestamp of those messages, allowing keys with expired messages to be found
+// without reading each of them from the cache.
+type joinIndexKey struct {
+	Key      string `json:"key"`
+	Earliest int64  `json:"earliest"`
+}
+
+type joinIndex struct {
+	Watermark int64          `json:"watermark"`
+	Pending   []joinIndexKey `json:"pending"`
+}
+
+type joinProcessor struct {
+	accessCache func(ctx context.Context, fn func(c service.Cache)) error
+	lock        *sync.Mutex
+	prefix      string
+
+	key         *service.InterpolatedString
+	source      *service.InterpolatedString
+	left, right string
+	tsMapping   *bloblang.Executor
+	timeout     time.Duration
+}
+
+func newJoinProcessor(
+	accessCache func(ctx context.Context, fn func(c service.Cache)) error,
+	lockID, prefix string,
+	key, source *service.InterpolatedString,
+	left, right string,
+	tsMapping *bloblang.Executor,
+	timeout time.Duration,
+) *joinProcessor {
+	lock, _ := cacheStateLocks.LoadOrStore(lockID, &sync.Mutex{})
+	return &joinProcessor{
+		accessCache: accessCache,
+		lock:        lock.(*sync.Mutex),
+		prefix:      prefix,
+		key:         key,
+		source:      source,
+		left:        left,
+		right:       right,
+		tsMapping:   tsMapping,
+		timeout:     timeout,
+	}
+}
+
+func (j *joinProcessor) indexKey() string {
+	return j.prefix + "index"
+}
+
+func (j *joinProcessor) pendingKey(key string) string {
+	return j.prefix + "pending_" + key
+}
+
+func (j *joinProcessor) expired(e joinEntry, watermark int64) bool {
+	return e.Timestamp+int64(j.timeout) <= watermark
+}
+
+func (j *joinProcessor) Process(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
+	tsValue, err := mapStructured(msg, j.tsMapping)
+	if err != nil {
+		return nil, fmt.Errorf("timestamp mapping failed: %w", err)
+	}
+	ts, err := query.IGetTimestamp(tsValue)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse result of timestamp mapping as timestamp: %w", err)
+	}
+
+	isLeft := false
+	switch source := j.source.String(msg); source {
+	case j.left:
+		isLeft = true
+	case j.right:
+	default:
+		return nil, fmt.Errorf("message source '%v' does not match either '%v' or '%v'", source, j.left, j.right)
+	}
+
+	doc, err := msg.AsStructured()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse message as JSON: %w", err)
+	}
+	entry := joinEntry{Timestamp: ts.UnixNano(), Doc: doc}
+	key := j.key.String(msg)
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	var emitted service.MessageBatch
+	var cerr error
+	if err := j.accessCache(ctx, func(c service.Cache) {
+		var index joinIndex
+		if _, cerr = getJSON(ctx, c, j.indexKey(), &index); cerr != nil {
+			return
+		}
+		if entry.Timestamp > index.Watermark {
+			index.Watermark = entry.Timestamp
+		}
+
+		// Emit any messages that have expired, including those of the key of
+		// this message, which are removed from the pending state stored
+		// within the cache. Only keys containing an expired message, and the
+		// key of this message, are read from the cache.
+		var current joinPending
+		keys := index.Pending[:0]
+		for _, ik := range index.Pending {
+			k := ik.Key
+			if k != key && !j.expired(joinEntry{Timestamp: ik.Earliest}, index.Watermark) {
+				keys = append(keys, ik)
+				continue
+			}
+			var pending joinPending
+			if _, cerr = getJSON(ctx, c, j.pendingKey(k), &pending); cerr != nil {
+				return
+			}
+			var expiredLeft, expiredRight []joinEntry
+			expiredLeft, pending.Left = j.splitExpired(pending.Left, index.Watermark)
+			expiredRight, pending.Right = j.splitExpired(pending.Right, index.Watermark)
+			for _, e := range expiredLeft {
+				emitted = append(emitted, j.unmatchedMessage(k, j.left, e))
+			}
+			for _, e := range expiredRight {
+				emitted = append(emitted, j.unmatchedMessage(k, j.right, e))
+			}
+			if k == key {
+				current = pending
+				continue
+			}
+			if len(pending.Left) == 0 && len(pending.Right) == 0 {
+				if cerr = c.Delete(ctx, j.pendingKey(k)); cerr != nil && !errors.Is(cerr, service.ErrKeyNotFound) {
+					return
+				}
+				cerr = nil
+				continue
+			}
+			if len(expiredLeft) > 0 || len(expiredRight) > 0 {
+				if cerr = setJSON(ctx, c, j.pendingKey(k), pending); cerr != nil {
+					return
+				}
+			}
+			keys = append(keys, joinIndexKey{Key: k, Earliest: pending.earliest()})
+		}
+		index.Pending = keys
+
+		// Messages older than the timeout are emitted immediately as they
+		// would have already expired.
+		if j.expired(entry, index.Watermark) {
+			if isLeft {
+				emitted = append(emitted, j.unmatchedMessage(key, j.left, entry))
+			} else {
+				emitted = append(emitted, j.unmatchedMessage(key, j.right, entry))
+			}
+		} else {
+			opposite := &current.Left
+			if isLeft {
+				opposite = &current.Right
+			}
+			matched := false
+			for i, e := range *opposite {
+				if diff := e.Timestamp - entry.Timestamp; diff >= int64(j.timeout) || -diff >= int64(j.timeout) {
+					continue
+				}
+				*opposite = append((*opposite)[:i], (*opposite)[i+1:]...)
+				if isLeft {
+					emitted = append(emitted, j.matchedMessage(msg, key, entry, e))
+				} else {
+					emitted = append(emitted, j.matchedMessage(msg, key, e, entry))
+				}
+				matched = true
+				break
+			}
+			if !matched {
+				if isLeft {
+					current.Left = append(current.Left, entry)
+				} else {
+					current.Right = append(current.Right, entry)
+				}
+			}
+		}
+
+		if len(current.Left) == 0 && len(current.Right) == 0 {
+			if cerr = c.Delete(ctx, j.pendingKey(key)); cerr != nil && !errors.Is(cerr, service.ErrKeyNotFound) {
+				return
+			}
+			cerr = nil
+		} else {
+			if cerr = setJSON(ctx, c, j.pendingKey(key), current); cerr != nil {
+				return
+			}
+			index.Pending = append(index.Pending, joinIndexKey{Key: key, Earliest: current.earliest()})
+		}
+		cerr = setJSON(ctx, c, j.indexKey(), index)
+	}); err != nil {
+		return nil, err
+	}
+	if cerr != nil {
+		return nil, cerr
+	}
+	return emitted, nil
+}
+
+// earliest returns the lowest timestamp of all pending entries.
+func (p joinPending) earliest() int64 {
+	var ts int64
+	found := false
+	for _, entries := range [][]joinEntry{p.Left, p.Right} {
+		for _, e := range entries {
+			if !found || e.Timestamp < ts {
+				ts, found = e.Timestamp, true
+			}
+		}
+	}
+	return ts
+}
+
+// splitExpired separates the entries that have expired according to a
+// watermark from those that have not.
+func (j *joinProcessor) splitExpired(entries []joinEntry, watermark int64) (expired, remaining []joinEntry) {
+	for _, e := range entries {
+		if j.expired(e, watermark) {
+			expired = append(expired, e)
+		} else {
+			remaining = append(remaining, e)
+		}
+	}
+	return
+}
+
+func (j *joinProcessor) matchedMessage(from *service.Message, key string, left, right joinEntry) *service.Message {
+	msg := from.Copy()
+	msg.SetStructured(map[string]interface{}{
+		"key":   key,
+		j.left:  left.Doc,
+		j.right: right.Doc,
+	})
+	msg.MetaSet("join_matched", "true")
+	return msg
+}
+
+func (j *joinProcessor) unmatchedMessage(key, source string, e joinEntry) *service.Message {
+	msg := service.NewMessage(nil)
+	msg.SetStructured(map[string]interface{}{
+		"key":  key,
+		source: e.Doc,
+	})
+	msg.MetaSet("join_matched", "false")
+	return msg
+}
+
+func (j *joinProcessor) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,142 @@
+package generic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/bloblang"
+	"github.com/Jeffail/benthos/v3/public/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestJoin(t *testing.T, cache service.Cache) *joinProcessor {
+	t.Helper()
+
+	key, err := service.NewInterpolatedString(`${! json("id") }`)
+	require.NoError(t, err)
+	source, err := service.NewInterpolatedString(`${! meta("source") }`)
+	require.NoError(t, err)
+	tsMapping, err := bloblang.Parse("root = this.ts")
+	require.NoError(t, err)
+
+	return newJoinProcessor(func(ctx context.Context, fn func(c service.Cache)) error {
+		fn(cache)
+		return nil
+	}, t.Name(), "test_", key, source, "req", "res", tsMapping, time.Minute)
+}
+
+type joinResult struct {
+	content string
+	matched string
+}
+
+func processJoin(t *testing.T, proc *joinProcessor, source, input string) []joinResult {
+	t.Helper()
+
+	msg := service.NewMessage([]byte(input))
+	msg.MetaSet("source", source)
+
+	batch, err := proc.Process(context.Background(), msg)
+	require.NoError(t, err)
+
+	var results []joinResult
+	for _, msg := range batch {
+		b, err := msg.AsBytes()
+		require.NoError(t, err)
+		matched, _ := msg.MetaGet("join_matched")
+		results = append(results, joinResult{content: string(b), matched: matched})
+	}
+	return results
+}
+
+func TestJoinMatched(t *testing.T) {
+	cache := &fakeWindowCache{items: map[string][]byte{}}
+	proc := newTestJoin(t, cache)
+
+	assert.Empty(t, processJoin(t, proc, "req", `{"id":"a","ts":"2021-08-07T10:00:00Z"}`))
+	assert.Empty(t, processJoin(t, proc, "req", `{"id":"b","ts":"2021-08-07T10:00:05Z"}`))
+
+	// A new processor sharing the cache continues from the persisted state.
+	proc = newTestJoin(t, cache)
+
+	assert.Equal(t, []joinResult{
+		{
+			content: `{"key":"b","req":{"id":"b","ts":"2021-08-07T10:00:05Z"},"res":{"id":"b","ts":"2021-08-07T10:00:10Z"}}`,
+			matched: "true",
+		},
+	}, processJoin(t, proc, "res", `{"id":"b","ts":"2021-08-07T10:00:10Z"}`))
+}
+
+func TestJoinUnmatched(t *testing.T) {
+	cache := &fakeWindowCache{items: map[string][]byte{}}
+	proc := newTestJoin(t, cache)
+
+	assert.Empty(t, processJoin(t, proc, "req", `{"id":"a","ts":"2021-08-07T10:00:00Z"}`))
+	assert.Empty(t, processJoin(t, proc, "res", `{"id":"b","ts":"2021-08-07T10:00:30Z"}`))
+
+	assert.Equal(t, []joinResult{
+		{content: `{"key":"a","req":{"id":"a","ts":"2021-08-07T10:00:00Z"}}`, matched: "false"},
+	}, processJoin(t, proc, "req", `{"id":"c","ts":"2021-08-07T10:01:10Z"}`))
+
+	// Messages that have already expired are emitted immediately.
+	assert.Equal(t, []joinResult{
+		{content: `{"key":"a","res":{"id":"a","ts":"2021-08-07T10:00:05Z"}}`, matched: "false"},
+	}, processJoin(t, proc, "res", `{"id":"a","ts":"2021-08-07T10:00:05Z"}`))
+
+	assert.Equal(t, []joinResult{
+		{content: `{"key":"b","res":{"id":"b","ts":"2021-08-07T10:00:30Z"}}`, matched: "false"},
+		{content: `{"key":"c","req":{"id":"c","ts":"2021-08-07T10:01:10Z"}}`, matched: "false"},
+	}, processJoin(t, proc, "req", `{"id":"d","ts":"2021-08-07T10:05:00Z"}`))
+
+	// Only the pending message and the index remain.
+	assert.Len(t, cache.items, 2)
+}
+
+type countingJoinCache struct {
+	*fakeWindowCache
+	gets []string
+}
+
+func (c *countingJoinCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.gets = append(c.gets, key)
+	return c.fakeWindowCache.Get(ctx, key)
+}
+
+func TestJoinOnlyReadsExpiredKeys(t *testing.T) {
+	cache := &countingJoinCache{fakeWindowCache: &fakeWindowCache{items: map[string][]byte{}}}
+	proc := newTestJoin(t, cache)
+
+	assert.Empty(t, processJoin(t, proc, "req", `{"id":"a","ts":"2021-08-07T10:00:00Z"}`))
+	assert.Empty(t, processJoin(t, proc, "req", `{"id":"b","ts":"2021-08-07T10:00:30Z"}`))
+	assert.Empty(t, processJoin(t, proc, "req", `{"id":"c","ts":"2021-08-07T10:00:40Z"}`))
+
+	// None of the pending keys have expired, so only the index and the key of
+	// the message are read.
+	cache.gets = nil
+	assert.Equal(t, []joinResult{
+		{
+			content: `{"key":"c","req":{"id":"c","ts":"2021-08-07T10:00:40Z"},"res":{"id":"c","ts":"2021-08-07T10:00:50Z"}}`,
+			matched: "true",
+		},
+	}, processJoin(t, proc, "res", `{"id":"c","ts":"2021-08-07T10:00:50Z"}`))
+	assert.Equal(t, []string{"test_index", "test_pending_c"}, cache.gets)
+
+	// Only the pending key that has expired is read.
+	cache.gets = nil
+	assert.Equal(t, []joinResult{
+		{content: `{"key":"a","req":{"id":"a","ts":"2021-08-07T10:00:00Z"}}`, matched: "false"},
+	}, processJoin(t, proc, "req", `{"id":"e","ts":"2021-08-07T10:01:10Z"}`))
+	assert.Equal(t, []string{"test_index", "test_pending_a"}, cache.gets)
+}
+
+func TestJoinUnknownSource(t *testing.T) {
+	proc := newTestJoin(t, &fakeWindowCache{items: map[string][]byte{}})
+
+	msg := service.NewMessage([]byte(`{"id":"a","ts":"2021-08-07T10:00:00Z"}`))
+	msg.MetaSet("source", "nope")
+
+	_, err := proc.Process(context.Background(), msg)
+	assert.EqualError(t, err, "message source 'nope' does not match either 'req' or 'res'")
+}
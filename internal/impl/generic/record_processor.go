@@ -0,0 +1,115 @@
+package generic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+func recordProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Utility").
+		Summary("Records the raw contents, metadata and arrival time of each message batch to a capture file, leaving the messages unchanged.").
+		Description(`
+Capture files can be read by the `+"[`replay` input](/docs/components/inputs/replay)"+` in order to reproduce a stream of messages locally with its original pacing, which is useful when investigating an issue seen in production.
+
+In order to capture messages exactly as they were consumed this processor should be placed at the beginning of the [input processors](/docs/components/inputs/about). Each message batch is written to the file as a single line of JSON, and the file is appended to when it already exists.`).
+		Field(service.NewStringField("path").
+			Description("The path of the capture file to write.").
+			Example("./capture.jsonl"))
+}
+
+func init() {
+	err := service.RegisterBatchProcessor(
+		"record", recordProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchProcessor, error) {
+			path, err := conf.FieldString("path")
+			if err != nil {
+				return nil, err
+			}
+			return newRecordProcessor(path)
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// capturedPart is a single message within a capture file.
+type capturedPart struct {
+	Content  []byte            `json:"content"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// capturedBatch is a single line of a capture file, containing a message batch
+// and the time at which it was recorded as a unix timestamp in nanoseconds.
+type capturedBatch struct {
+	Timestamp int64          `json:"timestamp"`
+	Parts     []capturedPart `json:"parts"`
+}
+
+type recordProcessor struct {
+	mut  sync.Mutex
+	file *os.File
+	now  func() time.Time
+}
+
+func newRecordProcessor(path string) (*recordProcessor, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open capture file: %w", err)
+	}
+	return &recordProcessor{
+		file: file,
+		now:  time.Now,
+	}, nil
+}
+
+func (r *recordProcessor) ProcessBatch(ctx context.Context, batch service.MessageBatch) ([]service.MessageBatch, error) {
+	captured := capturedBatch{
+		Timestamp: r.now().UnixNano(),
+		Parts:     make([]capturedPart, len(batch)),
+	}
+	for i, msg := range batch {
+		content, err := msg.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		part := capturedPart{Content: content}
+		_ = msg.MetaWalk(func(k, v string) error {
+			if part.Metadata == nil {
+				part.Metadata = map[string]string{}
+			}
+			part.Metadata[k] = v
+			return nil
+		})
+		captured.Parts[i] = part
+	}
+
+	line, err := json.Marshal(captured)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write to capture file: %w", err)
+	}
+	return []service.MessageBatch{batch}, nil
+}
+
+func (r *recordProcessor) Close(ctx context.Context) error {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return r.file.Close()
+}
@@ -0,0 +1,155 @@
+package generic
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+func replayInputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Utility").
+		Summary("Reads message batches from a capture file written by the `record` processor, reproducing the pacing at which they were originally recorded.").
+		Description(`
+This input is useful for reproducing production issues locally. Messages are emitted with the contents and metadata they were recorded with, and the gaps between batches match those observed at the time of recording, divided by the ` + "`speed`" + ` multiplier.
+
+Once the end of the capture file is reached the pipeline will gracefully terminate.`).
+		Field(service.NewStringField("path").
+			Description("The path of the capture file to read.").
+			Example("./capture.jsonl")).
+		Field(service.NewFloatField("speed").
+			Description("A multiplier applied to the original pacing of messages, where `2` replays messages twice as fast as they were recorded. Set to `0` in order to replay messages as fast as possible.").
+			Default(1.0).
+			Example(2.0).Example(0.5))
+}
+
+func init() {
+	err := service.RegisterBatchInput(
+		"replay", replayInputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchInput, error) {
+			path, err := conf.FieldString("path")
+			if err != nil {
+				return nil, err
+			}
+			speed, err := conf.FieldFloat("speed")
+			if err != nil {
+				return nil, err
+			}
+			if speed < 0 {
+				return nil, errors.New("speed must not be negative")
+			}
+			return &replayInput{path: path, speed: speed}, nil
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type replayInput struct {
+	path  string
+	speed float64
+
+	file   *os.File
+	reader *bufio.Reader
+
+	pending       *capturedBatch
+	firstRecorded int64
+	firstReplayed time.Time
+}
+
+func (r *replayInput) Connect(ctx context.Context) error {
+	if r.file != nil {
+		return nil
+	}
+	file, err := os.Open(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to open capture file: %w", err)
+	}
+	r.file = file
+	r.reader = bufio.NewReader(file)
+	return nil
+}
+
+func (r *replayInput) readNext() (*capturedBatch, error) {
+	var line []byte
+	for len(line) == 0 {
+		var err error
+		if line, err = r.reader.ReadBytes('\n'); err != nil {
+			if !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			if len(line) == 0 {
+				return nil, service.ErrEndOfInput
+			}
+			break
+		}
+		line = line[:len(line)-1]
+	}
+
+	var captured capturedBatch
+	if err := json.Unmarshal(line, &captured); err != nil {
+		return nil, fmt.Errorf("failed to parse capture file line: %w", err)
+	}
+	return &captured, nil
+}
+
+func (r *replayInput) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	if r.reader == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+
+	// A batch is kept pending until its scheduled time is reached so that it
+	// isn't lost when the context is cancelled during the wait.
+	if r.pending == nil {
+		captured, err := r.readNext()
+		if err != nil {
+			return nil, nil, err
+		}
+		r.pending = captured
+	}
+	captured := r.pending
+
+	if r.firstReplayed.IsZero() {
+		r.firstRecorded = captured.Timestamp
+		r.firstReplayed = time.Now()
+	} else if r.speed > 0 {
+		offset := time.Duration(float64(captured.Timestamp-r.firstRecorded) / r.speed)
+		if wait := time.Until(r.firstReplayed.Add(offset)); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			}
+		}
+	}
+	r.pending = nil
+
+	batch := make(service.MessageBatch, len(captured.Parts))
+	for i, part := range captured.Parts {
+		msg := service.NewMessage(part.Content)
+		for k, v := range part.Metadata {
+			msg.MetaSet(k, v)
+		}
+		batch[i] = msg
+	}
+	return batch, func(context.Context, error) error { return nil }, nil
+}
+
+func (r *replayInput) Close(ctx context.Context) error {
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Close()
+}
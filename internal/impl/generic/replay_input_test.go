@@ -0,0 +1,93 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+
+	rec, err := newRecordProcessor(path)
+	require.NoError(t, err)
+
+	start := time.Now()
+	offsets := []time.Duration{0, time.Millisecond * 200, time.Millisecond * 400}
+	for i, offset := range offsets {
+		rec.now = func() time.Time { return start.Add(offset) }
+
+		msg := service.NewMessage([]byte("foo"))
+		msg.MetaSet("index", string(rune('a'+i)))
+		batches, err := rec.ProcessBatch(context.Background(), service.MessageBatch{msg, service.NewMessage([]byte("bar"))})
+		require.NoError(t, err)
+		require.Len(t, batches, 1)
+		assert.Len(t, batches[0], 2)
+	}
+	require.NoError(t, rec.Close(context.Background()))
+
+	replay := &replayInput{path: path, speed: 2}
+	require.NoError(t, replay.Connect(context.Background()))
+
+	replayStart := time.Now()
+	for i := range offsets {
+		batch, ackFn, err := replay.ReadBatch(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, ackFn(context.Background(), nil))
+		require.Len(t, batch, 2)
+
+		b, err := batch[0].AsBytes()
+		require.NoError(t, err)
+		assert.Equal(t, "foo", string(b))
+
+		v, _ := batch[0].MetaGet("index")
+		assert.Equal(t, string(rune('a'+i)), v)
+
+		b, err = batch[1].AsBytes()
+		require.NoError(t, err)
+		assert.Equal(t, "bar", string(b))
+	}
+	assert.GreaterOrEqual(t, int64(time.Since(replayStart)), int64(time.Millisecond*200))
+
+	_, _, err = replay.ReadBatch(context.Background())
+	assert.True(t, errors.Is(err, service.ErrEndOfInput))
+	require.NoError(t, replay.Close(context.Background()))
+}
+
+func TestReplayCancelledWait(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+
+	rec, err := newRecordProcessor(path)
+	require.NoError(t, err)
+
+	start := time.Now()
+	for _, offset := range []time.Duration{0, time.Hour} {
+		rec.now = func() time.Time { return start.Add(offset) }
+		_, err := rec.ProcessBatch(context.Background(), service.MessageBatch{service.NewMessage([]byte("foo"))})
+		require.NoError(t, err)
+	}
+	require.NoError(t, rec.Close(context.Background()))
+
+	replay := &replayInput{path: path, speed: 1}
+	require.NoError(t, replay.Connect(context.Background()))
+
+	_, _, err = replay.ReadBatch(context.Background())
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer done()
+	_, _, err = replay.ReadBatch(ctx)
+	require.Error(t, err)
+
+	// The batch is still pending, and replaying as fast as possible returns it.
+	replay.speed = 0
+	batch, _, err := replay.ReadBatch(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, batch, 1)
+}
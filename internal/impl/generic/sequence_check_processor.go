@@ -0,0 +1,273 @@
+package generic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+func sequenceCheckProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Utility").
+		Summary("Detects gaps and out of order arrivals within a sequence number assigned upstream to each message, counted separately for each key, and emits alerts to an output resource.").
+		Description(`
+The highest sequence number seen for each key is stored within a [cache resource](/docs/components/caches/about), and each message is compared against it. When the sequence number of a message is higher than the next expected number a gap is detected, and when it is lower than or equal to the highest number seen the message has arrived out of order (or is a duplicate).
+
+Messages are not modified other than the metadata field `+"`sequence_check`"+`, which is set to either `+"`ok`, `gap` or `out_of_order`"+`, and can therefore be filtered or routed downstream. The first message of each key is always considered `+"`ok`"+`. Messages with a sequence number that isn't an integer are flagged as failed, and can be handled using the methods outlined [here](/docs/configuration/error_handling).
+
+## Alerts
+
+When an `+"`alert_output`"+` is configured each gap or out of order arrival is also written to the [output resource](/docs/components/outputs/about) of that name as a JSON document of the form:
+
+`+"```json"+`
+{
+  "type": "gap",
+  "key": "foo",
+  "expected": 12,
+  "received": 15,
+  "missing": 3
+}
+`+"```"+`
+
+Where the field `+"`missing`"+` is only present for gaps. Failing to write an alert is logged but does not fail the message.`).
+		Field(service.NewStringField("cache").
+			Description("The [cache resource](/docs/components/caches/about) to store the highest sequence number seen for each key within.")).
+		Field(service.NewInterpolatedStringField("key").
+			Description("An optional key to check sequence numbers by, where each key has its own sequence.").
+			Default("").
+			Example(`${! json("customer_id") }`)).
+		Field(service.NewInterpolatedStringField("sequence").
+			Description("The sequence number of each message, which must resolve to an integer.").
+			Example(`${! meta("sequence") }`).
+			Example(`${! json("seq") }`)).
+		Field(service.NewStringField("alert_output").
+			Description("An optional [output resource](/docs/components/outputs/about) to write alerts to.").
+			Default("")).
+		Field(service.NewStringField("cache_key_prefix").
+			Description("A prefix added to the keys of all entries stored within the cache.").
+			Default("sequence_check_").
+			Advanced()).
+		Example("Alerting on Gaps", "The following config checks the sequence numbers assigned by a `sequence` processor of an upstream Benthos instance, and writes alerts to a Kafka topic:",
+			`
+pipeline:
+  processors:
+    - sequence_check:
+        cache: sequences
+        key: ${! json("customer_id") }
+        sequence: ${! meta("sequence") }
+        alert_output: alerts
+
+cache_resources:
+  - label: sequences
+    redis:
+      url: tcp://localhost:6379
+
+output_resources:
+  - label: alerts
+    kafka:
+      addresses: [ TODO ]
+      topic: sequence_alerts
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterBatchProcessor(
+		"sequence_check", sequenceCheckProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchProcessor, error) {
+			cacheName, err := conf.FieldString("cache")
+			if err != nil {
+				return nil, err
+			}
+			key, err := conf.FieldInterpolatedString("key")
+			if err != nil {
+				return nil, err
+			}
+			sequence, err := conf.FieldInterpolatedString("sequence")
+			if err != nil {
+				return nil, err
+			}
+			alertOutput, err := conf.FieldString("alert_output")
+			if err != nil {
+				return nil, err
+			}
+			prefix, err := conf.FieldString("cache_key_prefix")
+			if err != nil {
+				return nil, err
+			}
+			accessCache := func(ctx context.Context, fn func(c service.Cache)) error {
+				return mgr.AccessCache(ctx, cacheName, fn)
+			}
+			if err := accessCache(context.Background(), func(service.Cache) {}); err != nil {
+				return nil, fmt.Errorf("cache resource '%v': %w", cacheName, err)
+			}
+
+			var writeAlerts func(ctx context.Context, b service.MessageBatch) error
+			if alertOutput != "" {
+				if !mgr.HasOutput(alertOutput) {
+					return nil, fmt.Errorf("output resource '%v' was not found", alertOutput)
+				}
+				writeAlerts = func(ctx context.Context, b service.MessageBatch) error {
+					var werr error
+					if err := mgr.AccessOutput(ctx, alertOutput, func(o *service.ResourceOutput) {
+						werr = o.WriteBatch(ctx, b)
+					}); err != nil {
+						return err
+					}
+					return werr
+				}
+			}
+
+			return newSequenceCheckProcessor(
+				accessCache, cacheName+"\x00"+prefix, prefix,
+				key, sequence, writeAlerts,
+				mgr.Logger(), mgr.Metrics(),
+			), nil
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type sequenceAlert struct {
+	Type     string `json:"type"`
+	Key      string `json:"key"`
+	Expected int64  `json:"expected"`
+	Received int64  `json:"received"`
+	Missing  int64  `json:"missing,omitempty"`
+}
+
+type sequenceCheckProcessor struct {
+	accessCache func(ctx context.Context, fn func(c service.Cache)) error
+	writeAlerts func(ctx context.Context, b service.MessageBatch) error
+	lock        *sync.Mutex
+	prefix      string
+
+	key      *service.InterpolatedString
+	sequence *service.InterpolatedString
+
+	log         *service.Logger
+	mGap        *service.MetricCounter
+	mOutOfOrder *service.MetricCounter
+	mAlertSent  *service.MetricCounter
+	mAlertErr   *service.MetricCounter
+}
+
+func newSequenceCheckProcessor(
+	accessCache func(ctx context.Context, fn func(c service.Cache)) error,
+	lockID, prefix string,
+	key, sequence *service.InterpolatedString,
+	writeAlerts func(ctx context.Context, b service.MessageBatch) error,
+	log *service.Logger,
+	stats *service.Metrics,
+) *sequenceCheckProcessor {
+	lock, _ := cacheStateLocks.LoadOrStore(lockID, &sync.Mutex{})
+	return &sequenceCheckProcessor{
+		accessCache: accessCache,
+		writeAlerts: writeAlerts,
+		lock:        lock.(*sync.Mutex),
+		prefix:      prefix,
+		key:         key,
+		sequence:    sequence,
+		log:         log,
+		mGap:        stats.NewCounter("gap"),
+		mOutOfOrder: stats.NewCounter("out_of_order"),
+		mAlertSent:  stats.NewCounter("alert.sent"),
+		mAlertErr:   stats.NewCounter("alert.error"),
+	}
+}
+
+// check compares a sequence number against the highest seen for a key,
+// returning an alert when it isn't the next expected number.
+func (s *sequenceCheckProcessor) check(ctx context.Context, key string, seq int64) (alert *sequenceAlert, err error) {
+	cacheKey := s.prefix + key
+	if cerr := s.accessCache(ctx, func(c service.Cache) {
+		var b []byte
+		if b, err = c.Get(ctx, cacheKey); errors.Is(err, service.ErrKeyNotFound) {
+			err = c.Set(ctx, cacheKey, []byte(strconv.FormatInt(seq, 10)), nil)
+			return
+		}
+		if err != nil {
+			return
+		}
+		var last int64
+		if last, err = strconv.ParseInt(string(b), 10, 64); err != nil {
+			err = fmt.Errorf("failed to parse sequence of key '%v': %w", cacheKey, err)
+			return
+		}
+		if seq <= last {
+			alert = &sequenceAlert{Type: "out_of_order", Key: key, Expected: last + 1, Received: seq}
+			return
+		}
+		if seq > last+1 {
+			alert = &sequenceAlert{Type: "gap", Key: key, Expected: last + 1, Received: seq, Missing: seq - last - 1}
+		}
+		err = c.Set(ctx, cacheKey, []byte(strconv.FormatInt(seq, 10)), nil)
+	}); cerr != nil {
+		err = cerr
+	}
+	return
+}
+
+func (s *sequenceCheckProcessor) ProcessBatch(ctx context.Context, batch service.MessageBatch) ([]service.MessageBatch, error) {
+	var alerts service.MessageBatch
+
+	s.lock.Lock()
+	for _, msg := range batch {
+		seqStr := strings.TrimSpace(s.sequence.String(msg))
+		seq, err := strconv.ParseInt(seqStr, 10, 64)
+		if err != nil {
+			msg.SetError(fmt.Errorf("failed to parse sequence number '%v': %w", seqStr, err))
+			continue
+		}
+
+		alert, err := s.check(ctx, s.key.String(msg), seq)
+		if err != nil {
+			s.log.Errorf("Failed to check sequence number: %v", err)
+			msg.SetError(fmt.Errorf("failed to check sequence number: %w", err))
+			continue
+		}
+
+		status := "ok"
+		if alert != nil {
+			status = alert.Type
+			if alert.Type == "gap" {
+				s.mGap.Incr(1)
+			} else {
+				s.mOutOfOrder.Incr(1)
+			}
+			alertBytes, _ := json.Marshal(alert)
+			alerts = append(alerts, service.NewMessage(alertBytes))
+		}
+		msg.MetaSet("sequence_check", status)
+	}
+	s.lock.Unlock()
+
+	if len(alerts) > 0 && s.writeAlerts != nil {
+		wctx, done := context.WithTimeout(ctx, time.Second*30)
+		defer done()
+		if err := s.writeAlerts(wctx, alerts); err != nil {
+			s.mAlertErr.Incr(1)
+			s.log.Errorf("Failed to write sequence alerts: %v", err)
+		} else {
+			s.mAlertSent.Incr(int64(len(alerts)))
+		}
+	}
+	return []service.MessageBatch{batch}, nil
+}
+
+func (s *sequenceCheckProcessor) Close(ctx context.Context) error {
+	return nil
+}
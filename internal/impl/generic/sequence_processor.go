@@ -0,0 +1,170 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+func sequenceProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Utility").
+		Summary("Assigns a monotonically increasing sequence number to each message, counted separately for each key, and stores it within a metadata field.").
+		Description(`
+The last sequence number assigned to each key is stored within a [cache resource](/docs/components/caches/about), and therefore when the cache is persistent (such as `+"`redis`"+`) the sequence continues from where it left off after a restart of the service.
+
+Sequence numbers are allocated under a lock that is shared by all processors of this Benthos instance targeting the same cache and `+"`cache_key_prefix`"+`, but multiple instances sharing a cache should each use a distinct prefix in order to avoid allocating the same number twice.
+
+The gaps and out of order arrivals of sequence numbers assigned upstream can be detected with the `+"[`sequence_check` processor](/docs/components/processors/sequence_check)"+`.`).
+		Field(service.NewStringField("cache").
+			Description("The [cache resource](/docs/components/caches/about) to store the last sequence number of each key within.")).
+		Field(service.NewInterpolatedStringField("key").
+			Description("An optional key to count sequence numbers by, where each key has its own sequence.").
+			Default("").
+			Example(`${! json("customer_id") }`)).
+		Field(service.NewStringField("metadata_key").
+			Description("The metadata field to store the sequence number of each message within.").
+			Default("sequence")).
+		Field(service.NewIntField("start").
+			Description("The sequence number assigned to the first message of each key.").
+			Default(1).
+			Advanced()).
+		Field(service.NewStringField("cache_key_prefix").
+			Description("A prefix added to the keys of all entries stored within the cache.").
+			Default("sequence_").
+			Advanced()).
+		Example("Numbering by Customer", "The following config numbers the messages of each customer, continuing from the last number assigned after a restart:",
+			`
+pipeline:
+  processors:
+    - sequence:
+        cache: sequences
+        key: ${! json("customer_id") }
+
+cache_resources:
+  - label: sequences
+    redis:
+      url: tcp://localhost:6379
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterProcessor(
+		"sequence", sequenceProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Processor, error) {
+			cacheName, err := conf.FieldString("cache")
+			if err != nil {
+				return nil, err
+			}
+			key, err := conf.FieldInterpolatedString("key")
+			if err != nil {
+				return nil, err
+			}
+			metaKey, err := conf.FieldString("metadata_key")
+			if err != nil {
+				return nil, err
+			}
+			if metaKey == "" {
+				return nil, errors.New("metadata_key must not be empty")
+			}
+			start, err := conf.FieldInt("start")
+			if err != nil {
+				return nil, err
+			}
+			prefix, err := conf.FieldString("cache_key_prefix")
+			if err != nil {
+				return nil, err
+			}
+			accessCache := func(ctx context.Context, fn func(c service.Cache)) error {
+				return mgr.AccessCache(ctx, cacheName, fn)
+			}
+			if err := accessCache(context.Background(), func(service.Cache) {}); err != nil {
+				return nil, fmt.Errorf("cache resource '%v': %w", cacheName, err)
+			}
+			return newSequenceProcessor(
+				accessCache, cacheName+"\x00"+prefix, prefix,
+				key, metaKey, int64(start),
+			), nil
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type sequenceProcessor struct {
+	accessCache func(ctx context.Context, fn func(c service.Cache)) error
+	lock        *sync.Mutex
+	prefix      string
+
+	key     *service.InterpolatedString
+	metaKey string
+	start   int64
+}
+
+func newSequenceProcessor(
+	accessCache func(ctx context.Context, fn func(c service.Cache)) error,
+	lockID, prefix string,
+	key *service.InterpolatedString,
+	metaKey string,
+	start int64,
+) *sequenceProcessor {
+	lock, _ := cacheStateLocks.LoadOrStore(lockID, &sync.Mutex{})
+	return &sequenceProcessor{
+		accessCache: accessCache,
+		lock:        lock.(*sync.Mutex),
+		prefix:      prefix,
+		key:         key,
+		metaKey:     metaKey,
+		start:       start,
+	}
+}
+
+// next increments and returns the sequence number of a key.
+func (s *sequenceProcessor) next(ctx context.Context, key string) (seq int64, err error) {
+	cacheKey := s.prefix + key
+	if cerr := s.accessCache(ctx, func(c service.Cache) {
+		var b []byte
+		if b, err = c.Get(ctx, cacheKey); errors.Is(err, service.ErrKeyNotFound) {
+			seq, err = s.start, nil
+		} else if err == nil {
+			if seq, err = strconv.ParseInt(string(b), 10, 64); err != nil {
+				err = fmt.Errorf("failed to parse sequence of key '%v': %w", cacheKey, err)
+				return
+			}
+			seq++
+		}
+		if err != nil {
+			return
+		}
+		err = c.Set(ctx, cacheKey, []byte(strconv.FormatInt(seq, 10)), nil)
+	}); cerr != nil {
+		err = cerr
+	}
+	return
+}
+
+func (s *sequenceProcessor) Process(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	seq, err := s.next(ctx, s.key.String(msg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign sequence number: %w", err)
+	}
+	msg.MetaSet(s.metaKey, strconv.FormatInt(seq, 10))
+	return service.MessageBatch{msg}, nil
+}
+
+func (s *sequenceProcessor) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,159 @@
+package generic_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runSequenceStream runs a stream that consumes the lines of input through a
+// processor and returns the value of a metadata key of each resulting message,
+// or the error flagged on it.
+func runSequenceStream(t *testing.T, input, resources, proc, metaKey string) []string {
+	t.Helper()
+
+	inFilePath := filepath.Join(t.TempDir(), "in.txt")
+	require.NoError(t, ioutil.WriteFile(inFilePath, []byte(input), 0644))
+
+	b := service.NewStreamBuilder()
+	require.NoError(t, b.SetLoggerYAML("level: NONE"))
+	require.NoError(t, b.AddInputYAML(fmt.Sprintf(`
+file:
+  codec: lines
+  paths: [ %v ]`, inFilePath)))
+	require.NoError(t, b.AddResourcesYAML(resources))
+	require.NoError(t, b.AddProcessorYAML(proc))
+
+	var results []string
+	var resultsMut sync.Mutex
+	require.NoError(t, b.AddConsumerFunc(func(_ context.Context, m *service.Message) error {
+		resultsMut.Lock()
+		defer resultsMut.Unlock()
+		if err := m.GetError(); err != nil {
+			results = append(results, "error")
+			return nil
+		}
+		v, _ := m.MetaGet(metaKey)
+		results = append(results, v)
+		return nil
+	}))
+
+	strm, err := b.Build()
+	require.NoError(t, err)
+	require.NoError(t, strm.Run(context.Background()))
+
+	resultsMut.Lock()
+	defer resultsMut.Unlock()
+	return results
+}
+
+func TestSequenceProcessor(t *testing.T) {
+	resources := `
+cache_resources:
+  - label: foocache
+    memory: {}
+`
+	proc := `
+sequence:
+  cache: foocache
+  key: ${! json("id") }
+`
+	assert.Equal(t, []string{"1", "1", "2", "3"}, runSequenceStream(t, `{"id":"a"}
+{"id":"b"}
+{"id":"a"}
+{"id":"a"}`, resources, proc, "sequence"))
+}
+
+func TestSequenceProcessorStart(t *testing.T) {
+	resources := `
+cache_resources:
+  - label: foocache
+    memory: {}
+`
+	proc := `
+sequence:
+  cache: foocache
+  metadata_key: seq
+  start: 10
+`
+	assert.Equal(t, []string{"10", "11"}, runSequenceStream(t, "foo\nbar", resources, proc, "seq"))
+}
+
+func TestSequenceProcessorMissingCache(t *testing.T) {
+	b := service.NewStreamBuilder()
+	require.NoError(t, b.SetLoggerYAML("level: NONE"))
+	require.NoError(t, b.AddInputYAML(`generate: { mapping: 'root = "foo"' }`))
+	require.NoError(t, b.AddProcessorYAML(`sequence: { cache: nope }`))
+
+	strm, err := b.Build()
+	require.NoError(t, err)
+	require.Error(t, strm.Run(context.Background()))
+}
+
+func TestSequenceCheckProcessor(t *testing.T) {
+	alertsPath := filepath.Join(t.TempDir(), "alerts.jsonl")
+	resources := fmt.Sprintf(`
+cache_resources:
+  - label: foocache
+    memory: {}
+
+output_resources:
+  - label: alerts
+    file:
+      path: %v
+      codec: lines
+`, alertsPath)
+	proc := `
+sequence_check:
+  cache: foocache
+  key: ${! json("id") }
+  sequence: ${! json("seq") }
+  alert_output: alerts
+`
+	assert.Equal(t, []string{"ok", "ok", "ok", "gap", "out_of_order", "ok", "error"}, runSequenceStream(t, `{"id":"a","seq":1}
+{"id":"a","seq":2}
+{"id":"b","seq":5}
+{"id":"a","seq":5}
+{"id":"a","seq":3}
+{"id":"a","seq":6}
+{"id":"a","seq":"nope"}`, resources, proc, "sequence_check"))
+
+	alertBytes, err := ioutil.ReadFile(alertsPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`{"type":"gap","key":"a","expected":3,"received":5,"missing":2}`,
+		`{"type":"out_of_order","key":"a","expected":6,"received":3}`,
+	}, strings.Split(strings.TrimSpace(string(alertBytes)), "\n"))
+}
+
+func TestSequenceCheckProcessorMissingOutput(t *testing.T) {
+	b := service.NewStreamBuilder()
+	require.NoError(t, b.SetLoggerYAML("level: NONE"))
+	require.NoError(t, b.AddInputYAML(`generate: { mapping: 'root = "foo"' }`))
+	require.NoError(t, b.AddResourcesYAML(`
+cache_resources:
+  - label: foocache
+    memory: {}
+`))
+	require.NoError(t, b.AddProcessorYAML(`
+sequence_check:
+  cache: foocache
+  sequence: ${! json("seq") }
+  alert_output: alerts
+`))
+
+	strm, err := b.Build()
+	require.NoError(t, err)
+
+	err = strm.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "output resource 'alerts' was not found")
+}
@@ -0,0 +1,415 @@
+package generic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/bloblang/query"
+	"github.com/Jeffail/benthos/v3/public/bloblang"
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+func windowAggregateProcessorConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Windowing").
+		Summary("Groups messages by a key over tumbling or sliding windows of time and emits an aggregate of each window as a new message once it has ended, storing the state of open windows within a cache resource.").
+		Description(`
+Messages consumed by this processor are added to the aggregates of the windows they belong to and then removed from the pipeline, and once a window has ended a single message is emitted in their place of the form:
+
+`+"```json"+`
+{
+  "key": "foo",
+  "window_start": "2021-08-07T10:00:00Z",
+  "window_end": "2021-08-07T11:00:00Z",
+  "count": 15,
+  "sum": 43,
+  "min": 1,
+  "max": 5
+}
+`+"```"+`
+
+Where the fields `+"`sum`, `min` and `max`"+` are only present when a `+"[`value_mapping`](#value_mapping)"+` is configured.
+
+Windows are aligned to the zeroth minute and zeroth hour on the UTC clock. In tumbling mode (default) the beginning of a window immediately follows the end of a prior window, and sliding windows are produced by specifying a `+"[`slide` duration](#slide)"+`, in which case messages may belong to multiple windows.
+
+## Closing Windows
+
+This processor has no timer of its own, instead the progress of time is measured by the timestamps of the messages it processes. A window is ended once a message is processed with a timestamp beyond the end of the window plus any `+"[`allowed_lateness`](#allowed_lateness)"+`, and messages that arrive for a window that has already ended are dropped.
+
+This means that when the stream of messages stops the final windows are not emitted until more messages arrive. When this is a concern a `+"[`generate` input](/docs/components/inputs/generate)"+` can be combined with the input of the pipeline in order to produce periodic messages that advance time, with a key and value that are filtered out of the aggregates downstream.
+
+## Persistence
+
+The aggregates of open windows, and the latest timestamp seen, are stored within the configured `+"[cache resource](/docs/components/caches/about)"+` rather than in memory, and therefore when the cache is persistent (such as `+"`redis`"+`) window state survives restarts of the service. Messages are acknowledged once they have been added to the aggregates within the cache.
+
+Multiple processors can share a cache as long as they each have a distinct `+"`cache_key_prefix`"+`.`).
+		Field(service.NewStringField("cache").
+			Description("The [cache resource](/docs/components/caches/about) to store the state of windows within.")).
+		Field(service.NewInterpolatedStringField("key").
+			Description("An interpolated string resulting in the key to group messages by, where each key is aggregated separately.").
+			Default("").
+			Example(`${! json("traffic_light") }`)).
+		Field(service.NewBloblangField("timestamp_mapping").
+			Description("A [Bloblang mapping](/docs/guides/bloblang/about) that provides the timestamp of each message, used for allocating it to windows. The result must either be a numerical unix time in seconds, or a string in ISO 8601 format. By default the time at which the message is processed is used.").
+			Default("root = now()").
+			Example("root = this.created_at").Example(`root = meta("kafka_timestamp_unix").number()`)).
+		Field(service.NewStringField("value_mapping").
+			Description("An optional [Bloblang mapping](/docs/guides/bloblang/about) that provides a numerical value from each message, which is aggregated into the sum, minimum and maximum of each window.").
+			Default("").
+			Example("root = this.passengers")).
+		Field(service.NewStringField("size").
+			Description("A duration string describing the size of each window.").
+			Example("30s").Example("1h")).
+		Field(service.NewStringField("slide").
+			Description("An optional duration string describing by how much time the beginning of each window should be offset from the beginning of the previous, and therefore creates sliding windows instead of tumbling. When specified this duration must be smaller than the `size` of the window.").
+			Default("").
+			Example("10s").Example("15m")).
+		Field(service.NewStringField("allowed_lateness").
+			Description("An optional duration string describing the length of time to wait after a window has ended before emitting it, allowing late arrivals to be included.").
+			Default("").
+			Example("10s").Example("1m")).
+		Field(service.NewStringField("cache_key_prefix").
+			Description("A prefix added to the keys of all entries stored within the cache.").
+			Default("window_aggregate_").
+			Advanced()).
+		Example("Counting Passengers at Traffic", `Given a stream of messages relating to cars passing through various traffic lights of the form:
+
+`+"```json"+`
+{
+  "traffic_light": "cbf2eafc-806e-4067-9211-97be7e42cee3",
+  "created_at": "2021-08-07T09:49:35Z",
+  "registration_plate": "AB1C DEF",
+  "passengers": 3
+}
+`+"```"+`
+
+We can emit the number of cars and passengers passing through each traffic light every hour, storing the state of each window within Redis:`,
+			`
+pipeline:
+  processors:
+    - window_aggregate:
+        cache: windows
+        key: ${! json("traffic_light") }
+        timestamp_mapping: root = this.created_at
+        value_mapping: root = this.passengers
+        size: 1h
+
+cache_resources:
+  - label: windows
+    redis:
+      url: tcp://localhost:6379
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterProcessor(
+		"window_aggregate", windowAggregateProcessorConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Processor, error) {
+			cacheName, err := conf.FieldString("cache")
+			if err != nil {
+				return nil, err
+			}
+			key, err := conf.FieldInterpolatedString("key")
+			if err != nil {
+				return nil, err
+			}
+			tsMapping, err := conf.FieldBloblang("timestamp_mapping")
+			if err != nil {
+				return nil, err
+			}
+			var valueMapping *bloblang.Executor
+			valueMappingStr, err := conf.FieldString("value_mapping")
+			if err != nil {
+				return nil, err
+			}
+			if valueMappingStr != "" {
+				if valueMapping, err = bloblang.Parse(valueMappingStr); err != nil {
+					return nil, fmt.Errorf("failed to parse value_mapping: %w", err)
+				}
+			}
+			size, err := getDuration(conf, true, "size")
+			if err != nil {
+				return nil, err
+			}
+			slide, err := getDuration(conf, false, "slide")
+			if err != nil {
+				return nil, err
+			}
+			if slide >= size {
+				return nil, fmt.Errorf("invalid window slide '%v' must be lower than the size '%v'", slide, size)
+			}
+			allowedLateness, err := getDuration(conf, false, "allowed_lateness")
+			if err != nil {
+				return nil, err
+			}
+			prefix, err := conf.FieldString("cache_key_prefix")
+			if err != nil {
+				return nil, err
+			}
+			return newWindowAggregateProcessor(
+				func(ctx context.Context, fn func(c service.Cache)) error {
+					return mgr.AccessCache(ctx, cacheName, fn)
+				},
+				cacheName+"\x00"+prefix, prefix,
+				key, tsMapping, valueMapping,
+				size, slide, allowedLateness,
+			), nil
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// cacheStateLocks contains a mutex for each combination of cache and key
+// prefix, since the state within a cache is shared between the processors of
+// each pipeline thread.
+var cacheStateLocks sync.Map
+
+type windowAggregateState struct {
+	Count int64    `json:"count"`
+	Sum   float64  `json:"sum"`
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+}
+
+type windowAggregateRef struct {
+	Key   string `json:"key"`
+	Start int64  `json:"start"`
+}
+
+type windowAggregateIndex struct {
+	Watermark int64                `json:"watermark"`
+	Windows   []windowAggregateRef `json:"windows"`
+}
+
+type windowAggregateProcessor struct {
+	accessCache func(ctx context.Context, fn func(c service.Cache)) error
+	lock        *sync.Mutex
+	prefix      string
+
+	key          *service.InterpolatedString
+	tsMapping    *bloblang.Executor
+	valueMapping *bloblang.Executor
+
+	size, slide, allowedLateness time.Duration
+}
+
+func newWindowAggregateProcessor(
+	accessCache func(ctx context.Context, fn func(c service.Cache)) error,
+	lockID, prefix string,
+	key *service.InterpolatedString,
+	tsMapping, valueMapping *bloblang.Executor,
+	size, slide, allowedLateness time.Duration,
+) *windowAggregateProcessor {
+	if slide <= 0 {
+		slide = size
+	}
+	lock, _ := cacheStateLocks.LoadOrStore(lockID, &sync.Mutex{})
+	return &windowAggregateProcessor{
+		accessCache:     accessCache,
+		lock:            lock.(*sync.Mutex),
+		prefix:          prefix,
+		key:             key,
+		tsMapping:       tsMapping,
+		valueMapping:    valueMapping,
+		size:            size,
+		slide:           slide,
+		allowedLateness: allowedLateness,
+	}
+}
+
+// mapStructured executes a mapping on a message and returns the result as a
+// structured value, falling back to a string when it is not valid JSON.
+func mapStructured(msg *service.Message, mapping *bloblang.Executor) (interface{}, error) {
+	res, err := msg.BloblangQuery(mapping)
+	if err != nil {
+		return nil, err
+	}
+	v, err := res.AsStructured()
+	if err != nil {
+		if b, _ := res.AsBytes(); len(b) > 0 {
+			return string(b), nil
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+// windowStarts returns the start of each window that a timestamp belongs to.
+func (w *windowAggregateProcessor) windowStarts(ts time.Time) []time.Time {
+	var starts []time.Time
+	for start := ts.Truncate(w.slide); start.Add(w.size).After(ts); start = start.Add(-w.slide) {
+		starts = append(starts, start)
+	}
+	return starts
+}
+
+// closed returns true if a window has ended according to a watermark.
+func (w *windowAggregateProcessor) closed(start time.Time, watermark time.Time) bool {
+	return !start.Add(w.size + w.allowedLateness).After(watermark)
+}
+
+func (w *windowAggregateProcessor) indexKey() string {
+	return w.prefix + "index"
+}
+
+func (w *windowAggregateProcessor) stateKey(ref windowAggregateRef) string {
+	return fmt.Sprintf("%v%v@%v", w.prefix, ref.Key, ref.Start)
+}
+
+func getJSON(ctx context.Context, c service.Cache, key string, v interface{}) (bool, error) {
+	b, err := c.Get(ctx, key)
+	if errors.Is(err, service.ErrKeyNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err = json.Unmarshal(b, v); err != nil {
+		return false, fmt.Errorf("failed to parse cache entry '%v': %w", key, err)
+	}
+	return true, nil
+}
+
+func setJSON(ctx context.Context, c service.Cache, key string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, key, b, nil)
+}
+
+func (w *windowAggregateProcessor) Process(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
+	tsValue, err := mapStructured(msg, w.tsMapping)
+	if err != nil {
+		return nil, fmt.Errorf("timestamp mapping failed: %w", err)
+	}
+	ts, err := query.IGetTimestamp(tsValue)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse result of timestamp mapping as timestamp: %w", err)
+	}
+
+	var value float64
+	if w.valueMapping != nil {
+		v, err := mapStructured(msg, w.valueMapping)
+		if err != nil {
+			return nil, fmt.Errorf("value mapping failed: %w", err)
+		}
+		if value, err = query.IGetNumber(v); err != nil {
+			return nil, fmt.Errorf("unable to parse result of value mapping as number: %w", err)
+		}
+	}
+
+	key := w.key.String(msg)
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	var emitted service.MessageBatch
+	var cerr error
+	if err := w.accessCache(ctx, func(c service.Cache) {
+		var index windowAggregateIndex
+		if _, cerr = getJSON(ctx, c, w.indexKey(), &index); cerr != nil {
+			return
+		}
+		if ts.UnixNano() > index.Watermark {
+			index.Watermark = ts.UnixNano()
+		}
+		watermark := time.Unix(0, index.Watermark)
+
+		// Emit any windows that have ended.
+		open := index.Windows[:0]
+		for _, ref := range index.Windows {
+			start := time.Unix(0, ref.Start)
+			if !w.closed(start, watermark) {
+				open = append(open, ref)
+				continue
+			}
+			var state windowAggregateState
+			if _, cerr = getJSON(ctx, c, w.stateKey(ref), &state); cerr != nil {
+				return
+			}
+			emitted = append(emitted, w.aggregateMessage(ref.Key, start, state))
+			if cerr = c.Delete(ctx, w.stateKey(ref)); cerr != nil && !errors.Is(cerr, service.ErrKeyNotFound) {
+				return
+			}
+			cerr = nil
+		}
+		index.Windows = open
+
+		// Add the message to the windows it belongs to, unless they have
+		// already ended.
+		for _, start := range w.windowStarts(ts) {
+			if w.closed(start, watermark) {
+				continue
+			}
+			ref := windowAggregateRef{Key: key, Start: start.UnixNano()}
+
+			var state windowAggregateState
+			var exists bool
+			if exists, cerr = getJSON(ctx, c, w.stateKey(ref), &state); cerr != nil {
+				return
+			}
+			state.Count++
+			if w.valueMapping != nil {
+				state.Sum += value
+				if state.Min == nil || value < *state.Min {
+					v := value
+					state.Min = &v
+				}
+				if state.Max == nil || value > *state.Max {
+					v := value
+					state.Max = &v
+				}
+			}
+			if cerr = setJSON(ctx, c, w.stateKey(ref), state); cerr != nil {
+				return
+			}
+			if !exists {
+				index.Windows = append(index.Windows, ref)
+			}
+		}
+		cerr = setJSON(ctx, c, w.indexKey(), index)
+	}); err != nil {
+		return nil, err
+	}
+	if cerr != nil {
+		return nil, cerr
+	}
+	return emitted, nil
+}
+
+func (w *windowAggregateProcessor) aggregateMessage(key string, start time.Time, state windowAggregateState) *service.Message {
+	doc := map[string]interface{}{
+		"key":          key,
+		"window_start": start.UTC().Format(time.RFC3339Nano),
+		"window_end":   start.Add(w.size).UTC().Format(time.RFC3339Nano),
+		"count":        state.Count,
+	}
+	if w.valueMapping != nil {
+		doc["sum"] = state.Sum
+		if state.Min != nil {
+			doc["min"] = *state.Min
+		}
+		if state.Max != nil {
+			doc["max"] = *state.Max
+		}
+	}
+	msg := service.NewMessage(nil)
+	msg.SetStructured(doc)
+	return msg
+}
+
+func (w *windowAggregateProcessor) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,128 @@
+package generic
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/bloblang"
+	"github.com/Jeffail/benthos/v3/public/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWindowCache struct {
+	mut   sync.Mutex
+	items map[string][]byte
+}
+
+func (f *fakeWindowCache) Get(ctx context.Context, key string) ([]byte, error) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	b, ok := f.items[key]
+	if !ok {
+		return nil, service.ErrKeyNotFound
+	}
+	return b, nil
+}
+
+func (f *fakeWindowCache) Set(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	f.items[key] = value
+	return nil
+}
+
+func (f *fakeWindowCache) Add(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if _, ok := f.items[key]; ok {
+		return service.ErrKeyAlreadyExists
+	}
+	f.items[key] = value
+	return nil
+}
+
+func (f *fakeWindowCache) Delete(ctx context.Context, key string) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	delete(f.items, key)
+	return nil
+}
+
+func (f *fakeWindowCache) Close(ctx context.Context) error {
+	return nil
+}
+
+func newTestWindowAggregate(t *testing.T, cache service.Cache, slide time.Duration) *windowAggregateProcessor {
+	t.Helper()
+
+	key, err := service.NewInterpolatedString(`${! json("key") }`)
+	require.NoError(t, err)
+	tsMapping, err := bloblang.Parse("root = this.ts")
+	require.NoError(t, err)
+	valueMapping, err := bloblang.Parse("root = this.value")
+	require.NoError(t, err)
+
+	return newWindowAggregateProcessor(func(ctx context.Context, fn func(c service.Cache)) error {
+		fn(cache)
+		return nil
+	}, t.Name(), "test_", key, tsMapping, valueMapping, time.Minute, slide, 0)
+}
+
+func processWindowAggregate(t *testing.T, proc *windowAggregateProcessor, input string) []string {
+	t.Helper()
+
+	batch, err := proc.Process(context.Background(), service.NewMessage([]byte(input)))
+	require.NoError(t, err)
+
+	var results []string
+	for _, msg := range batch {
+		b, err := msg.AsBytes()
+		require.NoError(t, err)
+		results = append(results, string(b))
+	}
+	return results
+}
+
+func TestWindowAggregateTumbling(t *testing.T) {
+	cache := &fakeWindowCache{items: map[string][]byte{}}
+	proc := newTestWindowAggregate(t, cache, 0)
+
+	assert.Empty(t, processWindowAggregate(t, proc, `{"key":"a","ts":"2021-08-07T10:00:10Z","value":3}`))
+	assert.Empty(t, processWindowAggregate(t, proc, `{"key":"a","ts":"2021-08-07T10:00:20Z","value":1}`))
+	assert.Empty(t, processWindowAggregate(t, proc, `{"key":"b","ts":"2021-08-07T10:00:30Z","value":5}`))
+
+	// A new processor sharing the cache continues from the persisted state.
+	proc = newTestWindowAggregate(t, cache, 0)
+
+	assert.Equal(t, []string{
+		`{"count":2,"key":"a","max":3,"min":1,"sum":4,"window_end":"2021-08-07T10:01:00Z","window_start":"2021-08-07T10:00:00Z"}`,
+		`{"count":1,"key":"b","max":5,"min":5,"sum":5,"window_end":"2021-08-07T10:01:00Z","window_start":"2021-08-07T10:00:00Z"}`,
+	}, processWindowAggregate(t, proc, `{"key":"a","ts":"2021-08-07T10:01:05Z","value":2}`))
+
+	// Messages of windows that have ended are dropped.
+	assert.Empty(t, processWindowAggregate(t, proc, `{"key":"a","ts":"2021-08-07T10:00:50Z","value":10}`))
+
+	assert.Equal(t, []string{
+		`{"count":1,"key":"a","max":2,"min":2,"sum":2,"window_end":"2021-08-07T10:02:00Z","window_start":"2021-08-07T10:01:00Z"}`,
+	}, processWindowAggregate(t, proc, `{"key":"a","ts":"2021-08-07T10:05:00Z","value":1}`))
+
+	// Only the state of the open window and the index remain.
+	assert.Len(t, cache.items, 2)
+}
+
+func TestWindowAggregateSliding(t *testing.T) {
+	cache := &fakeWindowCache{items: map[string][]byte{}}
+	proc := newTestWindowAggregate(t, cache, time.Second*30)
+
+	assert.Empty(t, processWindowAggregate(t, proc, `{"key":"a","ts":"2021-08-07T10:00:10Z","value":1}`))
+	assert.Equal(t, []string{
+		`{"count":1,"key":"a","max":1,"min":1,"sum":1,"window_end":"2021-08-07T10:00:30Z","window_start":"2021-08-07T09:59:30Z"}`,
+	}, processWindowAggregate(t, proc, `{"key":"a","ts":"2021-08-07T10:00:40Z","value":2}`))
+
+	assert.Equal(t, []string{
+		`{"count":2,"key":"a","max":2,"min":1,"sum":3,"window_end":"2021-08-07T10:01:00Z","window_start":"2021-08-07T10:00:00Z"}`,
+	}, processWindowAggregate(t, proc, `{"key":"a","ts":"2021-08-07T10:01:00Z","value":4}`))
+}
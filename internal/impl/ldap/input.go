@@ -0,0 +1,351 @@
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+var searchScopes = map[string]int{
+	"base": goldap.ScopeBaseObject,
+	"one":  goldap.ScopeSingleLevel,
+	"sub":  goldap.ScopeWholeSubtree,
+}
+
+func ldapInputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Services").
+		Summary("Searches an LDAP directory and consumes each matching entry as a message, optionally repeating the search periodically.").
+		Description(`
+Each entry returned by the search is consumed as a JSON document containing its distinguished name and attributes. Attributes with a single value are strings and attributes with multiple values are arrays of strings:
+
+`+"```json"+`
+{
+  "dn": "uid=jdoe,ou=people,dc=example,dc=org",
+  "uid": "jdoe",
+  "departmentNumber": "engineering",
+  "memberOf": [
+    "cn=admins,ou=groups,dc=example,dc=org",
+    "cn=developers,ou=groups,dc=example,dc=org"
+  ]
+}
+`+"```"+`
+
+When `+"`interval`"+` is empty the search is performed once and the input shuts down after all entries have been consumed. Otherwise the search is performed again each `+"`interval`"+`, which makes it possible to keep a [cache](/docs/components/caches/about) up to date with the contents of a directory so that enrichment lookups can be served locally:
+
+`+"```yaml"+`
+input:
+  ldap:
+    url: ldap://localhost:389
+    bind_dn: cn=readonly,dc=example,dc=org
+    bind_password: ${LDAP_PASSWORD}
+    base_dn: ou=people,dc=example,dc=org
+    filter: (objectClass=inetOrgPerson)
+    attributes: [ uid, departmentNumber ]
+    interval: 10m
+
+output:
+  cache:
+    target: users
+    key: ${! json("uid") }
+
+cache_resources:
+  - label: users
+    memory:
+      default_ttl: 1h
+`+"```"+`
+
+Results are requested in pages of `+"`page_size`"+` entries, although all entries of a search are gathered before the first is consumed.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- ldap_dn
+`+"```"+`
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#metadata).`).
+		Field(service.NewStringField("url").
+			Description("The URL of the LDAP server, the schemes `ldap` and `ldaps` are supported.").
+			Example("ldap://localhost:389").
+			Example("ldaps://ldap.example.org:636")).
+		Field(service.NewStringField("bind_dn").
+			Description("The distinguished name to bind as. When empty the search is performed anonymously.").
+			Default("")).
+		Field(service.NewStringField("bind_password").
+			Description("The password to bind with.").
+			Default("")).
+		Field(service.NewStringField("base_dn").
+			Description("The distinguished name of the entry to begin the search from.").
+			Example("ou=people,dc=example,dc=org")).
+		Field(service.NewStringField("filter").
+			Description("An LDAP filter that entries must match.").
+			Default("(objectClass=*)").
+			Example("(&(objectClass=inetOrgPerson)(departmentNumber=*))")).
+		Field(service.NewStringListField("attributes").
+			Description("A list of attributes to return for each entry. When empty all user attributes are returned.").
+			Default([]string{}).
+			Example([]string{"uid", "mail", "departmentNumber"})).
+		Field(service.NewStringField("scope").
+			Description("The scope of the search, either `base` for the base entry only, `one` for the direct children of the base entry, or `sub` for the entire subtree.").
+			Default("sub").
+			Advanced()).
+		Field(service.NewStringField("interval").
+			Description("An optional period after which the search is performed again. When empty the search is performed once.").
+			Default("").
+			Example("10m")).
+		Field(service.NewIntField("page_size").
+			Description("The number of entries to request with each page of search results.").
+			Default(500).
+			Advanced()).
+		Field(service.NewBoolField("start_tls").
+			Description("Whether to upgrade an `ldap` connection to TLS with the StartTLS operation.").
+			Default(false).
+			Advanced()).
+		Field(service.NewTLSField("tls").
+			Description("TLS settings to use for `ldaps` connections and when `start_tls` is enabled.").
+			Advanced())
+}
+
+func init() {
+	err := service.RegisterInput(
+		"ldap", ldapInputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			i, err := newLDAPInputFromConfig(conf, mgr.Logger())
+			if err != nil {
+				return nil, err
+			}
+			return service.AutoRetryNacks(i), nil
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type ldapInput struct {
+	url          string
+	bindDN       string
+	bindPassword string
+	startTLS     bool
+	tlsConf      *tls.Config
+
+	baseDN     string
+	filter     string
+	attributes []string
+	scope      int
+	pageSize   uint32
+	interval   time.Duration
+
+	log *service.Logger
+
+	connMut    sync.Mutex
+	conn       *goldap.Conn
+	pending    []*goldap.Entry
+	searched   bool
+	nextSearch time.Time
+}
+
+func newLDAPInputFromConfig(conf *service.ParsedConfig, log *service.Logger) (*ldapInput, error) {
+	l := &ldapInput{log: log}
+
+	var err error
+	if l.url, err = conf.FieldString("url"); err != nil {
+		return nil, err
+	}
+	if l.bindDN, err = conf.FieldString("bind_dn"); err != nil {
+		return nil, err
+	}
+	if l.bindPassword, err = conf.FieldString("bind_password"); err != nil {
+		return nil, err
+	}
+	if l.startTLS, err = conf.FieldBool("start_tls"); err != nil {
+		return nil, err
+	}
+	if l.tlsConf, err = conf.FieldTLS("tls"); err != nil {
+		return nil, err
+	}
+	if l.baseDN, err = conf.FieldString("base_dn"); err != nil {
+		return nil, err
+	}
+	if l.filter, err = conf.FieldString("filter"); err != nil {
+		return nil, err
+	}
+	if _, err = goldap.CompileFilter(l.filter); err != nil {
+		return nil, fmt.Errorf("failed to parse filter: %w", err)
+	}
+	if l.attributes, err = conf.FieldStringList("attributes"); err != nil {
+		return nil, err
+	}
+
+	scopeStr, err := conf.FieldString("scope")
+	if err != nil {
+		return nil, err
+	}
+	var exists bool
+	if l.scope, exists = searchScopes[scopeStr]; !exists {
+		return nil, fmt.Errorf("scope not recognised: %v", scopeStr)
+	}
+
+	intervalStr, err := conf.FieldString("interval")
+	if err != nil {
+		return nil, err
+	}
+	if intervalStr != "" {
+		if l.interval, err = time.ParseDuration(intervalStr); err != nil {
+			return nil, fmt.Errorf("failed to parse interval: %w", err)
+		}
+	}
+
+	pageSize, err := conf.FieldInt("page_size")
+	if err != nil {
+		return nil, err
+	}
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("invalid page_size: %v", pageSize)
+	}
+	l.pageSize = uint32(pageSize)
+	return l, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (l *ldapInput) Connect(ctx context.Context) error {
+	l.connMut.Lock()
+	defer l.connMut.Unlock()
+
+	if l.conn != nil {
+		return nil
+	}
+
+	conn, err := goldap.DialURL(l.url, goldap.DialWithTLSConfig(l.tlsConf))
+	if err != nil {
+		return err
+	}
+	if l.startTLS {
+		if err = conn.StartTLS(l.tlsConf); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+	if l.bindDN != "" {
+		err = conn.Bind(l.bindDN, l.bindPassword)
+	} else {
+		err = conn.UnauthenticatedBind("")
+	}
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	l.conn = conn
+	l.log.Infof("Searching LDAP entries at: %v\n", l.url)
+	return nil
+}
+
+func (l *ldapInput) search() error {
+	req := goldap.NewSearchRequest(
+		l.baseDN, l.scope, goldap.NeverDerefAliases, 0, 0, false,
+		l.filter, l.attributes, nil,
+	)
+	res, err := l.conn.SearchWithPaging(req, l.pageSize)
+	if err != nil {
+		return err
+	}
+	l.pending = res.Entries
+	return nil
+}
+
+func entryToMessage(entry *goldap.Entry) (*service.Message, error) {
+	doc := make(map[string]interface{}, len(entry.Attributes)+1)
+	for _, attr := range entry.Attributes {
+		if len(attr.Values) == 1 {
+			doc[attr.Name] = attr.Values[0]
+		} else {
+			values := make([]interface{}, len(attr.Values))
+			for i, v := range attr.Values {
+				values[i] = v
+			}
+			doc[attr.Name] = values
+		}
+	}
+	doc["dn"] = entry.DN
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := service.NewMessage(b)
+	msg.MetaSet("ldap_dn", entry.DN)
+	return msg, nil
+}
+
+func (l *ldapInput) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	l.connMut.Lock()
+	defer l.connMut.Unlock()
+
+	if l.conn == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+
+	for len(l.pending) == 0 {
+		if l.searched {
+			if l.interval == 0 {
+				return nil, nil, service.ErrEndOfInput
+			}
+			select {
+			case <-time.After(time.Until(l.nextSearch)):
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			}
+		}
+
+		if err := l.search(); err != nil {
+			if goldap.IsErrorWithCode(err, goldap.ErrorNetwork) {
+				l.log.Errorf("Lost connection due to: %v\n", err)
+				l.conn.Close()
+				l.conn = nil
+				return nil, nil, service.ErrNotConnected
+			}
+			return nil, nil, fmt.Errorf("search failed: %w", err)
+		}
+		l.searched = true
+		l.nextSearch = time.Now().Add(l.interval)
+
+		l.log.Debugf("Search returned %v entries\n", len(l.pending))
+	}
+
+	entry := l.pending[0]
+	l.pending = l.pending[1:]
+
+	msg, err := entryToMessage(entry)
+	if err != nil {
+		return nil, nil, err
+	}
+	return msg, func(ctx context.Context, err error) error {
+		return nil
+	}, nil
+}
+
+func (l *ldapInput) Close(ctx context.Context) error {
+	l.connMut.Lock()
+	defer l.connMut.Unlock()
+
+	if l.conn != nil {
+		l.conn.Close()
+		l.conn = nil
+	}
+	return nil
+}
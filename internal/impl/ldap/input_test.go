@@ -0,0 +1,38 @@
+package ldap
+
+import (
+	"testing"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryToMessage(t *testing.T) {
+	entry := goldap.NewEntry("uid=jdoe,ou=people,dc=example,dc=org", map[string][]string{
+		"uid":              {"jdoe"},
+		"departmentNumber": {"engineering"},
+		"memberOf": {
+			"cn=admins,ou=groups,dc=example,dc=org",
+			"cn=developers,ou=groups,dc=example,dc=org",
+		},
+	})
+
+	msg, err := entryToMessage(entry)
+	require.NoError(t, err)
+
+	b, err := msg.AsBytes()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "dn": "uid=jdoe,ou=people,dc=example,dc=org",
+  "uid": "jdoe",
+  "departmentNumber": "engineering",
+  "memberOf": [
+    "cn=admins,ou=groups,dc=example,dc=org",
+    "cn=developers,ou=groups,dc=example,dc=org"
+  ]
+}`, string(b))
+
+	dn, _ := msg.MetaGet("ldap_dn")
+	assert.Equal(t, "uid=jdoe,ou=people,dc=example,dc=org", dn)
+}
@@ -0,0 +1,3 @@
+// Package ldap contains component implementations for reading directory
+// entries from LDAP servers.
+package ldap
@@ -0,0 +1,124 @@
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// Function codes of the Modbus read requests supported by the client.
+const (
+	funcReadCoils            byte = 0x01
+	funcReadDiscreteInputs   byte = 0x02
+	funcReadHoldingRegisters byte = 0x03
+	funcReadInputRegisters   byte = 0x04
+)
+
+var exceptionCodes = map[byte]string{
+	0x01: "illegal function",
+	0x02: "illegal data address",
+	0x03: "illegal data value",
+	0x04: "server device failure",
+	0x05: "acknowledge",
+	0x06: "server device busy",
+	0x08: "memory parity error",
+	0x0A: "gateway path unavailable",
+	0x0B: "gateway target device failed to respond",
+}
+
+// exceptionError is returned when a server responds to a request with an
+// exception, in which case the connection remains usable.
+type exceptionError struct {
+	function byte
+	code     byte
+}
+
+func (e *exceptionError) Error() string {
+	desc, exists := exceptionCodes[e.code]
+	if !exists {
+		desc = "unknown exception"
+	}
+	return fmt.Sprintf("modbus exception %v for function %v: %v", e.code, e.function, desc)
+}
+
+// tcpClient is a minimal Modbus TCP client supporting the read functions.
+// Requests are sent sequentially and therefore a client must not be used
+// concurrently.
+type tcpClient struct {
+	conn          net.Conn
+	unitID        byte
+	timeout       time.Duration
+	transactionID uint16
+}
+
+func dialTCPClient(ctx context.Context, address string, unitID byte, timeout time.Duration) (*tcpClient, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	return &tcpClient{
+		conn:    conn,
+		unitID:  unitID,
+		timeout: timeout,
+	}, nil
+}
+
+// read sends a read request for quantity items starting at address and
+// returns the data bytes of the response.
+func (c *tcpClient) read(function byte, address, quantity uint16) ([]byte, error) {
+	c.transactionID++
+
+	// The MBAP header is followed by the function code, the starting address
+	// and the quantity of items to read.
+	req := make([]byte, 12)
+	binary.BigEndian.PutUint16(req[0:], c.transactionID)
+	binary.BigEndian.PutUint16(req[2:], 0)
+	binary.BigEndian.PutUint16(req[4:], 6)
+	req[6] = c.unitID
+	req[7] = function
+	binary.BigEndian.PutUint16(req[8:], address)
+	binary.BigEndian.PutUint16(req[10:], quantity)
+
+	if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return nil, err
+	}
+	if _, err := c.conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 7)
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint16(header[4:])
+	if length < 2 {
+		return nil, fmt.Errorf("invalid response length: %v", length)
+	}
+
+	pdu := make([]byte, length-1)
+	if _, err := io.ReadFull(c.conn, pdu); err != nil {
+		return nil, err
+	}
+
+	if id := binary.BigEndian.Uint16(header[0:]); id != c.transactionID {
+		return nil, fmt.Errorf("response transaction id %v does not match request %v", id, c.transactionID)
+	}
+	if pdu[0] == function|0x80 {
+		return nil, &exceptionError{function: function, code: pdu[1]}
+	}
+	if pdu[0] != function {
+		return nil, fmt.Errorf("response function %v does not match request %v", pdu[0], function)
+	}
+	if int(pdu[1]) != len(pdu)-2 {
+		return nil, fmt.Errorf("response byte count %v does not match data length %v", pdu[1], len(pdu)-2)
+	}
+	return pdu[2:], nil
+}
+
+func (c *tcpClient) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,417 @@
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+func modbusInputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Network").
+		Summary("Polls tags from a device over Modbus TCP and emits their readings as JSON documents.").
+		Description(`
+Every `+"`interval`"+` each configured tag is read from the device and a single message is emitted containing the readings of all tags as an object keyed by tag name:
+
+`+"```json"+`
+{
+  "temperature": 21.5,
+  "pump_running": true
+}
+`+"```"+`
+
+Tags of the `+"`coil`"+` and `+"`discrete_input`"+` register types are read as booleans. Tags of the `+"`holding`"+` and `+"`input`"+` register types are decoded according to their `+"`data_type`"+`, where 32 and 64 bit types span two and four consecutive registers respectively and are read most significant word first unless `+"`word_order`"+` is set to `+"`little`"+`.
+
+If a tag cannot be read due to an exception returned by the device the poll fails and no message is emitted for it, and if the connection to the device is lost then it is reestablished before the next poll.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- modbus_address
+- modbus_unit_id
+`+"```"+`
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#metadata).`).
+		Field(service.NewStringField("address").
+			Description("The address of the Modbus TCP device to connect to.").
+			Example("localhost:502")).
+		Field(service.NewIntField("unit_id").
+			Description("The unit identifier of the device, which is usually only significant when the device is reached through a gateway.").
+			Default(1).
+			Advanced()).
+		Field(service.NewStringField("interval").
+			Description("The period between polls of the device.").
+			Default("1s")).
+		Field(service.NewStringField("timeout").
+			Description("The maximum period to wait for a response to each request.").
+			Default("5s").
+			Advanced()).
+		Field(service.NewStringField("word_order").
+			Description("The order of the registers that make up 32 and 64 bit values, either `big` or `little`.").
+			Default("big").
+			Advanced()).
+		Field(service.NewObjectListField("tags",
+			service.NewStringField("name").
+				Description("The name of the tag, which is used as the field name of its reading."),
+			service.NewStringField("register_type").
+				Description("The type of register to read, one of `holding`, `input`, `coil` or `discrete_input`.").
+				Default("holding"),
+			service.NewIntField("address").
+				Description("The zero based address of the register to read."),
+			service.NewStringField("data_type").
+				Description("The type of the value stored in `holding` and `input` registers, one of `uint16`, `int16`, `uint32`, `int32`, `float32`, `uint64`, `int64` or `float64`.").
+				Default("uint16"),
+			service.NewFloatField("scale").
+				Description("A factor to multiply numeric readings by, which is ignored when set to `1`.").
+				Default(1.0),
+		).
+			Description("A list of tags to read from the device with each poll.").
+			Example([]interface{}{
+				map[string]interface{}{
+					"name":          "temperature",
+					"register_type": "input",
+					"address":       0,
+					"data_type":     "int16",
+					"scale":         0.1,
+				},
+				map[string]interface{}{
+					"name":          "pump_running",
+					"register_type": "coil",
+					"address":       12,
+				},
+			}))
+}
+
+func init() {
+	err := service.RegisterInput(
+		"modbus", modbusInputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			return newModbusInputFromConfig(conf, mgr.Logger())
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// registerCounts maps the data types of holding and input registers to the
+// number of registers they span.
+var registerCounts = map[string]uint16{
+	"uint16":  1,
+	"int16":   1,
+	"uint32":  2,
+	"int32":   2,
+	"float32": 2,
+	"uint64":  4,
+	"int64":   4,
+	"float64": 4,
+}
+
+var registerFunctions = map[string]byte{
+	"coil":           funcReadCoils,
+	"discrete_input": funcReadDiscreteInputs,
+	"holding":        funcReadHoldingRegisters,
+	"input":          funcReadInputRegisters,
+}
+
+type modbusTag struct {
+	name     string
+	function byte
+	address  uint16
+	dataType string
+	scale    float64
+}
+
+func tagFromParsed(conf *service.ParsedConfig) (tag modbusTag, err error) {
+	if tag.name, err = conf.FieldString("name"); err != nil {
+		return
+	}
+	if tag.name == "" {
+		err = errors.New("tag name must not be empty")
+		return
+	}
+
+	var registerType string
+	if registerType, err = conf.FieldString("register_type"); err != nil {
+		return
+	}
+	var exists bool
+	if tag.function, exists = registerFunctions[registerType]; !exists {
+		err = fmt.Errorf("tag %v: register_type not recognised: %v", tag.name, registerType)
+		return
+	}
+
+	var address int
+	if address, err = conf.FieldInt("address"); err != nil {
+		return
+	}
+	if address < 0 || address > math.MaxUint16 {
+		err = fmt.Errorf("tag %v: invalid address: %v", tag.name, address)
+		return
+	}
+	tag.address = uint16(address)
+
+	if tag.dataType, err = conf.FieldString("data_type"); err != nil {
+		return
+	}
+	if tag.function == funcReadCoils || tag.function == funcReadDiscreteInputs {
+		tag.dataType = "bool"
+	} else if _, exists = registerCounts[tag.dataType]; !exists {
+		err = fmt.Errorf("tag %v: data_type not recognised: %v", tag.name, tag.dataType)
+		return
+	}
+
+	tag.scale, err = conf.FieldFloat("scale")
+	return
+}
+
+type modbusInput struct {
+	address         string
+	unitID          byte
+	interval        time.Duration
+	timeout         time.Duration
+	littleWordOrder bool
+	tags            []modbusTag
+
+	log *service.Logger
+
+	clientMut sync.Mutex
+	client    *tcpClient
+	nextPoll  time.Time
+}
+
+func newModbusInputFromConfig(conf *service.ParsedConfig, log *service.Logger) (*modbusInput, error) {
+	m := &modbusInput{log: log}
+
+	var err error
+	if m.address, err = conf.FieldString("address"); err != nil {
+		return nil, err
+	}
+	unitID, err := conf.FieldInt("unit_id")
+	if err != nil {
+		return nil, err
+	}
+	if unitID < 0 || unitID > math.MaxUint8 {
+		return nil, fmt.Errorf("invalid unit_id: %v", unitID)
+	}
+	m.unitID = byte(unitID)
+	if m.interval, err = durationField(conf, "interval"); err != nil {
+		return nil, err
+	}
+	if m.timeout, err = durationField(conf, "timeout"); err != nil {
+		return nil, err
+	}
+
+	wordOrder, err := conf.FieldString("word_order")
+	if err != nil {
+		return nil, err
+	}
+	switch wordOrder {
+	case "big":
+	case "little":
+		m.littleWordOrder = true
+	default:
+		return nil, fmt.Errorf("word_order not recognised: %v", wordOrder)
+	}
+
+	tagConfs, err := conf.FieldObjectList("tags")
+	if err != nil {
+		return nil, err
+	}
+	if len(tagConfs) == 0 {
+		return nil, errors.New("at least one tag must be specified")
+	}
+	seen := map[string]struct{}{}
+	for _, tc := range tagConfs {
+		tag, err := tagFromParsed(tc)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := seen[tag.name]; exists {
+			return nil, fmt.Errorf("duplicate tag name: %v", tag.name)
+		}
+		seen[tag.name] = struct{}{}
+		m.tags = append(m.tags, tag)
+	}
+	return m, nil
+}
+
+func durationField(conf *service.ParsedConfig, name string) (time.Duration, error) {
+	str, err := conf.FieldString(name)
+	if err != nil {
+		return 0, err
+	}
+	d, err := time.ParseDuration(str)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse field '%v' as duration: %w", name, err)
+	}
+	return d, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (m *modbusInput) Connect(ctx context.Context) error {
+	m.clientMut.Lock()
+	defer m.clientMut.Unlock()
+
+	if m.client != nil {
+		return nil
+	}
+
+	client, err := dialTCPClient(ctx, m.address, m.unitID, m.timeout)
+	if err != nil {
+		return err
+	}
+	m.client = client
+
+	m.log.Infof("Polling Modbus device at: %v\n", m.address)
+	return nil
+}
+
+func (m *modbusInput) readTag(tag modbusTag) (interface{}, error) {
+	if tag.dataType == "bool" {
+		data, err := m.client.read(tag.function, tag.address, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < 1 {
+			return nil, fmt.Errorf("tag %v: empty response", tag.name)
+		}
+		return data[0]&0x01 == 1, nil
+	}
+
+	count := registerCounts[tag.dataType]
+	data, err := m.client.read(tag.function, tag.address, count)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != int(count)*2 {
+		return nil, fmt.Errorf("tag %v: expected %v bytes, received %v", tag.name, count*2, len(data))
+	}
+	if m.littleWordOrder {
+		// Reverse the order of the registers whilst preserving the byte
+		// order within each register.
+		for i, j := 0, len(data)-2; i < j; i, j = i+2, j-2 {
+			data[i], data[i+1], data[j], data[j+1] = data[j], data[j+1], data[i], data[i+1]
+		}
+	}
+
+	var v interface{}
+	switch tag.dataType {
+	case "uint16":
+		v = binary.BigEndian.Uint16(data)
+	case "int16":
+		v = int16(binary.BigEndian.Uint16(data))
+	case "uint32":
+		v = binary.BigEndian.Uint32(data)
+	case "int32":
+		v = int32(binary.BigEndian.Uint32(data))
+	case "float32":
+		v = math.Float32frombits(binary.BigEndian.Uint32(data))
+	case "uint64":
+		v = binary.BigEndian.Uint64(data)
+	case "int64":
+		v = int64(binary.BigEndian.Uint64(data))
+	case "float64":
+		v = math.Float64frombits(binary.BigEndian.Uint64(data))
+	}
+
+	if tag.scale != 1 {
+		v = toFloat64(v) * tag.scale
+	}
+	return v, nil
+}
+
+func toFloat64(v interface{}) float64 {
+	switch t := v.(type) {
+	case uint16:
+		return float64(t)
+	case int16:
+		return float64(t)
+	case uint32:
+		return float64(t)
+	case int32:
+		return float64(t)
+	case float32:
+		return float64(t)
+	case uint64:
+		return float64(t)
+	case int64:
+		return float64(t)
+	case float64:
+		return t
+	}
+	return 0
+}
+
+func (m *modbusInput) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	m.clientMut.Lock()
+	defer m.clientMut.Unlock()
+
+	if m.client == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+
+	if wait := time.Until(m.nextPoll); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+	m.nextPoll = time.Now().Add(m.interval)
+
+	readings := make(map[string]interface{}, len(m.tags))
+	for _, tag := range m.tags {
+		v, err := m.readTag(tag)
+		if err != nil {
+			var exErr *exceptionError
+			if errors.As(err, &exErr) {
+				return nil, nil, fmt.Errorf("failed to read tag %v: %w", tag.name, err)
+			}
+			m.log.Errorf("Lost connection to Modbus device due to: %v\n", err)
+			m.client.Close()
+			m.client = nil
+			return nil, nil, service.ErrNotConnected
+		}
+		readings[tag.name] = v
+	}
+
+	b, err := json.Marshal(readings)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msg := service.NewMessage(b)
+	msg.MetaSet("modbus_address", m.address)
+	msg.MetaSet("modbus_unit_id", strconv.Itoa(int(m.unitID)))
+	return msg, func(ctx context.Context, err error) error {
+		// Readings are a snapshot and cannot be redelivered.
+		return nil
+	}, nil
+}
+
+func (m *modbusInput) Close(ctx context.Context) error {
+	m.clientMut.Lock()
+	defer m.clientMut.Unlock()
+
+	if m.client != nil {
+		m.client.Close()
+		m.client = nil
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runTestServer starts a Modbus TCP server that responds to read requests
+// with the provided register values and coil states.
+func runTestServer(t *testing.T, registers map[uint16]uint16, coils map[uint16]bool) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ln.Close()
+	})
+
+	handle := func(conn net.Conn) {
+		defer conn.Close()
+		for {
+			req := make([]byte, 12)
+			if _, err := io.ReadFull(conn, req); err != nil {
+				return
+			}
+			function := req[7]
+			address := binary.BigEndian.Uint16(req[8:])
+			quantity := binary.BigEndian.Uint16(req[10:])
+
+			var pdu []byte
+			switch function {
+			case funcReadHoldingRegisters:
+				pdu = []byte{function, byte(quantity * 2)}
+				for i := uint16(0); i < quantity; i++ {
+					v, exists := registers[address+i]
+					if !exists {
+						pdu = []byte{function | 0x80, 0x02}
+						break
+					}
+					pdu = append(pdu, byte(v>>8), byte(v))
+				}
+			case funcReadCoils:
+				var b byte
+				if coils[address] {
+					b = 1
+				}
+				pdu = []byte{function, 1, b}
+			default:
+				pdu = []byte{function | 0x80, 0x01}
+			}
+
+			res := make([]byte, 7, 7+len(pdu))
+			copy(res, req[:4])
+			binary.BigEndian.PutUint16(res[4:], uint16(len(pdu)+1))
+			res[6] = req[6]
+			res = append(res, pdu...)
+			if _, err := conn.Write(res); err != nil {
+				return
+			}
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestModbusInputReadsTags(t *testing.T) {
+	addr := runTestServer(t, map[uint16]uint16{
+		0:  215,
+		1:  0xFFFF,
+		10: 0x0001,
+		11: 0x0002,
+		20: 0x41AC,
+		21: 0x0000,
+	}, map[uint16]bool{
+		5: true,
+	})
+
+	m := &modbusInput{
+		address:  addr,
+		unitID:   1,
+		interval: time.Millisecond,
+		timeout:  time.Second,
+		tags: []modbusTag{
+			{name: "temperature", function: funcReadHoldingRegisters, address: 0, dataType: "uint16", scale: 0.1},
+			{name: "offset", function: funcReadHoldingRegisters, address: 1, dataType: "int16", scale: 1},
+			{name: "counter", function: funcReadHoldingRegisters, address: 10, dataType: "uint32", scale: 1},
+			{name: "pressure", function: funcReadHoldingRegisters, address: 20, dataType: "float32", scale: 1},
+			{name: "pump_running", function: funcReadCoils, address: 5, dataType: "bool", scale: 1},
+		},
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, m.Connect(ctx))
+	t.Cleanup(func() {
+		_ = m.Close(context.Background())
+	})
+
+	for i := 0; i < 2; i++ {
+		msg, ackFn, err := m.Read(ctx)
+		require.NoError(t, err)
+		require.NoError(t, ackFn(ctx, nil))
+
+		b, err := msg.AsBytes()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+  "temperature": 21.5,
+  "offset": -1,
+  "counter": 65538,
+  "pressure": 21.5,
+  "pump_running": true
+}`, string(b))
+
+		unitID, _ := msg.MetaGet("modbus_unit_id")
+		assert.Equal(t, "1", unitID)
+	}
+}
+
+func TestModbusInputWordOrder(t *testing.T) {
+	addr := runTestServer(t, map[uint16]uint16{
+		0: 0x0002,
+		1: 0x0001,
+	}, nil)
+
+	m := &modbusInput{
+		address:         addr,
+		interval:        time.Millisecond,
+		timeout:         time.Second,
+		littleWordOrder: true,
+		tags: []modbusTag{
+			{name: "counter", function: funcReadHoldingRegisters, address: 0, dataType: "uint32", scale: 1},
+		},
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, m.Connect(ctx))
+	t.Cleanup(func() {
+		_ = m.Close(context.Background())
+	})
+
+	msg, _, err := m.Read(ctx)
+	require.NoError(t, err)
+
+	b, err := msg.AsBytes()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"counter":65538}`, string(b))
+}
+
+func TestModbusInputException(t *testing.T) {
+	addr := runTestServer(t, map[uint16]uint16{}, nil)
+
+	m := &modbusInput{
+		address:  addr,
+		interval: time.Millisecond,
+		timeout:  time.Second,
+		tags: []modbusTag{
+			{name: "missing", function: funcReadHoldingRegisters, address: 100, dataType: "uint16", scale: 1},
+		},
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, m.Connect(ctx))
+	t.Cleanup(func() {
+		_ = m.Close(context.Background())
+	})
+
+	_, _, err := m.Read(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "illegal data address")
+
+	// The connection remains usable after an exception.
+	m.clientMut.Lock()
+	assert.NotNil(t, m.client)
+	m.clientMut.Unlock()
+}
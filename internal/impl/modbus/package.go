@@ -0,0 +1,3 @@
+// Package modbus contains component implementations for polling industrial
+// devices over the Modbus TCP protocol.
+package modbus
@@ -0,0 +1,344 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/checkpoint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSlot holds the changes of a replication slot, including transaction
+// markers, which fakeSlotDriver serves to pg_logical_slot_peek_changes queries,
+// and records slot advances.
+type fakeSlot struct {
+	mut      sync.Mutex
+	changes  [][]driver.Value
+	peeks    [][]driver.Value
+	advances []string
+}
+
+func (f *fakeSlot) add(changes ...[]driver.Value) {
+	f.mut.Lock()
+	f.changes = append(f.changes, changes...)
+	f.mut.Unlock()
+}
+
+// fakeTxn returns the changes of a transaction wrapped in begin and commit
+// markers. LSNs in these tests are compared lexicographically, and so the
+// changes of a transaction are given the LSN prefix followed by their index,
+// with the begin and commit markers at 0 and 9 respectively.
+func fakeTxn(lsnPrefix, xid string, changes ...string) [][]driver.Value {
+	rows := [][]driver.Value{{lsnPrefix + "0", xid, `{"action":"B"}`}}
+	for i, c := range changes {
+		rows = append(rows, []driver.Value{fmt.Sprintf("%v%v", lsnPrefix, i+1), xid, c})
+	}
+	return append(rows, []driver.Value{lsnPrefix + "9", xid, `{"action":"C"}`})
+}
+
+type fakeSlotConn struct {
+	slot *fakeSlot
+}
+
+func (c *fakeSlotConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeSlotStmt{slot: c.slot, query: query}, nil
+}
+func (c *fakeSlotConn) Close() error              { return nil }
+func (c *fakeSlotConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type fakeSlotStmt struct {
+	slot  *fakeSlot
+	query string
+}
+
+func (s *fakeSlotStmt) Close() error  { return nil }
+func (s *fakeSlotStmt) NumInput() int { return -1 }
+
+func (s *fakeSlotStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.slot.mut.Lock()
+	defer s.slot.mut.Unlock()
+	if strings.Contains(s.query, "pg_replication_slot_advance") {
+		s.slot.advances = append(s.slot.advances, args[1].(string))
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (s *fakeSlotStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.slot.mut.Lock()
+	defer s.slot.mut.Unlock()
+	s.slot.peeks = append(s.slot.peeks, args)
+
+	confirmed := "0/0"
+	if len(s.slot.advances) > 0 {
+		confirmed = s.slot.advances[len(s.slot.advances)-1]
+	}
+	includeTxn := strings.Contains(s.query, "'include-transaction', 'true'")
+
+	// As with postgres the limit of changes applies from the confirmed
+	// position of the slot, where decoding stops at the end of the transaction
+	// in which the limit is reached, and only then are changes after the LSN
+	// given as the last argument selected.
+	//
+	// A transaction is decoded in full unless the slot has been advanced to
+	// the end of it.
+	commits := map[string]string{}
+	for _, c := range s.slot.changes {
+		if strings.HasPrefix(c[2].(string), `{"action":"C"`) {
+			commits[c[1].(string)] = c[0].(string)
+		}
+	}
+	var peeked [][]driver.Value
+	for _, c := range s.slot.changes {
+		if commits[c[1].(string)] <= confirmed {
+			continue
+		}
+		if len(peeked) >= int(args[1].(int64)) && strings.HasPrefix(c[2].(string), `{"action":"B"`) {
+			break
+		}
+		if !includeTxn && (strings.HasPrefix(c[2].(string), `{"action":"B"`) || strings.HasPrefix(c[2].(string), `{"action":"C"`)) {
+			continue
+		}
+		peeked = append(peeked, c)
+	}
+
+	var rows [][]driver.Value
+	for _, c := range peeked {
+		if c[0].(string) > args[2].(string) {
+			rows = append(rows, c)
+		}
+	}
+	return &fakeSlotRows{rows: rows}, nil
+}
+
+type fakeSlotRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeSlotRows) Columns() []string { return []string{"lsn", "xid", "data"} }
+func (r *fakeSlotRows) Close() error      { return nil }
+
+func (r *fakeSlotRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var registerFakeSlotOnce sync.Once
+
+func newFakeSlotInput(t *testing.T, changes [][]driver.Value, maxPending int) (*cdcInput, *fakeSlot) {
+	t.Helper()
+
+	slot := &fakeSlot{changes: changes}
+	registerFakeSlotOnce.Do(func() {
+		sql.Register("fake_slot", &fakeSlotDriver{})
+	})
+	fakeSlots.Store(t.Name(), slot)
+
+	db, err := sql.Open("fake_slot", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return &cdcInput{
+		slotName:          "foo",
+		maxPendingChanges: maxPending,
+		pollInterval:      time.Millisecond,
+		db:                db,
+		readLSN:           "0/0",
+		checkpointer:      checkpoint.NewCapped(10),
+	}, slot
+}
+
+// fakeSlots maps data source names to slots as sql.Register can only be called
+// once per driver name.
+var fakeSlots sync.Map
+
+type fakeSlotDriver struct{}
+
+func (fakeSlotDriver) Open(name string) (driver.Conn, error) {
+	slot, _ := fakeSlots.Load(name)
+	return &fakeSlotConn{slot.(*fakeSlot)}, nil
+}
+
+//------------------------------------------------------------------------------
+
+func TestCDCInputReadChanges(t *testing.T) {
+	c, slot := newFakeSlotInput(t, append(
+		fakeTxn("0/A", "500",
+			`{"action":"I","table":"foo","columns":[{"name":"id","type":"integer","value":1}]}`,
+			`{"action":"U","table":"foo","columns":[{"name":"id","type":"integer","value":1}]}`,
+		),
+		fakeTxn("0/B", "501",
+			`{"action":"D","table":"foo","identity":[{"name":"id","type":"integer","value":1}]}`,
+		)...,
+	), 2)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	batch, ackFn, err := c.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.Len(t, batch, 2)
+
+	b, err := batch[0].AsBytes()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"action":"I","table":"foo","columns":[{"name":"id","type":"integer","value":1}]}`, string(b))
+
+	lsn, _ := batch[1].MetaGet("postgres_lsn")
+	assert.Equal(t, "0/A2", lsn)
+	xid, _ := batch[1].MetaGet("postgres_xid")
+	assert.Equal(t, "500", xid)
+
+	require.NoError(t, ackFn(ctx, nil))
+
+	batch, _, err = c.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+
+	lsn, _ = batch[0].MetaGet("postgres_lsn")
+	assert.Equal(t, "0/B1", lsn)
+	xid, _ = batch[0].MetaGet("postgres_xid")
+	assert.Equal(t, "501", xid)
+
+	slot.mut.Lock()
+	require.Len(t, slot.peeks, 2)
+	assert.Equal(t, []driver.Value{"foo", int64(2), "0/0"}, slot.peeks[0])
+	assert.Equal(t, "0/A9", slot.peeks[1][2])
+	slot.mut.Unlock()
+}
+
+func TestCDCInputTransactionMarkers(t *testing.T) {
+	c, _ := newFakeSlotInput(t, fakeTxn("0/A", "500", `{"action":"I"}`), 10)
+	c.includeTxnMarkers = true
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	batch, _, err := c.ReadBatch(ctx)
+	require.NoError(t, err)
+
+	var actions []string
+	for _, msg := range batch {
+		b, err := msg.AsBytes()
+		require.NoError(t, err)
+		actions = append(actions, string(b))
+	}
+	assert.Equal(t, []string{`{"action":"B"}`, `{"action":"I"}`, `{"action":"C"}`}, actions)
+}
+
+func TestCDCInputFullWindow(t *testing.T) {
+	c, slot := newFakeSlotInput(t, append(
+		fakeTxn("0/A", "500", `{"action":"I"}`, `{"action":"I"}`),
+		fakeTxn("0/B", "501", `{"action":"I"}`)...,
+	), 2)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	batch, ackFn, err := c.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.Len(t, batch, 2)
+
+	// Whilst the changes of the window are unacknowledged every peek only
+	// returns changes that have already been read.
+	waitCtx, waitDone := context.WithTimeout(ctx, time.Millisecond*50)
+	_, _, err = c.ReadBatch(waitCtx)
+	waitDone()
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	slot.mut.Lock()
+	assert.Greater(t, len(slot.peeks), 2)
+	slot.mut.Unlock()
+
+	require.NoError(t, ackFn(ctx, nil))
+
+	batch, _, err = c.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+
+	lsn, _ := batch[0].MetaGet("postgres_lsn")
+	assert.Equal(t, "0/B1", lsn)
+}
+
+func TestCDCInputAdvanceOnAck(t *testing.T) {
+	c, slot := newFakeSlotInput(t, fakeTxn("0/A", "500", `{"action":"I"}`), 10)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	var acks []func(context.Context, error) error
+	for _, txn := range [][][]driver.Value{
+		nil,
+		fakeTxn("0/B", "501", `{"action":"I"}`),
+		fakeTxn("0/C", "502", `{"action":"I"}`),
+	} {
+		slot.add(txn...)
+		_, ackFn, err := c.ReadBatch(ctx)
+		require.NoError(t, err)
+		acks = append(acks, ackFn)
+	}
+
+	advances := func() []string {
+		slot.mut.Lock()
+		defer slot.mut.Unlock()
+		return append([]string{}, slot.advances...)
+	}
+
+	// The slot is not advanced past changes that are yet to be delivered, and
+	// is advanced to the end of each transaction.
+	require.NoError(t, acks[1](ctx, nil))
+	assert.Empty(t, advances())
+
+	require.NoError(t, acks[0](ctx, nil))
+	assert.Equal(t, []string{"0/B9"}, advances())
+
+	require.NoError(t, acks[2](ctx, nil))
+	assert.Equal(t, []string{"0/B9", "0/C9"}, advances())
+}
+
+func TestCDCInputNoRedeliveryAfterRestart(t *testing.T) {
+	c, slot := newFakeSlotInput(t, append(
+		fakeTxn("0/A", "500", `{"action":"I"}`),
+		fakeTxn("0/B", "501", `{"action":"U"}`)...,
+	), 1)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	_, ackFn, err := c.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ackFn(ctx, nil))
+
+	// A new input reading from the same slot continues after the transaction
+	// that was acknowledged.
+	restarted := &cdcInput{
+		slotName:          c.slotName,
+		maxPendingChanges: c.maxPendingChanges,
+		pollInterval:      c.pollInterval,
+		db:                c.db,
+		readLSN:           "0/0",
+		checkpointer:      checkpoint.NewCapped(10),
+	}
+
+	batch, _, err := restarted.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+
+	b, err := batch[0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, `{"action":"U"}`, string(b))
+
+	slot.mut.Lock()
+	assert.Equal(t, []string{"0/A9"}, slot.advances)
+	slot.mut.Unlock()
+}
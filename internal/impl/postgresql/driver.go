@@ -0,0 +1,9 @@
+//go:build !wasm
+// +build !wasm
+
+package postgresql
+
+// Import drivers that aren't supported by WASM builds.
+import (
+	_ "github.com/lib/pq"
+)
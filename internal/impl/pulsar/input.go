@@ -3,6 +3,7 @@ package pulsar
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -57,6 +58,8 @@ You can access these metadata fields using
 			),
 			docs.FieldString("topics", "A list of topics to subscribe to.").Array(),
 			docs.FieldCommon("subscription_name", "Specify the subscription name for this consumer."),
+			docs.FieldAdvanced("subscription_type", "Specify the subscription type for this consumer. A `key_shared` subscription distributes messages across consumers by key, and `exclusive` or `failover` subscriptions allow only one active consumer at a time.").
+				HasOptions("shared", "key_shared", "failover", "exclusive").AtVersion("3.54.0"),
 		).ChildDefaultAndTypesFromStruct(input.NewPulsarConfig()),
 	})
 }
@@ -67,9 +70,10 @@ type pulsarReader struct {
 	client   pulsar.Client
 	consumer pulsar.Consumer
 
-	conf  input.PulsarConfig
-	stats metrics.Type
-	log   log.Modular
+	conf    input.PulsarConfig
+	subType pulsar.SubscriptionType
+	stats   metrics.Type
+	log     log.Modular
 
 	m       sync.RWMutex
 	shutSig *shutdown.Signaller
@@ -85,7 +89,12 @@ func newPulsarReader(conf input.PulsarConfig, log log.Modular, stats metrics.Typ
 	if conf.SubscriptionName == "" {
 		return nil, errors.New("field subscription_name must not be empty")
 	}
+	subType, err := parseSubscriptionType(conf.SubscriptionType)
+	if err != nil {
+		return nil, err
+	}
 	p := pulsarReader{
+		subType: subType,
 		conf:    conf,
 		stats:   stats,
 		log:     log,
@@ -94,6 +103,22 @@ func newPulsarReader(conf input.PulsarConfig, log log.Modular, stats metrics.Typ
 	return &p, nil
 }
 
+func parseSubscriptionType(subType string) (pulsar.SubscriptionType, error) {
+	// Pulsar defines "Exclusive" as the default subscription type, but we
+	// historically defaulted to "Shared", so we keep that for compatibility.
+	switch subType {
+	case "shared", "":
+		return pulsar.Shared, nil
+	case "key_shared":
+		return pulsar.KeyShared, nil
+	case "failover":
+		return pulsar.Failover, nil
+	case "exclusive":
+		return pulsar.Exclusive, nil
+	}
+	return pulsar.Shared, fmt.Errorf("subscription type %v not recognised", subType)
+}
+
 //------------------------------------------------------------------------------
 
 // ConnectWithContext establishes a connection to an Pulsar server.
@@ -122,7 +147,7 @@ func (p *pulsarReader) ConnectWithContext(ctx context.Context) error {
 	if consumer, err = client.Subscribe(pulsar.ConsumerOptions{
 		Topics:           p.conf.Topics,
 		SubscriptionName: p.conf.SubscriptionName,
-		Type:             pulsar.Shared,
+		Type:             p.subType,
 	}); err != nil {
 		client.Close()
 		return err
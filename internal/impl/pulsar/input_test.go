@@ -0,0 +1,41 @@
+package pulsar
+
+import (
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/input"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPulsarReaderSubscriptionType(t *testing.T) {
+	for subType, exp := range map[string]pulsar.SubscriptionType{
+		"":           pulsar.Shared,
+		"shared":     pulsar.Shared,
+		"key_shared": pulsar.KeyShared,
+		"failover":   pulsar.Failover,
+		"exclusive":  pulsar.Exclusive,
+	} {
+		conf := input.NewPulsarConfig()
+		conf.URL = "pulsar://localhost:6650"
+		conf.Topics = []string{"foo"}
+		conf.SubscriptionName = "foo"
+		conf.SubscriptionType = subType
+
+		r, err := newPulsarReader(conf, log.Noop(), metrics.Noop())
+		require.NoError(t, err, subType)
+		assert.Equal(t, exp, r.subType, subType)
+	}
+
+	conf := input.NewPulsarConfig()
+	conf.URL = "pulsar://localhost:6650"
+	conf.Topics = []string{"foo"}
+	conf.SubscriptionName = "foo"
+	conf.SubscriptionType = "nope"
+
+	_, err := newPulsarReader(conf, log.Noop(), metrics.Noop())
+	require.EqualError(t, err, "subscription type nope not recognised")
+}
@@ -3,16 +3,19 @@ package pulsar
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/Jeffail/benthos/v3/internal/batch"
+	"github.com/Jeffail/benthos/v3/internal/bloblang"
+	"github.com/Jeffail/benthos/v3/internal/bloblang/field"
 	"github.com/Jeffail/benthos/v3/internal/bundle"
 	"github.com/Jeffail/benthos/v3/internal/docs"
 	"github.com/Jeffail/benthos/v3/internal/shutdown"
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/output"
-	"github.com/Jeffail/benthos/v3/lib/output/writer"
 	"github.com/Jeffail/benthos/v3/lib/types"
 	"github.com/apache/pulsar-client-go/pulsar"
 )
@@ -23,17 +26,18 @@ func init() {
 		if err != nil {
 			return nil, err
 		}
-		o, err := output.NewAsyncWriter(output.TypePulsar, c.Pulsar.MaxInFlight, w, nm.Logger(), nm.Metrics())
-		if err != nil {
-			return nil, err
-		}
-		return output.OnlySinglePayloads(o), nil
+		return output.NewAsyncWriter(output.TypePulsar, c.Pulsar.MaxInFlight, w, nm.Logger(), nm.Metrics())
 	}), docs.ComponentSpec{
 		Name:    output.TypePulsar,
 		Type:    docs.TypeOutput,
 		Status:  docs.StatusExperimental,
 		Version: "3.43.0",
 		Summary: `Write messages to an Apache Pulsar server.`,
+		Description: `
+All messages of a batch are handed to the producer before waiting for their
+acknowledgements, and are therefore published within the same producer batches
+where possible. Increasing ` + "`max_in_flight`" + ` allows messages of separate
+batches to be grouped in the same way.`,
 		Categories: []string{
 			string(output.CategoryServices),
 		},
@@ -45,6 +49,8 @@ func init() {
 				"pulsar+ssl://pulsar.us-west.example.com:6651",
 			),
 			docs.FieldCommon("topic", "A topic to publish to."),
+			docs.FieldCommon("key", "The key to publish messages with.").IsInterpolated().AtVersion("3.54.0"),
+			docs.FieldAdvanced("key_based_batching", "Whether messages should be batched by the producer according to their key, ensuring that each batch contains messages of a single key. This is required when consumers use a `key_shared` subscription. Messages are only grouped when they are pending within the producer at the same time, which is the case for messages of the same batch or of separate batches in flight.").AtVersion("3.54.0"),
 			docs.FieldCommon("max_in_flight", "The maximum number of messages to have in flight at a given time. Increase this to improve throughput."),
 		).ChildDefaultAndTypesFromStruct(output.NewPulsarConfig()),
 	})
@@ -57,6 +63,7 @@ type pulsarWriter struct {
 	producer pulsar.Producer
 
 	conf  output.PulsarConfig
+	key   *field.Expression
 	stats metrics.Type
 	log   log.Modular
 
@@ -77,6 +84,10 @@ func newPulsarWriter(conf output.PulsarConfig, log log.Modular, stats metrics.Ty
 		log:     log,
 		shutSig: shutdown.NewSignaller(),
 	}
+	var err error
+	if p.key, err = bloblang.NewField(conf.Key); err != nil {
+		return nil, fmt.Errorf("failed to parse key expression: %v", err)
+	}
 	return &p, nil
 }
 
@@ -104,9 +115,7 @@ func (p *pulsarWriter) ConnectWithContext(ctx context.Context) error {
 		return err
 	}
 
-	if producer, err = client.CreateProducer(pulsar.ProducerOptions{
-		Topic: p.conf.Topic,
-	}); err != nil {
+	if producer, err = client.CreateProducer(p.producerOptions()); err != nil {
 		client.Close()
 		return err
 	}
@@ -118,6 +127,16 @@ func (p *pulsarWriter) ConnectWithContext(ctx context.Context) error {
 	return nil
 }
 
+func (p *pulsarWriter) producerOptions() pulsar.ProducerOptions {
+	opts := pulsar.ProducerOptions{
+		Topic: p.conf.Topic,
+	}
+	if p.conf.KeyBasedBatching {
+		opts.BatcherBuilderType = pulsar.KeyBasedBatchBuilder
+	}
+	return opts
+}
+
 // disconnect safely closes a connection to an Pulsar server.
 func (p *pulsarWriter) disconnect(ctx context.Context) error {
 	p.m.Lock()
@@ -155,13 +174,62 @@ func (p *pulsarWriter) WriteWithContext(ctx context.Context, msg types.Message)
 		return types.ErrNotConnected
 	}
 
-	return writer.IterateBatchedSend(msg, func(i int, p types.Part) error {
-		m := &pulsar.ProducerMessage{
-			Payload: p.Get(),
-		}
-		_, err := r.Send(context.Background(), m)
+	// Every message is queued before waiting on any acknowledgements so that
+	// the producer is able to group them into batches.
+	var wg sync.WaitGroup
+	var batchErr *batch.Error
+	var batchErrMut sync.Mutex
+
+	wg.Add(msg.Len())
+	for i := 0; i < msg.Len(); i++ {
+		index := i
+		r.SendAsync(context.Background(), p.producerMessage(i, msg), func(_ pulsar.MessageID, _ *pulsar.ProducerMessage, err error) {
+			if err != nil {
+				batchErrMut.Lock()
+				if batchErr == nil {
+					batchErr = batch.NewError(msg, err)
+				}
+				batchErr.Failed(index, err)
+				batchErrMut.Unlock()
+			}
+			wg.Done()
+		})
+	}
+	if err := r.Flush(); err != nil {
+		// Whether the queued messages were published is unknown, and so the
+		// batch as a whole is failed in order for it to be retried.
+		p.log.Errorf("Failed to flush pulsar producer: %v\n", err)
 		return err
-	})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if batchErr != nil {
+		if msg.Len() == 1 {
+			return batchErr.Unwrap()
+		}
+		return batchErr
+	}
+	return nil
+}
+
+func (p *pulsarWriter) producerMessage(i int, msg types.Message) *pulsar.ProducerMessage {
+	m := &pulsar.ProducerMessage{
+		Payload: msg.Get(i).Get(),
+	}
+	if key := p.key.String(i, msg); key != "" {
+		m.Key = key
+	}
+	return m
 }
 
 // CloseAsync shuts down the Pulsar input and stops processing requests.
@@ -0,0 +1,202 @@
+package pulsar
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/batch"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/output"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPulsarWriterKeys(t *testing.T) {
+	conf := output.NewPulsarConfig()
+	conf.URL = "pulsar://localhost:6650"
+	conf.Topic = "foo"
+	conf.Key = `${! json("device") }`
+	conf.KeyBasedBatching = true
+
+	w, err := newPulsarWriter(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	opts := w.producerOptions()
+	assert.Equal(t, "foo", opts.Topic)
+	assert.Equal(t, pulsar.KeyBasedBatchBuilder, opts.BatcherBuilderType)
+
+	msg := message.New([][]byte{
+		[]byte(`{"device":"a"}`),
+		[]byte(`{"device":"b"}`),
+		[]byte(`{"device":"a"}`),
+		[]byte(`{}`),
+	})
+
+	byKey := map[string][]string{}
+	for i := 0; i < msg.Len(); i++ {
+		m := w.producerMessage(i, msg)
+		byKey[m.Key] = append(byKey[m.Key], string(m.Payload))
+	}
+	assert.Equal(t, map[string][]string{
+		"a":    {`{"device":"a"}`, `{"device":"a"}`},
+		"b":    {`{"device":"b"}`},
+		"null": {`{}`},
+	}, byKey)
+}
+
+func TestPulsarWriterDefaultOptions(t *testing.T) {
+	conf := output.NewPulsarConfig()
+	conf.URL = "pulsar://localhost:6650"
+	conf.Topic = "foo"
+
+	w, err := newPulsarWriter(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	assert.Equal(t, pulsar.DefaultBatchBuilder, w.producerOptions().BatcherBuilderType)
+
+	m := w.producerMessage(0, message.New([][]byte{[]byte("hello")}))
+	assert.Equal(t, "", m.Key)
+	assert.Equal(t, "hello", string(m.Payload))
+}
+
+type pendingSend struct {
+	msg      *pulsar.ProducerMessage
+	callback func(pulsar.MessageID, *pulsar.ProducerMessage, error)
+}
+
+// fakeKeyBatchProducer mimics a producer with key based batching, where all
+// messages pending at the time of a flush are published in batches of a
+// single key.
+type fakeKeyBatchProducer struct {
+	pulsar.Producer
+
+	mut      sync.Mutex
+	pending  []pendingSend
+	batches  [][]string
+	sendErr  error
+	flushErr error
+}
+
+func (f *fakeKeyBatchProducer) SendAsync(ctx context.Context, msg *pulsar.ProducerMessage, callback func(pulsar.MessageID, *pulsar.ProducerMessage, error)) {
+	f.mut.Lock()
+	f.pending = append(f.pending, pendingSend{msg: msg, callback: callback})
+	f.mut.Unlock()
+}
+
+func (f *fakeKeyBatchProducer) Flush() error {
+	f.mut.Lock()
+	pending := f.pending
+	f.pending = nil
+
+	var keys []string
+	byKey := map[string][]string{}
+	for _, p := range pending {
+		if _, exists := byKey[p.msg.Key]; !exists {
+			keys = append(keys, p.msg.Key)
+		}
+		byKey[p.msg.Key] = append(byKey[p.msg.Key], string(p.msg.Payload))
+	}
+	for _, k := range keys {
+		f.batches = append(f.batches, byKey[k])
+	}
+	sendErr, flushErr := f.sendErr, f.flushErr
+	f.mut.Unlock()
+
+	for _, p := range pending {
+		var err error
+		if string(p.msg.Payload) == "fail" {
+			err = sendErr
+		}
+		p.callback(nil, p.msg, err)
+	}
+	return flushErr
+}
+
+func TestPulsarWriterKeyBasedBatches(t *testing.T) {
+	conf := output.NewPulsarConfig()
+	conf.URL = "pulsar://localhost:6650"
+	conf.Topic = "foo"
+	conf.Key = `${! json("device") }`
+	conf.KeyBasedBatching = true
+
+	w, err := newPulsarWriter(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	producer := &fakeKeyBatchProducer{}
+	w.producer = producer
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, w.WriteWithContext(ctx, message.New([][]byte{
+		[]byte(`{"device":"a","n":1}`),
+		[]byte(`{"device":"b","n":2}`),
+		[]byte(`{"device":"a","n":3}`),
+		[]byte(`{"device":"a","n":4}`),
+	})))
+
+	assert.Equal(t, [][]string{
+		{`{"device":"a","n":1}`, `{"device":"a","n":3}`, `{"device":"a","n":4}`},
+		{`{"device":"b","n":2}`},
+	}, producer.batches)
+}
+
+func TestPulsarWriterSendErrors(t *testing.T) {
+	conf := output.NewPulsarConfig()
+	conf.URL = "pulsar://localhost:6650"
+	conf.Topic = "foo"
+
+	w, err := newPulsarWriter(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	assert.Equal(t, types.ErrNotConnected, w.WriteWithContext(ctx, message.New([][]byte{[]byte("foo")})))
+
+	sendErr := errors.New("nope")
+	w.producer = &fakeKeyBatchProducer{sendErr: sendErr}
+
+	assert.Equal(t, sendErr, w.WriteWithContext(ctx, message.New([][]byte{[]byte("fail")})))
+
+	err = w.WriteWithContext(ctx, message.New([][]byte{
+		[]byte("foo"), []byte("fail"), []byte("bar"),
+	}))
+	var bErr *batch.Error
+	require.True(t, errors.As(err, &bErr))
+
+	var failed []int
+	bErr.WalkParts(func(i int, _ types.Part, err error) bool {
+		if err != nil {
+			failed = append(failed, i)
+		}
+		return true
+	})
+	assert.Equal(t, []int{1}, failed)
+}
+
+func TestPulsarWriterFlushError(t *testing.T) {
+	conf := output.NewPulsarConfig()
+	conf.URL = "pulsar://localhost:6650"
+	conf.Topic = "foo"
+
+	w, err := newPulsarWriter(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	flushErr := errors.New("flush failed")
+	w.producer = &fakeKeyBatchProducer{flushErr: flushErr}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	assert.Equal(t, flushErr, w.WriteWithContext(ctx, message.New([][]byte{
+		[]byte("foo"), []byte("bar"),
+	})))
+}
@@ -0,0 +1,206 @@
+package shm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+func shmInputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Local").
+		Summary("Consumes messages from a ring buffer within a shared memory file, written to by a co-located process.").
+		Description(`
+This input, along with the ` + "[`shm` output](/docs/components/outputs/shm)" + `, allows messages to be handed between Benthos and other processes on the same machine with very low latency, as each message is written to and read from memory mapped by both processes rather than passing through a socket.
+
+A ring has exactly one producer and exactly one consumer. The file is created with the configured capacity when it does not already exist, otherwise the capacity of the existing file is used. Placing the file within a memory backed filesystem such as ` + "`/dev/shm`" + ` avoids writes to disk.
+
+Each message is released from the ring once it has been acknowledged, and therefore messages that were consumed but not yet acknowledged when Benthos stops are consumed again on restart. Rejected messages are retried until they are acknowledged.
+
+Only the raw contents of messages are transferred, metadata is not.
+
+### Ring Layout
+
+The ring layout is described by the C header ` + "`internal/impl/shm/shm_ring.h`" + ` of the Benthos repository, which can be used to implement a producer or consumer in other languages. A file begins with a 192 byte header containing a magic number, a version, the capacity of the data region and the read and write positions, followed by the data region holding length prefixed records.`).
+		Field(service.NewStringField("path").
+			Description("The path of the ring file.").
+			Example("/dev/shm/benthos_ring")).
+		Field(service.NewIntField("capacity").
+			Description("The capacity in bytes of the data region of the ring when it is created by this component, which must be a multiple of 8.").
+			Default(4 * 1024 * 1024).
+			Advanced()).
+		Field(service.NewStringField("poll_interval").
+			Description("The period to wait before checking the ring again when it is empty.").
+			Default("100us").
+			Advanced())
+}
+
+func init() {
+	err := service.RegisterInput(
+		"shm", shmInputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			i, err := newShmInputFromConfig(conf, mgr.Logger())
+			if err != nil {
+				return nil, err
+			}
+			return service.AutoRetryNacks(i), nil
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type ringConfig struct {
+	path         string
+	capacity     uint64
+	pollInterval time.Duration
+}
+
+func ringConfigFromParsed(conf *service.ParsedConfig) (c ringConfig, err error) {
+	if c.path, err = conf.FieldString("path"); err != nil {
+		return
+	}
+	if c.path == "" {
+		err = errors.New("field path must not be empty")
+		return
+	}
+	capacity, err := conf.FieldInt("capacity")
+	if err != nil {
+		return
+	}
+	if capacity <= 0 || capacity%ringAlign != 0 {
+		err = fmt.Errorf("field capacity must be a positive multiple of %v, got %v", ringAlign, capacity)
+		return
+	}
+	c.capacity = uint64(capacity)
+
+	pollStr, err := conf.FieldString("poll_interval")
+	if err != nil {
+		return
+	}
+	if c.pollInterval, err = time.ParseDuration(pollStr); err != nil {
+		err = fmt.Errorf("failed to parse field 'poll_interval' as duration: %w", err)
+	}
+	return
+}
+
+// shmPending is a record that has been read from the ring and is yet to be
+// released.
+type shmPending struct {
+	next  uint64
+	acked bool
+}
+
+type shmInput struct {
+	conf ringConfig
+	log  *service.Logger
+
+	mut     sync.Mutex
+	ring    *ring
+	cursor  uint64
+	pending []*shmPending
+}
+
+func newShmInputFromConfig(conf *service.ParsedConfig, log *service.Logger) (*shmInput, error) {
+	rConf, err := ringConfigFromParsed(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &shmInput{conf: rConf, log: log}, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (s *shmInput) Connect(ctx context.Context) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.ring != nil {
+		return nil
+	}
+
+	r, err := openRingFile(s.conf.path, s.conf.capacity)
+	if err != nil {
+		return err
+	}
+
+	s.ring = r
+	s.cursor = r.readPos()
+	s.pending = nil
+
+	s.log.Infof("Consuming messages from shared memory ring: %v\n", s.conf.path)
+	return nil
+}
+
+func (s *shmInput) tryRead() (*service.Message, service.AckFunc, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.ring == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+
+	p, next, ok, err := s.ring.read(s.cursor)
+	if err != nil || !ok {
+		return nil, nil, err
+	}
+	s.cursor = next
+
+	r := s.ring
+	pending := &shmPending{next: next}
+	s.pending = append(s.pending, pending)
+
+	return service.NewMessage(p), func(ctx context.Context, res error) error {
+		s.mut.Lock()
+		defer s.mut.Unlock()
+
+		// Records are released in the order that they were read, and
+		// therefore only once all records before them are acknowledged.
+		pending.acked = true
+		if s.ring != r {
+			return nil
+		}
+		i := 0
+		for ; i < len(s.pending) && s.pending[i].acked; i++ {
+			r.commit(s.pending[i].next)
+		}
+		s.pending = s.pending[i:]
+		return nil
+	}, nil
+}
+
+func (s *shmInput) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	for {
+		msg, ackFn, err := s.tryRead()
+		if err != nil || msg != nil {
+			return msg, ackFn, err
+		}
+		select {
+		case <-time.After(s.conf.pollInterval):
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+func (s *shmInput) Close(ctx context.Context) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.ring == nil {
+		return nil
+	}
+	err := s.ring.close()
+	s.ring = nil
+	s.pending = nil
+	return err
+}
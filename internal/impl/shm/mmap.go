@@ -0,0 +1,16 @@
+// +build !windows,!wasm
+
+package shm
+
+import (
+	"os"
+	"syscall"
+)
+
+func mmapFile(f *os.File, size int) ([]byte, error) {
+	return syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+}
+
+func munmapFile(mem []byte) error {
+	return syscall.Munmap(mem)
+}
@@ -0,0 +1,18 @@
+// +build windows wasm
+
+package shm
+
+import (
+	"errors"
+	"os"
+)
+
+var errMmapUnsupported = errors.New("shared memory rings are not supported on this platform")
+
+func mmapFile(f *os.File, size int) ([]byte, error) {
+	return nil, errMmapUnsupported
+}
+
+func munmapFile(mem []byte) error {
+	return errMmapUnsupported
+}
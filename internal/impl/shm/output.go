@@ -0,0 +1,131 @@
+package shm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+func shmOutputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Local").
+		Summary("Writes messages to a ring buffer within a shared memory file, read by a co-located process.").
+		Description(`
+This output, along with the ` + "[`shm` input](/docs/components/inputs/shm)" + `, allows messages to be handed between Benthos and other processes on the same machine with very low latency, as each message is written to and read from memory mapped by both processes rather than passing through a socket.
+
+A ring has exactly one producer and exactly one consumer. The file is created with the configured capacity when it does not already exist, otherwise the capacity of the existing file is used. Placing the file within a memory backed filesystem such as ` + "`/dev/shm`" + ` avoids writes to disk.
+
+A message is acknowledged once it has been written to the ring, and when the ring is full writes are blocked until the consumer releases enough space. Messages larger than the capacity of the ring are rejected.
+
+Only the raw contents of messages are transferred, metadata is not.
+
+### Ring Layout
+
+The ring layout is described by the C header ` + "`internal/impl/shm/shm_ring.h`" + ` of the Benthos repository, which can be used to implement a producer or consumer in other languages. A file begins with a 192 byte header containing a magic number, a version, the capacity of the data region and the read and write positions, followed by the data region holding length prefixed records.`).
+		Field(service.NewStringField("path").
+			Description("The path of the ring file.").
+			Example("/dev/shm/benthos_ring")).
+		Field(service.NewIntField("capacity").
+			Description("The capacity in bytes of the data region of the ring when it is created by this component, which must be a multiple of 8.").
+			Default(4 * 1024 * 1024).
+			Advanced()).
+		Field(service.NewStringField("poll_interval").
+			Description("The period to wait before checking the ring again when it is full.").
+			Default("100us").
+			Advanced())
+}
+
+func init() {
+	err := service.RegisterOutput(
+		"shm", shmOutputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Output, int, error) {
+			o, err := newShmOutputFromConfig(conf, mgr.Logger())
+			return o, 1, err
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type shmOutput struct {
+	conf ringConfig
+	log  *service.Logger
+
+	mut  sync.Mutex
+	ring *ring
+}
+
+func newShmOutputFromConfig(conf *service.ParsedConfig, log *service.Logger) (*shmOutput, error) {
+	rConf, err := ringConfigFromParsed(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &shmOutput{conf: rConf, log: log}, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (s *shmOutput) Connect(ctx context.Context) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.ring != nil {
+		return nil
+	}
+
+	r, err := openRingFile(s.conf.path, s.conf.capacity)
+	if err != nil {
+		return err
+	}
+	s.ring = r
+
+	s.log.Infof("Writing messages to shared memory ring: %v\n", s.conf.path)
+	return nil
+}
+
+func (s *shmOutput) tryWrite(p []byte) (bool, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.ring == nil {
+		return false, service.ErrNotConnected
+	}
+	return s.ring.write(p)
+}
+
+func (s *shmOutput) Write(ctx context.Context, msg *service.Message) error {
+	p, err := msg.AsBytes()
+	if err != nil {
+		return err
+	}
+	for {
+		written, err := s.tryWrite(p)
+		if err != nil || written {
+			return err
+		}
+		select {
+		case <-time.After(s.conf.pollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *shmOutput) Close(ctx context.Context) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.ring == nil {
+		return nil
+	}
+	err := s.ring.close()
+	s.ring = nil
+	return err
+}
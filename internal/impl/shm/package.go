@@ -0,0 +1,3 @@
+// Package shm contains component implementations for exchanging messages with
+// co-located processes through a ring buffer within a shared memory file.
+package shm
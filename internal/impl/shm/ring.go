@@ -0,0 +1,206 @@
+package shm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"unsafe"
+)
+
+// The layout of a ring file, which is also described for other processes by
+// the C header shm_ring.h within this package. All integers are little endian
+// and the read and write positions are updated atomically.
+const (
+	ringMagic   uint32 = 0x52485342 // "BSHR"
+	ringVersion uint32 = 1
+
+	ringOffMagic    = 0
+	ringOffVersion  = 4
+	ringOffCapacity = 8
+	ringOffWritePos = 64
+	ringOffReadPos  = 128
+	ringHeaderSize  = 192
+
+	ringWrapMarker uint32 = 0xFFFFFFFF
+	ringAlign             = 8
+)
+
+var (
+	errRingNotReady = errors.New("ring file has not been initialised")
+	errRingTooLarge = errors.New("message exceeds the capacity of the ring")
+)
+
+// ring is a single producer, single consumer ring buffer of length prefixed
+// records within shared memory. The write and read positions are byte offsets
+// that increase monotonically, and are mapped into the data region modulo its
+// capacity. A record that does not fit before the end of the data region is
+// preceded by a wrap marker and written at the start instead.
+type ring struct {
+	mem      []byte
+	data     []byte
+	capacity uint64
+}
+
+func ringRecordSize(n int) uint64 {
+	return (uint64(n) + 4 + ringAlign - 1) &^ (ringAlign - 1)
+}
+
+// openRingFile maps a ring file into memory, creating and initialising it with
+// a capacity when it does not already exist. The capacity of an existing file
+// is read from its header.
+func openRingFile(path string, capacity uint64) (*ring, error) {
+	if capacity < ringAlign || capacity%ringAlign != 0 {
+		return nil, fmt.Errorf("ring capacity must be a positive multiple of %v bytes", ringAlign)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	if err == nil {
+		defer f.Close()
+		if err = f.Truncate(int64(ringHeaderSize + capacity)); err != nil {
+			return nil, err
+		}
+		mem, err := mmapFile(f, int(ringHeaderSize+capacity))
+		if err != nil {
+			return nil, err
+		}
+		return initRing(mem, capacity), nil
+	}
+	if !os.IsExist(err) {
+		return nil, err
+	}
+
+	if f, err = os.OpenFile(path, os.O_RDWR, 0); err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < ringHeaderSize {
+		return nil, errRingNotReady
+	}
+	mem, err := mmapFile(f, int(info.Size()))
+	if err != nil {
+		return nil, err
+	}
+	r, err := openRing(mem)
+	if err != nil {
+		_ = munmapFile(mem)
+		return nil, err
+	}
+	return r, nil
+}
+
+// initRing writes the header of a new ring, where the magic number is written
+// last in order to signal that the ring is ready.
+func initRing(mem []byte, capacity uint64) *ring {
+	r := &ring{
+		mem:      mem,
+		data:     mem[ringHeaderSize : ringHeaderSize+capacity],
+		capacity: capacity,
+	}
+	binary.LittleEndian.PutUint32(mem[ringOffVersion:], ringVersion)
+	binary.LittleEndian.PutUint64(mem[ringOffCapacity:], capacity)
+	atomic.StoreUint64(r.pos(ringOffWritePos), 0)
+	atomic.StoreUint64(r.pos(ringOffReadPos), 0)
+	atomic.StoreUint32((*uint32)(unsafe.Pointer(&mem[ringOffMagic])), ringMagic)
+	return r
+}
+
+func openRing(mem []byte) (*ring, error) {
+	if atomic.LoadUint32((*uint32)(unsafe.Pointer(&mem[ringOffMagic]))) != ringMagic {
+		return nil, errRingNotReady
+	}
+	if v := binary.LittleEndian.Uint32(mem[ringOffVersion:]); v != ringVersion {
+		return nil, fmt.Errorf("unsupported ring version: %v", v)
+	}
+	capacity := binary.LittleEndian.Uint64(mem[ringOffCapacity:])
+	if capacity%ringAlign != 0 || uint64(len(mem)) < ringHeaderSize+capacity {
+		return nil, fmt.Errorf("ring capacity %v does not match file size %v", capacity, len(mem))
+	}
+	return &ring{
+		mem:      mem,
+		data:     mem[ringHeaderSize : ringHeaderSize+capacity],
+		capacity: capacity,
+	}, nil
+}
+
+func (r *ring) pos(offset int) *uint64 {
+	return (*uint64)(unsafe.Pointer(&r.mem[offset]))
+}
+
+// write appends a record to the ring, returning false when there isn't enough
+// free space for it.
+func (r *ring) write(p []byte) (bool, error) {
+	need := ringRecordSize(len(p))
+	if need > r.capacity || uint64(len(p)) >= uint64(ringWrapMarker) {
+		return false, errRingTooLarge
+	}
+
+	w := atomic.LoadUint64(r.pos(ringOffWritePos))
+	rd := atomic.LoadUint64(r.pos(ringOffReadPos))
+
+	idx := w % r.capacity
+	total := need
+	if tail := r.capacity - idx; need > tail {
+		total += tail
+	}
+	if r.capacity-(w-rd) < total {
+		return false, nil
+	}
+	if total > need {
+		binary.LittleEndian.PutUint32(r.data[idx:], ringWrapMarker)
+		w += r.capacity - idx
+		idx = 0
+	}
+
+	binary.LittleEndian.PutUint32(r.data[idx:], uint32(len(p)))
+	copy(r.data[idx+4:], p)
+	atomic.StoreUint64(r.pos(ringOffWritePos), w+need)
+	return true, nil
+}
+
+// read returns a copy of the record at a position along with the position of
+// the following record, or false when no record has been written there yet.
+func (r *ring) read(pos uint64) ([]byte, uint64, bool, error) {
+	w := atomic.LoadUint64(r.pos(ringOffWritePos))
+	if pos == w {
+		return nil, pos, false, nil
+	}
+
+	idx := pos % r.capacity
+	n := binary.LittleEndian.Uint32(r.data[idx:])
+	if n == ringWrapMarker {
+		pos += r.capacity - idx
+		idx = 0
+		n = binary.LittleEndian.Uint32(r.data[idx:])
+	}
+
+	next := pos + ringRecordSize(int(n))
+	if next > w || idx+4+uint64(n) > r.capacity {
+		return nil, pos, false, fmt.Errorf("corrupted record at position %v", pos)
+	}
+
+	p := make([]byte, n)
+	copy(p, r.data[idx+4:])
+	return p, next, true, nil
+}
+
+// readPos returns the position of the oldest record that has not been
+// consumed.
+func (r *ring) readPos() uint64 {
+	return atomic.LoadUint64(r.pos(ringOffReadPos))
+}
+
+// commit releases all records before a position to be overwritten.
+func (r *ring) commit(pos uint64) {
+	atomic.StoreUint64(r.pos(ringOffReadPos), pos)
+}
+
+func (r *ring) close() error {
+	return munmapFile(r.mem)
+}
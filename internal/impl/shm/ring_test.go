@@ -0,0 +1,173 @@
+// +build !windows,!wasm
+
+package shm
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingWrapAround(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+
+	producer, err := openRingFile(path, 64)
+	require.NoError(t, err)
+	defer producer.close()
+
+	consumer, err := openRingFile(path, 1024)
+	require.NoError(t, err)
+	defer consumer.close()
+	assert.Equal(t, uint64(64), consumer.capacity)
+
+	var pos uint64
+	for i := 0; i < 20; i++ {
+		msg := []byte(fmt.Sprintf("message %v", i))
+		ok, err := producer.write(msg)
+		require.NoError(t, err)
+		require.True(t, ok, i)
+
+		p, next, ok, err := consumer.read(pos)
+		require.NoError(t, err)
+		require.True(t, ok, i)
+		assert.Equal(t, string(msg), string(p))
+
+		pos = next
+		consumer.commit(pos)
+	}
+
+	_, _, ok, err := consumer.read(pos)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestRingFull(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+
+	r, err := openRingFile(path, 64)
+	require.NoError(t, err)
+	defer r.close()
+
+	_, err = r.write(make([]byte, 61))
+	assert.Equal(t, errRingTooLarge, err)
+
+	// Each record occupies 24 bytes.
+	for i := 0; i < 2; i++ {
+		ok, err := r.write(make([]byte, 20))
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	ok, err := r.write(make([]byte, 20))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Releasing the first record frees enough space for a record written at
+	// the start of the ring, skipping the remaining bytes at the end.
+	_, next, _, err := r.read(0)
+	require.NoError(t, err)
+	r.commit(next)
+
+	ok, err = r.write([]byte("0123456789abcdefghij"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, next, _, err = r.read(next)
+	require.NoError(t, err)
+
+	p, next, ok, err := r.read(next)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "0123456789abcdefghij", string(p))
+	assert.Equal(t, uint64(88), next)
+}
+
+func TestShmInputOutput(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	conf := ringConfig{
+		path:         filepath.Join(t.TempDir(), "ring"),
+		capacity:     128,
+		pollInterval: time.Millisecond,
+	}
+
+	out := &shmOutput{conf: conf}
+	require.NoError(t, out.Connect(ctx))
+
+	in := &shmInput{conf: conf}
+	require.NoError(t, in.Connect(ctx))
+
+	n := 100
+	go func() {
+		for i := 0; i < n; i++ {
+			assert.NoError(t, out.Write(ctx, service.NewMessage([]byte(fmt.Sprintf("hello world %v", i)))))
+		}
+	}()
+
+	var acks []service.AckFunc
+	for i := 0; i < n; i++ {
+		msg, ackFn, err := in.Read(ctx)
+		require.NoError(t, err)
+
+		b, err := msg.AsBytes()
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("hello world %v", i), string(b))
+
+		// Acknowledge in reverse pairs, records are only released once all
+		// records before them are acknowledged.
+		if acks = append(acks, ackFn); len(acks) == 2 {
+			require.NoError(t, acks[1](ctx, nil))
+			require.NoError(t, acks[0](ctx, nil))
+			acks = nil
+		}
+	}
+
+	require.NoError(t, in.Close(ctx))
+	require.NoError(t, out.Close(ctx))
+}
+
+func TestShmInputRedeliversUnacked(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	conf := ringConfig{
+		path:         filepath.Join(t.TempDir(), "ring"),
+		capacity:     1024,
+		pollInterval: time.Millisecond,
+	}
+
+	out := &shmOutput{conf: conf}
+	require.NoError(t, out.Connect(ctx))
+	defer out.Close(ctx)
+
+	for _, s := range []string{"foo", "bar"} {
+		require.NoError(t, out.Write(ctx, service.NewMessage([]byte(s))))
+	}
+
+	in := &shmInput{conf: conf}
+	require.NoError(t, in.Connect(ctx))
+
+	_, ackFn, err := in.Read(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ackFn(ctx, nil))
+
+	_, _, err = in.Read(ctx)
+	require.NoError(t, err)
+	require.NoError(t, in.Close(ctx))
+
+	require.NoError(t, in.Connect(ctx))
+	msg, _, err := in.Read(ctx)
+	require.NoError(t, err)
+
+	b, err := msg.AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, "bar", string(b))
+
+	require.NoError(t, in.Close(ctx))
+}
@@ -0,0 +1,59 @@
+/*
+ * Layout of the shared memory ring files used by the Benthos shm input and
+ * output. A ring has exactly one producer and one consumer, which can be
+ * either Benthos or any co-located process that maps the same file.
+ *
+ * All integers are little endian. The file begins with a header followed by a
+ * data region of `capacity` bytes, where capacity is a multiple of 8.
+ *
+ * write_pos and read_pos are byte offsets that only ever increase, a position
+ * maps to the byte `pos % capacity` of the data region. Only the producer
+ * modifies write_pos and only the consumer modifies read_pos, and both must be
+ * loaded and stored atomically with acquire and release semantics.
+ *
+ * Each record is a uint32_t payload length followed by the payload, padded to
+ * a multiple of 8 bytes. When a record does not fit before the end of the data
+ * region the producer writes SHM_RING_WRAP_MARKER in place of a length and
+ * writes the record at the start of the data region instead, skipping the
+ * remaining bytes. The producer must wait until a record, including any
+ * skipped bytes, fits within `capacity - (write_pos - read_pos)` bytes.
+ *
+ * A producer publishes a record by storing the position following it to
+ * write_pos. A consumer releases records by storing the position following the
+ * last record it has finished with to read_pos.
+ *
+ * The process that creates a file initialises the header and stores the magic
+ * number last, a file without the magic number is not yet ready to be used.
+ */
+
+#ifndef BENTHOS_SHM_RING_H
+#define BENTHOS_SHM_RING_H
+
+#include <stdint.h>
+
+#define SHM_RING_MAGIC 0x52485342u /* "BSHR" */
+#define SHM_RING_VERSION 1u
+#define SHM_RING_WRAP_MARKER 0xFFFFFFFFu
+#define SHM_RING_ALIGN 8u
+
+struct shm_ring_header {
+	uint32_t magic;
+	uint32_t version;
+	uint64_t capacity;
+	uint8_t _pad0[48];
+
+	uint64_t write_pos;
+	uint8_t _pad1[56];
+
+	uint64_t read_pos;
+	uint8_t _pad2[56];
+};
+
+/* The data region begins immediately after the header at offset 192. */
+#define SHM_RING_DATA(hdr) ((uint8_t *)(hdr) + sizeof(struct shm_ring_header))
+
+/* The size of a record holding a payload of n bytes. */
+#define SHM_RING_RECORD_SIZE(n) \
+	(((uint64_t)(n) + 4u + SHM_RING_ALIGN - 1u) & ~(uint64_t)(SHM_RING_ALIGN - 1u))
+
+#endif /* BENTHOS_SHM_RING_H */
@@ -0,0 +1,3 @@
+// Package snmp contains component implementations for receiving SNMP
+// notifications.
+package snmp
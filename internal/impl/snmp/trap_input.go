@@ -0,0 +1,411 @@
+package snmp
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+	"github.com/gosnmp/gosnmp"
+)
+
+const (
+	oidSysUpTime   = ".1.3.6.1.2.1.1.3.0"
+	oidSnmpTrapOID = ".1.3.6.1.6.3.1.1.4.1.0"
+)
+
+var authProtocols = map[string]gosnmp.SnmpV3AuthProtocol{
+	"none":   gosnmp.NoAuth,
+	"MD5":    gosnmp.MD5,
+	"SHA":    gosnmp.SHA,
+	"SHA224": gosnmp.SHA224,
+	"SHA256": gosnmp.SHA256,
+	"SHA384": gosnmp.SHA384,
+	"SHA512": gosnmp.SHA512,
+}
+
+var privProtocols = map[string]gosnmp.SnmpV3PrivProtocol{
+	"none":    gosnmp.NoPriv,
+	"DES":     gosnmp.DES,
+	"AES":     gosnmp.AES,
+	"AES192":  gosnmp.AES192,
+	"AES256":  gosnmp.AES256,
+	"AES192C": gosnmp.AES192C,
+	"AES256C": gosnmp.AES256C,
+}
+
+func snmpTrapInputConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		// Stable(). TODO
+		Version("3.54.0").
+		Categories("Network").
+		Summary("Receives SNMP v2c and v3 traps and informs over UDP.").
+		Description(`
+Each notification received is converted into a JSON document containing the variable bindings of the notification:
+
+`+"```json"+`
+{
+  "version": "2c",
+  "community": "public",
+  "trap_oid": ".1.3.6.1.6.3.1.1.5.3",
+  "uptime": 123456,
+  "variables": [
+    {
+      "oid": ".1.3.6.1.2.1.2.2.1.1.2",
+      "type": "Integer",
+      "value": 2
+    }
+  ]
+}
+`+"```"+`
+
+Values of the type `+"`OctetString`"+` are converted into strings, and all numeric types are converted into numbers. Informs are acknowledged as soon as they are received.
+
+SNMP v3 notifications are accepted when a `+"`usm.user`"+` is configured, in which case they must be authenticated and encrypted according to the fields of `+"`usm`"+`. Since the keys of a notification are localised to the engine that sent it the field `+"`usm.engine_id`"+` must match the engine ID of the sender.
+
+Since SNMP notifications are delivered over UDP there are no delivery guarantees, and notifications received while the pipeline is applying back pressure might be dropped.
+
+### Metadata
+
+This input adds the following metadata fields to each message:
+
+`+"```text"+`
+- snmp_version
+- snmp_source
+- snmp_trap_oid
+`+"```"+`
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#metadata).`).
+		Field(service.NewStringField("address").
+			Description("The address to listen for notifications on.").
+			Default("0.0.0.0:162").
+			Example("0.0.0.0:1162")).
+		Field(service.NewStringField("community").
+			Description("When set, SNMP v2c notifications with a different community string are dropped.").
+			Default("")).
+		Field(service.NewObjectField("usm",
+			service.NewStringField("user").
+				Description("The user name that SNMP v3 notifications are sent with. When empty SNMP v3 notifications are not accepted.").
+				Default(""),
+			service.NewStringField("engine_id").
+				Description("The authoritative engine ID of the sender of SNMP v3 notifications as a hex string.").
+				Default("").
+				Example("8000000001020304"),
+			service.NewStringField("auth_protocol").
+				Description("The authentication protocol of SNMP v3 notifications, one of `none`, `MD5`, `SHA`, `SHA224`, `SHA256`, `SHA384` or `SHA512`.").
+				Default("none"),
+			service.NewStringField("auth_passphrase").
+				Description("The authentication passphrase of SNMP v3 notifications.").
+				Default(""),
+			service.NewStringField("priv_protocol").
+				Description("The privacy protocol of SNMP v3 notifications, one of `none`, `DES`, `AES`, `AES192`, `AES256`, `AES192C` or `AES256C`.").
+				Default("none"),
+			service.NewStringField("priv_passphrase").
+				Description("The privacy passphrase of SNMP v3 notifications.").
+				Default(""),
+		).
+			Description("User-based security parameters for SNMP v3 notifications.").
+			Advanced())
+}
+
+func init() {
+	err := service.RegisterInput(
+		"snmp_trap", snmpTrapInputConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			return newSNMPTrapInputFromConfig(conf, mgr.Logger())
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type snmpTrapInput struct {
+	address   string
+	community string
+	usm       *gosnmp.UsmSecurityParameters
+	msgFlags  gosnmp.SnmpV3MsgFlags
+
+	log *service.Logger
+
+	connMut  sync.Mutex
+	listener *gosnmp.TrapListener
+	msgChan  chan *service.Message
+	closeSig chan struct{}
+}
+
+func newSNMPTrapInputFromConfig(conf *service.ParsedConfig, log *service.Logger) (*snmpTrapInput, error) {
+	s := &snmpTrapInput{log: log}
+
+	var err error
+	if s.address, err = conf.FieldString("address"); err != nil {
+		return nil, err
+	}
+	if s.community, err = conf.FieldString("community"); err != nil {
+		return nil, err
+	}
+
+	user, err := conf.FieldString("usm", "user")
+	if err != nil {
+		return nil, err
+	}
+	if user == "" {
+		return s, nil
+	}
+
+	usm := &gosnmp.UsmSecurityParameters{
+		UserName: user,
+	}
+
+	engineIDStr, err := conf.FieldString("usm", "engine_id")
+	if err != nil {
+		return nil, err
+	}
+	engineID, err := hex.DecodeString(strings.TrimPrefix(engineIDStr, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse engine_id: %w", err)
+	}
+	usm.AuthoritativeEngineID = string(engineID)
+
+	authStr, err := conf.FieldString("usm", "auth_protocol")
+	if err != nil {
+		return nil, err
+	}
+	var exists bool
+	if usm.AuthenticationProtocol, exists = authProtocols[authStr]; !exists {
+		return nil, fmt.Errorf("auth_protocol not recognised: %v", authStr)
+	}
+	if usm.AuthenticationPassphrase, err = conf.FieldString("usm", "auth_passphrase"); err != nil {
+		return nil, err
+	}
+
+	privStr, err := conf.FieldString("usm", "priv_protocol")
+	if err != nil {
+		return nil, err
+	}
+	if usm.PrivacyProtocol, exists = privProtocols[privStr]; !exists {
+		return nil, fmt.Errorf("priv_protocol not recognised: %v", privStr)
+	}
+	if usm.PrivacyPassphrase, err = conf.FieldString("usm", "priv_passphrase"); err != nil {
+		return nil, err
+	}
+
+	s.msgFlags = gosnmp.NoAuthNoPriv
+	if usm.AuthenticationProtocol != gosnmp.NoAuth {
+		s.msgFlags = gosnmp.AuthNoPriv
+		if usm.PrivacyProtocol != gosnmp.NoPriv {
+			s.msgFlags = gosnmp.AuthPriv
+		}
+	} else if usm.PrivacyProtocol != gosnmp.NoPriv {
+		return nil, errors.New("priv_protocol requires an auth_protocol")
+	}
+
+	s.usm = usm
+	return s, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (s *snmpTrapInput) Connect(ctx context.Context) error {
+	s.connMut.Lock()
+	defer s.connMut.Unlock()
+
+	if s.listener != nil {
+		return nil
+	}
+
+	params := &gosnmp.GoSNMP{
+		Transport: "udp",
+		Version:   gosnmp.Version2c,
+		Community: s.community,
+	}
+	if s.usm != nil {
+		params.Version = gosnmp.Version3
+		params.SecurityModel = gosnmp.UserSecurityModel
+		params.MsgFlags = s.msgFlags
+		params.SecurityParameters = s.usm
+	}
+
+	msgChan := make(chan *service.Message)
+	closeSig := make(chan struct{})
+
+	listener := gosnmp.NewTrapListener()
+	listener.Params = params
+	listener.OnNewTrap = func(packet *gosnmp.SnmpPacket, addr *net.UDPAddr) {
+		msg, err := s.packetToMessage(packet, addr)
+		if err != nil {
+			s.log.Debugf("Dropping notification from %v: %v\n", addr, err)
+			return
+		}
+		select {
+		case msgChan <- msg:
+		case <-closeSig:
+		}
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- listener.Listen(s.address)
+	}()
+
+	select {
+	case <-listener.Listening():
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		// The listener might still bind the address, in which case it must be
+		// closed before giving up or the address remains in use.
+		close(closeSig)
+		select {
+		case <-listener.Listening():
+			listener.Close()
+			<-errChan
+		case <-errChan:
+		}
+		return ctx.Err()
+	}
+
+	go func() {
+		if err := <-errChan; err != nil {
+			s.log.Errorf("Listener failed: %v\n", err)
+		}
+		s.connMut.Lock()
+		if s.listener == listener {
+			s.listener = nil
+			s.msgChan = nil
+		}
+		s.connMut.Unlock()
+	}()
+
+	s.listener = listener
+	s.msgChan = msgChan
+	s.closeSig = closeSig
+
+	s.log.Infof("Receiving SNMP notifications at: %v\n", s.address)
+	return nil
+}
+
+func (s *snmpTrapInput) packetToMessage(packet *gosnmp.SnmpPacket, addr *net.UDPAddr) (*service.Message, error) {
+	doc := map[string]interface{}{}
+
+	switch packet.Version {
+	case gosnmp.Version2c:
+		if s.community != "" && packet.Community != s.community {
+			return nil, errors.New("community does not match")
+		}
+		doc["version"] = "2c"
+		doc["community"] = packet.Community
+	case gosnmp.Version3:
+		if s.usm == nil {
+			return nil, errors.New("SNMP v3 notifications are not enabled")
+		}
+		doc["version"] = "3"
+		if usm, ok := packet.SecurityParameters.(*gosnmp.UsmSecurityParameters); ok {
+			doc["user"] = usm.UserName
+		}
+	default:
+		return nil, fmt.Errorf("unsupported SNMP version: %v", packet.Version)
+	}
+
+	trapOID := ""
+	variables := make([]interface{}, 0, len(packet.Variables))
+	for _, v := range packet.Variables {
+		name := v.Name
+		if !strings.HasPrefix(name, ".") {
+			name = "." + name
+		}
+
+		value := pduValue(v)
+		switch name {
+		case oidSysUpTime:
+			doc["uptime"] = value
+			continue
+		case oidSnmpTrapOID:
+			trapOID, _ = value.(string)
+			doc["trap_oid"] = trapOID
+			continue
+		}
+
+		variables = append(variables, map[string]interface{}{
+			"oid":   name,
+			"type":  v.Type.String(),
+			"value": value,
+		})
+	}
+	doc["variables"] = variables
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := service.NewMessage(b)
+	msg.MetaSet("snmp_version", doc["version"].(string))
+	msg.MetaSet("snmp_source", addr.String())
+	msg.MetaSet("snmp_trap_oid", trapOID)
+	return msg, nil
+}
+
+// pduValue converts the value of a variable binding into a type that can be
+// serialised as JSON.
+func pduValue(v gosnmp.SnmpPDU) interface{} {
+	switch v.Type {
+	case gosnmp.OctetString:
+		if b, ok := v.Value.([]byte); ok {
+			return string(b)
+		}
+	case gosnmp.Null, gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView:
+		return nil
+	case gosnmp.ObjectIdentifier:
+		if s, ok := v.Value.(string); ok && !strings.HasPrefix(s, ".") {
+			return "." + s
+		}
+	case gosnmp.Opaque, gosnmp.BitString:
+		if b, ok := v.Value.([]byte); ok {
+			return hex.EncodeToString(b)
+		}
+	}
+	return v.Value
+}
+
+func (s *snmpTrapInput) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	s.connMut.Lock()
+	msgChan := s.msgChan
+	s.connMut.Unlock()
+
+	if msgChan == nil {
+		return nil, nil, service.ErrNotConnected
+	}
+
+	select {
+	case msg := <-msgChan:
+		return msg, func(ctx context.Context, err error) error {
+			// Notifications cannot be redelivered.
+			return nil
+		}, nil
+	case <-ctx.Done():
+	}
+	return nil, nil, ctx.Err()
+}
+
+func (s *snmpTrapInput) Close(ctx context.Context) error {
+	s.connMut.Lock()
+	listener, closeSig := s.listener, s.closeSig
+	s.listener = nil
+	s.msgChan = nil
+	s.closeSig = nil
+	s.connMut.Unlock()
+
+	if listener != nil {
+		close(closeSig)
+		listener.Close()
+	}
+	return nil
+}
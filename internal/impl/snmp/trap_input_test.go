@@ -0,0 +1,179 @@
+package snmp
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func freeUDPPort(t *testing.T) int {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	require.NoError(t, conn.Close())
+	return port
+}
+
+func TestSNMPTrapInputV2c(t *testing.T) {
+	port := freeUDPPort(t)
+
+	s := &snmpTrapInput{
+		address:   "127.0.0.1:" + strconv.Itoa(port),
+		community: "public",
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, s.Connect(ctx))
+	t.Cleanup(func() {
+		_ = s.Close(context.Background())
+	})
+
+	sendTrap := func(community string) {
+		t.Helper()
+
+		sender := &gosnmp.GoSNMP{
+			Target:    "127.0.0.1",
+			Port:      uint16(port),
+			Community: community,
+			Version:   gosnmp.Version2c,
+			Timeout:   time.Second,
+		}
+		require.NoError(t, sender.Connect())
+		defer sender.Conn.Close()
+
+		_, err := sender.SendTrap(gosnmp.SnmpTrap{
+			Variables: []gosnmp.SnmpPDU{
+				{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(1234)},
+				{Name: "1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+				{Name: "1.3.6.1.2.1.2.2.1.1.2", Type: gosnmp.Integer, Value: 2},
+				{Name: "1.3.6.1.2.1.2.2.1.2.2", Type: gosnmp.OctetString, Value: "eth0"},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	// Notifications with the wrong community are dropped.
+	sendTrap("private")
+	sendTrap("public")
+
+	msg, ackFn, err := s.Read(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ackFn(ctx, nil))
+
+	b, err := msg.AsBytes()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "version": "2c",
+  "community": "public",
+  "trap_oid": ".1.3.6.1.6.3.1.1.5.3",
+  "uptime": 1234,
+  "variables": [
+    {"oid": ".1.3.6.1.2.1.2.2.1.1.2", "type": "Integer", "value": 2},
+    {"oid": ".1.3.6.1.2.1.2.2.1.2.2", "type": "OctetString", "value": "eth0"}
+  ]
+}`, string(b))
+
+	trapOID, _ := msg.MetaGet("snmp_trap_oid")
+	assert.Equal(t, ".1.3.6.1.6.3.1.1.5.3", trapOID)
+
+	version, _ := msg.MetaGet("snmp_version")
+	assert.Equal(t, "2c", version)
+}
+
+func TestSNMPTrapInputConnectCancelled(t *testing.T) {
+	port := freeUDPPort(t)
+
+	s := &snmpTrapInput{
+		address:   "127.0.0.1:" + strconv.Itoa(port),
+		community: "public",
+	}
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// An abandoned connection attempt must not leave the address bound.
+	if err := s.Connect(cancelledCtx); err == nil {
+		require.NoError(t, s.Close(context.Background()))
+	} else {
+		assert.Equal(t, context.Canceled, err)
+	}
+	<-time.After(time.Millisecond * 100)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, s.Connect(ctx))
+	require.NoError(t, s.Close(ctx))
+}
+
+func TestSNMPTrapInputV3(t *testing.T) {
+	port := freeUDPPort(t)
+	engineID := "\x80\x00\x00\x00\x01\x02\x03\x04"
+
+	s := &snmpTrapInput{
+		address: "127.0.0.1:" + strconv.Itoa(port),
+		usm: &gosnmp.UsmSecurityParameters{
+			UserName:                 "benthos",
+			AuthoritativeEngineID:    engineID,
+			AuthenticationProtocol:   gosnmp.SHA,
+			AuthenticationPassphrase: "authpassphrase",
+			PrivacyProtocol:          gosnmp.AES,
+			PrivacyPassphrase:        "privpassphrase",
+		},
+		msgFlags: gosnmp.AuthPriv,
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	require.NoError(t, s.Connect(ctx))
+	t.Cleanup(func() {
+		_ = s.Close(context.Background())
+	})
+
+	sender := &gosnmp.GoSNMP{
+		Target:        "127.0.0.1",
+		Port:          uint16(port),
+		Version:       gosnmp.Version3,
+		Timeout:       time.Second,
+		SecurityModel: gosnmp.UserSecurityModel,
+		MsgFlags:      gosnmp.AuthPriv,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			UserName:                 "benthos",
+			AuthoritativeEngineID:    engineID,
+			AuthenticationProtocol:   gosnmp.SHA,
+			AuthenticationPassphrase: "authpassphrase",
+			PrivacyProtocol:          gosnmp.AES,
+			PrivacyPassphrase:        "privpassphrase",
+		},
+	}
+	require.NoError(t, sender.Connect())
+	defer sender.Conn.Close()
+
+	_, err := sender.SendTrap(gosnmp.SnmpTrap{
+		Variables: []gosnmp.SnmpPDU{
+			{Name: "1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.1"},
+		},
+	})
+	require.NoError(t, err)
+
+	msg, _, err := s.Read(ctx)
+	require.NoError(t, err)
+
+	b, err := msg.AsBytes()
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"version":"3"`)
+	assert.Contains(t, string(b), `"user":"benthos"`)
+	assert.Contains(t, string(b), `"trap_oid":".1.3.6.1.6.3.1.1.5.1"`)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"sync"
 
 	"github.com/Jeffail/benthos/v3/lib/types"
 )
@@ -187,3 +189,26 @@ func AccessRateLimit(ctx context.Context, mgr types.Manager, name string, fn fun
 	fn(c)
 	return nil
 }
+
+// AcquireShared obtains a resource that is shared under a key by the components
+// of a manager, creating it when no component currently holds it, and returns a
+// func to be called once the caller is finished with it. If the manager does
+// not support shared resources then a resource is created for the sole use of
+// the caller, and is closed when released.
+func AcquireShared(mgr types.Manager, key string, create func() (io.Closer, error)) (io.Closer, func(), error) {
+	if sm, ok := mgr.(interface {
+		AcquireShared(key string, create func() (io.Closer, error)) (io.Closer, func(), error)
+	}); ok {
+		return sm.AcquireShared(key, create)
+	}
+	res, err := create()
+	if err != nil {
+		return nil, nil, err
+	}
+	var releaseOnce sync.Once
+	return res, func() {
+		releaseOnce.Do(func() {
+			_ = res.Close()
+		})
+	}, nil
+}
@@ -0,0 +1,41 @@
+package alert
+
+// ThresholdConfig describes a threshold that a monitored value must exceed
+// for a period before an alert is fired.
+type ThresholdConfig struct {
+	Threshold float64 `json:"threshold" yaml:"threshold"`
+	Period    string  `json:"period" yaml:"period"`
+}
+
+// NewThresholdConfig creates a new ThresholdConfig with default values.
+func NewThresholdConfig() ThresholdConfig {
+	return ThresholdConfig{
+		Threshold: 0,
+		Period:    "30s",
+	}
+}
+
+// Config contains configuration fields for service alerts.
+type Config struct {
+	WebhookURL    string          `json:"webhook_url" yaml:"webhook_url"`
+	Output        string          `json:"output" yaml:"output"`
+	CheckInterval string          `json:"check_interval" yaml:"check_interval"`
+	ErrorRate     ThresholdConfig `json:"error_rate" yaml:"error_rate"`
+	BufferBacklog ThresholdConfig `json:"buffer_backlog" yaml:"buffer_backlog"`
+}
+
+// NewConfig creates a new Config with default values.
+func NewConfig() Config {
+	return Config{
+		WebhookURL:    "",
+		Output:        "",
+		CheckInterval: "1s",
+		ErrorRate:     NewThresholdConfig(),
+		BufferBacklog: NewThresholdConfig(),
+	}
+}
+
+// Enabled returns true if any alert thresholds have been configured.
+func (c Config) Enabled() bool {
+	return c.ErrorRate.Threshold > 0 || c.BufferBacklog.Threshold > 0
+}
@@ -0,0 +1,21 @@
+package alert
+
+import "github.com/Jeffail/benthos/v3/internal/docs"
+
+func thresholdSpec(name, desc, unit string) docs.FieldSpec {
+	return docs.FieldCommon(name, desc).WithChildren(
+		docs.FieldFloat("threshold", "The "+unit+" that must be exceeded in order to trigger the alert, a value of zero disables the alert.").HasDefault(0),
+		docs.FieldString("period", "The period of time that the threshold must be continuously exceeded before the alert is fired.").HasDefault("30s"),
+	)
+}
+
+// Spec returns a field spec for the alert configuration fields.
+func Spec() docs.FieldSpecs {
+	return docs.FieldSpecs{
+		docs.FieldString("webhook_url", "An optional URL to send alerts to as JSON documents via HTTP POST requests.", "https://hooks.example.com/benthos").HasDefault(""),
+		docs.FieldString("output", "An optional [output resource](/docs/configuration/resources) to write alerts to as JSON documents.").HasDefault(""),
+		docs.FieldString("check_interval", "The period between each check of the monitored values.").Advanced().HasDefault("1s"),
+		thresholdSpec("error_rate", "Fires an alert when the rate of errors, measured from all metrics ending with `error`, exceeds a threshold.", "number of errors per second"),
+		thresholdSpec("buffer_backlog", "Fires an alert when the backlog of the buffer, measured from the `buffer.backlog` metric, exceeds a threshold.", "size of the backlog"),
+	}
+}
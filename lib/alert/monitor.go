@@ -0,0 +1,371 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/interop"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/types"
+)
+
+// Alert describes a change in the state of a monitored value.
+type Alert struct {
+	Name      string  `json:"name"`
+	State     string  `json:"state"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	Period    string  `json:"period"`
+	Timestamp string  `json:"timestamp"`
+}
+
+// Alert states.
+const (
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+//------------------------------------------------------------------------------
+
+type threshold struct {
+	name      string
+	threshold float64
+	period    time.Duration
+	periodStr string
+
+	breachedSince time.Time
+	firing        bool
+}
+
+func newThreshold(name string, conf ThresholdConfig) (*threshold, error) {
+	if conf.Threshold <= 0 {
+		return nil, nil
+	}
+	period, err := time.ParseDuration(conf.Period)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %v period: %v", name, err)
+	}
+	return &threshold{
+		name:      name,
+		threshold: conf.Threshold,
+		period:    period,
+		periodStr: conf.Period,
+	}, nil
+}
+
+// check updates the state of the threshold with a new value and returns an
+// alert if the state has changed.
+func (t *threshold) check(value float64, now time.Time) *Alert {
+	if value <= t.threshold {
+		t.breachedSince = time.Time{}
+		if !t.firing {
+			return nil
+		}
+		t.firing = false
+		return t.alert(StateResolved, value, now)
+	}
+	if t.breachedSince.IsZero() {
+		t.breachedSince = now
+	}
+	if t.firing || now.Sub(t.breachedSince) < t.period {
+		return nil
+	}
+	t.firing = true
+	return t.alert(StateFiring, value, now)
+}
+
+func (t *threshold) alert(state string, value float64, now time.Time) *Alert {
+	return &Alert{
+		Name:      t.name,
+		State:     state,
+		Value:     value,
+		Threshold: t.threshold,
+		Period:    t.periodStr,
+		Timestamp: now.Format(time.RFC3339),
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// Monitor is a metrics.Type that wraps another and observes the error counters
+// and buffer backlog gauges registered with it, emitting alerts when any of the
+// configured thresholds are exceeded for a sustained period.
+type Monitor struct {
+	metrics.Type
+
+	conf          Config
+	checkInterval time.Duration
+	errorRate     *threshold
+	backlog       *threshold
+
+	log        log.Modular
+	httpClient *http.Client
+
+	errors      int64
+	gaugesMut   sync.Mutex
+	gauges      map[string]*int64
+	mgr         types.Manager
+	closeChan   chan struct{}
+	startedOnce sync.Once
+	closeOnce   sync.Once
+}
+
+// NewMonitor creates a new Monitor that wraps a metrics type. The monitor does
+// not begin checking thresholds until Start is called.
+func NewMonitor(conf Config, stats metrics.Type, log log.Modular) (*Monitor, error) {
+	m := &Monitor{
+		Type:       stats,
+		conf:       conf,
+		log:        log,
+		httpClient: &http.Client{Timeout: time.Second * 5},
+		gauges:     map[string]*int64{},
+		closeChan:  make(chan struct{}),
+	}
+
+	var err error
+	if m.checkInterval, err = time.ParseDuration(conf.CheckInterval); err != nil {
+		return nil, fmt.Errorf("failed to parse check_interval: %v", err)
+	}
+	if m.checkInterval <= 0 {
+		return nil, errors.New("check_interval must be greater than zero")
+	}
+	if m.errorRate, err = newThreshold("error_rate", conf.ErrorRate); err != nil {
+		return nil, err
+	}
+	if m.backlog, err = newThreshold("buffer_backlog", conf.BufferBacklog); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+//------------------------------------------------------------------------------
+
+func isErrorPath(path string) bool {
+	return path == "error" || strings.HasSuffix(path, ".error")
+}
+
+func isBacklogPath(path string) bool {
+	return path == "buffer.backlog" || strings.HasSuffix(path, ".buffer.backlog")
+}
+
+type errorCounter struct {
+	metrics.StatCounter
+	total *int64
+}
+
+func (e errorCounter) Incr(count int64) error {
+	atomic.AddInt64(e.total, count)
+	return e.StatCounter.Incr(count)
+}
+
+type errorCounterVec struct {
+	metrics.StatCounterVec
+	total *int64
+}
+
+func (e errorCounterVec) With(labelValues ...string) metrics.StatCounter {
+	return errorCounter{StatCounter: e.StatCounterVec.With(labelValues...), total: e.total}
+}
+
+type backlogGauge struct {
+	metrics.StatGauge
+	value *int64
+}
+
+func (b backlogGauge) Set(value int64) error {
+	atomic.StoreInt64(b.value, value)
+	return b.StatGauge.Set(value)
+}
+
+func (b backlogGauge) Incr(count int64) error {
+	atomic.AddInt64(b.value, count)
+	return b.StatGauge.Incr(count)
+}
+
+func (b backlogGauge) Decr(count int64) error {
+	atomic.AddInt64(b.value, -count)
+	return b.StatGauge.Decr(count)
+}
+
+// newBacklogGauge wraps a backlog gauge in order to track its value. A gauge
+// registered at a path that was previously registered, such as when a stream
+// is recreated, replaces the previous gauge so that stale values are ignored.
+func (m *Monitor) newBacklogGauge(path string, g metrics.StatGauge) metrics.StatGauge {
+	value := new(int64)
+	m.gaugesMut.Lock()
+	m.gauges[path] = value
+	m.gaugesMut.Unlock()
+	return backlogGauge{StatGauge: g, value: value}
+}
+
+// GetCounter returns an editable counter stat for a given path.
+func (m *Monitor) GetCounter(path string) metrics.StatCounter {
+	c := m.Type.GetCounter(path)
+	if isErrorPath(path) {
+		return errorCounter{StatCounter: c, total: &m.errors}
+	}
+	return c
+}
+
+// GetCounterVec returns an editable counter stat for a given path with labels.
+func (m *Monitor) GetCounterVec(path string, labelNames []string) metrics.StatCounterVec {
+	c := m.Type.GetCounterVec(path, labelNames)
+	if isErrorPath(path) {
+		return errorCounterVec{StatCounterVec: c, total: &m.errors}
+	}
+	return c
+}
+
+// GetGauge returns an editable gauge stat for a given path.
+func (m *Monitor) GetGauge(path string) metrics.StatGauge {
+	g := m.Type.GetGauge(path)
+	if isBacklogPath(path) {
+		return m.newBacklogGauge(path, g)
+	}
+	return g
+}
+
+// HandlerFunc returns an http.HandlerFunc for accessing metrics for appropriate
+// child types
+func (m *Monitor) HandlerFunc() http.HandlerFunc {
+	if wHandler, ok := m.Type.(metrics.WithHandlerFunc); ok {
+		return wHandler.HandlerFunc()
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(501)
+		w.Write([]byte("The child of this Monitor does not support HTTP metrics."))
+	}
+}
+
+// Close stops checking thresholds and closes the wrapped metrics type.
+func (m *Monitor) Close() error {
+	m.closeOnce.Do(func() {
+		close(m.closeChan)
+	})
+	return m.Type.Close()
+}
+
+//------------------------------------------------------------------------------
+
+// Start begins checking the configured thresholds. The manager is used in
+// order to access the output resource that alerts are written to.
+func (m *Monitor) Start(mgr types.Manager) error {
+	if m.conf.Output != "" {
+		if err := interop.ProbeOutput(context.Background(), mgr, m.conf.Output); err != nil {
+			return err
+		}
+	}
+	m.mgr = mgr
+	m.startedOnce.Do(func() {
+		go m.loop(atomic.LoadInt64(&m.errors))
+	})
+	return nil
+}
+
+func (m *Monitor) backlogValue() float64 {
+	m.gaugesMut.Lock()
+	defer m.gaugesMut.Unlock()
+	var total int64
+	for _, g := range m.gauges {
+		total += atomic.LoadInt64(g)
+	}
+	return float64(total)
+}
+
+func (m *Monitor) loop(lastErrors int64) {
+	ticker := time.NewTicker(m.checkInterval)
+	defer ticker.Stop()
+
+	lastCheck := time.Now()
+	for {
+		select {
+		case now := <-ticker.C:
+			if m.errorRate != nil {
+				errs := atomic.LoadInt64(&m.errors)
+				rate := float64(errs-lastErrors) / now.Sub(lastCheck).Seconds()
+				lastErrors = errs
+				if a := m.errorRate.check(rate, now); a != nil {
+					m.emit(*a)
+				}
+			}
+			if m.backlog != nil {
+				if a := m.backlog.check(m.backlogValue(), now); a != nil {
+					m.emit(*a)
+				}
+			}
+			lastCheck = now
+		case <-m.closeChan:
+			return
+		}
+	}
+}
+
+func (m *Monitor) emit(a Alert) {
+	if a.State == StateFiring {
+		m.log.Warnf("Alert %v is firing, value %v exceeded threshold %v for %v\n", a.Name, a.Value, a.Threshold, a.Period)
+	} else {
+		m.log.Infof("Alert %v has resolved, value %v is within threshold %v\n", a.Name, a.Value, a.Threshold)
+	}
+
+	body, err := json.Marshal(a)
+	if err != nil {
+		m.log.Errorf("Failed to serialise alert: %v\n", err)
+		return
+	}
+	if m.conf.WebhookURL != "" {
+		if err := m.sendWebhook(body); err != nil {
+			m.log.Errorf("Failed to send alert to webhook: %v\n", err)
+		}
+	}
+	if m.conf.Output != "" {
+		if err := m.writeOutput(body); err != nil {
+			m.log.Errorf("Failed to write alert to output resource '%v': %v\n", m.conf.Output, err)
+		}
+	}
+}
+
+func (m *Monitor) sendWebhook(body []byte) error {
+	res, err := m.httpClient.Post(m.conf.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code: %v", res.StatusCode)
+	}
+	return nil
+}
+
+func (m *Monitor) writeOutput(body []byte) error {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	resChan := make(chan types.Response, 1)
+
+	var err error
+	if aerr := interop.AccessOutput(ctx, m.mgr, m.conf.Output, func(o types.OutputWriter) {
+		err = o.WriteTransaction(ctx, types.NewTransaction(message.New([][]byte{body}), resChan))
+	}); aerr != nil {
+		return aerr
+	}
+	if err != nil {
+		return err
+	}
+
+	select {
+	case res := <-resChan:
+		return res.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
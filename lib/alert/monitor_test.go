@@ -0,0 +1,138 @@
+package alert
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThresholdCheck(t *testing.T) {
+	thres, err := newThreshold("foo", ThresholdConfig{
+		Threshold: 10,
+		Period:    "10s",
+	})
+	require.NoError(t, err)
+
+	now := time.Unix(1000, 0)
+	assert.Nil(t, thres.check(5, now))
+	assert.Nil(t, thres.check(15, now.Add(time.Second)))
+	assert.Nil(t, thres.check(15, now.Add(time.Second*5)))
+
+	a := thres.check(20, now.Add(time.Second*11))
+	require.NotNil(t, a)
+	assert.Equal(t, "foo", a.Name)
+	assert.Equal(t, StateFiring, a.State)
+	assert.Equal(t, float64(20), a.Value)
+	assert.Equal(t, float64(10), a.Threshold)
+
+	// Only fires once whilst the threshold remains exceeded.
+	assert.Nil(t, thres.check(20, now.Add(time.Second*12)))
+
+	a = thres.check(2, now.Add(time.Second*13))
+	require.NotNil(t, a)
+	assert.Equal(t, StateResolved, a.State)
+
+	// Breaching again restarts the period.
+	assert.Nil(t, thres.check(15, now.Add(time.Second*14)))
+	assert.Nil(t, thres.check(5, now.Add(time.Second*20)))
+	assert.Nil(t, thres.check(15, now.Add(time.Second*21)))
+	assert.Nil(t, thres.check(15, now.Add(time.Second*30)))
+	assert.NotNil(t, thres.check(15, now.Add(time.Second*31)))
+}
+
+func TestThresholdDisabled(t *testing.T) {
+	thres, err := newThreshold("foo", NewThresholdConfig())
+	require.NoError(t, err)
+	assert.Nil(t, thres)
+
+	_, err = newThreshold("foo", ThresholdConfig{Threshold: 1, Period: "nope"})
+	require.Error(t, err)
+}
+
+func TestMonitorMetrics(t *testing.T) {
+	stats := metrics.NewLocal()
+
+	conf := NewConfig()
+	conf.BufferBacklog.Threshold = 1
+
+	m, err := NewMonitor(conf, stats, log.Noop())
+	require.NoError(t, err)
+
+	m.GetCounter("pipeline.processor.0.error").Incr(2)
+	m.GetCounter("foo.error").Incr(3)
+	m.GetCounter("foo.sent").Incr(4)
+	m.GetCounterVec("bar.error", []string{"a"}).With("b").Incr(5)
+	assert.Equal(t, int64(10), m.errors)
+
+	m.GetGauge("buffer.backlog").Set(10)
+	strmBacklog := m.GetGauge("foo.buffer.backlog")
+	strmBacklog.Incr(7)
+	strmBacklog.Decr(2)
+	m.GetGauge("foo.bar").Set(100)
+	assert.Equal(t, float64(15), m.backlogValue())
+
+	// Registering a backlog gauge again replaces the previous one.
+	strmBacklog = m.GetGauge("foo.buffer.backlog")
+	assert.Equal(t, float64(10), m.backlogValue())
+	strmBacklog.Set(1)
+	assert.Equal(t, float64(11), m.backlogValue())
+
+	// The underlying metrics type still receives all stats.
+	assert.Equal(t, int64(3), stats.GetCounters()["foo.error"])
+	assert.Equal(t, int64(4), stats.GetCounters()["foo.sent"])
+}
+
+func TestMonitorWebhook(t *testing.T) {
+	alerts := make(chan Alert, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		var a Alert
+		assert.NoError(t, json.Unmarshal(b, &a))
+		alerts <- a
+	}))
+	defer server.Close()
+
+	conf := NewConfig()
+	conf.WebhookURL = server.URL
+	conf.CheckInterval = "10ms"
+	conf.ErrorRate.Threshold = 1
+	conf.ErrorRate.Period = "0s"
+
+	m, err := NewMonitor(conf, metrics.Noop(), log.Noop())
+	require.NoError(t, err)
+	require.NoError(t, m.Start(types.NoopMgr()))
+	defer m.Close()
+
+	errCtr := m.GetCounter("foo.error")
+	errCtr.Incr(1000)
+
+	select {
+	case a := <-alerts:
+		assert.Equal(t, "error_rate", a.Name)
+		assert.Equal(t, StateFiring, a.State)
+		assert.Equal(t, float64(1), a.Threshold)
+		assert.Greater(t, a.Value, float64(1))
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for firing alert")
+	}
+
+	select {
+	case a := <-alerts:
+		assert.Equal(t, "error_rate", a.Name)
+		assert.Equal(t, StateResolved, a.State)
+		assert.Equal(t, float64(0), a.Value)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for resolved alert")
+	}
+}
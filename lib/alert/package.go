@@ -0,0 +1,4 @@
+// Package alert provides a basic self-alerting mechanism that monitors the
+// error rate and buffer backlog of a Benthos service and emits an alert when
+// either exceeds a threshold for a sustained period.
+package alert
@@ -22,13 +22,14 @@ import (
 
 // Config contains the configuration fields for the Benthos API.
 type Config struct {
-	Address        string `json:"address" yaml:"address"`
-	Enabled        bool   `json:"enabled" yaml:"enabled"`
-	ReadTimeout    string `json:"read_timeout" yaml:"read_timeout"`
-	RootPath       string `json:"root_path" yaml:"root_path"`
-	DebugEndpoints bool   `json:"debug_endpoints" yaml:"debug_endpoints"`
-	CertFile       string `json:"cert_file" yaml:"cert_file"`
-	KeyFile        string `json:"key_file" yaml:"key_file"`
+	Address        string     `json:"address" yaml:"address"`
+	Enabled        bool       `json:"enabled" yaml:"enabled"`
+	ReadTimeout    string     `json:"read_timeout" yaml:"read_timeout"`
+	RootPath       string     `json:"root_path" yaml:"root_path"`
+	DebugEndpoints bool       `json:"debug_endpoints" yaml:"debug_endpoints"`
+	CertFile       string     `json:"cert_file" yaml:"cert_file"`
+	KeyFile        string     `json:"key_file" yaml:"key_file"`
+	Auth           AuthConfig `json:"auth" yaml:"auth"`
 }
 
 // NewConfig creates a new API config with default values.
@@ -41,6 +42,7 @@ func NewConfig() Config {
 		DebugEndpoints: false,
 		CertFile:       "",
 		KeyFile:        "",
+		Auth:           NewAuthConfig(),
 	}
 }
 
@@ -63,6 +65,14 @@ func OptWithTLS(tls *tls.Config) OptFunc {
 	}
 }
 
+// OptWithCommit sets the git commit that the service was built from, which is
+// returned by the /version endpoint.
+func OptWithCommit(commit string) OptFunc {
+	return func(t *Type) {
+		t.commit = commit
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // Type implements the Benthos HTTP API.
@@ -80,6 +90,8 @@ type Type struct {
 	log    log.Modular
 	mux    *mux.Router
 	server *http.Server
+
+	commit string
 }
 
 // New creates a new Benthos HTTP API.
@@ -99,6 +111,14 @@ func New(
 		Handler: handler,
 	}
 
+	authMw, err := newAuthMiddleware(conf.Auth, conf.RootPath)
+	if err != nil {
+		return nil, fmt.Errorf("auth: %w", err)
+	}
+	if authMw.enabled() {
+		server.Handler = authMw.wrap(handler)
+	}
+
 	if conf.CertFile != "" || conf.KeyFile != "" {
 		if conf.CertFile == "" || conf.KeyFile == "" {
 			return nil, errors.New("both cert_file and key_file must be specified, or neither")
@@ -160,7 +180,16 @@ func New(
 	}
 
 	handleVersion := func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte(fmt.Sprintf("{\"version\":\"%v\", \"built\":\"%v\"}", version, dateBuilt)))
+		resBytes, err := json.Marshal(map[string]string{
+			"version": version,
+			"commit":  t.commit,
+			"built":   dateBuilt,
+		})
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write(resBytes)
 	}
 
 	handleEndpoints := func(w http.ResponseWriter, r *http.Request) {
@@ -222,6 +251,10 @@ func New(
 	t.RegisterEndpoint("/ping", "Ping me.", handlePing)
 	t.RegisterEndpoint("/version", "Returns the service version.", handleVersion)
 	t.RegisterEndpoint("/endpoints", "Returns this map of endpoints.", handleEndpoints)
+	t.RegisterEndpoint(
+		"/topology", "Returns the topology of the pipeline as JSON, or as a Graphviz DOT graph when the query parameter format=dot is set.",
+		topologyHandler(wholeConf),
+	)
 
 	// If we want to expose a JSON stats endpoint we register the endpoints.
 	if wHandlerFunc, ok := stats.(metrics.WithHandlerFunc); ok {
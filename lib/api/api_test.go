@@ -0,0 +1,33 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/api"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionEndpoint(t *testing.T) {
+	var handler http.Handler
+	_, err := api.New(
+		"v1.2.3", "2021-09-01T12:00:00Z", api.NewConfig(), nil, log.Noop(), metrics.Noop(),
+		api.OptWithCommit("abc123"),
+		api.OptWithMiddleware(func(h http.Handler) http.Handler {
+			handler = h
+			return h
+		}),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, handler)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/version", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"version":"v1.2.3","commit":"abc123","built":"2021-09-01T12:00:00Z"}`, rec.Body.String())
+}
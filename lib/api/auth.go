@@ -0,0 +1,142 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+//------------------------------------------------------------------------------
+
+// AuthConfig contains fields for restricting access to the endpoints of the
+// Benthos API.
+type AuthConfig struct {
+	Username    string   `json:"username" yaml:"username"`
+	Password    string   `json:"password" yaml:"password"`
+	BearerToken string   `json:"bearer_token" yaml:"bearer_token"`
+	AllowedIPs  []string `json:"allowed_ips" yaml:"allowed_ips"`
+	ExemptPaths []string `json:"exempt_paths" yaml:"exempt_paths"`
+}
+
+// NewAuthConfig creates a new AuthConfig with default values.
+func NewAuthConfig() AuthConfig {
+	return AuthConfig{
+		Username:    "",
+		Password:    "",
+		BearerToken: "",
+		AllowedIPs:  []string{},
+		ExemptPaths: []string{},
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// authMiddleware rejects requests from addresses that are not within an allow
+// list with a 403, and requests without either valid basic auth credentials or
+// a valid bearer token with a 401.
+type authMiddleware struct {
+	username    string
+	password    string
+	bearerToken string
+	allowed     []*net.IPNet
+	exempt      map[string]struct{}
+}
+
+func newAuthMiddleware(conf AuthConfig, rootPath string) (*authMiddleware, error) {
+	if conf.Password != "" && conf.Username == "" {
+		return nil, fmt.Errorf("a username must be specified along with a password")
+	}
+	a := &authMiddleware{
+		username:    conf.Username,
+		password:    conf.Password,
+		bearerToken: conf.BearerToken,
+		exempt:      map[string]struct{}{},
+	}
+	for _, s := range conf.AllowedIPs {
+		if !strings.Contains(s, "/") {
+			if strings.Contains(s, ":") {
+				s += "/128"
+			} else {
+				s += "/32"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse allowed IP: %w", err)
+		}
+		a.allowed = append(a.allowed, ipNet)
+	}
+	for _, p := range conf.ExemptPaths {
+		a.exempt[p] = struct{}{}
+		a.exempt[rootPath+p] = struct{}{}
+	}
+	return a, nil
+}
+
+func (a *authMiddleware) enabled() bool {
+	return a.username != "" || a.bearerToken != "" || len(a.allowed) > 0
+}
+
+func (a *authMiddleware) ipAllowed(remoteAddr string) bool {
+	if len(a.allowed) == 0 {
+		return true
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, n := range a.allowed {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *authMiddleware) authenticated(r *http.Request) bool {
+	if a.username == "" && a.bearerToken == "" {
+		return true
+	}
+	if a.username != "" {
+		if user, pass, ok := r.BasicAuth(); ok &&
+			subtle.ConstantTimeCompare([]byte(user), []byte(a.username)) == 1 &&
+			subtle.ConstantTimeCompare([]byte(pass), []byte(a.password)) == 1 {
+			return true
+		}
+	}
+	if a.bearerToken != "" {
+		header := r.Header.Get("Authorization")
+		if strings.HasPrefix(header, "Bearer ") &&
+			subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, "Bearer ")), []byte(a.bearerToken)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *authMiddleware) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, exists := a.exempt[r.URL.Path]; exists {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !a.ipAllowed(r.RemoteAddr) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if !a.authenticated(r) {
+			if a.username != "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="benthos"`)
+			}
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,129 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/api"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAuthHandler(t *testing.T, conf api.AuthConfig) http.Handler {
+	t.Helper()
+
+	apiConf := api.NewConfig()
+	apiConf.Auth = conf
+
+	var handler http.Handler
+	_, err := api.New(
+		"", "", apiConf, nil, log.Noop(), metrics.Noop(),
+		api.OptWithMiddleware(func(h http.Handler) http.Handler {
+			handler = h
+			return h
+		}),
+	)
+	require.NoError(t, err)
+	return handler
+}
+
+func TestAuthCredentials(t *testing.T) {
+	conf := api.NewAuthConfig()
+	conf.Username = "foo"
+	conf.Password = "bar"
+	conf.BearerToken = "baz"
+	conf.ExemptPaths = []string{"/ping"}
+	handler := newAuthHandler(t, conf)
+
+	tests := map[string]struct {
+		path   string
+		setup  func(r *http.Request)
+		status int
+	}{
+		"no credentials": {
+			path:   "/version",
+			setup:  func(r *http.Request) {},
+			status: http.StatusUnauthorized,
+		},
+		"valid basic auth": {
+			path:   "/version",
+			setup:  func(r *http.Request) { r.SetBasicAuth("foo", "bar") },
+			status: http.StatusOK,
+		},
+		"invalid basic auth": {
+			path:   "/version",
+			setup:  func(r *http.Request) { r.SetBasicAuth("foo", "nope") },
+			status: http.StatusUnauthorized,
+		},
+		"valid bearer token": {
+			path:   "/benthos/version",
+			setup:  func(r *http.Request) { r.Header.Set("Authorization", "Bearer baz") },
+			status: http.StatusOK,
+		},
+		"invalid bearer token": {
+			path:   "/version",
+			setup:  func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") },
+			status: http.StatusUnauthorized,
+		},
+		"exempt path": {
+			path:   "/ping",
+			setup:  func(r *http.Request) {},
+			status: http.StatusOK,
+		},
+		"exempt path with root": {
+			path:   "/benthos/ping",
+			setup:  func(r *http.Request) {},
+			status: http.StatusOK,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", test.path, nil)
+			test.setup(req)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, test.status, rec.Code)
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/version", nil))
+	assert.Equal(t, `Basic realm="benthos"`, rec.Header().Get("WWW-Authenticate"))
+}
+
+func TestAuthAllowedIPs(t *testing.T) {
+	conf := api.NewAuthConfig()
+	conf.AllowedIPs = []string{"10.0.0.0/8", "192.168.0.1", "::1"}
+	handler := newAuthHandler(t, conf)
+
+	for addr, status := range map[string]int{
+		"10.1.2.3:1234":    http.StatusOK,
+		"192.168.0.1:1234": http.StatusOK,
+		"192.168.0.2:1234": http.StatusForbidden,
+		"[::1]:1234":       http.StatusOK,
+		"[::2]:1234":       http.StatusForbidden,
+	} {
+		req := httptest.NewRequest("GET", "/ping", nil)
+		req.RemoteAddr = addr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, status, rec.Code, addr)
+	}
+}
+
+func TestAuthBadConfig(t *testing.T) {
+	conf := api.NewConfig()
+	conf.Auth.AllowedIPs = []string{"not an ip"}
+	_, err := api.New("", "", conf, nil, log.Noop(), metrics.Noop())
+	assert.Error(t, err)
+
+	conf = api.NewConfig()
+	conf.Auth.Password = "foo"
+	_, err = api.New("", "", conf, nil, log.Noop(), metrics.Noop())
+	assert.Error(t, err)
+}
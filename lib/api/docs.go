@@ -15,6 +15,13 @@ func Spec() docs.FieldSpecs {
 		).HasDefault(false),
 		docs.FieldString("cert_file", "An optional certificate file for enabling TLS.").Advanced().HasDefault(""),
 		docs.FieldString("key_file", "An optional key file for enabling TLS.").Advanced().HasDefault(""),
+		docs.FieldAdvanced("auth", "Restricts access to all endpoints of the HTTP server, including metrics, config and debug endpoints and any endpoints registered by components. When credentials are configured requests must provide either valid basic auth credentials or a valid bearer token.").WithChildren(
+			docs.FieldString("username", "A username that requests must provide with basic auth. When empty basic auth is disabled.").HasDefault(""),
+			docs.FieldString("password", "A password that requests must provide with basic auth.").HasDefault(""),
+			docs.FieldString("bearer_token", "A token that requests must provide within an `Authorization: Bearer <token>` header. When empty bearer tokens are disabled.").HasDefault(""),
+			docs.FieldString("allowed_ips", "A list of IP addresses or CIDR ranges that requests are accepted from, requests from other addresses are rejected with a 403. The address of the connection is used, and therefore headers set by proxies such as `X-Forwarded-For` are ignored. When empty requests from any address are accepted.", []string{"10.0.0.0/8", "127.0.0.1"}).Array().HasDefault([]string{}),
+			docs.FieldString("exempt_paths", "A list of endpoint paths that are accessible without restrictions, such as health checks.", []string{"/ping", "/ready"}).Array().HasDefault([]string{}),
+		).AtVersion("3.54.0"),
 		docs.FieldDeprecated("read_timeout"),
 	}
 }
@@ -0,0 +1,296 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Jeffail/benthos/v3/internal/docs"
+	yaml "gopkg.in/yaml.v3"
+)
+
+//------------------------------------------------------------------------------
+
+// TopologyNode is a single component within a pipeline topology, identified by
+// the path of its config.
+type TopologyNode struct {
+	ID       string `json:"id"`
+	Kind     string `json:"kind"`
+	Type     string `json:"type"`
+	Label    string `json:"label,omitempty"`
+	Resource string `json:"resource,omitempty"`
+}
+
+// TopologyEdge is a directed connection between two components of a pipeline
+// topology, following the direction in which messages flow.
+type TopologyEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Topology describes the components of a pipeline and the connections between
+// them.
+type Topology struct {
+	Nodes []TopologyNode `json:"nodes"`
+	Edges []TopologyEdge `json:"edges"`
+}
+
+func (t *Topology) addEdge(from, to string) {
+	t.Edges = append(t.Edges, TopologyEdge{From: from, To: to})
+}
+
+// TopologyFromYAML builds a pipeline topology from a parsed Benthos config,
+// following messages from the input, through the buffer and pipeline
+// processors, to the output.
+func TopologyFromYAML(node *yaml.Node) (*Topology, error) {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	t := &Topology{
+		Nodes: []TopologyNode{},
+		Edges: []TopologyEdge{},
+	}
+
+	var exit string
+	if inNode := mapValue(node, "input"); inNode != nil {
+		_, inExit, err := t.walk(docs.TypeInput, "input", inNode)
+		if err != nil {
+			return nil, err
+		}
+		exit = inExit
+	}
+
+	if bufNode := mapValue(node, "buffer"); bufNode != nil && !isNoneBuffer(bufNode) {
+		bufEntry, bufExit, err := t.walk(docs.TypeBuffer, "buffer", bufNode)
+		if err != nil {
+			return nil, err
+		}
+		if exit != "" {
+			t.addEdge(exit, bufEntry)
+		}
+		exit = bufExit
+	}
+
+	if procsNode := mapValue(mapValue(node, "pipeline"), "processors"); procsNode != nil {
+		for i, pNode := range procsNode.Content {
+			pEntry, pExit, err := t.walk(docs.TypeProcessor, "pipeline.processors."+strconv.Itoa(i), pNode)
+			if err != nil {
+				return nil, err
+			}
+			if exit != "" {
+				t.addEdge(exit, pEntry)
+			}
+			exit = pExit
+		}
+	}
+
+	if outNode := mapValue(node, "output"); outNode != nil {
+		outEntry, _, err := t.walk(docs.TypeOutput, "output", outNode)
+		if err != nil {
+			return nil, err
+		}
+		if exit != "" {
+			t.addEdge(exit, outEntry)
+		}
+	}
+	return t, nil
+}
+
+func isNoneBuffer(node *yaml.Node) bool {
+	if len(node.Content) == 0 {
+		return true
+	}
+	name, _, _ := docs.GetInferenceCandidateFromYAML(nil, docs.TypeBuffer, "", node)
+	return name == "none"
+}
+
+func mapValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// walk adds a component and all of its children to the topology, returning the
+// IDs of the nodes at which messages enter and exit the component, which differ
+// from the component itself when it has processors.
+func (t *Topology) walk(cType docs.Type, path string, node *yaml.Node) (entry, exit string, err error) {
+	name, spec, err := docs.GetInferenceCandidateFromYAML(nil, cType, "", node)
+	if err != nil {
+		return "", "", fmt.Errorf("%v: %w", path, err)
+	}
+
+	tNode := TopologyNode{
+		ID:   path,
+		Kind: string(cType),
+		Type: name,
+	}
+	if labelNode := mapValue(node, "label"); labelNode != nil {
+		tNode.Label = labelNode.Value
+	}
+	confNode := mapValue(node, name)
+	if name == "resource" && confNode != nil {
+		tNode.Resource = confNode.Value
+	}
+	t.Nodes = append(t.Nodes, tNode)
+
+	if confNode != nil {
+		if err := t.walkField(path, path+"."+name, spec.Config, confNode); err != nil {
+			return "", "", err
+		}
+	}
+
+	var procIDs []string
+	if procsNode := mapValue(node, "processors"); procsNode != nil {
+		for i, pNode := range procsNode.Content {
+			pPath := path + ".processors." + strconv.Itoa(i)
+			if _, _, err := t.walk(docs.TypeProcessor, pPath, pNode); err != nil {
+				return "", "", err
+			}
+			procIDs = append(procIDs, pPath)
+		}
+	}
+
+	// Input processors are applied after the input has consumed a message,
+	// whereas output processors are applied before the output writes it.
+	chain := append([]string{path}, procIDs...)
+	if cType == docs.TypeOutput {
+		chain = append(procIDs, path)
+	}
+	for i := 1; i < len(chain); i++ {
+		t.addEdge(chain[i-1], chain[i])
+	}
+	return chain[0], chain[len(chain)-1], nil
+}
+
+// walkField searches the value of a field within a component config for child
+// components, adding them to the topology along with edges connecting them to
+// the parent component.
+func (t *Topology) walkField(parent, path string, f docs.FieldSpec, node *yaml.Node) error {
+	type element struct {
+		path string
+		node *yaml.Node
+	}
+	var elements []element
+	switch f.Kind {
+	case docs.KindArray:
+		for i, e := range node.Content {
+			elements = append(elements, element{path + "." + strconv.Itoa(i), e})
+		}
+	case docs.Kind2DArray:
+		for i, es := range node.Content {
+			for j, e := range es.Content {
+				elements = append(elements, element{path + "." + strconv.Itoa(i) + "." + strconv.Itoa(j), e})
+			}
+		}
+	case docs.KindMap:
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			elements = append(elements, element{path + "." + node.Content[i].Value, node.Content[i+1]})
+		}
+	default:
+		elements = append(elements, element{path, node})
+	}
+
+	coreType, isCore := f.Type.IsCoreComponent()
+	if isCore && coreType != docs.TypeInput && coreType != docs.TypeOutput && coreType != docs.TypeProcessor {
+		return nil
+	}
+
+	for _, e := range elements {
+		if !isCore {
+			for _, child := range f.Children {
+				if cNode := mapValue(e.node, child.Name); cNode != nil {
+					if err := t.walkField(parent, e.path+"."+child.Name, child, cNode); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+
+		if e.node.Kind != yaml.MappingNode || len(e.node.Content) == 0 {
+			continue
+		}
+		cEntry, cExit, err := t.walk(coreType, e.path, e.node)
+		if err != nil {
+			return err
+		}
+		if coreType == docs.TypeInput {
+			t.addEdge(cExit, parent)
+		} else {
+			t.addEdge(parent, cEntry)
+		}
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// DOT returns the topology in the DOT language of Graphviz.
+func (t *Topology) DOT() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("digraph benthos {\n")
+	buf.WriteString("  rankdir=LR;\n")
+	for _, n := range t.Nodes {
+		label := n.Kind + ": " + n.Type
+		if n.Label != "" {
+			label += "\\n" + n.Label
+		}
+		if n.Resource != "" {
+			label += "\\n" + n.Resource
+		}
+		fmt.Fprintf(&buf, "  %v [label=%v];\n", dotQuote(n.ID), dotQuote(label))
+	}
+	for _, e := range t.Edges {
+		fmt.Fprintf(&buf, "  %v -> %v;\n", dotQuote(e.From), dotQuote(e.To))
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+//------------------------------------------------------------------------------
+
+func topologyHandler(wholeConf interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		node, ok := wholeConf.(yaml.Node)
+		if !ok {
+			if err := node.Encode(wholeConf); err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+		}
+
+		topo, err := TopologyFromYAML(&node)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		if r.URL.Query().Get("format") == "dot" {
+			w.Header().Set("Content-Type", "text/vnd.graphviz")
+			w.Write(topo.DOT())
+			return
+		}
+
+		resBytes, err := json.Marshal(topo)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(resBytes)
+	}
+}
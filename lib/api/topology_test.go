@@ -0,0 +1,81 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+
+	_ "github.com/Jeffail/benthos/v3/public/components/all"
+)
+
+func TestTopologyFromYAML(t *testing.T) {
+	var node yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(`
+input:
+  broker:
+    inputs:
+      - label: foo
+        generate:
+          mapping: 'root = "foo"'
+      - resource: bar
+  processors:
+    - bloblang: 'root = content().uppercase()'
+buffer:
+  memory: {}
+pipeline:
+  processors:
+    - switch:
+        - check: 'this.foo == "bar"'
+          processors:
+            - noop: {}
+output:
+  broker:
+    pattern: fan_out
+    outputs:
+      - drop: {}
+      - drop_on:
+          error: true
+          output:
+            stdout: {}
+  processors:
+    - noop: {}
+`), &node))
+
+	topo, err := api.TopologyFromYAML(&node)
+	require.NoError(t, err)
+
+	assert.Equal(t, []api.TopologyNode{
+		{ID: "input", Kind: "input", Type: "broker"},
+		{ID: "input.broker.inputs.0", Kind: "input", Type: "generate", Label: "foo"},
+		{ID: "input.broker.inputs.1", Kind: "input", Type: "resource", Resource: "bar"},
+		{ID: "input.processors.0", Kind: "processor", Type: "bloblang"},
+		{ID: "buffer", Kind: "buffer", Type: "memory"},
+		{ID: "pipeline.processors.0", Kind: "processor", Type: "switch"},
+		{ID: "pipeline.processors.0.switch.0.processors.0", Kind: "processor", Type: "noop"},
+		{ID: "output", Kind: "output", Type: "broker"},
+		{ID: "output.broker.outputs.0", Kind: "output", Type: "drop"},
+		{ID: "output.broker.outputs.1", Kind: "output", Type: "drop_on"},
+		{ID: "output.broker.outputs.1.drop_on.output", Kind: "output", Type: "stdout"},
+		{ID: "output.processors.0", Kind: "processor", Type: "noop"},
+	}, topo.Nodes)
+
+	assert.Equal(t, []api.TopologyEdge{
+		{From: "input.broker.inputs.0", To: "input"},
+		{From: "input.broker.inputs.1", To: "input"},
+		{From: "input", To: "input.processors.0"},
+		{From: "input.processors.0", To: "buffer"},
+		{From: "pipeline.processors.0", To: "pipeline.processors.0.switch.0.processors.0"},
+		{From: "buffer", To: "pipeline.processors.0"},
+		{From: "output", To: "output.broker.outputs.0"},
+		{From: "output.broker.outputs.1", To: "output.broker.outputs.1.drop_on.output"},
+		{From: "output", To: "output.broker.outputs.1"},
+		{From: "output.processors.0", To: "output"},
+		{From: "pipeline.processors.0", To: "output.processors.0"},
+	}, topo.Edges)
+
+	assert.Contains(t, string(topo.DOT()), `"input.broker.inputs.0" -> "input";`)
+	assert.Contains(t, string(topo.DOT()), `"input.broker.inputs.0" [label="input: generate\nfoo"];`)
+}
@@ -1,11 +1,13 @@
 package broker
 
 import (
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/response"
 	"github.com/Jeffail/benthos/v3/lib/types"
 )
 
@@ -30,6 +32,11 @@ type wrappedInput struct {
 // DynamicFanIn is a broker that implements types.Producer and manages a map of
 // inputs to unique string identifiers, routing them through a single message
 // channel. Inputs can be added and removed dynamically as the broker runs.
+//
+// When an input is removed or replaced it is first drained, where it stops
+// consuming new messages but is not considered removed until all of the
+// messages it has already sent have received a response, which allows it to
+// acknowledge them before closing.
 type DynamicFanIn struct {
 	running int32
 
@@ -44,9 +51,13 @@ type DynamicFanIn struct {
 	newInputChan     chan wrappedInput
 	inputs           map[string]DynamicInput
 	inputClosedChans map[string]chan struct{}
+	inputStopChans   map[string]chan struct{}
+	inputPending     map[string]*sync.WaitGroup
 
-	closedChan chan struct{}
-	closeChan  chan struct{}
+	closedChan     chan struct{}
+	closeChan      chan struct{}
+	fullyCloseOnce sync.Once
+	fullyCloseChan chan struct{}
 }
 
 // NewDynamicFanIn creates a new DynamicFanIn type by providing an initial map
@@ -70,9 +81,12 @@ func NewDynamicFanIn(
 		newInputChan:     make(chan wrappedInput),
 		inputs:           make(map[string]DynamicInput),
 		inputClosedChans: make(map[string]chan struct{}),
+		inputStopChans:   make(map[string]chan struct{}),
+		inputPending:     make(map[string]*sync.WaitGroup),
 
-		closedChan: make(chan struct{}),
-		closeChan:  make(chan struct{}),
+		closedChan:     make(chan struct{}),
+		closeChan:      make(chan struct{}),
+		fullyCloseChan: make(chan struct{}),
 	}
 	for _, opt := range options {
 		opt(d)
@@ -89,8 +103,9 @@ func NewDynamicFanIn(
 }
 
 // SetInput attempts to add a new input to the dynamic input broker. If an input
-// already exists with the same identifier it will be closed and removed. If
-// either action takes longer than the timeout period an error will be returned.
+// already exists with the same identifier it will be drained of in-flight
+// messages, closed and removed. If either action takes longer than the timeout
+// period an error will be returned.
 //
 // A nil input is safe and will simply remove the previous input under the
 // indentifier, if there was one.
@@ -145,8 +160,19 @@ func OptDynamicFanInSetOnRemove(onRemoveFunc func(label string)) func(*DynamicFa
 
 //------------------------------------------------------------------------------
 
+// reject responds to a transaction that was never sent with an error, allowing
+// the input to deliver it again later.
+func (d *DynamicFanIn) reject(tran types.Transaction) {
+	select {
+	case tran.ResponseChan <- response.NewError(types.ErrTypeClosed):
+	case <-d.fullyCloseChan:
+	}
+}
+
 func (d *DynamicFanIn) addInput(ident string, input DynamicInput) error {
 	closedChan := make(chan struct{})
+	stopChan := make(chan struct{})
+	pending := &sync.WaitGroup{}
 	// Launch goroutine that async writes input into single channel
 	go func(in DynamicInput, cChan chan struct{}) {
 		defer func() {
@@ -160,13 +186,46 @@ func (d *DynamicFanIn) addInput(ident string, input DynamicInput) error {
 				// Race condition: This will be called when shutting down.
 				return
 			}
-			d.transactionChan <- in
+
+			resChan := make(chan types.Response)
+			select {
+			case d.transactionChan <- types.NewTransaction(in.Payload, resChan):
+			case <-stopChan:
+				d.reject(in)
+				return
+			case <-d.closeChan:
+				d.reject(in)
+				return
+			}
+
+			// Track the transaction until its response has been passed back
+			// to the input so that removals can wait for it.
+			pending.Add(1)
+			go func(rChan chan<- types.Response) {
+				defer pending.Done()
+				select {
+				case res := <-resChan:
+					select {
+					case rChan <- res:
+					case <-d.fullyCloseChan:
+					}
+				case <-d.fullyCloseChan:
+					// The transaction is abandoned, which the input is told
+					// about if it is still listening.
+					select {
+					case rChan <- response.NewError(types.ErrTypeClosed):
+					default:
+					}
+				}
+			}(in.ResponseChan)
 		}
 	}(input, closedChan)
 
 	// Add new input to our map
 	d.inputs[ident] = input
 	d.inputClosedChans[ident] = closedChan
+	d.inputStopChans[ident] = stopChan
+	d.inputPending[ident] = pending
 
 	return nil
 }
@@ -178,17 +237,41 @@ func (d *DynamicFanIn) removeInput(ident string, timeout time.Duration) error {
 		return nil
 	}
 
+	deadline := time.After(timeout)
+
+	// Transactions that the input has yet to send downstream are rejected.
+	if stopChan := d.inputStopChans[ident]; stopChan != nil {
+		close(stopChan)
+		d.inputStopChans[ident] = nil
+	}
 	input.CloseAsync()
 	select {
 	case <-d.inputClosedChans[ident]:
-	case <-time.After(timeout):
+	case <-deadline:
 		// Do NOT remove inputs from our map unless we are sure they are
 		// closed.
 		return types.ErrTimeout
 	}
 
+	// The input no longer sends transactions, but those already in flight
+	// must be resolved before it is removed or their messages might never be
+	// acknowledged.
+	drainedChan := make(chan struct{})
+	go func(pending *sync.WaitGroup) {
+		pending.Wait()
+		close(drainedChan)
+	}(d.inputPending[ident])
+	select {
+	case <-drainedChan:
+	case <-deadline:
+		d.log.Warnf("Dynamic input '%v' still has messages in flight after closing\n", ident)
+		return types.ErrTimeout
+	}
+
 	delete(d.inputs, ident)
 	delete(d.inputClosedChans, ident)
+	delete(d.inputStopChans, ident)
+	delete(d.inputPending, ident)
 
 	return nil
 }
@@ -263,8 +346,15 @@ func (d *DynamicFanIn) CloseAsync() {
 	}
 }
 
-// WaitForClose blocks until the DynamicFanIn broker has closed down.
+// WaitForClose blocks until the DynamicFanIn broker has closed down. The
+// transactions that are still in flight when the broker is closed are given
+// until shortly before the timeout to be resolved, after which they are
+// rejected so that their inputs can be closed.
 func (d *DynamicFanIn) WaitForClose(timeout time.Duration) error {
+	go d.fullyCloseOnce.Do(func() {
+		<-time.After(timeout - time.Second)
+		close(d.fullyCloseChan)
+	})
 	select {
 	case <-d.closedChan:
 	case <-time.After(timeout):
@@ -329,3 +329,205 @@ func TestStaticDynamicFanInAsync(t *testing.T) {
 }
 
 //------------------------------------------------------------------------------
+
+func TestDynamicFanInRemoveDrains(t *testing.T) {
+	input := &MockInputType{
+		TChan: make(chan types.Transaction),
+	}
+
+	fanIn, err := NewDynamicFanIn(nil, log.Noop(), metrics.Noop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		fanIn.CloseAsync()
+		if err := fanIn.WaitForClose(time.Second * 10); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	if err = fanIn.SetInput("foo", input, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	rChan := make(chan types.Response)
+	select {
+	case input.TChan <- types.NewTransaction(message.New([][]byte{[]byte("hello world")}), rChan):
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for broker send")
+	}
+
+	var ts types.Transaction
+	select {
+	case ts = <-fanIn.TransactionChan():
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for broker propagate")
+	}
+
+	// The message has not been acknowledged and therefore the removal must
+	// time out without removing the input.
+	if err = fanIn.SetInput("foo", nil, time.Millisecond*50); err != types.ErrTimeout {
+		t.Fatalf("Expected timeout error, received: %v", err)
+	}
+
+	removeErrChan := make(chan error)
+	go func() {
+		removeErrChan <- fanIn.SetInput("foo", nil, time.Second*5)
+	}()
+
+	select {
+	case err := <-removeErrChan:
+		t.Fatalf("Input removed before its message was acknowledged: %v", err)
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	select {
+	case ts.ResponseChan <- response.NewAck():
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for response to broker")
+	}
+
+	select {
+	case res := <-rChan:
+		if err := res.Error(); err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for response to input")
+	}
+
+	select {
+	case err := <-removeErrChan:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for input removal")
+	}
+}
+
+func TestDynamicFanInCloseUnsent(t *testing.T) {
+	input := &MockInputType{
+		TChan: make(chan types.Transaction),
+	}
+
+	fanIn, err := NewDynamicFanIn(map[string]DynamicInput{"foo": input}, log.Noop(), metrics.Noop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing consumes from the broker.
+	rChan := make(chan types.Response)
+	select {
+	case input.TChan <- types.NewTransaction(message.New([][]byte{[]byte("hello world")}), rChan):
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for broker send")
+	}
+
+	fanIn.CloseAsync()
+
+	select {
+	case res := <-rChan:
+		if exp, act := types.ErrTypeClosed, res.Error(); exp != act {
+			t.Errorf("Wrong response: %v != %v", act, exp)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for response to input")
+	}
+
+	if err := fanIn.WaitForClose(time.Second * 5); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDynamicFanInRemoveUnsent(t *testing.T) {
+	input := &MockInputType{
+		TChan: make(chan types.Transaction),
+	}
+
+	fanIn, err := NewDynamicFanIn(map[string]DynamicInput{"foo": input}, log.Noop(), metrics.Noop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		fanIn.CloseAsync()
+		if err := fanIn.WaitForClose(time.Second * 5); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	// Nothing consumes from the broker.
+	rChan := make(chan types.Response)
+	select {
+	case input.TChan <- types.NewTransaction(message.New([][]byte{[]byte("hello world")}), rChan):
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for broker send")
+	}
+
+	removeErrChan := make(chan error)
+	go func() {
+		removeErrChan <- fanIn.SetInput("foo", nil, time.Second*5)
+	}()
+
+	select {
+	case res := <-rChan:
+		if exp, act := types.ErrTypeClosed, res.Error(); exp != act {
+			t.Errorf("Wrong response: %v != %v", act, exp)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for response to input")
+	}
+
+	select {
+	case err := <-removeErrChan:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for input removal")
+	}
+}
+
+func TestDynamicFanInCloseUnresolved(t *testing.T) {
+	input := &MockInputType{
+		TChan: make(chan types.Transaction),
+	}
+
+	fanIn, err := NewDynamicFanIn(map[string]DynamicInput{"foo": input}, log.Noop(), metrics.Noop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rChan := make(chan types.Response)
+	select {
+	case input.TChan <- types.NewTransaction(message.New([][]byte{[]byte("hello world")}), rChan):
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for broker send")
+	}
+
+	// The transaction is consumed but never resolved.
+	select {
+	case <-fanIn.TransactionChan():
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for broker propagate")
+	}
+
+	resErrChan := make(chan error, 1)
+	go func() {
+		resErrChan <- (<-rChan).Error()
+	}()
+
+	fanIn.CloseAsync()
+	if err := fanIn.WaitForClose(time.Millisecond * 1500); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-resErrChan:
+		if exp, act := types.ErrTypeClosed, err; exp != act {
+			t.Errorf("Wrong response: %v != %v", act, exp)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for response to input")
+	}
+}
@@ -0,0 +1,240 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/component/output"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/throttle"
+)
+
+//------------------------------------------------------------------------------
+
+// FanOutBuffered is a broker that implements types.Consumer and broadcasts each
+// message out to an array of outputs, where each output is given its own
+// independent buffer. Messages are acknowledged once they have been added to
+// the buffer of every output, meaning a lagging output accumulates a backlog
+// within its own buffer rather than applying back pressure to the input until
+// its buffer is full.
+type FanOutBuffered struct {
+	logger log.Modular
+	stats  metrics.Type
+
+	bufferSize   int
+	transactions <-chan types.Transaction
+
+	outputBuffers []chan types.Message
+	outputTSChans []chan types.Transaction
+	outputs       []types.Output
+
+	ctx        context.Context
+	close      func()
+	closedChan chan struct{}
+}
+
+// NewFanOutBuffered creates a new FanOutBuffered type by providing outputs.
+func NewFanOutBuffered(
+	outputs []types.Output, logger log.Modular, stats metrics.Type,
+) (*FanOutBuffered, error) {
+	ctx, done := context.WithCancel(context.Background())
+	o := &FanOutBuffered{
+		bufferSize:   1,
+		stats:        stats,
+		logger:       logger,
+		transactions: nil,
+		outputs:      outputs,
+		closedChan:   make(chan struct{}),
+		ctx:          ctx,
+		close:        done,
+	}
+
+	o.outputTSChans = make([]chan types.Transaction, len(o.outputs))
+	for i := range o.outputTSChans {
+		o.outputTSChans[i] = make(chan types.Transaction)
+		if err := o.outputs[i].Consume(o.outputTSChans[i]); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// WithBufferSize sets the number of messages that may be buffered for each
+// output before back pressure is applied. This must be set before calling
+// Consume.
+func (o *FanOutBuffered) WithBufferSize(i int) *FanOutBuffered {
+	if i < 1 {
+		i = 1
+	}
+	o.bufferSize = i
+	return o
+}
+
+//------------------------------------------------------------------------------
+
+// Consume assigns a new transactions channel for the broker to read.
+func (o *FanOutBuffered) Consume(transactions <-chan types.Transaction) error {
+	if o.transactions != nil {
+		return types.ErrAlreadyStarted
+	}
+	o.transactions = transactions
+
+	o.outputBuffers = make([]chan types.Message, len(o.outputTSChans))
+	for i := range o.outputBuffers {
+		o.outputBuffers[i] = make(chan types.Message, o.bufferSize)
+	}
+
+	go o.loop()
+	return nil
+}
+
+// Connected returns a boolean indicating whether this output is currently
+// connected to its target.
+func (o *FanOutBuffered) Connected() bool {
+	for _, out := range o.outputs {
+		if !out.Connected() {
+			return false
+		}
+	}
+	return true
+}
+
+//------------------------------------------------------------------------------
+
+// outputLoop drains the buffer of a single output, retrying each message until
+// success or shutdown.
+func (o *FanOutBuffered) outputLoop(i int, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	var (
+		mOutputErr = o.stats.GetCounter("error")
+		mMsgsSnt   = o.stats.GetCounter("messages.sent")
+		mBacklog   = o.stats.GetGauge(fmt.Sprintf("outputs.%v.backlog", i))
+	)
+
+	throt := throttle.New(throttle.OptCloseChan(o.ctx.Done()))
+	resChan := make(chan types.Response)
+	for {
+		var msg types.Message
+		var open bool
+		select {
+		case msg, open = <-o.outputBuffers[i]:
+			if !open {
+				return
+			}
+		case <-o.ctx.Done():
+			return
+		}
+		mBacklog.Set(int64(len(o.outputBuffers[i])))
+
+		for {
+			select {
+			case o.outputTSChans[i] <- types.NewTransaction(msg, resChan):
+			case <-o.ctx.Done():
+				return
+			}
+			var res types.Response
+			select {
+			case res = <-resChan:
+			case <-o.ctx.Done():
+				return
+			}
+			if res.Error() == nil {
+				mMsgsSnt.Incr(1)
+				throt.Reset()
+				break
+			}
+			o.logger.Errorf("Failed to dispatch fan out message to output '%v': %v\n", i, res.Error())
+			mOutputErr.Incr(1)
+			if !throt.Retry() {
+				return
+			}
+		}
+	}
+}
+
+// loop is an internal loop that brokers incoming messages to the buffers of
+// many outputs.
+func (o *FanOutBuffered) loop() {
+	var (
+		wg        = sync.WaitGroup{}
+		mMsgsRcvd = o.stats.GetCounter("messages.received")
+	)
+
+	for i := range o.outputBuffers {
+		for j := 0; j < o.outputMaxInFlight(i); j++ {
+			wg.Add(1)
+			go o.outputLoop(i, &wg)
+		}
+	}
+
+	defer func() {
+		for _, c := range o.outputBuffers {
+			close(c)
+		}
+		wg.Wait()
+		for _, c := range o.outputTSChans {
+			close(c)
+		}
+		closeAllOutputs(o.outputs)
+		close(o.closedChan)
+	}()
+
+	for {
+		var ts types.Transaction
+		var open bool
+		select {
+		case ts, open = <-o.transactions:
+			if !open {
+				return
+			}
+		case <-o.ctx.Done():
+			return
+		}
+		mMsgsRcvd.Incr(1)
+
+		for _, b := range o.outputBuffers {
+			select {
+			case b <- ts.Payload.Copy():
+			case <-o.ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case ts.ResponseChan <- response.NewAck():
+		case <-o.ctx.Done():
+			return
+		}
+	}
+}
+
+func (o *FanOutBuffered) outputMaxInFlight(i int) int {
+	if mif, ok := output.GetMaxInFlight(o.outputs[i]); ok && mif > 1 {
+		return mif
+	}
+	return 1
+}
+
+// CloseAsync shuts down the FanOutBuffered broker and stops processing
+// requests.
+func (o *FanOutBuffered) CloseAsync() {
+	o.close()
+}
+
+// WaitForClose blocks until the FanOutBuffered broker has closed down.
+func (o *FanOutBuffered) WaitForClose(timeout time.Duration) error {
+	select {
+	case <-o.closedChan:
+	case <-time.After(timeout):
+		return types.ErrTimeout
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
@@ -0,0 +1,137 @@
+package broker
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ types.Consumer = &FanOutBuffered{}
+var _ types.Closable = &FanOutBuffered{}
+
+//------------------------------------------------------------------------------
+
+func TestFanOutBufferedLaggingOutput(t *testing.T) {
+	healthy, lagging := &MockOutputType{}, &MockOutputType{}
+
+	oTM, err := NewFanOutBuffered([]types.Output{healthy, lagging}, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+	oTM = oTM.WithBufferSize(3)
+
+	readChan := make(chan types.Transaction)
+	require.NoError(t, oTM.Consume(readChan))
+
+	// The lagging output does not read any messages, which must not block
+	// the healthy output until its buffer is full.
+	resChan := make(chan types.Response)
+	for i := 0; i < 3; i++ {
+		content := fmt.Sprintf("foo%v", i)
+		select {
+		case readChan <- types.NewTransaction(message.New([][]byte{[]byte(content)}), resChan):
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+
+		select {
+		case res := <-resChan:
+			assert.NoError(t, res.Error())
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+
+		var ts types.Transaction
+		select {
+		case ts = <-healthy.TChan:
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+		assert.Equal(t, content, string(ts.Payload.Get(0).Get()))
+		select {
+		case ts.ResponseChan <- response.NewAck():
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+
+	// The lagging output eventually receives the backlog in order, and
+	// failed messages are retried.
+	for i := 0; i < 3; i++ {
+		content := fmt.Sprintf("foo%v", i)
+
+		var ts types.Transaction
+		select {
+		case ts = <-lagging.TChan:
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+		assert.Equal(t, content, string(ts.Payload.Get(0).Get()))
+		select {
+		case ts.ResponseChan <- response.NewError(errors.New("nope")):
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+
+		select {
+		case ts = <-lagging.TChan:
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+		assert.Equal(t, content, string(ts.Payload.Get(0).Get()))
+		select {
+		case ts.ResponseChan <- response.NewAck():
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+
+	oTM.CloseAsync()
+	require.NoError(t, oTM.WaitForClose(time.Second*5))
+}
+
+func TestFanOutBufferedBackPressure(t *testing.T) {
+	healthy, lagging := &MockOutputType{}, &MockOutputType{}
+
+	oTM, err := NewFanOutBuffered([]types.Output{healthy, lagging}, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+	oTM = oTM.WithBufferSize(1)
+
+	readChan := make(chan types.Transaction)
+	require.NoError(t, oTM.Consume(readChan))
+
+	go func() {
+		for ts := range healthy.TChan {
+			ts.ResponseChan <- response.NewAck()
+		}
+	}()
+
+	// Once the buffer of the lagging output is full the input is blocked.
+	resChan := make(chan types.Response)
+	blocked := false
+	for i := 0; i < 5 && !blocked; i++ {
+		select {
+		case readChan <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), resChan):
+		case <-time.After(time.Millisecond * 100):
+			blocked = true
+			continue
+		}
+		select {
+		case res := <-resChan:
+			assert.NoError(t, res.Error())
+		case <-time.After(time.Millisecond * 100):
+			blocked = true
+		}
+	}
+	assert.True(t, blocked)
+
+	oTM.CloseAsync()
+	require.NoError(t, oTM.WaitForClose(time.Second*5))
+}
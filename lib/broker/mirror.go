@@ -0,0 +1,241 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/component/output"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/types"
+)
+
+//------------------------------------------------------------------------------
+
+// Mirror is a broker that implements types.Consumer and sends every message to
+// a primary output, and a percentage of messages to one or more shadow outputs.
+// Shadow outputs are written to in the background without retries, and when a
+// shadow output applies back pressure messages destined for it are dropped,
+// meaning a shadow output is never able to impact the delivery of messages to
+// the primary output.
+type Mirror struct {
+	logger log.Modular
+	stats  metrics.Type
+
+	percentage float64
+	queueSize  int
+	rand       *rand.Rand
+
+	transactions <-chan types.Transaction
+
+	primaryTSChan chan types.Transaction
+	shadowQueues  []chan types.Message
+	shadowTSChans []chan types.Transaction
+	outputs       []types.Output
+
+	ctx        context.Context
+	close      func()
+	closedChan chan struct{}
+}
+
+// NewMirror creates a new Mirror type by providing outputs, where the first
+// output is the primary and all others are shadows. The percentage determines
+// the proportion of messages, from 0 to 100, that are sent to shadow outputs.
+func NewMirror(
+	outputs []types.Output, percentage float64, logger log.Modular, stats metrics.Type,
+) (*Mirror, error) {
+	if len(outputs) == 0 {
+		return nil, errors.New("missing outputs")
+	}
+	if percentage < 0 || percentage > 100 {
+		return nil, errors.New("mirror percentage must be between 0 and 100")
+	}
+
+	ctx, done := context.WithCancel(context.Background())
+	m := &Mirror{
+		logger:        logger,
+		stats:         stats,
+		percentage:    percentage,
+		queueSize:     1,
+		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		outputs:       outputs,
+		primaryTSChan: make(chan types.Transaction),
+		closedChan:    make(chan struct{}),
+		ctx:           ctx,
+		close:         done,
+	}
+	if err := outputs[0].Consume(m.primaryTSChan); err != nil {
+		return nil, err
+	}
+
+	m.shadowTSChans = make([]chan types.Transaction, len(outputs)-1)
+	for i := range m.shadowTSChans {
+		m.shadowTSChans[i] = make(chan types.Transaction)
+		if err := outputs[i+1].Consume(m.shadowTSChans[i]); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// WithShadowQueueSize sets the number of messages that may be queued for each
+// shadow output before further messages destined for it are dropped. This must
+// be set before calling Consume.
+func (m *Mirror) WithShadowQueueSize(i int) *Mirror {
+	if i < 1 {
+		i = 1
+	}
+	m.queueSize = i
+	return m
+}
+
+//------------------------------------------------------------------------------
+
+// Consume assigns a new transactions channel for the broker to read.
+func (m *Mirror) Consume(transactions <-chan types.Transaction) error {
+	if m.transactions != nil {
+		return types.ErrAlreadyStarted
+	}
+	m.transactions = transactions
+
+	m.shadowQueues = make([]chan types.Message, len(m.shadowTSChans))
+	for i := range m.shadowQueues {
+		m.shadowQueues[i] = make(chan types.Message, m.queueSize)
+	}
+
+	go m.loop()
+	return nil
+}
+
+// Connected returns a boolean indicating whether the primary output is
+// currently connected to its target.
+func (m *Mirror) Connected() bool {
+	return m.outputs[0].Connected()
+}
+
+// MaxInFlight returns the maximum number of in flight messages permitted by the
+// primary output. This value can be used to determine a sensible value for
+// parent outputs, but should not be relied upon as part of dispatcher logic.
+func (m *Mirror) MaxInFlight() (int, bool) {
+	return output.GetMaxInFlight(m.outputs[0])
+}
+
+//------------------------------------------------------------------------------
+
+func (m *Mirror) shadowLoop(i int, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	var (
+		mShadowErr  = m.stats.GetCounter("shadow.error")
+		mShadowSent = m.stats.GetCounter("shadow.sent")
+	)
+
+	resChan := make(chan types.Response)
+	for {
+		var msg types.Message
+		var open bool
+		select {
+		case msg, open = <-m.shadowQueues[i]:
+			if !open {
+				return
+			}
+		case <-m.ctx.Done():
+			return
+		}
+
+		select {
+		case m.shadowTSChans[i] <- types.NewTransaction(msg, resChan):
+		case <-m.ctx.Done():
+			return
+		}
+		select {
+		case res := <-resChan:
+			if res.Error() != nil {
+				m.logger.Debugf("Failed to dispatch mirrored message to shadow output '%v': %v\n", i+1, res.Error())
+				mShadowErr.Incr(1)
+			} else {
+				mShadowSent.Incr(1)
+			}
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+// loop is an internal loop that brokers incoming messages to the primary and
+// shadow outputs.
+func (m *Mirror) loop() {
+	var (
+		shadowWG       = sync.WaitGroup{}
+		mMsgsRcvd      = m.stats.GetCounter("messages.received")
+		mShadowDropped = m.stats.GetCounter("shadow.dropped")
+	)
+
+	for i := range m.shadowQueues {
+		shadowWG.Add(1)
+		go m.shadowLoop(i, &shadowWG)
+	}
+
+	defer func() {
+		close(m.primaryTSChan)
+		for _, c := range m.shadowQueues {
+			close(c)
+		}
+		shadowWG.Wait()
+		for _, c := range m.shadowTSChans {
+			close(c)
+		}
+		closeAllOutputs(m.outputs)
+		close(m.closedChan)
+	}()
+
+	for {
+		var ts types.Transaction
+		var open bool
+		select {
+		case ts, open = <-m.transactions:
+			if !open {
+				return
+			}
+		case <-m.ctx.Done():
+			return
+		}
+		mMsgsRcvd.Incr(1)
+
+		if len(m.shadowQueues) > 0 && m.rand.Float64()*100 < m.percentage {
+			for _, q := range m.shadowQueues {
+				select {
+				case q <- ts.Payload.Copy():
+				default:
+					mShadowDropped.Incr(1)
+				}
+			}
+		}
+
+		select {
+		case m.primaryTSChan <- ts:
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+// CloseAsync shuts down the Mirror broker and stops processing requests.
+func (m *Mirror) CloseAsync() {
+	m.close()
+}
+
+// WaitForClose blocks until the Mirror broker has closed down.
+func (m *Mirror) WaitForClose(timeout time.Duration) error {
+	select {
+	case <-m.closedChan:
+	case <-time.After(timeout):
+		return types.ErrTimeout
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
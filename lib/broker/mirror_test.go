@@ -0,0 +1,150 @@
+package broker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ types.Consumer = &Mirror{}
+var _ types.Closable = &Mirror{}
+
+//------------------------------------------------------------------------------
+
+func TestMirrorShadowFailure(t *testing.T) {
+	primary, shadow := &MockOutputType{}, &MockOutputType{}
+
+	oTM, err := NewMirror([]types.Output{primary, shadow}, 100, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	readChan := make(chan types.Transaction)
+	require.NoError(t, oTM.Consume(readChan))
+
+	resChan := make(chan types.Response)
+	for _, content := range []string{"foo", "bar"} {
+		select {
+		case readChan <- types.NewTransaction(message.New([][]byte{[]byte(content)}), resChan):
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+
+		var ts types.Transaction
+		select {
+		case ts = <-shadow.TChan:
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+		assert.Equal(t, content, string(ts.Payload.Get(0).Get()))
+		go func(ts types.Transaction) {
+			ts.ResponseChan <- response.NewError(errors.New("shadow failed"))
+		}(ts)
+
+		select {
+		case ts = <-primary.TChan:
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+		assert.Equal(t, content, string(ts.Payload.Get(0).Get()))
+		go func() {
+			ts.ResponseChan <- response.NewAck()
+		}()
+
+		select {
+		case res := <-resChan:
+			assert.NoError(t, res.Error())
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+
+	oTM.CloseAsync()
+	require.NoError(t, oTM.WaitForClose(time.Second))
+}
+
+func TestMirrorShadowBackPressure(t *testing.T) {
+	primary, shadow := &MockOutputType{}, &MockOutputType{}
+
+	oTM, err := NewMirror([]types.Output{primary, shadow}, 100, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	readChan := make(chan types.Transaction)
+	require.NoError(t, oTM.Consume(readChan))
+
+	// The shadow output never reads, which must not block the primary.
+	resChan := make(chan types.Response)
+	for i := 0; i < 10; i++ {
+		select {
+		case readChan <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), resChan):
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+
+		var ts types.Transaction
+		select {
+		case ts = <-primary.TChan:
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+		go func() {
+			ts.ResponseChan <- response.NewAck()
+		}()
+
+		select {
+		case res := <-resChan:
+			assert.NoError(t, res.Error())
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+
+	oTM.CloseAsync()
+	require.NoError(t, oTM.WaitForClose(time.Second))
+}
+
+func TestMirrorZeroPercent(t *testing.T) {
+	primary, shadow := &MockOutputType{}, &MockOutputType{}
+
+	oTM, err := NewMirror([]types.Output{primary, shadow}, 0, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	readChan := make(chan types.Transaction)
+	require.NoError(t, oTM.Consume(readChan))
+
+	resChan := make(chan types.Response)
+	for i := 0; i < 10; i++ {
+		select {
+		case readChan <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), resChan):
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+
+		var ts types.Transaction
+		select {
+		case ts = <-primary.TChan:
+		case <-shadow.TChan:
+			t.Fatal("unexpected shadow message")
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+		go func() {
+			ts.ResponseChan <- response.NewAck()
+		}()
+		require.NoError(t, (<-resChan).Error())
+	}
+
+	oTM.CloseAsync()
+	require.NoError(t, oTM.WaitForClose(time.Second))
+}
+
+func TestMirrorBadPercentage(t *testing.T) {
+	_, err := NewMirror([]types.Output{&MockOutputType{}}, 101, log.Noop(), metrics.Noop())
+	require.Error(t, err)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/Jeffail/benthos/v3/internal/component/output"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/response"
 	"github.com/Jeffail/benthos/v3/lib/types"
 )
 
@@ -27,6 +28,8 @@ type Try struct {
 	outputTSChans []chan types.Transaction
 	outputs       []types.Output
 
+	dedupe *failoverDedupe
+
 	ctx        context.Context
 	close      func()
 	closedChan chan struct{}
@@ -148,9 +151,21 @@ func (t *Try) loop() {
 			}
 			mMsgsRcvd.Incr(1)
 
+			payload := tran.Payload
+			if t.dedupe != nil {
+				if payload = t.dedupe.filter(payload); payload == nil {
+					select {
+					case tran.ResponseChan <- response.NewAck():
+					case <-t.ctx.Done():
+						return
+					}
+					continue
+				}
+			}
+
 			rChan := make(chan types.Response)
 			select {
-			case t.outputTSChans[0] <- types.NewTransaction(tran.Payload, rChan):
+			case t.outputTSChans[0] <- types.NewTransaction(payload, rChan):
 			case <-t.ctx.Done():
 				return
 			}
@@ -168,6 +183,9 @@ func (t *Try) loop() {
 					if res.Error() != nil {
 						mErrs[i-1].Incr(1)
 					} else {
+						if i > 1 && t.dedupe != nil {
+							t.dedupe.delivered(payload)
+						}
 						break triesLoop
 					}
 				case <-t.ctx.Done():
@@ -176,7 +194,7 @@ func (t *Try) loop() {
 
 				if i < len(t.outputTSChans) {
 					select {
-					case t.outputTSChans[i] <- types.NewTransaction(tran.Payload, rChan):
+					case t.outputTSChans[i] <- types.NewTransaction(payload, rChan):
 					case <-t.ctx.Done():
 						return
 					}
@@ -0,0 +1,128 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/bloblang/field"
+	"github.com/Jeffail/benthos/v3/internal/interop"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/OneOfOne/xxhash"
+)
+
+//------------------------------------------------------------------------------
+
+// failoverDedupe records the messages that a Try broker has delivered to its
+// fallback outputs within a cache, in order to suppress sending them to the
+// primary output again within a window of time. This prevents each flap of a
+// primary output from resulting in duplicates of messages that are redelivered
+// by the input.
+type failoverDedupe struct {
+	mgr    types.Manager
+	cache  string
+	key    *field.Expression
+	window time.Duration
+	log    log.Modular
+
+	mSuppressed metrics.StatCounter
+	mErr        metrics.StatCounter
+}
+
+// WithFailoverDedupe enables the suppression of messages that have already been
+// delivered to a fallback output within a window of time, where each message is
+// identified by an interpolated key and recorded within a cache resource. The
+// cache must support setting a TTL per item, as records expire at the end of
+// the window. This must be set before calling Consume.
+func (t *Try) WithFailoverDedupe(
+	mgr types.Manager, cache string, key *field.Expression, window time.Duration, log log.Modular,
+) (*Try, error) {
+	supportsTTL := false
+	if err := interop.AccessCache(context.Background(), mgr, cache, func(c types.Cache) {
+		_, supportsTTL = c.(types.CacheWithTTL)
+	}); err != nil {
+		return nil, err
+	}
+	if !supportsTTL {
+		return nil, fmt.Errorf("cache resource '%v' does not support a TTL per item", cache)
+	}
+	t.dedupe = &failoverDedupe{
+		mgr:         mgr,
+		cache:       cache,
+		key:         key,
+		window:      window,
+		log:         log,
+		mSuppressed: t.stats.GetCounter("failover_dedupe.suppressed"),
+		mErr:        t.stats.GetCounter("failover_dedupe.error"),
+	}
+	return t, nil
+}
+
+func (d *failoverDedupe) cacheKey(index int, msg types.Message) string {
+	return "failover_" + strconv.FormatUint(xxhash.ChecksumString64(d.key.String(index, msg)), 16)
+}
+
+// filter returns a message containing only the parts that have not already
+// been delivered to a fallback output, which is nil when all of them have.
+func (d *failoverDedupe) filter(msg types.Message) types.Message {
+	var skip map[int]struct{}
+	if err := interop.AccessCache(context.Background(), d.mgr, d.cache, func(c types.Cache) {
+		for i := 0; i < msg.Len(); i++ {
+			if _, err := c.Get(d.cacheKey(i, msg)); err == nil {
+				if skip == nil {
+					skip = map[int]struct{}{}
+				}
+				skip[i] = struct{}{}
+			} else if err != types.ErrKeyNotFound {
+				d.mErr.Incr(1)
+				d.log.Errorf("Failed to check failover dedupe cache: %v\n", err)
+			}
+		}
+	}); err != nil {
+		d.mErr.Incr(1)
+		d.log.Errorf("Failed to access failover dedupe cache: %v\n", err)
+	}
+	if len(skip) == 0 {
+		return msg
+	}
+	d.mSuppressed.Incr(int64(len(skip)))
+	if len(skip) == msg.Len() {
+		return nil
+	}
+	filtered := message.New(nil)
+	for i := 0; i < msg.Len(); i++ {
+		if _, exists := skip[i]; !exists {
+			filtered.Append(msg.Get(i))
+		}
+	}
+	return filtered
+}
+
+// delivered records the parts of a message that were delivered to a fallback
+// output.
+func (d *failoverDedupe) delivered(msg types.Message) {
+	items := make(map[string]types.CacheTTLItem, msg.Len())
+	for i := 0; i < msg.Len(); i++ {
+		items[d.cacheKey(i, msg)] = types.CacheTTLItem{
+			Value: []byte{'t'},
+			TTL:   &d.window,
+		}
+	}
+
+	var err error
+	if cerr := interop.AccessCache(context.Background(), d.mgr, d.cache, func(c types.Cache) {
+		err = c.(types.CacheWithTTL).SetMultiWithTTL(items)
+	}); cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		d.mErr.Incr(1)
+		d.log.Errorf("Failed to record failover delivery within cache: %v\n", err)
+	}
+}
+
+//------------------------------------------------------------------------------
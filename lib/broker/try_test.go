@@ -8,10 +8,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Jeffail/benthos/v3/internal/bloblang"
+	"github.com/Jeffail/benthos/v3/lib/cache"
+	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/message"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/response"
 	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var _ types.Consumer = &Try{}
@@ -385,3 +390,121 @@ func TestTryAllFailParallel(t *testing.T) {
 }
 
 //------------------------------------------------------------------------------
+
+type dedupeMgr struct {
+	types.DudMgr
+	caches map[string]types.Cache
+}
+
+func (d dedupeMgr) GetCache(name string) (types.Cache, error) {
+	if c, exists := d.caches[name]; exists {
+		return c, nil
+	}
+	return nil, types.ErrCacheNotFound
+}
+
+func TestTryFailoverDedupe(t *testing.T) {
+	memCache, err := cache.NewMemory(cache.NewConfig(), nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+	mgr := dedupeMgr{caches: map[string]types.Cache{"foocache": memCache}}
+
+	mockOutputs := []*MockOutputType{{}, {}}
+	outputs := []types.Output{mockOutputs[0], mockOutputs[1]}
+
+	key, err := bloblang.NewField(`${! content() }`)
+	require.NoError(t, err)
+
+	oTM, err := NewTry(outputs, metrics.Noop())
+	require.NoError(t, err)
+	_, err = oTM.WithFailoverDedupe(mgr, "foocache", key, time.Minute, log.Noop())
+	require.NoError(t, err)
+
+	readChan := make(chan types.Transaction)
+	resChan := make(chan types.Response)
+	require.NoError(t, oTM.Consume(readChan))
+
+	send := func(content ...string) {
+		t.Helper()
+		var parts [][]byte
+		for _, c := range content {
+			parts = append(parts, []byte(c))
+		}
+		select {
+		case readChan <- types.NewTransaction(message.New(parts), resChan):
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for broker send")
+		}
+	}
+
+	receive := func(i int, res types.Response) []string {
+		t.Helper()
+		var ts types.Transaction
+		select {
+		case ts = <-mockOutputs[i].TChan:
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for output %v", i)
+		}
+		var contents []string
+		_ = ts.Payload.Iter(func(_ int, p types.Part) error {
+			contents = append(contents, string(p.Get()))
+			return nil
+		})
+		select {
+		case ts.ResponseChan <- res:
+		case <-time.After(time.Second):
+			t.Fatal("Timed out responding to broker")
+		}
+		return contents
+	}
+
+	awaitAck := func() {
+		t.Helper()
+		select {
+		case res := <-resChan:
+			require.NoError(t, res.Error())
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for broker response")
+		}
+	}
+
+	// The primary fails and the message is delivered to the fallback.
+	send("foo")
+	assert.Equal(t, []string{"foo"}, receive(0, response.NewError(errors.New("nope"))))
+	assert.Equal(t, []string{"foo"}, receive(1, response.NewAck()))
+	awaitAck()
+
+	// Redelivered, the message is acknowledged without being sent again.
+	send("foo")
+	awaitAck()
+
+	// Only the parts of a batch that weren't delivered are sent.
+	send("foo", "bar")
+	assert.Equal(t, []string{"bar"}, receive(0, response.NewAck()))
+	awaitAck()
+
+	// Messages delivered to the primary are not recorded.
+	send("bar")
+	assert.Equal(t, []string{"bar"}, receive(0, response.NewAck()))
+	awaitAck()
+
+	oTM.CloseAsync()
+	require.NoError(t, oTM.WaitForClose(time.Second*10))
+}
+
+func TestTryFailoverDedupeNoTTL(t *testing.T) {
+	memCache, err := cache.NewMemory(cache.NewConfig(), nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+	mgr := dedupeMgr{caches: map[string]types.Cache{
+		"foocache": struct{ types.Cache }{memCache},
+	}}
+
+	key, err := bloblang.NewField(`${! content() }`)
+	require.NoError(t, err)
+
+	oTM, err := NewTry([]types.Output{&MockOutputType{}}, metrics.Noop())
+	require.NoError(t, err)
+
+	_, err = oTM.WithFailoverDedupe(mgr, "foocache", key, time.Minute, log.Noop())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not support a TTL per item")
+}
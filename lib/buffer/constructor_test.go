@@ -93,8 +93,11 @@ func TestSanitise(t *testing.T) {
 	exp = `{` +
 		`"type":"memory",` +
 		`"memory":{` +
-		`"batch_policy":{"byte_size":0,"check":"","count":0,"enabled":false,"period":"","processors":[]},` +
-		`"limit":20` +
+		`"batch_policy":{"byte_size":0,"check":"","count":0,"enabled":false,"max_count":0,"period":"","processors":[]},` +
+		`"inspect_endpoint":false,` +
+		`"limit":20,` +
+		`"max_attempts":0,` +
+		`"poison_output":""` +
 		`}` +
 		`}`
 
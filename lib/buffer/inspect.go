@@ -0,0 +1,102 @@
+package buffer
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Jeffail/benthos/v3/lib/buffer/parallel"
+	"github.com/Jeffail/benthos/v3/lib/types"
+)
+
+//------------------------------------------------------------------------------
+
+const (
+	inspectDefaultLimit = 20
+	inspectMaxLimit     = 1000
+)
+
+// peeker is implemented by buffers that are able to list the messages they
+// hold without consuming them.
+type peeker interface {
+	Peek(offset, limit int) ([]parallel.PeekedMessage, int)
+}
+
+type inspectedPart struct {
+	Metadata map[string]string `json:"metadata"`
+	Content  string            `json:"content"`
+}
+
+type inspectedMessage struct {
+	InFlight bool            `json:"in_flight"`
+	Parts    []inspectedPart `json:"parts"`
+}
+
+type inspectResponse struct {
+	Offset   int                `json:"offset"`
+	Total    int                `json:"total"`
+	Messages []inspectedMessage `json:"messages"`
+}
+
+// inspectHandler returns an HTTP handler that pages through the messages held
+// within a buffer without consuming them, the page is selected with the query
+// parameters offset and limit.
+func inspectHandler(buf peeker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		offset, limit := 0, inspectDefaultLimit
+		var err error
+		if v := r.URL.Query().Get("offset"); v != "" {
+			if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+				http.Error(w, "Bad request: offset must be a positive integer", http.StatusBadRequest)
+				return
+			}
+		}
+		if v := r.URL.Query().Get("limit"); v != "" {
+			if limit, err = strconv.Atoi(v); err != nil || limit < 1 {
+				http.Error(w, "Bad request: limit must be a positive integer", http.StatusBadRequest)
+				return
+			}
+		}
+		if limit > inspectMaxLimit {
+			limit = inspectMaxLimit
+		}
+
+		peeked, total := buf.Peek(offset, limit)
+		res := inspectResponse{
+			Offset:   offset,
+			Total:    total,
+			Messages: make([]inspectedMessage, 0, len(peeked)),
+		}
+		for _, p := range peeked {
+			msg := inspectedMessage{InFlight: p.InFlight}
+			_ = p.Message.Iter(func(i int, part types.Part) error {
+				meta := map[string]string{}
+				_ = part.Metadata().Iter(func(k, v string) error {
+					meta[k] = v
+					return nil
+				})
+				msg.Parts = append(msg.Parts, inspectedPart{
+					Metadata: meta,
+					Content:  string(part.Get()),
+				})
+				return nil
+			})
+			res.Messages = append(res.Messages, msg)
+		}
+
+		resBytes, err := json.Marshal(res)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(resBytes)
+	}
+}
+
+//------------------------------------------------------------------------------
@@ -0,0 +1,63 @@
+package buffer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/buffer/parallel"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectHandler(t *testing.T) {
+	mem := parallel.NewMemory(1000)
+	for _, c := range []string{"foo", "bar", "baz"} {
+		part := message.NewPart([]byte(c))
+		part.Metadata().Set("key", c)
+		msg := message.New(nil)
+		msg.Append(part)
+		_, err := mem.PushMessage(msg)
+		require.NoError(t, err)
+	}
+	_, _, err := mem.NextMessage()
+	require.NoError(t, err)
+
+	handler := inspectHandler(mem)
+
+	req := httptest.NewRequest("GET", "/buffer/messages?offset=1&limit=1", nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var res inspectResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, inspectResponse{
+		Offset: 1,
+		Total:  3,
+		Messages: []inspectedMessage{
+			{
+				InFlight: false,
+				Parts: []inspectedPart{
+					{Metadata: map[string]string{"key": "bar"}, Content: "bar"},
+				},
+			},
+		},
+	}, res)
+
+	req = httptest.NewRequest("GET", "/buffer/messages", nil)
+	w = httptest.NewRecorder()
+	handler(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res.Messages, 3)
+	assert.True(t, res.Messages[0].InFlight)
+	assert.Equal(t, "foo", res.Messages[0].Parts[0].Content)
+
+	req = httptest.NewRequest("GET", "/buffer/messages?limit=nope", nil)
+	w = httptest.NewRecorder()
+	handler(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -1,6 +1,7 @@
 package buffer
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/Jeffail/benthos/v3/internal/docs"
@@ -38,7 +39,49 @@ therefore should never be used in places where data loss is unacceptable.
 ## Batching
 
 It is possible to batch up messages sent from this buffer using a
-[batch policy](/docs/configuration/batching#batch-policy).`,
+[batch policy](/docs/configuration/batching#batch-policy).
+
+## Poison Messages
+
+Messages that fail to be delivered remain in the buffer and are attempted again,
+which means a single malformed message can prevent the buffer from ever being
+drained. Setting ` + "`max_attempts`" + ` limits the number of delivery attempts
+of each message, after which the message is written to the
+` + "`poison_output`" + ` resource instead:
+
+` + "```yaml" + `
+buffer:
+  memory:
+    max_attempts: 5
+    poison_output: dead_letters
+
+output_resources:
+  - label: dead_letters
+    file:
+      path: ./dead_letters.jsonl
+      codec: lines
+` + "```" + `
+
+## Inspecting the Buffer
+
+When ` + "`inspect_endpoint`" + ` is enabled an HTTP endpoint ` + "`/buffer/messages`" + ` is
+registered, which lists the messages held within the buffer without consuming
+them. This is useful for checking whether a particular message is stuck within
+the buffer without stopping the pipeline. Messages currently being delivered are
+listed first and are marked with ` + "`in_flight`" + `, followed by the messages
+waiting to be delivered in the order in which they will be read. The listing is
+paged with the query parameters ` + "`offset`" + ` and ` + "`limit`" + `:
+
+` + "```sh" + `
+curl "http://localhost:4195/buffer/messages?offset=0&limit=20"
+` + "```" + `
+
+When running in [streams mode](/docs/guides/streams_mode/about) the endpoint of
+each stream is prefixed with the stream ID, e.g. ` + "`/foo/buffer/messages`" + ` for
+the stream ` + "`foo`" + `.
+
+Since the endpoint exposes the contents of messages it should only be enabled
+when the HTTP server of Benthos is not publicly accessible.`,
 		FieldSpecs: docs.FieldSpecs{
 			docs.FieldCommon("limit", "The maximum buffer size (in bytes) to allow before applying backpressure upstream."),
 			docs.FieldCommon("batch_policy", "Optionally configure a policy to flush buffered messages in batches.").WithChildren(
@@ -46,6 +89,9 @@ It is possible to batch up messages sent from this buffer using a
 					docs.FieldCommon("enabled", "Whether to batch messages as they are flushed."),
 				}, batch.FieldSpec().Children...)...,
 			),
+			docs.FieldAdvanced("max_attempts", "The maximum number of times delivery of a message from the buffer is attempted. Once a message has failed this many times it is routed to the `poison_output` if one is set, otherwise it is dropped. When set to zero messages are retried indefinitely.").AtVersion("3.54.0"),
+			docs.FieldAdvanced("poison_output", "An optional [output resource](/docs/configuration/resources) that messages are written to once they have reached `max_attempts`. If the write fails the message remains in the buffer and is attempted again.").AtVersion("3.54.0"),
+			docs.FieldAdvanced("inspect_endpoint", "Whether to register an HTTP endpoint `/buffer/messages` that lists the messages held within the buffer without consuming them.").AtVersion("3.54.0"),
 		},
 	}
 }
@@ -60,8 +106,11 @@ type EnabledBatchPolicyConfig struct {
 
 // MemoryConfig is config values for a purely memory based ring buffer type.
 type MemoryConfig struct {
-	Limit       int                      `json:"limit" yaml:"limit"`
-	BatchPolicy EnabledBatchPolicyConfig `json:"batch_policy" yaml:"batch_policy"`
+	Limit           int                      `json:"limit" yaml:"limit"`
+	BatchPolicy     EnabledBatchPolicyConfig `json:"batch_policy" yaml:"batch_policy"`
+	MaxAttempts     int                      `json:"max_attempts" yaml:"max_attempts"`
+	PoisonOutput    string                   `json:"poison_output" yaml:"poison_output"`
+	InspectEndpoint bool                     `json:"inspect_endpoint" yaml:"inspect_endpoint"`
 }
 
 // NewMemoryConfig creates a new MemoryConfig with default values.
@@ -72,6 +121,9 @@ func NewMemoryConfig() MemoryConfig {
 			Enabled:      false,
 			PolicyConfig: batch.NewPolicyConfig(),
 		},
+		MaxAttempts:     0,
+		PoisonOutput:    "",
+		InspectEndpoint: false,
 	}
 }
 
@@ -79,7 +131,24 @@ func NewMemoryConfig() MemoryConfig {
 
 // NewMemory creates a buffer held in memory.
 func NewMemory(config Config, mgr types.Manager, log log.Modular, stats metrics.Type) (Type, error) {
-	wrap := NewParallelWrapper(config, parallel.NewMemory(config.Memory.Limit), log, stats)
+	mem := parallel.NewMemory(config.Memory.Limit)
+	if config.Memory.InspectEndpoint {
+		mgr.RegisterEndpoint(
+			"/buffer/messages",
+			"Lists the messages held within the buffer without consuming them, paged with the query parameters offset and limit.",
+			inspectHandler(mem),
+		)
+	}
+	wrap := NewParallelWrapper(config, mem, log, stats)
+	if config.Memory.MaxAttempts > 0 {
+		poison, err := newPoisonHandler(config.Memory.MaxAttempts, config.Memory.PoisonOutput, mgr, log, stats)
+		if err != nil {
+			return nil, fmt.Errorf("poison output: %w", err)
+		}
+		wrap.(*ParallelWrapper).poison = poison
+	} else if config.Memory.PoisonOutput != "" {
+		return nil, errors.New("a poison_output requires max_attempts to be greater than zero")
+	}
 	if !config.Memory.BatchPolicy.Enabled {
 		return wrap, nil
 	}
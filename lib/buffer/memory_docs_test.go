@@ -26,10 +26,14 @@ func TestMemorySanit(t *testing.T) {
     batch_policy:
         enabled: false
         count: 0
+        max_count: 0
         byte_size: 0
         period: ""
         check: ""
         processors: []
+    max_attempts: 0
+    poison_output: ""
+    inspect_endpoint: false
 `
 
 	b, err := yaml.Marshal(node)
@@ -1,6 +1,7 @@
 package parallel
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/Jeffail/benthos/v3/lib/types"
@@ -8,10 +9,19 @@ import (
 
 //------------------------------------------------------------------------------
 
+// memoryEntry is a message held within a Memory buffer along with an ID that
+// is kept across failed delivery attempts.
+type memoryEntry struct {
+	id  uint64
+	msg types.Message
+}
+
 // Memory is a parallel buffer implementation that allows multiple parallel
 // consumers to read and purge messages from the buffer asynchronously.
 type Memory struct {
-	messages     []types.Message
+	messages     []memoryEntry
+	inFlight     map[uint64]types.Message
+	nextID       uint64
 	bytes        int
 	pendingBytes int
 
@@ -24,9 +34,10 @@ type Memory struct {
 // NewMemory creates a memory based parallel buffer.
 func NewMemory(capacity int) *Memory {
 	return &Memory{
-		bytes: 0,
-		cap:   capacity,
-		cond:  sync.NewCond(&sync.Mutex{}),
+		bytes:    0,
+		inFlight: map[uint64]types.Message{},
+		cap:      capacity,
+		cond:     sync.NewCond(&sync.Mutex{}),
 	}
 }
 
@@ -35,6 +46,15 @@ func NewMemory(capacity int) *Memory {
 // NextMessage reads the next oldest message, the message is preserved until the
 // returned AckFunc is called.
 func (m *Memory) NextMessage() (types.Message, AckFunc, error) {
+	_, msg, ackFn, err := m.NextIdentifiedMessage()
+	return msg, ackFn, err
+}
+
+// NextIdentifiedMessage reads the next oldest message along with an ID that
+// identifies it for as long as it is held within the buffer, including when it
+// is read again after a failed delivery attempt. The message is preserved until
+// the returned AckFunc is called.
+func (m *Memory) NextIdentifiedMessage() (uint64, types.Message, AckFunc, error) {
 	m.cond.L.Lock()
 	for len(m.messages) == 0 && !m.closed {
 		m.cond.Wait()
@@ -42,12 +62,13 @@ func (m *Memory) NextMessage() (types.Message, AckFunc, error) {
 
 	if m.closed {
 		m.cond.L.Unlock()
-		return nil, nil, types.ErrTypeClosed
+		return 0, nil, nil, types.ErrTypeClosed
 	}
 
-	msg := m.messages[0]
+	entry := m.messages[0]
+	msg := entry.msg
 
-	m.messages[0] = nil
+	m.messages[0] = memoryEntry{}
 	m.messages = m.messages[1:]
 
 	messageSize := 0
@@ -57,20 +78,24 @@ func (m *Memory) NextMessage() (types.Message, AckFunc, error) {
 	})
 	m.pendingBytes += messageSize
 
+	id := entry.id
+	m.inFlight[id] = msg
+
 	m.cond.Broadcast()
 	m.cond.L.Unlock()
 
-	return msg, func(ack bool) (int, error) {
+	return id, msg, func(ack bool) (int, error) {
 		m.cond.L.Lock()
 		if m.closed {
 			m.cond.L.Unlock()
 			return 0, types.ErrTypeClosed
 		}
 		m.pendingBytes -= messageSize
+		delete(m.inFlight, id)
 		if ack {
 			m.bytes -= messageSize
 		} else {
-			m.messages = append([]types.Message{msg}, m.messages...)
+			m.messages = append([]memoryEntry{entry}, m.messages...)
 		}
 		m.cond.Broadcast()
 
@@ -81,6 +106,47 @@ func (m *Memory) NextMessage() (types.Message, AckFunc, error) {
 	}, nil
 }
 
+// PeekedMessage is a message held within a buffer that has been listed without
+// being consumed.
+type PeekedMessage struct {
+	Message  types.Message
+	InFlight bool
+}
+
+// Peek lists up to limit messages held within the buffer starting from an
+// offset without consuming them, and returns the total number of messages
+// held. Messages currently being delivered are listed first, in the order in
+// which they were written, followed by the messages waiting to be read. The
+// listed messages are deep copies and can therefore be modified freely.
+func (m *Memory) Peek(offset, limit int) ([]PeekedMessage, int) {
+	m.cond.L.Lock()
+	defer m.cond.L.Unlock()
+
+	ids := make([]uint64, 0, len(m.inFlight))
+	for id := range m.inFlight {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	total := len(ids) + len(m.messages)
+	var peeked []PeekedMessage
+	for i := offset; i < total && len(peeked) < limit; i++ {
+		if i < len(ids) {
+			peeked = append(peeked, PeekedMessage{
+				Message:  m.inFlight[ids[i]].DeepCopy(),
+				InFlight: true,
+			})
+		} else {
+			peeked = append(peeked, PeekedMessage{
+				Message: m.messages[i-len(ids)].msg.DeepCopy(),
+			})
+		}
+	}
+	return peeked, total
+}
+
 // PushMessage adds a new message to the stack. Returns the backlog in bytes.
 func (m *Memory) PushMessage(msg types.Message) (int, error) {
 	extraBytes := 0
@@ -108,7 +174,11 @@ func (m *Memory) PushMessage(msg types.Message) (int, error) {
 		}
 	}
 
-	m.messages = append(m.messages, msg.DeepCopy())
+	m.messages = append(m.messages, memoryEntry{
+		id:  m.nextID,
+		msg: msg.DeepCopy(),
+	})
+	m.nextID++
 	m.bytes += extraBytes
 
 	backlog := m.bytes
@@ -9,6 +9,8 @@ import (
 
 	"github.com/Jeffail/benthos/v3/lib/message"
 	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMemoryBasic(t *testing.T) {
@@ -227,6 +229,36 @@ func TestMemoryAck(t *testing.T) {
 	}
 }
 
+func TestMemoryIdentifiedMessages(t *testing.T) {
+	block := NewMemory(1000)
+
+	_, err := block.PushMessage(message.New([][]byte{[]byte("1")}))
+	require.NoError(t, err)
+	_, err = block.PushMessage(message.New([][]byte{[]byte("2")}))
+	require.NoError(t, err)
+
+	firstID, m, ackFunc, err := block.NextIdentifiedMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(m.Get(0).Get()))
+	_, err = ackFunc(false)
+	require.NoError(t, err)
+
+	// A message read again after a failed attempt keeps its ID.
+	id, m, ackFunc, err := block.NextIdentifiedMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(m.Get(0).Get()))
+	assert.Equal(t, firstID, id)
+	_, err = ackFunc(true)
+	require.NoError(t, err)
+
+	id, m, ackFunc, err = block.NextIdentifiedMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "2", string(m.Get(0).Get()))
+	assert.NotEqual(t, firstID, id)
+	_, err = ackFunc(true)
+	require.NoError(t, err)
+}
+
 func TestMemoryClose(t *testing.T) {
 	block := NewMemory(1000)
 
@@ -322,3 +354,84 @@ func TestMemoryRejectLargeMessage(t *testing.T) {
 		t.Errorf("Unexpected error: %v != %v", exp, actual)
 	}
 }
+
+func TestMemoryPeek(t *testing.T) {
+	block := NewMemory(1000)
+	for _, c := range []string{"1", "2", "3"} {
+		if _, err := block.PushMessage(message.New([][]byte{[]byte(c)})); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, ackFunc, err := block.NextMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peeked, total := block.Peek(0, 10)
+	if exp, act := 3, total; exp != act {
+		t.Errorf("Wrong total: %v != %v", act, exp)
+	}
+	if exp, act := 3, len(peeked); exp != act {
+		t.Fatalf("Wrong count of peeked messages: %v != %v", act, exp)
+	}
+	for i, exp := range []string{"1", "2", "3"} {
+		if act := string(peeked[i].Message.Get(0).Get()); exp != act {
+			t.Errorf("Wrong message contents at %v: %v != %v", i, act, exp)
+		}
+		if exp, act := i == 0, peeked[i].InFlight; exp != act {
+			t.Errorf("Wrong in flight flag at %v: %v != %v", i, act, exp)
+		}
+	}
+
+	if peeked, _ = block.Peek(1, 1); len(peeked) != 1 {
+		t.Fatalf("Wrong count of peeked messages: %v != %v", len(peeked), 1)
+	}
+	if exp, act := "2", string(peeked[0].Message.Get(0).Get()); exp != act {
+		t.Errorf("Wrong message contents: %v != %v", act, exp)
+	}
+
+	// Peeking does not consume messages.
+	if _, err := ackFunc(true); err != nil {
+		t.Fatal(err)
+	}
+	if _, total = block.Peek(0, 10); total != 2 {
+		t.Errorf("Wrong total: %v != %v", total, 2)
+	}
+	m, _, err := block.NextMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp, act := "2", string(m.Get(0).Get()); exp != act {
+		t.Errorf("Wrong message contents: %v != %v", act, exp)
+	}
+	block.Close()
+}
+
+func TestMemoryPeekDeepCopy(t *testing.T) {
+	block := NewMemory(1000)
+	if _, err := block.PushMessage(message.New([][]byte{[]byte("foo")})); err != nil {
+		t.Fatal(err)
+	}
+
+	// Modifying a peeked message must not modify the message held within the
+	// buffer.
+	peeked, _ := block.Peek(0, 1)
+	if exp, act := 1, len(peeked); exp != act {
+		t.Fatalf("Wrong count of peeked messages: %v != %v", act, exp)
+	}
+	peeked[0].Message.Get(0).Get()[0] = 'b'
+	peeked[0].Message.Get(0).Metadata().Set("foo", "bar")
+
+	m, _, err := block.NextMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp, act := "foo", string(m.Get(0).Get()); exp != act {
+		t.Errorf("Wrong message contents: %v != %v", act, exp)
+	}
+	if exp, act := "", m.Get(0).Metadata().Get("foo"); exp != act {
+		t.Errorf("Wrong metadata: %v != %v", act, exp)
+	}
+	block.Close()
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/Jeffail/benthos/v3/lib/buffer/parallel"
 	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
 	"github.com/Jeffail/benthos/v3/lib/message/tracing"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/response"
@@ -49,6 +50,7 @@ type ParallelWrapper struct {
 
 	buffer      Parallel
 	errThrottle *throttle.Type
+	poison      *poisonHandler
 
 	running   int32
 	consuming int32
@@ -127,6 +129,20 @@ func (m *ParallelWrapper) inputLoop() {
 	}
 }
 
+// identifiedParallel is implemented by parallel buffers that identify the
+// messages they hold, allowing delivery attempts to be tracked per message.
+type identifiedParallel interface {
+	NextIdentifiedMessage() (uint64, types.Message, parallel.AckFunc, error)
+}
+
+func (m *ParallelWrapper) nextMessage() (uint64, types.Message, parallel.AckFunc, error) {
+	if ib, ok := m.buffer.(identifiedParallel); ok {
+		return ib.NextIdentifiedMessage()
+	}
+	msg, ackFunc, err := m.buffer.NextMessage()
+	return 0, msg, ackFunc, err
+}
+
 // outputLoop is an internal loop brokers buffer messages to output pipe.
 func (m *ParallelWrapper) outputLoop() {
 	defer func() {
@@ -146,7 +162,7 @@ func (m *ParallelWrapper) outputLoop() {
 	)
 
 	for atomic.LoadInt32(&m.running) == 1 {
-		msg, ackFunc, err := m.buffer.NextMessage()
+		id, msg, ackFunc, err := m.nextMessage()
 		if err != nil {
 			if err != types.ErrTypeClosed {
 				mReadErr.Incr(1)
@@ -161,6 +177,7 @@ func (m *ParallelWrapper) outputLoop() {
 
 		// It's possible that the buffer wiped our previous root span.
 		tracing.InitSpans("buffer_"+m.conf.Type, msg)
+		message.StampShifted(msg, time.Now())
 
 		mReadCount.Incr(1)
 		m.errThrottle.Reset()
@@ -180,8 +197,14 @@ func (m *ParallelWrapper) outputLoop() {
 				mLatency.Timing(time.Since(msg.CreatedAt()).Nanoseconds())
 				tracing.FinishSpans(msg)
 				doAck = true
+				if m.poison != nil {
+					m.poison.delivered(id)
+				}
 			} else {
 				mSendErr.Incr(1)
+				if open && m.poison != nil {
+					doAck = m.poison.failed(id, msg)
+				}
 			}
 			blog, ackErr := aFunc(doAck)
 			if ackErr != nil {
@@ -0,0 +1,110 @@
+package buffer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/interop"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/types"
+)
+
+//------------------------------------------------------------------------------
+
+// poisonHandler tracks the number of delivery attempts made for each message
+// read from a buffer, and once a message has reached a maximum number of
+// attempts it is routed to a poison output resource instead of being replayed
+// again. Messages are tracked by the ID given to them by the buffer, which is
+// kept across attempts.
+type poisonHandler struct {
+	maxAttempts int
+	output      string
+
+	mgr   types.Manager
+	log   log.Modular
+	stats metrics.Type
+
+	mut      sync.Mutex
+	attempts map[uint64]int
+}
+
+func newPoisonHandler(maxAttempts int, output string, mgr types.Manager, log log.Modular, stats metrics.Type) (*poisonHandler, error) {
+	if output != "" {
+		if err := interop.ProbeOutput(context.Background(), mgr, output); err != nil {
+			return nil, err
+		}
+	}
+	return &poisonHandler{
+		maxAttempts: maxAttempts,
+		output:      output,
+		mgr:         mgr,
+		log:         log,
+		stats:       stats,
+		attempts:    map[uint64]int{},
+	}, nil
+}
+
+// delivered clears the attempts of a message that was delivered successfully.
+func (p *poisonHandler) delivered(id uint64) {
+	p.mut.Lock()
+	delete(p.attempts, id)
+	p.mut.Unlock()
+}
+
+// failed records a failed delivery attempt of a message and returns true if the
+// message has been dealt with and should therefore be removed from the buffer
+// rather than replayed.
+func (p *poisonHandler) failed(id uint64, msg types.Message) bool {
+	p.mut.Lock()
+	p.attempts[id]++
+	attempts := p.attempts[id]
+	p.mut.Unlock()
+
+	if attempts < p.maxAttempts {
+		return false
+	}
+
+	if p.output == "" {
+		p.log.Errorf("Dropping message after %v failed delivery attempts\n", attempts)
+		metrics.NewDroppedCounter(p.stats, "poison.dropped", metrics.DropReasonPoison).Incr(1)
+		p.delivered(id)
+		return true
+	}
+
+	if err := p.writeOutput(msg); err != nil {
+		p.log.Errorf("Failed to write message to poison output resource '%v': %v\n", p.output, err)
+		p.stats.GetCounter("poison.error").Incr(1)
+		return false
+	}
+
+	p.log.Warnf("Routed message to poison output resource '%v' after %v failed delivery attempts\n", p.output, attempts)
+	p.stats.GetCounter("poison.sent").Incr(1)
+	p.delivered(id)
+	return true
+}
+
+func (p *poisonHandler) writeOutput(msg types.Message) error {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	resChan := make(chan types.Response, 1)
+
+	var err error
+	if aerr := interop.AccessOutput(ctx, p.mgr, p.output, func(o types.OutputWriter) {
+		err = o.WriteTransaction(ctx, types.NewTransaction(msg.Copy(), resChan))
+	}); aerr != nil {
+		return aerr
+	}
+	if err != nil {
+		return err
+	}
+
+	select {
+	case res := <-resChan:
+		return res.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,172 @@
+package buffer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type poisonWriter struct {
+	msgs chan types.Message
+}
+
+func (p *poisonWriter) WriteTransaction(ctx context.Context, ts types.Transaction) error {
+	p.msgs <- ts.Payload
+	ts.ResponseChan <- response.NewAck()
+	return nil
+}
+
+func (p *poisonWriter) Connected() bool {
+	return true
+}
+
+func (p *poisonWriter) CloseAsync() {}
+
+func (p *poisonWriter) WaitForClose(time.Duration) error {
+	return nil
+}
+
+type poisonMgr struct {
+	types.DudMgr
+	outputs map[string]types.OutputWriter
+}
+
+func (p poisonMgr) GetOutput(name string) (types.OutputWriter, error) {
+	if o, exists := p.outputs[name]; exists {
+		return o, nil
+	}
+	return nil, types.ErrOutputNotFound
+}
+
+func TestMemoryBufferPoisonOutput(t *testing.T) {
+	writer := &poisonWriter{msgs: make(chan types.Message, 1)}
+	mgr := poisonMgr{outputs: map[string]types.OutputWriter{"foo": writer}}
+
+	conf := NewConfig()
+	conf.Type = "memory"
+	conf.Memory.MaxAttempts = 3
+	conf.Memory.PoisonOutput = "foo"
+
+	stats := metrics.NewLocal()
+	buf, err := New(conf, mgr, log.Noop(), stats)
+	require.NoError(t, err)
+
+	tChan, resChan := make(chan types.Transaction), make(chan types.Response)
+	require.NoError(t, buf.Consume(tChan))
+
+	tChan <- types.NewTransaction(message.New([][]byte{[]byte("bad")}), resChan)
+	require.NoError(t, (<-resChan).Error())
+
+	tChan <- types.NewTransaction(message.New([][]byte{[]byte("good")}), resChan)
+	require.NoError(t, (<-resChan).Error())
+
+	var badAttempts, goodAttempts int
+	for badAttempts < 3 || goodAttempts < 1 {
+		select {
+		case tran := <-buf.TransactionChan():
+			if string(tran.Payload.Get(0).Get()) == "bad" {
+				badAttempts++
+				tran.ResponseChan <- response.NewError(types.ErrNotConnected)
+			} else {
+				goodAttempts++
+				tran.ResponseChan <- response.NewAck()
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out waiting for message")
+		}
+	}
+
+	select {
+	case msg := <-writer.msgs:
+		assert.Equal(t, "bad", string(msg.Get(0).Get()))
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for poison output")
+	}
+
+	select {
+	case tran := <-buf.TransactionChan():
+		t.Errorf("unexpected message: %s", tran.Payload.Get(0).Get())
+	case <-time.After(time.Millisecond * 100):
+	}
+	assert.Equal(t, 3, badAttempts)
+	assert.Equal(t, 1, goodAttempts)
+
+	// Messages routed to the poison output aren't dropped.
+	assert.Eventually(t, func() bool {
+		return stats.GetCounters()["poison.sent"] == 1
+	}, time.Second*5, time.Millisecond*10)
+	assert.NotContains(t, stats.GetCounters(), "dropped_by_reason")
+
+	buf.CloseAsync()
+	require.NoError(t, buf.WaitForClose(time.Second*5))
+}
+
+func TestMemoryBufferPoisonAttemptsCleared(t *testing.T) {
+	conf := NewConfig()
+	conf.Type = "memory"
+	conf.Memory.MaxAttempts = 2
+
+	buf, err := New(conf, poisonMgr{}, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	tChan, resChan := make(chan types.Transaction), make(chan types.Response)
+	require.NoError(t, buf.Consume(tChan))
+
+	for _, content := range []string{"foo", "bar"} {
+		tChan <- types.NewTransaction(message.New([][]byte{[]byte(content)}), resChan)
+		require.NoError(t, (<-resChan).Error())
+	}
+
+	// Each message fails once and is then delivered, which must not count
+	// towards the attempts of the other message nor be remembered afterwards.
+	attempts := map[string]int{}
+	for delivered := 0; delivered < 2; {
+		select {
+		case tran := <-buf.TransactionChan():
+			content := string(tran.Payload.Get(0).Get())
+			attempts[content]++
+			if attempts[content] == 1 {
+				tran.ResponseChan <- response.NewError(types.ErrNotConnected)
+			} else {
+				tran.ResponseChan <- response.NewAck()
+				delivered++
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out waiting for message")
+		}
+	}
+	assert.Equal(t, map[string]int{"foo": 2, "bar": 2}, attempts)
+
+	poison := buf.(*ParallelWrapper).poison
+	assert.Eventually(t, func() bool {
+		poison.mut.Lock()
+		defer poison.mut.Unlock()
+		return len(poison.attempts) == 0
+	}, time.Second*5, time.Millisecond*10)
+
+	buf.CloseAsync()
+	require.NoError(t, buf.WaitForClose(time.Second*5))
+}
+
+func TestMemoryBufferPoisonErrors(t *testing.T) {
+	conf := NewConfig()
+	conf.Type = "memory"
+	conf.Memory.PoisonOutput = "foo"
+
+	_, err := New(conf, poisonMgr{}, log.Noop(), metrics.Noop())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max_attempts")
+
+	conf.Memory.MaxAttempts = 3
+	_, err = New(conf, poisonMgr{}, log.Noop(), metrics.Noop())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "output resource 'foo' was not found")
+}
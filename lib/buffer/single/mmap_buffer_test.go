@@ -406,3 +406,62 @@ func BenchmarkMmapBufferBasic(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkMmapBufferMessageSizes(b *testing.B) {
+	for _, size := range []int{16, 256, 4096, 65536} {
+		size := size
+		b.Run(fmt.Sprintf("%vB", size), func(b *testing.B) {
+			benchmarkMmapBufferMessageSize(b, size)
+		})
+	}
+}
+
+func benchmarkMmapBufferMessageSize(b *testing.B, size int) {
+	dir, err := ioutil.TempDir("", "benthos_test_")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer cleanUpMmapDir(dir)
+
+	conf := NewMmapBufferConfig()
+	conf.FileSize = 16 * 1024 * 1024
+	conf.ReservedDiskSpace = 0
+	conf.Path = dir
+
+	block, err := NewMmapBuffer(conf, log.Noop(), metrics.Noop())
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer block.Close()
+
+	payload := make([]byte, size)
+	rand.Read(payload)
+
+	b.SetBytes(int64(size))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	readErrChan := make(chan error, 1)
+	go func() {
+		for i := 0; i < b.N; i++ {
+			if _, err := block.NextMessage(); err != nil {
+				readErrChan <- err
+				return
+			}
+			if _, err := block.ShiftMessage(); err != nil {
+				readErrChan <- err
+				return
+			}
+		}
+		readErrChan <- nil
+	}()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := block.PushMessage(message.New([][]byte{payload})); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := <-readErrChan; err != nil {
+		b.Fatal(err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
 	"github.com/Jeffail/benthos/v3/lib/message/tracing"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/response"
@@ -178,6 +179,7 @@ func (m *SingleWrapper) outputLoop() {
 		if msg != nil {
 			// It's possible that the buffer wiped our previous root span.
 			tracing.InitSpans("buffer_"+m.conf.Type, msg)
+			message.StampShifted(msg, time.Now())
 
 			select {
 			case m.messagesOut <- types.NewTransaction(msg, m.responsesOut):
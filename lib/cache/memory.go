@@ -11,6 +11,7 @@ import (
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/clock"
 	"github.com/OneOfOne/xxhash"
 )
 
@@ -84,15 +85,19 @@ func NewMemoryConfig() MemoryConfig {
 
 type item struct {
 	value []byte
-	ts    time.Time
+	ts    time.Duration
+
+	// Init values are exempt from TTLs until they're overridden.
+	exempt bool
 }
 
 type shard struct {
 	items map[string]item
 	ttl   time.Duration
+	clock clock.Monotonic
 
 	compInterval   time.Duration
-	lastCompaction time.Time
+	lastCompaction time.Duration
 
 	mKeys        metrics.StatGauge
 	mCompactions metrics.StatCounter
@@ -104,17 +109,18 @@ func (s *shard) isExpired(i item) bool {
 	if s.compInterval == 0 {
 		return false
 	}
-	if i.ts.IsZero() {
+	if i.exempt {
 		return false
 	}
-	return time.Since(i.ts) >= s.ttl
+	return s.clock.Elapsed()-i.ts >= s.ttl
 }
 
 func (s *shard) compaction() {
 	if s.compInterval == 0 {
 		return
 	}
-	if time.Since(s.lastCompaction) < s.compInterval {
+	now := s.clock.Elapsed()
+	if now-s.lastCompaction < s.compInterval {
 		return
 	}
 	s.mCompactions.Incr(1)
@@ -123,15 +129,27 @@ func (s *shard) compaction() {
 			delete(s.items, k)
 		}
 	}
-	s.lastCompaction = time.Now()
+	s.lastCompaction = now
+}
+
+func (s *shard) newItem(value []byte) item {
+	return item{value: value, ts: s.clock.Elapsed()}
 }
 
 //------------------------------------------------------------------------------
 
 // NewMemory creates a new Memory cache type.
 func NewMemory(conf Config, mgr types.Manager, log log.Modular, stats metrics.Type) (types.Cache, error) {
+	m, err := newMemoryV2(conf.Memory, stats, clock.System)
+	if err != nil {
+		return nil, err
+	}
+	return cache.NewV2ToV1Cache(m, stats), nil
+}
+
+func newMemoryV2(conf MemoryConfig, stats metrics.Type, clk clock.Monotonic) (*memoryV2, error) {
 	var interval time.Duration
-	if tout := conf.Memory.CompactionInterval; len(tout) > 0 {
+	if tout := conf.CompactionInterval; len(tout) > 0 {
 		var err error
 		if interval, err = time.ParseDuration(tout); err != nil {
 			return nil, fmt.Errorf("failed to parse compaction interval string: %v", err)
@@ -139,30 +157,32 @@ func NewMemory(conf Config, mgr types.Manager, log log.Modular, stats metrics.Ty
 	}
 
 	m := &memoryV2{}
-	if conf.Memory.Shards <= 0 {
-		return nil, fmt.Errorf("expected >=1 shards, found: %v", conf.Memory.Shards)
+	if conf.Shards <= 0 {
+		return nil, fmt.Errorf("expected >=1 shards, found: %v", conf.Shards)
 	}
-	if conf.Memory.Shards == 1 {
+	if conf.Shards == 1 {
 		m.shards = []*shard{
 			{
 				items: map[string]item{},
-				ttl:   time.Second * time.Duration(conf.Memory.TTL),
+				ttl:   time.Second * time.Duration(conf.TTL),
+				clock: clk,
 
 				compInterval:   interval,
-				lastCompaction: time.Now(),
+				lastCompaction: clk.Elapsed(),
 
 				mKeys:        stats.GetGauge("keys"),
 				mCompactions: stats.GetCounter("compaction"),
 			},
 		}
 	} else {
-		for i := 0; i < conf.Memory.Shards; i++ {
+		for i := 0; i < conf.Shards; i++ {
 			m.shards = append(m.shards, &shard{
 				items: map[string]item{},
-				ttl:   time.Second * time.Duration(conf.Memory.TTL),
+				ttl:   time.Second * time.Duration(conf.TTL),
+				clock: clk,
 
 				compInterval:   interval,
-				lastCompaction: time.Now(),
+				lastCompaction: clk.Elapsed(),
 
 				mKeys:        stats.GetGauge(fmt.Sprintf("shard.%v.keys", i)),
 				mCompactions: stats.GetCounter(fmt.Sprintf("shard.%v.compaction", i)),
@@ -170,14 +190,13 @@ func NewMemory(conf Config, mgr types.Manager, log log.Modular, stats metrics.Ty
 		}
 	}
 
-	for k, v := range conf.Memory.InitValues {
+	for k, v := range conf.InitValues {
 		m.getShard(k).items[k] = item{
-			value: []byte(v),
-			ts:    time.Time{},
+			value:  []byte(v),
+			exempt: true,
 		}
 	}
-
-	return cache.NewV2ToV1Cache(m, stats), nil
+	return m, nil
 }
 
 type memoryV2 struct {
@@ -212,7 +231,7 @@ func (m *memoryV2) Set(_ context.Context, key string, value []byte, _ *time.Dura
 	shard := m.getShard(key)
 	shard.Lock()
 	shard.compaction()
-	shard.items[key] = item{value: value, ts: time.Now()}
+	shard.items[key] = shard.newItem(value)
 	shard.mKeys.Set(int64(len(shard.items)))
 	shard.Unlock()
 	return nil
@@ -226,7 +245,7 @@ func (m *memoryV2) Add(_ context.Context, key string, value []byte, _ *time.Dura
 		return types.ErrKeyAlreadyExists
 	}
 	shard.compaction()
-	shard.items[key] = item{value: value, ts: time.Now()}
+	shard.items[key] = shard.newItem(value)
 	shard.mKeys.Set(int64(len(shard.items)))
 	shard.Unlock()
 	return nil
@@ -292,7 +311,7 @@ func (m *Memory) Set(key string, value []byte) error {
 	shard := m.getShard(key)
 	shard.Lock()
 	shard.compaction()
-	shard.items[key] = item{value: value, ts: time.Now()}
+	shard.items[key] = shard.newItem(value)
 	shard.mKeys.Set(int64(len(shard.items)))
 	shard.Unlock()
 	return nil
@@ -323,7 +342,7 @@ func (m *Memory) Add(key string, value []byte) error {
 		return types.ErrKeyAlreadyExists
 	}
 	shard.compaction()
-	shard.items[key] = item{value: value, ts: time.Now()}
+	shard.items[key] = shard.newItem(value)
 	shard.mKeys.Set(int64(len(shard.items)))
 	shard.Unlock()
 	return nil
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/clock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -147,6 +149,39 @@ func TestMemoryCacheInitValues(t *testing.T) {
 	}
 }
 
+func TestMemoryCacheMonotonicTTL(t *testing.T) {
+	conf := NewMemoryConfig()
+	conf.TTL = 60
+	conf.CompactionInterval = "1m"
+	conf.InitValues = map[string]string{"foo": "bar"}
+
+	clk := clock.NewManual()
+	c, err := newMemoryV2(conf, metrics.Noop(), clk)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, c.Set(ctx, "baz", []byte("buz"), nil))
+
+	// Wall clock steps are invisible to the cache, and so only the time
+	// measured by the monotonic clock counts towards TTLs.
+	clk.Advance(time.Second * 59)
+	act, err := c.Get(ctx, "baz")
+	require.NoError(t, err)
+	assert.Equal(t, "buz", string(act))
+
+	clk.Advance(time.Second)
+	_, err = c.Get(ctx, "baz")
+	assert.Equal(t, types.ErrKeyNotFound, err)
+
+	// This should trigger compaction.
+	require.NoError(t, c.Set(ctx, "qux", []byte("quz"), nil))
+	assert.Len(t, c.shards[0].items, 2)
+
+	act, err = c.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", string(act))
+}
+
 func TestMemoryCacheCompactionOnRead(t *testing.T) {
 	testLog := log.Noop()
 
@@ -2,9 +2,11 @@ package config
 
 import (
 	"github.com/Jeffail/benthos/v3/internal/docs"
+	"github.com/Jeffail/benthos/v3/lib/alert"
 	"github.com/Jeffail/benthos/v3/lib/api"
 	"github.com/Jeffail/benthos/v3/lib/buffer"
 	"github.com/Jeffail/benthos/v3/lib/condition"
+	"github.com/Jeffail/benthos/v3/lib/election"
 	"github.com/Jeffail/benthos/v3/lib/input"
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/manager"
@@ -13,6 +15,7 @@ import (
 	"github.com/Jeffail/benthos/v3/lib/processor"
 	"github.com/Jeffail/benthos/v3/lib/stream"
 	"github.com/Jeffail/benthos/v3/lib/tracer"
+	"github.com/Jeffail/benthos/v3/lib/tuning"
 	"gopkg.in/yaml.v3"
 )
 
@@ -23,11 +26,18 @@ type Type struct {
 	HTTP                   api.Config `json:"http" yaml:"http"`
 	stream.Config          `json:",inline" yaml:",inline"`
 	manager.ResourceConfig `json:",inline" yaml:",inline"`
-	Logger                 log.Config     `json:"logger" yaml:"logger"`
-	Metrics                metrics.Config `json:"metrics" yaml:"metrics"`
-	Tracer                 tracer.Config  `json:"tracer" yaml:"tracer"`
-	SystemCloseTimeout     string         `json:"shutdown_timeout" yaml:"shutdown_timeout"`
-	Tests                  []interface{}  `json:"tests,omitempty" yaml:"tests,omitempty"`
+	Logger                 log.Config            `json:"logger" yaml:"logger"`
+	Metrics                metrics.Config        `json:"metrics" yaml:"metrics"`
+	Tracer                 tracer.Config         `json:"tracer" yaml:"tracer"`
+	Alerts                 alert.Config          `json:"alerts" yaml:"alerts"`
+	Runtime                tuning.Config         `json:"runtime" yaml:"runtime"`
+	SystemCloseTimeout     string                `json:"shutdown_timeout" yaml:"shutdown_timeout"`
+	ShutdownAfterIdle      string                `json:"shutdown_after_idle" yaml:"shutdown_after_idle"`
+	Watchdog               stream.WatchdogConfig `json:"watchdog" yaml:"watchdog"`
+	LeaderElection         election.Config       `json:"leader_election" yaml:"leader_election"`
+	BufferHandoff          stream.HandoffConfig  `json:"buffer_handoff" yaml:"buffer_handoff"`
+	Audit                  stream.AuditConfig    `json:"audit" yaml:"audit"`
+	Tests                  []interface{}         `json:"tests,omitempty" yaml:"tests,omitempty"`
 }
 
 // New returns a new configuration with default values.
@@ -39,7 +49,14 @@ func New() Type {
 		Logger:             log.NewConfig(),
 		Metrics:            metrics.NewConfig(),
 		Tracer:             tracer.NewConfig(),
+		Alerts:             alert.NewConfig(),
+		Runtime:            tuning.NewConfig(),
 		SystemCloseTimeout: "20s",
+		ShutdownAfterIdle:  "",
+		Watchdog:           stream.NewWatchdogConfig(),
+		LeaderElection:     election.NewConfig(),
+		BufferHandoff:      stream.NewHandoffConfig(),
+		Audit:              stream.NewAuditConfig(),
 		Tests:              nil,
 	}
 }
@@ -57,7 +74,14 @@ type SanitisedConfig struct {
 	Logger             interface{} `json:"logger" yaml:"logger"`
 	Metrics            interface{} `json:"metrics" yaml:"metrics"`
 	Tracer             interface{} `json:"tracer" yaml:"tracer"`
+	Alerts             interface{} `json:"alerts" yaml:"alerts"`
+	Runtime            interface{} `json:"runtime" yaml:"runtime"`
 	SystemCloseTimeout interface{} `json:"shutdown_timeout" yaml:"shutdown_timeout"`
+	ShutdownAfterIdle  interface{} `json:"shutdown_after_idle" yaml:"shutdown_after_idle"`
+	Watchdog           interface{} `json:"watchdog" yaml:"watchdog"`
+	LeaderElection     interface{} `json:"leader_election" yaml:"leader_election"`
+	BufferHandoff      interface{} `json:"buffer_handoff" yaml:"buffer_handoff"`
+	Audit              interface{} `json:"audit" yaml:"audit"`
 	Tests              interface{} `json:"tests,omitempty" yaml:"tests,omitempty"`
 }
 
@@ -157,7 +181,14 @@ func (c Type) sanitised(skipDeprecated bool) (*SanitisedConfig, error) {
 		Logger:             logConf,
 		Metrics:            metConf,
 		Tracer:             tracConf,
+		Alerts:             c.Alerts,
+		Runtime:            c.Runtime,
 		SystemCloseTimeout: c.SystemCloseTimeout,
+		ShutdownAfterIdle:  c.ShutdownAfterIdle,
+		Watchdog:           c.Watchdog,
+		LeaderElection:     c.LeaderElection,
+		BufferHandoff:      c.BufferHandoff,
+		Audit:              c.Audit,
 		Tests:              c.Tests,
 	}, nil
 }
@@ -2,10 +2,13 @@ package config
 
 import (
 	"github.com/Jeffail/benthos/v3/internal/docs"
+	"github.com/Jeffail/benthos/v3/lib/alert"
 	"github.com/Jeffail/benthos/v3/lib/api"
+	"github.com/Jeffail/benthos/v3/lib/election"
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/manager"
 	"github.com/Jeffail/benthos/v3/lib/stream"
+	"github.com/Jeffail/benthos/v3/lib/tuning"
 )
 
 // Spec returns a docs.FieldSpec for an entire Benthos configuration.
@@ -19,7 +22,14 @@ func Spec() docs.FieldSpecs {
 		docs.FieldCommon("logger", "Describes how operational logs should be emitted.").WithChildren(log.Spec()...),
 		docs.FieldCommon("metrics", "A mechanism for exporting metrics.").HasType(docs.FieldTypeMetrics),
 		docs.FieldCommon("tracer", "A mechanism for exporting traces.").HasType(docs.FieldTypeTracer),
+		docs.FieldAdvanced("alerts", "Emits alerts when the error rate or buffer backlog of the service exceeds a threshold for a sustained period.").WithChildren(alert.Spec()...).AtVersion("3.54.0"),
+		docs.FieldAdvanced("runtime", "Tuning options for the Go runtime that are applied at startup, useful for containerized deployments with CPU or memory limits.").WithChildren(tuning.Spec()...).AtVersion("3.54.0"),
 		docs.FieldString("shutdown_timeout", "The maximum period of time to wait for a clean shutdown. If this time is exceeded Benthos will forcefully close.").HasDefault("20s"),
+		docs.FieldString("shutdown_after_idle", "An optional period of time after which Benthos shuts down cleanly if the pipeline has been idle, meaning no messages have been consumed or delivered and none remain within a buffer. This is useful for batch jobs that should exit once their input has been exhausted. This field is ignored in streams mode.", "30s", "5m").HasDefault("").Advanced().AtVersion("3.54.0"),
+		docs.FieldAdvanced("watchdog", "Detects when messages stop flowing through the pipeline despite remaining in flight or within a buffer, which usually indicates a deadlock or a stuck component, and logs diagnostics or shuts down the service. This field is ignored in streams mode.").WithChildren(stream.WatchdogSpec()...).AtVersion("3.54.0"),
+		docs.FieldAdvanced("leader_election", "Elects a leader between instances that share a config, allowing them to run in active/standby mode. The input of each instance is only created once it acquires leadership, and is closed again if leadership is lost, which happens as soon as the lock of the leader fails to be renewed, so that a standby instance does not consume from or hold resources of its source, such as consumer group memberships or listening ports. Messages already consumed by an instance that loses leadership are processed and delivered before its input is closed. This field is ignored in streams mode.").WithChildren(election.Spec()...).AtVersion("3.54.0"),
+		docs.FieldAdvanced("buffer_handoff", "Hands off the backlog of the buffer to a peer instance, either on demand or each time the service shuts down, so that scaling down does not leave data stranded within the buffer of a removed instance. Messages are sent to the peer before reaching the pipeline, and are therefore processed by the peer. This field is ignored in streams mode.").WithChildren(stream.HandoffSpec()...).AtVersion("3.54.0"),
+		docs.FieldAdvanced("audit", "Records a one line summary of each message sent to the output, including its size, a SHA-256 hash of its contents, the input it was read from, the outputs it was sent to and the outcome of the delivery, in order to prove that messages were delivered. Records are written before the output acknowledges a message upstream, to a rotated file as JSON lines and/or an output resource. This field is ignored in streams mode.").WithChildren(stream.AuditSpec()...).AtVersion("3.54.0"),
 		docs.FieldCommon("tests", "Optional unit tests for the config, to be run with the `benthos test` subcommand.").Array().HasType(docs.FieldTypeUnknown).HasDefault([]interface{}{}),
 	}...)
 
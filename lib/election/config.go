@@ -0,0 +1,48 @@
+package election
+
+import (
+	btls "github.com/Jeffail/benthos/v3/lib/util/tls"
+)
+
+// Leader election types.
+const (
+	TypeNone       = "none"
+	TypeConsul     = "consul"
+	TypeEtcd       = "etcd"
+	TypeKubernetes = "kubernetes"
+)
+
+// Config contains configuration fields for leader election.
+type Config struct {
+	Type      string      `json:"type" yaml:"type"`
+	URL       string      `json:"url" yaml:"url"`
+	Key       string      `json:"key" yaml:"key"`
+	Namespace string      `json:"namespace" yaml:"namespace"`
+	Identity  string      `json:"identity" yaml:"identity"`
+	TTL       string      `json:"ttl" yaml:"ttl"`
+	TLS       btls.Config `json:"tls" yaml:"tls"`
+	Token     string      `json:"token" yaml:"token"`
+	Username  string      `json:"username" yaml:"username"`
+	Password  string      `json:"password" yaml:"password"`
+}
+
+// NewConfig creates a new Config with default values.
+func NewConfig() Config {
+	return Config{
+		Type:      TypeNone,
+		URL:       "",
+		Key:       "",
+		Namespace: "",
+		Identity:  "",
+		TTL:       "15s",
+		TLS:       btls.NewConfig(),
+		Token:     "",
+		Username:  "",
+		Password:  "",
+	}
+}
+
+// Enabled returns true if a leader election type has been configured.
+func (c Config) Enabled() bool {
+	return c.Type != "" && c.Type != TypeNone
+}
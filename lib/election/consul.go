@@ -0,0 +1,110 @@
+package election
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// consulLock is a lock on a Consul key that is acquired with a session, where
+// the session expires unless it is renewed within the ttl.
+type consulLock struct {
+	baseURL  string
+	key      string
+	identity string
+	ttl      time.Duration
+	token    string
+	client   *http.Client
+
+	session string
+}
+
+func newConsulLock(conf Config, identity string, ttl time.Duration) (*consulLock, error) {
+	baseURL := conf.URL
+	if baseURL == "" {
+		if baseURL = os.Getenv("CONSUL_HTTP_ADDR"); baseURL == "" {
+			baseURL = "127.0.0.1:8500"
+		}
+	}
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+	client, err := newHTTPClient(conf)
+	if err != nil {
+		return nil, err
+	}
+	token := conf.Token
+	if token == "" {
+		token = os.Getenv("CONSUL_HTTP_TOKEN")
+	}
+	return &consulLock{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		key:      strings.Trim(conf.Key, "/"),
+		identity: identity,
+		ttl:      ttl,
+		token:    token,
+		client:   client,
+	}, nil
+}
+
+func (c *consulLock) do(ctx context.Context, method, path string, reqBody, resBody interface{}) (int, error) {
+	req, err := newRequest(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return 0, err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+	return doJSON(c.client, req, resBody)
+}
+
+// renewSession renews the current session, or creates a new session when there
+// isn't one or it has expired.
+func (c *consulLock) renewSession(ctx context.Context) error {
+	if c.session != "" {
+		status, err := c.do(ctx, http.MethodPut, "/v1/session/renew/"+c.session, nil, nil)
+		if status != http.StatusNotFound {
+			return err
+		}
+		c.session = ""
+	}
+
+	var resBody struct {
+		ID string
+	}
+	if _, err := c.do(ctx, http.MethodPut, "/v1/session/create", map[string]interface{}{
+		"Name":      "benthos-" + c.identity,
+		"TTL":       c.ttl.String(),
+		"Behavior":  "release",
+		"LockDelay": "0s",
+	}, &resBody); err != nil {
+		return err
+	}
+	c.session = resBody.ID
+	return nil
+}
+
+func (c *consulLock) acquire(ctx context.Context) (bool, error) {
+	if err := c.renewSession(ctx); err != nil {
+		return false, err
+	}
+
+	// Acquiring a key that the session already holds succeeds.
+	var acquired bool
+	if _, err := c.do(ctx, http.MethodPut, "/v1/kv/"+c.key+"?acquire="+url.QueryEscape(c.session), []byte(c.identity), &acquired); err != nil {
+		return false, err
+	}
+	return acquired, nil
+}
+
+func (c *consulLock) release(ctx context.Context) error {
+	if c.session == "" {
+		return nil
+	}
+	_, err := c.do(ctx, http.MethodPut, "/v1/session/destroy/"+c.session, nil, nil)
+	c.session = ""
+	return err
+}
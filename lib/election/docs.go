@@ -0,0 +1,28 @@
+package election
+
+import (
+	"github.com/Jeffail/benthos/v3/internal/docs"
+	btls "github.com/Jeffail/benthos/v3/lib/util/tls"
+)
+
+// Spec returns a field spec for the leader election configuration fields.
+func Spec() docs.FieldSpecs {
+	return docs.FieldSpecs{
+		docs.FieldString("type", "The system used to elect a leader, where `none` disables leader election.").HasOptions(TypeNone, TypeConsul, TypeEtcd, TypeKubernetes).HasDefault(TypeNone),
+		docs.FieldString(
+			"url", "The URL of the API of the election system. When empty Consul defaults to the environment variable `CONSUL_HTTP_ADDR` or `http://127.0.0.1:8500`, etcd defaults to `http://127.0.0.1:2379`, and Kubernetes defaults to the API server of the cluster the instance is running within.",
+			"http://consul.example.com:8500", "http://127.0.0.1:8001",
+		).HasDefault(""),
+		docs.FieldString(
+			"key", "The key that is locked by the leader, which must be the same for all instances that share a config. For Consul this is a key within the KV store, for etcd it is a key, and for Kubernetes it is the name of a `Lease` object.",
+			"service/benthos/leader", "benthos-leader",
+		).HasDefault(""),
+		docs.FieldString("namespace", "The Kubernetes namespace of the `Lease` object. When empty the namespace of the instance is used.").HasDefault("").Advanced(),
+		docs.FieldString("identity", "A unique identity of this instance, which is stored with the lock. When empty the hostname is used.").HasDefault("").Advanced(),
+		docs.FieldString("ttl", "The period after which the lock of a leader that has stopped renewing it expires, allowing a standby to take over. The lock is renewed three times within each period. Consul requires a period of at least `10s`.").HasDefault("15s").Advanced(),
+		btls.FieldSpec(),
+		docs.FieldString("token", "A token to authenticate with. For Consul this is an ACL token, which defaults to the environment variable `CONSUL_HTTP_TOKEN`, and for Kubernetes it is a bearer token, which defaults to the token of the service account of the instance. This field is ignored by etcd, which authenticates with a `username` and `password` instead.").HasDefault("").Advanced(),
+		docs.FieldString("username", "A username to authenticate with etcd. When empty etcd is accessed without authentication.").HasDefault("").Advanced(),
+		docs.FieldString("password", "The password of the etcd user.").HasDefault("").Advanced(),
+	}
+}
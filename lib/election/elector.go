@@ -0,0 +1,226 @@
+package election
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+)
+
+// lock is a lock with an expiry that is held by the leader.
+type lock interface {
+	// acquire attempts to acquire the lock, or to renew it when it is already
+	// held, and returns whether it is held.
+	acquire(ctx context.Context) (bool, error)
+
+	// release releases the lock when it is held.
+	release(ctx context.Context) error
+}
+
+//------------------------------------------------------------------------------
+
+// Elector campaigns for leadership on behalf of an instance, renewing the lock
+// of the leader periodically and reporting each change of leadership.
+type Elector struct {
+	lock     lock
+	interval time.Duration
+	log      log.Modular
+
+	leading bool
+
+	startOnce  sync.Once
+	closeOnce  sync.Once
+	closeChan  chan struct{}
+	closedChan chan struct{}
+}
+
+// NewElector creates a new Elector. The elector does not begin campaigning
+// until Start is called.
+func NewElector(conf Config, log log.Modular) (*Elector, error) {
+	ttl, err := time.ParseDuration(conf.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ttl: %v", err)
+	}
+	if ttl < time.Second {
+		return nil, errors.New("ttl must be at least one second")
+	}
+	if conf.Key == "" {
+		return nil, errors.New("a key must be specified")
+	}
+
+	identity := conf.Identity
+	if identity == "" {
+		if identity, err = os.Hostname(); err != nil {
+			return nil, fmt.Errorf("failed to obtain hostname for identity: %v", err)
+		}
+	}
+
+	var l lock
+	switch conf.Type {
+	case TypeConsul:
+		l, err = newConsulLock(conf, identity, ttl)
+	case TypeEtcd:
+		l, err = newEtcdLock(conf, identity, ttl)
+	case TypeKubernetes:
+		l, err = newKubernetesLock(conf, identity, ttl)
+	default:
+		err = fmt.Errorf("leader election type not recognised: %v", conf.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Campaigning for leadership as '%v'\n", identity)
+	return newElector(l, ttl/3, log), nil
+}
+
+func newElector(l lock, interval time.Duration, log log.Modular) *Elector {
+	return &Elector{
+		lock:       l,
+		interval:   interval,
+		log:        log,
+		closeChan:  make(chan struct{}),
+		closedChan: make(chan struct{}),
+	}
+}
+
+// Start begins campaigning for leadership, calling onChange each time this
+// instance becomes or stops being the leader. Leadership is given up as soon as
+// the lock fails to be renewed, which happens before it expires and can
+// therefore be acquired by another instance.
+func (e *Elector) Start(onChange func(leading bool)) {
+	e.startOnce.Do(func() {
+		go e.loop(onChange)
+	})
+}
+
+func (e *Elector) campaign(onChange func(leading bool)) {
+	ctx, done := context.WithTimeout(context.Background(), e.interval)
+	defer done()
+
+	leading, err := e.lock.acquire(ctx)
+	if err != nil {
+		e.log.Warnf("Failed to acquire leadership lock: %v\n", err)
+	}
+	if leading == e.leading {
+		return
+	}
+	e.leading = leading
+	if leading {
+		e.log.Infoln("Acquired leadership, resuming input.")
+	} else {
+		e.log.Warnln("Lost leadership, pausing input.")
+	}
+	onChange(leading)
+}
+
+func (e *Elector) loop(onChange func(leading bool)) {
+	defer close(e.closedChan)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		e.campaign(onChange)
+		select {
+		case <-ticker.C:
+		case <-e.closeChan:
+			if e.leading {
+				ctx, done := context.WithTimeout(context.Background(), e.interval)
+				if err := e.lock.release(ctx); err != nil {
+					e.log.Warnf("Failed to release leadership lock: %v\n", err)
+				}
+				done()
+			}
+			return
+		}
+	}
+}
+
+// Close stops campaigning and releases the lock when it is held, allowing a
+// standby to take over without waiting for the lock to expire.
+func (e *Elector) Close() error {
+	e.closeOnce.Do(func() {
+		close(e.closeChan)
+	})
+	select {
+	case <-e.closedChan:
+	case <-time.After(e.interval * 2):
+		return errors.New("timed out waiting for leadership lock to be released")
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// doJSON executes a request and decodes a JSON response body into resBody,
+// which can be nil. The status code is returned along with an error when the
+// status is not a success.
+func doJSON(client *http.Client, req *http.Request, resBody interface{}) (int, error) {
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return res.StatusCode, err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status %v: %s", res.StatusCode, bytes.TrimSpace(body))
+	}
+	if resBody == nil {
+		return res.StatusCode, nil
+	}
+	return res.StatusCode, json.Unmarshal(body, resBody)
+}
+
+// newRequest creates a request with a body, which is marshalled as JSON unless
+// it is already a []byte.
+func newRequest(ctx context.Context, method, url string, reqBody interface{}) (*http.Request, error) {
+	var body []byte
+	switch t := reqBody.(type) {
+	case nil:
+	case []byte:
+		body = t
+	default:
+		var err error
+		if body, err = json.Marshal(t); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if _, isRaw := reqBody.([]byte); reqBody != nil && !isRaw {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// newHTTPClient creates a client for the API of the election system, which uses
+// the configured TLS settings when enabled.
+func newHTTPClient(conf Config) (*http.Client, error) {
+	client := &http.Client{}
+	if conf.TLS.Enabled {
+		tlsConf, err := conf.TLS.Get()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create tls config: %v", err)
+		}
+		client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConf,
+		}
+	}
+	return client, nil
+}
@@ -0,0 +1,508 @@
+package election
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLock struct {
+	mut      sync.Mutex
+	held     bool
+	err      error
+	released bool
+}
+
+func (f *fakeLock) acquire(ctx context.Context) (bool, error) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if f.err != nil {
+		return false, f.err
+	}
+	return f.held, nil
+}
+
+func (f *fakeLock) release(ctx context.Context) error {
+	f.mut.Lock()
+	f.released = true
+	f.mut.Unlock()
+	return nil
+}
+
+func TestElectorChanges(t *testing.T) {
+	lock := &fakeLock{}
+	e := newElector(lock, time.Millisecond, log.Noop())
+
+	changes := make(chan bool)
+	e.Start(func(leading bool) {
+		changes <- leading
+	})
+
+	nextChange := func() bool {
+		t.Helper()
+		select {
+		case leading := <-changes:
+			return leading
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+		return false
+	}
+
+	lock.mut.Lock()
+	lock.held = true
+	lock.mut.Unlock()
+	assert.True(t, nextChange())
+
+	// Leadership is given up as soon as the lock can't be renewed.
+	lock.mut.Lock()
+	lock.err = errors.New("nope")
+	lock.mut.Unlock()
+	assert.False(t, nextChange())
+
+	lock.mut.Lock()
+	lock.err = nil
+	lock.mut.Unlock()
+	assert.True(t, nextChange())
+
+	require.NoError(t, e.Close())
+	lock.mut.Lock()
+	assert.True(t, lock.released)
+	lock.mut.Unlock()
+}
+
+func TestElectorConfigErrors(t *testing.T) {
+	conf := NewConfig()
+	conf.Type = "nope"
+	conf.Key = "foo"
+	_, err := NewElector(conf, log.Noop())
+	assert.EqualError(t, err, "leader election type not recognised: nope")
+
+	conf.Type = TypeEtcd
+	conf.Key = ""
+	_, err = NewElector(conf, log.Noop())
+	assert.EqualError(t, err, "a key must be specified")
+}
+
+func TestConsulLock(t *testing.T) {
+	var mut sync.Mutex
+	sessions := map[string]bool{}
+	var created int
+	var holder string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mut.Lock()
+		defer mut.Unlock()
+
+		assert.Equal(t, http.MethodPut, r.Method)
+		switch {
+		case r.URL.Path == "/v1/session/create":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "15s", body["TTL"])
+			created++
+			id := "session" + string(rune('0'+created))
+			sessions[id] = true
+			_, _ = w.Write([]byte(`{"ID":"` + id + `"}`))
+		case strings.HasPrefix(r.URL.Path, "/v1/session/renew/"):
+			if !sessions[strings.TrimPrefix(r.URL.Path, "/v1/session/renew/")] {
+				http.Error(w, "session not found", http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`[]`))
+		case strings.HasPrefix(r.URL.Path, "/v1/session/destroy/"):
+			id := strings.TrimPrefix(r.URL.Path, "/v1/session/destroy/")
+			delete(sessions, id)
+			if holder == id {
+				holder = ""
+			}
+			_, _ = w.Write([]byte(`true`))
+		case r.URL.Path == "/v1/kv/benthos/leader":
+			id := r.URL.Query().Get("acquire")
+			value, _ := ioutil.ReadAll(r.Body)
+			assert.Equal(t, "foo", string(value))
+			if holder == "" || holder == id {
+				holder = id
+				_, _ = w.Write([]byte(`true`))
+			} else {
+				_, _ = w.Write([]byte(`false`))
+			}
+		default:
+			t.Errorf("unexpected path: %v", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	conf := NewConfig()
+	conf.URL = ts.URL
+	conf.Key = "/benthos/leader"
+
+	a, err := newConsulLock(conf, "foo", time.Second*15)
+	require.NoError(t, err)
+	b, err := newConsulLock(conf, "foo", time.Second*15)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		held, err := a.acquire(ctx)
+		require.NoError(t, err)
+		assert.True(t, held)
+
+		held, err = b.acquire(ctx)
+		require.NoError(t, err)
+		assert.False(t, held)
+	}
+
+	// An expired session is replaced.
+	mut.Lock()
+	delete(sessions, a.session)
+	holder = ""
+	mut.Unlock()
+
+	held, err := b.acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	held, err = a.acquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	require.NoError(t, b.release(ctx))
+	held, err = a.acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+}
+
+func TestEtcdLock(t *testing.T) {
+	var mut sync.Mutex
+	leases := map[string]bool{}
+	var granted int
+	var holderLease string
+
+	b64 := base64.StdEncoding.EncodeToString
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mut.Lock()
+		defer mut.Unlock()
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch r.URL.Path {
+		case "/v3/lease/grant":
+			assert.Equal(t, "15", body["TTL"])
+			granted++
+			id := string(rune('0' + granted))
+			leases[id] = true
+			_, _ = w.Write([]byte(`{"ID":"` + id + `","TTL":"15"}`))
+		case "/v3/lease/keepalive":
+			if leases[body["ID"].(string)] {
+				_, _ = w.Write([]byte(`{"result":{"ID":"` + body["ID"].(string) + `","TTL":"15"}}`))
+			} else {
+				_, _ = w.Write([]byte(`{"result":{"ID":"` + body["ID"].(string) + `"}}`))
+			}
+		case "/v3/lease/revoke":
+			delete(leases, body["ID"].(string))
+			if holderLease == body["ID"].(string) {
+				holderLease = ""
+			}
+			_, _ = w.Write([]byte(`{}`))
+		case "/v3/kv/txn":
+			put := body["success"].([]interface{})[0].(map[string]interface{})["request_put"].(map[string]interface{})
+			assert.Equal(t, b64([]byte("benthos-leader")), put["key"])
+			assert.Equal(t, b64([]byte("foo")), put["value"])
+			if holderLease == "" {
+				holderLease = put["lease"].(string)
+				_, _ = w.Write([]byte(`{"succeeded":true}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"responses":[{"response_range":{"kvs":[{"lease":"` + holderLease + `"}]}}]}`))
+		default:
+			t.Errorf("unexpected path: %v", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	conf := NewConfig()
+	conf.URL = ts.URL
+	conf.Key = "benthos-leader"
+
+	a, err := newEtcdLock(conf, "foo", time.Second*15)
+	require.NoError(t, err)
+	b, err := newEtcdLock(conf, "foo", time.Second*15)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		held, err := a.acquire(ctx)
+		require.NoError(t, err)
+		assert.True(t, held)
+
+		held, err = b.acquire(ctx)
+		require.NoError(t, err)
+		assert.False(t, held)
+	}
+
+	require.NoError(t, a.release(ctx))
+	held, err := b.acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	// An expired lease is replaced, but the key was deleted with it.
+	mut.Lock()
+	delete(leases, b.lease)
+	holderLease = ""
+	mut.Unlock()
+
+	held, err = a.acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	held, err = b.acquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, held)
+}
+
+func TestKubernetesLock(t *testing.T) {
+	var mut sync.Mutex
+	var lease *kubernetesLease
+	version := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mut.Lock()
+		defer mut.Unlock()
+
+		const base = "/apis/coordination.k8s.io/v1/namespaces/bar/leases"
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == base+"/benthos-leader":
+			if lease == nil {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(lease))
+		case r.Method == http.MethodPost && r.URL.Path == base:
+			if lease != nil {
+				http.Error(w, "exists", http.StatusConflict)
+				return
+			}
+			var l kubernetesLease
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&l))
+			assert.Equal(t, "Lease", l.Kind)
+			version++
+			l.Metadata.ResourceVersion = string(rune('0' + version))
+			lease = &l
+			_, _ = w.Write([]byte(`{}`))
+		case r.Method == http.MethodPut && r.URL.Path == base+"/benthos-leader":
+			var l kubernetesLease
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&l))
+			if l.Metadata.ResourceVersion != lease.Metadata.ResourceVersion {
+				http.Error(w, "conflict", http.StatusConflict)
+				return
+			}
+			version++
+			l.Metadata.ResourceVersion = string(rune('0' + version))
+			lease = &l
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	conf := NewConfig()
+	conf.URL = ts.URL
+	conf.Key = "benthos-leader"
+	conf.Namespace = "bar"
+
+	now := time.Now()
+	clock := func() time.Time { return now }
+
+	a, err := newKubernetesLock(conf, "a", time.Second*15)
+	require.NoError(t, err)
+	a.now = clock
+	b, err := newKubernetesLock(conf, "b", time.Second*15)
+	require.NoError(t, err)
+	b.now = clock
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		held, err := a.acquire(ctx)
+		require.NoError(t, err)
+		assert.True(t, held)
+
+		held, err = b.acquire(ctx)
+		require.NoError(t, err)
+		assert.False(t, held)
+	}
+
+	// Once the lease expires it can be taken over.
+	now = now.Add(time.Second * 15)
+	held, err := b.acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+	assert.Equal(t, 1, *lease.Spec.LeaseTransitions)
+
+	held, err = a.acquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	require.NoError(t, b.release(ctx))
+	held, err = a.acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+	assert.Equal(t, 2, *lease.Spec.LeaseTransitions)
+}
+
+func TestConsulLockTLSAndToken(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Consul-Token") != "secret" {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/session/create":
+			_, _ = w.Write([]byte(`{"ID":"session"}`))
+		default:
+			_, _ = w.Write([]byte(`true`))
+		}
+	}))
+	defer ts.Close()
+
+	conf := NewConfig()
+	conf.URL = ts.URL
+	conf.Key = "benthos/leader"
+	conf.Token = "secret"
+	conf.TLS.Enabled = true
+	conf.TLS.RootCAs = string(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: ts.Certificate().Raw,
+	}))
+
+	l, err := newConsulLock(conf, "foo", time.Second*15)
+	require.NoError(t, err)
+
+	held, err := l.acquire(context.Background())
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	// Without the TLS settings the certificate of the server isn't trusted.
+	conf.TLS.Enabled = false
+	l, err = newConsulLock(conf, "foo", time.Second*15)
+	require.NoError(t, err)
+
+	_, err = l.acquire(context.Background())
+	require.Error(t, err)
+}
+
+func TestEtcdLockAuth(t *testing.T) {
+	var mut sync.Mutex
+	var authenticated int
+	validToken := ""
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mut.Lock()
+		defer mut.Unlock()
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		if r.URL.Path == "/v3/auth/authenticate" {
+			if body["name"] != "foo" || body["password"] != "bar" {
+				http.Error(w, `{"error":"authentication failed"}`, http.StatusBadRequest)
+				return
+			}
+			authenticated++
+			validToken = "token" + string(rune('0'+authenticated))
+			_, _ = w.Write([]byte(`{"token":"` + validToken + `"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != validToken {
+			http.Error(w, `{"error":"invalid auth token"}`, http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v3/lease/grant":
+			_, _ = w.Write([]byte(`{"ID":"1","TTL":"15"}`))
+		case "/v3/lease/keepalive":
+			_, _ = w.Write([]byte(`{"result":{"ID":"1","TTL":"15"}}`))
+		case "/v3/kv/txn":
+			_, _ = w.Write([]byte(`{"succeeded":true}`))
+		default:
+			t.Errorf("unexpected path: %v", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	conf := NewConfig()
+	conf.URL = ts.URL
+	conf.Key = "benthos-leader"
+	conf.Username = "foo"
+	conf.Password = "bar"
+
+	l, err := newEtcdLock(conf, "foo", time.Second*15)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	held, err := l.acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+	assert.Equal(t, 1, authenticated)
+
+	// An expired token is replaced.
+	mut.Lock()
+	validToken = ""
+	mut.Unlock()
+
+	held, err = l.acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+	assert.Equal(t, 2, authenticated)
+
+	conf.Password = "baz"
+	l, err = newEtcdLock(conf, "foo", time.Second*15)
+	require.NoError(t, err)
+
+	_, err = l.acquire(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to authenticate")
+}
+
+func TestKubernetesLockToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodGet {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	conf := NewConfig()
+	conf.URL = ts.URL
+	conf.Key = "benthos-leader"
+	conf.Namespace = "bar"
+	conf.Token = "secret"
+
+	l, err := newKubernetesLock(conf, "a", time.Second*15)
+	require.NoError(t, err)
+
+	held, err := l.acquire(context.Background())
+	require.NoError(t, err)
+	assert.True(t, held)
+}
@@ -0,0 +1,182 @@
+package election
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// etcdLock is an etcd key that is created with a lease, where the lease and
+// therefore the key expire unless the lease is kept alive within the ttl.
+type etcdLock struct {
+	baseURL  string
+	key      string
+	identity string
+	ttl      time.Duration
+	username string
+	password string
+	client   *http.Client
+
+	token string
+	lease string
+}
+
+func newEtcdLock(conf Config, identity string, ttl time.Duration) (*etcdLock, error) {
+	baseURL := conf.URL
+	if baseURL == "" {
+		baseURL = "http://127.0.0.1:2379"
+	}
+	client, err := newHTTPClient(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &etcdLock{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		key:      base64.StdEncoding.EncodeToString([]byte(conf.Key)),
+		identity: base64.StdEncoding.EncodeToString([]byte(identity)),
+		ttl:      ttl,
+		username: conf.Username,
+		password: conf.Password,
+		client:   client,
+	}, nil
+}
+
+// authenticate obtains a token for the configured user, which is sent with
+// each request until it is rejected.
+func (e *etcdLock) authenticate(ctx context.Context) error {
+	req, err := newRequest(ctx, http.MethodPost, e.baseURL+"/v3/auth/authenticate", map[string]string{
+		"name":     e.username,
+		"password": e.password,
+	})
+	if err != nil {
+		return err
+	}
+	var resBody struct {
+		Token string `json:"token"`
+	}
+	if _, err = doJSON(e.client, req, &resBody); err != nil {
+		return fmt.Errorf("failed to authenticate: %v", err)
+	}
+	e.token = resBody.Token
+	return nil
+}
+
+func (e *etcdLock) do(ctx context.Context, path string, reqBody, resBody interface{}) error {
+	for attempt := 0; ; attempt++ {
+		if e.username != "" && e.token == "" {
+			if err := e.authenticate(ctx); err != nil {
+				return err
+			}
+		}
+		req, err := newRequest(ctx, http.MethodPost, e.baseURL+path, reqBody)
+		if err != nil {
+			return err
+		}
+		if e.token != "" {
+			req.Header.Set("Authorization", e.token)
+		}
+		status, err := doJSON(e.client, req, resBody)
+		if status == http.StatusUnauthorized && e.username != "" && attempt == 0 {
+			// Tokens expire, in which case a new one is obtained.
+			e.token = ""
+			continue
+		}
+		return err
+	}
+}
+
+// renewLease keeps the current lease alive, or grants a new lease when there
+// isn't one or it has expired. The JSON gateway of etcd encodes 64-bit integers
+// as strings.
+func (e *etcdLock) renewLease(ctx context.Context) error {
+	if e.lease != "" {
+		var resBody struct {
+			Result struct {
+				TTL string `json:"TTL"`
+			} `json:"result"`
+		}
+		if err := e.do(ctx, "/v3/lease/keepalive", map[string]string{"ID": e.lease}, &resBody); err != nil {
+			return err
+		}
+		if ttl, _ := strconv.ParseInt(resBody.Result.TTL, 10, 64); ttl > 0 {
+			return nil
+		}
+		e.lease = ""
+	}
+
+	var resBody struct {
+		ID string `json:"ID"`
+	}
+	if err := e.do(ctx, "/v3/lease/grant", map[string]string{
+		"TTL": strconv.Itoa(int(e.ttl.Seconds())),
+	}, &resBody); err != nil {
+		return err
+	}
+	e.lease = resBody.ID
+	return nil
+}
+
+func (e *etcdLock) acquire(ctx context.Context) (bool, error) {
+	if err := e.renewLease(ctx); err != nil {
+		return false, err
+	}
+
+	// Create the key only if it does not exist, otherwise read it in order to
+	// find out whether it belongs to our lease.
+	var resBody struct {
+		Succeeded bool `json:"succeeded"`
+		Responses []struct {
+			ResponseRange struct {
+				KVs []struct {
+					Lease string `json:"lease"`
+				} `json:"kvs"`
+			} `json:"response_range"`
+		} `json:"responses"`
+	}
+	if err := e.do(ctx, "/v3/kv/txn", map[string]interface{}{
+		"compare": []interface{}{map[string]string{
+			"key":             e.key,
+			"target":          "CREATE",
+			"result":          "EQUAL",
+			"create_revision": "0",
+		}},
+		"success": []interface{}{map[string]interface{}{
+			"request_put": map[string]string{
+				"key":   e.key,
+				"value": e.identity,
+				"lease": e.lease,
+			},
+		}},
+		"failure": []interface{}{map[string]interface{}{
+			"request_range": map[string]string{
+				"key": e.key,
+			},
+		}},
+	}, &resBody); err != nil {
+		return false, err
+	}
+	if resBody.Succeeded {
+		return true, nil
+	}
+	for _, res := range resBody.Responses {
+		for _, kv := range res.ResponseRange.KVs {
+			if kv.Lease == e.lease {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (e *etcdLock) release(ctx context.Context) error {
+	if e.lease == "" {
+		return nil
+	}
+	err := e.do(ctx, "/v3/lease/revoke", map[string]string{"ID": e.lease}, nil)
+	e.lease = ""
+	return err
+}
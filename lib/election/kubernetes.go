@@ -0,0 +1,212 @@
+package election
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	kubernetesServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+	// kubernetesMicroTime is the format of timestamps within Lease objects.
+	kubernetesMicroTime = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+type kubernetesLeaseSpec struct {
+	HolderIdentity       *string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds *int    `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          *string `json:"acquireTime,omitempty"`
+	RenewTime            *string `json:"renewTime,omitempty"`
+	LeaseTransitions     *int    `json:"leaseTransitions,omitempty"`
+}
+
+type kubernetesLease struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name            string `json:"name"`
+		Namespace       string `json:"namespace,omitempty"`
+		ResourceVersion string `json:"resourceVersion,omitempty"`
+	} `json:"metadata"`
+	Spec kubernetesLeaseSpec `json:"spec"`
+}
+
+// kubernetesLock is a Kubernetes Lease object, which is held by the instance
+// named by its holder identity until its renew time is older than its duration.
+// Updates use the resource version of the object in order to detect conflicts.
+type kubernetesLock struct {
+	leaseURL  string
+	name      string
+	namespace string
+	identity  string
+	ttl       time.Duration
+	token     string
+	tokenPath string
+	client    *http.Client
+
+	now func() time.Time
+}
+
+func newKubernetesLock(conf Config, identity string, ttl time.Duration) (*kubernetesLock, error) {
+	client, err := newHTTPClient(conf)
+	if err != nil {
+		return nil, err
+	}
+	k := &kubernetesLock{
+		name:      conf.Key,
+		namespace: conf.Namespace,
+		identity:  identity,
+		ttl:       ttl,
+		token:     conf.Token,
+		client:    client,
+		now:       time.Now,
+	}
+
+	baseURL := conf.URL
+	if baseURL == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, errors.New("a url must be specified when not running within a Kubernetes cluster")
+		}
+		baseURL = "https://" + net.JoinHostPort(host, port)
+	}
+
+	if k.namespace == "" {
+		ns, err := ioutil.ReadFile(kubernetesServiceAccountDir + "/namespace")
+		if err != nil {
+			return nil, errors.New("a namespace must be specified when not running within a Kubernetes cluster")
+		}
+		k.namespace = strings.TrimSpace(string(ns))
+	}
+
+	// Unless a token is configured the token of the service account is used
+	// when present, and is read for each request as it can be rotated.
+	if _, err := os.Stat(kubernetesServiceAccountDir + "/token"); err == nil && k.token == "" {
+		k.tokenPath = kubernetesServiceAccountDir + "/token"
+	}
+	// The certificate authority of the service account is trusted unless TLS
+	// settings are configured.
+	if caCert, err := ioutil.ReadFile(kubernetesServiceAccountDir + "/ca.crt"); err == nil && !conf.TLS.Enabled {
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(caCert)
+		k.client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		}
+	}
+
+	k.leaseURL = strings.TrimSuffix(baseURL, "/") + "/apis/coordination.k8s.io/v1/namespaces/" + k.namespace + "/leases"
+	return k, nil
+}
+
+func (k *kubernetesLock) do(ctx context.Context, method, url string, reqBody, resBody interface{}) (int, error) {
+	req, err := newRequest(ctx, method, url, reqBody)
+	if err != nil {
+		return 0, err
+	}
+	if k.token != "" {
+		req.Header.Set("Authorization", "Bearer "+k.token)
+	} else if k.tokenPath != "" {
+		token, err := ioutil.ReadFile(k.tokenPath)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	return doJSON(k.client, req, resBody)
+}
+
+func (k *kubernetesLock) heldBy(lease *kubernetesLease, now time.Time) string {
+	spec := lease.Spec
+	if spec.HolderIdentity == nil || *spec.HolderIdentity == "" || spec.RenewTime == nil {
+		return ""
+	}
+	renewed, err := time.Parse(kubernetesMicroTime, *spec.RenewTime)
+	if err != nil {
+		return ""
+	}
+	duration := k.ttl
+	if spec.LeaseDurationSeconds != nil {
+		duration = time.Duration(*spec.LeaseDurationSeconds) * time.Second
+	}
+	if now.Sub(renewed) >= duration {
+		return ""
+	}
+	return *spec.HolderIdentity
+}
+
+func (k *kubernetesLock) acquire(ctx context.Context) (bool, error) {
+	now := k.now()
+	nowStr := now.UTC().Format(kubernetesMicroTime)
+	durationSeconds := int(k.ttl.Seconds())
+
+	var lease kubernetesLease
+	status, err := k.do(ctx, http.MethodGet, k.leaseURL+"/"+k.name, nil, &lease)
+	if status == http.StatusNotFound {
+		lease = kubernetesLease{APIVersion: "coordination.k8s.io/v1", Kind: "Lease"}
+		lease.Metadata.Name = k.name
+		lease.Metadata.Namespace = k.namespace
+		transitions := 0
+		lease.Spec = kubernetesLeaseSpec{
+			HolderIdentity:       &k.identity,
+			LeaseDurationSeconds: &durationSeconds,
+			AcquireTime:          &nowStr,
+			RenewTime:            &nowStr,
+			LeaseTransitions:     &transitions,
+		}
+		status, err = k.do(ctx, http.MethodPost, k.leaseURL, &lease, nil)
+		if status == http.StatusConflict {
+			// Another instance created the lease first.
+			return false, nil
+		}
+		return err == nil, err
+	}
+	if err != nil {
+		return false, err
+	}
+
+	holder := k.heldBy(&lease, now)
+	if holder != "" && holder != k.identity {
+		return false, nil
+	}
+	if holder != k.identity {
+		transitions := 1
+		if lease.Spec.LeaseTransitions != nil {
+			transitions = *lease.Spec.LeaseTransitions + 1
+		}
+		lease.Spec.LeaseTransitions = &transitions
+		lease.Spec.AcquireTime = &nowStr
+	}
+	lease.Spec.HolderIdentity = &k.identity
+	lease.Spec.LeaseDurationSeconds = &durationSeconds
+	lease.Spec.RenewTime = &nowStr
+
+	status, err = k.do(ctx, http.MethodPut, k.leaseURL+"/"+k.name, &lease, nil)
+	if status == http.StatusConflict {
+		// The lease was updated by another instance since it was read.
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (k *kubernetesLock) release(ctx context.Context) error {
+	var lease kubernetesLease
+	if _, err := k.do(ctx, http.MethodGet, k.leaseURL+"/"+k.name, nil, &lease); err != nil {
+		return err
+	}
+	if k.heldBy(&lease, k.now()) != k.identity {
+		return nil
+	}
+	empty := ""
+	lease.Spec.HolderIdentity = &empty
+	_, err := k.do(ctx, http.MethodPut, k.leaseURL+"/"+k.name, &lease, nil)
+	return err
+}
@@ -0,0 +1,4 @@
+// Package election provides leader election between Benthos instances that
+// share a config, using Consul, etcd or Kubernetes leases, so that a standby
+// instance can hold its input paused until the active instance goes away.
+package election
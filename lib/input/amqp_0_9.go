@@ -66,6 +66,21 @@ then the declaration passively verifies that they match the target fields.`,
 				docs.FieldAdvanced("enabled", "Whether to enable queue declaration.").HasDefault(false),
 				docs.FieldAdvanced("durable", "Whether the declared queue is durable.").HasDefault(false),
 			),
+			docs.FieldAdvanced("exchanges_declare",
+				"Allows you to declare exchanges before the bindings of the target queue are declared. Exchanges that do not exist are created, and if an exchange already exists then the declaration verifies that it matches the target fields.",
+				[]interface{}{
+					map[string]interface{}{
+						"name": "foo",
+						"type": "topic",
+					},
+				},
+			).Array().WithChildren(
+				docs.FieldString("name", "The name of the exchange.").HasDefault(""),
+				docs.FieldString("type", "The type of the exchange.").HasOptions(
+					"direct", "fanout", "topic", "headers", "x-custom",
+				).HasDefault("direct"),
+				docs.FieldBool("durable", "Whether the exchange should be durable.").HasDefault(true),
+			).AtVersion("3.54.0"),
 			docs.FieldAdvanced("bindings_declare",
 				"Allows you to passively declare bindings for the target queue.",
 				[]interface{}{
@@ -43,6 +43,8 @@ Receive messages from one or more Kinesis streams.`,
 		Description: `
 Consumes messages from one or more Kinesis streams either by automatically balancing shards across other instances of this input, or by consuming shards listed explicitly. The latest message sequence consumed by this input is stored within a [DynamoDB table](#table-schema), which allows it to resume at the correct sequence of the shard during restarts. This table is also used for coordination across distributed inputs when shard balancing.
 
+Alternatively, sequences can be stored within a local file by setting the field ` + "`checkpoint_path`" + `, in which case no DynamoDB table is required but shards are not balanced across instances, and therefore only a single instance should consume a given stream.
+
 Benthos will not store a consumed sequence unless it is acknowledged at the output level, which ensures at-least-once delivery guarantees. However, this also means that by default messages of a given shard cannot be processed concurrently. In order to increase the number of shard messages that can be processed concurrently increase the field ` + "`checkpoint_limit`" + `.
 
 ## Table Schema
@@ -58,6 +60,7 @@ Use the ` + "`batching`" + ` fields to configure an optional [batching policy](/
 				docs.FieldCommon(
 					"dynamodb", "Determines the table used for storing and accessing the latest consumed sequence for shards, and for coordinating balanced consumers of streams.",
 				).WithChildren(dynamoDBCheckpointFields...),
+				docs.FieldAdvanced("checkpoint_path", "An optional path of a local file in which to store the latest consumed sequence of shards instead of a DynamoDB table. Since shards cannot be coordinated across instances without a table, only a single instance of this input should consume a given stream when this is set.", "./kinesis_checkpoints.json").HasDefault("").AtVersion("3.54.0"),
 				docs.FieldCommon(
 					"checkpoint_limit", "The maximum gap between the in flight sequence versus the latest acknowledged sequence at a given time. Increasing this limit enables parallel processing and batching at the output level to work on individual shards. Any given sequence will not be committed unless all messages under that offset are delivered in order to preserve at least once delivery guarantees.",
 				),
@@ -82,6 +85,7 @@ type AWSKinesisConfig struct {
 	session.Config  `json:",inline" yaml:",inline"`
 	Streams         []string                 `json:"streams" yaml:"streams"`
 	DynamoDB        DynamoDBCheckpointConfig `json:"dynamodb" yaml:"dynamodb"`
+	CheckpointPath  string                   `json:"checkpoint_path" yaml:"checkpoint_path"`
 	CheckpointLimit int                      `json:"checkpoint_limit" yaml:"checkpoint_limit"`
 	CommitPeriod    string                   `json:"commit_period" yaml:"commit_period"`
 	LeasePeriod     string                   `json:"lease_period" yaml:"lease_period"`
@@ -96,6 +100,7 @@ func NewAWSKinesisConfig() AWSKinesisConfig {
 		Config:          session.NewConfig(),
 		Streams:         []string{},
 		DynamoDB:        NewDynamoDBCheckpointConfig(),
+		CheckpointPath:  "",
 		CheckpointLimit: 1,
 		CommitPeriod:    "5s",
 		LeasePeriod:     "30s",
@@ -123,7 +128,7 @@ type kinesisReader struct {
 	boffPool    sync.Pool
 
 	svc          kinesisiface.KinesisAPI
-	checkpointer *awsKinesisCheckpointer
+	checkpointer kinesisCheckpointer
 
 	streamShards    map[string][]string
 	balancedStreams []string
@@ -204,6 +209,9 @@ func newKinesisReader(
 			}
 		}
 	}
+	if conf.CheckpointPath != "" && conf.DynamoDB.Table != "" {
+		return nil, errors.New("it is not possible to set both a dynamodb table and a checkpoint_path")
+	}
 	if k.commitPeriod, err = time.ParseDuration(k.conf.CommitPeriod); err != nil {
 		return nil, fmt.Errorf("failed to parse commit period string: %v", err)
 	}
@@ -385,10 +393,10 @@ func (k *kinesisReader) runConsumer(wg *sync.WaitGroup, streamID, shardID, start
 			}
 
 			wg.Done()
-			k.log.Debugf("Closing stream '%v' shard '%v' as client '%v'%v\n", streamID, shardID, k.checkpointer.clientID, reason)
+			k.log.Debugf("Closing stream '%v' shard '%v' as client '%v'%v\n", streamID, shardID, k.clientID, reason)
 		}()
 
-		k.log.Debugf("Consuming stream '%v' shard '%v' as client '%v'\n", streamID, shardID, k.checkpointer.clientID)
+		k.log.Debugf("Consuming stream '%v' shard '%v' as client '%v'\n", streamID, shardID, k.clientID)
 
 		for {
 			var err error
@@ -700,7 +708,13 @@ func (k *kinesisReader) ConnectWithContext(ctx context.Context) error {
 	}
 
 	svc := kinesis.New(sess)
-	checkpointer, err := newAWSKinesisCheckpointer(sess, k.clientID, k.conf.DynamoDB, k.leasePeriod, k.commitPeriod)
+
+	var checkpointer kinesisCheckpointer
+	if k.conf.CheckpointPath != "" {
+		checkpointer, err = newFileKinesisCheckpointer(k.conf.CheckpointPath, k.clientID)
+	} else {
+		checkpointer, err = newAWSKinesisCheckpointer(sess, k.clientID, k.conf.DynamoDB, k.leasePeriod, k.commitPeriod)
+	}
 	if err != nil {
 		return err
 	}
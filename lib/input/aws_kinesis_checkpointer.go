@@ -4,8 +4,13 @@ package input
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/Jeffail/benthos/v3/internal/docs"
@@ -55,6 +60,16 @@ var (
 	ErrLeaseNotAcquired = errors.New("the shard could not be leased due to a collision")
 )
 
+// kinesisCheckpointer stores the latest sequence of shards and coordinates
+// which client consumes each shard.
+type kinesisCheckpointer interface {
+	AllClaims(ctx context.Context, streamID string) (map[string][]awsKinesisClientClaim, error)
+	Claim(ctx context.Context, streamID, shardID, fromClientID string) (string, error)
+	Checkpoint(ctx context.Context, streamID, shardID, sequenceNumber string, final bool) (bool, error)
+	Yield(ctx context.Context, streamID, shardID, sequenceNumber string) error
+	Delete(ctx context.Context, streamID, shardID string) error
+}
+
 // awsKinesisCheckpointer manages the shard checkpointing for a given client
 // identifier.
 type awsKinesisCheckpointer struct {
@@ -438,3 +453,139 @@ func (k *awsKinesisCheckpointer) Delete(ctx context.Context, streamID, shardID s
 }
 
 //------------------------------------------------------------------------------
+
+// fileKinesisCheckpointer stores shard checkpoints within a local file, and is
+// therefore only able to coordinate the shards consumed by a single client.
+// Claims are held in memory and only the sequences of shards are stored.
+type fileKinesisCheckpointer struct {
+	path     string
+	clientID string
+
+	mut       sync.Mutex
+	sequences map[string]map[string]string
+	claimed   map[string]map[string]bool
+}
+
+// newFileKinesisCheckpointer creates a file based checkpointer, reading the
+// sequences previously stored at a path if it exists.
+func newFileKinesisCheckpointer(path, clientID string) (*fileKinesisCheckpointer, error) {
+	f := &fileKinesisCheckpointer{
+		path:      path,
+		clientID:  clientID,
+		sequences: map[string]map[string]string{},
+		claimed:   map[string]map[string]bool{},
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return f, nil
+		}
+		return nil, fmt.Errorf("failed to read checkpoints: %w", err)
+	}
+	if err = json.Unmarshal(b, &f.sequences); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoints: %w", err)
+	}
+	return f, nil
+}
+
+// flush writes the sequences of all shards, the lock must be held.
+func (f *fileKinesisCheckpointer) flush() error {
+	b, err := json.Marshal(f.sequences)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(filepath.Dir(f.path), "."+filepath.Base(f.path)+".tmp")
+	if err = ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
+
+func (f *fileKinesisCheckpointer) setSequence(streamID, shardID, sequenceNumber string) {
+	if sequenceNumber == "" {
+		return
+	}
+	shards, exists := f.sequences[streamID]
+	if !exists {
+		shards = map[string]string{}
+		f.sequences[streamID] = shards
+	}
+	shards[shardID] = sequenceNumber
+}
+
+// AllClaims returns the shards of a stream claimed by this client, the leases
+// of which never expire.
+func (f *fileKinesisCheckpointer) AllClaims(ctx context.Context, streamID string) (map[string][]awsKinesisClientClaim, error) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	var claims []awsKinesisClientClaim
+	for shardID := range f.claimed[streamID] {
+		claims = append(claims, awsKinesisClientClaim{
+			ShardID:      shardID,
+			LeaseTimeout: time.Now(),
+		})
+	}
+	if len(claims) == 0 {
+		return map[string][]awsKinesisClientClaim{}, nil
+	}
+	return map[string][]awsKinesisClientClaim{f.clientID: claims}, nil
+}
+
+// Claim claims a shard and returns its stored sequence, a shard that is
+// already claimed cannot be claimed again until it is released.
+func (f *fileKinesisCheckpointer) Claim(ctx context.Context, streamID, shardID, fromClientID string) (string, error) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	shards, exists := f.claimed[streamID]
+	if !exists {
+		shards = map[string]bool{}
+		f.claimed[streamID] = shards
+	}
+	if shards[shardID] {
+		return "", ErrLeaseNotAcquired
+	}
+	shards[shardID] = true
+	return f.sequences[streamID][shardID], nil
+}
+
+// Checkpoint stores the sequence of a shard, and releases the claim of the
+// shard if final is true. The shard is always still owned by this client.
+func (f *fileKinesisCheckpointer) Checkpoint(ctx context.Context, streamID, shardID, sequenceNumber string, final bool) (bool, error) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	if final {
+		delete(f.claimed[streamID], shardID)
+	}
+	f.setSequence(streamID, shardID, sequenceNumber)
+	if err := f.flush(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Yield stores the sequence of a shard.
+func (f *fileKinesisCheckpointer) Yield(ctx context.Context, streamID, shardID, sequenceNumber string) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	f.setSequence(streamID, shardID, sequenceNumber)
+	return f.flush()
+}
+
+// Delete removes the checkpoint and the claim of a shard.
+func (f *fileKinesisCheckpointer) Delete(ctx context.Context, streamID, shardID string) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	delete(f.claimed[streamID], shardID)
+	delete(f.sequences[streamID], shardID)
+	if len(f.sequences[streamID]) == 0 {
+		delete(f.sequences, streamID)
+	}
+	return f.flush()
+}
+
+//------------------------------------------------------------------------------
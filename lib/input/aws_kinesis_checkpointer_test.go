@@ -0,0 +1,84 @@
+package input
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileKinesisCheckpointer(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+
+	c, err := newFileKinesisCheckpointer(path, "foo")
+	require.NoError(t, err)
+
+	seq, err := c.Claim(ctx, "stream", "shard0", "")
+	require.NoError(t, err)
+	assert.Equal(t, "", seq)
+
+	_, err = c.Claim(ctx, "stream", "shard0", "")
+	assert.Equal(t, ErrLeaseNotAcquired, err)
+
+	_, err = c.Claim(ctx, "stream", "shard1", "")
+	require.NoError(t, err)
+
+	claims, err := c.AllClaims(ctx, "stream")
+	require.NoError(t, err)
+	require.Len(t, claims["foo"], 2)
+
+	owned, err := c.Checkpoint(ctx, "stream", "shard0", "100", false)
+	require.NoError(t, err)
+	assert.True(t, owned)
+
+	owned, err = c.Checkpoint(ctx, "stream", "shard1", "200", true)
+	require.NoError(t, err)
+	assert.True(t, owned)
+
+	claims, err = c.AllClaims(ctx, "stream")
+	require.NoError(t, err)
+	require.Len(t, claims["foo"], 1)
+	assert.Equal(t, "shard0", claims["foo"][0].ShardID)
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"stream":{"shard0":"100","shard1":"200"}}`, string(b))
+
+	// A new checkpointer resumes from the stored sequences.
+	c, err = newFileKinesisCheckpointer(path, "bar")
+	require.NoError(t, err)
+
+	seq, err = c.Claim(ctx, "stream", "shard0", "")
+	require.NoError(t, err)
+	assert.Equal(t, "100", seq)
+
+	require.NoError(t, c.Delete(ctx, "stream", "shard0"))
+
+	b, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"stream":{"shard1":"200"}}`, string(b))
+}
+
+func TestFileKinesisCheckpointerBadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte("nope"), 0644))
+
+	_, err := newFileKinesisCheckpointer(path, "foo")
+	assert.Error(t, err)
+}
+
+func TestKinesisCheckpointPathWithTable(t *testing.T) {
+	conf := NewAWSKinesisConfig()
+	conf.Streams = []string{"foo"}
+	conf.DynamoDB.Table = "bar"
+	conf.CheckpointPath = "./baz.json"
+
+	_, err := newKinesisReader(conf, nil, log.Noop(), metrics.Noop())
+	assert.Error(t, err)
+}
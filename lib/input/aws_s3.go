@@ -85,6 +85,7 @@ You can access these metadata fields using [function interpolation](/docs/config
 			append(docs.FieldSpecs{
 				docs.FieldCommon("bucket", "The bucket to consume from. If the field `sqs.url` is specified this field is optional."),
 				docs.FieldCommon("prefix", "An optional path prefix, if set only objects with the prefix are consumed when walking a bucket."),
+				docs.FieldAdvanced("start_after", "An optional object key to start walking a bucket after, only objects with keys that are lexicographically greater are consumed. This is useful for replaying archived data from a point in time when keys are prefixed with a date.", "2021/08/01/").AtVersion("3.54.0"),
 			}, sess.FieldSpecs()...),
 			docs.FieldAdvanced("force_path_style_urls", "Forces the client API to use path style URLs for downloading keys, which is often required when connecting to custom endpoints."),
 			docs.FieldAdvanced("delete_objects", "Whether to delete downloaded objects from the bucket once they are processed."),
@@ -95,6 +96,7 @@ You can access these metadata fields using [function interpolation](/docs/config
 				docs.FieldCommon("key_path", "A [dot path](/docs/configuration/field_paths) whereby object keys are found in SQS messages."),
 				docs.FieldCommon("bucket_path", "A [dot path](/docs/configuration/field_paths) whereby the bucket name can be found in SQS messages."),
 				docs.FieldCommon("envelope_path", "A [dot path](/docs/configuration/field_paths) of a field to extract an enveloped JSON payload for further extracting the key and bucket from SQS messages. This is specifically useful when subscribing an SQS queue to an SNS topic that receives bucket events.", "Message"),
+				docs.FieldAdvanced("event_name_path", "An optional [dot path](/docs/configuration/field_paths) whereby the event names of SQS messages can be found. When set only objects of events with a name beginning with `ObjectCreated:` are downloaded, and SQS messages without any such events are deleted from the queue.", "Records.*.eventName").HasDefault("").AtVersion("3.54.0"),
 				docs.FieldAdvanced(
 					"delay_period",
 					"An optional period of time to wait from when a notification was originally sent to when the target key download is attempted.",
@@ -114,25 +116,27 @@ You can access these metadata fields using [function interpolation](/docs/config
 
 // AWSS3SQSConfig contains configuration for hooking up the S3 input with an SQS queue.
 type AWSS3SQSConfig struct {
-	URL          string `json:"url" yaml:"url"`
-	Endpoint     string `json:"endpoint" yaml:"endpoint"`
-	EnvelopePath string `json:"envelope_path" yaml:"envelope_path"`
-	KeyPath      string `json:"key_path" yaml:"key_path"`
-	BucketPath   string `json:"bucket_path" yaml:"bucket_path"`
-	DelayPeriod  string `json:"delay_period" yaml:"delay_period"`
-	MaxMessages  int64  `json:"max_messages" yaml:"max_messages"`
+	URL           string `json:"url" yaml:"url"`
+	Endpoint      string `json:"endpoint" yaml:"endpoint"`
+	EnvelopePath  string `json:"envelope_path" yaml:"envelope_path"`
+	KeyPath       string `json:"key_path" yaml:"key_path"`
+	BucketPath    string `json:"bucket_path" yaml:"bucket_path"`
+	EventNamePath string `json:"event_name_path" yaml:"event_name_path"`
+	DelayPeriod   string `json:"delay_period" yaml:"delay_period"`
+	MaxMessages   int64  `json:"max_messages" yaml:"max_messages"`
 }
 
 // NewAWSS3SQSConfig creates a new AWSS3SQSConfig with default values.
 func NewAWSS3SQSConfig() AWSS3SQSConfig {
 	return AWSS3SQSConfig{
-		URL:          "",
-		Endpoint:     "",
-		EnvelopePath: "",
-		KeyPath:      "Records.*.s3.object.key",
-		BucketPath:   "Records.*.s3.bucket.name",
-		DelayPeriod:  "",
-		MaxMessages:  10,
+		URL:           "",
+		Endpoint:      "",
+		EnvelopePath:  "",
+		KeyPath:       "Records.*.s3.object.key",
+		BucketPath:    "Records.*.s3.bucket.name",
+		EventNamePath: "",
+		DelayPeriod:   "",
+		MaxMessages:   10,
 	}
 }
 
@@ -142,6 +146,7 @@ type AWSS3Config struct {
 	Bucket             string         `json:"bucket" yaml:"bucket"`
 	Codec              string         `json:"codec" yaml:"codec"`
 	Prefix             string         `json:"prefix" yaml:"prefix"`
+	StartAfter         string         `json:"start_after" yaml:"start_after"`
 	ForcePathStyleURLs bool           `json:"force_path_style_urls" yaml:"force_path_style_urls"`
 	DeleteObjects      bool           `json:"delete_objects" yaml:"delete_objects"`
 	SQS                AWSS3SQSConfig `json:"sqs" yaml:"sqs"`
@@ -153,6 +158,7 @@ func NewAWSS3Config() AWSS3Config {
 		Config:             sess.NewConfig(),
 		Bucket:             "",
 		Prefix:             "",
+		StartAfter:         "",
 		Codec:              "all-bytes",
 		ForcePathStyleURLs: false,
 		DeleteObjects:      false,
@@ -231,6 +237,9 @@ func newStaticTargetReader(
 	if len(conf.Prefix) > 0 {
 		listInput.Prefix = aws.String(conf.Prefix)
 	}
+	if len(conf.StartAfter) > 0 {
+		listInput.StartAfter = aws.String(conf.StartAfter)
+	}
 	output, err := s3Client.ListObjectsV2WithContext(ctx, listInput)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list objects: %v", err)
@@ -379,6 +388,7 @@ func (s *sqsTargetReader) parseObjectPaths(sqsMsg *string) ([]s3ObjectTarget, er
 
 	var keys []string
 	var buckets []string
+	var eventNames []string
 
 	switch t := gObj.Path(s.conf.SQS.KeyPath).Data().(type) {
 	case string:
@@ -394,9 +404,22 @@ func (s *sqsTargetReader) parseObjectPaths(sqsMsg *string) ([]s3ObjectTarget, er
 			buckets = digStrsFromSlices(t)
 		}
 	}
+	if len(s.conf.SQS.EventNamePath) > 0 {
+		switch t := gObj.Path(s.conf.SQS.EventNamePath).Data().(type) {
+		case string:
+			eventNames = []string{t}
+		case []interface{}:
+			eventNames = digStrsFromSlices(t)
+		}
+	}
 
 	objects := make([]s3ObjectTarget, 0, len(keys))
 	for i, key := range keys {
+		if len(s.conf.SQS.EventNamePath) > 0 {
+			if len(eventNames) <= i || !strings.HasPrefix(eventNames[i], "ObjectCreated:") {
+				continue
+			}
+		}
 		if key, err = url.QueryUnescape(key); err != nil {
 			return nil, fmt.Errorf("failed to parse key from SQS message: %v", err)
 		}
@@ -462,6 +485,15 @@ func (s *sqsTargetReader) readSQSEvents(ctx context.Context) ([]*s3ObjectTarget,
 			continue
 		}
 		if len(objects) == 0 {
+			if len(s.conf.SQS.EventNamePath) > 0 {
+				// Notifications of other events, such as deletions and tests,
+				// are never going to contain objects to download.
+				s.log.Debugln("Deleting SQS message without object created events")
+				if err := s.ackSQSMessage(ctx, sqsMsg); err != nil {
+					s.log.Errorf("Failed to delete SQS message: %v\n", err)
+				}
+				continue
+			}
 			addDudFn(sqsMsg)
 			s.log.Debugln("Extracted zero target keys from SQS message")
 			continue
@@ -582,6 +614,9 @@ func newAmazonS3(
 	if conf.Prefix != "" && conf.SQS.URL != "" {
 		return nil, errors.New("cannot specify both a prefix and sqs.url")
 	}
+	if conf.StartAfter != "" && conf.SQS.URL != "" {
+		return nil, errors.New("cannot specify both a start_after and sqs.url")
+	}
 	s := &awsS3{
 		conf:  conf,
 		log:   log,
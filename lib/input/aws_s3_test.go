@@ -0,0 +1,109 @@
+package input
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSS3SQSEventNameFilter(t *testing.T) {
+	body := aws.String(`{"Records":[
+	{"eventName":"ObjectCreated:Put","s3":{"bucket":{"name":"foo"},"object":{"key":"a%2Fb.txt"}}},
+	{"eventName":"ObjectRemoved:Delete","s3":{"bucket":{"name":"foo"},"object":{"key":"c.txt"}}},
+	{"eventName":"ObjectCreated:CompleteMultipartUpload","s3":{"bucket":{"name":"bar"},"object":{"key":"d.txt"}}}
+]}`)
+
+	conf := NewAWSS3Config()
+	r := newSQSTargetReader(conf, log.Noop(), nil, nil)
+
+	objects, err := r.parseObjectPaths(body)
+	require.NoError(t, err)
+	assert.Equal(t, []s3ObjectTarget{
+		{key: "a/b.txt", bucket: "foo"},
+		{key: "c.txt", bucket: "foo"},
+		{key: "d.txt", bucket: "bar"},
+	}, objects)
+
+	conf.SQS.EventNamePath = "Records.*.eventName"
+	r = newSQSTargetReader(conf, log.Noop(), nil, nil)
+
+	objects, err = r.parseObjectPaths(body)
+	require.NoError(t, err)
+	assert.Equal(t, []s3ObjectTarget{
+		{key: "a/b.txt", bucket: "foo"},
+		{key: "d.txt", bucket: "bar"},
+	}, objects)
+
+	// Test events sent when configuring notifications contain no objects.
+	objects, err = r.parseObjectPaths(aws.String(`{"Service":"Amazon S3","Event":"s3:TestEvent","Bucket":"foo"}`))
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+}
+
+func TestAWSS3StaticStartAfter(t *testing.T) {
+	keys := []string{"2021/07/31/a.json", "2021/08/01/a.json", "2021/08/01/b.json", "2021/08/02/a.json"}
+
+	var startAfterMut sync.Mutex
+	var startAfters []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startAfter := r.URL.Query().Get("start-after")
+		startAfterMut.Lock()
+		startAfters = append(startAfters, startAfter)
+		startAfterMut.Unlock()
+
+		// Lists a single key per page in order to exercise pagination.
+		var contents string
+		for _, k := range keys {
+			if k > startAfter {
+				contents = fmt.Sprintf("<Contents><Key>%v</Key></Contents>", k)
+				break
+			}
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><ListBucketResult><Name>foo</Name>%v</ListBucketResult>`, contents)
+	}))
+	defer server.Close()
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:           aws.String("eu-west-1"),
+		Endpoint:         aws.String(server.URL),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("foo", "bar", ""),
+	})
+	require.NoError(t, err)
+
+	conf := NewAWSS3Config()
+	conf.Bucket = "foo"
+	conf.StartAfter = "2021/08/01/"
+
+	r, err := newStaticTargetReader(context.Background(), conf, log.Noop(), s3.New(sess))
+	require.NoError(t, err)
+
+	var consumed []string
+	for {
+		obj, err := r.Pop(context.Background())
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		consumed = append(consumed, obj.key)
+	}
+
+	assert.Equal(t, keys[1:], consumed)
+
+	startAfterMut.Lock()
+	assert.Equal(t, []string{"2021/08/01/", "2021/08/01/a.json", "2021/08/01/b.json", "2021/08/02/a.json"}, startAfters)
+	startAfterMut.Unlock()
+}
@@ -2,6 +2,8 @@ package input
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -18,6 +20,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	"github.com/cenkalti/backoff/v4"
 )
 
@@ -59,6 +62,8 @@ You can access these metadata fields using
 		FieldSpecs: append(docs.FieldSpecs{
 			docs.FieldCommon("url", "The SQS URL to consume from."),
 			docs.FieldAdvanced("delete_message", "Whether to delete the consumed message once it is acked. Disabling allows you to handle the deletion using a different mechanism."),
+			docs.FieldAdvanced("wait_time_seconds", "The maximum number of seconds to wait for messages to arrive when polling the queue, which enables long polling when greater than zero. The maximum is 20.").HasDefault(0).AtVersion("3.54.0"),
+			docs.FieldAdvanced("visibility_timeout", "An optional visibility timeout to set for received messages, which overrides the default of the queue. When set, the visibility timeout of messages that have been received but not yet acknowledged is periodically extended, which prevents messages that take a long time to deliver from becoming visible to other consumers. The timeout is rounded down to whole seconds.", "30s", "5m").HasDefault("").AtVersion("3.54.0"),
 		}, sess.FieldSpecs()...),
 		Categories: []Category{
 			CategoryServices,
@@ -71,17 +76,21 @@ You can access these metadata fields using
 
 // AWSSQSConfig contains configuration values for the input type.
 type AWSSQSConfig struct {
-	sess.Config   `json:",inline" yaml:",inline"`
-	URL           string `json:"url" yaml:"url"`
-	DeleteMessage bool   `json:"delete_message" yaml:"delete_message"`
+	sess.Config       `json:",inline" yaml:",inline"`
+	URL               string `json:"url" yaml:"url"`
+	DeleteMessage     bool   `json:"delete_message" yaml:"delete_message"`
+	WaitTimeSeconds   int    `json:"wait_time_seconds" yaml:"wait_time_seconds"`
+	VisibilityTimeout string `json:"visibility_timeout" yaml:"visibility_timeout"`
 }
 
 // NewAWSSQSConfig creates a new Config with default values.
 func NewAWSSQSConfig() AWSSQSConfig {
 	return AWSSQSConfig{
-		Config:        sess.NewConfig(),
-		URL:           "",
-		DeleteMessage: true,
+		Config:            sess.NewConfig(),
+		URL:               "",
+		DeleteMessage:     true,
+		WaitTimeSeconds:   0,
+		VisibilityTimeout: "",
 	}
 }
 
@@ -90,8 +99,14 @@ func NewAWSSQSConfig() AWSSQSConfig {
 type awsSQS struct {
 	conf AWSSQSConfig
 
+	visibilityTimeout int64
+	visibilityRefresh time.Duration
+
 	session *session.Session
-	sqs     *sqs.SQS
+	sqs     sqsiface.SQSAPI
+
+	inFlightMut sync.Mutex
+	inFlight    map[string]string
 
 	messagesChan     chan *sqs.Message
 	ackMessagesChan  chan sqsMessageHandle
@@ -103,15 +118,30 @@ type awsSQS struct {
 }
 
 func newAWSSQS(conf AWSSQSConfig, log log.Modular, stats metrics.Type) (*awsSQS, error) {
-	return &awsSQS{
+	if conf.WaitTimeSeconds < 0 || conf.WaitTimeSeconds > 20 {
+		return nil, fmt.Errorf("wait_time_seconds must be between 0 and 20, got %v", conf.WaitTimeSeconds)
+	}
+	a := &awsSQS{
 		conf:             conf,
 		log:              log,
 		stats:            stats,
+		inFlight:         map[string]string{},
 		messagesChan:     make(chan *sqs.Message),
 		ackMessagesChan:  make(chan sqsMessageHandle),
 		nackMessagesChan: make(chan sqsMessageHandle),
 		closeSignal:      shutdown.NewSignaller(),
-	}, nil
+	}
+	if conf.VisibilityTimeout != "" {
+		timeout, err := time.ParseDuration(conf.VisibilityTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse visibility_timeout string: %v", err)
+		}
+		if a.visibilityTimeout = int64(timeout / time.Second); a.visibilityTimeout < 1 {
+			return nil, errors.New("visibility_timeout must be at least one second")
+		}
+		a.visibilityRefresh = time.Duration(a.visibilityTimeout) * time.Second / 2
+	}
+	return a, nil
 }
 
 // ConnectWithContext attempts to establish a connection to the target SQS
@@ -128,18 +158,83 @@ func (a *awsSQS) ConnectWithContext(ctx context.Context) error {
 
 	a.sqs = sqs.New(sess)
 	a.session = sess
+	a.start()
+
+	a.log.Infof("Receiving Amazon SQS messages from URL: %v\n", a.conf.URL)
+	return nil
+}
 
+func (a *awsSQS) start() {
 	var wg sync.WaitGroup
 	wg.Add(2)
 	go a.readLoop(&wg)
 	go a.ackLoop(&wg)
+	if a.visibilityTimeout > 0 {
+		wg.Add(1)
+		go a.refreshLoop(&wg)
+	}
 	go func() {
 		wg.Wait()
 		a.closeSignal.ShutdownComplete()
 	}()
+}
 
-	a.log.Infof("Receiving Amazon SQS messages from URL: %v\n", a.conf.URL)
-	return nil
+// refreshLoop periodically extends the visibility timeout of messages that
+// have been received but not yet acknowledged.
+func (a *awsSQS) refreshLoop(wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	refreshTicker := time.NewTicker(a.visibilityRefresh)
+	defer refreshTicker.Stop()
+
+	for {
+		select {
+		case <-refreshTicker.C:
+		case <-a.closeSignal.CloseAtLeisureChan():
+			return
+		}
+
+		a.inFlightMut.Lock()
+		handles := make([]sqsMessageHandle, 0, len(a.inFlight))
+		for id, receiptHandle := range a.inFlight {
+			handles = append(handles, sqsMessageHandle{
+				id:            id,
+				receiptHandle: receiptHandle,
+			})
+		}
+		a.inFlightMut.Unlock()
+		if len(handles) == 0 {
+			continue
+		}
+
+		ctx, done := a.closeSignal.CloseAtLeisureCtx(context.Background())
+		if err := a.updateVisibilityMessages(ctx, a.visibilityTimeout, handles...); err != nil {
+			a.log.Errorf("Failed to extend the visibility timeout of messages: %v", err)
+		}
+		done()
+	}
+}
+
+func (a *awsSQS) addInFlight(msgs ...*sqs.Message) {
+	if a.visibilityTimeout == 0 {
+		return
+	}
+	a.inFlightMut.Lock()
+	for _, m := range msgs {
+		if m.MessageId != nil && m.ReceiptHandle != nil {
+			a.inFlight[*m.MessageId] = *m.ReceiptHandle
+		}
+	}
+	a.inFlightMut.Unlock()
+}
+
+func (a *awsSQS) removeInFlight(id string) {
+	if a.visibilityTimeout == 0 {
+		return
+	}
+	a.inFlightMut.Lock()
+	delete(a.inFlight, id)
+	a.inFlightMut.Unlock()
 }
 
 func (a *awsSQS) ackLoop(wg *sync.WaitGroup) {
@@ -235,12 +330,19 @@ func (a *awsSQS) readLoop(wg *sync.WaitGroup) {
 	getMsgs := func() {
 		ctx, done := a.closeSignal.CloseAtLeisureCtx(context.Background())
 		defer done()
-		res, err := a.sqs.ReceiveMessageWithContext(ctx, &sqs.ReceiveMessageInput{
+		input := &sqs.ReceiveMessageInput{
 			QueueUrl:              aws.String(a.conf.URL),
 			MaxNumberOfMessages:   aws.Int64(10),
 			AttributeNames:        []*string{aws.String("All")},
 			MessageAttributeNames: []*string{aws.String("All")},
-		})
+		}
+		if a.conf.WaitTimeSeconds > 0 {
+			input.WaitTimeSeconds = aws.Int64(int64(a.conf.WaitTimeSeconds))
+		}
+		if a.visibilityTimeout > 0 {
+			input.VisibilityTimeout = aws.Int64(a.visibilityTimeout)
+		}
+		res, err := a.sqs.ReceiveMessageWithContext(ctx, input)
 		if err != nil {
 			if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != request.CanceledErrorCode {
 				a.log.Errorf("Failed to pull new SQS messages: %v", aerr)
@@ -248,6 +350,7 @@ func (a *awsSQS) readLoop(wg *sync.WaitGroup) {
 			return
 		}
 		if len(res.Messages) > 0 {
+			a.addInFlight(res.Messages...)
 			pendingMsgs = append(pendingMsgs, res.Messages...)
 			backoff.Reset()
 		}
@@ -308,6 +411,10 @@ func (a *awsSQS) deleteMessages(ctx context.Context, msgs ...sqsMessageHandle) e
 }
 
 func (a *awsSQS) resetMessages(ctx context.Context, msgs ...sqsMessageHandle) error {
+	return a.updateVisibilityMessages(ctx, 0, msgs...)
+}
+
+func (a *awsSQS) updateVisibilityMessages(ctx context.Context, timeout int64, msgs ...sqsMessageHandle) error {
 	for len(msgs) > 0 {
 		input := sqs.ChangeMessageVisibilityBatchInput{
 			QueueUrl: aws.String(a.conf.URL),
@@ -318,7 +425,7 @@ func (a *awsSQS) resetMessages(ctx context.Context, msgs ...sqsMessageHandle) er
 			input.Entries = append(input.Entries, &sqs.ChangeMessageVisibilityBatchRequestEntry{
 				Id:                aws.String(msg.id),
 				ReceiptHandle:     aws.String(msg.receiptHandle),
-				VisibilityTimeout: aws.Int64(0),
+				VisibilityTimeout: aws.Int64(timeout),
 			})
 			if len(input.Entries) == 10 {
 				break
@@ -331,7 +438,7 @@ func (a *awsSQS) resetMessages(ctx context.Context, msgs ...sqsMessageHandle) er
 			return err
 		}
 		for _, fail := range response.Failed {
-			a.log.Errorf("Failed to delete consumed SQS message '%v', response code: %v\n", *fail.Id, *fail.Code)
+			a.log.Errorf("Failed to update the visibility timeout of consumed SQS message '%v', response code: %v\n", *fail.Id, *fail.Code)
 		}
 	}
 	return nil
@@ -390,6 +497,7 @@ func (a *awsSQS) ReadWithContext(ctx context.Context) (types.Message, reader.Asy
 		if mHandle.receiptHandle == "" {
 			return nil
 		}
+		a.removeInFlight(mHandle.id)
 
 		if res.Error() == nil {
 			if !a.conf.DeleteMessage {
@@ -0,0 +1,155 @@
+package input
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSQSInput struct {
+	sqsiface.SQSAPI
+
+	mut        sync.Mutex
+	queue      []*sqs.Message
+	receives   []*sqs.ReceiveMessageInput
+	extended   map[string]int64
+	deleted    []string
+	extendChan chan struct{}
+}
+
+func (m *mockSQSInput) ReceiveMessageWithContext(ctx aws.Context, input *sqs.ReceiveMessageInput, opts ...request.Option) (*sqs.ReceiveMessageOutput, error) {
+	m.mut.Lock()
+	m.receives = append(m.receives, input)
+	msgs := m.queue
+	m.queue = nil
+	m.mut.Unlock()
+
+	if len(msgs) == 0 {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &sqs.ReceiveMessageOutput{Messages: msgs}, nil
+}
+
+func (m *mockSQSInput) ChangeMessageVisibilityBatchWithContext(ctx aws.Context, input *sqs.ChangeMessageVisibilityBatchInput, opts ...request.Option) (*sqs.ChangeMessageVisibilityBatchOutput, error) {
+	m.mut.Lock()
+	for _, e := range input.Entries {
+		m.extended[*e.Id] = *e.VisibilityTimeout
+	}
+	m.mut.Unlock()
+
+	select {
+	case m.extendChan <- struct{}{}:
+	default:
+	}
+	return &sqs.ChangeMessageVisibilityBatchOutput{}, nil
+}
+
+func (m *mockSQSInput) DeleteMessageBatchWithContext(ctx aws.Context, input *sqs.DeleteMessageBatchInput, opts ...request.Option) (*sqs.DeleteMessageBatchOutput, error) {
+	m.mut.Lock()
+	for _, e := range input.Entries {
+		m.deleted = append(m.deleted, *e.Id)
+	}
+	m.mut.Unlock()
+	return &sqs.DeleteMessageBatchOutput{}, nil
+}
+
+func (m *mockSQSInput) waitForExtension(t *testing.T) map[string]int64 {
+	t.Helper()
+
+	m.mut.Lock()
+	m.extended = map[string]int64{}
+	m.mut.Unlock()
+
+	select {
+	case <-m.extendChan:
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for visibility extension")
+	}
+
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	extended := m.extended
+	m.extended = map[string]int64{}
+	return extended
+}
+
+func TestAWSSQSVisibilityExtension(t *testing.T) {
+	conf := NewAWSSQSConfig()
+	conf.URL = "http://example.com/queue"
+	conf.WaitTimeSeconds = 20
+	conf.VisibilityTimeout = "30s"
+
+	r, err := newAWSSQS(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	mock := &mockSQSInput{
+		queue: []*sqs.Message{
+			{MessageId: aws.String("foo"), ReceiptHandle: aws.String("foo_handle"), Body: aws.String("foo body")},
+			{MessageId: aws.String("bar"), ReceiptHandle: aws.String("bar_handle"), Body: aws.String("bar body")},
+		},
+		extended:   map[string]int64{},
+		extendChan: make(chan struct{}),
+	}
+	r.sqs = mock
+	r.session = &session.Session{}
+	r.visibilityRefresh = time.Millisecond * 10
+	r.start()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	msg, ackFn, err := r.ReadWithContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "foo body", string(msg.Get(0).Get()))
+
+	// Messages that have been received are extended whether or not they have
+	// been read yet.
+	assert.Equal(t, map[string]int64{"foo": 30, "bar": 30}, mock.waitForExtension(t))
+
+	// An extension that began before the ack may still include the acked
+	// message, and therefore the one after it is checked.
+	require.NoError(t, ackFn(ctx, response.NewAck()))
+	mock.waitForExtension(t)
+	assert.Equal(t, map[string]int64{"bar": 30}, mock.waitForExtension(t))
+
+	mock.mut.Lock()
+	require.NotEmpty(t, mock.receives)
+	assert.Equal(t, int64(20), *mock.receives[0].WaitTimeSeconds)
+	assert.Equal(t, int64(30), *mock.receives[0].VisibilityTimeout)
+	mock.mut.Unlock()
+
+	// Only the acked message is deleted.
+	r.CloseAsync()
+	require.NoError(t, r.WaitForClose(time.Second*5))
+
+	mock.mut.Lock()
+	assert.Equal(t, []string{"foo"}, mock.deleted)
+	mock.mut.Unlock()
+}
+
+func TestAWSSQSBadConfig(t *testing.T) {
+	for name, fn := range map[string]func(c *AWSSQSConfig){
+		"wait time too long":       func(c *AWSSQSConfig) { c.WaitTimeSeconds = 21 },
+		"negative wait time":       func(c *AWSSQSConfig) { c.WaitTimeSeconds = -1 },
+		"bad visibility timeout":   func(c *AWSSQSConfig) { c.VisibilityTimeout = "nope" },
+		"short visibility timeout": func(c *AWSSQSConfig) { c.VisibilityTimeout = "500ms" },
+	} {
+		conf := NewAWSSQSConfig()
+		fn(&conf)
+		_, err := newAWSSQS(conf, log.Noop(), metrics.Noop())
+		assert.Error(t, err, name)
+	}
+}
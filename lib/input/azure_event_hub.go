@@ -0,0 +1,150 @@
+package input
+
+import (
+	"strings"
+
+	"github.com/Jeffail/benthos/v3/internal/docs"
+	"github.com/Jeffail/benthos/v3/lib/input/reader"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message/batch"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/kafka/eventhub"
+)
+
+//------------------------------------------------------------------------------
+
+func init() {
+	Constructors[TypeAzureEventHub] = TypeSpec{
+		constructor: fromSimpleConstructor(NewAzureEventHub),
+		Status:      docs.StatusExperimental,
+		Version:     "3.54.0",
+		Summary: `
+Consumes events from an Azure event hub through the Kafka compatible endpoint of its namespace.`,
+		Description: `
+The Kafka compatible endpoint of Event Hubs is available in the standard tier and above. The address of the endpoint and the credentials used to authenticate with it are derived from the connection string of the namespace or event hub.
+
+By default the partitions of the event hub are balanced across all consumers that share the consumer group, and the offsets of delivered messages are committed to the consumer group as checkpoints, from which consumption resumes after a restart or a rebalance. Alternatively, the field ` + "`partitions`" + ` consumes explicit partitions. Messages of the same partition are processed in order unless the field ` + "`checkpoint_limit`" + ` is increased.
+
+### Metadata
+
+This input adds the same metadata fields to each message as the ` + "[`kafka` input](/docs/components/inputs/kafka#metadata)" + `, where the topic is the event hub:
+
+` + "``` text" + `
+- kafka_key
+- kafka_topic
+- kafka_partition
+- kafka_offset
+- kafka_lag
+- kafka_timestamp_unix
+- All existing message headers
+` + "```" + `
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#metadata).`,
+		FieldSpecs: docs.FieldSpecs{
+			docs.FieldString("connection_string", "A connection string of the Event Hubs namespace or event hub, which is the password of the SASL `PLAIN` mechanism that the endpoint is authenticated with.", "Endpoint=sb://example.servicebus.windows.net/;SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=..."),
+			docs.FieldString("event_hub", "The event hub to consume from, which can be omitted when the connection string contains an `EntityPath`.", "my-event-hub"),
+			docs.FieldString(
+				"partitions", "A list of explicit partitions of the event hub to consume from, where ranges such as `0-3` are supported. When empty the partitions are balanced across the consumers of the consumer group.",
+				[]string{"0", "1"}, []string{"0-3"},
+			).Array(),
+			docs.FieldString("consumer_group", "The consumer group of the event hub that checkpoints are committed to. This field can be explicitly made empty when consuming explicit `partitions` in order to disable checkpoints."),
+			docs.FieldString("client_id", "An identifier for the client connection.").Advanced(),
+			docs.FieldBool("start_from_oldest", "If a checkpoint is not found for a partition, determines whether to consume from the oldest available event, otherwise events are consumed from the latest.").Advanced(),
+			docs.FieldInt("checkpoint_limit", "The maximum number of messages of the same partition that can be processed at a given time. Increasing this limit enables parallel processing and batching at the output level to work on individual partitions. Any given offset will not be committed unless all messages under that offset are delivered in order to preserve at least once delivery guarantees."),
+			docs.FieldString("commit_period", "The period of time between each commit of the checkpoints of the consumed partitions. Checkpoints are always committed during shutdown.").Advanced(),
+			func() docs.FieldSpec {
+				b := batch.FieldSpec()
+				b.IsAdvanced = true
+				return b
+			}(),
+		},
+		Categories: []Category{
+			CategoryServices,
+			CategoryAzure,
+		},
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// AzureEventHubConfig contains configuration fields for the AzureEventHub input
+// type.
+type AzureEventHubConfig struct {
+	ConnectionString string             `json:"connection_string" yaml:"connection_string"`
+	EventHub         string             `json:"event_hub" yaml:"event_hub"`
+	Partitions       []string           `json:"partitions" yaml:"partitions"`
+	ConsumerGroup    string             `json:"consumer_group" yaml:"consumer_group"`
+	ClientID         string             `json:"client_id" yaml:"client_id"`
+	StartFromOldest  bool               `json:"start_from_oldest" yaml:"start_from_oldest"`
+	CheckpointLimit  int                `json:"checkpoint_limit" yaml:"checkpoint_limit"`
+	CommitPeriod     string             `json:"commit_period" yaml:"commit_period"`
+	Batching         batch.PolicyConfig `json:"batching" yaml:"batching"`
+}
+
+// NewAzureEventHubConfig creates a new AzureEventHubConfig with default values.
+func NewAzureEventHubConfig() AzureEventHubConfig {
+	return AzureEventHubConfig{
+		ConnectionString: "",
+		EventHub:         "",
+		Partitions:       []string{},
+		ConsumerGroup:    "$Default",
+		ClientID:         "benthos_azure_event_hub_input",
+		StartFromOldest:  true,
+		CheckpointLimit:  1,
+		CommitPeriod:     "1s",
+		Batching:         batch.NewPolicyConfig(),
+	}
+}
+
+// kafkaConfig returns the config of a kafka input that consumes from the Kafka
+// compatible endpoint of the event hub.
+func (c AzureEventHubConfig) kafkaConfig() (reader.KafkaConfig, error) {
+	endpoint, err := eventhub.ParseConnectionString(c.ConnectionString)
+	if err != nil {
+		return reader.KafkaConfig{}, err
+	}
+	topic, err := endpoint.Topic(c.EventHub)
+	if err != nil {
+		return reader.KafkaConfig{}, err
+	}
+
+	kConf := reader.NewKafkaConfig()
+	kConf.Addresses = []string{endpoint.Address}
+	kConf.TLS = endpoint.TLS()
+	kConf.SASL = endpoint.SASL()
+	for _, p := range c.Partitions {
+		for _, splitPartitions := range strings.Split(p, ",") {
+			if trimmed := strings.TrimSpace(splitPartitions); len(trimmed) > 0 {
+				kConf.Topics = append(kConf.Topics, topic+":"+trimmed)
+			}
+		}
+	}
+	if len(kConf.Topics) == 0 {
+		kConf.Topics = []string{topic}
+	}
+	kConf.ConsumerGroup = c.ConsumerGroup
+	kConf.ClientID = c.ClientID
+	kConf.StartFromOldest = c.StartFromOldest
+	kConf.CheckpointLimit = c.CheckpointLimit
+	kConf.CommitPeriod = c.CommitPeriod
+	kConf.Batching = c.Batching
+	return kConf, nil
+}
+
+//------------------------------------------------------------------------------
+
+// NewAzureEventHub creates a new AzureEventHub input type.
+func NewAzureEventHub(conf Config, mgr types.Manager, log log.Modular, stats metrics.Type) (Type, error) {
+	kConf, err := conf.AzureEventHub.kafkaConfig()
+	if err != nil {
+		return nil, err
+	}
+	rdr, err := newKafkaReader(kConf, mgr, log, stats)
+	if err != nil {
+		return nil, err
+	}
+	return NewAsyncReader(TypeAzureEventHub, false, reader.NewAsyncPreserver(rdr), log, stats)
+}
+
+//------------------------------------------------------------------------------
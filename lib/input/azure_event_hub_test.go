@@ -0,0 +1,55 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureEventHubKafkaConfig(t *testing.T) {
+	connStr := "Endpoint=sb://foo.servicebus.windows.net/;SharedAccessKeyName=a;SharedAccessKey=b="
+
+	conf := NewAzureEventHubConfig()
+	conf.ConnectionString = connStr
+	conf.EventHub = "bar"
+
+	kConf, err := conf.kafkaConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo.servicebus.windows.net:9093"}, kConf.Addresses)
+	assert.Equal(t, []string{"bar"}, kConf.Topics)
+	assert.Equal(t, "$Default", kConf.ConsumerGroup)
+	assert.True(t, kConf.TLS.Enabled)
+	assert.Equal(t, "PLAIN", kConf.SASL.Mechanism)
+	assert.Equal(t, "$ConnectionString", kConf.SASL.User)
+	assert.Equal(t, connStr, kConf.SASL.Password)
+
+	k, err := newKafkaReader(kConf, types.NoopMgr(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bar"}, k.balancedTopics)
+
+	conf.Partitions = []string{"0", "2-3,5"}
+	kConf, err = conf.kafkaConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bar:0", "bar:2-3", "bar:5"}, kConf.Topics)
+
+	k, err = newKafkaReader(kConf, types.NoopMgr(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]int32{"bar": {0, 2, 3, 5}}, k.topicPartitions)
+}
+
+func TestAzureEventHubErrors(t *testing.T) {
+	conf := NewConfig()
+	conf.Type = TypeAzureEventHub
+
+	_, err := New(conf, types.NoopMgr(), log.Noop(), metrics.Noop())
+	require.Error(t, err)
+
+	conf.AzureEventHub.ConnectionString = "Endpoint=sb://foo.servicebus.windows.net/;SharedAccessKeyName=a;SharedAccessKey=b"
+	_, err = New(conf, types.NoopMgr(), log.Noop(), metrics.Noop())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "EntityPath")
+}
@@ -209,6 +209,7 @@ const (
 	TypeAWSS3             = "aws_s3"
 	TypeAWSSQS            = "aws_sqs"
 	TypeAzureBlobStorage  = "azure_blob_storage"
+	TypeAzureEventHub     = "azure_event_hub"
 	TypeAzureQueueStorage = "azure_queue_storage"
 	TypeBloblang          = "bloblang"
 	TypeBroker            = "broker"
@@ -267,6 +268,7 @@ type Config struct {
 	AWSS3             AWSS3Config                  `json:"aws_s3" yaml:"aws_s3"`
 	AWSSQS            AWSSQSConfig                 `json:"aws_sqs" yaml:"aws_sqs"`
 	AzureBlobStorage  AzureBlobStorageConfig       `json:"azure_blob_storage" yaml:"azure_blob_storage"`
+	AzureEventHub     AzureEventHubConfig          `json:"azure_event_hub" yaml:"azure_event_hub"`
 	AzureQueueStorage AzureQueueStorageConfig      `json:"azure_queue_storage" yaml:"azure_queue_storage"`
 	Bloblang          BloblangConfig               `json:"bloblang" yaml:"bloblang"`
 	Broker            BrokerConfig                 `json:"broker" yaml:"broker"`
@@ -326,6 +328,7 @@ func NewConfig() Config {
 		AWSS3:             NewAWSS3Config(),
 		AWSSQS:            NewAWSSQSConfig(),
 		AzureBlobStorage:  NewAzureBlobStorageConfig(),
+		AzureEventHub:     NewAzureEventHubConfig(),
 		AzureQueueStorage: NewAzureQueueStorageConfig(),
 		Bloblang:          NewBloblangConfig(),
 		Broker:            NewBrokerConfig(),
@@ -42,7 +42,13 @@ To GET a JSON map of input identifiers with their current uptimes use the
 To perform CRUD actions on the inputs themselves use POST, DELETE, and GET
 methods on the ` + "`/inputs/{input_id}`" + ` endpoint. When using POST the body
 of the request should be a YAML configuration for the input, if the input
-already exists it will be changed.`,
+already exists it will be changed.
+
+When an input is changed or removed it first stops consuming new messages, and
+is only closed once all messages it has already consumed have been either
+delivered or rejected, allowing it to acknowledge them. If this takes longer
+than the ` + "`timeout`" + ` the request fails and the input remains draining until a
+subsequent request succeeds.`,
 		Categories: []Category{
 			CategoryUtility,
 		},
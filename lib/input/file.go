@@ -34,8 +34,13 @@ Consumes data from files on disk, emitting messages according to a chosen codec.
 			docs.FieldDeprecated("delimiter"),
 			docs.FieldDeprecated("multipart"),
 			docs.FieldAdvanced("delete_on_finish", "Whether to delete consumed files from the disk once they are fully consumed."),
+			fileTailSpec(),
 		},
 		Description: `
+### Tailing
+
+With ` + "`tail.enabled`" + ` set to ` + "`true`" + ` files are followed as they are written to, in which case files that match the ` + "`paths`" + ` and are created later are also followed. A file that is replaced, such as when it is rotated, is read to its end before the new file is followed, and a file that is truncated is followed from the beginning. When ` + "`tail.offsets_path`" + ` is set the offsets of acknowledged messages are stored so that a restarted instance resumes where it left off, in which case messages that were in flight are consumed again.
+
 ### Metadata
 
 This input adds the following metadata fields to each message:
@@ -68,13 +73,14 @@ input:
 
 // FileConfig contains configuration values for the File input type.
 type FileConfig struct {
-	Path           string   `json:"path" yaml:"path"`
-	Paths          []string `json:"paths" yaml:"paths"`
-	Codec          string   `json:"codec" yaml:"codec"`
-	Multipart      bool     `json:"multipart" yaml:"multipart"`
-	MaxBuffer      int      `json:"max_buffer" yaml:"max_buffer"`
-	Delim          string   `json:"delimiter" yaml:"delimiter"`
-	DeleteOnFinish bool     `json:"delete_on_finish" yaml:"delete_on_finish"`
+	Path           string         `json:"path" yaml:"path"`
+	Paths          []string       `json:"paths" yaml:"paths"`
+	Codec          string         `json:"codec" yaml:"codec"`
+	Multipart      bool           `json:"multipart" yaml:"multipart"`
+	MaxBuffer      int            `json:"max_buffer" yaml:"max_buffer"`
+	Delim          string         `json:"delimiter" yaml:"delimiter"`
+	DeleteOnFinish bool           `json:"delete_on_finish" yaml:"delete_on_finish"`
+	Tail           FileTailConfig `json:"tail" yaml:"tail"`
 }
 
 // NewFileConfig creates a new FileConfig with default values.
@@ -88,6 +94,7 @@ func NewFileConfig() FileConfig {
 		MaxBuffer:      1000000,
 		Delim:          "",
 		DeleteOnFinish: false,
+		Tail:           NewFileTailConfig(),
 	}
 }
 
@@ -104,6 +111,13 @@ func NewFile(conf Config, mgr types.Manager, log log.Modular, stats metrics.Type
 	if conf.File.Multipart && !strings.HasSuffix(conf.File.Codec, "/multipart") {
 		conf.File.Codec += "/multipart"
 	}
+	if conf.File.Tail.Enabled {
+		rdr, err := newFileTailer(conf.File, log)
+		if err != nil {
+			return nil, err
+		}
+		return NewAsyncReader(TypeFile, true, reader.NewAsyncPreserver(rdr), log, stats)
+	}
 	rdr, err := newFileConsumer(conf.File, log)
 	if err != nil {
 		return nil, err
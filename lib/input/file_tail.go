@@ -0,0 +1,453 @@
+package input
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/checkpoint"
+	"github.com/Jeffail/benthos/v3/internal/docs"
+	ifilepath "github.com/Jeffail/benthos/v3/internal/filepath"
+	"github.com/Jeffail/benthos/v3/lib/input/reader"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/types"
+)
+
+//------------------------------------------------------------------------------
+
+// FileTailConfig contains configuration fields for following files as they are
+// written to.
+type FileTailConfig struct {
+	Enabled          bool   `json:"enabled" yaml:"enabled"`
+	PollInterval     string `json:"poll_interval" yaml:"poll_interval"`
+	OffsetsPath      string `json:"offsets_path" yaml:"offsets_path"`
+	MultilinePattern string `json:"multiline_pattern" yaml:"multiline_pattern"`
+	MultilineTimeout string `json:"multiline_timeout" yaml:"multiline_timeout"`
+}
+
+// NewFileTailConfig creates a new FileTailConfig with default values.
+func NewFileTailConfig() FileTailConfig {
+	return FileTailConfig{
+		Enabled:          false,
+		PollInterval:     "1s",
+		OffsetsPath:      "",
+		MultilinePattern: "",
+		MultilineTimeout: "1s",
+	}
+}
+
+func fileTailSpec() docs.FieldSpec {
+	return docs.FieldAdvanced("tail", "Follows files as they are written to rather than consuming them once, in which case the input never finishes. Only the `lines` and `delim` codecs are supported in this mode.").WithChildren(
+		docs.FieldBool("enabled", "Whether to follow files.").HasDefault(false),
+		docs.FieldString("poll_interval", "The period to wait before checking for new data, and for new files matching the `paths`, once all files have been read to their end.").HasDefault("1s"),
+		docs.FieldString("offsets_path", "An optional path of a file where the offsets of acknowledged messages are stored, allowing the input to resume from where it left off after a restart. When empty files are consumed from the beginning after each restart.", "./offsets.json").HasDefault(""),
+		docs.FieldString("multiline_pattern", "An optional regular expression that matches the first line of a record, where the lines that follow which do not match are joined to it, such as the lines of a stack trace.", `^\d{4}-\d{2}-\d{2}`).HasDefault(""),
+		docs.FieldString("multiline_timeout", "The period after which a record that is joining lines is emitted when no more lines are written.").HasDefault("1s"),
+	).AtVersion("3.54.0")
+}
+
+//------------------------------------------------------------------------------
+
+// tailFile is a file that is followed, where offset is the position within the
+// file of the first byte of buf, which contains data read but not yet emitted.
+type tailFile struct {
+	path string
+	file *os.File
+	info os.FileInfo
+	gen  int
+
+	offset int64
+	buf    []byte
+	eof    bool
+
+	pending      []byte
+	pendingEnd   int64
+	pendingSince time.Time
+
+	checkpointer *checkpoint.Type
+}
+
+type fileTailer struct {
+	log log.Modular
+
+	patterns  []string
+	delim     []byte
+	poll      time.Duration
+	offsets   string
+	multiline *regexp.Regexp
+	mlTimeout time.Duration
+
+	mut       sync.Mutex
+	files     map[string]*tailFile
+	order     []string
+	next      int
+	gens      map[string]int
+	committed map[string]int64
+	dirty     bool
+	closed    bool
+}
+
+func newFileTailer(conf FileConfig, log log.Modular) (*fileTailer, error) {
+	if conf.DeleteOnFinish {
+		return nil, errors.New("delete_on_finish cannot be used when tailing files")
+	}
+	t := &fileTailer{
+		log:       log,
+		patterns:  conf.Paths,
+		offsets:   conf.Tail.OffsetsPath,
+		files:     map[string]*tailFile{},
+		gens:      map[string]int{},
+		committed: map[string]int64{},
+	}
+	switch {
+	case conf.Codec == "lines":
+		t.delim = []byte("\n")
+	case strings.HasPrefix(conf.Codec, "delim:"):
+		t.delim = []byte(strings.TrimPrefix(conf.Codec, "delim:"))
+	default:
+		return nil, fmt.Errorf("codec %v is not supported when tailing files", conf.Codec)
+	}
+	if len(t.delim) == 0 {
+		return nil, errors.New("a delimiter must be specified when tailing files")
+	}
+
+	var err error
+	if t.poll, err = time.ParseDuration(conf.Tail.PollInterval); err != nil {
+		return nil, fmt.Errorf("failed to parse poll interval: %w", err)
+	}
+	if conf.Tail.MultilinePattern != "" {
+		if t.multiline, err = regexp.Compile(conf.Tail.MultilinePattern); err != nil {
+			return nil, fmt.Errorf("failed to compile multiline pattern: %w", err)
+		}
+		if t.mlTimeout, err = time.ParseDuration(conf.Tail.MultilineTimeout); err != nil {
+			return nil, fmt.Errorf("failed to parse multiline timeout: %w", err)
+		}
+	}
+	if t.offsets != "" {
+		b, err := ioutil.ReadFile(t.offsets)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read offsets: %w", err)
+		}
+		if len(b) > 0 {
+			if err = json.Unmarshal(b, &t.committed); err != nil {
+				return nil, fmt.Errorf("failed to parse offsets: %w", err)
+			}
+		}
+	}
+	return t, nil
+}
+
+// ConnectWithContext checks that the paths can be expanded.
+func (t *fileTailer) ConnectWithContext(ctx context.Context) error {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	if t.closed {
+		return types.ErrTypeClosed
+	}
+	return t.discover()
+}
+
+// discover opens any files matching the patterns that are not already being
+// followed. The lock must be held by the caller.
+func (t *fileTailer) discover() error {
+	paths, err := ifilepath.Globs(t.patterns)
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		if _, exists := t.files[p]; exists {
+			continue
+		}
+		f, err := t.open(p, t.committed[p])
+		if err != nil {
+			t.log.Errorf("Failed to open file '%v': %v\n", p, err)
+			continue
+		}
+		t.files[p] = f
+		t.order = append(t.order, p)
+		t.log.Infof("Following file '%v' from offset %v\n", p, f.offset)
+	}
+	return nil
+}
+
+func (t *fileTailer) open(path string, offset int64) (*tailFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, errors.New("path is a directory")
+	}
+
+	// When the file is smaller than the stored offset it has been truncated or
+	// replaced since, and is therefore consumed from the beginning.
+	if offset > info.Size() {
+		offset = 0
+	}
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	t.gens[path]++
+	return &tailFile{
+		path:         path,
+		file:         file,
+		info:         info,
+		gen:          t.gens[path],
+		offset:       offset,
+		checkpointer: checkpoint.New(),
+	}, nil
+}
+
+// readLine returns the next complete line of a file and the offset of the end
+// of it, reading more of the file when necessary.
+func (t *fileTailer) readLine(f *tailFile) ([]byte, int64, bool) {
+	for {
+		if i := bytes.Index(f.buf, t.delim); i >= 0 {
+			line := f.buf[:i]
+			end := f.offset + int64(i+len(t.delim))
+			f.buf = f.buf[i+len(t.delim):]
+			f.offset = end
+			return line, end, true
+		}
+		if f.eof {
+			return nil, 0, false
+		}
+		chunk := make([]byte, 64*1024)
+		n, err := f.file.Read(chunk)
+		f.buf = append(f.buf, chunk[:n]...)
+		if err != nil || n == 0 {
+			f.eof = true
+		}
+	}
+}
+
+// nextRecord returns the next record of a file, joining lines when a multiline
+// pattern is configured.
+func (t *fileTailer) nextRecord(f *tailFile) ([]byte, int64, bool) {
+	for {
+		line, end, ok := t.readLine(f)
+		if !ok {
+			if len(f.pending) > 0 && time.Since(f.pendingSince) >= t.mlTimeout {
+				return t.flushPending(f)
+			}
+			return nil, 0, false
+		}
+		if t.multiline == nil {
+			return line, end, true
+		}
+		if len(f.pending) > 0 && t.multiline.Match(line) {
+			record, recordEnd, _ := t.flushPending(f)
+			f.pending, f.pendingEnd, f.pendingSince = append([]byte(nil), line...), end, time.Now()
+			return record, recordEnd, true
+		}
+		if len(f.pending) == 0 {
+			f.pendingSince = time.Now()
+		} else {
+			f.pending = append(f.pending, t.delim...)
+		}
+		f.pending = append(f.pending, line...)
+		f.pendingEnd = end
+	}
+}
+
+func (t *fileTailer) flushPending(f *tailFile) ([]byte, int64, bool) {
+	record, end := f.pending, f.pendingEnd
+	f.pending = nil
+	return record, end, true
+}
+
+// checkReplaced reopens a file that has been read to its end when the path now
+// refers to a different file, or the file has been truncated. The remainder of
+// a replaced file without a trailing delimiter is returned as a final record.
+func (t *fileTailer) checkReplaced(f *tailFile) (*tailFile, []byte, int64) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return f, nil, 0
+	}
+	replaced := !os.SameFile(info, f.info)
+	if !replaced && info.Size() >= f.offset+int64(len(f.buf)) {
+		f.eof = false
+		return f, nil, 0
+	}
+
+	var remainder []byte
+	var end int64
+	if replaced && len(f.buf) > 0 {
+		remainder, end = f.buf, f.offset+int64(len(f.buf))
+	}
+	if len(f.pending) > 0 {
+		if remainder != nil {
+			remainder = append(append(f.pending, t.delim...), remainder...)
+		} else {
+			remainder, end = f.pending, f.pendingEnd
+		}
+	}
+	f.file.Close()
+
+	nf, err := t.open(f.path, 0)
+	if err != nil {
+		t.log.Errorf("Failed to reopen file '%v': %v\n", f.path, err)
+		return f, nil, 0
+	}
+	if replaced {
+		t.log.Infof("File '%v' was replaced, following the new file\n", f.path)
+	} else {
+		t.log.Infof("File '%v' was truncated, following from the beginning\n", f.path)
+	}
+	t.committed[f.path] = 0
+	t.dirty = true
+	t.files[f.path] = nf
+
+	if remainder == nil {
+		return nf, nil, 0
+	}
+	return f, remainder, end
+}
+
+func (t *fileTailer) ack(f *tailFile, end int64) func(context.Context, types.Response) error {
+	resolve := f.checkpointer.Track(end, 1)
+	return func(ctx context.Context, res types.Response) error {
+		if res.Error() != nil {
+			return nil
+		}
+		t.mut.Lock()
+		t.commit(f, resolve)
+		if t.closed {
+			t.flushOffsets()
+		}
+		t.mut.Unlock()
+		return nil
+	}
+}
+
+// commit resolves the checkpoint of a record, storing the offset of the latest
+// record of the file for which all prior records are also resolved. The lock
+// must be held by the caller.
+func (t *fileTailer) commit(f *tailFile, resolve func() interface{}) {
+	offset, _ := resolve().(int64)
+	if t.gens[f.path] == f.gen && offset > t.committed[f.path] {
+		t.committed[f.path] = offset
+		t.dirty = true
+	}
+}
+
+// flushOffsets writes the offsets of acknowledged messages. The lock must be
+// held by the caller.
+func (t *fileTailer) flushOffsets() {
+	if t.offsets == "" || !t.dirty {
+		return
+	}
+	b, err := json.Marshal(t.committed)
+	if err == nil {
+		tmp := filepath.Join(filepath.Dir(t.offsets), "."+filepath.Base(t.offsets)+".tmp")
+		if err = ioutil.WriteFile(tmp, b, 0644); err == nil {
+			err = os.Rename(tmp, t.offsets)
+		}
+	}
+	if err != nil {
+		t.log.Errorf("Failed to write offsets: %v\n", err)
+		return
+	}
+	t.dirty = false
+}
+
+func (t *fileTailer) tryRead() (types.Message, reader.AsyncAckFn, bool) {
+	for i := 0; i < len(t.order); i++ {
+		f := t.files[t.order[t.next%len(t.order)]]
+		t.next++
+
+		record, end, ok := t.nextRecord(f)
+		if !ok {
+			nf, remainder, rEnd := t.checkReplaced(f)
+			if remainder != nil {
+				record, end, ok = remainder, rEnd, true
+			} else {
+				f = nf
+				record, end, ok = t.nextRecord(f)
+			}
+		}
+		if !ok {
+			continue
+		}
+		if len(record) == 0 {
+			// Empty records are skipped, but their offsets are still
+			// committed once all prior records are acknowledged.
+			t.commit(f, f.checkpointer.Track(end, 1))
+			i--
+			continue
+		}
+
+		part := message.NewPart(append([]byte(nil), record...))
+		part.Metadata().Set("path", f.path)
+		msg := message.New(nil)
+		msg.Append(part)
+		return msg, t.ack(f, end), true
+	}
+	return nil, nil, false
+}
+
+// ReadWithContext reads the next record from any of the followed files, waiting
+// for the poll interval when there is nothing to read.
+func (t *fileTailer) ReadWithContext(ctx context.Context) (types.Message, reader.AsyncAckFn, error) {
+	t.mut.Lock()
+	if t.closed {
+		t.mut.Unlock()
+		return nil, nil, types.ErrTypeClosed
+	}
+	t.flushOffsets()
+	msg, ackFn, ok := t.tryRead()
+	if !ok {
+		if err := t.discover(); err != nil {
+			t.log.Errorf("Failed to expand paths: %v\n", err)
+		}
+	}
+	t.mut.Unlock()
+
+	if ok {
+		return msg, ackFn, nil
+	}
+	select {
+	case <-time.After(t.poll):
+	case <-ctx.Done():
+	}
+	return nil, nil, types.ErrTimeout
+}
+
+// CloseAsync closes all followed files and writes the offsets of acknowledged
+// messages.
+func (t *fileTailer) CloseAsync() {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	t.flushOffsets()
+	for _, f := range t.files {
+		f.file.Close()
+	}
+}
+
+// WaitForClose will block until either the reader is closed or a specified
+// timeout occurs.
+func (t *fileTailer) WaitForClose(time.Duration) error {
+	return nil
+}
@@ -0,0 +1,188 @@
+package input
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/input/reader"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func appendFile(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func newTestTailer(t *testing.T, conf FileConfig) *fileTailer {
+	t.Helper()
+	conf.Tail.Enabled = true
+	conf.Tail.PollInterval = "10ms"
+	tailer, err := newFileTailer(conf, log.Noop())
+	require.NoError(t, err)
+	require.NoError(t, tailer.ConnectWithContext(context.Background()))
+	return tailer
+}
+
+func readTail(t *testing.T, tailer *fileTailer) (string, string, reader.AsyncAckFn) {
+	t.Helper()
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+	for {
+		msg, ackFn, err := tailer.ReadWithContext(ctx)
+		if err == types.ErrTimeout {
+			require.NoError(t, ctx.Err(), "timed out waiting for message")
+			continue
+		}
+		require.NoError(t, err)
+		return string(msg.Get(0).Get()), msg.Get(0).Metadata().Get("path"), ackFn
+	}
+}
+
+func readTailAck(t *testing.T, tailer *fileTailer) string {
+	t.Helper()
+	content, _, ackFn := readTail(t, tailer)
+	require.NoError(t, ackFn(context.Background(), response.NewAck()))
+	return content
+}
+
+func assertNoTail(t *testing.T, tailer *fileTailer) {
+	t.Helper()
+	_, _, err := tailer.ReadWithContext(context.Background())
+	assert.Equal(t, types.ErrTimeout, err)
+}
+
+func TestFileTailFollow(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.log")
+	appendFile(t, path, "foo\nbar\n\nbaz")
+
+	conf := NewFileConfig()
+	conf.Paths = []string{filepath.Join(dir, "*.log")}
+	tailer := newTestTailer(t, conf)
+	defer tailer.CloseAsync()
+
+	content, metaPath, _ := readTail(t, tailer)
+	assert.Equal(t, "foo", content)
+	assert.Equal(t, path, metaPath)
+	assert.Equal(t, "bar", readTailAck(t, tailer))
+	assertNoTail(t, tailer)
+
+	// The incomplete line is emitted once it is complete.
+	appendFile(t, path, "\nqux\n")
+	assert.Equal(t, "baz", readTailAck(t, tailer))
+	assert.Equal(t, "qux", readTailAck(t, tailer))
+
+	// Files created later are followed.
+	appendFile(t, filepath.Join(dir, "b.log"), "quz\n")
+	assert.Equal(t, "quz", readTailAck(t, tailer))
+}
+
+func TestFileTailOffsets(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.log")
+	appendFile(t, path, "foo\nbar\nbaz\n")
+
+	conf := NewFileConfig()
+	conf.Paths = []string{path}
+	conf.Tail.OffsetsPath = filepath.Join(dir, "offsets.json")
+
+	tailer := newTestTailer(t, conf)
+	assert.Equal(t, "foo", readTailAck(t, tailer))
+
+	// The second message is not acknowledged and therefore neither is the
+	// third.
+	_, _, ackBar := readTail(t, tailer)
+	assert.Equal(t, "baz", readTailAck(t, tailer))
+	tailer.CloseAsync()
+
+	b, err := ioutil.ReadFile(conf.Tail.OffsetsPath)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"`+path+`":4}`, string(b))
+
+	tailer = newTestTailer(t, conf)
+	content, _, _ := readTail(t, tailer)
+	assert.Equal(t, "bar", content)
+	content, _, _ = readTail(t, tailer)
+	assert.Equal(t, "baz", content)
+	tailer.CloseAsync()
+
+	// Acknowledgements of closed tailers are still stored.
+	require.NoError(t, ackBar(context.Background(), response.NewAck()))
+
+	tailer = newTestTailer(t, conf)
+	defer tailer.CloseAsync()
+	assertNoTail(t, tailer)
+}
+
+func TestFileTailRotateAndTruncate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.log")
+	appendFile(t, path, "foo\n")
+
+	conf := NewFileConfig()
+	conf.Paths = []string{path}
+	tailer := newTestTailer(t, conf)
+	defer tailer.CloseAsync()
+
+	assert.Equal(t, "foo", readTailAck(t, tailer))
+
+	// The remainder of a rotated file is read before the new file.
+	appendFile(t, path, "bar\nbaz")
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendFile(t, path, "qux\n")
+
+	assert.Equal(t, "bar", readTailAck(t, tailer))
+	assert.Equal(t, "baz", readTailAck(t, tailer))
+	assert.Equal(t, "qux", readTailAck(t, tailer))
+
+	// A truncated file is read from the beginning.
+	require.NoError(t, os.Truncate(path, 0))
+	assertNoTail(t, tailer)
+	appendFile(t, path, "quz\n")
+	assert.Equal(t, "quz", readTailAck(t, tailer))
+}
+
+func TestFileTailMultiline(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.log")
+	appendFile(t, path, "2021-01-01 foo\n  at bar\n  at baz\n2021-01-02 qux\n2021-01-03 quz\n  at")
+
+	conf := NewFileConfig()
+	conf.Paths = []string{path}
+	conf.Tail.MultilinePattern = `^\d{4}-\d{2}-\d{2}`
+	conf.Tail.MultilineTimeout = "500ms"
+	tailer := newTestTailer(t, conf)
+	defer tailer.CloseAsync()
+
+	assert.Equal(t, "2021-01-01 foo\n  at bar\n  at baz", readTailAck(t, tailer))
+	assert.Equal(t, "2021-01-02 qux", readTailAck(t, tailer))
+
+	// The last record is emitted once no more lines are written within the
+	// timeout.
+	appendFile(t, path, " bar\n")
+	assert.Equal(t, "2021-01-03 quz\n  at bar", readTailAck(t, tailer))
+}
+
+func TestFileTailBadConfig(t *testing.T) {
+	conf := NewFileConfig()
+	conf.Codec = "all-bytes"
+	_, err := newFileTailer(conf, log.Noop())
+	assert.Error(t, err)
+
+	conf = NewFileConfig()
+	conf.DeleteOnFinish = true
+	_, err = newFileTailer(conf, log.Noop())
+	assert.Error(t, err)
+}
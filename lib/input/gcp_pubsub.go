@@ -26,6 +26,7 @@ This input adds the following metadata fields to each message:
 
 ` + "``` text" + `
 - gcp_pubsub_publish_time_unix
+- gcp_pubsub_ordering_key (when set)
 - All message attributes
 ` + "```" + `
 
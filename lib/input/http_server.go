@@ -16,6 +16,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Jeffail/benthos/v3/internal/binjson"
 	"github.com/Jeffail/benthos/v3/internal/bloblang"
 	"github.com/Jeffail/benthos/v3/internal/bloblang/field"
 	"github.com/Jeffail/benthos/v3/internal/docs"
@@ -23,6 +24,7 @@ import (
 	"github.com/Jeffail/benthos/v3/internal/shutdown"
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/message"
+	mio "github.com/Jeffail/benthos/v3/lib/message/io"
 	"github.com/Jeffail/benthos/v3/lib/message/metadata"
 	"github.com/Jeffail/benthos/v3/lib/message/roundtrip"
 	"github.com/Jeffail/benthos/v3/lib/message/tracing"
@@ -63,6 +65,17 @@ customise headers with the ` + "`sync_response` field `headers`" + `, which can
 also use [function interpolation](/docs/configuration/interpolation#bloblang-queries)
 in the value based on the response message contents.
 
+### Signature Verification
+
+Requests to the ` + "`path`" + ` endpoint can be required to carry a valid HMAC-SHA256 signature of their body by setting the ` + "`signature` field `style`" + `, in which case requests with a missing or invalid signature are rejected with a 401 response before they are consumed. The following styles are supported:
+
+- ` + "`github`" + `: The ` + "`X-Hub-Signature-256`" + ` header of GitHub webhooks.
+- ` + "`stripe`" + `: The ` + "`Stripe-Signature`" + ` header of Stripe webhooks, which also includes a timestamp.
+- ` + "`slack`" + `: The ` + "`X-Slack-Signature` and `X-Slack-Request-Timestamp`" + ` headers of Slack requests.
+- ` + "`hmac_sha256`" + `: A hex encoded signature, optionally prefixed with ` + "`sha256=`" + `, within the header specified by the field ` + "`header`" + `.
+
+For the styles that include a timestamp requests signed longer ago than the ` + "`tolerance`" + ` period are also rejected in order to prevent replay attacks.
+
 ### Endpoints
 
 The following fields specify endpoints that are registered for sending messages, and support path parameters of the form ` + "`/{foo}`" + `, which are added to ingested messages as metadata:
@@ -77,6 +90,17 @@ If the request contains a multipart ` + "`content-type`" + ` header as per
 multiple parts are consumed as a batch of messages, where each body part is a
 message of the batch.
 
+Requests with a content type of ` + "`application/x-benthos-wire`" + ` are consumed
+as messages sent by the ` + "`http_client`" + ` output of another Benthos instance
+with ` + "`wire_format`" + ` enabled, where the parts and metadata of each message
+are preserved.
+
+When ` + "`decode_binary_formats`" + ` is enabled, request bodies (and body parts)
+with a content type of ` + "`application/msgpack`" + ` (or the aliases
+` + "`application/x-msgpack`" + ` and ` + "`application/vnd.msgpack`" + `) or
+` + "`application/cbor`" + ` are decoded into JSON documents before being consumed.
+Responses are not converted into these formats.
+
 #### ` + "`ws_path` (defaults to `/post/ws`)" + `
 
 Creates a websocket connection, where payloads received on the socket are passed
@@ -97,12 +121,19 @@ This input adds the following metadata fields to each message:
 - http_server_user_agent
 - http_server_request_path
 - http_server_verb
+- http_server_content_type
 - All headers (only first values are taken)
 - All query parameters
 - All path parameters
 - All cookies
 ` + "```" + `
 
+The field ` + "`http_server_content_type`" + ` contains the media type of the
+message without parameters, e.g. ` + "`application/json`" + `, which for multipart
+requests is the media type of each individual part. Payloads that are decoded
+with ` + "`decode_binary`" + ` have the media type ` + "`application/json`" + `. This allows
+messages to be routed by their type without parsing the payload.
+
 You can access these metadata fields using
 [function interpolation](/docs/configuration/interpolation#metadata).`,
 		FieldSpecs: docs.FieldSpecs{
@@ -116,7 +147,18 @@ You can access these metadata fields using
 			docs.FieldCommon("rate_limit", "An optional [rate limit](/docs/components/rate_limits/about) to throttle requests by."),
 			docs.FieldAdvanced("cert_file", "Only valid with a custom `address`."),
 			docs.FieldAdvanced("key_file", "Only valid with a custom `address`."),
+			docs.FieldAdvanced("signature", "Require requests to the `path` endpoint to carry a valid HMAC signature of their body.").WithChildren(
+				docs.FieldCommon("style", "The style of signature to verify. When empty signatures are not verified.").HasOptions("", "github", "stripe", "slack", "hmac_sha256"),
+				docs.FieldCommon("secret", "The secret shared with the sender that signatures are computed with."),
+				docs.FieldCommon("header", "The header containing the signature, which is only used by the `hmac_sha256` style.", "X-Signature"),
+				docs.FieldAdvanced("tolerance", "The maximum age of the timestamp of signatures that include one. Set to an empty string in order to accept any timestamp."),
+			).AtVersion("3.54.0"),
+			docs.FieldAdvanced("decode_binary_formats", "Whether to decode request bodies with a content type of `application/msgpack`, `application/x-msgpack`, `application/vnd.msgpack` or `application/cbor` into JSON documents. Only request bodies are decoded, responses are not negotiated and are sent as they are regardless of the `Accept` header.").AtVersion("3.54.0"),
 			docs.FieldAdvanced("sync_response", "Customise messages returned via [synchronous responses](/docs/guides/sync_responses).").WithChildren(
+				docs.FieldAdvanced("mode", "Determines when a response is returned to the client.").HasAnnotatedOptions(
+					"ack", "Respond once the message has been acknowledged by all outputs, with any responses added by `sync_response` processors or outputs.",
+					"first_result", "Respond as soon as a result is available, which is either a response added by a `sync_response` processor, or the message as it was delivered by the first output to acknowledge it. Errors from outputs that acknowledge the message afterwards are not reported to the client.",
+				).AtVersion("3.54.0"),
 				docs.FieldCommon(
 					"status",
 					"Specify the status code to return with synchronous responses. This is a string value, which allows you to customize it based on resulting payloads and their metadata.",
@@ -138,6 +180,7 @@ You can access these metadata fields using
 // HTTPServerResponseConfig provides config fields for customising the response
 // given from successful requests.
 type HTTPServerResponseConfig struct {
+	Mode    string            `json:"mode" yaml:"mode"`
 	Status  string            `json:"status" yaml:"status"`
 	Headers map[string]string `json:"headers" yaml:"headers"`
 }
@@ -145,6 +188,7 @@ type HTTPServerResponseConfig struct {
 // NewHTTPServerResponseConfig creates a new HTTPServerConfig with default values.
 func NewHTTPServerResponseConfig() HTTPServerResponseConfig {
 	return HTTPServerResponseConfig{
+		Mode:   "ack",
 		Status: "200",
 		Headers: map[string]string{
 			"Content-Type": "application/octet-stream",
@@ -154,17 +198,19 @@ func NewHTTPServerResponseConfig() HTTPServerResponseConfig {
 
 // HTTPServerConfig contains configuration for the HTTPServer input type.
 type HTTPServerConfig struct {
-	Address            string                   `json:"address" yaml:"address"`
-	Path               string                   `json:"path" yaml:"path"`
-	WSPath             string                   `json:"ws_path" yaml:"ws_path"`
-	WSWelcomeMessage   string                   `json:"ws_welcome_message" yaml:"ws_welcome_message"`
-	WSRateLimitMessage string                   `json:"ws_rate_limit_message" yaml:"ws_rate_limit_message"`
-	AllowedVerbs       []string                 `json:"allowed_verbs" yaml:"allowed_verbs"`
-	Timeout            string                   `json:"timeout" yaml:"timeout"`
-	RateLimit          string                   `json:"rate_limit" yaml:"rate_limit"`
-	CertFile           string                   `json:"cert_file" yaml:"cert_file"`
-	KeyFile            string                   `json:"key_file" yaml:"key_file"`
-	Response           HTTPServerResponseConfig `json:"sync_response" yaml:"sync_response"`
+	Address            string                    `json:"address" yaml:"address"`
+	Path               string                    `json:"path" yaml:"path"`
+	WSPath             string                    `json:"ws_path" yaml:"ws_path"`
+	WSWelcomeMessage   string                    `json:"ws_welcome_message" yaml:"ws_welcome_message"`
+	WSRateLimitMessage string                    `json:"ws_rate_limit_message" yaml:"ws_rate_limit_message"`
+	AllowedVerbs       []string                  `json:"allowed_verbs" yaml:"allowed_verbs"`
+	Timeout            string                    `json:"timeout" yaml:"timeout"`
+	RateLimit          string                    `json:"rate_limit" yaml:"rate_limit"`
+	CertFile           string                    `json:"cert_file" yaml:"cert_file"`
+	KeyFile            string                    `json:"key_file" yaml:"key_file"`
+	Signature          HTTPServerSignatureConfig `json:"signature" yaml:"signature"`
+	DecodeBinary       bool                      `json:"decode_binary_formats" yaml:"decode_binary_formats"`
+	Response           HTTPServerResponseConfig  `json:"sync_response" yaml:"sync_response"`
 }
 
 // NewHTTPServerConfig creates a new HTTPServerConfig with default values.
@@ -178,11 +224,13 @@ func NewHTTPServerConfig() HTTPServerConfig {
 		AllowedVerbs: []string{
 			"POST",
 		},
-		Timeout:   "5s",
-		RateLimit: "",
-		CertFile:  "",
-		KeyFile:   "",
-		Response:  NewHTTPServerResponseConfig(),
+		Timeout:      "5s",
+		RateLimit:    "",
+		CertFile:     "",
+		KeyFile:      "",
+		Signature:    NewHTTPServerSignatureConfig(),
+		DecodeBinary: false,
+		Response:     NewHTTPServerResponseConfig(),
 	}
 }
 
@@ -212,11 +260,13 @@ type HTTPServer struct {
 	shutSig *shutdown.Signaller
 
 	allowedVerbs map[string]struct{}
+	verifySig    signatureVerifier
 
 	// TODO: V4 Reduce this way down
 	mCount         metrics.StatCounter
 	mLatency       metrics.StatTimer
 	mRateLimited   metrics.StatCounter
+	mSigInvalid    metrics.StatCounter
 	mWSRateLimited metrics.StatCounter
 	mRcvd          metrics.StatCounter
 	mPartsRcvd     metrics.StatCounter
@@ -273,6 +323,7 @@ func NewHTTPServer(conf Config, mgr types.Manager, log log.Modular, stats metric
 		mCount:         stats.GetCounter("count"),
 		mLatency:       stats.GetTimer("latency"),
 		mRateLimited:   stats.GetCounter("rate_limited"),
+		mSigInvalid:    stats.GetCounter("signature.invalid"),
 		mWSRateLimited: stats.GetCounter("ws.rate_limited"),
 		mRcvd:          stats.GetCounter("batch.received"),
 		mPartsRcvd:     stats.GetCounter("received"),
@@ -287,6 +338,14 @@ func NewHTTPServer(conf Config, mgr types.Manager, log log.Modular, stats metric
 	}
 
 	var err error
+	if h.verifySig, err = newSignatureVerifier(h.conf.Signature); err != nil {
+		return nil, err
+	}
+	switch h.conf.Response.Mode {
+	case "", "ack", "first_result":
+	default:
+		return nil, fmt.Errorf("sync response mode not recognised: %v", h.conf.Response.Mode)
+	}
 	if h.responseStatus, err = bloblang.NewField(h.conf.Response.Status); err != nil {
 		return nil, fmt.Errorf("failed to parse response status expression: %v", err)
 	}
@@ -330,8 +389,26 @@ func NewHTTPServer(conf Config, mgr types.Manager, log log.Modular, stats metric
 
 //------------------------------------------------------------------------------
 
+// decodeBinary converts a payload into JSON when binary formats are decoded and
+// the media type is of a supported binary format, and returns the media type of
+// the resulting payload.
+func (h *HTTPServer) decodeBinary(mediaType string, b []byte) ([]byte, string, error) {
+	if !h.conf.DecodeBinary {
+		return b, mediaType, nil
+	}
+	format, ok := binjson.FormatFromContentType(mediaType)
+	if !ok {
+		return b, mediaType, nil
+	}
+	jBytes, err := binjson.ToJSON(format, b)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode %v body: %w", format, err)
+	}
+	return jBytes, "application/json", nil
+}
+
 func (h *HTTPServer) extractMessageFromRequest(r *http.Request) (types.Message, error) {
-	msg := message.New(nil)
+	var msg types.Message = message.New(nil)
 
 	contentType := r.Header.Get("Content-Type")
 	if contentType == "" {
@@ -343,7 +420,20 @@ func (h *HTTPServer) extractMessageFromRequest(r *http.Request) (types.Message,
 		return nil, err
 	}
 
-	if strings.HasPrefix(mediaType, "multipart/") {
+	// The media type of each part, which is the media type of the request
+	// unless it is multipart.
+	var partMediaTypes []string
+
+	isWire := mediaType == mio.WireContentType
+	if isWire {
+		var msgBytes []byte
+		if msgBytes, err = ioutil.ReadAll(r.Body); err != nil {
+			return nil, err
+		}
+		if msg, err = mio.MessageFromWire(msgBytes); err != nil {
+			return nil, err
+		}
+	} else if strings.HasPrefix(mediaType, "multipart/") {
 		mr := multipart.NewReader(r.Body, params["boundary"])
 		for {
 			var p *multipart.Part
@@ -357,14 +447,30 @@ func (h *HTTPServer) extractMessageFromRequest(r *http.Request) (types.Message,
 			if msgBytes, err = ioutil.ReadAll(p); err != nil {
 				return nil, err
 			}
+
+			partMediaType := "text/plain"
+			if partContentType := p.Header.Get("Content-Type"); partContentType != "" {
+				if partMediaType, _, err = mime.ParseMediaType(partContentType); err != nil {
+					return nil, err
+				}
+			}
+			if msgBytes, partMediaType, err = h.decodeBinary(partMediaType, msgBytes); err != nil {
+				return nil, err
+			}
 			msg.Append(message.NewPart(msgBytes))
+			partMediaTypes = append(partMediaTypes, partMediaType)
 		}
 	} else {
 		var msgBytes []byte
 		if msgBytes, err = ioutil.ReadAll(r.Body); err != nil {
 			return nil, err
 		}
+		var partMediaType string
+		if msgBytes, partMediaType, err = h.decodeBinary(mediaType, msgBytes); err != nil {
+			return nil, err
+		}
 		msg.Append(message.NewPart(msgBytes))
+		partMediaTypes = append(partMediaTypes, partMediaType)
 	}
 
 	meta := metadata.New(nil)
@@ -387,7 +493,30 @@ func (h *HTTPServer) extractMessageFromRequest(r *http.Request) (types.Message,
 	for _, c := range r.Cookies() {
 		meta.Set(c.Name, c.Value)
 	}
-	message.SetAllMetadata(msg, meta)
+	if isWire {
+		// Metadata carried by the message itself takes precedence.
+		_ = msg.Iter(func(i int, p types.Part) error {
+			partMeta := p.Metadata()
+			existing := map[string]struct{}{}
+			_ = partMeta.Iter(func(k, _ string) error {
+				existing[k] = struct{}{}
+				return nil
+			})
+			return meta.Iter(func(k, v string) error {
+				if _, exists := existing[k]; !exists {
+					partMeta.Set(k, v)
+				}
+				return nil
+			})
+		})
+	} else {
+		_ = msg.Iter(func(i int, p types.Part) error {
+			partMeta := meta.Copy()
+			partMeta.Set("http_server_content_type", partMediaTypes[i])
+			p.SetMetadata(partMeta)
+			return nil
+		})
+	}
 
 	// Try to either extract parent span from headers, or create a new one.
 	carrier := opentracing.HTTPHeadersCarrier(r.Header)
@@ -432,7 +561,29 @@ func (h *HTTPServer) postHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if h.verifySig != nil {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			h.log.Warnf("Request read failed: %v\n", err)
+			return
+		}
+		if err = h.verifySig(r.Header, body, time.Now()); err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			h.mSigInvalid.Incr(1)
+			h.log.Debugf("Rejected request with invalid signature: %v\n", err)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
 	msg, err := h.extractMessageFromRequest(r)
+	if errors.Is(err, mio.ErrWireVersionUnsupported) {
+		w.Header().Set(mio.WireVersionsHeader, mio.FormatWireVersions(mio.WireVersions))
+		http.Error(w, "Unsupported wire format version", http.StatusUnsupportedMediaType)
+		h.log.Warnf("Request read failed: %v\n", err)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Bad request", http.StatusBadRequest)
 		h.log.Warnf("Request read failed: %v\n", err)
@@ -440,7 +591,14 @@ func (h *HTTPServer) postHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tracing.FinishSpans(msg)
 
-	store := roundtrip.NewResultStore()
+	var store roundtrip.ResultStore
+	var replied <-chan struct{}
+	if h.conf.Response.Mode == "first_result" {
+		replyStore := roundtrip.NewReplyStore()
+		store, replied = replyStore, replyStore.Done()
+	} else {
+		store = roundtrip.NewResultStore()
+	}
 	roundtrip.AddResultStore(msg, store)
 
 	h.mCount.Incr(1)
@@ -477,6 +635,10 @@ func (h *HTTPServer) postHandler(w http.ResponseWriter, r *http.Request) {
 		tTaken := time.Since(msg.CreatedAt()).Nanoseconds()
 		h.mLatency.Timing(tTaken)
 		h.mSucc.Incr(1)
+	case <-replied:
+		tTaken := time.Since(msg.CreatedAt()).Nanoseconds()
+		h.mLatency.Timing(tTaken)
+		h.mSucc.Incr(1)
 	case <-time.After(h.timeout):
 		h.mTimeout.Incr(1)
 		http.Error(w, "Request timed out", http.StatusRequestTimeout)
@@ -490,8 +652,14 @@ func (h *HTTPServer) postHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	results := store.Get()
+	if replied != nil && len(results) > 1 {
+		// Only the first result is used as a reply.
+		results = results[:1]
+	}
+
 	responseMsg := message.New(nil)
-	for _, resMsg := range store.Get() {
+	for _, resMsg := range results {
 		resMsg.Iter(func(i int, part types.Part) error {
 			responseMsg.Append(part)
 			return nil
@@ -0,0 +1,170 @@
+package input
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPServerSignatureConfig contains configuration fields for verifying the
+// HMAC signatures of requests received by the HTTPServer input.
+type HTTPServerSignatureConfig struct {
+	Style     string `json:"style" yaml:"style"`
+	Secret    string `json:"secret" yaml:"secret"`
+	Header    string `json:"header" yaml:"header"`
+	Tolerance string `json:"tolerance" yaml:"tolerance"`
+}
+
+// NewHTTPServerSignatureConfig creates a new HTTPServerSignatureConfig with
+// default values.
+func NewHTTPServerSignatureConfig() HTTPServerSignatureConfig {
+	return HTTPServerSignatureConfig{
+		Style:     "",
+		Secret:    "",
+		Header:    "",
+		Tolerance: "5m",
+	}
+}
+
+//------------------------------------------------------------------------------
+
+var errSignatureMissing = errors.New("signature missing")
+var errSignatureMismatch = errors.New("signature mismatch")
+
+// signatureVerifier checks the signature of a request body against its headers
+// and returns an error if it is not valid.
+type signatureVerifier func(header http.Header, body []byte, now time.Time) error
+
+func newSignatureVerifier(conf HTTPServerSignatureConfig) (signatureVerifier, error) {
+	if conf.Style == "" {
+		return nil, nil
+	}
+	if conf.Secret == "" {
+		return nil, errors.New("a signature secret must be provided")
+	}
+
+	var tolerance time.Duration
+	if conf.Tolerance != "" {
+		var err error
+		if tolerance, err = time.ParseDuration(conf.Tolerance); err != nil {
+			return nil, fmt.Errorf("failed to parse signature tolerance: %v", err)
+		}
+	}
+
+	secret := []byte(conf.Secret)
+	switch conf.Style {
+	case "github":
+		return func(header http.Header, body []byte, now time.Time) error {
+			return verifyHexSignature(secret, header.Get("X-Hub-Signature-256"), "sha256=", body)
+		}, nil
+	case "stripe":
+		return func(header http.Header, body []byte, now time.Time) error {
+			return verifyStripeSignature(secret, header.Get("Stripe-Signature"), body, now, tolerance)
+		}, nil
+	case "slack":
+		return func(header http.Header, body []byte, now time.Time) error {
+			ts := header.Get("X-Slack-Request-Timestamp")
+			if err := checkTimestamp(ts, now, tolerance); err != nil {
+				return err
+			}
+			signed := append([]byte("v0:"+ts+":"), body...)
+			return verifyHexSignature(secret, header.Get("X-Slack-Signature"), "v0=", signed)
+		}, nil
+	case "hmac_sha256":
+		if conf.Header == "" {
+			return nil, errors.New("a signature header must be provided for the hmac_sha256 style")
+		}
+		return func(header http.Header, body []byte, now time.Time) error {
+			sig := strings.TrimPrefix(header.Get(conf.Header), "sha256=")
+			return verifyHexSignature(secret, sig, "", body)
+		}, nil
+	}
+	return nil, fmt.Errorf("signature style not recognised: %v", conf.Style)
+}
+
+func computeHMAC(secret, data []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func verifyHexSignature(secret []byte, sig, prefix string, signed []byte) error {
+	if sig == "" {
+		return errSignatureMissing
+	}
+	if !strings.HasPrefix(sig, prefix) {
+		return errSignatureMismatch
+	}
+	sigBytes, err := hex.DecodeString(strings.TrimPrefix(sig, prefix))
+	if err != nil {
+		return errSignatureMismatch
+	}
+	if !hmac.Equal(sigBytes, computeHMAC(secret, signed)) {
+		return errSignatureMismatch
+	}
+	return nil
+}
+
+func checkTimestamp(ts string, now time.Time, tolerance time.Duration) error {
+	if ts == "" {
+		return errSignatureMissing
+	}
+	secs, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse signature timestamp: %v", err)
+	}
+	if tolerance <= 0 {
+		return nil
+	}
+	diff := now.Sub(time.Unix(secs, 0))
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > tolerance {
+		return errors.New("signature timestamp outside of tolerance")
+	}
+	return nil
+}
+
+// verifyStripeSignature checks a header of the form t=<ts>,v1=<sig>,v1=<sig>,
+// where any one of the v1 signatures must match.
+func verifyStripeSignature(secret []byte, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	if header == "" {
+		return errSignatureMissing
+	}
+
+	var ts string
+	var sigs []string
+	for _, kv := range strings.Split(header, ",") {
+		parts := strings.SplitN(strings.TrimSpace(kv), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		switch parts[0] {
+		case "t":
+			ts = parts[1]
+		case "v1":
+			sigs = append(sigs, parts[1])
+		}
+	}
+	if err := checkTimestamp(ts, now, tolerance); err != nil {
+		return err
+	}
+	if len(sigs) == 0 {
+		return errSignatureMissing
+	}
+
+	signed := append([]byte(ts+"."), body...)
+	for _, sig := range sigs {
+		if verifyHexSignature(secret, sig, "", signed) == nil {
+			return nil
+		}
+	}
+	return errSignatureMismatch
+}
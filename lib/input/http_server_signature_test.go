@@ -0,0 +1,158 @@
+package input
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHMAC(secret, data string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestHTTPServerSignatureStyles(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	nowStr := strconv.FormatInt(now.Unix(), 10)
+	oldStr := strconv.FormatInt(now.Add(-time.Hour).Unix(), 10)
+	body := `{"hello":"world"}`
+
+	tests := []struct {
+		name    string
+		style   string
+		header  string
+		headers map[string]string
+		valid   bool
+	}{
+		{
+			name:  "github valid",
+			style: "github",
+			headers: map[string]string{
+				"X-Hub-Signature-256": "sha256=" + testHMAC("foo", body),
+			},
+			valid: true,
+		},
+		{
+			name:  "github wrong secret",
+			style: "github",
+			headers: map[string]string{
+				"X-Hub-Signature-256": "sha256=" + testHMAC("bar", body),
+			},
+		},
+		{
+			name:  "github missing",
+			style: "github",
+		},
+		{
+			name:  "stripe valid",
+			style: "stripe",
+			headers: map[string]string{
+				"Stripe-Signature": "t=" + nowStr + ",v1=deadbeef,v1=" + testHMAC("foo", nowStr+"."+body),
+			},
+			valid: true,
+		},
+		{
+			name:  "stripe expired",
+			style: "stripe",
+			headers: map[string]string{
+				"Stripe-Signature": "t=" + oldStr + ",v1=" + testHMAC("foo", oldStr+"."+body),
+			},
+		},
+		{
+			name:  "slack valid",
+			style: "slack",
+			headers: map[string]string{
+				"X-Slack-Request-Timestamp": nowStr,
+				"X-Slack-Signature":         "v0=" + testHMAC("foo", "v0:"+nowStr+":"+body),
+			},
+			valid: true,
+		},
+		{
+			name:  "slack tampered timestamp",
+			style: "slack",
+			headers: map[string]string{
+				"X-Slack-Request-Timestamp": nowStr,
+				"X-Slack-Signature":         "v0=" + testHMAC("foo", "v0:"+oldStr+":"+body),
+			},
+		},
+		{
+			name:   "hmac_sha256 valid",
+			style:  "hmac_sha256",
+			header: "X-Signature",
+			headers: map[string]string{
+				"X-Signature": testHMAC("foo", body),
+			},
+			valid: true,
+		},
+		{
+			name:   "hmac_sha256 valid with prefix",
+			style:  "hmac_sha256",
+			header: "X-Signature",
+			headers: map[string]string{
+				"X-Signature": "sha256=" + testHMAC("foo", body),
+			},
+			valid: true,
+		},
+		{
+			name:   "hmac_sha256 bad encoding",
+			style:  "hmac_sha256",
+			header: "X-Signature",
+			headers: map[string]string{
+				"X-Signature": "not hex",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			conf := NewHTTPServerSignatureConfig()
+			conf.Style = test.style
+			conf.Secret = "foo"
+			conf.Header = test.header
+
+			verify, err := newSignatureVerifier(conf)
+			require.NoError(t, err)
+
+			header := http.Header{}
+			for k, v := range test.headers {
+				header.Set(k, v)
+			}
+
+			err = verify(header, []byte(body), now)
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestHTTPServerSignatureConfigErrors(t *testing.T) {
+	conf := NewHTTPServerSignatureConfig()
+	verify, err := newSignatureVerifier(conf)
+	require.NoError(t, err)
+	assert.Nil(t, verify)
+
+	conf.Style = "github"
+	_, err = newSignatureVerifier(conf)
+	assert.Error(t, err)
+
+	conf.Secret = "foo"
+	conf.Style = "nope"
+	_, err = newSignatureVerifier(conf)
+	assert.Error(t, err)
+
+	conf.Style = "hmac_sha256"
+	_, err = newSignatureVerifier(conf)
+	assert.Error(t, err)
+}
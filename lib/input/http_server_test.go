@@ -23,6 +23,7 @@ import (
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/manager"
 	"github.com/Jeffail/benthos/v3/lib/message"
+	mio "github.com/Jeffail/benthos/v3/lib/message/io"
 	"github.com/Jeffail/benthos/v3/lib/message/roundtrip"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/ratelimit"
@@ -1095,3 +1096,329 @@ func TestHTTPSyncResponseHeadersStatus(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestHTTPServerSignature(t *testing.T) {
+	t.Parallel()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+	mgr, err := manager.New(manager.NewConfig(), reg, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	conf := input.NewConfig()
+	conf.HTTPServer.Path = "/testpost"
+	conf.HTTPServer.Signature.Style = "github"
+	conf.HTTPServer.Signature.Secret = "foo"
+
+	h, err := input.NewHTTPServer(conf, mgr, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	server := httptest.NewServer(reg.mut)
+	defer server.Close()
+
+	// Signature of "hello world" with the secret "foo".
+	validSig := "sha256=8ef552dbcee03816e802b64315b486a09930241564206b789e9ac97b504ece8a"
+
+	req, err := http.NewRequest("POST", server.URL+"/testpost", bytes.NewBufferString("hello world"))
+	require.NoError(t, err)
+	req.Header.Set("X-Hub-Signature-256", "sha256=0000")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	go func() {
+		req, err := http.NewRequest("POST", server.URL+"/testpost", bytes.NewBufferString("hello world"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		req.Header.Set("X-Hub-Signature-256", validSig)
+
+		res, err := http.DefaultClient.Do(req)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+		}
+	}()
+
+	select {
+	case ts := <-h.TransactionChan():
+		assert.Equal(t, "hello world", string(ts.Payload.Get(0).Get()))
+		select {
+		case ts.ResponseChan <- response.NewAck():
+		case <-time.After(time.Second):
+			t.Error("Timed out waiting for response")
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Timed out waiting for message")
+	}
+
+	h.CloseAsync()
+	require.NoError(t, h.WaitForClose(time.Second*5))
+}
+
+func TestHTTPServerDecodeBinary(t *testing.T) {
+	t.Parallel()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+	mgr, err := manager.New(manager.NewConfig(), reg, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	conf := input.NewConfig()
+	conf.HTTPServer.Path = "/testpost"
+	conf.HTTPServer.DecodeBinary = true
+
+	h, err := input.NewHTTPServer(conf, mgr, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	server := httptest.NewServer(reg.mut)
+	defer server.Close()
+
+	req, err := http.NewRequest("POST", server.URL+"/testpost", bytes.NewBufferString("not cbor"))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/cbor")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	for _, test := range []struct {
+		contentType string
+		body        []byte
+	}{
+		{contentType: "application/msgpack", body: []byte{0x81, 0xa3, 'f', 'o', 'o', 0xa3, 'b', 'a', 'r'}},
+		{contentType: "application/cbor", body: []byte{0xa1, 0x63, 'f', 'o', 'o', 0x63, 'b', 'a', 'r'}},
+		{contentType: "application/json", body: []byte(`{"foo":"bar"}`)},
+	} {
+		test := test
+		go func() {
+			req, err := http.NewRequest("POST", server.URL+"/testpost", bytes.NewReader(test.body))
+			if !assert.NoError(t, err) {
+				return
+			}
+			req.Header.Set("Content-Type", test.contentType)
+
+			res, err := http.DefaultClient.Do(req)
+			if assert.NoError(t, err) {
+				assert.Equal(t, http.StatusOK, res.StatusCode)
+			}
+		}()
+
+		select {
+		case ts := <-h.TransactionChan():
+			assert.Equal(t, `{"foo":"bar"}`, string(ts.Payload.Get(0).Get()), test.contentType)
+			assert.Equal(t, "application/json", ts.Payload.Get(0).Metadata().Get("http_server_content_type"), test.contentType)
+			select {
+			case ts.ResponseChan <- response.NewAck():
+			case <-time.After(time.Second):
+				t.Error("Timed out waiting for response")
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatal("Timed out waiting for message")
+		}
+	}
+
+	h.CloseAsync()
+	require.NoError(t, h.WaitForClose(time.Second*5))
+}
+
+func TestHTTPServerContentTypeMetadata(t *testing.T) {
+	t.Parallel()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+	mgr, err := manager.New(manager.NewConfig(), reg, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	conf := input.NewConfig()
+	conf.HTTPServer.Path = "/testpost"
+
+	h, err := input.NewHTTPServer(conf, mgr, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	server := httptest.NewServer(reg.mut)
+	defer server.Close()
+
+	for _, test := range []struct {
+		contentType string
+		body        string
+		expected    []string
+	}{
+		{
+			contentType: "application/json; charset=utf-8",
+			body:        `{"foo":"bar"}`,
+			expected:    []string{"application/json"},
+		},
+		{
+			contentType: "multipart/mixed; boundary=foo",
+			body: "--foo\r\n" +
+				"Content-Type: application/avro\r\n\r\n" +
+				"first\r\n" +
+				"--foo\r\n\r\n" +
+				"second\r\n" +
+				"--foo--\r\n",
+			expected: []string{"application/avro", "text/plain"},
+		},
+	} {
+		test := test
+		go func() {
+			req, err := http.NewRequest("POST", server.URL+"/testpost", bytes.NewBufferString(test.body))
+			if !assert.NoError(t, err) {
+				return
+			}
+			req.Header.Set("Content-Type", test.contentType)
+
+			res, err := http.DefaultClient.Do(req)
+			if assert.NoError(t, err) {
+				assert.Equal(t, http.StatusOK, res.StatusCode)
+			}
+		}()
+
+		select {
+		case ts := <-h.TransactionChan():
+			require.Equal(t, len(test.expected), ts.Payload.Len(), test.contentType)
+			for i, exp := range test.expected {
+				assert.Equal(t, exp, ts.Payload.Get(i).Metadata().Get("http_server_content_type"), test.contentType)
+			}
+			select {
+			case ts.ResponseChan <- response.NewAck():
+			case <-time.After(time.Second):
+				t.Error("Timed out waiting for response")
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatal("Timed out waiting for message")
+		}
+	}
+
+	h.CloseAsync()
+	require.NoError(t, h.WaitForClose(time.Second*5))
+}
+
+func TestHTTPServerWireFormat(t *testing.T) {
+	t.Parallel()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+	mgr, err := manager.New(manager.NewConfig(), reg, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	conf := input.NewConfig()
+	conf.HTTPServer.Path = "/testpost"
+
+	h, err := input.NewHTTPServer(conf, mgr, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	server := httptest.NewServer(reg.mut)
+	defer server.Close()
+
+	req, err := http.NewRequest("POST", server.URL+"/testpost", bytes.NewReader([]byte{99, 0}))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", mio.WireContentType+"; version=99")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+	assert.Equal(t, "1", res.Header.Get(mio.WireVersionsHeader))
+
+	msg := message.New([][]byte{[]byte("foo"), []byte("bar")})
+	msg.Get(0).Metadata().Set("http_server_verb", "NOT_POST")
+	msg.Get(1).Metadata().Set("baz", "buz")
+	wireBytes, err := mio.MessageToWire(msg, mio.WireVersion)
+	require.NoError(t, err)
+
+	go func() {
+		req, err := http.NewRequest("POST", server.URL+"/testpost", bytes.NewReader(wireBytes))
+		if !assert.NoError(t, err) {
+			return
+		}
+		req.Header.Set("Content-Type", mio.WireContentType+"; version=1")
+
+		res, err := http.DefaultClient.Do(req)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+		}
+	}()
+
+	select {
+	case ts := <-h.TransactionChan():
+		require.Equal(t, 2, ts.Payload.Len())
+		assert.Equal(t, "foo", string(ts.Payload.Get(0).Get()))
+		assert.Equal(t, "NOT_POST", ts.Payload.Get(0).Metadata().Get("http_server_verb"))
+		assert.Equal(t, "bar", string(ts.Payload.Get(1).Get()))
+		assert.Equal(t, "buz", ts.Payload.Get(1).Metadata().Get("baz"))
+		assert.Equal(t, "POST", ts.Payload.Get(1).Metadata().Get("http_server_verb"))
+		select {
+		case ts.ResponseChan <- response.NewAck():
+		case <-time.After(time.Second):
+			t.Error("Timed out waiting for response")
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Timed out waiting for message")
+	}
+
+	h.CloseAsync()
+	require.NoError(t, h.WaitForClose(time.Second*5))
+}
+
+func TestHTTPServerSyncResponseFirstResult(t *testing.T) {
+	t.Parallel()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+	mgr, err := manager.New(manager.NewConfig(), reg, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	conf := input.NewConfig()
+	conf.HTTPServer.Path = "/testpost"
+	conf.HTTPServer.Response.Mode = "first_result"
+
+	stats := metrics.NewLocal()
+	h, err := input.NewHTTPServer(conf, mgr, log.Noop(), stats)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(reg.mut)
+	defer server.Close()
+
+	resChan := make(chan string, 1)
+	go func() {
+		res, err := http.Post(server.URL+"/testpost", "text/plain", bytes.NewBufferString("hello world"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		b, err := ioutil.ReadAll(res.Body)
+		assert.NoError(t, err)
+		resChan <- string(b)
+	}()
+
+	var ts types.Transaction
+	select {
+	case ts = <-h.TransactionChan():
+	case <-time.After(time.Second * 5):
+		t.Fatal("Timed out waiting for message")
+	}
+
+	// Simulate the message being processed and then delivered by an output,
+	// the response must be returned before the transaction is acknowledged.
+	processed := ts.Payload.Copy()
+	processed.Get(0).Set([]byte("HELLO WORLD"))
+	roundtrip.SetAsDelivered(processed)
+
+	delivered := ts.Payload.Copy()
+	delivered.Get(0).Set([]byte("second output"))
+	roundtrip.SetAsDelivered(delivered)
+
+	select {
+	case res := <-resChan:
+		assert.Equal(t, "HELLO WORLD", res)
+	case <-time.After(time.Second * 5):
+		t.Fatal("Timed out waiting for response")
+	}
+	assert.Equal(t, int64(1), stats.GetCounters()["send.success"])
+
+	select {
+	case ts.ResponseChan <- response.NewAck():
+	case <-time.After(time.Second):
+		t.Error("Timed out waiting for response")
+	}
+
+	h.CloseAsync()
+	require.NoError(t, h.WaitForClose(time.Second*5))
+}
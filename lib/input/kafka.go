@@ -3,6 +3,7 @@ package input
 import (
 	"context"
 	"crypto/tls"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"strconv"
@@ -50,12 +51,19 @@ This input adds the following metadata fields to each message:
 - kafka_offset
 - kafka_lag
 - kafka_timestamp_unix
+- kafka_schema_id
 - All existing message headers (version 0.11+)
 ` + "```" + `
 
 The field ` + "`kafka_lag`" + ` is the calculated difference between the high water mark offset of the partition at the time of ingestion and the current message offset.
 
-You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#metadata).`,
+The field ` + "`kafka_schema_id`" + ` is only added when ` + "`extract_schema_id`" + ` is enabled and the message value is framed in the Confluent Schema Registry wire format, in which case it contains the ID of the schema that the value was encoded with. This allows messages to be routed by their schema without decoding them.
+
+You can access these metadata fields using [function interpolation](/docs/configuration/interpolation#metadata).
+
+### Azure Event Hubs
+
+This input is able to consume from Azure Event Hubs through its Kafka compatible endpoint, find out more [in this guide](/docs/guides/azure#event-hubs).`,
 		FieldSpecs: docs.FieldSpecs{
 			docs.FieldString(
 				"addresses", "A list of broker addresses to connect to. If an item of the list contains commas it will be expanded into multiple addresses.",
@@ -81,6 +89,7 @@ You can access these metadata fields using [function interpolation](/docs/config
 			docs.FieldAdvanced("commit_period", "The period of time between each commit of the current partition offsets. Offsets are always committed during shutdown."),
 			docs.FieldAdvanced("max_processing_period", "A maximum estimate for the time taken to process a message, this is used for tuning consumer group synchronization."),
 			input.ExtractTracingSpanMappingDocs,
+			docs.FieldAdvanced("extract_schema_id", "Whether to extract the schema ID of message values framed in the [Confluent Schema Registry wire format](https://docs.confluent.io/platform/current/schema-registry/serdes-develop/index.html#wire-format) into the metadata field `kafka_schema_id`. The value of the message is left unchanged.").HasDefault(false).AtVersion("3.54.0"),
 			docs.FieldAdvanced("group", "Tuning parameters for consumer group synchronization.").WithChildren(
 				docs.FieldAdvanced("session_timeout", "A period after which a consumer of the group is kicked after no heartbeats."),
 				docs.FieldAdvanced("heartbeat_interval", "A period in which heartbeats should be sent out."),
@@ -407,7 +416,7 @@ func (k *kafkaReader) syncCheckpointer(topic string, partition int32) func(conte
 	}
 }
 
-func dataToPart(highestOffset int64, data *sarama.ConsumerMessage) types.Part {
+func dataToPart(highestOffset int64, data *sarama.ConsumerMessage, extractSchemaID bool) types.Part {
 	part := message.NewPart(data.Value)
 
 	meta := part.Metadata()
@@ -427,9 +436,24 @@ func dataToPart(highestOffset int64, data *sarama.ConsumerMessage) types.Part {
 	meta.Set("kafka_lag", strconv.FormatInt(lag, 10))
 	meta.Set("kafka_timestamp_unix", strconv.FormatInt(data.Timestamp.Unix(), 10))
 
+	if extractSchemaID {
+		if id, ok := schemaIDFromValue(data.Value); ok {
+			meta.Set("kafka_schema_id", strconv.FormatUint(uint64(id), 10))
+		}
+	}
 	return part
 }
 
+// schemaIDFromValue returns the schema ID of a message value framed in the
+// Confluent Schema Registry wire format, which is a zero magic byte followed by
+// a four byte big-endian schema ID.
+func schemaIDFromValue(value []byte) (uint32, bool) {
+	if len(value) < 5 || value[0] != 0 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(value[1:5]), true
+}
+
 //------------------------------------------------------------------------------
 
 func (k *kafkaReader) closeGroupAndConsumers() {
@@ -5,6 +5,7 @@ import (
 
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Shopify/sarama"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -44,3 +45,30 @@ func TestKafkaBadParams(t *testing.T) {
 		})
 	}
 }
+
+func TestKafkaDataToPartSchemaID(t *testing.T) {
+	data := &sarama.ConsumerMessage{
+		Topic:     "foo",
+		Partition: 1,
+		Offset:    5,
+		Value:     []byte{0, 0, 0, 1, 2, 'f', 'o', 'o'},
+	}
+
+	part := dataToPart(10, data, false)
+	assert.Equal(t, "", part.Metadata().Get("kafka_schema_id"))
+	assert.Equal(t, "4", part.Metadata().Get("kafka_lag"))
+
+	part = dataToPart(10, data, true)
+	assert.Equal(t, "258", part.Metadata().Get("kafka_schema_id"))
+	assert.Equal(t, data.Value, part.Get())
+
+	for _, v := range [][]byte{
+		[]byte(`{"foo":"bar"}`),
+		{0, 0, 1},
+		nil,
+	} {
+		data.Value = v
+		part = dataToPart(10, data, true)
+		assert.Equal(t, "", part.Metadata().Get("kafka_schema_id"), string(v))
+	}
+}
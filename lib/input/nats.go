@@ -23,6 +23,7 @@ This input adds the following metadata fields to each message:
 
 ` + "``` text" + `
 - nats_subject
+- nats_reply_subject (only set when the message has a reply subject)
 ` + "```" + `
 
 You can access these metadata fields using
@@ -5,6 +5,7 @@ type PulsarConfig struct {
 	URL              string   `json:"url" yaml:"url"`
 	Topics           []string `json:"topics" yaml:"topics"`
 	SubscriptionName string   `json:"subscription_name" yaml:"subscription_name"`
+	SubscriptionType string   `json:"subscription_type" yaml:"subscription_type"`
 }
 
 // NewPulsarConfig creates a new PulsarConfig with default values.
@@ -13,5 +14,6 @@ func NewPulsarConfig() PulsarConfig {
 		URL:              "",
 		Topics:           []string{},
 		SubscriptionName: "",
+		SubscriptionType: "shared",
 	}
 }
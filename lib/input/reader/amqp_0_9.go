@@ -3,6 +3,7 @@ package reader
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"sync"
 	"time"
@@ -26,6 +27,52 @@ type AMQP09QueueDeclareConfig struct {
 	Durable bool `json:"durable" yaml:"durable"`
 }
 
+// AMQP09ExchangeDeclareConfig contains fields describing an exchange to be
+// declared.
+type AMQP09ExchangeDeclareConfig struct {
+	Name    string `json:"name" yaml:"name"`
+	Type    string `json:"type" yaml:"type"`
+	Durable bool   `json:"durable" yaml:"durable"`
+}
+
+// NewAMQP09ExchangeDeclareConfig creates a new AMQP09ExchangeDeclareConfig with
+// default values.
+func NewAMQP09ExchangeDeclareConfig() AMQP09ExchangeDeclareConfig {
+	return AMQP09ExchangeDeclareConfig{
+		Name:    "",
+		Type:    "direct",
+		Durable: true,
+	}
+}
+
+// UnmarshalJSON ensures that when parsing configs that are in a slice the
+// default values are still applied.
+func (e *AMQP09ExchangeDeclareConfig) UnmarshalJSON(bytes []byte) error {
+	type confAlias AMQP09ExchangeDeclareConfig
+	aliased := confAlias(NewAMQP09ExchangeDeclareConfig())
+
+	if err := json.Unmarshal(bytes, &aliased); err != nil {
+		return err
+	}
+
+	*e = AMQP09ExchangeDeclareConfig(aliased)
+	return nil
+}
+
+// UnmarshalYAML ensures that when parsing configs that are in a slice the
+// default values are still applied.
+func (e *AMQP09ExchangeDeclareConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type confAlias AMQP09ExchangeDeclareConfig
+	aliased := confAlias(NewAMQP09ExchangeDeclareConfig())
+
+	if err := unmarshal(&aliased); err != nil {
+		return err
+	}
+
+	*e = AMQP09ExchangeDeclareConfig(aliased)
+	return nil
+}
+
 // AMQP09BindingConfig contains fields describing a queue binding to be
 // declared.
 type AMQP09BindingConfig struct {
@@ -35,15 +82,16 @@ type AMQP09BindingConfig struct {
 
 // AMQP09Config contains configuration for the AMQP09 input type.
 type AMQP09Config struct {
-	URL             string                   `json:"url" yaml:"url"`
-	Queue           string                   `json:"queue" yaml:"queue"`
-	QueueDeclare    AMQP09QueueDeclareConfig `json:"queue_declare" yaml:"queue_declare"`
-	BindingsDeclare []AMQP09BindingConfig    `json:"bindings_declare" yaml:"bindings_declare"`
-	ConsumerTag     string                   `json:"consumer_tag" yaml:"consumer_tag"`
-	AutoAck         bool                     `json:"auto_ack" yaml:"auto_ack"`
-	PrefetchCount   int                      `json:"prefetch_count" yaml:"prefetch_count"`
-	PrefetchSize    int                      `json:"prefetch_size" yaml:"prefetch_size"`
-	TLS             btls.Config              `json:"tls" yaml:"tls"`
+	URL              string                        `json:"url" yaml:"url"`
+	Queue            string                        `json:"queue" yaml:"queue"`
+	QueueDeclare     AMQP09QueueDeclareConfig      `json:"queue_declare" yaml:"queue_declare"`
+	ExchangesDeclare []AMQP09ExchangeDeclareConfig `json:"exchanges_declare" yaml:"exchanges_declare"`
+	BindingsDeclare  []AMQP09BindingConfig         `json:"bindings_declare" yaml:"bindings_declare"`
+	ConsumerTag      string                        `json:"consumer_tag" yaml:"consumer_tag"`
+	AutoAck          bool                          `json:"auto_ack" yaml:"auto_ack"`
+	PrefetchCount    int                           `json:"prefetch_count" yaml:"prefetch_count"`
+	PrefetchSize     int                           `json:"prefetch_size" yaml:"prefetch_size"`
+	TLS              btls.Config                   `json:"tls" yaml:"tls"`
 
 	// TODO: V4 remove this (maybe in V5 to allow a grace period)
 	Batching batch.PolicyConfig `json:"batching" yaml:"batching"`
@@ -58,13 +106,14 @@ func NewAMQP09Config() AMQP09Config {
 			Enabled: false,
 			Durable: true,
 		},
-		ConsumerTag:     "benthos-consumer",
-		AutoAck:         false,
-		PrefetchCount:   10,
-		PrefetchSize:    0,
-		TLS:             btls.NewConfig(),
-		Batching:        batch.NewPolicyConfig(),
-		BindingsDeclare: []AMQP09BindingConfig{},
+		ConsumerTag:      "benthos-consumer",
+		AutoAck:          false,
+		PrefetchCount:    10,
+		PrefetchSize:     0,
+		TLS:              btls.NewConfig(),
+		Batching:         batch.NewPolicyConfig(),
+		ExchangesDeclare: []AMQP09ExchangeDeclareConfig{},
+		BindingsDeclare:  []AMQP09BindingConfig{},
 	}
 }
 
@@ -146,6 +195,20 @@ func (a *AMQP09) ConnectWithContext(ctx context.Context) (err error) {
 		}
 	}
 
+	for _, eConf := range a.conf.ExchangesDeclare {
+		if err = amqpChan.ExchangeDeclare(
+			eConf.Name,    // name of the exchange
+			eConf.Type,    // type
+			eConf.Durable, // durable
+			false,         // delete when complete
+			false,         // internal
+			false,         // noWait
+			nil,           // arguments
+		); err != nil {
+			return fmt.Errorf("exchange Declare: %s", err)
+		}
+	}
+
 	for _, bConf := range a.conf.BindingsDeclare {
 		if err = amqpChan.QueueBind(
 			a.conf.Queue,     // name of the queue
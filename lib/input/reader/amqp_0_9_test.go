@@ -0,0 +1,36 @@
+package reader
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestAMQP09ExchangesDeclareDefaults(t *testing.T) {
+	exp := []AMQP09ExchangeDeclareConfig{
+		{Name: "foo", Type: "direct", Durable: true},
+		{Name: "bar", Type: "topic", Durable: false},
+	}
+
+	conf := NewAMQP09Config()
+	require.NoError(t, yaml.Unmarshal([]byte(`
+exchanges_declare:
+  - name: foo
+  - name: bar
+    type: topic
+    durable: false
+`), &conf))
+	assert.Equal(t, exp, conf.ExchangesDeclare)
+
+	conf = NewAMQP09Config()
+	require.NoError(t, json.Unmarshal([]byte(`{
+	"exchanges_declare": [
+		{ "name": "foo" },
+		{ "name": "bar", "type": "topic", "durable": false }
+	]
+}`), &conf))
+	assert.Equal(t, exp, conf.ExchangesDeclare)
+}
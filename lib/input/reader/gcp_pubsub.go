@@ -152,6 +152,9 @@ func (c *GCPPubSub) ReadWithContext(ctx context.Context) (types.Message, AsyncAc
 	part := message.NewPart(gmsg.Data)
 	part.SetMetadata(metadata.New(gmsg.Attributes))
 	part.Metadata().Set("gcp_pubsub_publish_time_unix", strconv.FormatInt(gmsg.PublishTime.Unix(), 10))
+	if gmsg.OrderingKey != "" {
+		part.Metadata().Set("gcp_pubsub_ordering_key", gmsg.OrderingKey)
+	}
 	msg.Append(part)
 
 	return msg, func(ctx context.Context, res types.Response) error {
@@ -183,6 +186,9 @@ func (c *GCPPubSub) Read() (types.Message, error) {
 	part := message.NewPart(gmsg.Data)
 	part.SetMetadata(metadata.New(gmsg.Attributes))
 	part.Metadata().Set("gcp_pubsub_publish_time_unix", strconv.FormatInt(gmsg.PublishTime.Unix(), 10))
+	if gmsg.OrderingKey != "" {
+		part.Metadata().Set("gcp_pubsub_ordering_key", gmsg.OrderingKey)
+	}
 	msg.Append(part)
 
 batchLoop:
@@ -200,6 +206,9 @@ batchLoop:
 		part := message.NewPart(gmsg.Data)
 		part.SetMetadata(metadata.New(gmsg.Attributes))
 		part.Metadata().Set("gcp_pubsub_publish_time_unix", strconv.FormatInt(gmsg.PublishTime.Unix(), 10))
+		if gmsg.OrderingKey != "" {
+			part.Metadata().Set("gcp_pubsub_ordering_key", gmsg.OrderingKey)
+		}
 		msg.Append(part)
 	}
 
@@ -32,6 +32,7 @@ type KafkaConfig struct {
 	CommitPeriod        string                   `json:"commit_period" yaml:"commit_period"`
 	CheckpointLimit     int                      `json:"checkpoint_limit" yaml:"checkpoint_limit"`
 	ExtractTracingMap   string                   `json:"extract_tracing_map" yaml:"extract_tracing_map"`
+	ExtractSchemaID     bool                     `json:"extract_schema_id" yaml:"extract_schema_id"`
 	MaxProcessingPeriod string                   `json:"max_processing_period" yaml:"max_processing_period"`
 	FetchBufferCap      int                      `json:"fetch_buffer_cap" yaml:"fetch_buffer_cap"`
 	StartFromOldest     bool                     `json:"start_from_oldest" yaml:"start_from_oldest"`
@@ -65,6 +66,7 @@ func NewKafkaConfig() KafkaConfig {
 		CommitPeriod:        "1s",
 		CheckpointLimit:     1,
 		MaxProcessingPeriod: "100ms",
+		ExtractSchemaID:     false,
 		FetchBufferCap:      256,
 		Topic:               "benthos_stream",
 		Partition:           0,
@@ -163,6 +163,9 @@ func (n *NATS) ReadWithContext(ctx context.Context) (types.Message, AsyncAckFn,
 
 	bmsg := message.New([][]byte{msg.Data})
 	bmsg.Get(0).Metadata().Set("nats_subject", msg.Subject)
+	if msg.Reply != "" {
+		bmsg.Get(0).Metadata().Set("nats_reply_subject", msg.Reply)
+	}
 
 	return bmsg, func(ctx context.Context, res types.Response) error {
 		if res.Error() != nil {
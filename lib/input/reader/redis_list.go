@@ -2,6 +2,7 @@ package reader
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -21,14 +22,16 @@ type RedisListConfig struct {
 	bredis.Config `json:",inline" yaml:",inline"`
 	Key           string `json:"key" yaml:"key"`
 	Timeout       string `json:"timeout" yaml:"timeout"`
+	BatchSize     int    `json:"batch_size" yaml:"batch_size"`
 }
 
 // NewRedisListConfig creates a new RedisListConfig with default values.
 func NewRedisListConfig() RedisListConfig {
 	return RedisListConfig{
-		Config:  bredis.NewConfig(),
-		Key:     "benthos_list",
-		Timeout: "5s",
+		Config:    bredis.NewConfig(),
+		Key:       "benthos_list",
+		Timeout:   "5s",
+		BatchSize: 1,
 	}
 }
 
@@ -63,6 +66,10 @@ func NewRedisList(
 		}
 	}
 
+	if conf.BatchSize < 1 {
+		return nil, errors.New("batch_size must be at least 1")
+	}
+
 	if _, err := conf.Config.Client(); err != nil {
 		return nil, err
 	}
@@ -129,7 +136,27 @@ func (r *RedisList) ReadWithContext(ctx context.Context) (types.Message, AsyncAc
 		return nil, nil, types.ErrTimeout
 	}
 
-	return message.New([][]byte{[]byte(res[1])}), noopAsyncAckFn, nil
+	msg := message.New([][]byte{[]byte(res[1])})
+	if r.conf.BatchSize > 1 {
+		// Pop the remainder of the batch without blocking, the range and trim
+		// are executed within a transaction so that no other consumer can pop
+		// the same elements.
+		var rangeCmd *redis.StringSliceCmd
+		if _, err = client.TxPipelined(func(pipe redis.Pipeliner) error {
+			rangeCmd = pipe.LRange(r.conf.Key, 0, int64(r.conf.BatchSize-2))
+			pipe.LTrim(r.conf.Key, int64(r.conf.BatchSize-1), -1)
+			return nil
+		}); err != nil {
+			// The first element has already been popped and is therefore
+			// returned regardless.
+			r.log.Errorf("Error from redis: %v\n", err)
+			return msg, noopAsyncAckFn, nil
+		}
+		for _, v := range rangeCmd.Val() {
+			msg.Append(message.NewPart([]byte(v)))
+		}
+	}
+	return msg, noopAsyncAckFn, nil
 }
 
 // Acknowledge is a noop since Redis Lists do not support acknowledgements.
@@ -0,0 +1,84 @@
+package reader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/go-redis/redis/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedisList pops from an in memory list rather than a Redis server.
+type fakeRedisList struct {
+	redis.UniversalClient
+	list []string
+}
+
+func (f *fakeRedisList) BLPop(timeout time.Duration, keys ...string) *redis.StringSliceCmd {
+	if len(f.list) == 0 {
+		return redis.NewStringSliceResult(nil, redis.Nil)
+	}
+	v := f.list[0]
+	f.list = f.list[1:]
+	return redis.NewStringSliceResult([]string{keys[0], v}, nil)
+}
+
+func (f *fakeRedisList) TxPipelined(fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	return nil, fn(&fakeRedisListPipe{list: f})
+}
+
+type fakeRedisListPipe struct {
+	redis.Pipeliner
+	list *fakeRedisList
+}
+
+func (p *fakeRedisListPipe) LRange(key string, start, stop int64) *redis.StringSliceCmd {
+	if stop >= int64(len(p.list.list)) {
+		stop = int64(len(p.list.list)) - 1
+	}
+	return redis.NewStringSliceResult(append([]string{}, p.list.list[start:stop+1]...), nil)
+}
+
+func (p *fakeRedisListPipe) LTrim(key string, start, stop int64) *redis.StatusCmd {
+	if start > int64(len(p.list.list)) {
+		start = int64(len(p.list.list))
+	}
+	p.list.list = p.list.list[start:]
+	return redis.NewStatusResult("OK", nil)
+}
+
+func TestRedisListBatchSize(t *testing.T) {
+	conf := NewRedisListConfig()
+	conf.BatchSize = 3
+
+	r, err := NewRedisList(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+	r.client = &fakeRedisList{list: []string{"a", "b", "c", "d", "e"}}
+
+	ctx := context.Background()
+	msg, _, err := r.ReadWithContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b"), []byte("c")}, message.GetAllBytes(msg))
+
+	// The remaining batch is smaller than the batch size.
+	msg, _, err = r.ReadWithContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("d"), []byte("e")}, message.GetAllBytes(msg))
+
+	_, _, err = r.ReadWithContext(ctx)
+	assert.Equal(t, types.ErrTimeout, err)
+}
+
+func TestRedisListBatchSizeInvalid(t *testing.T) {
+	conf := NewRedisListConfig()
+	conf.BatchSize = 0
+
+	_, err := NewRedisList(conf, log.Noop(), metrics.Noop())
+	require.EqualError(t, err, "batch_size must be at least 1")
+}
@@ -72,6 +72,10 @@ func getZMQType(t string) (zmq4.Type, error) {
 		return zmq4.SUB, nil
 	case "PULL":
 		return zmq4.PULL, nil
+	case "ROUTER":
+		return zmq4.ROUTER, nil
+	case "DEALER":
+		return zmq4.DEALER, nil
 	}
 	return zmq4.PULL, types.ErrInvalidZMQType
 }
@@ -112,6 +116,12 @@ func (z *ZMQ4) ConnectWithContext(ignored context.Context) error {
 
 	socket.SetRcvhwm(z.conf.HighWaterMark)
 
+	if z.conf.Identity != "" {
+		if err = socket.SetIdentity(z.conf.Identity); err != nil {
+			return err
+		}
+	}
+
 	for _, address := range z.urls {
 		if z.conf.Bind {
 			err = socket.Bind(address)
@@ -166,7 +176,22 @@ func (z *ZMQ4) ReadWithContext(ctx context.Context) (types.Message, AsyncAckFn,
 		return nil, nil, err
 	}
 
-	return message.New(data), noopAsyncAckFn, nil
+	// ROUTER sockets prefix messages with the identity of the peer they were
+	// received from, which is moved into metadata so that replies can be
+	// routed back to it.
+	var identity []byte
+	if z.conf.SocketType == "ROUTER" && len(data) > 0 {
+		identity, data = data[0], data[1:]
+	}
+
+	msg := message.New(data)
+	if identity != nil {
+		msg.Iter(func(i int, p types.Part) error {
+			p.Metadata().Set("zmq4_identity", string(identity))
+			return nil
+		})
+	}
+	return msg, noopAsyncAckFn, nil
 }
 
 // Acknowledge instructs whether the pending messages were propagated
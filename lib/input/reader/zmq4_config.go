@@ -8,6 +8,7 @@ type ZMQ4Config struct {
 	Bind          bool     `json:"bind" yaml:"bind"`
 	SocketType    string   `json:"socket_type" yaml:"socket_type"`
 	SubFilters    []string `json:"sub_filters" yaml:"sub_filters"`
+	Identity      string   `json:"identity" yaml:"identity"`
 	HighWaterMark int      `json:"high_water_mark" yaml:"high_water_mark"`
 	PollTimeout   string   `json:"poll_timeout" yaml:"poll_timeout"`
 }
@@ -19,6 +20,7 @@ func NewZMQ4Config() *ZMQ4Config {
 		Bind:          false,
 		SocketType:    "PULL",
 		SubFilters:    []string{},
+		Identity:      "",
 		HighWaterMark: 0,
 		PollTimeout:   "5s",
 	}
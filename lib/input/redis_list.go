@@ -19,6 +19,7 @@ Pops messages from the beginning of a Redis list using the BLPop command.`,
 		FieldSpecs: redis.ConfigDocs().Add(
 			docs.FieldCommon("key", "The key of a list to read from."),
 			docs.FieldAdvanced("timeout", "The length of time to poll for new messages before reattempting."),
+			docs.FieldAdvanced("batch_size", "The maximum number of messages to pop at a time, which are consumed as a batch. After waiting for the first message the remainder of the batch is popped without waiting, and therefore batches can be smaller than this.").HasDefault(1).AtVersion("3.54.0"),
 		),
 		Categories: []Category{
 			CategoryServices,
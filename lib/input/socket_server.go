@@ -24,7 +24,9 @@ func init() {
 		constructor: fromSimpleConstructor(NewSocketServer),
 		Summary:     `Creates a server that receives a stream of messages over a tcp, udp or unix socket.`,
 		Description: `
-The field ` + "`max_buffer`" + ` specifies the maximum amount of memory to allocate _per connection_ for buffering lines of data. If a line of data from a connection exceeds this value then the connection will be closed.`,
+The field ` + "`max_buffer`" + ` specifies the maximum amount of memory to allocate _per connection_ for buffering lines of data. If a line of data from a connection exceeds this value then the connection will be closed.
+
+Streams can be split into messages by a delimiter with codecs such as ` + "`lines`" + ` and ` + "`delim:x`" + `, or by a binary length prefix with the ` + "`length-prefixed:x`" + ` codec, in which case the ` + "`max_buffer`" + ` is also the maximum size of a message.`,
 		FieldSpecs: docs.FieldSpecs{
 			docs.FieldCommon("network", "A network type to accept (unix|tcp|udp).").HasOptions(
 				"unix", "tcp", "udp",
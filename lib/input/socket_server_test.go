@@ -92,6 +92,47 @@ func TestSocketServerBasic(t *testing.T) {
 	conn.Close()
 }
 
+func TestSocketServerLengthPrefixed(t *testing.T) {
+	conf := NewConfig()
+	conf.SocketServer.Network = "tcp"
+	conf.SocketServer.Address = "127.0.0.1:0"
+	conf.SocketServer.Codec = "length-prefixed:4"
+
+	rdr, err := NewSocketServer(conf, nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	defer func() {
+		rdr.CloseAsync()
+		assert.NoError(t, rdr.WaitForClose(time.Second))
+	}()
+
+	conn, err := net.Dial("tcp", rdr.(*SocketServer).Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	go func() {
+		conn.SetWriteDeadline(time.Now().Add(time.Second * 5))
+		// Frames may be split and joined arbitrarily by a stream.
+		_, _ = conn.Write([]byte("\x00\x00\x00\x07foo"))
+		_, _ = conn.Write([]byte("\nbar\x00\x00"))
+		_, _ = conn.Write([]byte("\x00\x03baz"))
+	}()
+
+	for _, exp := range []string{"foo\nbar", "baz"} {
+		select {
+		case tran := <-rdr.TransactionChan():
+			assert.Equal(t, [][]byte{[]byte(exp)}, message.GetAllBytes(tran.Payload))
+			select {
+			case tran.ResponseChan <- response.NewAck():
+			case <-time.After(time.Second):
+				t.Fatal("timed out")
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out")
+		}
+	}
+}
+
 func TestSocketServerRetries(t *testing.T) {
 	tmpDir, err := ioutil.TempDir("", "benthos_socket_test")
 	require.NoError(t, err)
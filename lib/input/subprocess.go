@@ -16,6 +16,7 @@ import (
 	"github.com/Jeffail/benthos/v3/lib/message"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/cenkalti/backoff/v4"
 )
 
 //------------------------------------------------------------------------------
@@ -33,7 +34,9 @@ func init() {
 		Summary: `
 Executes a command, runs it as a subprocess, and consumes messages from it over stdout.`,
 		Description: `
-Messages are consumed according to a specified codec. The command is executed once and if it terminates the input also closes down gracefully. Alternatively, the field ` + "`restart_on_close` can be set to `true`" + ` in order to have Benthos re-execute the command each time it stops.
+Messages are consumed according to a specified codec. The command is executed once and if it terminates the input also closes down gracefully. Alternatively, the field ` + "`restart_on_exit` can be set to `true`" + ` in order to have Benthos re-execute the command each time it stops.
+
+When a command is restarted Benthos waits for a period that grows exponentially each time the command exits without producing any messages, starting at ` + "`restart_backoff.initial_interval`" + ` and capped at ` + "`restart_backoff.max_interval`" + `. This prevents a command that fails immediately from being executed in a tight loop.
 
 The field ` + "`max_buffer`" + ` defines the maximum message size able to be read from the subprocess. This value should be set significantly above the real expected maximum message size.
 
@@ -45,6 +48,10 @@ The execution environment of the subprocess is the same as the Benthos instance,
 				"codec", "The way in which messages should be consumed from the subprocess.",
 			).HasOptions("lines"),
 			docs.FieldCommon("restart_on_exit", "Whether the command should be re-executed each time the subprocess ends."),
+			docs.FieldAdvanced("restart_backoff", "Control the time intervals between restarts of the command when `restart_on_exit` is enabled.").WithChildren(
+				docs.FieldAdvanced("initial_interval", "The initial period to wait before restarting the command."),
+				docs.FieldAdvanced("max_interval", "The maximum period to wait before restarting the command."),
+			).AtVersion("3.54.0"),
 			docs.FieldAdvanced("max_buffer", "The maximum expected size of an individual message."),
 		},
 		Categories: []Category{
@@ -84,13 +91,21 @@ func codecFromStr(codec string) (subprocCodec, error) {
 
 //------------------------------------------------------------------------------
 
+// SubprocessBackoffConfig contains configuration for the intervals between
+// restarts of a subprocess.
+type SubprocessBackoffConfig struct {
+	InitialInterval string `json:"initial_interval" yaml:"initial_interval"`
+	MaxInterval     string `json:"max_interval" yaml:"max_interval"`
+}
+
 // SubprocessConfig contains configuration for the Subprocess input type.
 type SubprocessConfig struct {
-	Name          string   `json:"name" yaml:"name"`
-	Args          []string `json:"args" yaml:"args"`
-	Codec         string   `json:"codec" yaml:"codec"`
-	RestartOnExit bool     `json:"restart_on_exit" yaml:"restart_on_exit"`
-	MaxBuffer     int      `json:"max_buffer" yaml:"max_buffer"`
+	Name           string                  `json:"name" yaml:"name"`
+	Args           []string                `json:"args" yaml:"args"`
+	Codec          string                  `json:"codec" yaml:"codec"`
+	RestartOnExit  bool                    `json:"restart_on_exit" yaml:"restart_on_exit"`
+	RestartBackoff SubprocessBackoffConfig `json:"restart_backoff" yaml:"restart_backoff"`
+	MaxBuffer      int                     `json:"max_buffer" yaml:"max_buffer"`
 }
 
 // NewSubprocessConfig creates a new SubprocessConfig with default values.
@@ -100,7 +115,11 @@ func NewSubprocessConfig() SubprocessConfig {
 		Args:          []string{},
 		Codec:         "lines",
 		RestartOnExit: false,
-		MaxBuffer:     bufio.MaxScanTokenSize,
+		RestartBackoff: SubprocessBackoffConfig{
+			InitialInterval: "100ms",
+			MaxInterval:     "10s",
+		},
+		MaxBuffer: bufio.MaxScanTokenSize,
 	}
 }
 
@@ -111,6 +130,9 @@ type Subprocess struct {
 	conf  SubprocessConfig
 	codec subprocCodec
 
+	restartBackoff backoff.BackOff
+	exited         bool
+
 	msgChan chan []byte
 	errChan chan error
 
@@ -128,6 +150,17 @@ func newSubprocess(conf SubprocessConfig) (*Subprocess, error) {
 	if s.codec, err = codecFromStr(s.conf.Codec); err != nil {
 		return nil, err
 	}
+
+	boff := backoff.NewExponentialBackOff()
+	if boff.InitialInterval, err = time.ParseDuration(s.conf.RestartBackoff.InitialInterval); err != nil {
+		return nil, fmt.Errorf("failed to parse restart backoff initial interval: %w", err)
+	}
+	if boff.MaxInterval, err = time.ParseDuration(s.conf.RestartBackoff.MaxInterval); err != nil {
+		return nil, fmt.Errorf("failed to parse restart backoff max interval: %w", err)
+	}
+	boff.MaxElapsedTime = 0
+	boff.Reset()
+	s.restartBackoff = boff
 	return s, nil
 }
 
@@ -137,6 +170,17 @@ func (s *Subprocess) ConnectWithContext(ctx context.Context) error {
 		return nil
 	}
 
+	if s.exited {
+		select {
+		case <-time.After(s.restartBackoff.NextBackOff()):
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.ctx.Done():
+			return types.ErrTypeClosed
+		}
+		s.exited = false
+	}
+
 	cmd := exec.CommandContext(s.ctx, s.conf.Name, s.conf.Args...)
 
 	stdout, err := cmd.StdoutPipe()
@@ -219,10 +263,12 @@ func (s *Subprocess) ReadWithContext(ctx context.Context) (types.Message, reader
 			if s.conf.RestartOnExit {
 				s.msgChan = nil
 				s.errChan = nil
+				s.exited = true
 				return nil, nil, types.ErrNotConnected
 			}
 			return nil, nil, types.ErrTypeClosed
 		}
+		s.restartBackoff.Reset()
 		msg := message.New(nil)
 		msg.Append(message.NewPart(b))
 		return msg, func(context.Context, types.Response) error { return nil }, nil
@@ -231,6 +277,7 @@ func (s *Subprocess) ReadWithContext(ctx context.Context) (types.Message, reader
 			if s.conf.RestartOnExit {
 				s.msgChan = nil
 				s.errChan = nil
+				s.exited = true
 				return nil, nil, types.ErrNotConnected
 			}
 			return nil, nil, types.ErrTypeClosed
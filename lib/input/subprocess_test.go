@@ -1,6 +1,7 @@
 package input
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"path"
@@ -175,3 +176,45 @@ func main() {
 	i.CloseAsync()
 	require.NoError(t, i.WaitForClose(time.Second))
 }
+
+func TestSubprocessRestartBackoff(t *testing.T) {
+	filePath := testProgram(t, `package main
+
+func main() {}
+`)
+
+	conf := NewSubprocessConfig()
+	conf.Name = "go"
+	conf.Args = []string{"run", filePath}
+	conf.RestartOnExit = true
+	conf.RestartBackoff.InitialInterval = "500ms"
+
+	s, err := newSubprocess(conf)
+	require.NoError(t, err)
+	t.Cleanup(s.CloseAsync)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	require.NoError(t, s.ConnectWithContext(ctx))
+	for {
+		_, _, err = s.ReadWithContext(ctx)
+		if err == types.ErrNotConnected {
+			break
+		}
+		require.Error(t, err)
+	}
+
+	before := time.Now()
+	require.NoError(t, s.ConnectWithContext(ctx))
+	assert.GreaterOrEqual(t, int64(time.Since(before)), int64(time.Millisecond*250))
+}
+
+func TestSubprocessBadRestartBackoff(t *testing.T) {
+	conf := NewSubprocessConfig()
+	conf.Name = "cat"
+	conf.RestartBackoff.MaxInterval = "not a duration"
+
+	_, err := newSubprocess(conf)
+	require.Error(t, err)
+}
@@ -31,13 +31,17 @@ machine, then build with the tag:
 go install -tags "ZMQ4" github.com/Jeffail/benthos/v3/cmd/benthos
 ` + "```" + `
 
-ZMQ4 input supports PULL and SUB sockets only. If there is demand for other
-socket types then they can be added easily.`,
+ZMQ4 input supports PULL, SUB, ROUTER and DEALER sockets. A ROUTER socket
+receives messages fairly from all connected peers, and the identity of the peer
+that sent a message is removed from it and stored in the metadata field
+` + "`zmq4_identity`" + `, which a ` + "`zmq4`" + ` output with a ROUTER socket uses to
+route replies back to that peer.`,
 		FieldSpecs: docs.FieldSpecs{
 			docs.FieldCommon("urls", "A list of URLs to connect to. If an item of the list contains commas it will be expanded into multiple URLs."),
 			docs.FieldCommon("bind", "Whether to bind to the specified URLs or connect."),
-			docs.FieldCommon("socket_type", "The socket type to connect as.").HasOptions("PULL", "SUB"),
+			docs.FieldCommon("socket_type", "The socket type to connect as.").HasOptions("PULL", "SUB", "ROUTER", "DEALER"),
 			docs.FieldCommon("sub_filters", "A list of subscription topic filters to use when consuming from a SUB socket. Specifying a single sub_filter of `''` will subscribe to everything."),
+			docs.FieldAdvanced("identity", "An optional identity of the socket, which ROUTER peers use to address it. When empty an identity is generated by the peer.").HasDefault("").AtVersion("3.54.0"),
 			docs.FieldAdvanced("high_water_mark", "The message high water mark to use."),
 			docs.FieldAdvanced("poll_timeout", "The poll timeout to use."),
 		},
@@ -0,0 +1,115 @@
+package manager
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/Jeffail/benthos/v3/lib/types"
+)
+
+// ComponentInfo describes a labelled component that was created by a manager.
+type ComponentInfo struct {
+	Label    string `json:"label"`
+	Kind     string `json:"kind"`
+	Type     string `json:"type"`
+	Stream   string `json:"stream,omitempty"`
+	Resource bool   `json:"resource,omitempty"`
+}
+
+type componentKey struct {
+	stream string
+	kind   string
+	label  string
+}
+
+// componentRegistry tracks the labelled components created by a manager and
+// all variants of it.
+type componentRegistry struct {
+	mut        sync.Mutex
+	components map[componentKey]ComponentInfo
+}
+
+func newComponentRegistry() *componentRegistry {
+	return &componentRegistry{
+		components: map[componentKey]ComponentInfo{},
+	}
+}
+
+func (r *componentRegistry) add(info ComponentInfo) {
+	r.mut.Lock()
+	r.components[componentKey{
+		stream: info.Stream,
+		kind:   info.Kind,
+		label:  info.Label,
+	}] = info
+	r.mut.Unlock()
+}
+
+func (r *componentRegistry) removeStream(stream string) {
+	r.mut.Lock()
+	for k := range r.components {
+		if k.stream == stream {
+			delete(r.components, k)
+		}
+	}
+	r.mut.Unlock()
+}
+
+func (r *componentRegistry) list() []ComponentInfo {
+	r.mut.Lock()
+	infos := make([]ComponentInfo, 0, len(r.components))
+	for _, v := range r.components {
+		infos = append(infos, v)
+	}
+	r.mut.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Stream != infos[j].Stream {
+			return infos[i].Stream < infos[j].Stream
+		}
+		if infos[i].Kind != infos[j].Kind {
+			return infos[i].Kind < infos[j].Kind
+		}
+		return infos[i].Label < infos[j].Label
+	})
+	return infos
+}
+
+func (r *componentRegistry) handler(w http.ResponseWriter, req *http.Request) {
+	resBytes, err := json.Marshal(r.list())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resBytes)
+}
+
+//------------------------------------------------------------------------------
+
+// Components returns a list of the labelled components that have been created
+// by the manager or any variant of it, sorted by stream, kind and label.
+func (t *Type) Components() []ComponentInfo {
+	return t.components.list()
+}
+
+func (t *Type) registerComponent(kind, typeStr, label string, resource bool) {
+	t.components.add(ComponentInfo{
+		Label:    label,
+		Kind:     kind,
+		Type:     typeStr,
+		Stream:   t.stream,
+		Resource: resource,
+	})
+}
+
+// RemoveStreamComponents removes the labelled components of a stream from the
+// listings of a manager, which should be called once the stream is deleted.
+// This function does nothing if the manager type is not a *Type.
+func RemoveStreamComponents(mgr types.Manager, stream string) {
+	if t, ok := mgr.(*Type); ok {
+		t.components.removeStream(stream)
+	}
+}
@@ -0,0 +1,71 @@
+package manager
+
+import (
+	"io"
+	"sync"
+)
+
+// sharedResource is a resource held by a sharedRegistry along with the number
+// of components that currently hold it.
+type sharedResource struct {
+	refs int
+	res  io.Closer
+}
+
+// sharedRegistry holds resources that are shared by the components created by
+// a manager and all variants of it, such as the connection pools of clients.
+type sharedRegistry struct {
+	mut       sync.Mutex
+	resources map[string]*sharedResource
+}
+
+func newSharedRegistry() *sharedRegistry {
+	return &sharedRegistry{
+		resources: map[string]*sharedResource{},
+	}
+}
+
+func (r *sharedRegistry) acquire(key string, create func() (io.Closer, error)) (io.Closer, func(), error) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	s, exists := r.resources[key]
+	if !exists {
+		res, err := create()
+		if err != nil {
+			return nil, nil, err
+		}
+		s = &sharedResource{res: res}
+		r.resources[key] = s
+	}
+	s.refs++
+
+	var releaseOnce sync.Once
+	return s.res, func() {
+		releaseOnce.Do(func() {
+			r.release(key, s)
+		})
+	}, nil
+}
+
+func (r *sharedRegistry) release(key string, s *sharedResource) {
+	r.mut.Lock()
+	s.refs--
+	closeRes := s.refs == 0
+	if closeRes {
+		delete(r.resources, key)
+	}
+	r.mut.Unlock()
+
+	if closeRes {
+		_ = s.res.Close()
+	}
+}
+
+// AcquireShared returns a resource that is shared under a key by the components
+// of this manager, creating it when no component currently holds it, along with
+// a func to be called once the caller is finished with the resource. The
+// resource is closed once every component that acquired it has released it.
+func (t *Type) AcquireShared(key string, create func() (io.Closer, error)) (io.Closer, func(), error) {
+	return t.shared.acquire(key, create)
+}
@@ -0,0 +1,70 @@
+package manager
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type closeCounter struct {
+	closes int
+}
+
+func (c *closeCounter) Close() error {
+	c.closes++
+	return nil
+}
+
+func TestManagerSharedResources(t *testing.T) {
+	mgr, err := New(NewConfig(), types.NoopMgr(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	var created []*closeCounter
+	create := func() (io.Closer, error) {
+		c := &closeCounter{}
+		created = append(created, c)
+		return c, nil
+	}
+
+	// Variants of a manager share the same resources.
+	resA, releaseA, err := mgr.AcquireShared("foo", create)
+	require.NoError(t, err)
+	resB, releaseB, err := mgr.forStream("bar").forComponent("baz").AcquireShared("foo", create)
+	require.NoError(t, err)
+	resC, releaseC, err := mgr.AcquireShared("buz", create)
+	require.NoError(t, err)
+
+	assert.True(t, resA == resB)
+	assert.False(t, resA == resC)
+	require.Len(t, created, 2)
+
+	releaseA()
+	releaseA()
+	assert.Equal(t, 0, created[0].closes)
+
+	releaseB()
+	assert.Equal(t, 1, created[0].closes)
+	assert.Equal(t, 0, created[1].closes)
+
+	// Once closed the next acquisition creates the resource again.
+	resD, releaseD, err := mgr.AcquireShared("foo", create)
+	require.NoError(t, err)
+	assert.False(t, resA == resD)
+	require.Len(t, created, 3)
+
+	releaseC()
+	releaseD()
+	assert.Equal(t, 1, created[1].closes)
+	assert.Equal(t, 1, created[2].closes)
+
+	_, _, err = mgr.AcquireShared("err", func() (io.Closer, error) {
+		return nil, errors.New("nope")
+	})
+	assert.EqualError(t, err, "nope")
+}
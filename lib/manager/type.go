@@ -74,6 +74,12 @@ type Type struct {
 	pipes    map[string]<-chan types.Transaction
 	pipeLock *sync.RWMutex
 
+	// Tracks labelled components for listing through the API.
+	components *componentRegistry
+
+	// Resources shared between components, such as connection pools.
+	shared *sharedRegistry
+
 	// TODO: V4 Remove this
 	conditions map[string]types.Condition
 }
@@ -118,6 +124,9 @@ func NewV2(conf ResourceConfig, apiReg APIReg, log log.Modular, stats metrics.Ty
 		pipes:    map[string]<-chan types.Transaction{},
 		pipeLock: &sync.RWMutex{},
 
+		components: newComponentRegistry(),
+		shared:     newSharedRegistry(),
+
 		conditions: map[string]types.Condition{},
 	}
 
@@ -125,6 +134,12 @@ func NewV2(conf ResourceConfig, apiReg APIReg, log log.Modular, stats metrics.Ty
 		opt(t)
 	}
 
+	t.RegisterEndpoint(
+		"/components",
+		"Returns a list of the labelled components and resources of the service.",
+		t.components.handler,
+	)
+
 	conf, err := conf.collapsed()
 	if err != nil {
 		return nil, err
@@ -424,6 +439,7 @@ func (t *Type) NewCache(conf cache.Config) (types.Cache, error) {
 			return nil, err
 		}
 		mgr = t.forComponent(conf.Label)
+		mgr.registerComponent("cache", conf.Type, conf.Label, false)
 	}
 	return t.env.Caches.Init(conf, mgr)
 }
@@ -454,6 +470,7 @@ func (t *Type) StoreCache(ctx context.Context, name string, conf cache.Config) e
 	}
 
 	t.caches[name] = newCache
+	t.registerComponent("cache", conf.Type, name, true)
 	return nil
 }
 
@@ -490,6 +507,7 @@ func (t *Type) NewInput(conf input.Config, hasBatchProc bool, pipelines ...types
 			return nil, err
 		}
 		mgr = t.forComponent(conf.Label)
+		mgr.registerComponent("input", conf.Type, conf.Label, false)
 	}
 	return t.env.Inputs.Init(hasBatchProc, conf, mgr, pipelines...)
 }
@@ -524,6 +542,7 @@ func (t *Type) StoreInput(ctx context.Context, name string, conf input.Config) e
 	}
 
 	t.inputs[name] = newInput
+	t.registerComponent("input", conf.Type, name, true)
 	return nil
 }
 
@@ -559,6 +578,7 @@ func (t *Type) NewProcessor(conf processor.Config) (types.Processor, error) {
 			return nil, err
 		}
 		mgr = t.forComponent(conf.Label)
+		mgr.registerComponent("processor", conf.Type, conf.Label, false)
 	}
 	return t.env.Processors.Init(conf, mgr)
 }
@@ -593,6 +613,7 @@ func (t *Type) StoreProcessor(ctx context.Context, name string, conf processor.C
 	}
 
 	t.processors[name] = newProcessor
+	t.registerComponent("processor", conf.Type, name, true)
 	return nil
 }
 
@@ -627,6 +648,7 @@ func (t *Type) NewOutput(conf output.Config, pipelines ...types.PipelineConstruc
 			return nil, err
 		}
 		mgr = t.forComponent(conf.Label)
+		mgr.registerComponent("output", conf.Type, conf.Label, false)
 	}
 	return t.env.Outputs.Init(conf, mgr, pipelines...)
 }
@@ -664,6 +686,7 @@ func (t *Type) StoreOutput(ctx context.Context, name string, conf output.Config)
 			name, conf.Type, err,
 		)
 	}
+	t.registerComponent("output", conf.Type, name, true)
 	return nil
 }
 
@@ -699,6 +722,7 @@ func (t *Type) NewRateLimit(conf ratelimit.Config) (types.RateLimit, error) {
 			return nil, err
 		}
 		mgr = t.forComponent(conf.Label)
+		mgr.registerComponent("rate_limit", conf.Type, conf.Label, false)
 	}
 	return t.env.RateLimits.Init(conf, mgr)
 }
@@ -729,6 +753,7 @@ func (t *Type) StoreRateLimit(ctx context.Context, name string, conf ratelimit.C
 	}
 
 	t.rateLimits[name] = newRateLimit
+	t.registerComponent("rate_limit", conf.Type, name, true)
 	return nil
 }
 
@@ -64,6 +64,44 @@ func TestManagerProcessorLabels(t *testing.T) {
 	}
 }
 
+func TestManagerComponentListing(t *testing.T) {
+	cFoo := processor.NewConfig()
+	cFoo.Type = processor.TypeBloblang
+	cFoo.Bloblang = "root = this"
+	cFoo.Label = "foo"
+
+	conf := manager.NewResourceConfig()
+	conf.ResourceProcessors = append(conf.ResourceProcessors, cFoo)
+
+	mgr, err := manager.NewV2(conf, nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	cBar := processor.NewConfig()
+	cBar.Type = processor.TypeBloblang
+	cBar.Bloblang = "root = this"
+	cBar.Label = "bar"
+
+	strmMgr, ok := mgr.ForStream("baz").(*manager.Type)
+	require.True(t, ok)
+
+	_, err = strmMgr.NewProcessor(cBar)
+	require.NoError(t, err)
+
+	// Components without a label are not listed.
+	_, err = strmMgr.NewProcessor(processor.NewConfig())
+	require.NoError(t, err)
+
+	assert.Equal(t, []manager.ComponentInfo{
+		{Label: "foo", Kind: "processor", Type: "bloblang", Resource: true},
+		{Label: "bar", Kind: "processor", Type: "bloblang", Stream: "baz"},
+	}, mgr.Components())
+
+	manager.RemoveStreamComponents(mgr, "baz")
+	assert.Equal(t, []manager.ComponentInfo{
+		{Label: "foo", Kind: "processor", Type: "bloblang", Resource: true},
+	}, mgr.Components())
+}
+
 func TestManagerCache(t *testing.T) {
 	testLog := log.Noop()
 
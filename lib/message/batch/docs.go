@@ -30,6 +30,10 @@ Allows you to configure a [batching policy](/docs/configuration/batching).`,
 				"count",
 				"A number of messages at which the batch should be flushed. If `0` disables count based batching.",
 			),
+			docs.FieldInt(
+				"max_count",
+				"An optional maximum number of messages for adaptive batching. When set the number of messages at which a batch is flushed starts at `count` and doubles each time a batch fills up before its `period` elapses, which indicates a backlog of messages, up to this value. Each time a batch is flushed by its `period` instead the number halves, back down to `count`. This maximises throughput whilst draining a backlog and keeps latency low in steady state. Requires both `count` and `period` to be set. If `0` disables adaptive batching.",
+			).HasDefault(0).Advanced().AtVersion("3.54.0"),
 			docs.FieldInt(
 				"byte_size",
 				"An amount of bytes at which the batch should be flushed. If `0` disables size based batching.",
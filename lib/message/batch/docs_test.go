@@ -20,6 +20,7 @@ func TestBatchPolicySanit(t *testing.T) {
 	}))
 
 	expSanit := `count: 0
+max_count: 0
 byte_size: 0
 period: ""
 check: ""
@@ -33,6 +33,9 @@ func SanitisePolicyConfig(policy PolicyConfig) (interface{}, error) {
 		"period":     policy.Period,
 		"processors": procConfs,
 	}
+	if policy.MaxCount > 0 {
+		bSanit["max_count"] = policy.MaxCount
+	}
 	if !isNoopCondition(policy.Condition) {
 		condSanit, err := condition.SanitiseConfig(policy.Condition)
 		if err != nil {
@@ -53,6 +56,7 @@ func isNoopCondition(conf condition.Config) bool {
 type PolicyConfig struct {
 	ByteSize   int                `json:"byte_size" yaml:"byte_size"`
 	Count      int                `json:"count" yaml:"count"`
+	MaxCount   int                `json:"max_count" yaml:"max_count"`
 	Condition  condition.Config   `json:"condition" yaml:"condition"`
 	Check      string             `json:"check" yaml:"check"`
 	Period     string             `json:"period" yaml:"period"`
@@ -67,6 +71,7 @@ func NewPolicyConfig() PolicyConfig {
 	return PolicyConfig{
 		ByteSize:   0,
 		Count:      0,
+		MaxCount:   0,
 		Condition:  cond,
 		Check:      "",
 		Period:     "",
@@ -138,6 +143,8 @@ type Policy struct {
 
 	byteSize  int
 	count     int
+	minCount  int
+	maxCount  int
 	period    time.Duration
 	cond      condition.Type
 	check     *mapping.Executor
@@ -145,8 +152,9 @@ type Policy struct {
 	sizeTally int
 	parts     []types.Part
 
-	triggered bool
-	lastBatch time.Time
+	triggered      bool
+	countTriggered bool
+	lastBatch      time.Time
 
 	mSizeBatch   metrics.StatCounter
 	mCountBatch  metrics.StatCounter
@@ -188,6 +196,14 @@ func NewPolicy(
 			return nil, fmt.Errorf("failed to parse duration string: %v", err)
 		}
 	}
+	if conf.MaxCount > 0 {
+		if conf.Count <= 0 || conf.MaxCount < conf.Count {
+			return nil, errors.New("max_count must be used with a count that is no larger than it")
+		}
+		if period <= 0 {
+			return nil, errors.New("max_count must be used with a period in order for batches to shrink")
+		}
+	}
 	var procs []types.Processor
 	for i, pconf := range conf.Processors {
 		pMgr, pLog, pStats := interop.LabelChild(fmt.Sprintf("%v", i), mgr, log, stats)
@@ -202,6 +218,8 @@ func NewPolicy(
 
 		byteSize: conf.ByteSize,
 		count:    conf.Count,
+		minCount: conf.Count,
+		maxCount: conf.MaxCount,
 		period:   period,
 		cond:     cond,
 		check:    check,
@@ -227,6 +245,7 @@ func (p *Policy) Add(part types.Part) bool {
 
 	if !p.triggered && p.count > 0 && len(p.parts) >= p.count {
 		p.triggered = true
+		p.countTriggered = true
 		p.mCountBatch.Incr(1)
 		p.log.Traceln("Batching based on count")
 	}
@@ -293,10 +312,14 @@ func (p *Policy) FlushAny() []types.Message {
 		newMsg = message.New(nil)
 		newMsg.Append(p.parts...)
 	}
+	if newMsg != nil {
+		p.adaptCount()
+	}
 	p.parts = nil
 	p.sizeTally = 0
 	p.lastBatch = time.Now()
 	p.triggered = false
+	p.countTriggered = false
 
 	if newMsg == nil {
 		return nil
@@ -316,6 +339,31 @@ func (p *Policy) FlushAny() []types.Message {
 	return []types.Message{newMsg}
 }
 
+// adaptCount adjusts the count at which batches are flushed when a max_count is
+// configured. A batch that fills up before its period elapses suggests a
+// backlog of messages, and therefore the count is doubled (up to max_count) in
+// order to improve throughput, whereas a batch flushed by its period suggests
+// messages are sparse, and therefore the count is halved (down to count) in
+// order to reduce latency.
+func (p *Policy) adaptCount() {
+	if p.maxCount <= 0 {
+		return
+	}
+	prevCount := p.count
+	if p.countTriggered {
+		if p.count *= 2; p.count > p.maxCount {
+			p.count = p.maxCount
+		}
+	} else if !p.triggered {
+		if p.count /= 2; p.count < p.minCount {
+			p.count = p.minCount
+		}
+	}
+	if p.count != prevCount {
+		p.log.Tracef("Adjusted batch count from %v to %v\n", prevCount, p.count)
+	}
+}
+
 // Count returns the number of currently buffered message parts within this
 // policy.
 func (p *Policy) Count() int {
@@ -124,6 +124,68 @@ func TestPolicyPeriod(t *testing.T) {
 	}
 }
 
+func TestPolicyAdaptiveCount(t *testing.T) {
+	conf := NewPolicyConfig()
+	conf.Count = 2
+	conf.MaxCount = 6
+	conf.Period = "10ms"
+
+	pol, err := NewPolicy(conf, nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		pol.CloseAsync()
+		require.NoError(t, pol.WaitForClose(time.Second))
+	})
+
+	fill := func() int {
+		t.Helper()
+		n := 0
+		for {
+			n++
+			if pol.Add(message.NewPart([]byte("foo"))) {
+				break
+			}
+			require.Less(t, n, 100)
+		}
+		require.NotNil(t, pol.Flush())
+		return n
+	}
+
+	// Batches that fill up grow the count until it reaches the maximum.
+	assert.Equal(t, 2, fill())
+	assert.Equal(t, 4, fill())
+	assert.Equal(t, 6, fill())
+	assert.Equal(t, 6, fill())
+
+	// Batches flushed by the period shrink the count back down.
+	pol.Add(message.NewPart([]byte("foo")))
+	<-time.After(time.Millisecond * 20)
+	require.NotNil(t, pol.Flush())
+	assert.Equal(t, 3, fill())
+
+	pol.Add(message.NewPart([]byte("foo")))
+	<-time.After(time.Millisecond * 20)
+	require.NotNil(t, pol.Flush())
+	pol.Add(message.NewPart([]byte("foo")))
+	<-time.After(time.Millisecond * 20)
+	require.NotNil(t, pol.Flush())
+	assert.Equal(t, 2, fill())
+}
+
+func TestPolicyAdaptiveCountBadConfig(t *testing.T) {
+	conf := NewPolicyConfig()
+	conf.Count = 2
+	conf.MaxCount = 6
+	_, err := NewPolicy(conf, nil, log.Noop(), metrics.Noop())
+	require.Error(t, err)
+
+	conf.Period = "1s"
+	conf.MaxCount = 1
+	_, err = NewPolicy(conf, nil, log.Noop(), metrics.Noop())
+	require.Error(t, err)
+}
+
 func TestPolicySize(t *testing.T) {
 	conf := NewPolicyConfig()
 	conf.ByteSize = 10
@@ -0,0 +1,292 @@
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/types"
+)
+
+//------------------------------------------------------------------------------
+
+// WireVersion is the latest version of the wire format used for sending
+// messages between Benthos instances.
+const WireVersion = 1
+
+// WireVersions lists all versions of the wire format that can be read and
+// written, in order of preference.
+var WireVersions = []int{1}
+
+// WireContentType is the HTTP Content-Type of payloads of the wire format, the
+// version of the payload is specified with the parameter `version`.
+const WireContentType = "application/x-benthos-wire"
+
+// WireVersionsHeader is an HTTP header used by servers to advertise the
+// versions of the wire format that they support.
+const WireVersionsHeader = "X-Benthos-Wire-Versions"
+
+// ErrWireVersionUnsupported is returned when a wire format payload is of a
+// version that isn't supported.
+var ErrWireVersionUnsupported = errors.New("wire format version is not supported")
+
+var wireMagic = []byte("BNTW")
+
+// The wire format of a message (version 1) is as follows:
+//
+//   - The version of the format (1 byte)
+//   - The number of parts (uvarint)
+//   - For each part:
+//     - The number of metadata pairs (uvarint)
+//     - For each metadata pair, sorted by key:
+//       - The length of the key (uvarint), followed by the key
+//       - The length of the value (uvarint), followed by the value
+//     - The length of the payload (uvarint), followed by the payload
+//
+// Streams of messages, such as over a TCP connection, begin with a header
+// consisting of the magic bytes "BNTW", followed by the number of versions
+// supported by the writer (1 byte) and then each supported version (1 byte
+// each), which allows readers to negotiate the version of the stream.
+
+//------------------------------------------------------------------------------
+
+// MessageToWire serialises a message, including the metadata of each part,
+// into a given version of the wire format.
+func MessageToWire(msg types.Message, version int) ([]byte, error) {
+	if version != 1 {
+		return nil, ErrWireVersionUnsupported
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(byte(version))
+
+	uvarint := make([]byte, binary.MaxVarintLen64)
+	writeUvarint := func(v int) {
+		n := binary.PutUvarint(uvarint, uint64(v))
+		buf.Write(uvarint[:n])
+	}
+	writeBytes := func(b []byte) {
+		writeUvarint(len(b))
+		buf.Write(b)
+	}
+
+	writeUvarint(msg.Len())
+	_ = msg.Iter(func(i int, p types.Part) error {
+		meta := map[string]string{}
+		_ = p.Metadata().Iter(func(k, v string) error {
+			meta[k] = v
+			return nil
+		})
+		keys := make([]string, 0, len(meta))
+		for k := range meta {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		writeUvarint(len(keys))
+		for _, k := range keys {
+			writeBytes([]byte(k))
+			writeBytes([]byte(meta[k]))
+		}
+		writeBytes(p.Get())
+		return nil
+	})
+	return buf.Bytes(), nil
+}
+
+// MessageFromWire deserialises a message from the wire format, the version of
+// the format is read from the payload.
+func MessageFromWire(b []byte) (types.Message, error) {
+	if len(b) == 0 {
+		return nil, errors.New("wire format payload is empty")
+	}
+	if b[0] != 1 {
+		return nil, fmt.Errorf("%w: %v", ErrWireVersionUnsupported, b[0])
+	}
+	b = b[1:]
+
+	errTruncated := errors.New("wire format payload is truncated")
+	readUvarint := func() (int, error) {
+		v, n := binary.Uvarint(b)
+		if n <= 0 || v > uint64(len(b)) {
+			return 0, errTruncated
+		}
+		b = b[n:]
+		return int(v), nil
+	}
+	readBytes := func() ([]byte, error) {
+		l, err := readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		if l > len(b) {
+			return nil, errTruncated
+		}
+		v := b[:l]
+		b = b[l:]
+		return v, nil
+	}
+
+	nParts, err := readUvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	msg := message.New(nil)
+	for i := 0; i < nParts; i++ {
+		nMeta, err := readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		meta := make(map[string]string, nMeta)
+		for j := 0; j < nMeta; j++ {
+			k, err := readBytes()
+			if err != nil {
+				return nil, err
+			}
+			v, err := readBytes()
+			if err != nil {
+				return nil, err
+			}
+			meta[string(k)] = string(v)
+		}
+		payload, err := readBytes()
+		if err != nil {
+			return nil, err
+		}
+
+		part := message.NewPart(append([]byte(nil), payload...))
+		for k, v := range meta {
+			part.Metadata().Set(k, v)
+		}
+		msg.Append(part)
+	}
+	if len(b) > 0 {
+		return nil, errors.New("wire format payload has trailing data")
+	}
+	return msg, nil
+}
+
+//------------------------------------------------------------------------------
+
+// NegotiateWireVersion returns the most preferred version of the wire format
+// that is supported both locally and by a remote.
+func NegotiateWireVersion(remote []int) (int, error) {
+	for _, local := range WireVersions {
+		for _, r := range remote {
+			if local == r {
+				return local, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("%w: none of the versions %v are supported", ErrWireVersionUnsupported, remote)
+}
+
+// FormatWireVersions formats a list of wire format versions for an HTTP header.
+func FormatWireVersions(versions []int) string {
+	strs := make([]string, len(versions))
+	for i, v := range versions {
+		strs[i] = strconv.Itoa(v)
+	}
+	return strings.Join(strs, ",")
+}
+
+// ParseWireVersions parses a list of wire format versions from an HTTP header.
+func ParseWireVersions(s string) ([]int, error) {
+	var versions []int
+	for _, str := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(str))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse wire version: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+//------------------------------------------------------------------------------
+
+// WriteWireHeader writes the header of a stream of wire format messages,
+// advertising the versions supported by the writer.
+func WriteWireHeader(w io.Writer) error {
+	header := append([]byte(nil), wireMagic...)
+	header = append(header, byte(len(WireVersions)))
+	for _, v := range WireVersions {
+		header = append(header, byte(v))
+	}
+	_, err := w.Write(header)
+	return err
+}
+
+// ReadWireHeader reads the header of a stream of wire format messages and
+// returns the negotiated version of the stream.
+func ReadWireHeader(r io.Reader) (int, error) {
+	header := make([]byte, len(wireMagic)+1)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(header[:len(wireMagic)], wireMagic) {
+		return 0, errors.New("stream is not of the wire format")
+	}
+	versionBytes := make([]byte, int(header[len(wireMagic)]))
+	if _, err := io.ReadFull(r, versionBytes); err != nil {
+		return 0, err
+	}
+	remote := make([]int, len(versionBytes))
+	for i, v := range versionBytes {
+		remote[i] = int(v)
+	}
+	return NegotiateWireVersion(remote)
+}
+
+// PeekWireHeader returns true if the next bytes of a stream are the beginning
+// of a wire format header.
+func PeekWireHeader(r *bufio.Reader) bool {
+	b, err := r.Peek(len(wireMagic))
+	return err == nil && bytes.Equal(b, wireMagic)
+}
+
+// WriteWireFrame writes a wire format message to a stream, prefixed with its
+// length.
+func WriteWireFrame(w io.Writer, msg types.Message, version int) error {
+	b, err := MessageToWire(msg, version)
+	if err != nil {
+		return err
+	}
+	lenBytes := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(lenBytes, uint64(len(b)))
+	if _, err = w.Write(lenBytes[:n]); err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// ReadWireFrame reads a length prefixed wire format message from a stream.
+func ReadWireFrame(r *bufio.Reader) (types.Message, error) {
+	l, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if l > math.MaxInt32 {
+		return nil, errors.New("wire format frame exceeds the maximum size")
+	}
+	b := make([]byte, l)
+	if _, err = io.ReadFull(r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return MessageFromWire(b)
+}
+
+//------------------------------------------------------------------------------
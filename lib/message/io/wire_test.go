@@ -0,0 +1,111 @@
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWireRoundTrip(t *testing.T) {
+	msg := message.New([][]byte{
+		[]byte("foo"),
+		[]byte(`{"bar":"baz"}`),
+		nil,
+	})
+	msg.Get(0).Metadata().Set("meta1", "val1")
+	msg.Get(1).Metadata().Set("meta2", "val2").Set("meta3", "")
+
+	b, err := MessageToWire(msg, WireVersion)
+	require.NoError(t, err)
+
+	out, err := MessageFromWire(b)
+	require.NoError(t, err)
+	require.Equal(t, 3, out.Len())
+
+	assert.Equal(t, "foo", string(out.Get(0).Get()))
+	assert.Equal(t, "val1", out.Get(0).Metadata().Get("meta1"))
+	assert.Equal(t, `{"bar":"baz"}`, string(out.Get(1).Get()))
+	assert.Equal(t, "val2", out.Get(1).Metadata().Get("meta2"))
+	assert.Equal(t, "", string(out.Get(2).Get()))
+
+	b2, err := MessageToWire(out, WireVersion)
+	require.NoError(t, err)
+	assert.Equal(t, b, b2)
+}
+
+func TestWireBadPayloads(t *testing.T) {
+	_, err := MessageToWire(message.New(nil), 99)
+	assert.True(t, errors.Is(err, ErrWireVersionUnsupported))
+
+	_, err = MessageFromWire(nil)
+	assert.Error(t, err)
+
+	_, err = MessageFromWire([]byte{99, 0})
+	assert.True(t, errors.Is(err, ErrWireVersionUnsupported))
+
+	b, err := MessageToWire(message.New([][]byte{[]byte("hello world")}), WireVersion)
+	require.NoError(t, err)
+
+	_, err = MessageFromWire(b[:len(b)-2])
+	assert.Error(t, err)
+
+	_, err = MessageFromWire(append(b, 0))
+	assert.Error(t, err)
+}
+
+func TestWireNegotiation(t *testing.T) {
+	v, err := NegotiateWireVersion([]int{3, 2, 1})
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	_, err = NegotiateWireVersion([]int{3, 2})
+	assert.True(t, errors.Is(err, ErrWireVersionUnsupported))
+
+	versions, err := ParseWireVersions(FormatWireVersions([]int{2, 1}))
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 1}, versions)
+
+	_, err = ParseWireVersions("nope")
+	assert.Error(t, err)
+}
+
+func TestWireStream(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteWireHeader(&buf))
+
+	first := message.New([][]byte{[]byte("foo"), []byte("bar")})
+	first.Get(1).Metadata().Set("baz", "buz")
+	require.NoError(t, WriteWireFrame(&buf, first, WireVersion))
+	require.NoError(t, WriteWireFrame(&buf, message.New([][]byte{[]byte("qux")}), WireVersion))
+
+	r := bufio.NewReader(&buf)
+	v, err := ReadWireHeader(r)
+	require.NoError(t, err)
+	assert.Equal(t, WireVersion, v)
+
+	msg, err := ReadWireFrame(r)
+	require.NoError(t, err)
+	require.Equal(t, 2, msg.Len())
+	assert.Equal(t, "bar", string(msg.Get(1).Get()))
+	assert.Equal(t, "buz", msg.Get(1).Metadata().Get("baz"))
+
+	msg, err = ReadWireFrame(r)
+	require.NoError(t, err)
+	require.Equal(t, 1, msg.Len())
+	assert.Equal(t, "qux", string(msg.Get(0).Get()))
+
+	_, err = ReadWireFrame(r)
+	assert.Equal(t, io.EOF, err)
+
+	_, err = ReadWireHeader(bytes.NewReader([]byte("NOPE\x01\x01")))
+	assert.Error(t, err)
+
+	_, err = ReadWireHeader(bytes.NewReader([]byte("BNTW\x01\x07")))
+	assert.True(t, errors.Is(err, ErrWireVersionUnsupported))
+}
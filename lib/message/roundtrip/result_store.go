@@ -83,6 +83,71 @@ func NewResultStore() ResultStore {
 
 //------------------------------------------------------------------------------
 
+// ReplyStore is a ResultStore that also captures messages as they are
+// delivered by outputs, and signals once the first result has been stored.
+// This allows inputs to reply to requests with the result of a pipeline as
+// soon as it is available, rather than waiting for all outputs to acknowledge
+// the message.
+type ReplyStore struct {
+	resultStoreImpl
+
+	once sync.Once
+	done chan struct{}
+}
+
+// NewReplyStore returns a ReplyStore.
+func NewReplyStore() *ReplyStore {
+	return &ReplyStore{
+		done: make(chan struct{}),
+	}
+}
+
+// Add a message to the store and signal that a result is available.
+func (r *ReplyStore) Add(msg types.Message) {
+	r.resultStoreImpl.Add(msg)
+	r.once.Do(func() {
+		close(r.done)
+	})
+}
+
+// Done returns a channel that is closed once the first result is stored.
+func (r *ReplyStore) Done() <-chan struct{} {
+	return r.done
+}
+
+// SetAsDelivered is called by outputs once a message has been successfully
+// delivered, and adds the parts of the message to any ReplyStores found within
+// their contexts. Parts of a batch may originate from different requests, and
+// are therefore grouped by the store they belong to.
+func SetAsDelivered(msg types.Message) {
+	var stores []*ReplyStore
+	grouped := map[*ReplyStore][]types.Part{}
+	_ = msg.Iter(func(i int, p types.Part) error {
+		store, ok := message.GetContext(p).Value(ResultStoreKey).(*ReplyStore)
+		if !ok {
+			return nil
+		}
+		if _, exists := grouped[store]; !exists {
+			stores = append(stores, store)
+		}
+		grouped[store] = append(grouped[store], p)
+		return nil
+	})
+	for _, store := range stores {
+		select {
+		case <-store.done:
+			// Only the first result is used as a reply.
+			continue
+		default:
+		}
+		storeMsg := message.New(nil)
+		storeMsg.SetAll(grouped[store])
+		store.Add(storeMsg)
+	}
+}
+
+//------------------------------------------------------------------------------
+
 // AddResultStore sets a result store within the context of the provided message
 // that allows a roundtrip.Writer or any other component to propagate a
 // resulting message back to the origin.
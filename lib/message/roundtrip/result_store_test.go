@@ -40,3 +40,52 @@ func TestResultStore(t *testing.T) {
 		t.Errorf("Unexpected count of stored messages: %v != %v", act, exp)
 	}
 }
+
+func TestReplyStoreDelivered(t *testing.T) {
+	storeA, storeB := NewReplyStore(), NewReplyStore()
+
+	msgA := message.New([][]byte{[]byte("foo")})
+	AddResultStore(msgA, storeA)
+
+	msgB := message.New([][]byte{[]byte("bar"), []byte("baz")})
+	AddResultStore(msgB, storeB)
+
+	batch := message.New(nil)
+	batch.Append(msgB.Get(0))
+	batch.Append(msgA.Get(0))
+	batch.Append(msgB.Get(1))
+	batch.Append(message.NewPart([]byte("no store")))
+
+	SetAsDelivered(batch)
+
+	select {
+	case <-storeA.Done():
+	default:
+		t.Fatal("Expected store A to be done")
+	}
+	select {
+	case <-storeB.Done():
+	default:
+		t.Fatal("Expected store B to be done")
+	}
+
+	if results := storeA.Get(); len(results) != 1 || results[0].Len() != 1 {
+		t.Fatalf("Wrong results in store A: %v", results)
+	}
+	if exp, act := "foo", string(storeA.Get()[0].Get(0).Get()); exp != act {
+		t.Errorf("Wrong message contents: %v != %v", act, exp)
+	}
+
+	if results := storeB.Get(); len(results) != 1 || results[0].Len() != 2 {
+		t.Fatalf("Wrong results in store B: %v", results)
+	}
+	if exp, act := "baz", string(storeB.Get()[0].Get(1).Get()); exp != act {
+		t.Errorf("Wrong message contents: %v != %v", act, exp)
+	}
+
+	// Subsequent deliveries are ignored.
+	SetAsDelivered(msgA)
+	if results := storeA.Get(); len(results) != 1 {
+		t.Fatalf("Wrong count of results in store A: %v", len(results))
+	}
+}
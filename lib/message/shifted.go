@@ -0,0 +1,60 @@
+package message
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/types"
+)
+
+//------------------------------------------------------------------------------
+
+type shiftedAtKey struct{}
+
+// shiftedAt holds the unix nano timestamp of a shift, it is updated in place
+// when a message is shifted again in order to avoid growing its context.
+type shiftedAt struct {
+	nanos int64
+}
+
+// StampShifted attaches a timestamp to the context of each part of a message
+// marking the moment it was shifted from a buffer, replacing any previous
+// stamp. The stamp can subsequently be retrieved with ShiftedAt.
+func StampShifted(msg types.Message, t time.Time) {
+	stampedParts := make([]types.Part, msg.Len())
+	msg.Iter(func(i int, p types.Part) error {
+		ctx := GetContext(p)
+		if s, ok := ctx.Value(shiftedAtKey{}).(*shiftedAt); ok {
+			atomic.StoreInt64(&s.nanos, t.UnixNano())
+			stampedParts[i] = p
+			return nil
+		}
+		ctx = context.WithValue(ctx, shiftedAtKey{}, &shiftedAt{nanos: t.UnixNano()})
+		stampedParts[i] = WithContext(ctx, p)
+		return nil
+	})
+	msg.SetAll(stampedParts)
+}
+
+// ShiftedAt returns the earliest moment at which any part of a message was
+// shifted from a buffer. Messages that have not passed through a buffer return
+// the time at which they were created instead.
+func ShiftedAt(msg types.Message) time.Time {
+	var earliest time.Time
+	msg.Iter(func(i int, p types.Part) error {
+		if s, ok := GetContext(p).Value(shiftedAtKey{}).(*shiftedAt); ok {
+			t := time.Unix(0, atomic.LoadInt64(&s.nanos))
+			if earliest.IsZero() || t.Before(earliest) {
+				earliest = t
+			}
+		}
+		return nil
+	})
+	if earliest.IsZero() {
+		return msg.CreatedAt()
+	}
+	return earliest
+}
+
+//------------------------------------------------------------------------------
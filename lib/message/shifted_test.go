@@ -0,0 +1,30 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShiftedAt(t *testing.T) {
+	msg := New([][]byte{[]byte("foo"), []byte("bar")})
+	assert.Equal(t, msg.CreatedAt(), ShiftedAt(msg))
+
+	first := time.Now().Add(time.Hour)
+	StampShifted(msg, first)
+	assert.Equal(t, first.UnixNano(), ShiftedAt(msg).UnixNano())
+
+	second := first.Add(time.Minute)
+	StampShifted(msg, second)
+	assert.Equal(t, second.UnixNano(), ShiftedAt(msg).UnixNano())
+
+	copied := msg.Copy()
+	copied.Append(NewPart([]byte("baz")))
+	assert.Equal(t, second.UnixNano(), ShiftedAt(copied).UnixNano())
+
+	earlier := New([][]byte{[]byte("qux")})
+	StampShifted(earlier, first)
+	copied.Append(earlier.Get(0))
+	assert.Equal(t, first.UnixNano(), ShiftedAt(copied).UnixNano())
+}
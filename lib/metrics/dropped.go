@@ -0,0 +1,58 @@
+package metrics
+
+//------------------------------------------------------------------------------
+
+// Reasons for which messages are dropped, which are set as the `reason` label
+// of the standard dropped counter.
+const (
+	// DropReasonFilter indicates that messages were intentionally removed by
+	// a processor, such as a filter or deduplication.
+	DropReasonFilter = "filter"
+
+	// DropReasonTTLExpired indicates that messages were removed as they were
+	// older than a configured time to live.
+	DropReasonTTLExpired = "ttl_expired"
+
+	// DropReasonTooLarge indicates that messages were removed as they exceeded
+	// a size limit.
+	DropReasonTooLarge = "too_large"
+
+	// DropReasonQuota indicates that messages were removed as a quota or rate
+	// limit was exceeded.
+	DropReasonQuota = "quota"
+
+	// DropReasonPoison indicates that messages were discarded after reaching a
+	// maximum number of delivery attempts without a dead letter output to route
+	// them to.
+	DropReasonPoison = "poison"
+
+	// DropReasonError indicates that messages that failed to be delivered were
+	// acknowledged regardless.
+	DropReasonError = "error"
+
+	// DropReasonBackPressure indicates that messages were acknowledged without
+	// delivery as an output applied back pressure.
+	DropReasonBackPressure = "back_pressure"
+)
+
+// DroppedPath is the path of the standard counter of messages dropped by a
+// component, which is partitioned by the label `reason`.
+const DroppedPath = "dropped_by_reason"
+
+// GetDroppedCounter returns the standard counter of messages dropped by a
+// component for a given reason.
+func GetDroppedCounter(stats Type, reason string) StatCounter {
+	return stats.GetCounterVec(DroppedPath, []string{"reason"}).With(reason)
+}
+
+// NewDroppedCounter returns a counter that increments both a component
+// specific counter at a path and the standard counter of messages dropped for
+// a given reason.
+func NewDroppedCounter(stats Type, path, reason string) StatCounter {
+	return &combinedCounter{
+		c1: stats.GetCounter(path),
+		c2: GetDroppedCounter(stats, reason),
+	}
+}
+
+//------------------------------------------------------------------------------
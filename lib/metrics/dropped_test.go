@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingStat struct {
+	counts map[string]int64
+	key    string
+}
+
+func (c countingStat) Incr(count int64) error {
+	c.counts[c.key] += count
+	return nil
+}
+
+type countingType struct {
+	DudType
+	counts map[string]int64
+}
+
+func (c countingType) GetCounter(path string) StatCounter {
+	return countingStat{counts: c.counts, key: path}
+}
+
+func (c countingType) GetCounterVec(path string, n []string) StatCounterVec {
+	return fakeCounterVec(func(v []string) StatCounter {
+		key := path
+		for i, name := range n {
+			key += "," + name + "=" + v[i]
+		}
+		return countingStat{counts: c.counts, key: key}
+	})
+}
+
+func TestDroppedCounters(t *testing.T) {
+	stats := countingType{counts: map[string]int64{}}
+
+	NewDroppedCounter(stats, "foo.dropped", DropReasonFilter).Incr(2)
+	NewDroppedCounter(stats, "bar.dropped", DropReasonFilter).Incr(1)
+	GetDroppedCounter(stats, DropReasonPoison).Incr(3)
+
+	assert.Equal(t, map[string]int64{
+		"foo.dropped":                     2,
+		"bar.dropped":                     1,
+		"dropped_by_reason,reason=filter": 3,
+		"dropped_by_reason,reason=poison": 3,
+	}, stats.counts)
+}
@@ -57,12 +57,17 @@ func (p *PromCounter) Incr(count int64) error {
 // PromTiming is a representation of a single metric stat. Interactions with
 // this stat are thread safe.
 type PromTiming struct {
-	sum prometheus.Observer
+	sum   prometheus.Observer
+	scale float64
 }
 
 // Timing sets a timing metric.
 func (p *PromTiming) Timing(val int64) error {
-	p.sum.Observe(float64(val))
+	vFloat := float64(val)
+	if p.scale != 0 {
+		vFloat *= p.scale
+	}
+	p.sum.Observe(vFloat)
 	return nil
 }
 
@@ -82,13 +87,15 @@ func (p *PromCounterVec) With(labelValues ...string) StatCounter {
 
 // PromTimingVec creates StatTimers with dynamic labels.
 type PromTimingVec struct {
-	sum *prometheus.SummaryVec
+	sum   prometheus.ObserverVec
+	scale float64
 }
 
 // With returns a StatTimer with a set of label values.
 func (p *PromTimingVec) With(labelValues ...string) StatTimer {
 	return &PromTiming{
-		sum: p.sum.WithLabelValues(labelValues...),
+		sum:   p.sum.WithLabelValues(labelValues...),
+		scale: p.scale,
 	}
 }
 
@@ -122,7 +129,7 @@ type Prometheus struct {
 
 	counters map[string]*prometheus.CounterVec
 	gauges   map[string]*prometheus.GaugeVec
-	timers   map[string]*prometheus.SummaryVec
+	timers   map[string]prometheus.ObserverVec
 
 	sync.Mutex
 }
@@ -138,7 +145,7 @@ func NewPrometheus(config Config, opts ...func(Type)) (Type, error) {
 		reg:        prometheus.NewRegistry(),
 		counters:   map[string]*prometheus.CounterVec{},
 		gauges:     map[string]*prometheus.GaugeVec{},
-		timers:     map[string]*prometheus.SummaryVec{},
+		timers:     map[string]prometheus.ObserverVec{},
 	}
 
 	for _, opt := range opts {
@@ -232,6 +239,38 @@ func (p *Prometheus) GetCounter(path string) StatCounter {
 	}
 }
 
+// newTimerVec creates either a histogram or a summary for timing metrics
+// depending on the config.
+func (p *Prometheus) newTimerVec(stat string, labelNames []string) prometheus.ObserverVec {
+	if p.config.UseHistogram {
+		buckets := p.config.Buckets
+		if len(buckets) == 0 {
+			buckets = prometheus.DefBuckets
+		}
+		return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: p.prefix,
+			Name:      stat,
+			Help:      "Benthos Timing metric",
+			Buckets:   buckets,
+		}, labelNames)
+	}
+	return prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace:  p.prefix,
+		Name:       stat,
+		Help:       "Benthos Timing metric",
+		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+	}, labelNames)
+}
+
+// timingScale returns the factor applied to timing values in nanoseconds,
+// histograms are observed in seconds in order to match their buckets.
+func (p *Prometheus) timingScale() float64 {
+	if p.config.UseHistogram {
+		return 1e-9
+	}
+	return 0
+}
+
 // GetTimer returns a stat timer object for a path.
 func (p *Prometheus) GetTimer(path string) StatTimer {
 	stat, labels, values := p.toPromName(path)
@@ -239,24 +278,20 @@ func (p *Prometheus) GetTimer(path string) StatTimer {
 		return DudStat{}
 	}
 
-	var tmr *prometheus.SummaryVec
+	var tmr prometheus.ObserverVec
 
 	p.Lock()
 	var exists bool
 	if tmr, exists = p.timers[stat]; !exists {
-		tmr = prometheus.NewSummaryVec(prometheus.SummaryOpts{
-			Namespace:  p.prefix,
-			Name:       stat,
-			Help:       "Benthos Timing metric",
-			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
-		}, labels)
+		tmr = p.newTimerVec(stat, labels)
 		p.reg.MustRegister(tmr)
 		p.timers[stat] = tmr
 	}
 	p.Unlock()
 
 	return &PromTiming{
-		sum: tmr.WithLabelValues(values...),
+		sum:   tmr.WithLabelValues(values...),
+		scale: p.timingScale(),
 	}
 }
 
@@ -344,17 +379,12 @@ func (p *Prometheus) GetTimerVec(path string, labelNames []string) StatTimerVec
 		labelNames = append(labels, labelNames...)
 	}
 
-	var tmr *prometheus.SummaryVec
+	var tmr prometheus.ObserverVec
 
 	p.Lock()
 	var exists bool
 	if tmr, exists = p.timers[stat]; !exists {
-		tmr = prometheus.NewSummaryVec(prometheus.SummaryOpts{
-			Namespace:  p.prefix,
-			Name:       stat,
-			Help:       "Benthos Timing metric",
-			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
-		}, labelNames)
+		tmr = p.newTimerVec(stat, labelNames)
 		p.reg.MustRegister(tmr)
 		p.timers[stat] = tmr
 	}
@@ -365,12 +395,14 @@ func (p *Prometheus) GetTimerVec(path string, labelNames []string) StatTimerVec
 			fvs := append([]string{}, values...)
 			fvs = append(fvs, vs...)
 			return (&PromTimingVec{
-				sum: tmr,
+				sum:   tmr,
+				scale: p.timingScale(),
 			}).With(fvs...)
 		})
 	}
 	return &PromTimingVec{
-		sum: tmr,
+		sum:   tmr,
+		scale: p.timingScale(),
 	}
 }
 
@@ -14,6 +14,8 @@ Metrics paths will differ from [the standard list](/docs/components/metrics/abou
 		FieldSpecs: docs.FieldSpecs{
 			docs.FieldCommon("prefix", "A string prefix to add to all metrics."),
 			pathMappingDocs(true, true),
+			docs.FieldAdvanced("use_histogram_timing", "Whether to export timing metrics as a histogram, if `false` a summary is used instead. When exported as histograms timing metrics are measured in seconds rather than nanoseconds. For more information on histograms and summaries refer to: https://prometheus.io/docs/practices/histograms/.").HasType(docs.FieldTypeBool).AtVersion("3.54.0"),
+			docs.FieldAdvanced("histogram_buckets", "The upper bounds in seconds of the buckets of timing metrics exported as histograms, if empty the default buckets of the Prometheus client are used.", []float64{0.005, 0.05, 0.5, 5}).Array().HasType(docs.FieldTypeFloat).AtVersion("3.54.0"),
 			docs.FieldAdvanced("push_url", "An optional [Push Gateway URL](#push-gateway) to push metrics to."),
 			docs.FieldAdvanced("push_interval", "The period of time between each push when sending metrics to a Push Gateway."),
 			docs.FieldAdvanced("push_job_name", "An identifier for push jobs."),
@@ -44,6 +46,8 @@ If the Push Gateway requires HTTP Basic Authentication it can be configured with
 type PrometheusConfig struct {
 	Prefix        string                        `json:"prefix" yaml:"prefix"`
 	PathMapping   string                        `json:"path_mapping" yaml:"path_mapping"`
+	UseHistogram  bool                          `json:"use_histogram_timing" yaml:"use_histogram_timing"`
+	Buckets       []float64                     `json:"histogram_buckets" yaml:"histogram_buckets"`
 	PushURL       string                        `json:"push_url" yaml:"push_url"`
 	PushBasicAuth PrometheusPushBasicAuthConfig `json:"push_basic_auth" yaml:"push_basic_auth"`
 	PushInterval  string                        `json:"push_interval" yaml:"push_interval"`
@@ -70,6 +74,8 @@ func NewPrometheusConfig() PrometheusConfig {
 	return PrometheusConfig{
 		Prefix:        "benthos",
 		PathMapping:   "",
+		UseHistogram:  false,
+		Buckets:       []float64{},
 		PushURL:       "",
 		PushBasicAuth: NewPrometheusPushBasicAuthConfig(),
 		PushInterval:  "",
@@ -147,3 +147,31 @@ func TestPrometheusMetrics(t *testing.T) {
 	assert.Contains(t, body, "\ngaugetwo{label2=\"value3\"} 12")
 	assert.Contains(t, body, "\ntimertwo_sum{label3=\"value4\",label4=\"value5\"} 13")
 }
+
+func TestPrometheusHistogramMetrics(t *testing.T) {
+	conf := NewConfig()
+	conf.Prometheus.Prefix = ""
+	conf.Prometheus.UseHistogram = true
+	conf.Prometheus.Buckets = []float64{0.5, 5}
+	conf.Type = TypePrometheus
+
+	nm, err := New(conf)
+	require.NoError(t, err)
+
+	wHandler, ok := nm.(WithHandlerFunc)
+	require.True(t, ok)
+
+	tmr := nm.GetTimer("timerone")
+	tmr.Timing(int64(time.Second))
+
+	tmrTwo := nm.GetTimerVec("timertwo", []string{"label3"})
+	tmrTwo.With("value4").Timing(int64(time.Millisecond * 100))
+
+	body := getPage(t, wHandler.HandlerFunc())
+
+	assert.Contains(t, body, "\ntimerone_bucket{le=\"0.5\"} 0")
+	assert.Contains(t, body, "\ntimerone_bucket{le=\"5\"} 1")
+	assert.Contains(t, body, "\ntimerone_sum 1")
+	assert.Contains(t, body, "\ntimertwo_bucket{label3=\"value4\",le=\"0.5\"} 1")
+	assert.Contains(t, body, "\ntimertwo_sum{label3=\"value4\"} 0.1")
+}
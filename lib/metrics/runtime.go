@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"runtime"
+	"time"
+)
+
+//------------------------------------------------------------------------------
+
+// RuntimeGoroutinesPath is the path of the gauge reporting the number of
+// goroutines of the process.
+const RuntimeGoroutinesPath = "runtime.goroutines"
+
+// ReportRuntime sets gauges describing the Go runtime of the process, which is
+// currently the number of goroutines, at an interval until the returned func is
+// called.
+func ReportRuntime(stats Type, interval time.Duration) (stop func()) {
+	mGoroutines := stats.GetGauge(RuntimeGoroutinesPath)
+	mGoroutines.Set(int64(runtime.NumGoroutine()))
+
+	closeChan, doneChan := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(doneChan)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				mGoroutines.Set(int64(runtime.NumGoroutine()))
+			case <-closeChan:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(closeChan)
+		<-doneChan
+	}
+}
+
+//------------------------------------------------------------------------------
@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportRuntime(t *testing.T) {
+	stats := NewLocal()
+	stop := ReportRuntime(stats, time.Millisecond)
+	defer stop()
+
+	goroutines := stats.GetCounters()[RuntimeGoroutinesPath]
+	assert.Greater(t, goroutines, int64(0))
+	assert.Less(t, goroutines, int64(1000))
+
+	blockChan := make(chan struct{})
+	defer close(blockChan)
+	for i := 0; i < 1000; i++ {
+		go func() { <-blockChan }()
+	}
+
+	deadline := time.Now().Add(time.Second * 5)
+	for stats.GetCounters()[RuntimeGoroutinesPath] < 1000 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for goroutines gauge")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
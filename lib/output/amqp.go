@@ -7,6 +7,7 @@ import (
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/output/writer"
 	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/proxy"
 	"github.com/Jeffail/benthos/v3/lib/util/tls"
 )
 
@@ -38,11 +39,15 @@ Please use [` + "`amqp_0_9`" + `](/docs/components/outputs/amqp_0_9) instead.`,
 			docs.FieldAdvanced("content_type", "The content type attribute to set for each message.").IsInterpolated(),
 			docs.FieldAdvanced("content_encoding", "The content encoding attribute to set for each message.").IsInterpolated(),
 			docs.FieldCommon("metadata", "Specify criteria for which metadata values are attached to objects as headers.").WithChildren(output.MetadataFields()...),
+			docs.FieldString("headers", "An optional map of headers to set for each message in addition to metadata, where the values are interpolated.").IsInterpolated().Map().Advanced().AtVersion("3.54.0"),
 			docs.FieldAdvanced("priority", "Set the priority of each message with a dynamic interpolated expression.", "0", `${! meta("amqp_priority") }`, `${! json("doc.priority") }`).IsInterpolated(),
+			docs.FieldAdvanced("correlation_id", "Set the correlation ID of each message with a dynamic interpolated expression.").IsInterpolated().AtVersion("3.54.0"),
+			docs.FieldAdvanced("reply_to", "Set the reply-to address of each message with a dynamic interpolated expression.").IsInterpolated().AtVersion("3.54.0"),
 			docs.FieldCommon("max_in_flight", "The maximum number of messages to have in flight at a given time. Increase this to improve throughput."),
 			docs.FieldAdvanced("persistent", "Whether message delivery should be persistent (transient by default)."),
 			docs.FieldAdvanced("mandatory", "Whether to set the mandatory flag on published messages. When set if a published message is routed to zero queues it is returned."),
 			docs.FieldAdvanced("immediate", "Whether to set the immediate flag on published messages. When set if there are no ready consumers of a queue then the message is dropped instead of waiting."),
+			proxy.FieldSpec(),
 			tls.FieldSpec(),
 		},
 	}
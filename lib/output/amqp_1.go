@@ -35,6 +35,7 @@ Message metadata is added to each AMQP message as string annotations. In order t
 			tls.FieldSpec(),
 			sasl.FieldSpec(),
 			docs.FieldCommon("metadata", "Specify criteria for which metadata values are attached to messages as headers.").WithChildren(output.MetadataFields()...),
+			docs.FieldString("application_properties", "An optional map of application properties to set for each message, where the values are interpolated.", map[string]string{"source": "benthos", "tenant": `${! meta("tenant") }`}).IsInterpolated().Map().Advanced().AtVersion("3.54.0"),
 		},
 		Categories: []Category{
 			CategoryServices,
@@ -9,13 +9,16 @@ import (
 	"github.com/Jeffail/benthos/v3/internal/batch"
 	"github.com/Jeffail/benthos/v3/internal/bloblang"
 	"github.com/Jeffail/benthos/v3/internal/bloblang/mapping"
+	"github.com/Jeffail/benthos/v3/internal/component/output"
 	"github.com/Jeffail/benthos/v3/internal/shutdown"
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/message/roundtrip"
 	"github.com/Jeffail/benthos/v3/lib/message/tracing"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/response"
 	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/throttle"
 	"github.com/cenkalti/backoff/v4"
 	"github.com/opentracing/opentracing-go"
 )
@@ -44,12 +47,15 @@ type AsyncWriter struct {
 	typeStr     string
 	maxInflight int
 	noCancel    bool
+	fireForget  bool
 	writer      AsyncSink
 
 	injectTracingMap *mapping.Executor
 
-	log   log.Modular
-	stats metrics.Type
+	log      log.Modular
+	stats    metrics.Type
+	mBusy    metrics.StatGauge
+	throttle *throttle.Adaptive
 
 	transactions <-chan types.Transaction
 
@@ -70,6 +76,8 @@ func NewAsyncWriter(
 		writer:       w,
 		log:          log,
 		stats:        stats,
+		mBusy:        stats.GetGauge("workers.busy"),
+		throttle:     throttle.NewAdaptive(),
 		transactions: nil,
 		shutSig:      shutdown.NewSignaller(),
 	}
@@ -93,9 +101,23 @@ func (w *AsyncWriter) SetNoCancel() {
 	w.noCancel = true
 }
 
+// SetFireAndForget configures the async writer so that messages are
+// acknowledged as soon as they are received rather than once they have been
+// written. This is intended for sinks that cannot confirm delivery, such as UDP
+// or PUB sockets, where waiting for a write offers no delivery guarantee and
+// only holds up the buffer. Messages that then fail to be written are dropped,
+// and deliveries are counted with the metrics sent.assumed and
+// batch.sent.assumed instead of sent and batch.sent.
+func (w *AsyncWriter) SetFireAndForget() {
+	w.fireForget = true
+}
+
 //------------------------------------------------------------------------------
 
 func (w *AsyncWriter) latencyMeasuringWrite(msg types.Message) (latencyNs int64, err error) {
+	w.mBusy.Incr(1)
+	defer w.mBusy.Decr(1)
+
 	t0 := time.Now()
 	var ctx context.Context
 	if w.noCancel {
@@ -161,11 +183,26 @@ func (w *AsyncWriter) loop() {
 		mPartsSent  = w.stats.GetCounter("sent")
 		mSent       = w.stats.GetCounter("batch.sent")
 		mBytesSent  = w.stats.GetCounter("batch.bytes")
+		mPartsAssum = w.stats.GetCounter("sent.assumed")
+		mAssumed    = w.stats.GetCounter("batch.sent.assumed")
 		mLatency    = w.stats.GetTimer("batch.latency")
+		mDelivery   = w.stats.GetTimer("batch.delivery_latency")
 		mConn       = w.stats.GetCounter("connection.up")
 		mFailedConn = w.stats.GetCounter("connection.failed")
 		mLostConn   = w.stats.GetCounter("connection.lost")
+		mWorkers    = w.stats.GetGauge("workers")
+		mThrottled  = w.stats.GetCounter("throttled")
+		mInterval   = w.stats.GetGauge("throttle.interval")
 	)
+	mWorkers.Set(int64(w.maxInflight))
+
+	if tr, ok := w.writer.(output.ThrottleReporter); ok {
+		tr.OnThrottle(func(retryAfter time.Duration) {
+			w.throttle.Throttled(retryAfter)
+			mThrottled.Incr(1)
+			mInterval.Set(w.throttle.Interval().Milliseconds())
+		})
+	}
 
 	defer func() {
 		w.writer.CloseAsync()
@@ -256,6 +293,27 @@ func (w *AsyncWriter) loop() {
 				return
 			}
 
+			// Slow down when the sink has signalled that it is throttling
+			// writes.
+			if w.throttle.Paced() {
+				throttleCtx, throttleDone := w.shutSig.CloseAtLeisureCtx(context.Background())
+				permitted := w.throttle.Wait(throttleCtx)
+				throttleDone()
+				if !permitted {
+					return
+				}
+			}
+
+			// Without acknowledgements from the sink there is nothing to gain
+			// from holding the transaction open until the write completes.
+			if w.fireForget {
+				select {
+				case ts.ResponseChan <- response.NewAck():
+				case <-w.shutSig.CloseAtLeisureChan():
+					return
+				}
+			}
+
 			w.log.Tracef("Attempting to write %v messages to '%v'.\n", ts.Payload.Len(), w.typeStr)
 			spans := tracing.CreateChildSpans("output_"+w.typeStr, ts.Payload)
 			ts.Payload = w.injectSpans(ts.Payload, spans)
@@ -272,6 +330,15 @@ func (w *AsyncWriter) loop() {
 				return
 			}
 
+			if retryAfter, throttled := output.IsThrottled(err); throttled {
+				w.throttle.Throttled(retryAfter)
+				mThrottled.Incr(1)
+				mInterval.Set(w.throttle.Interval().Milliseconds())
+			} else if err == nil && w.throttle.Interval() > 0 {
+				w.throttle.Succeeded()
+				mInterval.Set(w.throttle.Interval().Milliseconds())
+			}
+
 			if err != nil {
 				if w.typeStr != TypeReject {
 					// TODO: Maybe reintroduce a sleep here if we encounter a
@@ -281,17 +348,31 @@ func (w *AsyncWriter) loop() {
 					w.log.Debugf("Rejecting message: %v\n", err)
 				}
 			} else {
-				mSent.Incr(1)
-				mPartsSent.Incr(int64(batch.MessageCollapsedCount(ts.Payload)))
+				if w.fireForget {
+					mAssumed.Incr(1)
+					mPartsAssum.Incr(int64(batch.MessageCollapsedCount(ts.Payload)))
+				} else {
+					mSent.Incr(1)
+					mPartsSent.Incr(int64(batch.MessageCollapsedCount(ts.Payload)))
+				}
 				mBytesSent.Incr(int64(message.GetAllBytesLen(ts.Payload)))
 				mLatency.Timing(latency)
+				mDelivery.Timing(time.Since(message.ShiftedAt(ts.Payload)).Nanoseconds())
 				w.log.Tracef("Successfully wrote %v messages to '%v'.\n", ts.Payload.Len(), w.typeStr)
+				roundtrip.SetAsDelivered(ts.Payload)
 			}
 
 			for _, s := range spans {
 				s.Finish()
 			}
 
+			if w.fireForget {
+				if err != nil {
+					w.log.Warnf("Dropped %v messages that failed to send to %v\n", ts.Payload.Len(), w.typeStr)
+				}
+				continue
+			}
+
 			select {
 			case ts.ResponseChan <- response.NewError(err):
 			case <-w.shutSig.CloseAtLeisureChan():
@@ -311,7 +392,7 @@ func (w *AsyncWriter) Consume(ts <-chan types.Transaction) error {
 	if w.transactions != nil {
 		return types.ErrAlreadyStarted
 	}
-	w.transactions = ts
+	w.transactions = queueTransactions(ts, w.maxInflight, w.shutSig.CloseAtLeisureChan(), w.stats.GetGauge("queue"))
 	go w.loop()
 	return nil
 }
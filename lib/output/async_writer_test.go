@@ -7,10 +7,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Jeffail/benthos/v3/internal/component/output"
+	"github.com/Jeffail/benthos/v3/lib/buffer"
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/message"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 //------------------------------------------------------------------------------
@@ -566,6 +570,312 @@ func TestAsyncWriterHappyPath(t *testing.T) {
 	}
 }
 
+func TestAsyncWriterDeliveryLatency(t *testing.T) {
+	t.Parallel()
+
+	writerImpl := newMockWriter()
+	stats := metrics.NewLocal()
+
+	w, err := NewAsyncWriter("foo", 1, writerImpl, log.Noop(), stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msgChan := make(chan types.Transaction)
+	resChan := make(chan types.Response)
+
+	if err = w.Consume(msgChan); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := message.New([][]byte{[]byte("foo")})
+	<-time.After(time.Millisecond * 50)
+
+	go func() {
+		select {
+		case msgChan <- types.NewTransaction(msg, resChan):
+		case <-time.After(time.Second):
+			t.Error("Timed out")
+		}
+	}()
+
+	select {
+	case writerImpl.connChan <- nil:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+	select {
+	case writerImpl.writeChan <- nil:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+	select {
+	case <-resChan:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+
+	w.CloseAsync()
+	if err = w.WaitForClose(time.Second); err != nil {
+		t.Error(err)
+	}
+
+	// The delivery latency includes the period since the batch was created.
+	if act := stats.GetTimings()["batch.delivery_latency"]; act < int64(time.Millisecond*50) {
+		t.Errorf("Delivery latency too low: %v", time.Duration(act))
+	}
+}
+
+func TestAsyncWriterThrottled(t *testing.T) {
+	t.Parallel()
+
+	writerImpl := newMockWriter()
+	stats := metrics.NewLocal()
+
+	w, err := NewAsyncWriter("foo", 1, writerImpl, log.Noop(), stats)
+	require.NoError(t, err)
+
+	msgChan := make(chan types.Transaction)
+	resChan := make(chan types.Response)
+	require.NoError(t, w.Consume(msgChan))
+
+	sendMsg := func() {
+		select {
+		case msgChan <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), resChan):
+		case <-time.After(time.Second):
+			t.Error("Timed out")
+		}
+	}
+
+	go sendMsg()
+	select {
+	case writerImpl.connChan <- nil:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+	select {
+	case writerImpl.writeChan <- output.ErrThrottled{
+		Err:        errors.New("too many requests"),
+		RetryAfter: time.Millisecond * 100,
+	}:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+	select {
+	case res := <-resChan:
+		assert.EqualError(t, res.Error(), "too many requests")
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+	throttledAt := time.Now()
+
+	// The next write must not be attempted until the period requested by the
+	// sink has passed.
+	go sendMsg()
+	select {
+	case writerImpl.writeChan <- nil:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+	assert.True(t, time.Since(throttledAt) >= time.Millisecond*90)
+	select {
+	case res := <-resChan:
+		assert.NoError(t, res.Error())
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+
+	w.CloseAsync()
+	require.NoError(t, w.WaitForClose(time.Second))
+
+	assert.Equal(t, int64(1), stats.GetCounters()["throttled"])
+}
+
+func TestAsyncWriterFireAndForget(t *testing.T) {
+	t.Parallel()
+
+	writerImpl := newMockWriter()
+	stats := metrics.NewLocal()
+
+	w, err := NewAsyncWriter("foo", 1, writerImpl, log.Noop(), stats)
+	require.NoError(t, err)
+	w.(*AsyncWriter).SetFireAndForget()
+
+	msgChan := make(chan types.Transaction)
+	resChan := make(chan types.Response)
+	require.NoError(t, w.Consume(msgChan))
+
+	select {
+	case writerImpl.connChan <- nil:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+
+	// Both messages are acknowledged before they are written, including the
+	// one that fails.
+	for _, writeErr := range []error{errors.New("nope"), nil} {
+		select {
+		case msgChan <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), resChan):
+		case <-time.After(time.Second):
+			t.Fatal("Timed out")
+		}
+		select {
+		case res := <-resChan:
+			assert.NoError(t, res.Error())
+		case <-time.After(time.Second):
+			t.Fatal("Timed out")
+		}
+		select {
+		case writerImpl.writeChan <- writeErr:
+		case <-time.After(time.Second):
+			t.Fatal("Timed out")
+		}
+	}
+
+	w.CloseAsync()
+	require.NoError(t, w.WaitForClose(time.Second))
+
+	counters := stats.GetCounters()
+	assert.Equal(t, int64(1), counters["batch.sent.assumed"])
+	assert.Equal(t, int64(1), counters["sent.assumed"])
+	assert.Equal(t, int64(0), counters["batch.sent"])
+	assert.Equal(t, int64(0), counters["sent"])
+}
+
+type throttleReportingWriter struct {
+	*mockWriter
+	onThrottle func(time.Duration)
+}
+
+func (w *throttleReportingWriter) OnThrottle(fn func(time.Duration)) {
+	w.onThrottle = fn
+}
+
+func TestAsyncWriterThrottleReporter(t *testing.T) {
+	t.Parallel()
+
+	writerImpl := &throttleReportingWriter{mockWriter: newMockWriter()}
+	stats := metrics.NewLocal()
+
+	w, err := NewAsyncWriter("foo", 1, writerImpl, log.Noop(), stats)
+	require.NoError(t, err)
+
+	msgChan := make(chan types.Transaction)
+	resChan := make(chan types.Response)
+	require.NoError(t, w.Consume(msgChan))
+
+	sendMsg := func() {
+		select {
+		case msgChan <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), resChan):
+		case <-time.After(time.Second):
+			t.Error("Timed out")
+		}
+	}
+
+	go sendMsg()
+	select {
+	case writerImpl.connChan <- nil:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+
+	// The write succeeds but the sink reports that it was throttled.
+	require.NotNil(t, writerImpl.onThrottle)
+	writerImpl.onThrottle(time.Millisecond * 100)
+	throttledAt := time.Now()
+	select {
+	case writerImpl.writeChan <- nil:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+	select {
+	case res := <-resChan:
+		assert.NoError(t, res.Error())
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+
+	go sendMsg()
+	select {
+	case writerImpl.writeChan <- nil:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+	assert.True(t, time.Since(throttledAt) >= time.Millisecond*90)
+	select {
+	case res := <-resChan:
+		assert.NoError(t, res.Error())
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+
+	w.CloseAsync()
+	require.NoError(t, w.WaitForClose(time.Second))
+
+	assert.Equal(t, int64(1), stats.GetCounters()["throttled"])
+}
+
+func TestAsyncWriterDeliveryLatencyBuffered(t *testing.T) {
+	t.Parallel()
+
+	writerImpl := newMockWriter()
+	stats := metrics.NewLocal()
+
+	w, err := NewAsyncWriter("foo", 1, writerImpl, log.Noop(), stats)
+	require.NoError(t, err)
+
+	bufConf := buffer.NewConfig()
+	bufConf.Type = buffer.TypeMemory
+	buf, err := buffer.New(bufConf, nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	msgChan := make(chan types.Transaction)
+	resChan := make(chan types.Response)
+
+	require.NoError(t, buf.Consume(msgChan))
+	require.NoError(t, w.Consume(buf.TransactionChan()))
+
+	// The message ages before it reaches the buffer, which must not count
+	// towards the delivery latency.
+	msg := message.New([][]byte{[]byte("foo")})
+	<-time.After(time.Millisecond * 100)
+
+	select {
+	case msgChan <- types.NewTransaction(msg, resChan):
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+	select {
+	case res := <-resChan:
+		require.NoError(t, res.Error())
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+
+	select {
+	case writerImpl.connChan <- nil:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+	select {
+	case writerImpl.writeChan <- nil:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+
+	assert.Eventually(t, func() bool {
+		_, exists := stats.GetTimings()["batch.delivery_latency"]
+		return exists
+	}, time.Second, time.Millisecond*10)
+
+	buf.CloseAsync()
+	require.NoError(t, buf.WaitForClose(time.Second))
+	w.CloseAsync()
+	require.NoError(t, w.WaitForClose(time.Second))
+
+	assert.Less(t, stats.GetTimings()["batch.delivery_latency"], int64(time.Millisecond*100))
+}
+
 func TestAsyncWriterSadPath(t *testing.T) {
 	t.Parallel()
 
@@ -635,3 +945,49 @@ func TestAsyncWriterSadPath(t *testing.T) {
 }
 
 //------------------------------------------------------------------------------
+
+func TestAsyncWriterQueuesWhileConnecting(t *testing.T) {
+	t.Parallel()
+
+	writerImpl := newAsyncMockWriter()
+
+	w, err := NewAsyncWriter("foo", 2, writerImpl, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	msgChan := make(chan types.Transaction)
+	resChan := make(chan types.Response)
+	require.NoError(t, w.Consume(msgChan))
+
+	// The writer is still connecting, but transactions are accepted up to the
+	// size of the queue.
+	for i := 0; i < 2; i++ {
+		select {
+		case msgChan <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), resChan):
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+
+	select {
+	case writerImpl.connChan <- nil:
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case writerImpl.writeChan <- nil:
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+		select {
+		case res := <-resChan:
+			assert.NoError(t, res.Error())
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+
+	w.CloseAsync()
+	require.NoError(t, w.WaitForClose(time.Second))
+}
@@ -20,6 +20,10 @@ func init() {
 		Summary: `
 Sends messages to a Kinesis Firehose delivery stream.`,
 		Description: `
+Batches of messages are sent with PutRecordBatch requests of up to 500 records
+and 4 MiB each, larger batches are split across multiple requests. Messages
+larger than the Firehose record limit of 1000 KiB are rejected.
+
 ### Credentials
 
 By default Benthos will use a shared credentials file when connecting to AWS
@@ -114,6 +114,8 @@ output:
 				"STANDARD", "REDUCED_REDUNDANCY", "GLACIER", "STANDARD_IA", "ONEZONE_IA", "INTELLIGENT_TIERING", "DEEP_ARCHIVE",
 			).IsInterpolated(),
 			docs.FieldAdvanced("kms_key_id", "An optional server side encryption key."),
+			docs.FieldAdvanced("upload_part_size", "Objects larger than this size in bytes are uploaded in parts with a multipart upload, the minimum part size is 5MiB. Since the number of parts of an object is limited to 10,000 this should be increased for objects larger than 50GiB.").HasDefault(5242880).AtVersion("3.54.0"),
+			docs.FieldAdvanced("upload_concurrency", "The number of parts of a multipart upload to send in parallel. Note that each part is held in memory whilst it is being uploaded.").HasDefault(5).AtVersion("3.54.0"),
 			docs.FieldAdvanced("force_path_style_urls", "Forces the client API to use path style URLs, which helps when connecting to custom endpoints."),
 			docs.FieldCommon("max_in_flight", "The maximum number of messages to have in flight at a given time. Increase this to improve throughput."),
 			docs.FieldAdvanced("timeout", "The maximum period to wait on an upload before abandoning it and reattempting."),
@@ -229,6 +231,8 @@ output:
 				"STANDARD", "REDUCED_REDUNDANCY", "GLACIER", "STANDARD_IA", "ONEZONE_IA", "INTELLIGENT_TIERING", "DEEP_ARCHIVE",
 			).IsInterpolated(),
 			docs.FieldAdvanced("kms_key_id", "An optional server side encryption key."),
+			docs.FieldAdvanced("upload_part_size", "Objects larger than this size in bytes are uploaded in parts with a multipart upload, the minimum part size is 5MiB. Since the number of parts of an object is limited to 10,000 this should be increased for objects larger than 50GiB.").HasDefault(5242880).AtVersion("3.54.0"),
+			docs.FieldAdvanced("upload_concurrency", "The number of parts of a multipart upload to send in parallel. Note that each part is held in memory whilst it is being uploaded.").HasDefault(5).AtVersion("3.54.0"),
 			docs.FieldAdvanced("force_path_style_urls", "Forces the client API to use path style URLs, which helps when connecting to custom endpoints."),
 			docs.FieldCommon("max_in_flight", "The maximum number of messages to have in flight at a given time. Increase this to improve throughput."),
 			docs.FieldAdvanced("timeout", "The maximum period to wait on an upload before abandoning it and reattempting."),
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"github.com/Jeffail/benthos/v3/internal/component/output"
+	"github.com/Jeffail/benthos/v3/internal/docs"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message/batch"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/output/writer"
+	"github.com/Jeffail/benthos/v3/lib/types"
+)
+
+//------------------------------------------------------------------------------
+
+func init() {
+	Constructors[TypeAzureEventHub] = TypeSpec{
+		constructor: fromSimpleConstructor(NewAzureEventHub),
+		Status:      docs.StatusExperimental,
+		Version:     "3.54.0",
+		Summary: `
+Publishes events to an Azure event hub through the Kafka compatible endpoint of its namespace.`,
+		Description: `
+The Kafka compatible endpoint of Event Hubs is available in the standard tier and above. The address of the endpoint and the credentials used to authenticate with it are derived from the connection string of the namespace or event hub.
+
+Events are distributed across the partitions of the event hub according to their ` + "`partition_key`" + `, and events that share a key are written to the same partition in the order that they were sent. Alternatively, an explicit ` + "`partition`" + ` can be set for each event. Metadata is sent as the properties of events according to the ` + "`metadata`" + ` field.
+
+Event Hubs rejects events larger than the maximum event size of the tier of the namespace, which is 1MB for the standard tier.`,
+		Async:   true,
+		Batches: true,
+		FieldSpecs: docs.FieldSpecs{
+			docs.FieldString("connection_string", "A connection string of the Event Hubs namespace or event hub, which is the password of the SASL `PLAIN` mechanism that the endpoint is authenticated with.", "Endpoint=sb://example.servicebus.windows.net/;SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=..."),
+			docs.FieldString("event_hub", "The event hub to publish to, which can be omitted when the connection string contains an `EntityPath`.", "my-event-hub"),
+			docs.FieldInterpolatedString("partition_key", "The key that events are distributed across partitions by. When empty each event is published to a random partition.", `${! meta("device_id") }`),
+			docs.FieldInterpolatedString("partition", "An optional explicit partition to publish each event to, which must resolve to a valid integer. When set the `partition_key` does not determine the partition.", `${! meta("partition") }`).Advanced(),
+			docs.FieldString("client_id", "An identifier for the client connection.").Advanced(),
+			docs.FieldCommon("metadata", "Specify criteria for which metadata values are sent with events as properties.").WithChildren(output.MetadataFields()...),
+			docs.FieldInt("max_in_flight", "The maximum number of parallel message batches to have in flight at any given time."),
+			docs.FieldInt("max_msg_bytes", "The maximum size in bytes of events sent to the event hub.").Advanced(),
+			docs.FieldString("timeout", "The maximum period of time to wait for message sends before abandoning the request and retrying.").Advanced(),
+			batch.FieldSpec(),
+		},
+		Categories: []Category{
+			CategoryServices,
+			CategoryAzure,
+		},
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// NewAzureEventHub creates a new AzureEventHub output type.
+func NewAzureEventHub(conf Config, mgr types.Manager, log log.Modular, stats metrics.Type) (Type, error) {
+	kConf, err := conf.AzureEventHub.KafkaConfig()
+	if err != nil {
+		return nil, err
+	}
+	k, err := writer.NewKafka(kConf, mgr, log, stats)
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewAsyncWriter(TypeAzureEventHub, kConf.MaxInFlight, k, log, stats)
+	if err != nil {
+		return nil, err
+	}
+	return NewBatcherFromConfig(kConf.Batching, w, mgr, log, stats)
+}
+
+//------------------------------------------------------------------------------
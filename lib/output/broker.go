@@ -3,7 +3,9 @@ package output
 import (
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/Jeffail/benthos/v3/internal/bloblang"
 	"github.com/Jeffail/benthos/v3/internal/component/output"
 	"github.com/Jeffail/benthos/v3/internal/docs"
 	"github.com/Jeffail/benthos/v3/internal/interop"
@@ -68,6 +70,25 @@ Sometimes it is useful to disable the back pressure or retries of certain fan
 out outputs and instead drop messages that have failed or were blocked. In this
 case you can wrap outputs with a ` + "[`drop_on` output](/docs/components/outputs/drop_on)" + `.
 
+### ` + "`fan_out_buffered`" + `
+
+Similar to the fan out pattern except each output is given its own independent
+buffer of messages, the size of which is determined by the field
+` + "`buffer_size`" + `. Messages are acknowledged as soon as they have been added
+to the buffer of every output, and each output consumes from its own buffer at
+its own pace, retrying failed messages continuously until completion or service
+shut down.
+
+This means that an output that is lagging behind or temporarily failing
+accumulates a backlog within its own buffer rather than applying back pressure
+to the input, and therefore to the other outputs, until that buffer is full. The
+backlog of each output can be monitored with the metric
+` + "`outputs.<index>.backlog`" + `.
+
+Since messages are acknowledged before they are delivered, any messages held
+within the buffers when the service is stopped are lost, which is the same
+trade-off as a ` + "[`memory` buffer](/docs/components/buffers/memory)" + `.
+
 ### ` + "`fan_out_sequential`" + `
 
 Similar to the fan out pattern except outputs are written to sequentially,
@@ -88,16 +109,55 @@ potentially disproportionate message allocations to those outputs. Each message
 is sent to a single output, which is determined by allowing outputs to claim
 messages as soon as they are able to process them. This results in certain
 faster outputs potentially processing more messages at the cost of slower
-outputs.`,
+outputs.
+
+### ` + "`mirror`" + `
+
+With the mirror pattern every message is sent to the first output, which is
+considered the primary, and a percentage of messages (determined by the field
+` + "`mirror_percentage`" + `) is also sent to all remaining outputs, which are
+considered shadows. This is useful for canarying a new sink with a sample of
+production traffic.
+
+Only the primary output determines whether a message is acknowledged. Messages
+are written to shadow outputs in the background without retries, and if a
+shadow output applies back pressure the messages destined for it are queued, up
+to ` + "`shadow_queue_size`" + ` messages, after which they are dropped rather than
+blocking the primary. Shadow delivery outcomes can be monitored with
+the metrics ` + "`shadow.sent`, `shadow.error` and `shadow.dropped`" + `.
+
+### ` + "`try`" + `
+
+With the try pattern each message is sent to the first output, and if that
+fails it is sent to the next output in the list, and so on, the same as a
+` + "[`try` output](/docs/components/outputs/try)" + `.
+
+When the first output is flapping between failing and recovering an input that
+redelivers messages can result in messages being delivered to both a fallback
+output and then again to the first output. In order to control these duplicates
+a ` + "`failover_dedupe` cache" + ` can be specified along with a ` + "`key`" + ` that
+uniquely identifies each message, in which case the messages delivered to a
+fallback output are recorded within the cache, and messages that are found
+within the cache are acknowledged without being sent again for the duration of
+the ` + "`failover_dedupe.window`" + `. The number of suppressed
+messages can be monitored with the metric ` + "`failover_dedupe.suppressed`" + `.`,
 		FieldSpecs: docs.FieldSpecs{
 			docs.FieldAdvanced("copies", "The number of copies of each configured output to spawn."),
 			docs.FieldCommon("pattern", "The brokering pattern to use.").HasOptions(
-				"fan_out", "fan_out_sequential", "round_robin", "greedy",
+				"fan_out", "fan_out_buffered", "fan_out_sequential", "round_robin", "greedy", "mirror", "try",
 			),
 			docs.FieldAdvanced(
 				"max_in_flight",
 				"The maximum number of parallel message batches to have in flight at any given time. Note that if a child output has a higher `max_in_flight` then the switch output will automatically match it, therefore this value is the minimum `max_in_flight` to set in cases where the child values can't be inferred (such as when using resource outputs as children). Only relevant for `fan_out`, `fan_out_sequential` brokers.",
 			),
+			docs.FieldAdvanced("mirror_percentage", "The percentage of messages, from 0 to 100, to send to shadow outputs. Only relevant for the `mirror` broker.").HasType(docs.FieldTypeFloat).AtVersion("3.54.0"),
+			docs.FieldAdvanced("shadow_queue_size", "The number of messages that may be queued for each shadow output before further messages destined for it are dropped. Only relevant for the `mirror` broker.").AtVersion("3.54.0"),
+			docs.FieldAdvanced("buffer_size", "The number of message batches that may be buffered for each output before back pressure is applied. Only relevant for the `fan_out_buffered` broker.").AtVersion("3.54.0"),
+			docs.FieldAdvanced("failover_dedupe", "Suppresses sending messages to the first output that have already been delivered to a fallback output within a window of time. Only relevant for the `try` broker.").WithChildren(
+				docs.FieldCommon("cache", "A [cache resource](/docs/components/caches/about) to record delivered messages within, which must support a TTL per item such as the `memory`, `redis` or `memcached` caches. Suppression is disabled when empty."),
+				docs.FieldCommon("key", "An interpolated string that uniquely identifies each message, which must be set when a `cache` is specified.", `${! meta("kafka_key") }`, `${! json("id") }`).IsInterpolated(),
+				docs.FieldCommon("window", "The length of time after a message is delivered to a fallback output during which it is suppressed."),
+			).AtVersion("3.54.0"),
 			docs.FieldCommon("outputs", "A list of child outputs to broker.").Array().HasType(docs.FieldTypeOutput),
 			batch.FieldSpec(),
 		},
@@ -111,21 +171,46 @@ outputs.`,
 
 // BrokerConfig contains configuration fields for the Broker output type.
 type BrokerConfig struct {
-	Copies      int                `json:"copies" yaml:"copies"`
-	Pattern     string             `json:"pattern" yaml:"pattern"`
-	MaxInFlight int                `json:"max_in_flight" yaml:"max_in_flight"`
-	Outputs     brokerOutputList   `json:"outputs" yaml:"outputs"`
-	Batching    batch.PolicyConfig `json:"batching" yaml:"batching"`
+	Copies           int                `json:"copies" yaml:"copies"`
+	Pattern          string             `json:"pattern" yaml:"pattern"`
+	MaxInFlight      int                `json:"max_in_flight" yaml:"max_in_flight"`
+	MirrorPercentage float64            `json:"mirror_percentage" yaml:"mirror_percentage"`
+	ShadowQueueSize  int                `json:"shadow_queue_size" yaml:"shadow_queue_size"`
+	BufferSize       int                `json:"buffer_size" yaml:"buffer_size"`
+	FailoverDedupe   BrokerDedupeConfig `json:"failover_dedupe" yaml:"failover_dedupe"`
+	Outputs          brokerOutputList   `json:"outputs" yaml:"outputs"`
+	Batching         batch.PolicyConfig `json:"batching" yaml:"batching"`
 }
 
 // NewBrokerConfig creates a new BrokerConfig with default values.
 func NewBrokerConfig() BrokerConfig {
 	return BrokerConfig{
-		Copies:      1,
-		Pattern:     "fan_out",
-		MaxInFlight: 1,
-		Outputs:     brokerOutputList{},
-		Batching:    batch.NewPolicyConfig(),
+		Copies:           1,
+		Pattern:          "fan_out",
+		MaxInFlight:      1,
+		MirrorPercentage: 100,
+		ShadowQueueSize:  100,
+		BufferSize:       100,
+		FailoverDedupe:   NewBrokerDedupeConfig(),
+		Outputs:          brokerOutputList{},
+		Batching:         batch.NewPolicyConfig(),
+	}
+}
+
+// BrokerDedupeConfig contains configuration fields for suppressing duplicates
+// sent to the first output of a try broker.
+type BrokerDedupeConfig struct {
+	Cache  string `json:"cache" yaml:"cache"`
+	Key    string `json:"key" yaml:"key"`
+	Window string `json:"window" yaml:"window"`
+}
+
+// NewBrokerDedupeConfig creates a new BrokerDedupeConfig with default values.
+func NewBrokerDedupeConfig() BrokerDedupeConfig {
+	return BrokerDedupeConfig{
+		Cache:  "",
+		Key:    "",
+		Window: "5m",
 	}
 }
 
@@ -196,6 +281,11 @@ func NewBroker(
 		if bTmp, err = broker.NewFanOut(outputs, log, stats); err == nil {
 			b = bTmp.WithMaxInFlight(maxInFlight)
 		}
+	case "fan_out_buffered":
+		var bTmp *broker.FanOutBuffered
+		if bTmp, err = broker.NewFanOutBuffered(outputs, log, stats); err == nil {
+			b = bTmp.WithBufferSize(conf.Broker.BufferSize)
+		}
 	case "fan_out_sequential":
 		var bTmp *broker.FanOutSequential
 		if bTmp, err = broker.NewFanOutSequential(outputs, log, stats); err == nil {
@@ -205,8 +295,24 @@ func NewBroker(
 		b, err = broker.NewRoundRobin(outputs, stats)
 	case "greedy":
 		b, err = broker.NewGreedy(outputs)
+	case "mirror":
+		if conf.Broker.Copies > 1 {
+			return nil, errors.New("the mirror broker pattern does not support copies")
+		}
+		var bTmp *broker.Mirror
+		if bTmp, err = broker.NewMirror(outputs, conf.Broker.MirrorPercentage, log, stats); err == nil {
+			b = bTmp.WithShadowQueueSize(conf.Broker.ShadowQueueSize)
+		}
 	case "try":
-		b, err = broker.NewTry(outputs, stats)
+		var bTmp *broker.Try
+		if bTmp, err = broker.NewTry(outputs, stats); err == nil {
+			if conf.Broker.FailoverDedupe.Cache != "" {
+				bTmp, err = withFailoverDedupe(bTmp, conf.Broker.FailoverDedupe, mgr, log)
+			}
+			if err == nil {
+				b = bTmp
+			}
+		}
 	default:
 		return nil, fmt.Errorf("broker pattern was not recognised: %v", conf.Broker.Pattern)
 	}
@@ -226,3 +332,20 @@ func NewBroker(
 }
 
 //------------------------------------------------------------------------------
+
+func withFailoverDedupe(t *broker.Try, conf BrokerDedupeConfig, mgr types.Manager, log log.Modular) (*broker.Try, error) {
+	if conf.Key == "" {
+		return nil, errors.New("a failover_dedupe key must be specified in order to identify messages")
+	}
+	key, err := bloblang.NewField(conf.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse failover_dedupe key expression: %v", err)
+	}
+	window, err := time.ParseDuration(conf.Window)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse failover_dedupe window: %v", err)
+	}
+	return t.WithFailoverDedupe(mgr, conf.Cache, key, window, log)
+}
+
+//------------------------------------------------------------------------------
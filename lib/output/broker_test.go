@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -375,3 +376,22 @@ func TestTryBroker(t *testing.T) {
 		}
 	}
 }
+
+func TestTryBrokerFailoverDedupeNoKey(t *testing.T) {
+	outOne, outTwo := NewConfig(), NewConfig()
+	outOne.Type, outTwo.Type = TypeDrop, TypeDrop
+
+	conf := NewConfig()
+	conf.Type = TypeBroker
+	conf.Broker.Pattern = "try"
+	conf.Broker.Outputs = append(conf.Broker.Outputs, outOne, outTwo)
+	conf.Broker.FailoverDedupe.Cache = "foocache"
+
+	_, err := New(conf, nil, log.Noop(), metrics.Noop())
+	if err == nil {
+		t.Fatal("Expected error from missing failover_dedupe key")
+	}
+	if exp, act := "key must be specified", err.Error(); !strings.Contains(act, exp) {
+		t.Errorf("Wrong error: %v != %v", act, exp)
+	}
+}
@@ -56,7 +56,8 @@ type TypeSpec struct {
 
 // AppendProcessorsFromConfig takes a variant arg of pipeline constructor
 // functions and returns a new slice of them where the processors of the
-// provided output configuration will also be initialized.
+// provided output configuration will also be initialized, followed by the
+// mapping of message parts when configured.
 func AppendProcessorsFromConfig(
 	conf Config,
 	mgr types.Manager,
@@ -83,6 +84,15 @@ func AppendProcessorsFromConfig(
 			return pipeline.NewProcessor(log, stats, processors...), nil
 		}}...)
 	}
+	if conf.Parts.Mode != "" {
+		pipelines = append(pipelines, func(i *int) (types.Pipeline, error) {
+			p, err := newParts(conf.Parts, log, stats)
+			if err != nil {
+				return nil, err
+			}
+			return pipeline.NewProcessor(log, stats, p), nil
+		})
+	}
 	return pipelines
 }
 
@@ -165,6 +175,7 @@ const (
 	TypeAWSSNS             = "aws_sns"
 	TypeAWSSQS             = "aws_sqs"
 	TypeAzureBlobStorage   = "azure_blob_storage"
+	TypeAzureEventHub      = "azure_event_hub"
 	TypeAzureQueueStorage  = "azure_queue_storage"
 	TypeAzureTableStorage  = "azure_table_storage"
 	TypeBlobStorage        = "blob_storage"
@@ -177,6 +188,7 @@ const (
 	TypeDynamic            = "dynamic"
 	TypeDynamoDB           = "dynamodb"
 	TypeElasticsearch      = "elasticsearch"
+	TypeFault              = "fault"
 	TypeFile               = "file"
 	TypeFiles              = "files"
 	TypeGCPCloudStorage    = "gcp_cloud_storage"
@@ -237,6 +249,7 @@ type Config struct {
 	AWSSNS             writer.SNSConfig               `json:"aws_sns" yaml:"aws_sns"`
 	AWSSQS             writer.AmazonSQSConfig         `json:"aws_sqs" yaml:"aws_sqs"`
 	AzureBlobStorage   writer.AzureBlobStorageConfig  `json:"azure_blob_storage" yaml:"azure_blob_storage"`
+	AzureEventHub      writer.AzureEventHubConfig     `json:"azure_event_hub" yaml:"azure_event_hub"`
 	AzureQueueStorage  writer.AzureQueueStorageConfig `json:"azure_queue_storage" yaml:"azure_queue_storage"`
 	AzureTableStorage  writer.AzureTableStorageConfig `json:"azure_table_storage" yaml:"azure_table_storage"`
 	BlobStorage        writer.AzureBlobStorageConfig  `json:"blob_storage" yaml:"blob_storage"`
@@ -249,6 +262,7 @@ type Config struct {
 	Dynamic            DynamicConfig                  `json:"dynamic" yaml:"dynamic"`
 	DynamoDB           writer.DynamoDBConfig          `json:"dynamodb" yaml:"dynamodb"`
 	Elasticsearch      writer.ElasticsearchConfig     `json:"elasticsearch" yaml:"elasticsearch"`
+	Fault              FaultConfig                    `json:"fault" yaml:"fault"`
 	File               FileConfig                     `json:"file" yaml:"file"`
 	Files              writer.FilesConfig             `json:"files" yaml:"files"`
 	GCPCloudStorage    GCPCloudStorageConfig          `json:"gcp_cloud_storage" yaml:"gcp_cloud_storage"`
@@ -293,6 +307,7 @@ type Config struct {
 	Websocket          writer.WebsocketConfig         `json:"websocket" yaml:"websocket"`
 	ZMQ4               *writer.ZMQ4Config             `json:"zmq4,omitempty" yaml:"zmq4,omitempty"`
 	Processors         []processor.Config             `json:"processors" yaml:"processors"`
+	Parts              PartsConfig                    `json:"parts" yaml:"parts"`
 }
 
 // NewConfig returns a configuration struct fully populated with default values.
@@ -310,6 +325,7 @@ func NewConfig() Config {
 		AWSSNS:             writer.NewSNSConfig(),
 		AWSSQS:             writer.NewAmazonSQSConfig(),
 		AzureBlobStorage:   writer.NewAzureBlobStorageConfig(),
+		AzureEventHub:      writer.NewAzureEventHubConfig(),
 		AzureQueueStorage:  writer.NewAzureQueueStorageConfig(),
 		AzureTableStorage:  writer.NewAzureTableStorageConfig(),
 		BlobStorage:        writer.NewAzureBlobStorageConfig(),
@@ -322,6 +338,7 @@ func NewConfig() Config {
 		Dynamic:            NewDynamicConfig(),
 		DynamoDB:           writer.NewDynamoDBConfig(),
 		Elasticsearch:      writer.NewElasticsearchConfig(),
+		Fault:              NewFaultConfig(),
 		File:               NewFileConfig(),
 		Files:              writer.NewFilesConfig(),
 		GCPCloudStorage:    NewGCPCloudStorageConfig(),
@@ -366,6 +383,7 @@ func NewConfig() Config {
 		Websocket:          writer.NewWebsocketConfig(),
 		ZMQ4:               writer.NewZMQ4Config(),
 		Processors:         []processor.Config{},
+		Parts:              NewPartsConfig(),
 	}
 }
 
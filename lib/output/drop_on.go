@@ -187,6 +187,9 @@ func (d *dropOn) loop() {
 	var (
 		mDropped      = d.stats.GetCounter("drop_on.dropped")
 		mDroppedBatch = d.stats.GetCounter("drop_on.batch.dropped")
+
+		mDroppedError        = metrics.GetDroppedCounter(d.stats, metrics.DropReasonError)
+		mDroppedBackPressure = metrics.GetDroppedCounter(d.stats, metrics.DropReasonBackPressure)
 	)
 
 	defer func() {
@@ -252,6 +255,7 @@ func (d *dropOn) loop() {
 					mDroppedBatch.Incr(1)
 					d.log.Warnln("Message dropped due to back pressure.")
 					if d.onError {
+						mDroppedBackPressure.Incr(int64(ts.Payload.Len()))
 						res = response.NewAck()
 					} else {
 						res = response.NewError(fmt.Errorf("experienced back pressure beyond: %v", d.onBackpressure))
@@ -279,6 +283,7 @@ func (d *dropOn) loop() {
 		if res.Error() != nil && d.onError {
 			mDropped.Incr(int64(ts.Payload.Len()))
 			mDroppedBatch.Incr(1)
+			mDroppedError.Incr(int64(ts.Payload.Len()))
 			d.log.Warnf("Message dropped due to: %v\n", res.Error())
 			res = response.NewAck()
 		}
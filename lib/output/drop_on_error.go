@@ -158,7 +158,7 @@ func NewDropOnError(
 func (d *DropOnError) loop() {
 	// Metrics paths
 	var (
-		mDropped      = d.stats.GetCounter("drop_on_error.dropped")
+		mDropped      = metrics.NewDroppedCounter(d.stats, "drop_on_error.dropped", metrics.DropReasonError)
 		mDroppedBatch = d.stats.GetCounter("drop_on_error.batch.dropped")
 	)
 
@@ -0,0 +1,347 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/component/output"
+	"github.com/Jeffail/benthos/v3/internal/docs"
+	"github.com/Jeffail/benthos/v3/internal/shutdown"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/clock"
+)
+
+//------------------------------------------------------------------------------
+
+func init() {
+	Constructors[TypeFault] = TypeSpec{
+		constructor: fromSimpleConstructor(func(conf Config, mgr types.Manager, log log.Modular, stats metrics.Type) (Type, error) {
+			if conf.Fault.Output == nil {
+				return nil, errors.New("cannot create a fault output without a child")
+			}
+			wrapped, err := New(*conf.Fault.Output, mgr, log, stats)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create output '%v': %v", conf.Fault.Output.Type, err)
+			}
+			return newFault(conf.Fault.FaultConditions, wrapped, log, stats)
+		}),
+		Summary: `
+Writes messages to a child output whilst injecting configurable errors, latency and disconnects, allowing you to test the retry and failover behaviour of a config before real outages occur.`,
+		Description: `
+Faults are injected for each message batch before it reaches the child output. A batch that is selected for an error is rejected without being written to the child, and a batch that is selected for a disconnect causes the output to report itself as disconnected and block all writes for the ` + "`disconnect_duration`" + `, mimicking an output that has lost its connection.
+
+This output is intended for testing and should not be used in production.`,
+		Categories: []Category{
+			CategoryUtility,
+		},
+		Status: docs.StatusExperimental,
+		FieldSpecs: docs.FieldSpecs{
+			docs.FieldCommon("error_percentage", "The percentage of message batches, from 0 to 100, to reject with an error.").HasType(docs.FieldTypeFloat),
+			docs.FieldCommon("latency", "An optional duration string of latency to add to each write.", "100ms", "1s"),
+			docs.FieldCommon("disconnect_percentage", "The percentage of message batches, from 0 to 100, that trigger a simulated disconnect.").HasType(docs.FieldTypeFloat),
+			docs.FieldCommon("disconnect_duration", "The duration string of each simulated disconnect.", "5s", "1m"),
+			docs.FieldCommon("output", "A child output.").HasType(docs.FieldTypeOutput),
+		},
+		Examples: []docs.AnnotatedExample{
+			{
+				Title:   "Testing a failover",
+				Summary: "In this example we check that messages are routed to a backup output when a flaky primary output rejects a tenth of all writes.",
+				Config: `
+output:
+  try:
+    - fault:
+        error_percentage: 10
+        output:
+          http_client:
+            url: http://example.com/foo/messages
+            verb: POST
+    - file:
+        path: ./backup.jsonl
+`,
+			},
+		},
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// FaultConditions is a config struct representing the different faults to
+// inject into writes of a child output.
+type FaultConditions struct {
+	ErrorPercentage      float64 `json:"error_percentage" yaml:"error_percentage"`
+	Latency              string  `json:"latency" yaml:"latency"`
+	DisconnectPercentage float64 `json:"disconnect_percentage" yaml:"disconnect_percentage"`
+	DisconnectDuration   string  `json:"disconnect_duration" yaml:"disconnect_duration"`
+}
+
+// FaultConfig contains configuration values for the Fault output type.
+type FaultConfig struct {
+	FaultConditions `json:",inline" yaml:",inline"`
+	Output          *Config `json:"output" yaml:"output"`
+}
+
+// NewFaultConfig creates a new FaultConfig with default values.
+func NewFaultConfig() FaultConfig {
+	return FaultConfig{
+		FaultConditions: FaultConditions{
+			ErrorPercentage:      0,
+			Latency:              "",
+			DisconnectPercentage: 0,
+			DisconnectDuration:   "5s",
+		},
+		Output: nil,
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type dummyFaultConfig struct {
+	FaultConditions `json:",inline" yaml:",inline"`
+	Output          interface{} `json:"output" yaml:"output"`
+}
+
+// MarshalJSON prints an empty object instead of nil.
+func (f FaultConfig) MarshalJSON() ([]byte, error) {
+	dummy := dummyFaultConfig{
+		Output:          f.Output,
+		FaultConditions: f.FaultConditions,
+	}
+	if f.Output == nil {
+		dummy.Output = struct{}{}
+	}
+	return json.Marshal(dummy)
+}
+
+// MarshalYAML prints an empty object instead of nil.
+func (f FaultConfig) MarshalYAML() (interface{}, error) {
+	dummy := dummyFaultConfig{
+		Output:          f.Output,
+		FaultConditions: f.FaultConditions,
+	}
+	if f.Output == nil {
+		dummy.Output = struct{}{}
+	}
+	return dummy, nil
+}
+
+//------------------------------------------------------------------------------
+
+// errFaultInjected is returned for message batches that are rejected by a fault
+// output.
+var errFaultInjected = errors.New("injected fault")
+
+// fault forwards messages to a child output, but injects errors, latency and
+// disconnects along the way.
+type fault struct {
+	stats metrics.Type
+	log   log.Modular
+
+	errorPct      float64
+	latency       time.Duration
+	disconnectPct float64
+	disconnectFor time.Duration
+	rand          *rand.Rand
+	clock         clock.Monotonic
+
+	disconnectedUntil int64 // A reading of clock
+	wrapped           Type
+
+	transactionsIn  <-chan types.Transaction
+	transactionsOut chan types.Transaction
+
+	ctx        context.Context
+	done       func()
+	closedChan chan struct{}
+}
+
+func newFault(conf FaultConditions, wrapped Type, log log.Modular, stats metrics.Type) (*fault, error) {
+	if conf.ErrorPercentage < 0 || conf.ErrorPercentage > 100 {
+		return nil, errors.New("error_percentage must be between 0 and 100")
+	}
+	if conf.DisconnectPercentage < 0 || conf.DisconnectPercentage > 100 {
+		return nil, errors.New("disconnect_percentage must be between 0 and 100")
+	}
+
+	var latency time.Duration
+	if len(conf.Latency) > 0 {
+		var err error
+		if latency, err = time.ParseDuration(conf.Latency); err != nil {
+			return nil, fmt.Errorf("failed to parse latency duration: %w", err)
+		}
+	}
+
+	var disconnectFor time.Duration
+	if len(conf.DisconnectDuration) > 0 {
+		var err error
+		if disconnectFor, err = time.ParseDuration(conf.DisconnectDuration); err != nil {
+			return nil, fmt.Errorf("failed to parse disconnect_duration: %w", err)
+		}
+	}
+
+	ctx, done := context.WithCancel(context.Background())
+	return &fault{
+		log:             log,
+		stats:           stats,
+		wrapped:         wrapped,
+		transactionsOut: make(chan types.Transaction),
+
+		errorPct:      conf.ErrorPercentage,
+		latency:       latency,
+		disconnectPct: conf.DisconnectPercentage,
+		disconnectFor: disconnectFor,
+		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		clock:         clock.System,
+
+		ctx:        ctx,
+		done:       done,
+		closedChan: make(chan struct{}),
+	}, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (f *fault) roll(pct float64) bool {
+	return pct > 0 && f.rand.Float64()*100 < pct
+}
+
+func (f *fault) sleep(d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	select {
+	case <-time.After(d):
+	case <-f.ctx.Done():
+		return false
+	}
+	return true
+}
+
+func (f *fault) loop() {
+	// Metrics paths
+	var (
+		mErrors      = f.stats.GetCounter("fault.error")
+		mDisconnects = f.stats.GetCounter("fault.disconnect")
+	)
+
+	wg := sync.WaitGroup{}
+
+	defer func() {
+		wg.Wait()
+		close(f.transactionsOut)
+		f.wrapped.CloseAsync()
+		_ = f.wrapped.WaitForClose(shutdown.MaximumShutdownWait())
+		close(f.closedChan)
+	}()
+
+	for {
+		var ts types.Transaction
+		var open bool
+		select {
+		case ts, open = <-f.transactionsIn:
+			if !open {
+				return
+			}
+		case <-f.ctx.Done():
+			return
+		}
+
+		// Faults are rolled in order of consumption, whereas each transaction
+		// is delayed and delivered in its own goroutine so that the
+		// parallelism of the wrapped output is preserved.
+		injectDisconnect := f.roll(f.disconnectPct)
+		injectErr := f.roll(f.errorPct)
+
+		wg.Add(1)
+		go func(ts types.Transaction) {
+			defer wg.Done()
+
+			if !f.sleep(f.latency) {
+				return
+			}
+
+			if injectDisconnect {
+				mDisconnects.Incr(1)
+				f.log.Warnf("Injecting disconnect for %v\n", f.disconnectFor)
+				atomic.StoreInt64(&f.disconnectedUntil, int64(f.clock.Elapsed()+f.disconnectFor))
+			}
+			if !f.sleep(time.Duration(atomic.LoadInt64(&f.disconnectedUntil)) - f.clock.Elapsed()) {
+				return
+			}
+
+			var res types.Response
+			if injectErr {
+				mErrors.Incr(1)
+				res = response.NewError(errFaultInjected)
+			} else {
+				resChan := make(chan types.Response)
+				select {
+				case f.transactionsOut <- types.NewTransaction(ts.Payload, resChan):
+				case <-f.ctx.Done():
+					return
+				}
+				select {
+				case res = <-resChan:
+				case <-f.ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case ts.ResponseChan <- res:
+			case <-f.ctx.Done():
+			}
+		}(ts)
+	}
+}
+
+// Consume assigns a messages channel for the output to read.
+func (f *fault) Consume(ts <-chan types.Transaction) error {
+	if f.transactionsIn != nil {
+		return types.ErrAlreadyStarted
+	}
+	if err := f.wrapped.Consume(f.transactionsOut); err != nil {
+		return err
+	}
+	f.transactionsIn = ts
+	go f.loop()
+	return nil
+}
+
+// Connected returns a boolean indicating whether this output is currently
+// connected to its target, which is false during a simulated disconnect.
+func (f *fault) Connected() bool {
+	if int64(f.clock.Elapsed()) < atomic.LoadInt64(&f.disconnectedUntil) {
+		return false
+	}
+	return f.wrapped.Connected()
+}
+
+func (f *fault) MaxInFlight() (int, bool) {
+	return output.GetMaxInFlight(f.wrapped)
+}
+
+// CloseAsync shuts down the fault output and stops processing requests.
+func (f *fault) CloseAsync() {
+	f.done()
+}
+
+// WaitForClose blocks until the fault output has closed down.
+func (f *fault) WaitForClose(timeout time.Duration) error {
+	select {
+	case <-f.closedChan:
+	case <-time.After(timeout):
+		return types.ErrTimeout
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
@@ -0,0 +1,158 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaultErrors(t *testing.T) {
+	child := &mockOutput{}
+
+	conf := NewFaultConfig()
+	conf.ErrorPercentage = 100
+
+	f, err := newFault(conf.FaultConditions, child, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	tChan := make(chan types.Transaction)
+	rChan := make(chan types.Response)
+	require.NoError(t, f.Consume(tChan))
+
+	for i := 0; i < 5; i++ {
+		select {
+		case tChan <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), rChan):
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+		select {
+		case res := <-rChan:
+			assert.Equal(t, errFaultInjected, res.Error())
+		case <-child.ts:
+			t.Fatal("unexpected child write")
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+
+	f.CloseAsync()
+	require.NoError(t, f.WaitForClose(time.Second))
+}
+
+func TestFaultDisconnect(t *testing.T) {
+	child := &mockOutput{}
+
+	conf := NewFaultConfig()
+	conf.DisconnectPercentage = 100
+	conf.DisconnectDuration = "100ms"
+	conf.Latency = "10ms"
+
+	f, err := newFault(conf.FaultConditions, child, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	tChan := make(chan types.Transaction)
+	rChan := make(chan types.Response)
+	require.NoError(t, f.Consume(tChan))
+	assert.True(t, f.Connected())
+
+	start := time.Now()
+	select {
+	case tChan <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), rChan):
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	assert.Eventually(t, func() bool {
+		return !f.Connected()
+	}, time.Second, time.Millisecond)
+
+	var ts types.Transaction
+	select {
+	case ts = <-child.ts:
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(time.Millisecond*110))
+	assert.Equal(t, "foo", string(ts.Payload.Get(0).Get()))
+
+	go func() {
+		ts.ResponseChan <- response.NewAck()
+	}()
+	select {
+	case res := <-rChan:
+		assert.NoError(t, res.Error())
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	f.CloseAsync()
+	require.NoError(t, f.WaitForClose(time.Second))
+}
+
+func TestFaultParallelWrites(t *testing.T) {
+	child := &mockOutput{}
+
+	conf := NewFaultConfig()
+	conf.Latency = "10ms"
+
+	f, err := newFault(conf.FaultConditions, child, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	tChan := make(chan types.Transaction)
+	rChan := make(chan types.Response)
+	require.NoError(t, f.Consume(tChan))
+
+	// Both transactions reach the child before either is acknowledged.
+	var childTs []types.Transaction
+	for _, content := range []string{"foo", "bar"} {
+		select {
+		case tChan <- types.NewTransaction(message.New([][]byte{[]byte(content)}), rChan):
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case ts := <-child.ts:
+			childTs = append(childTs, ts)
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+
+	for _, ts := range childTs {
+		go func(ts types.Transaction) {
+			ts.ResponseChan <- response.NewAck()
+		}(ts)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case res := <-rChan:
+			assert.NoError(t, res.Error())
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+
+	f.CloseAsync()
+	require.NoError(t, f.WaitForClose(time.Second))
+}
+
+func TestFaultBadConfig(t *testing.T) {
+	conf := NewFaultConfig()
+	conf.ErrorPercentage = 150
+	_, err := newFault(conf.FaultConditions, &mockOutput{}, log.Noop(), metrics.Noop())
+	require.Error(t, err)
+
+	conf = NewFaultConfig()
+	conf.Latency = "nope"
+	_, err = newFault(conf.FaultConditions, &mockOutput{}, log.Noop(), metrics.Noop())
+	require.Error(t, err)
+}
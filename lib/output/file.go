@@ -27,7 +27,13 @@ func init() {
 		Summary: `
 Writes messages to files on disk based on a chosen codec.`,
 		Description: `
-Messages can be written to different files by using [interpolation functions](/docs/configuration/interpolation#bloblang-queries) in the path field. However, only one file is ever open at a given time, and therefore when the path changes the previously open file is closed.`,
+Messages can be written to different files by using [interpolation functions](/docs/configuration/interpolation#bloblang-queries) in the path field. However, only one file is ever open at a given time, and therefore when the path changes the previously open file is closed.
+
+### Rotation
+
+Files can be rotated once they reach a size with ` + "`rotation.max_size`" + `, or once they reach an age with ` + "`rotation.max_age`" + `. A rotated file is renamed with a timestamp inserted before its extension, so that ` + "`/tmp/data.txt`" + ` becomes ` + "`/tmp/data-2021-01-02T15-04-05.000.txt`" + `, and a new file is created at the original path for the messages that follow. Rotated files can optionally be compressed with gzip, and old ones deleted with ` + "`rotation.max_files`" + `.
+
+Rotation requires a codec that keeps files open between writes, such as ` + "`lines`" + ` or ` + "`delim`" + `.`,
 		FieldSpecs: docs.FieldSpecs{
 			docs.FieldCommon(
 				"path", "The file to write to, if the file does not yet exist it will be created.",
@@ -37,6 +43,7 @@ Messages can be written to different files by using [interpolation functions](/d
 			).IsInterpolated().AtVersion("3.33.0"),
 			codec.WriterDocs.AtVersion("3.33.0"),
 			docs.FieldDeprecated("delimiter"),
+			fileRotationSpec(),
 		},
 		Categories: []Category{
 			CategoryLocal,
@@ -48,17 +55,19 @@ Messages can be written to different files by using [interpolation functions](/d
 
 // FileConfig contains configuration fields for the file based output type.
 type FileConfig struct {
-	Path  string `json:"path" yaml:"path"`
-	Codec string `json:"codec" yaml:"codec"`
-	Delim string `json:"delimiter" yaml:"delimiter"`
+	Path     string             `json:"path" yaml:"path"`
+	Codec    string             `json:"codec" yaml:"codec"`
+	Delim    string             `json:"delimiter" yaml:"delimiter"`
+	Rotation FileRotationConfig `json:"rotation" yaml:"rotation"`
 }
 
 // NewFileConfig creates a new FileConfig with default values.
 func NewFileConfig() FileConfig {
 	return FileConfig{
-		Path:  "",
-		Codec: "lines",
-		Delim: "",
+		Path:     "",
+		Codec:    "lines",
+		Delim:    "",
+		Rotation: NewFileRotationConfig(),
 	}
 }
 
@@ -73,6 +82,12 @@ func NewFile(conf Config, mgr types.Manager, log log.Modular, stats metrics.Type
 	if err != nil {
 		return nil, err
 	}
+	if f.rotator, err = newFileRotator(conf.File.Rotation, log); err != nil {
+		return nil, err
+	}
+	if f.rotator != nil && f.codecConf.CloseAfter {
+		return nil, fmt.Errorf("rotation is not supported with the codec %v", conf.File.Codec)
+	}
 	w, err := NewAsyncWriter(TypeFile, 1, f, log, stats)
 	if err != nil {
 		return nil, err
@@ -93,9 +108,13 @@ type fileWriter struct {
 	codec     codec.WriterConstructor
 	codecConf codec.WriterConfig
 
-	handleMut  sync.Mutex
-	handlePath string
-	handle     codec.Writer
+	rotator *fileRotator
+
+	handleMut    sync.Mutex
+	handlePath   string
+	handle       codec.Writer
+	handleFile   *countingWriteCloser
+	handleOpened time.Duration
 
 	shutSig *shutdown.Signaller
 }
@@ -133,10 +152,21 @@ func (w *fileWriter) WriteWithContext(ctx context.Context, msg types.Message) er
 		defer w.handleMut.Unlock()
 
 		if w.handle != nil && path == w.handlePath {
-			return w.handle.Write(ctx, p)
-		}
-		if w.handle != nil {
-			if err := w.handle.Close(ctx); err != nil {
+			if w.rotator == nil || !w.rotator.due(w.handleFile.size, w.handleOpened) {
+				return w.handle.Write(ctx, p)
+			}
+			err := w.handle.Close(ctx)
+			w.handle = nil
+			if err != nil {
+				return err
+			}
+			if err = w.rotator.rotate(path); err != nil {
+				return err
+			}
+		} else if w.handle != nil {
+			err := w.handle.Close(ctx)
+			w.handle = nil
+			if err != nil {
 				return err
 			}
 		}
@@ -153,13 +183,28 @@ func (w *fileWriter) WriteWithContext(ctx context.Context, msg types.Message) er
 			return err
 		}
 
+		// A file left over from a previous run might already be due a
+		// rotation by size.
+		if w.rotator != nil && w.rotator.maxSize > 0 {
+			if info, err := os.Stat(path); err == nil && info.Size() >= w.rotator.maxSize {
+				if err = w.rotator.rotate(path); err != nil {
+					return err
+				}
+			}
+		}
+
 		file, err := os.OpenFile(path, flag, os.FileMode(0666))
 		if err != nil {
 			return err
 		}
 
+		counter := &countingWriteCloser{WriteCloser: file}
+		if info, err := file.Stat(); err == nil && w.codecConf.Append {
+			counter.size = info.Size()
+		}
+
 		w.handlePath = path
-		handle, err := w.codec(file)
+		handle, err := w.codec(counter)
 		if err != nil {
 			return err
 		}
@@ -171,6 +216,10 @@ func (w *fileWriter) WriteWithContext(ctx context.Context, msg types.Message) er
 
 		if !w.codecConf.CloseAfter {
 			w.handle = handle
+			w.handleFile = counter
+			if w.rotator != nil {
+				w.handleOpened = w.rotator.clock.Elapsed()
+			}
 		} else {
 			handle.Close(ctx)
 		}
@@ -199,6 +248,9 @@ func (w *fileWriter) CloseAsync() {
 			w.handle = nil
 		}
 		w.handleMut.Unlock()
+		if w.rotator != nil {
+			w.rotator.wait()
+		}
 		w.shutSig.ShutdownComplete()
 	}()
 }
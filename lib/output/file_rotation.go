@@ -0,0 +1,251 @@
+package output
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/docs"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/util/clock"
+)
+
+//------------------------------------------------------------------------------
+
+// FileRotationConfig contains configuration fields for rotating the files
+// written by the file output.
+type FileRotationConfig struct {
+	MaxSize  int64  `json:"max_size" yaml:"max_size"`
+	MaxAge   string `json:"max_age" yaml:"max_age"`
+	MaxFiles int    `json:"max_files" yaml:"max_files"`
+	Compress bool   `json:"compress" yaml:"compress"`
+}
+
+// NewFileRotationConfig creates a new FileRotationConfig with default values.
+func NewFileRotationConfig() FileRotationConfig {
+	return FileRotationConfig{
+		MaxSize:  0,
+		MaxAge:   "",
+		MaxFiles: 0,
+		Compress: false,
+	}
+}
+
+func fileRotationSpec() docs.FieldSpec {
+	return docs.FieldAdvanced("rotation", "Rotates files once they reach a size or an age, at which point the file is renamed with a timestamp inserted before its extension and a new file is created in its place. Rotation is disabled unless either `max_size` or `max_age` is set.").WithChildren(
+		docs.FieldInt("max_size", "The size in bytes that a file reaches before it is rotated, zero disables size based rotation.", 100*1024*1024).HasDefault(0),
+		docs.FieldString("max_age", "The period after which a file is rotated, measured from when it was opened. The age of a file is checked before each write, and therefore a file is not rotated whilst no messages are written. Empty disables age based rotation.", "1h", "24h").HasDefault(""),
+		docs.FieldInt("max_files", "The maximum number of rotated files to keep for each path, the oldest of which are deleted. Zero keeps all rotated files.").HasDefault(0),
+		docs.FieldBool("compress", "Whether to compress rotated files with gzip, in which case they are given the extension `.gz`.").HasDefault(false),
+	).AtVersion("3.54.0")
+}
+
+//------------------------------------------------------------------------------
+
+// rotatedTimeFormat is inserted into the names of rotated files, it sorts
+// lexically in chronological order and contains no characters that are
+// reserved in file names.
+const rotatedTimeFormat = "2006-01-02T15-04-05.000"
+
+// fileRotator decides when the files of a file output are rotated and performs
+// the rotation, where the compression of rotated files and the deletion of old
+// ones happens in the background.
+type fileRotator struct {
+	maxSize  int64
+	maxAge   time.Duration
+	maxFiles int
+	compress bool
+
+	log   log.Modular
+	clock clock.Monotonic
+	now   func() time.Time
+
+	bgMut sync.Mutex
+	bgWG  sync.WaitGroup
+}
+
+// newFileRotator returns a rotator from a config, or nil if rotation is
+// disabled.
+func newFileRotator(conf FileRotationConfig, log log.Modular) (*fileRotator, error) {
+	if conf.MaxSize < 0 {
+		return nil, errors.New("rotation max_size must not be negative")
+	}
+	if conf.MaxFiles < 0 {
+		return nil, errors.New("rotation max_files must not be negative")
+	}
+	var maxAge time.Duration
+	if conf.MaxAge != "" {
+		var err error
+		if maxAge, err = time.ParseDuration(conf.MaxAge); err != nil {
+			return nil, fmt.Errorf("failed to parse rotation max_age: %w", err)
+		}
+		if maxAge <= 0 {
+			return nil, errors.New("rotation max_age must be positive")
+		}
+	}
+	if conf.MaxSize == 0 && maxAge == 0 {
+		return nil, nil
+	}
+	return &fileRotator{
+		maxSize:  conf.MaxSize,
+		maxAge:   maxAge,
+		maxFiles: conf.MaxFiles,
+		compress: conf.Compress,
+		log:      log,
+		clock:    clock.System,
+		now:      time.Now,
+	}, nil
+}
+
+// due returns true if a file of a given size, which was opened at a given
+// reading of the clock, should be rotated before writing to it.
+func (r *fileRotator) due(size int64, openedAt time.Duration) bool {
+	if r.maxSize > 0 && size >= r.maxSize {
+		return true
+	}
+	return r.maxAge > 0 && r.clock.Elapsed()-openedAt >= r.maxAge
+}
+
+// rotatedPath returns the path of a file when rotated at a given time, where
+// the timestamp is inserted before the extension.
+func rotatedPath(path string, t time.Time) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + t.UTC().Format(rotatedTimeFormat) + ext
+}
+
+// isRotatedPath returns true if a file name is that of a rotated file of a
+// path.
+func isRotatedPath(path, name string) bool {
+	ext := filepath.Ext(path)
+	prefix := strings.TrimSuffix(filepath.Base(path), ext) + "-"
+	name = strings.TrimSuffix(name, ".gz")
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+		return false
+	}
+	_, err := time.Parse(rotatedTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext))
+	return err == nil
+}
+
+// rotate renames the file at a path, which must be closed, and schedules the
+// compression of the rotated file and the deletion of old ones.
+func (r *fileRotator) rotate(path string) error {
+	t := r.now()
+	target := rotatedPath(path, t)
+	for fileExists(target) || fileExists(target+".gz") {
+		t = t.Add(time.Millisecond)
+		target = rotatedPath(path, t)
+	}
+	if err := os.Rename(path, target); err != nil {
+		return fmt.Errorf("failed to rotate file: %w", err)
+	}
+
+	r.bgWG.Add(1)
+	go func() {
+		defer r.bgWG.Done()
+
+		r.bgMut.Lock()
+		defer r.bgMut.Unlock()
+
+		if r.compress {
+			if err := compressFile(target); err != nil {
+				r.log.Errorf("Failed to compress rotated file '%v': %v\n", target, err)
+			}
+		}
+		if err := r.prune(path); err != nil {
+			r.log.Errorf("Failed to delete old rotated files of '%v': %v\n", path, err)
+		}
+	}()
+	return nil
+}
+
+// prune deletes the oldest rotated files of a path beyond the maximum.
+func (r *fileRotator) prune(path string) error {
+	if r.maxFiles <= 0 {
+		return nil
+	}
+	infos, err := ioutil.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	var rotated []string
+	for _, info := range infos {
+		if !info.IsDir() && isRotatedPath(path, info.Name()) {
+			rotated = append(rotated, info.Name())
+		}
+	}
+	if len(rotated) <= r.maxFiles {
+		return nil
+	}
+	sort.Slice(rotated, func(i, j int) bool {
+		return strings.TrimSuffix(rotated[i], ".gz") < strings.TrimSuffix(rotated[j], ".gz")
+	})
+	for _, name := range rotated[:len(rotated)-r.maxFiles] {
+		if err := os.Remove(filepath.Join(filepath.Dir(path), name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// wait blocks until all background work has finished.
+func (r *fileRotator) wait() {
+	r.bgWG.Wait()
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// compressFile replaces a file with a gzip compressed copy of it.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmpPath := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(0666))
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err == nil {
+		err = zw.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path+".gz")
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return os.Remove(path)
+}
+
+//------------------------------------------------------------------------------
+
+// countingWriteCloser counts the bytes written to a file.
+type countingWriteCloser struct {
+	io.WriteCloser
+	size int64
+}
+
+func (c *countingWriteCloser) Write(p []byte) (int, error) {
+	n, err := c.WriteCloser.Write(p)
+	c.size += int64(n)
+	return n, err
+}
@@ -0,0 +1,185 @@
+package output
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/util/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRotatingFileWriter(t *testing.T, path string, conf FileRotationConfig, ts *time.Time) (*fileWriter, *clock.Manual) {
+	t.Helper()
+
+	w, err := newFileWriter(path, "lines", log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	w.rotator, err = newFileRotator(conf, log.Noop())
+	require.NoError(t, err)
+	require.NotNil(t, w.rotator)
+
+	clk := clock.NewManual()
+	w.rotator.clock = clk
+
+	w.rotator.now = func() time.Time {
+		*ts = ts.Add(time.Second)
+		return *ts
+	}
+	return w, clk
+}
+
+func writeFileLines(t *testing.T, w *fileWriter, lines ...string) {
+	t.Helper()
+	for _, l := range lines {
+		require.NoError(t, w.WriteWithContext(context.Background(), message.New([][]byte{[]byte(l)})))
+	}
+}
+
+func testRotationStart() *time.Time {
+	ts := time.Date(2021, 1, 2, 15, 4, 5, 0, time.UTC)
+	return &ts
+}
+
+func readDirFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	infos, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	for _, info := range infos {
+		path := filepath.Join(dir, info.Name())
+		var b []byte
+		if filepath.Ext(path) == ".gz" {
+			f, err := os.Open(path)
+			require.NoError(t, err)
+			zr, err := gzip.NewReader(f)
+			require.NoError(t, err)
+			b, err = ioutil.ReadAll(zr)
+			require.NoError(t, err)
+			f.Close()
+		} else {
+			b, err = ioutil.ReadFile(path)
+			require.NoError(t, err)
+		}
+		files[info.Name()] = string(b)
+	}
+	return files
+}
+
+func TestFileRotationSize(t *testing.T) {
+	dir := t.TempDir()
+
+	ts := testRotationStart()
+
+	conf := NewFileRotationConfig()
+	conf.MaxSize = 8
+	w, _ := newTestRotatingFileWriter(t, filepath.Join(dir, "data.txt"), conf, ts)
+
+	writeFileLines(t, w, "foo", "bar", "baz", "qux", "quz", "buz")
+	w.CloseAsync()
+	require.NoError(t, w.WaitForClose(time.Second))
+
+	assert.Equal(t, map[string]string{
+		"data-2021-01-02T15-04-06.000.txt": "foo\nbar\n",
+		"data-2021-01-02T15-04-07.000.txt": "baz\nqux\n",
+		"data.txt":                         "quz\nbuz\n",
+	}, readDirFiles(t, dir))
+
+	// A file that is already due a rotation when opened is rotated first.
+	w, _ = newTestRotatingFileWriter(t, filepath.Join(dir, "data.txt"), conf, ts)
+	writeFileLines(t, w, "bev")
+	w.CloseAsync()
+	require.NoError(t, w.WaitForClose(time.Second))
+
+	files := readDirFiles(t, dir)
+	assert.Len(t, files, 4)
+	assert.Equal(t, "quz\nbuz\n", files["data-2021-01-02T15-04-08.000.txt"])
+	assert.Equal(t, "bev\n", files["data.txt"])
+}
+
+func TestFileRotationAge(t *testing.T) {
+	dir := t.TempDir()
+
+	conf := NewFileRotationConfig()
+	conf.MaxAge = "1h"
+	w, clk := newTestRotatingFileWriter(t, filepath.Join(dir, "data"), conf, testRotationStart())
+
+	writeFileLines(t, w, "foo", "bar")
+	clk.Advance(time.Minute * 59)
+	writeFileLines(t, w, "baz")
+	clk.Advance(time.Minute)
+	writeFileLines(t, w, "qux")
+
+	w.CloseAsync()
+	require.NoError(t, w.WaitForClose(time.Second))
+
+	assert.Equal(t, map[string]string{
+		"data-2021-01-02T15-04-06.000": "foo\nbar\nbaz\n",
+		"data":                         "qux\n",
+	}, readDirFiles(t, dir))
+}
+
+func TestFileRotationCompressAndPrune(t *testing.T) {
+	dir := t.TempDir()
+
+	conf := NewFileRotationConfig()
+	conf.MaxSize = 1
+	conf.MaxFiles = 2
+	conf.Compress = true
+	w, _ := newTestRotatingFileWriter(t, filepath.Join(dir, "data.log"), conf, testRotationStart())
+
+	// Unrelated files are left alone.
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "data-nope.log"), []byte("nope"), 0644))
+
+	writeFileLines(t, w, "foo", "bar", "baz", "qux")
+	w.CloseAsync()
+	require.NoError(t, w.WaitForClose(time.Second))
+
+	files := readDirFiles(t, dir)
+	names := make([]string, 0, len(files))
+	for k := range files {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	assert.Equal(t, []string{
+		"data-2021-01-02T15-04-07.000.log.gz",
+		"data-2021-01-02T15-04-08.000.log.gz",
+		"data-nope.log",
+		"data.log",
+	}, names)
+	assert.Equal(t, "bar\n", files["data-2021-01-02T15-04-07.000.log.gz"])
+	assert.Equal(t, "baz\n", files["data-2021-01-02T15-04-08.000.log.gz"])
+	assert.Equal(t, "qux\n", files["data.log"])
+}
+
+func TestFileRotationBadConfig(t *testing.T) {
+	for name, fn := range map[string]func(c *FileConfig){
+		"negative size":  func(c *FileConfig) { c.Rotation.MaxSize = -1 },
+		"bad age":        func(c *FileConfig) { c.Rotation.MaxAge = "nope" },
+		"negative files": func(c *FileConfig) { c.Rotation.MaxFiles = -1 },
+		"close after codec": func(c *FileConfig) {
+			c.Codec = "all-bytes"
+			c.Rotation.MaxSize = 10
+		},
+	} {
+		conf := NewConfig()
+		conf.Type = TypeFile
+		conf.File.Path = filepath.Join(t.TempDir(), "data.txt")
+		fn(&conf.File)
+
+		_, err := NewFile(conf, nil, log.Noop(), metrics.Noop())
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,43 @@
+package output
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileCompressedSingleMessage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.gz")
+
+	w, err := newFileWriter(path, "gzip/lines", log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+	defer func() {
+		w.CloseAsync()
+		require.NoError(t, w.WaitForClose(time.Second))
+	}()
+
+	require.NoError(t, w.WriteWithContext(context.Background(), message.New([][]byte{[]byte("foo")})))
+
+	// The message must be readable from the file once the write is complete,
+	// without waiting for the writer to be closed.
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+
+	line, err := bufio.NewReader(zr).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "foo\n", line)
+}
@@ -26,6 +26,7 @@ For information on how to set up credentials check out
 			docs.FieldCommon("topic", "The topic to publish to.").IsInterpolated(),
 			docs.FieldCommon("max_in_flight", "The maximum number of messages to have in flight at a given time. Increase this to improve throughput."),
 			docs.FieldAdvanced("publish_timeout", "The maximum length of time to wait before abandoning a publish attempt for a message.", "10s", "5m", "60m"),
+			docs.FieldAdvanced("ordering_key", "An optional key that enables ordered publishing, where messages with the same key are delivered to subscribers in the order they were published. Subscriptions must have message ordering enabled to receive them in order, and in order to preserve ordering when messages fail to publish `max_in_flight` should be set to `1`.", `${! meta("user_id") }`).IsInterpolated().HasDefault("").AtVersion("3.54.0"),
 			docs.FieldCommon("metadata", "Specify criteria for which metadata values are sent as attributes.").WithChildren(output.MetadataFields()...),
 		},
 		Categories: []Category{
@@ -28,6 +28,8 @@ message has multiple parts (is a batch) the request will be sent according to
 [RFC1341](https://www.w3.org/Protocols/rfc1341/7_2_Multipart.html). This
 behaviour can be disabled by setting the field ` + "[`batch_as_multipart`](#batch_as_multipart) to `false`" + `.
 
+When sending messages to the ` + "[`http_server` input](/docs/components/inputs/http_server)" + ` of another Benthos instance the field ` + "[`wire_format`](#wire_format)" + ` can be enabled, in which case messages are sent in a versioned binary format that preserves the parts and metadata of each message. If the server rejects the version of the format with a 415 status code then the client negotiates a version from those advertised by the server.
+
 ### Propagating Responses
 
 It's possible to propagate the response from each HTTP request back to the input
@@ -39,6 +41,8 @@ these propagated responses.`,
 		FieldSpecs: client.FieldSpecs().Add(
 			docs.FieldAdvanced("batch_as_multipart", "Send message batches as a single request using [RFC1341](https://www.w3.org/Protocols/rfc1341/7_2_Multipart.html). If disabled messages in batches will be sent as individual requests."),
 			docs.FieldAdvanced("propagate_response", "Whether responses from the server should be [propagated back](/docs/guides/sync_responses) to the input."),
+			docs.FieldAdvanced("compression", "An optional compression algorithm to apply to request bodies, the `Content-Encoding` header of requests is set to the name of the algorithm. When messages are batched the entire request body is compressed.").HasOptions("none", "gzip", "deflate", "zstd").AtVersion("3.54.0"),
+			docs.FieldAdvanced("wire_format", "Send messages in a versioned binary format that preserves the parts and metadata of each message, for consumption by the `http_server` input of another Benthos instance.").AtVersion("3.54.0"),
 			docs.FieldCommon("max_in_flight", "The maximum number of messages to have in flight at a given time. Increase this to improve throughput."),
 		).Add(batch.FieldSpec()),
 		Categories: []Category{
@@ -10,7 +10,9 @@ import (
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/output/writer"
 	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/discovery"
 	"github.com/Jeffail/benthos/v3/lib/util/kafka/sasl"
+	"github.com/Jeffail/benthos/v3/lib/util/proxy"
 	"github.com/Jeffail/benthos/v3/lib/util/retries"
 	"github.com/Jeffail/benthos/v3/lib/util/tls"
 )
@@ -25,30 +27,44 @@ The kafka output type writes a batch of messages to Kafka brokers and waits for
 		Description: `
 The config field ` + "`ack_replicas`" + ` determines whether we wait for acknowledgement from all replicas or just a single broker.
 
-Both the ` + "`key` and `topic`" + ` fields can be dynamically set using function interpolations described [here](/docs/configuration/interpolation#bloblang-queries).
+The fields ` + "`key`, `topic` and `partition`" + ` can be dynamically set using function interpolations described [here](/docs/configuration/interpolation#bloblang-queries).
 
 [Metadata](/docs/configuration/metadata) will be added to each message sent as headers, but can be restricted using the field ` + "[`metadata`](#metadata)" + `.
 
+### Service Discovery
+
+` + discovery.Description + `
+
+The addresses are resolved again every ` + "`discovery_interval`" + `, and when they change the output reconnects to the new brokers once the messages being sent have been flushed.
+
 ### Strict Ordering and Retries
 
 When strict ordering is required for messages written to topic partitions it is important to ensure that both the field ` + "`max_in_flight` is set to `1` and that the field `retry_as_batch` is set to `true`" + `.
 
 You must also ensure that failed batches are never rerouted back to the same output. This can be done by setting the field ` + "`max_retries` to `0` and `backoff.max_elapsed_time`" + ` to empty, which will apply back pressure indefinitely until the batch is sent successfully.
 
-However, this also means that manual intervention will eventually be required in cases where the batch cannot be sent due to configuration problems such as an incorrect ` + "`max_msg_bytes`" + ` estimate. A less strict but automated alternative would be to route failed batches to a dead letter queue using a ` + "[`try` broker](/docs/components/outputs/try)" + `, but this would allow subsequent batches to be delivered in the meantime whilst those failed batches are dealt with.`,
+However, this also means that manual intervention will eventually be required in cases where the batch cannot be sent due to configuration problems such as an incorrect ` + "`max_msg_bytes`" + ` estimate. A less strict but automated alternative would be to route failed batches to a dead letter queue using a ` + "[`try` broker](/docs/components/outputs/try)" + `, but this would allow subsequent batches to be delivered in the meantime whilst those failed batches are dealt with.
+
+### Azure Event Hubs
+
+This output is able to publish to Azure Event Hubs through its Kafka compatible endpoint, find out more [in this guide](/docs/guides/azure#event-hubs).`,
 		Async:   true,
 		Batches: true,
 		FieldSpecs: append(docs.FieldSpecs{
 			docs.FieldDeprecated("round_robin_partitions"),
-			docs.FieldCommon("addresses", "A list of broker addresses to connect to. If an item of the list contains commas it will be expanded into multiple addresses.", []string{"localhost:9092"}, []string{"localhost:9041,localhost:9042"}, []string{"localhost:9041", "localhost:9042"}).Array(),
+			docs.FieldCommon("addresses", "A list of broker addresses to connect to. If an item of the list contains commas it will be expanded into multiple addresses.", []string{"localhost:9092"}, []string{"localhost:9041,localhost:9042"}, []string{"localhost:9041", "localhost:9042"}, []string{"dnssrv://_kafka._tcp.example.com"}).Array(),
+			docs.FieldAdvanced("discovery_interval", "When any of the `addresses` are resolved using service discovery, the period of time between each resolution.").AtVersion("3.54.0"),
+			proxy.FieldSpec(),
 			tls.FieldSpec(),
 			sasl.FieldSpec(),
 			docs.FieldCommon("topic", "The topic to publish messages to.").IsInterpolated(),
 			docs.FieldCommon("client_id", "An identifier for the client connection."),
 			docs.FieldCommon("key", "The key to publish messages with.").IsInterpolated(),
-			docs.FieldCommon("partitioner", "The partitioning algorithm to use.").HasOptions("fnv1a_hash", "murmur2_hash", "random", "round_robin"),
-			docs.FieldCommon("compression", "The compression algorithm to use.").HasOptions("none", "snappy", "lz4", "gzip"),
+			docs.FieldCommon("partitioner", "The partitioning algorithm to use.").HasOptions("fnv1a_hash", "murmur2_hash", "random", "round_robin", "manual"),
+			docs.FieldAdvanced("partition", "An optional explicit partition to set for each message. This field is only relevant when the `partitioner` is set to `manual`. The provided interpolation string must be a valid integer.", `${! meta("partition") }`).IsInterpolated().AtVersion("3.54.0"),
+			docs.FieldCommon("compression", "The compression algorithm to use. Compression is applied by the client to each batch of records sent to a partition, the `zstd` algorithm requires a `target_version` of at least `2.1.0`.").HasOptions("none", "snappy", "lz4", "gzip", "zstd"),
 			docs.FieldString("static_headers", "An optional map of static headers that should be added to messages in addition to metadata.", map[string]string{"first-static-header": "value-1", "second-static-header": "value-2"}).Map(),
+			docs.FieldString("headers", "An optional map of headers that should be added to messages in addition to metadata and `static_headers`, where the values are interpolated for each message. This can be used to add routing hints for downstream consumers without the use of a processor.", map[string]string{"source": "benthos", "tenant": `${! meta("tenant") }`}).IsInterpolated().Map().AtVersion("3.54.0"),
 			docs.FieldCommon("metadata", "Specify criteria for which metadata values are sent with messages as headers.").WithChildren(output.MetadataFields()...),
 			output.InjectTracingSpanMappingDocs,
 			docs.FieldCommon("max_in_flight", "The maximum number of parallel message batches to have in flight at any given time."),
@@ -71,6 +71,7 @@ func (w *LineWriter) loop() {
 		mSent      = w.stats.GetCounter("batch.sent")
 		mBytesSent = w.stats.GetCounter("batch.bytes")
 		mLatency   = w.stats.GetTimer("batch.latency")
+		mDelivery  = w.stats.GetTimer("batch.delivery_latency")
 	)
 
 	defer func() {
@@ -115,6 +116,7 @@ func (w *LineWriter) loop() {
 			mBytesSent.Incr(int64(message.GetAllBytesLen(ts.Payload)))
 			w.log.Tracef("Successfully wrote %v messages to '%v'.\n", ts.Payload.Len(), w.typeStr)
 			mLatency.Timing(latency)
+			mDelivery.Timing(time.Since(message.ShiftedAt(ts.Payload)).Nanoseconds())
 		}
 
 		for _, s := range spans {
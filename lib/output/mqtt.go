@@ -23,9 +23,10 @@ messages these interpolations are performed per message part.`,
 		Async: true,
 		FieldSpecs: docs.FieldSpecs{
 			docs.FieldCommon("urls", "A list of URLs to connect to. If an item of the list contains commas it will be expanded into multiple URLs.", []string{"tcp://localhost:1883"}).Array(),
-			docs.FieldCommon("topic", "The topic to publish messages to."),
+			docs.FieldCommon("topic", "The topic to publish messages to.").IsInterpolated(),
 			docs.FieldCommon("client_id", "An identifier for the client."),
 			docs.FieldCommon("qos", "The QoS value to set for each message.").HasOptions("0", "1", "2"),
+			docs.FieldAdvanced("retained", "Whether messages are published as retained, in which case the broker stores the last message of each topic and delivers it to new subscribers of the topic.").HasDefault(false).AtVersion("3.54.0"),
 			docs.FieldAdvanced("user", "A username to connect with."),
 			docs.FieldAdvanced("password", "A password to connect with."),
 			tls.FieldSpec().AtVersion("3.45.0"),
@@ -24,6 +24,7 @@ Currently only PUSH and PUB sockets are supported.`,
 			docs.FieldCommon("socket_type", "The socket type to send with.").HasOptions("PUSH", "PUB"),
 			docs.FieldCommon("poll_timeout", "The maximum period of time to wait for a message to send before the request is abandoned and reattempted."),
 			docs.FieldCommon("max_in_flight", "The maximum number of messages to have in flight at a given time. Increase this to improve throughput."),
+			fireAndForgetFieldSpec(),
 		},
 		Categories: []Category{
 			CategoryNetwork,
@@ -43,6 +44,9 @@ func NewNanomsg(conf Config, mgr types.Manager, log log.Modular, stats metrics.T
 	if err != nil {
 		return nil, err
 	}
+	if aw, ok := a.(*AsyncWriter); ok && conf.Nanomsg.FireAndForget {
+		aw.SetFireAndForget()
+	}
 	return OnlySinglePayloads(a), nil
 }
 
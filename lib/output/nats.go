@@ -6,6 +6,7 @@ import (
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/output/writer"
 	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/proxy"
 	"github.com/Jeffail/benthos/v3/lib/util/tls"
 )
 
@@ -18,7 +19,20 @@ func init() {
 Publish to an NATS subject.`,
 		Description: `
 This output will interpolate functions within the subject field, you
-can find a list of functions [here](/docs/configuration/interpolation#bloblang-queries).`,
+can find a list of functions [here](/docs/configuration/interpolation#bloblang-queries).
+
+### Replying to Requests
+
+This output can publish the results of requests consumed by the ` + "`nats`" + ` input
+back to the reply subject of each request, which allows Benthos to take part in
+request/reply topologies:
+
+` + "```yaml" + `
+output:
+  nats:
+    urls: [ nats://127.0.0.1:4222 ]
+    subject: ${! meta("nats_reply_subject") }
+` + "```" + ``,
 		Async: true,
 		FieldSpecs: docs.FieldSpecs{
 			docs.FieldCommon(
@@ -29,6 +43,7 @@ can find a list of functions [here](/docs/configuration/interpolation#bloblang-q
 			).Array(),
 			docs.FieldCommon("subject", "The subject to publish to.").IsInterpolated(),
 			docs.FieldCommon("max_in_flight", "The maximum number of messages to have in flight at a given time. Increase this to improve throughput."),
+			proxy.FieldSpec(),
 			tls.FieldSpec(),
 		},
 		Categories: []Category{
@@ -0,0 +1,148 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/processor"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+)
+
+//------------------------------------------------------------------------------
+
+// PartsConfig contains configuration fields that determine how the parts of a
+// multiple part message are mapped to writes of an output.
+type PartsConfig struct {
+	Mode      string `json:"mode" yaml:"mode"`
+	Delimiter string `json:"delimiter" yaml:"delimiter"`
+	Index     int    `json:"index" yaml:"index"`
+}
+
+// NewPartsConfig returns a PartsConfig with default values.
+func NewPartsConfig() PartsConfig {
+	return PartsConfig{
+		Mode:      "",
+		Delimiter: "\n",
+		Index:     0,
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// parts is a processor that maps the parts of each message into the payloads
+// written by an output.
+type parts struct {
+	conf PartsConfig
+	log  log.Modular
+
+	mDropped metrics.StatCounter
+	mErr     metrics.StatCounter
+}
+
+func newParts(conf PartsConfig, log log.Modular, stats metrics.Type) (types.Processor, error) {
+	switch conf.Mode {
+	case "concatenate", "index", "each", "json_array":
+	default:
+		return nil, fmt.Errorf("parts mode not recognised: %v", conf.Mode)
+	}
+	return &parts{
+		conf:     conf,
+		log:      log,
+		mDropped: metrics.NewDroppedCounter(stats, "parts.dropped", metrics.DropReasonFilter),
+		mErr:     stats.GetCounter("parts.error"),
+	}, nil
+}
+
+// ProcessMessage maps the parts of a message according to the parts mode.
+func (p *parts) ProcessMessage(msg types.Message) ([]types.Message, types.Response) {
+	if msg.Len() == 0 {
+		return nil, response.NewAck()
+	}
+
+	switch p.conf.Mode {
+	case "concatenate":
+		var buf bytes.Buffer
+		_ = msg.Iter(func(i int, part types.Part) error {
+			if i > 0 {
+				buf.WriteString(p.conf.Delimiter)
+			}
+			buf.Write(part.Get())
+			return nil
+		})
+		newPart := msg.Get(0).Copy()
+		newPart.Set(buf.Bytes())
+		newMsg := message.New(nil)
+		newMsg.Append(newPart)
+		return []types.Message{newMsg}, nil
+	case "index":
+		index := p.conf.Index
+		if index < 0 {
+			index = msg.Len() + index
+		}
+		if index < 0 || index >= msg.Len() {
+			p.log.Warnf("Dropping message of %v parts as it has no part at index %v\n", msg.Len(), p.conf.Index)
+			p.mDropped.Incr(1)
+			return nil, response.NewAck()
+		}
+		newMsg := message.New(nil)
+		newMsg.Append(msg.Get(index).Copy())
+		return []types.Message{newMsg}, nil
+	case "each":
+		msgs := make([]types.Message, 0, msg.Len())
+		_ = msg.Iter(func(i int, part types.Part) error {
+			newMsg := message.New(nil)
+			newMsg.Append(part.Copy())
+			msgs = append(msgs, newMsg)
+			return nil
+		})
+		return msgs, nil
+	}
+
+	// Parts that aren't valid JSON are flagged as having failed, in which case
+	// the message is written as it is.
+	var array []interface{}
+	var failed bool
+	_ = msg.Iter(func(i int, part types.Part) error {
+		doc, err := part.JSON()
+		if err != nil {
+			p.log.Errorf("Failed to parse part %v as JSON for a JSON array: %v\n", i, err)
+			p.mErr.Incr(1)
+			processor.FlagErr(part, err)
+			failed = true
+			return nil
+		}
+		array = append(array, doc)
+		return nil
+	})
+	if failed {
+		return []types.Message{msg}, nil
+	}
+	newPart := msg.Get(0).Copy()
+	if err := newPart.SetJSON(array); err != nil {
+		p.log.Errorf("Failed to set JSON array: %v\n", err)
+		p.mErr.Incr(1)
+		_ = msg.Iter(func(i int, part types.Part) error {
+			processor.FlagErr(part, err)
+			return nil
+		})
+		return []types.Message{msg}, nil
+	}
+	newMsg := message.New(nil)
+	newMsg.Append(newPart)
+	return []types.Message{newMsg}, nil
+}
+
+// CloseAsync does nothing.
+func (p *parts) CloseAsync() {}
+
+// WaitForClose does nothing.
+func (p *parts) WaitForClose(time.Duration) error {
+	return nil
+}
+
+//------------------------------------------------------------------------------
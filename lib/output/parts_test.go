@@ -0,0 +1,155 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/processor"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartsModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		conf     func(c *PartsConfig)
+		input    []string
+		expected [][]string
+	}{
+		{
+			name: "concatenate",
+			conf: func(c *PartsConfig) {
+				c.Mode = "concatenate"
+				c.Delimiter = ","
+			},
+			input:    []string{"foo", "bar", "baz"},
+			expected: [][]string{{"foo,bar,baz"}},
+		},
+		{
+			name: "index",
+			conf: func(c *PartsConfig) {
+				c.Mode = "index"
+				c.Index = 1
+			},
+			input:    []string{"foo", "bar", "baz"},
+			expected: [][]string{{"bar"}},
+		},
+		{
+			name: "negative index",
+			conf: func(c *PartsConfig) {
+				c.Mode = "index"
+				c.Index = -1
+			},
+			input:    []string{"foo", "bar", "baz"},
+			expected: [][]string{{"baz"}},
+		},
+		{
+			name: "index out of bounds",
+			conf: func(c *PartsConfig) {
+				c.Mode = "index"
+				c.Index = 5
+			},
+			input:    []string{"foo", "bar", "baz"},
+			expected: nil,
+		},
+		{
+			name: "each",
+			conf: func(c *PartsConfig) {
+				c.Mode = "each"
+			},
+			input:    []string{"foo", "bar", "baz"},
+			expected: [][]string{{"foo"}, {"bar"}, {"baz"}},
+		},
+		{
+			name: "json array",
+			conf: func(c *PartsConfig) {
+				c.Mode = "json_array"
+			},
+			input:    []string{`{"id":1}`, `{"id":2}`},
+			expected: [][]string{{`[{"id":1},{"id":2}]`}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			conf := NewPartsConfig()
+			test.conf(&conf)
+
+			p, err := newParts(conf, log.Noop(), metrics.Noop())
+			require.NoError(t, err)
+
+			var input [][]byte
+			for _, s := range test.input {
+				input = append(input, []byte(s))
+			}
+			msgs, res := p.ProcessMessage(message.New(input))
+			if test.expected == nil {
+				require.NotNil(t, res)
+				assert.NoError(t, res.Error())
+				assert.Empty(t, msgs)
+				return
+			}
+			require.Nil(t, res)
+
+			var actual [][]string
+			for _, m := range msgs {
+				var parts []string
+				_ = m.Iter(func(i int, p types.Part) error {
+					parts = append(parts, string(p.Get()))
+					return nil
+				})
+				actual = append(actual, parts)
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestPartsBadJSON(t *testing.T) {
+	conf := NewPartsConfig()
+	conf.Mode = "json_array"
+
+	p, err := newParts(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	// The message is written as it is with the invalid part flagged.
+	msgs, res := p.ProcessMessage(message.New([][]byte{[]byte(`{"id":1}`), []byte(`nope`)}))
+	require.Nil(t, res)
+	require.Len(t, msgs, 1)
+	require.Equal(t, 2, msgs[0].Len())
+
+	assert.Equal(t, `{"id":1}`, string(msgs[0].Get(0).Get()))
+	assert.Equal(t, "", processor.GetFail(msgs[0].Get(0)))
+	assert.Equal(t, "nope", string(msgs[0].Get(1).Get()))
+	assert.Contains(t, processor.GetFail(msgs[0].Get(1)), "invalid character")
+}
+
+func TestPartsIndexOutOfRange(t *testing.T) {
+	conf := NewPartsConfig()
+	conf.Mode = "index"
+	conf.Index = 5
+
+	stats := metrics.NewLocal()
+	p, err := newParts(conf, log.Noop(), stats)
+	require.NoError(t, err)
+
+	msgs, res := p.ProcessMessage(message.New([][]byte{[]byte("foo"), []byte("bar")}))
+	require.NotNil(t, res)
+	assert.NoError(t, res.Error())
+	assert.Empty(t, msgs)
+
+	counters := stats.GetCounters()
+	assert.Equal(t, int64(1), counters["parts.dropped"])
+}
+
+func TestPartsBadMode(t *testing.T) {
+	conf := NewPartsConfig()
+	conf.Mode = "nope"
+
+	_, err := newParts(conf, log.Noop(), metrics.Noop())
+	assert.EqualError(t, err, "parts mode not recognised: nope")
+}
@@ -2,16 +2,20 @@ package output
 
 // PulsarConfig contains configuration for the Pulsar input type.
 type PulsarConfig struct {
-	URL         string `json:"url" yaml:"url"`
-	Topic       string `json:"topic" yaml:"topic"`
-	MaxInFlight int    `json:"max_in_flight" yaml:"max_in_flight"`
+	URL              string `json:"url" yaml:"url"`
+	Topic            string `json:"topic" yaml:"topic"`
+	Key              string `json:"key" yaml:"key"`
+	KeyBasedBatching bool   `json:"key_based_batching" yaml:"key_based_batching"`
+	MaxInFlight      int    `json:"max_in_flight" yaml:"max_in_flight"`
 }
 
 // NewPulsarConfig creates a new PulsarConfig with default values.
 func NewPulsarConfig() PulsarConfig {
 	return PulsarConfig{
-		URL:         "",
-		Topic:       "",
-		MaxInFlight: 1,
+		URL:              "",
+		Topic:            "",
+		Key:              "",
+		KeyBasedBatching: false,
+		MaxInFlight:      1,
 	}
 }
@@ -0,0 +1,85 @@
+package output
+
+import (
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+)
+
+//------------------------------------------------------------------------------
+
+// queueCloseTimeout is the maximum period of time spent rejecting queued
+// transactions once a queue is closed.
+const queueCloseTimeout = time.Second
+
+// queueTransactions reads transactions from a channel into a bounded queue and
+// returns a channel from which the workers of an output read them back out.
+// This allows an output to keep consuming transactions from upstream components
+// (such as a buffer) whilst its workers are busy with slow network I/O such as
+// DNS lookups or TLS handshakes, up to the size of the queue.
+//
+// The number of transactions waiting within the queue is reported with the
+// depth gauge, which is only set by the queue as it observes transactions both
+// entering and leaving it.
+//
+// The returned channel is closed once the source channel is closed and the
+// queue is drained, or immediately when closeChan is closed, in which case any
+// transactions that were read but not yet consumed are rejected with
+// types.ErrTypeClosed so that their senders aren't left waiting. Senders that
+// do not read the rejection within queueCloseTimeout are abandoned.
+func queueTransactions(in <-chan types.Transaction, size int, closeChan <-chan struct{}, depth metrics.StatGauge) <-chan types.Transaction {
+	if size < 1 {
+		size = 1
+	}
+	out := make(chan types.Transaction)
+	go func() {
+		queued := make([]types.Transaction, 0, size)
+		for {
+			// Only read from the source when there's room in the queue, and
+			// only write to the workers when there's something queued.
+			var inChan <-chan types.Transaction
+			if in != nil && len(queued) < size {
+				inChan = in
+			}
+			var outChan chan<- types.Transaction
+			var next types.Transaction
+			if len(queued) > 0 {
+				outChan, next = out, queued[0]
+			} else if in == nil {
+				close(out)
+				return
+			}
+
+			select {
+			case ts, open := <-inChan:
+				if !open {
+					in = nil
+					continue
+				}
+				queued = append(queued, ts)
+			case outChan <- next:
+				queued = append(queued[:0], queued[1:]...)
+			case <-closeChan:
+				close(out)
+				rejectQueued(queued)
+				depth.Set(0)
+				return
+			}
+			depth.Set(int64(len(queued)))
+		}
+	}()
+	return out
+}
+
+func rejectQueued(queued []types.Transaction) {
+	timeout := time.After(queueCloseTimeout)
+	for _, ts := range queued {
+		select {
+		case ts.ResponseChan <- response.NewError(types.ErrTypeClosed):
+		case <-timeout:
+			return
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueTransactionsDrain(t *testing.T) {
+	in := make(chan types.Transaction)
+	closeChan := make(chan struct{})
+	out := queueTransactions(in, 2, closeChan, metrics.Noop().GetGauge("queue"))
+
+	resChan := make(chan types.Response)
+	for _, content := range []string{"foo", "bar"} {
+		in <- types.NewTransaction(message.New([][]byte{[]byte(content)}), resChan)
+	}
+	close(in)
+
+	// Transactions that are already queued are still consumed once the source
+	// channel closes.
+	var contents []string
+	for ts := range out {
+		contents = append(contents, string(ts.Payload.Get(0).Get()))
+	}
+	assert.Equal(t, []string{"foo", "bar"}, contents)
+}
+
+func TestQueueTransactionsClosed(t *testing.T) {
+	in := make(chan types.Transaction)
+	closeChan := make(chan struct{})
+	out := queueTransactions(in, 2, closeChan, metrics.Noop().GetGauge("queue"))
+
+	// Both transactions are queued and neither is consumed before the queue is
+	// closed.
+	resChan := make(chan types.Response)
+	for _, content := range []string{"foo", "bar"} {
+		select {
+		case in <- types.NewTransaction(message.New([][]byte{[]byte(content)}), resChan):
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out")
+		}
+	}
+	close(closeChan)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case res := <-resChan:
+			assert.Equal(t, types.ErrTypeClosed, res.Error())
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out")
+		}
+	}
+
+	select {
+	case _, open := <-out:
+		require.False(t, open)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+}
+
+func TestQueueTransactionsClosedAbandoned(t *testing.T) {
+	stats := metrics.NewLocal()
+	in := make(chan types.Transaction)
+	closeChan := make(chan struct{})
+	_ = queueTransactions(in, 2, closeChan, stats.GetGauge("queue"))
+
+	resChan := make(chan types.Response)
+	for _, content := range []string{"foo", "bar"} {
+		select {
+		case in <- types.NewTransaction(message.New([][]byte{[]byte(content)}), resChan):
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out")
+		}
+	}
+
+	waitForDepth := func(exp int64, timeout time.Duration) {
+		t.Helper()
+		deadline := time.Now().Add(timeout)
+		for stats.GetCounters()["queue"] != exp {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for queue depth %v", exp)
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+	}
+	waitForDepth(2, time.Second*5)
+	close(closeChan)
+
+	// Nothing reads the rejections, the queue must still exit, which resets
+	// the depth gauge.
+	waitForDepth(0, queueCloseTimeout+time.Second*5)
+}
+
+func TestQueueTransactionsDepth(t *testing.T) {
+	stats := metrics.NewLocal()
+	in := make(chan types.Transaction)
+	closeChan := make(chan struct{})
+	defer close(closeChan)
+	out := queueTransactions(in, 2, closeChan, stats.GetGauge("queue"))
+
+	waitForDepth := func(exp int64) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second * 5)
+		for stats.GetCounters()["queue"] != exp {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for queue depth %v", exp)
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+	}
+
+	resChan := make(chan types.Response)
+	for _, content := range []string{"foo", "bar"} {
+		select {
+		case in <- types.NewTransaction(message.New([][]byte{[]byte(content)}), resChan):
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out")
+		}
+	}
+	waitForDepth(2)
+
+	// The queue is full and therefore doesn't accept more transactions.
+	select {
+	case in <- types.NewTransaction(message.New([][]byte{[]byte("baz")}), resChan):
+		t.Fatal("unexpected room in queue")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	for _, exp := range []string{"foo", "bar"} {
+		select {
+		case ts := <-out:
+			assert.Equal(t, exp, string(ts.Payload.Get(0).Get()))
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out")
+		}
+	}
+	waitForDepth(0)
+}
@@ -31,7 +31,7 @@ a metadata item and the body then the body takes precedence.`,
 		Async:   true,
 		Batches: true,
 		FieldSpecs: redis.ConfigDocs().Add(
-			docs.FieldCommon("stream", "The stream to add messages to."),
+			docs.FieldCommon("stream", "The stream to add messages to.").IsInterpolated(),
 			docs.FieldCommon("body_key", "A key to set the raw body of the message to."),
 			docs.FieldCommon("max_length", "When greater than zero enforces a rough cap on the length of the target stream."),
 			docs.FieldCommon("max_in_flight", "The maximum number of messages to have in flight at a given time. Increase this to improve throughput."),
@@ -8,10 +8,12 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/Jeffail/benthos/v3/internal/batch"
 	"github.com/Jeffail/benthos/v3/internal/component/output"
 	"github.com/Jeffail/benthos/v3/internal/docs"
 	"github.com/Jeffail/benthos/v3/internal/shutdown"
 	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/response"
 	"github.com/Jeffail/benthos/v3/lib/types"
@@ -37,6 +39,10 @@ This output type is useful whenever we wish to avoid reprocessing a message on
 the event of a failed send. We might, for example, have a dedupe processor that
 we want to avoid reapplying to the same message more than once in the pipeline.
 
+When the child output writes batches in bulk and reports which messages of a
+batch failed, as the ` + "`aws_sqs`, `elasticsearch` and `kafka`" + ` outputs
+do, only the failed messages are retried.
+
 Rather than retrying the same output you may wish to retry the send using a
 different output target (a dead letter queue). In which case you should instead
 use the ` + "[`try`](/docs/components/outputs/try)" + ` output type.`,
@@ -220,6 +226,8 @@ func (r *Retry) loop() {
 
 		wg.Add(1)
 		go func(ts types.Transaction, resChan chan types.Response) {
+			payload := ts.Payload
+
 			var backOff backoff.BackOff
 			var resOut types.Response
 			var inErrLoop bool
@@ -273,8 +281,12 @@ func (r *Retry) loop() {
 						return
 					}
 
+					// Outputs that report which messages of a batch failed
+					// allow us to only retry those messages.
+					payload = failedParts(payload, res.Error())
+
 					select {
-					case r.transactionsOut <- types.NewTransaction(ts.Payload, resChan):
+					case r.transactionsOut <- types.NewTransaction(payload, resChan):
 					case <-r.closeChan:
 						return
 					}
@@ -339,3 +351,27 @@ func (r *Retry) WaitForClose(timeout time.Duration) error {
 }
 
 //------------------------------------------------------------------------------
+
+// failedParts returns a message containing only the parts of a batch that
+// failed according to a batch error, or the original message if the error does
+// not identify individual failed parts.
+func failedParts(msg types.Message, err error) types.Message {
+	walkable, ok := err.(batch.WalkableError)
+	if !ok || walkable.IndexedErrors() == 0 || walkable.IndexedErrors() >= msg.Len() {
+		return msg
+	}
+
+	parts := 0
+	failed := message.New(nil)
+	walkable.WalkParts(func(i int, p types.Part, e error) bool {
+		parts++
+		if e != nil {
+			failed.Append(p)
+		}
+		return true
+	})
+	if parts != msg.Len() || failed.Len() == 0 {
+		return msg
+	}
+	return failed
+}
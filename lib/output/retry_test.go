@@ -1,14 +1,18 @@
 package output
 
 import (
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/Jeffail/benthos/v3/internal/batch"
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/message"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/response"
 	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRetryConfigErrs(t *testing.T) {
@@ -191,6 +195,78 @@ func TestRetrySadPath(t *testing.T) {
 	}
 }
 
+func TestRetryBatchErrorPartial(t *testing.T) {
+	conf := NewConfig()
+
+	childConf := NewConfig()
+	conf.Retry.Output = &childConf
+	conf.Retry.Backoff.InitialInterval = "10us"
+	conf.Retry.Backoff.MaxInterval = "10us"
+
+	output, err := NewRetry(conf, nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	ret, ok := output.(*Retry)
+	require.True(t, ok)
+
+	mOut := &mockOutput{
+		ts: make(chan types.Transaction),
+	}
+	ret.wrapped = mOut
+
+	tChan := make(chan types.Transaction)
+	resChan := make(chan types.Response)
+	require.NoError(t, ret.Consume(tChan))
+
+	testMsg := message.New([][]byte{[]byte("foo"), []byte("bar"), []byte("baz")})
+	go func() {
+		select {
+		case tChan <- types.NewTransaction(testMsg, resChan):
+		case <-time.After(time.Second):
+			t.Error("timed out")
+		}
+	}()
+
+	var tran types.Transaction
+	select {
+	case tran = <-mOut.ts:
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+	assert.Equal(t, 3, tran.Payload.Len())
+
+	bErr := batch.NewError(tran.Payload, errors.New("nope")).Failed(1, errors.New("nope"))
+	select {
+	case tran.ResponseChan <- response.NewError(bErr):
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	select {
+	case tran = <-mOut.ts:
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+	require.Equal(t, 1, tran.Payload.Len())
+	assert.Equal(t, "bar", string(tran.Payload.Get(0).Get()))
+
+	select {
+	case tran.ResponseChan <- response.NewAck():
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	select {
+	case res := <-resChan:
+		assert.NoError(t, res.Error())
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	output.CloseAsync()
+	assert.NoError(t, output.WaitForClose(time.Second))
+}
+
 func expectFromRetry(
 	resReturn types.Response,
 	tChan <-chan types.Transaction,
@@ -190,6 +190,29 @@ output:
           gcp_pubsub:
             project: people
             topic: that_i_dont_want_to_hang_with
+`,
+			},
+			{
+				Title: "Routing Parts of a Batch",
+				Summary: `
+Cases are checked against each message of a batch individually, and the messages that pass a case are sent to its output as a batch of their own. The batch is only acknowledged once every output it was routed to has confirmed delivery, and therefore the messages of a batch can be split across outputs without weakening delivery guarantees.
+
+In the following example each batch consists of a document followed by a message describing it, where the document is uploaded to S3 and the description is sent to Kafka.`,
+				Config: `
+output:
+  switch:
+    cases:
+      - check: batch_index() == 0
+        output:
+          aws_s3:
+            bucket: documents
+            path: ${!meta("document_id")}.json
+
+      - check: batch_index() == 1
+        output:
+          kafka:
+            addresses: [ localhost:9092 ]
+            topic: document_descriptions
 `,
 			},
 		},
@@ -574,6 +574,75 @@ func TestSwitchBatchSplit(t *testing.T) {
 	assert.NoError(t, s.WaitForClose(time.Second*5))
 }
 
+func TestSwitchBatchIndexRouting(t *testing.T) {
+	mockOutputs := []*MockOutputType{{}, {}}
+
+	conf := NewConfig()
+	for i := 0; i < len(mockOutputs); i++ {
+		conf.Switch.Cases = append(conf.Switch.Cases, NewSwitchConfigCase())
+	}
+	conf.Switch.Cases[0].Check = `batch_index() == 0`
+	conf.Switch.Cases[1].Check = `batch_index() > 0`
+
+	s := newSwitch(t, conf, mockOutputs)
+
+	readChan := make(chan types.Transaction)
+	resChan := make(chan types.Response)
+
+	require.NoError(t, s.Consume(readChan))
+
+	msg := message.New([][]byte{
+		[]byte(`body`),
+		[]byte(`meta one`),
+		[]byte(`meta two`),
+	})
+
+	select {
+	case readChan <- types.NewTransaction(msg, resChan):
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting to send")
+	}
+
+	var bodyTran, metaTran types.Transaction
+	for i := 0; i < len(mockOutputs); i++ {
+		select {
+		case bodyTran = <-mockOutputs[0].TChan:
+			assert.Equal(t, [][]byte{[]byte(`body`)}, message.GetAllBytes(bodyTran.Payload))
+		case metaTran = <-mockOutputs[1].TChan:
+			assert.Equal(t, [][]byte{[]byte(`meta one`), []byte(`meta two`)}, message.GetAllBytes(metaTran.Payload))
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for output to propagate")
+		}
+	}
+
+	// The source is not acknowledged until every destination has confirmed.
+	select {
+	case bodyTran.ResponseChan <- response.NewAck():
+	case <-time.After(time.Second):
+		t.Fatal("Timed out responding to output")
+	}
+	select {
+	case <-resChan:
+		t.Fatal("Received premature response from output")
+	case <-time.After(time.Millisecond * 50):
+	}
+	select {
+	case metaTran.ResponseChan <- response.NewAck():
+	case <-time.After(time.Second):
+		t.Fatal("Timed out responding to output")
+	}
+
+	select {
+	case res := <-resChan:
+		assert.NoError(t, res.Error())
+	case <-time.After(time.Second):
+		t.Fatal("Timed out responding to output")
+	}
+
+	s.CloseAsync()
+	assert.NoError(t, s.WaitForClose(time.Second*5))
+}
+
 func TestSwitchBatchGroup(t *testing.T) {
 	mockOutputs := []*MockOutputType{{}, {}, {}}
 
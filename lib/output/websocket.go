@@ -18,6 +18,7 @@ func init() {
 Sends messages to an HTTP server via a websocket connection.`,
 		FieldSpecs: docs.FieldSpecs{
 			docs.FieldCommon("url", "The URL to connect to."),
+			docs.FieldAdvanced("proxy_url", "An optional HTTP (`http://`) or SOCKS5 (`socks5://`) proxy URL to connect through.", "http://proxy.example.com:3128").AtVersion("3.54.0"),
 		}.Merge(auth.FieldSpecs()),
 		Categories: []Category{
 			CategoryNetwork,
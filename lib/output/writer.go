@@ -1,15 +1,18 @@
 package output
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/Jeffail/benthos/v3/internal/batch"
+	"github.com/Jeffail/benthos/v3/internal/component/output"
 	"github.com/Jeffail/benthos/v3/internal/shutdown"
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/message/roundtrip"
 	"github.com/Jeffail/benthos/v3/lib/message/tracing"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/output/writer"
@@ -30,6 +33,7 @@ type Writer struct {
 
 	log   log.Modular
 	stats metrics.Type
+	mBusy metrics.StatGauge
 
 	transactions <-chan types.Transaction
 
@@ -52,6 +56,7 @@ func NewWriter(
 		writer:         w,
 		log:            log,
 		stats:          stats,
+		mBusy:          stats.GetGauge("workers.busy"),
 		transactions:   nil,
 		closeChan:      make(chan struct{}),
 		fullyCloseChan: make(chan struct{}),
@@ -62,6 +67,9 @@ func NewWriter(
 //------------------------------------------------------------------------------
 
 func (w *Writer) latencyMeasuringWrite(msg types.Message) (latencyNs int64, err error) {
+	w.mBusy.Incr(1)
+	defer w.mBusy.Decr(1)
+
 	t0 := time.Now()
 	err = w.writer.Write(msg)
 	latencyNs = time.Since(t0).Nanoseconds()
@@ -77,10 +85,24 @@ func (w *Writer) loop() {
 		mSent       = w.stats.GetCounter("batch.sent")
 		mBytesSent  = w.stats.GetCounter("batch.bytes")
 		mLatency    = w.stats.GetTimer("batch.latency")
+		mDelivery   = w.stats.GetTimer("batch.delivery_latency")
 		mConn       = w.stats.GetCounter("connection.up")
 		mFailedConn = w.stats.GetCounter("connection.failed")
 		mLostConn   = w.stats.GetCounter("connection.lost")
+		mWorkers    = w.stats.GetGauge("workers")
+		mThrottled  = w.stats.GetCounter("throttled")
+		mInterval   = w.stats.GetGauge("throttle.interval")
 	)
+	mWorkers.Set(1)
+
+	adaptive := throttle.NewAdaptive()
+	if tr, ok := w.writer.(output.ThrottleReporter); ok {
+		tr.OnThrottle(func(retryAfter time.Duration) {
+			adaptive.Throttled(retryAfter)
+			mThrottled.Incr(1)
+			mInterval.Set(adaptive.Interval().Milliseconds())
+		})
+	}
 
 	defer func() {
 		_ = w.writer.WaitForClose(shutdown.MaximumShutdownWait())
@@ -123,6 +145,24 @@ func (w *Writer) loop() {
 			return
 		}
 
+		// Slow down when the sink has signalled that it is throttling
+		// writes.
+		if adaptive.Paced() {
+			waitCtx, waitDone := context.WithCancel(context.Background())
+			go func() {
+				select {
+				case <-w.closeChan:
+				case <-waitCtx.Done():
+				}
+				waitDone()
+			}()
+			permitted := adaptive.Wait(waitCtx)
+			waitDone()
+			if !permitted {
+				return
+			}
+		}
+
 		w.log.Tracef("Attempting to write %v messages to '%v'.\n", ts.Payload.Len(), w.typeStr)
 		spans := tracing.CreateChildSpans("output_"+w.typeStr, ts.Payload)
 		latency, err := w.latencyMeasuringWrite(ts.Payload)
@@ -160,6 +200,15 @@ func (w *Writer) loop() {
 			return
 		}
 
+		if retryAfter, throttled := output.IsThrottled(err); throttled {
+			adaptive.Throttled(retryAfter)
+			mThrottled.Incr(1)
+			mInterval.Set(adaptive.Interval().Milliseconds())
+		} else if err == nil && adaptive.Interval() > 0 {
+			adaptive.Succeeded()
+			mInterval.Set(adaptive.Interval().Milliseconds())
+		}
+
 		if err != nil {
 			w.log.Errorf("Failed to send message to %v: %v\n", w.typeStr, err)
 			if !throt.Retry() {
@@ -170,7 +219,9 @@ func (w *Writer) loop() {
 			mPartsSent.Incr(int64(batch.MessageCollapsedCount(ts.Payload)))
 			mBytesSent.Incr(int64(message.GetAllBytesLen(ts.Payload)))
 			mLatency.Timing(latency)
+			mDelivery.Timing(time.Since(message.ShiftedAt(ts.Payload)).Nanoseconds())
 			w.log.Tracef("Successfully wrote %v messages to '%v'.\n", ts.Payload.Len(), w.typeStr)
+			roundtrip.SetAsDelivered(ts.Payload)
 			throt.Reset()
 		}
 
@@ -197,7 +248,7 @@ func (w *Writer) Consume(ts <-chan types.Transaction) error {
 	if w.transactions != nil {
 		return types.ErrAlreadyStarted
 	}
-	w.transactions = ts
+	w.transactions = queueTransactions(ts, 1, w.closeChan, w.stats.GetGauge("queue"))
 	go w.loop()
 	return nil
 }
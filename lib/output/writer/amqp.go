@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 	"sync"
@@ -15,6 +16,7 @@ import (
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/proxy"
 	btls "github.com/Jeffail/benthos/v3/lib/util/tls"
 	"github.com/streadway/amqp"
 )
@@ -41,10 +43,14 @@ type AMQPConfig struct {
 	ContentType     string                    `json:"content_type" yaml:"content_type"`
 	ContentEncoding string                    `json:"content_encoding" yaml:"content_encoding"`
 	Metadata        output.Metadata           `json:"metadata" yaml:"metadata"`
+	Headers         map[string]string         `json:"headers" yaml:"headers"`
 	Priority        string                    `json:"priority" yaml:"priority"`
+	CorrelationID   string                    `json:"correlation_id" yaml:"correlation_id"`
+	ReplyTo         string                    `json:"reply_to" yaml:"reply_to"`
 	Persistent      bool                      `json:"persistent" yaml:"persistent"`
 	Mandatory       bool                      `json:"mandatory" yaml:"mandatory"`
 	Immediate       bool                      `json:"immediate" yaml:"immediate"`
+	ProxyURL        string                    `json:"proxy_url" yaml:"proxy_url"`
 	TLS             btls.Config               `json:"tls" yaml:"tls"`
 }
 
@@ -64,10 +70,14 @@ func NewAMQPConfig() AMQPConfig {
 		ContentType:     "application/octet-stream",
 		ContentEncoding: "",
 		Metadata:        output.NewMetadata(),
+		Headers:         map[string]string{},
 		Priority:        "",
+		CorrelationID:   "",
+		ReplyTo:         "",
 		Persistent:      false,
 		Mandatory:       false,
 		Immediate:       false,
+		ProxyURL:        "",
 		TLS:             btls.NewConfig(),
 	}
 }
@@ -81,22 +91,30 @@ type AMQP struct {
 	contentType     *field.Expression
 	contentEncoding *field.Expression
 	priority        *field.Expression
+	correlationID   *field.Expression
+	replyTo         *field.Expression
 	metaFilter      *output.MetadataFilter
+	headers         map[string]*field.Expression
 
 	log   log.Modular
 	stats metrics.Type
 
 	conf    AMQPConfig
 	tlsConf *tls.Config
+	dialer  proxy.Dialer
 
-	conn        *amqp.Connection
-	amqpChan    *amqp.Channel
-	confirmChan <-chan amqp.Confirmation
-	returnChan  <-chan amqp.Return
+	conn       *amqp.Connection
+	amqpChan   *amqp.Channel
+	confirms   *amqpConfirms
+	returnChan <-chan amqp.Return
 
 	deliveryMode uint8
 
 	connLock sync.RWMutex
+
+	// Returned messages cannot be matched to their publish and therefore
+	// messages are published one at a time when they might be returned.
+	returnLock sync.Mutex
 }
 
 // NewAMQP creates a new AMQP writer type.
@@ -106,6 +124,7 @@ func NewAMQP(conf AMQPConfig, log log.Modular, stats metrics.Type) (*AMQP, error
 		stats:        stats,
 		conf:         conf,
 		deliveryMode: amqp.Transient,
+		headers:      map[string]*field.Expression{},
 	}
 	var err error
 	if a.metaFilter, err = conf.Metadata.Filter(); err != nil {
@@ -126,6 +145,17 @@ func NewAMQP(conf AMQPConfig, log log.Modular, stats metrics.Type) (*AMQP, error
 	if a.priority, err = bloblang.NewField(conf.Priority); err != nil {
 		return nil, fmt.Errorf("failed to parse priority property expression: %w", err)
 	}
+	if a.correlationID, err = bloblang.NewField(conf.CorrelationID); err != nil {
+		return nil, fmt.Errorf("failed to parse correlation_id property expression: %w", err)
+	}
+	if a.replyTo, err = bloblang.NewField(conf.ReplyTo); err != nil {
+		return nil, fmt.Errorf("failed to parse reply_to property expression: %w", err)
+	}
+	for k, v := range conf.Headers {
+		if a.headers[k], err = bloblang.NewField(v); err != nil {
+			return nil, fmt.Errorf("failed to parse header '%v' expression: %w", k, err)
+		}
+	}
 	if conf.Persistent {
 		a.deliveryMode = amqp.Persistent
 	}
@@ -134,6 +164,13 @@ func NewAMQP(conf AMQPConfig, log log.Modular, stats metrics.Type) (*AMQP, error
 			return nil, err
 		}
 	}
+	if conf.ProxyURL != "" {
+		if a.dialer, err = proxy.NewDialer(conf.ProxyURL, &net.Dialer{
+			Timeout: 30 * time.Second,
+		}); err != nil {
+			return nil, err
+		}
+	}
 	return &a, nil
 }
 
@@ -152,7 +189,17 @@ func (a *AMQP) Connect() error {
 	var conn *amqp.Connection
 	var err error
 
-	if a.conf.TLS.Enabled {
+	if a.dialer != nil {
+		conn, err = amqp.DialConfig(a.conf.URL, amqp.Config{
+			Heartbeat:       10 * time.Second,
+			Locale:          "en_US",
+			TLSClientConfig: a.tlsConf,
+			Dial:            a.dialer.Dial,
+		})
+		if err != nil {
+			return fmt.Errorf("amqp failed to connect: %v", err)
+		}
+	} else if a.conf.TLS.Enabled {
 		conn, err = amqp.DialTLS(a.conf.URL, a.tlsConf)
 		if err != nil {
 			return fmt.Errorf("amqp failed to connect: %v", err)
@@ -192,7 +239,7 @@ func (a *AMQP) Connect() error {
 
 	a.conn = conn
 	a.amqpChan = amqpChan
-	a.confirmChan = amqpChan.NotifyPublish(make(chan amqp.Confirmation, a.conf.MaxInFlight))
+	a.confirms = newAMQPConfirms(amqpChan.NotifyPublish(make(chan amqp.Confirmation, a.conf.MaxInFlight)))
 	if a.conf.Mandatory || a.conf.Immediate {
 		a.returnChan = amqpChan.NotifyReturn(make(chan amqp.Return, 1))
 	}
@@ -220,19 +267,56 @@ func (a *AMQP) disconnect() error {
 
 //------------------------------------------------------------------------------
 
+// publishing creates the AMQP message to publish for the message part at index
+// i of a batch.
+func (a *AMQP) publishing(i int, msg types.Message) (amqp.Publishing, error) {
+	p := msg.Get(i)
+	msgType := strings.ReplaceAll(a.msgType.String(i, msg), "/", ".")
+	contentType := a.contentType.String(i, msg)
+	contentEncoding := a.contentEncoding.String(i, msg)
+
+	var priority uint8
+	if priorityString := a.priority.String(i, msg); priorityString != "" {
+		priorityInt, err := strconv.Atoi(priorityString)
+		if err != nil {
+			return amqp.Publishing{}, fmt.Errorf("failed to parse valid integer from priority expression: %w", err)
+		}
+		if priorityInt > 9 || priorityInt < 0 {
+			return amqp.Publishing{}, fmt.Errorf("invalid priority parsed from expression, must be <= 9 and >= 0, got %v", priorityInt)
+		}
+		priority = uint8(priorityInt)
+	}
+
+	headers := amqp.Table{}
+	a.metaFilter.Iter(p.Metadata(), func(k, v string) error {
+		headers[strings.ReplaceAll(k, "_", "-")] = v
+		return nil
+	})
+	for k, v := range a.headers {
+		headers[k] = v.String(i, msg)
+	}
+
+	return amqp.Publishing{
+		Headers:         headers,
+		ContentType:     contentType,
+		ContentEncoding: contentEncoding,
+		Body:            p.Get(),
+		DeliveryMode:    a.deliveryMode, // 1=non-persistent, 2=persistent
+		Priority:        priority,       // 0-9
+		Type:            msgType,
+		CorrelationId:   a.correlationID.String(i, msg),
+		ReplyTo:         a.replyTo.String(i, msg),
+		// a bunch of application/implementation-specific fields
+	}, nil
+}
+
 // WriteWithContext will attempt to write a message over AMQP, wait for
 // acknowledgement, and returns an error if applicable.
 func (a *AMQP) WriteWithContext(ctx context.Context, msg types.Message) error {
-	return a.Write(msg)
-}
-
-// Write will attempt to write a message over AMQP, wait for acknowledgement,
-// and returns an error if applicable.
-func (a *AMQP) Write(msg types.Message) error {
 	a.connLock.RLock()
 	conn := a.conn
 	amqpChan := a.amqpChan
-	confirmChan := a.confirmChan
+	confirms := a.confirms
 	returnChan := a.returnChan
 	a.connLock.RUnlock()
 
@@ -242,69 +326,64 @@ func (a *AMQP) Write(msg types.Message) error {
 
 	return IterateBatchedSend(msg, func(i int, p types.Part) error {
 		bindingKey := strings.ReplaceAll(a.key.String(i, msg), "/", ".")
-		msgType := strings.ReplaceAll(a.msgType.String(i, msg), "/", ".")
-		contentType := a.contentType.String(i, msg)
-		contentEncoding := a.contentEncoding.String(i, msg)
-
-		var priority uint8
-		if priorityString := a.priority.String(i, msg); priorityString != "" {
-			priorityInt, err := strconv.Atoi(priorityString)
-			if err != nil {
-				return fmt.Errorf("failed to parse valid integer from priority expression: %w", err)
-			}
-			if priorityInt > 9 || priorityInt < 0 {
-				return fmt.Errorf("invalid priority parsed from expression, must be <= 9 and >= 0, got %v", priorityInt)
-			}
-			priority = uint8(priorityInt)
+		pub, err := a.publishing(i, msg)
+		if err != nil {
+			return err
 		}
 
-		headers := amqp.Table{}
-		a.metaFilter.Iter(p.Metadata(), func(k, v string) error {
-			headers[strings.ReplaceAll(k, "_", "-")] = v
-			return nil
-		})
+		if returnChan != nil {
+			a.returnLock.Lock()
+			defer a.returnLock.Unlock()
+		}
 
-		err := amqpChan.Publish(
-			a.conf.Exchange,  // publish to an exchange
-			bindingKey,       // routing to 0 or more queues
-			a.conf.Mandatory, // mandatory
-			a.conf.Immediate, // immediate
-			amqp.Publishing{
-				Headers:         headers,
-				ContentType:     contentType,
-				ContentEncoding: contentEncoding,
-				Body:            p.Get(),
-				DeliveryMode:    a.deliveryMode, // 1=non-persistent, 2=persistent
-				Priority:        priority,       // 0-9
-				Type:            msgType,
-				// a bunch of application/implementation-specific fields
-			},
-		)
+		confirmChan, err := confirms.publish(func() error {
+			return amqpChan.Publish(
+				a.conf.Exchange,  // publish to an exchange
+				bindingKey,       // routing to 0 or more queues
+				a.conf.Mandatory, // mandatory
+				a.conf.Immediate, // immediate
+				pub,
+			)
+		})
 		if err != nil {
 			a.disconnect()
 			a.log.Errorf("Failed to send message: %v\n", err)
 			return types.ErrNotConnected
 		}
 		select {
-		case confirm, open := <-confirmChan:
+		case ack, open := <-confirmChan:
 			if !open {
 				a.log.Errorln("Failed to send message, ensure your target exchange exists.")
 				return types.ErrNotConnected
 			}
-			if !confirm.Ack {
+			if !ack {
 				a.log.Errorln("Failed to acknowledge message.")
 				return types.ErrNoAck
 			}
+			// A message is returned before it is confirmed.
+			select {
+			case <-returnChan:
+				return types.ErrNoAck
+			default:
+			}
 		case _, open := <-returnChan:
 			if !open {
 				return fmt.Errorf("acknowledgement not supported, ensure server supports immediate and mandatory flags")
 			}
 			return types.ErrNoAck
+		case <-ctx.Done():
+			return ctx.Err()
 		}
 		return nil
 	})
 }
 
+// Write will attempt to write a message over AMQP, wait for acknowledgement,
+// and returns an error if applicable.
+func (a *AMQP) Write(msg types.Message) error {
+	return a.WriteWithContext(context.Background(), msg)
+}
+
 // CloseAsync shuts down the AMQP output and stops processing messages.
 func (a *AMQP) CloseAsync() {
 	a.disconnect()
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/Azure/go-amqp"
+	"github.com/Jeffail/benthos/v3/internal/bloblang"
+	"github.com/Jeffail/benthos/v3/internal/bloblang/field"
 	"github.com/Jeffail/benthos/v3/internal/component/output"
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
@@ -26,6 +28,8 @@ type AMQP1Config struct {
 	TLS           btls.Config     `json:"tls" yaml:"tls"`
 	SASL          sasl.Config     `json:"sasl" yaml:"sasl"`
 	Metadata      output.Metadata `json:"metadata" yaml:"metadata"`
+
+	ApplicationProperties map[string]string `json:"application_properties" yaml:"application_properties"`
 }
 
 // NewAMQP1Config creates a new AMQP1Config with default values.
@@ -37,6 +41,8 @@ func NewAMQP1Config() AMQP1Config {
 		TLS:           btls.NewConfig(),
 		SASL:          sasl.NewConfig(),
 		Metadata:      output.NewMetadata(),
+
+		ApplicationProperties: map[string]string{},
 	}
 }
 
@@ -49,6 +55,7 @@ type AMQP1 struct {
 	sender  *amqp.Sender
 
 	metaFilter *output.MetadataFilter
+	appProps   map[string]*field.Expression
 
 	log   log.Modular
 	stats metrics.Type
@@ -62,9 +69,10 @@ type AMQP1 struct {
 // NewAMQP1 creates a new AMQP1 writer type.
 func NewAMQP1(conf AMQP1Config, log log.Modular, stats metrics.Type) (*AMQP1, error) {
 	a := AMQP1{
-		log:   log,
-		stats: stats,
-		conf:  conf,
+		log:      log,
+		stats:    stats,
+		conf:     conf,
+		appProps: map[string]*field.Expression{},
 	}
 	var err error
 	if conf.TLS.Enabled {
@@ -75,6 +83,11 @@ func NewAMQP1(conf AMQP1Config, log log.Modular, stats metrics.Type) (*AMQP1, er
 	if a.metaFilter, err = conf.Metadata.Filter(); err != nil {
 		return nil, fmt.Errorf("failed to construct metadata filter: %w", err)
 	}
+	for k, v := range conf.ApplicationProperties {
+		if a.appProps[k], err = bloblang.NewField(v); err != nil {
+			return nil, fmt.Errorf("failed to parse application property '%v' expression: %w", k, err)
+		}
+	}
 	return &a, nil
 }
 
@@ -193,6 +206,12 @@ func (a *AMQP1) WriteWithContext(ctx context.Context, msg types.Message) error {
 			m.Annotations[k] = v
 			return nil
 		})
+		if len(a.appProps) > 0 {
+			m.ApplicationProperties = make(map[string]interface{}, len(a.appProps))
+			for k, v := range a.appProps {
+				m.ApplicationProperties[k] = v.String(i, msg)
+			}
+		}
 		err := s.Send(ctx, m)
 		if err != nil {
 			if err == amqp.ErrTimeout {
@@ -0,0 +1,76 @@
+package writer
+
+import (
+	"sync"
+
+	"github.com/streadway/amqp"
+)
+
+//------------------------------------------------------------------------------
+
+// amqpConfirms matches the publisher confirmations of an AMQP channel in
+// confirm mode to the publishes they belong to. The server numbers the
+// messages published to a channel sequentially starting from one, and
+// confirmations are delivered in that order, which means concurrent
+// publishers can only tell whether their own message was confirmed by
+// tracking its delivery tag.
+type amqpConfirms struct {
+	mut     sync.Mutex
+	closed  bool
+	nextTag uint64
+	waiters map[uint64]chan bool
+}
+
+// newAMQPConfirms creates a tracker for a channel that has just been put into
+// confirm mode, and begins consuming its confirmations.
+func newAMQPConfirms(confirmChan <-chan amqp.Confirmation) *amqpConfirms {
+	c := &amqpConfirms{
+		waiters: map[uint64]chan bool{},
+	}
+	go c.loop(confirmChan)
+	return c
+}
+
+func (c *amqpConfirms) loop(confirmChan <-chan amqp.Confirmation) {
+	for confirm := range confirmChan {
+		c.mut.Lock()
+		if waiter, exists := c.waiters[confirm.DeliveryTag]; exists {
+			waiter <- confirm.Ack
+			delete(c.waiters, confirm.DeliveryTag)
+		}
+		c.mut.Unlock()
+	}
+
+	// The channel has closed and the remaining publishes will never be
+	// confirmed.
+	c.mut.Lock()
+	c.closed = true
+	for tag, waiter := range c.waiters {
+		close(waiter)
+		delete(c.waiters, tag)
+	}
+	c.mut.Unlock()
+}
+
+// publish calls a function that publishes a single message to the channel, and
+// returns a channel that receives whether the message was acknowledged by the
+// server, or is closed if the channel closes before the message is confirmed.
+func (c *amqpConfirms) publish(fn func() error) (<-chan bool, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if err := fn(); err != nil {
+		return nil, err
+	}
+
+	c.nextTag++
+	waiter := make(chan bool, 1)
+	if c.closed {
+		close(waiter)
+	} else {
+		c.waiters[c.nextTag] = waiter
+	}
+	return waiter, nil
+}
+
+//------------------------------------------------------------------------------
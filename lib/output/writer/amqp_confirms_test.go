@@ -0,0 +1,63 @@
+package writer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAMQPConfirmsMatchedByTag(t *testing.T) {
+	confirmChan := make(chan amqp.Confirmation)
+	c := newAMQPConfirms(confirmChan)
+
+	var waiters []<-chan bool
+	for i := 0; i < 3; i++ {
+		w, err := c.publish(func() error { return nil })
+		require.NoError(t, err)
+		waiters = append(waiters, w)
+	}
+
+	_, err := c.publish(func() error { return errors.New("nope") })
+	require.EqualError(t, err, "nope")
+
+	confirmChan <- amqp.Confirmation{DeliveryTag: 1, Ack: true}
+	confirmChan <- amqp.Confirmation{DeliveryTag: 2, Ack: false}
+
+	select {
+	case ack := <-waiters[1]:
+		assert.False(t, ack)
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+	select {
+	case ack := <-waiters[0]:
+		assert.True(t, ack)
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	// The third message must not be considered confirmed by the
+	// confirmations of the others.
+	select {
+	case <-waiters[2]:
+		t.Fatal("unexpected confirmation")
+	default:
+	}
+
+	close(confirmChan)
+	select {
+	case _, open := <-waiters[2]:
+		assert.False(t, open)
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	w, err := c.publish(func() error { return nil })
+	require.NoError(t, err)
+	_, open := <-w
+	assert.False(t, open)
+}
@@ -0,0 +1,76 @@
+package writer
+
+import (
+	"net"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAMQPReplyProperties(t *testing.T) {
+	conf := NewAMQPConfig()
+	conf.CorrelationID = `${! meta("amqp_correlation_id") }`
+	conf.ReplyTo = `${! meta("reply_queue") }`
+
+	w, err := NewAMQP(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	msg := message.New([][]byte{[]byte("foo"), []byte("bar")})
+	msg.Get(0).Metadata().Set("amqp_correlation_id", "first")
+	msg.Get(0).Metadata().Set("reply_queue", "replies")
+	msg.Get(1).Metadata().Set("amqp_correlation_id", "second")
+
+	pub, err := w.publishing(0, msg)
+	require.NoError(t, err)
+	assert.Equal(t, "first", pub.CorrelationId)
+	assert.Equal(t, "replies", pub.ReplyTo)
+	assert.Equal(t, "foo", string(pub.Body))
+
+	pub, err = w.publishing(1, msg)
+	require.NoError(t, err)
+	assert.Equal(t, "second", pub.CorrelationId)
+	assert.Equal(t, "", pub.ReplyTo)
+	assert.Equal(t, "bar", string(pub.Body))
+}
+
+func TestAMQPReplyPropertiesUnset(t *testing.T) {
+	w, err := NewAMQP(NewAMQPConfig(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	msg := message.New([][]byte{[]byte("foo")})
+	msg.Get(0).Metadata().Set("amqp_correlation_id", "first")
+
+	pub, err := w.publishing(0, msg)
+	require.NoError(t, err)
+	assert.Equal(t, "", pub.CorrelationId)
+	assert.Equal(t, "", pub.ReplyTo)
+}
+
+func TestAMQPProxy(t *testing.T) {
+	proxyAddr, proxyTargets := runConnectProxy(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	targetAddr := ln.Addr().String()
+	ln.Close()
+
+	conf := NewAMQPConfig()
+	conf.URL = "amqp://guest:guest@" + targetAddr + "/"
+	conf.ProxyURL = "http://" + proxyAddr
+
+	w, err := NewAMQP(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	// Nothing listens at the target, but the connection is still attempted
+	// through the proxy.
+	require.Error(t, w.Connect())
+	assert.Equal(t, []string{targetAddr}, proxyTargets())
+
+	conf.ProxyURL = "ftp://localhost:21"
+	_, err = NewAMQP(conf, log.Noop(), metrics.Noop())
+	require.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package writer
+
+import (
+	"github.com/Jeffail/benthos/v3/internal/component/output"
+	"github.com/Jeffail/benthos/v3/lib/message/batch"
+	"github.com/Jeffail/benthos/v3/lib/util/kafka/eventhub"
+)
+
+//------------------------------------------------------------------------------
+
+// AzureEventHubConfig contains configuration fields for the AzureEventHub output
+// type.
+type AzureEventHubConfig struct {
+	ConnectionString string             `json:"connection_string" yaml:"connection_string"`
+	EventHub         string             `json:"event_hub" yaml:"event_hub"`
+	PartitionKey     string             `json:"partition_key" yaml:"partition_key"`
+	Partition        string             `json:"partition" yaml:"partition"`
+	ClientID         string             `json:"client_id" yaml:"client_id"`
+	Metadata         output.Metadata    `json:"metadata" yaml:"metadata"`
+	MaxInFlight      int                `json:"max_in_flight" yaml:"max_in_flight"`
+	MaxMsgBytes      int                `json:"max_msg_bytes" yaml:"max_msg_bytes"`
+	Timeout          string             `json:"timeout" yaml:"timeout"`
+	Batching         batch.PolicyConfig `json:"batching" yaml:"batching"`
+}
+
+// NewAzureEventHubConfig creates a new AzureEventHubConfig with default values.
+func NewAzureEventHubConfig() AzureEventHubConfig {
+	return AzureEventHubConfig{
+		ConnectionString: "",
+		EventHub:         "",
+		PartitionKey:     "",
+		Partition:        "",
+		ClientID:         "benthos_azure_event_hub_output",
+		Metadata:         output.NewMetadata(),
+		MaxInFlight:      1,
+		MaxMsgBytes:      1000000,
+		Timeout:          "5s",
+		Batching:         batch.NewPolicyConfig(),
+	}
+}
+
+// KafkaConfig returns the config of a kafka output that publishes to the Kafka
+// compatible endpoint of the event hub.
+func (c AzureEventHubConfig) KafkaConfig() (KafkaConfig, error) {
+	endpoint, err := eventhub.ParseConnectionString(c.ConnectionString)
+	if err != nil {
+		return KafkaConfig{}, err
+	}
+	topic, err := endpoint.Topic(c.EventHub)
+	if err != nil {
+		return KafkaConfig{}, err
+	}
+
+	kConf := NewKafkaConfig()
+	kConf.Addresses = []string{endpoint.Address}
+	kConf.TLS = endpoint.TLS()
+	kConf.SASL = endpoint.SASL()
+	kConf.Topic = topic
+	kConf.Key = c.PartitionKey
+	if c.Partition != "" {
+		kConf.Partitioner = "manual"
+		kConf.Partition = c.Partition
+	}
+	kConf.ClientID = c.ClientID
+	kConf.Metadata = c.Metadata
+	kConf.MaxInFlight = c.MaxInFlight
+	kConf.MaxMsgBytes = c.MaxMsgBytes
+	kConf.Timeout = c.Timeout
+	kConf.Batching = c.Batching
+	return kConf, nil
+}
+
+//------------------------------------------------------------------------------
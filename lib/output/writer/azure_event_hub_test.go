@@ -0,0 +1,54 @@
+package writer
+
+import (
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureEventHubKafkaConfig(t *testing.T) {
+	connStr := "Endpoint=sb://foo.servicebus.windows.net/;SharedAccessKeyName=a;SharedAccessKey=b=;EntityPath=bar"
+
+	conf := NewAzureEventHubConfig()
+	conf.ConnectionString = connStr
+	conf.PartitionKey = `${! meta("device") }`
+
+	kConf, err := conf.KafkaConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo.servicebus.windows.net:9093"}, kConf.Addresses)
+	assert.Equal(t, "bar", kConf.Topic)
+	assert.Equal(t, `${! meta("device") }`, kConf.Key)
+	assert.Equal(t, "fnv1a_hash", kConf.Partitioner)
+	assert.True(t, kConf.TLS.Enabled)
+	assert.Equal(t, "PLAIN", kConf.SASL.Mechanism)
+	assert.Equal(t, "$ConnectionString", kConf.SASL.User)
+	assert.Equal(t, connStr, kConf.SASL.Password)
+
+	_, err = NewKafka(kConf, types.NoopMgr(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	conf.Partition = `${! meta("partition") }`
+	kConf, err = conf.KafkaConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "manual", kConf.Partitioner)
+	assert.Equal(t, `${! meta("partition") }`, kConf.Partition)
+
+	_, err = NewKafka(kConf, types.NoopMgr(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+}
+
+func TestAzureEventHubKafkaConfigErrors(t *testing.T) {
+	conf := NewAzureEventHubConfig()
+	_, err := conf.KafkaConfig()
+	require.Error(t, err)
+
+	conf.ConnectionString = "Endpoint=sb://foo.servicebus.windows.net/;SharedAccessKeyName=a;SharedAccessKey=b;EntityPath=bar"
+	conf.EventHub = "baz"
+	_, err = conf.KafkaConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "EntityPath")
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	batchInternal "github.com/Jeffail/benthos/v3/internal/batch"
 	"github.com/Jeffail/benthos/v3/internal/bloblang"
 	"github.com/Jeffail/benthos/v3/internal/bloblang/field"
 	"github.com/Jeffail/benthos/v3/lib/log"
@@ -259,6 +260,7 @@ func (e *Elasticsearch) Write(msg types.Message) error {
 	boff := e.backoffCtor()
 
 	requests := map[string]*pendingBulkIndex{}
+	indexes := map[string][]int{}
 	if err := msg.Iter(func(i int, part types.Part) error {
 		jObj, ierr := part.JSON()
 		if ierr != nil {
@@ -266,7 +268,9 @@ func (e *Elasticsearch) Write(msg types.Message) error {
 			e.log.Errorf("Failed to marshal message into JSON document: %v\n", ierr)
 			return fmt.Errorf("failed to marshal message into JSON document: %w", ierr)
 		}
-		requests[e.idStr.String(i, msg)] = &pendingBulkIndex{
+		id := e.idStr.String(i, msg)
+		indexes[id] = append(indexes[id], i)
+		requests[id] = &pendingBulkIndex{
 			Action:   e.actionStr.String(i, msg),
 			Index:    e.indexStr.String(i, msg),
 			Pipeline: e.pipelineStr.String(i, msg),
@@ -307,7 +311,8 @@ func (e *Elasticsearch) Write(msg types.Message) error {
 			}
 			if !shouldRetry(failed[i].Status) {
 				e.log.Errorf("Elasticsearch message '%v' rejected with code [%v]: %v\n", failed[i].Id, failed[i].Status, reason)
-				return fmt.Errorf("failed to send %v parts from message: [%v]: %v", len(failed), failed[i].Status, reason)
+				err := fmt.Errorf("failed to send %v parts from message: [%v]: %v", len(failed), failed[i].Status, reason)
+				return esBatchError(msg, err, indexes, failed)
 			}
 			e.log.Errorf("Elasticsearch message '%v' failed with code [%v]: %v\n", failed[i].Id, failed[i].Status, reason)
 			id := failed[i].Id
@@ -323,7 +328,8 @@ func (e *Elasticsearch) Write(msg types.Message) error {
 			if fErr := failed[0].Error; fErr != nil {
 				reason = fErr.Reason
 			}
-			return fmt.Errorf("failed to send %v parts from message: %v", len(failed), reason)
+			err := fmt.Errorf("failed to send %v parts from message: %v", len(failed), reason)
+			return esBatchError(msg, err, indexes, failed)
 		}
 		time.Sleep(wait)
 	}
@@ -331,6 +337,22 @@ func (e *Elasticsearch) Write(msg types.Message) error {
 	return nil
 }
 
+// esBatchError returns a batch error where only the messages of failed bulk
+// items are marked as failed, allowing the remaining messages to be
+// acknowledged.
+func esBatchError(msg types.Message, err error, indexes map[string][]int, failed []*elastic.BulkResponseItem) error {
+	bErr := batchInternal.NewError(msg, err)
+	for _, f := range failed {
+		for _, i := range indexes[f.Id] {
+			bErr.Failed(i, err)
+		}
+	}
+	if bErr.IndexedErrors() == 0 {
+		return err
+	}
+	return bErr
+}
+
 // CloseAsync shuts down the Elasticsearch writer and stops processing messages.
 func (e *Elasticsearch) CloseAsync() {
 }
@@ -24,6 +24,7 @@ type GCPPubSubConfig struct {
 	TopicID        string          `json:"topic" yaml:"topic"`
 	MaxInFlight    int             `json:"max_in_flight" yaml:"max_in_flight"`
 	PublishTimeout string          `json:"publish_timeout" yaml:"publish_timeout"`
+	OrderingKey    string          `json:"ordering_key" yaml:"ordering_key"`
 	Metadata       output.Metadata `json:"metadata" yaml:"metadata"`
 }
 
@@ -34,6 +35,7 @@ func NewGCPPubSubConfig() GCPPubSubConfig {
 		TopicID:        "",
 		MaxInFlight:    1,
 		PublishTimeout: "60s",
+		OrderingKey:    "",
 		Metadata:       output.NewMetadata(),
 	}
 }
@@ -49,9 +51,10 @@ type GCPPubSub struct {
 	publishTimeout time.Duration
 	metaFilter     *output.MetadataFilter
 
-	topicID  *field.Expression
-	topics   map[string]*pubsub.Topic
-	topicMut sync.Mutex
+	topicID     *field.Expression
+	orderingKey *field.Expression
+	topics      map[string]*pubsub.Topic
+	topicMut    sync.Mutex
 
 	log   log.Modular
 	stats metrics.Type
@@ -71,6 +74,10 @@ func NewGCPPubSub(
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse topic expression: %v", err)
 	}
+	orderingKey, err := bloblang.NewField(conf.OrderingKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ordering key expression: %v", err)
+	}
 	pubTimeout, err := time.ParseDuration(conf.PublishTimeout)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse publish timeout duration: %w", err)
@@ -87,6 +94,7 @@ func NewGCPPubSub(
 		publishTimeout: pubTimeout,
 		stats:          stats,
 		topicID:        topic,
+		orderingKey:    orderingKey,
 	}, nil
 }
 
@@ -123,6 +131,7 @@ func (c *GCPPubSub) getTopic(ctx context.Context, t string) (*pubsub.Topic, erro
 		return nil, fmt.Errorf("topic '%v' does not exist", t)
 	}
 	topic.PublishSettings.Timeout = c.publishTimeout
+	topic.EnableMessageOrdering = c.conf.OrderingKey != ""
 	c.topics[t] = topic
 	return topic, nil
 }
@@ -146,6 +155,7 @@ func (c *GCPPubSub) WriteWithContext(ctx context.Context, msg types.Message) err
 	}
 
 	results := make([]*pubsub.PublishResult, msg.Len())
+	orderingKeys := make([]string, msg.Len())
 	msg.Iter(func(i int, part types.Part) error {
 		topic := topics[i]
 		attr := map[string]string{}
@@ -159,6 +169,10 @@ func (c *GCPPubSub) WriteWithContext(ctx context.Context, msg types.Message) err
 		if len(attr) > 0 {
 			gmsg.Attributes = attr
 		}
+		if c.conf.OrderingKey != "" {
+			orderingKeys[i] = c.orderingKey.String(i, msg)
+			gmsg.OrderingKey = orderingKeys[i]
+		}
 		results[i] = topic.Publish(ctx, gmsg)
 		return nil
 	})
@@ -166,6 +180,11 @@ func (c *GCPPubSub) WriteWithContext(ctx context.Context, msg types.Message) err
 	var batchErr *batch.Error
 	for i, r := range results {
 		if _, err := r.Get(ctx); err != nil {
+			// Publishing is paused for an ordering key after a failure, and
+			// is resumed so that the message can be reattempted.
+			if orderingKeys[i] != "" {
+				topics[i].ResumePublish(orderingKeys[i])
+			}
 			if batchErr == nil {
 				batchErr = batch.NewError(msg, err)
 			}
@@ -0,0 +1,54 @@
+package writer
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCPPubSubOrderingKey(t *testing.T) {
+	srv := pstest.NewServer()
+	defer srv.Close()
+
+	require.NoError(t, os.Setenv("PUBSUB_EMULATOR_HOST", srv.Addr))
+	defer os.Unsetenv("PUBSUB_EMULATOR_HOST")
+
+	conf := NewGCPPubSubConfig()
+	conf.ProjectID = "foo"
+	conf.TopicID = "bar"
+	conf.OrderingKey = `${! meta("key") }`
+
+	w, err := NewGCPPubSub(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = w.client.CreateTopic(ctx, "bar")
+	require.NoError(t, err)
+
+	require.NoError(t, w.ConnectWithContext(ctx))
+	defer func() {
+		w.CloseAsync()
+		require.NoError(t, w.WaitForClose(0))
+	}()
+
+	msg := message.New([][]byte{[]byte("first"), []byte("second")})
+	msg.Get(0).Metadata().Set("key", "a")
+	msg.Get(1).Metadata().Set("key", "b")
+	require.NoError(t, w.WriteWithContext(ctx, msg))
+
+	keys := map[string]string{}
+	for _, m := range srv.Messages() {
+		keys[string(m.Data)] = m.OrderingKey
+	}
+	assert.Equal(t, map[string]string{
+		"first":  "a",
+		"second": "b",
+	}, keys)
+}
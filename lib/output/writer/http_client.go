@@ -22,6 +22,8 @@ type HTTPClientConfig struct {
 	BatchAsMultipart  bool               `json:"batch_as_multipart" yaml:"batch_as_multipart"`
 	MaxInFlight       int                `json:"max_in_flight" yaml:"max_in_flight"`
 	PropagateResponse bool               `json:"propagate_response" yaml:"propagate_response"`
+	Compression       string             `json:"compression" yaml:"compression"`
+	WireFormat        bool               `json:"wire_format" yaml:"wire_format"`
 	Batching          batch.PolicyConfig `json:"batching" yaml:"batching"`
 }
 
@@ -32,6 +34,8 @@ func NewHTTPClientConfig() HTTPClientConfig {
 		BatchAsMultipart:  true, // TODO: V4 Set false by default.
 		MaxInFlight:       1,    // TODO: Increase this default?
 		PropagateResponse: false,
+		Compression:       "none",
+		WireFormat:        false,
 		Batching:          batch.NewPolicyConfig(),
 	}
 }
@@ -63,14 +67,20 @@ func NewHTTPClient(
 		conf:      conf,
 		closeChan: make(chan struct{}),
 	}
-	var err error
-	if h.client, err = http.NewClient(
-		conf.Config,
+	opts := []func(*http.Client){
 		http.OptSetLogger(h.log),
 		http.OptSetManager(mgr),
 		// TODO: V4 Remove this
 		http.OptSetStats(metrics.Namespaced(h.stats, "client")),
-	); err != nil {
+	}
+	if conf.Compression != "" && conf.Compression != "none" {
+		opts = append(opts, http.OptSetCompression(conf.Compression))
+	}
+	if conf.WireFormat {
+		opts = append(opts, http.OptSetWireFormat())
+	}
+	var err error
+	if h.client, err = http.NewClient(conf.Config, opts...); err != nil {
 		return nil, err
 	}
 	return &h, nil
@@ -3,7 +3,10 @@ package writer
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,8 +19,10 @@ import (
 	"github.com/Jeffail/benthos/v3/lib/message/batch"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/discovery"
 	"github.com/Jeffail/benthos/v3/lib/util/hash/murmur2"
 	"github.com/Jeffail/benthos/v3/lib/util/kafka/sasl"
+	"github.com/Jeffail/benthos/v3/lib/util/proxy"
 	"github.com/Jeffail/benthos/v3/lib/util/retries"
 	btls "github.com/Jeffail/benthos/v3/lib/util/tls"
 	"github.com/Shopify/sarama"
@@ -28,25 +33,29 @@ import (
 
 // KafkaConfig contains configuration fields for the Kafka output type.
 type KafkaConfig struct {
-	Addresses        []string    `json:"addresses" yaml:"addresses"`
-	ClientID         string      `json:"client_id" yaml:"client_id"`
-	Key              string      `json:"key" yaml:"key"`
-	Partitioner      string      `json:"partitioner" yaml:"partitioner"`
-	Topic            string      `json:"topic" yaml:"topic"`
-	Compression      string      `json:"compression" yaml:"compression"`
-	MaxMsgBytes      int         `json:"max_msg_bytes" yaml:"max_msg_bytes"`
-	Timeout          string      `json:"timeout" yaml:"timeout"`
-	AckReplicas      bool        `json:"ack_replicas" yaml:"ack_replicas"`
-	TargetVersion    string      `json:"target_version" yaml:"target_version"`
-	TLS              btls.Config `json:"tls" yaml:"tls"`
-	SASL             sasl.Config `json:"sasl" yaml:"sasl"`
-	MaxInFlight      int         `json:"max_in_flight" yaml:"max_in_flight"`
-	retries.Config   `json:",inline" yaml:",inline"`
-	RetryAsBatch     bool               `json:"retry_as_batch" yaml:"retry_as_batch"`
-	Batching         batch.PolicyConfig `json:"batching" yaml:"batching"`
-	StaticHeaders    map[string]string  `json:"static_headers" yaml:"static_headers"`
-	Metadata         output.Metadata    `json:"metadata" yaml:"metadata"`
-	InjectTracingMap string             `json:"inject_tracing_map" yaml:"inject_tracing_map"`
+	Addresses         []string    `json:"addresses" yaml:"addresses"`
+	DiscoveryInterval string      `json:"discovery_interval" yaml:"discovery_interval"`
+	ProxyURL          string      `json:"proxy_url" yaml:"proxy_url"`
+	ClientID          string      `json:"client_id" yaml:"client_id"`
+	Key               string      `json:"key" yaml:"key"`
+	Partitioner       string      `json:"partitioner" yaml:"partitioner"`
+	Partition         string      `json:"partition" yaml:"partition"`
+	Topic             string      `json:"topic" yaml:"topic"`
+	Compression       string      `json:"compression" yaml:"compression"`
+	MaxMsgBytes       int         `json:"max_msg_bytes" yaml:"max_msg_bytes"`
+	Timeout           string      `json:"timeout" yaml:"timeout"`
+	AckReplicas       bool        `json:"ack_replicas" yaml:"ack_replicas"`
+	TargetVersion     string      `json:"target_version" yaml:"target_version"`
+	TLS               btls.Config `json:"tls" yaml:"tls"`
+	SASL              sasl.Config `json:"sasl" yaml:"sasl"`
+	MaxInFlight       int         `json:"max_in_flight" yaml:"max_in_flight"`
+	retries.Config    `json:",inline" yaml:",inline"`
+	RetryAsBatch      bool               `json:"retry_as_batch" yaml:"retry_as_batch"`
+	Batching          batch.PolicyConfig `json:"batching" yaml:"batching"`
+	StaticHeaders     map[string]string  `json:"static_headers" yaml:"static_headers"`
+	Headers           map[string]string  `json:"headers" yaml:"headers"`
+	Metadata          output.Metadata    `json:"metadata" yaml:"metadata"`
+	InjectTracingMap  string             `json:"inject_tracing_map" yaml:"inject_tracing_map"`
 
 	// TODO: V4 remove this.
 	RoundRobinPartitions bool `json:"round_robin_partitions" yaml:"round_robin_partitions"`
@@ -61,10 +70,13 @@ func NewKafkaConfig() KafkaConfig {
 
 	return KafkaConfig{
 		Addresses:            []string{"localhost:9092"},
+		DiscoveryInterval:    "30s",
+		ProxyURL:             "",
 		ClientID:             "benthos_kafka_output",
 		Key:                  "",
 		RoundRobinPartitions: false,
 		Partitioner:          "fnv1a_hash",
+		Partition:            "",
 		Topic:                "benthos_stream",
 		Compression:          "none",
 		MaxMsgBytes:          1000000,
@@ -72,6 +84,7 @@ func NewKafkaConfig() KafkaConfig {
 		AckReplicas:          false,
 		TargetVersion:        sarama.V1_0_0_0.String(),
 		StaticHeaders:        map[string]string{},
+		Headers:              map[string]string{},
 		Metadata:             output.NewMetadata(),
 		TLS:                  btls.NewConfig(),
 		SASL:                 sasl.NewConfig(),
@@ -95,20 +108,27 @@ type Kafka struct {
 	tlsConf *tls.Config
 	timeout time.Duration
 
-	addresses []string
-	version   sarama.KafkaVersion
-	conf      KafkaConfig
+	addresses         []string
+	discovery         *discovery.Resolver
+	discoveryInterval time.Duration
+	stopDiscovery     func()
+	version           sarama.KafkaVersion
+	conf              KafkaConfig
 
-	key   *field.Expression
-	topic *field.Expression
+	key       *field.Expression
+	topic     *field.Expression
+	partition *field.Expression
 
 	producer    sarama.SyncProducer
 	compression sarama.CompressionCodec
 	partitioner sarama.PartitionerConstructor
 
 	staticHeaders map[string]string
+	headers       map[string]*field.Expression
 	metaFilter    *output.MetadataFilter
 
+	onThrottle func(time.Duration)
+
 	connMut sync.RWMutex
 }
 
@@ -138,6 +158,7 @@ func NewKafka(conf KafkaConfig, mgr types.Manager, log log.Modular, stats metric
 		compression:   compression,
 		partitioner:   partitioner,
 		staticHeaders: conf.StaticHeaders,
+		headers:       map[string]*field.Expression{},
 	}
 
 	if k.metaFilter, err = conf.Metadata.Filter(); err != nil {
@@ -150,6 +171,21 @@ func NewKafka(conf KafkaConfig, mgr types.Manager, log log.Modular, stats metric
 	if k.topic, err = bloblang.NewField(conf.Topic); err != nil {
 		return nil, fmt.Errorf("failed to parse topic expression: %v", err)
 	}
+	for name, value := range conf.Headers {
+		if k.headers[name], err = bloblang.NewField(value); err != nil {
+			return nil, fmt.Errorf("failed to parse header '%v' expression: %v", name, err)
+		}
+	}
+	if conf.Partitioner == "manual" {
+		if conf.Partition == "" {
+			return nil, errors.New("partition field required for 'manual' partitioner")
+		}
+		if k.partition, err = bloblang.NewField(conf.Partition); err != nil {
+			return nil, fmt.Errorf("failed to parse partition expression: %v", err)
+		}
+	} else if conf.Partition != "" {
+		return nil, errors.New("partition field can only be specified for 'manual' partitioner")
+	}
 	if k.backoffCtor, err = conf.Config.GetCtor(); err != nil {
 		return nil, err
 	}
@@ -168,6 +204,14 @@ func NewKafka(conf KafkaConfig, mgr types.Manager, log log.Modular, stats metric
 		}
 	}
 
+	if conf.ProxyURL != "" {
+		// The dialer is created on each connection attempt in order to apply
+		// the dial timeouts of the client, but the URL is validated up front.
+		if _, err := proxy.NewDialer(conf.ProxyURL, nil); err != nil {
+			return nil, err
+		}
+	}
+
 	if k.version, err = sarama.ParseKafkaVersion(conf.TargetVersion); err != nil {
 		return nil, err
 	}
@@ -179,6 +223,14 @@ func NewKafka(conf KafkaConfig, mgr types.Manager, log log.Modular, stats metric
 			}
 		}
 	}
+	if k.discovery, err = discovery.NewResolver(k.addresses); err != nil {
+		return nil, err
+	}
+	if k.discovery.IsDynamic() {
+		if k.discoveryInterval, err = time.ParseDuration(conf.DiscoveryInterval); err != nil {
+			return nil, fmt.Errorf("failed to parse discovery interval string: %v", err)
+		}
+	}
 
 	return &k, nil
 }
@@ -195,6 +247,8 @@ func strToCompressionCodec(str string) (sarama.CompressionCodec, error) {
 		return sarama.CompressionLZ4, nil
 	case "gzip":
 		return sarama.CompressionGZIP, nil
+	case "zstd":
+		return sarama.CompressionZSTD, nil
 	}
 	return sarama.CompressionNone, fmt.Errorf("compression codec not recognised: %v", str)
 }
@@ -214,6 +268,8 @@ func strToPartitioner(str string) (sarama.PartitionerConstructor, error) {
 		return sarama.NewRandomPartitioner, nil
 	case "round_robin":
 		return sarama.NewRoundRobinPartitioner, nil
+	case "manual":
+		return sarama.NewManualPartitioner, nil
 	default:
 	}
 	return nil, fmt.Errorf("partitioner not recognised: %v", str)
@@ -258,15 +314,24 @@ func (k *Kafka) buildUserDefinedHeaders(staticHeaders map[string]string) []saram
 	return nil
 }
 
+func (k *Kafka) buildInterpolatedHeaders(index int, msg types.Message) []sarama.RecordHeader {
+	if len(k.headers) == 0 || !k.version.IsAtLeast(sarama.V0_11_0_0) {
+		return nil
+	}
+	out := make([]sarama.RecordHeader, 0, len(k.headers))
+	for name, value := range k.headers {
+		out = append(out, sarama.RecordHeader{
+			Key:   []byte(name),
+			Value: value.Bytes(index, msg),
+		})
+	}
+	return out
+}
+
 //------------------------------------------------------------------------------
 
 // ConnectWithContext attempts to establish a connection to a Kafka broker.
 func (k *Kafka) ConnectWithContext(ctx context.Context) error {
-	return k.Connect()
-}
-
-// Connect attempts to establish a connection to a Kafka broker.
-func (k *Kafka) Connect() error {
 	k.connMut.Lock()
 	defer k.connMut.Unlock()
 
@@ -292,6 +357,30 @@ func (k *Kafka) Connect() error {
 	if err := k.conf.SASL.Apply(k.mgr, config); err != nil {
 		return err
 	}
+	var dialer proxy.Dialer = &net.Dialer{
+		Timeout:   config.Net.DialTimeout,
+		KeepAlive: config.Net.KeepAlive,
+	}
+	if k.conf.ProxyURL != "" {
+		var err error
+		if dialer, err = proxy.NewDialer(k.conf.ProxyURL, &net.Dialer{
+			Timeout:   config.Net.DialTimeout,
+			KeepAlive: config.Net.KeepAlive,
+		}); err != nil {
+			return err
+		}
+		config.Net.Proxy.Enable = true
+		config.Net.Proxy.Dialer = dialer
+	}
+	if k.onThrottle != nil {
+		config.Net.Proxy.Enable = true
+		config.Net.Proxy.Dialer = &kafkaThrottleDialer{
+			dialer:     dialer,
+			tlsConf:    config.Net.TLS.Config,
+			onThrottle: k.onThrottle,
+		}
+		config.Net.TLS.Enable = false
+	}
 
 	if k.conf.AckReplicas {
 		config.Producer.RequiredAcks = sarama.WaitForAll
@@ -299,13 +388,67 @@ func (k *Kafka) Connect() error {
 		config.Producer.RequiredAcks = sarama.WaitForLocal
 	}
 
-	var err error
-	k.producer, err = sarama.NewSyncProducer(k.addresses, config)
+	addresses := k.addresses
+	if k.discovery.IsDynamic() {
+		var err error
+		if addresses, err = k.discovery.Resolve(ctx); err != nil {
+			return err
+		}
+	}
+
+	producer, err := sarama.NewSyncProducer(addresses, config)
+	if err != nil {
+		return err
+	}
+	k.producer = producer
+	k.log.Infof("Sending Kafka messages to addresses: %s\n", addresses)
+
+	if k.discovery.IsDynamic() {
+		watchCtx, done := context.WithCancel(context.Background())
+		k.stopDiscovery = done
+		go k.discovery.Watch(watchCtx, k.discoveryInterval, addresses, func(addrs []string) {
+			k.addressesChanged(producer, addrs)
+		}, func(err error) {
+			k.log.Warnf("Failed to refresh Kafka addresses: %v\n", err)
+		})
+	}
+	return nil
+}
+
+// OnThrottle registers a func to be called with the throttle time of each
+// produce response where a broker has throttled writes, which must be called
+// before connecting.
+func (k *Kafka) OnThrottle(fn func(retryAfter time.Duration)) {
+	k.connMut.Lock()
+	k.onThrottle = fn
+	k.connMut.Unlock()
+}
+
+// Connect attempts to establish a connection to a Kafka broker.
+func (k *Kafka) Connect() error {
+	return k.ConnectWithContext(context.Background())
+}
 
-	if err == nil {
-		k.log.Infof("Sending Kafka messages to addresses: %s\n", k.addresses)
+// addressesChanged closes a producer once the addresses it was created with
+// have changed, which results in a reconnect with the new addresses. Closing
+// the producer waits for messages that are already being sent.
+func (k *Kafka) addressesChanged(producer sarama.SyncProducer, addrs []string) {
+	k.connMut.Lock()
+	if k.producer != producer {
+		k.connMut.Unlock()
+		return
+	}
+	k.log.Infof("Kafka addresses have changed to %s, reconnecting\n", addrs)
+	k.producer = nil
+	if k.stopDiscovery != nil {
+		k.stopDiscovery()
+		k.stopDiscovery = nil
+	}
+	k.connMut.Unlock()
+
+	if err := producer.Close(); err != nil {
+		k.log.Errorf("Failed to close Kafka producer: %v\n", err)
 	}
-	return err
 }
 
 // Write will attempt to write a message to Kafka, wait for acknowledgement, and
@@ -329,20 +472,30 @@ func (k *Kafka) WriteWithContext(ctx context.Context, msg types.Message) error {
 
 	userDefinedHeaders := k.buildUserDefinedHeaders(k.staticHeaders)
 	msgs := []*sarama.ProducerMessage{}
-	msg.Iter(func(i int, p types.Part) error {
+	if err := msg.Iter(func(i int, p types.Part) error {
 		key := k.key.Bytes(i, msg)
 		nextMsg := &sarama.ProducerMessage{
 			Topic:    k.topic.String(i, msg),
 			Value:    sarama.ByteEncoder(p.Get()),
-			Headers:  append(k.buildSystemHeaders(p), userDefinedHeaders...),
+			Headers:  append(append(k.buildSystemHeaders(p), userDefinedHeaders...), k.buildInterpolatedHeaders(i, msg)...),
 			Metadata: i, // Store the original index for later reference.
 		}
 		if len(key) > 0 {
 			nextMsg.Key = sarama.ByteEncoder(key)
 		}
+		if k.partition != nil {
+			partitionStr := k.partition.String(i, msg)
+			partition, err := strconv.ParseInt(strings.TrimSpace(partitionStr), 10, 32)
+			if err != nil {
+				return fmt.Errorf("failed to parse partition '%v': %w", partitionStr, err)
+			}
+			nextMsg.Partition = int32(partition)
+		}
 		msgs = append(msgs, nextMsg)
 		return nil
-	})
+	}); err != nil {
+		return err
+	}
 
 	err := producer.SendMessages(msgs)
 	for err != nil {
@@ -399,6 +552,10 @@ func (k *Kafka) WriteWithContext(ctx context.Context, msg types.Message) error {
 func (k *Kafka) CloseAsync() {
 	go func() {
 		k.connMut.Lock()
+		if k.stopDiscovery != nil {
+			k.stopDiscovery()
+			k.stopDiscovery = nil
+		}
 		if k.producer != nil {
 			k.producer.Close()
 			k.producer = nil
@@ -0,0 +1,128 @@
+package writer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSyncProducer struct {
+	msgs   []*sarama.ProducerMessage
+	closed bool
+}
+
+func (f *fakeSyncProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	f.msgs = append(f.msgs, msg)
+	return msg.Partition, 0, nil
+}
+
+func (f *fakeSyncProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	f.msgs = append(f.msgs, msgs...)
+	return nil
+}
+
+func (f *fakeSyncProducer) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestKafkaManualPartitionerConfig(t *testing.T) {
+	conf := NewKafkaConfig()
+	conf.Partitioner = "manual"
+	_, err := NewKafka(conf, types.NoopMgr(), log.Noop(), metrics.Noop())
+	assert.EqualError(t, err, "partition field required for 'manual' partitioner")
+
+	conf = NewKafkaConfig()
+	conf.Partition = "1"
+	_, err = NewKafka(conf, types.NoopMgr(), log.Noop(), metrics.Noop())
+	assert.EqualError(t, err, "partition field can only be specified for 'manual' partitioner")
+}
+
+func TestKafkaManualPartitioner(t *testing.T) {
+	conf := NewKafkaConfig()
+	conf.Partitioner = "manual"
+	conf.Partition = `${! meta("partition") }`
+
+	k, err := NewKafka(conf, types.NoopMgr(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	producer := &fakeSyncProducer{}
+	k.producer = producer
+
+	msg := message.New([][]byte{[]byte("foo"), []byte("bar")})
+	msg.Get(0).Metadata().Set("partition", "3")
+	msg.Get(1).Metadata().Set("partition", "7")
+	require.NoError(t, k.WriteWithContext(context.Background(), msg))
+
+	require.Len(t, producer.msgs, 2)
+	assert.Equal(t, int32(3), producer.msgs[0].Partition)
+	assert.Equal(t, int32(7), producer.msgs[1].Partition)
+
+	msg = message.New([][]byte{[]byte("baz")})
+	msg.Get(0).Metadata().Set("partition", "nope")
+	assert.Error(t, k.WriteWithContext(context.Background(), msg))
+	assert.Len(t, producer.msgs, 2)
+}
+
+func TestKafkaHeaders(t *testing.T) {
+	conf := NewKafkaConfig()
+	conf.Metadata.ExcludePrefixes = []string{"tenant"}
+	conf.StaticHeaders = map[string]string{"source": "benthos"}
+	conf.Headers = map[string]string{"route": `${! meta("tenant") }-${! content() }`}
+
+	k, err := NewKafka(conf, types.NoopMgr(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	producer := &fakeSyncProducer{}
+	k.producer = producer
+
+	msg := message.New([][]byte{[]byte("foo"), []byte("bar")})
+	msg.Get(0).Metadata().Set("tenant", "a")
+	msg.Get(1).Metadata().Set("tenant", "b")
+	require.NoError(t, k.WriteWithContext(context.Background(), msg))
+
+	require.Len(t, producer.msgs, 2)
+	for i, exp := range []string{"a-foo", "b-bar"} {
+		headers := map[string]string{}
+		for _, h := range producer.msgs[i].Headers {
+			headers[string(h.Key)] = string(h.Value)
+		}
+		assert.Equal(t, map[string]string{"source": "benthos", "route": exp}, headers)
+	}
+
+	conf.Headers = map[string]string{"route": `${! meta( }`}
+	_, err = NewKafka(conf, types.NoopMgr(), log.Noop(), metrics.Noop())
+	assert.Error(t, err)
+}
+
+func TestKafkaAddressesChanged(t *testing.T) {
+	k, err := NewKafka(NewKafkaConfig(), types.NoopMgr(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	stale := &fakeSyncProducer{}
+	producer := &fakeSyncProducer{}
+	k.producer = producer
+
+	var stopped bool
+	k.stopDiscovery = func() { stopped = true }
+
+	// Changes observed by a previous producer are ignored.
+	k.addressesChanged(stale, []string{"foo:9092"})
+	assert.False(t, stale.closed)
+	assert.False(t, stopped)
+	assert.Equal(t, producer, k.producer)
+
+	k.addressesChanged(producer, []string{"foo:9092"})
+	assert.True(t, producer.closed)
+	assert.True(t, stopped)
+	assert.Nil(t, k.producer)
+
+	assert.Equal(t, types.ErrNotConnected, k.WriteWithContext(context.Background(), message.New([][]byte{[]byte("foo")})))
+}
@@ -0,0 +1,176 @@
+package writer
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/util/proxy"
+)
+
+//------------------------------------------------------------------------------
+
+// kafkaFrames incrementally splits one direction of a Kafka protocol stream
+// into its size delimited frames, capturing the first and last bytes of each.
+type kafkaFrames struct {
+	size  [4]byte
+	sizeN int
+
+	remaining int32
+	head      []byte
+	tail      []byte
+
+	onFrame func(head, tail []byte)
+}
+
+func (f *kafkaFrames) feed(b []byte) {
+	for len(b) > 0 {
+		if f.sizeN < 4 {
+			n := copy(f.size[f.sizeN:], b)
+			f.sizeN += n
+			b = b[n:]
+			if f.sizeN == 4 {
+				f.remaining = int32(binary.BigEndian.Uint32(f.size[:]))
+				f.head, f.tail = f.head[:0], f.tail[:0]
+				if f.remaining <= 0 {
+					f.sizeN = 0
+				}
+			}
+			continue
+		}
+
+		n := len(b)
+		if n > int(f.remaining) {
+			n = int(f.remaining)
+		}
+		chunk := b[:n]
+		b = b[n:]
+		f.remaining -= int32(n)
+
+		if missing := 8 - len(f.head); missing > 0 {
+			if missing > n {
+				missing = n
+			}
+			f.head = append(f.head, chunk[:missing]...)
+		}
+		if n >= 4 {
+			f.tail = append(f.tail[:0], chunk[n-4:]...)
+		} else if f.tail = append(f.tail, chunk...); len(f.tail) > 4 {
+			f.tail = append(f.tail[:0], f.tail[len(f.tail)-4:]...)
+		}
+
+		if f.remaining == 0 {
+			f.sizeN = 0
+			f.onFrame(f.head, f.tail)
+		}
+	}
+}
+
+//------------------------------------------------------------------------------
+
+const kafkaProduceAPIKey = 0
+
+// kafkaThrottleConn observes the requests written to and the responses read
+// from a Kafka broker connection, and reports the throttle time of each produce
+// response, which the sarama producer otherwise discards.
+//
+// Produce requests from version 1 up to the last version without tagged fields
+// (8) end their responses with the throttle time in milliseconds, and the
+// requests are matched to responses by correlation ID. Frames that aren't
+// Kafka requests, such as the raw tokens of a SASL handshake, are size
+// delimited the same way and therefore don't disrupt the framing.
+type kafkaThrottleConn struct {
+	net.Conn
+
+	reqs kafkaFrames
+	ress kafkaFrames
+
+	mut        sync.Mutex
+	produceIDs map[int32]struct{}
+	onThrottle func(time.Duration)
+}
+
+func newKafkaThrottleConn(conn net.Conn, onThrottle func(time.Duration)) *kafkaThrottleConn {
+	c := &kafkaThrottleConn{
+		Conn:       conn,
+		produceIDs: map[int32]struct{}{},
+		onThrottle: onThrottle,
+	}
+	c.reqs.onFrame = c.request
+	c.ress.onFrame = c.response
+	return c
+}
+
+func (c *kafkaThrottleConn) request(head, _ []byte) {
+	if len(head) < 8 {
+		return
+	}
+	apiKey := int16(binary.BigEndian.Uint16(head[0:2]))
+	apiVersion := int16(binary.BigEndian.Uint16(head[2:4]))
+	if apiKey != kafkaProduceAPIKey || apiVersion < 1 || apiVersion > 8 {
+		return
+	}
+	c.mut.Lock()
+	c.produceIDs[int32(binary.BigEndian.Uint32(head[4:8]))] = struct{}{}
+	c.mut.Unlock()
+}
+
+func (c *kafkaThrottleConn) response(head, tail []byte) {
+	if len(head) < 4 || len(tail) < 4 {
+		return
+	}
+	correlationID := int32(binary.BigEndian.Uint32(head[0:4]))
+	c.mut.Lock()
+	_, isProduce := c.produceIDs[correlationID]
+	delete(c.produceIDs, correlationID)
+	c.mut.Unlock()
+	if !isProduce {
+		return
+	}
+	if ms := int32(binary.BigEndian.Uint32(tail)); ms > 0 {
+		c.onThrottle(time.Duration(ms) * time.Millisecond)
+	}
+}
+
+func (c *kafkaThrottleConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.reqs.feed(b[:n])
+	return n, err
+}
+
+func (c *kafkaThrottleConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.ress.feed(b[:n])
+	return n, err
+}
+
+//------------------------------------------------------------------------------
+
+// kafkaThrottleDialer dials Kafka broker connections that report the throttle
+// time of produce responses. Since the responses must be observed in plain
+// text the dialer also takes over establishing TLS from sarama.
+type kafkaThrottleDialer struct {
+	dialer     proxy.Dialer
+	tlsConf    *tls.Config
+	onThrottle func(time.Duration)
+}
+
+func (d *kafkaThrottleDialer) Dial(network, addr string) (net.Conn, error) {
+	conn, err := d.dialer.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	if d.tlsConf != nil {
+		tlsConf := d.tlsConf
+		if tlsConf.ServerName == "" {
+			if host, _, err := net.SplitHostPort(addr); err == nil {
+				tlsConf = tlsConf.Clone()
+				tlsConf.ServerName = host
+			}
+		}
+		conn = tls.Client(conn, tlsConf)
+	}
+	return newKafkaThrottleConn(conn, d.onThrottle), nil
+}
@@ -0,0 +1,118 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type kafkaStubConn struct {
+	net.Conn
+	r io.Reader
+	w bytes.Buffer
+}
+
+func (c *kafkaStubConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+func (c *kafkaStubConn) Write(b []byte) (int, error) {
+	return c.w.Write(b)
+}
+
+func kafkaFrame(fields ...interface{}) []byte {
+	var body bytes.Buffer
+	for _, f := range fields {
+		_ = binary.Write(&body, binary.BigEndian, f)
+	}
+	frame := make([]byte, 4, 4+body.Len())
+	binary.BigEndian.PutUint32(frame, uint32(body.Len()))
+	return append(frame, body.Bytes()...)
+}
+
+func TestKafkaThrottleConn(t *testing.T) {
+	var responses []byte
+	// Metadata response, which must be ignored.
+	responses = append(responses, kafkaFrame(int32(1), []byte("foo"), int32(500))...)
+	// Raw SASL token, which isn't a response to any request.
+	responses = append(responses, kafkaFrame([]byte("ok"))...)
+	// Produce response without throttling.
+	responses = append(responses, kafkaFrame(int32(2), []byte("bar"), int32(0))...)
+	// Produce response of a request that was throttled.
+	responses = append(responses, kafkaFrame(int32(3), []byte("baz"), int32(250))...)
+
+	stub := &kafkaStubConn{r: iotest.OneByteReader(bytes.NewReader(responses))}
+
+	var throttled []time.Duration
+	conn := newKafkaThrottleConn(stub, func(d time.Duration) {
+		throttled = append(throttled, d)
+	})
+
+	// Metadata request.
+	_, err := conn.Write(kafkaFrame(int16(3), int16(5), int32(1), []byte("client")))
+	require.NoError(t, err)
+	// Produce requests, one of which is written in fragments.
+	_, err = conn.Write(kafkaFrame(int16(0), int16(7), int32(2), []byte("client")))
+	require.NoError(t, err)
+	req := kafkaFrame(int16(0), int16(7), int32(3), []byte("client"))
+	_, err = conn.Write(req[:6])
+	require.NoError(t, err)
+	_, err = conn.Write(req[6:])
+	require.NoError(t, err)
+
+	_, err = io.Copy(io.Discard, conn)
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Duration{time.Millisecond * 250}, throttled)
+	assert.Empty(t, conn.produceIDs)
+}
+
+func TestKafkaThrottleDialer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		req := make([]byte, len(kafkaFrame(int16(0), int16(3), int32(7))))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+		_, _ = conn.Write(kafkaFrame(int32(7), []byte("foo"), int32(100)))
+	}()
+
+	throttled := make(chan time.Duration, 1)
+	dialer := &kafkaThrottleDialer{
+		dialer: &net.Dialer{},
+		onThrottle: func(d time.Duration) {
+			throttled <- d
+		},
+	}
+
+	conn, err := dialer.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write(kafkaFrame(int16(0), int16(3), int32(7)))
+	require.NoError(t, err)
+	_, err = io.Copy(io.Discard, conn)
+	require.NoError(t, err)
+
+	select {
+	case d := <-throttled:
+		assert.Equal(t, time.Millisecond*100, d)
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for throttle time")
+	}
+}
@@ -20,6 +20,13 @@ import (
 
 //------------------------------------------------------------------------------
 
+const (
+	firehoseMaxRecordBytes = 1000 * 1024
+	firehoseMaxBatchBytes  = 4 * mebibyte
+)
+
+//------------------------------------------------------------------------------
+
 // KinesisFirehoseConfig contains configuration fields for the KinesisFirehose output type.
 type KinesisFirehoseConfig struct {
 	sessionConfig  `json:",inline" yaml:",inline"`
@@ -106,8 +113,8 @@ func (a *KinesisFirehose) toRecords(msg types.Message) ([]*firehose.Record, erro
 			Data: p.Get(),
 		}
 
-		if len(entry.Data) > mebibyte {
-			a.log.Errorf("part %d exceeds the maximum Kinesis Firehose payload limit of 1 MiB\n", i)
+		if len(entry.Data) > firehoseMaxRecordBytes {
+			a.log.Errorf("part %d exceeds the maximum Kinesis Firehose record size limit of 1000 KiB\n", i)
 			return types.ErrMessageTooLarge
 		}
 
@@ -118,6 +125,24 @@ func (a *KinesisFirehose) toRecords(msg types.Message) ([]*firehose.Record, erro
 	return entries, err
 }
 
+// fillFirehoseBatch appends pending records to a batch until either the maximum
+// record count or the maximum request size of a PutRecordBatch call would be
+// exceeded, returning the new batch and the remaining pending records.
+func fillFirehoseBatch(batch, pending []*firehose.Record) ([]*firehose.Record, []*firehose.Record) {
+	size := 0
+	for _, r := range batch {
+		size += len(r.Data)
+	}
+	for len(pending) > 0 && len(batch) < kinesisMaxRecordsCount {
+		if size+len(pending[0].Data) > firehoseMaxBatchBytes {
+			break
+		}
+		size += len(pending[0].Data)
+		batch, pending = append(batch, pending[0]), pending[1:]
+	}
+	return batch, pending
+}
+
 //------------------------------------------------------------------------------
 
 // ConnectWithContext creates a new Kinesis Firehose client and ensures that the
@@ -152,15 +177,16 @@ func (a *KinesisFirehose) Connect() error {
 }
 
 // Write attempts to write message contents to a target Kinesis Firehose delivery
-// stream in batches of 500. If throttling is detected, failed messages are retried
-// according to the configurable backoff settings.
+// stream in batches of up to 500 records and 4 MiB. If throttling is detected,
+// failed messages are retried according to the configurable backoff settings.
 func (a *KinesisFirehose) Write(msg types.Message) error {
 	return a.WriteWithContext(context.Background(), msg)
 }
 
 // WriteWithContext attempts to write message contents to a target Kinesis
-// Firehose delivery stream in batches of 500. If throttling is detected, failed
-// messages are retried according to the configurable backoff settings.
+// Firehose delivery stream in batches of up to 500 records and 4 MiB. If
+// throttling is detected, failed messages are retried according to the
+// configurable backoff settings.
 func (a *KinesisFirehose) WriteWithContext(ctx context.Context, msg types.Message) error {
 	if a.session == nil {
 		return types.ErrNotConnected
@@ -174,16 +200,11 @@ func (a *KinesisFirehose) WriteWithContext(ctx context.Context, msg types.Messag
 	}
 
 	input := &firehose.PutRecordBatchInput{
-		Records:            records,
 		DeliveryStreamName: a.streamName,
 	}
 
-	// trim input record length to max kinesis firehose batch size
-	if len(records) > kinesisMaxRecordsCount {
-		input.Records, records = records[:kinesisMaxRecordsCount], records[kinesisMaxRecordsCount:]
-	} else {
-		records = nil
-	}
+	// trim input records to the max kinesis firehose batch count and size
+	input.Records, records = fillFirehoseBatch(nil, records)
 
 	var failed []*firehose.Record
 	for len(input.Records) > 0 {
@@ -229,13 +250,7 @@ func (a *KinesisFirehose) WriteWithContext(ctx context.Context, msg types.Messag
 		}
 
 		// add remaining records to batch
-		if n := len(records); n > 0 && l < kinesisMaxRecordsCount {
-			if remaining := kinesisMaxRecordsCount - l; remaining < n {
-				input.Records, records = append(input.Records, records[:remaining]...), records[remaining:]
-			} else {
-				input.Records, records = append(input.Records, records...), nil
-			}
-		}
+		input.Records, records = fillFirehoseBatch(input.Records, records)
 	}
 	return err
 }
@@ -3,10 +3,12 @@ package writer
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/types"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -139,6 +141,43 @@ func TestKinesisFirehoseWriteChunk(t *testing.T) {
 	}
 }
 
+func TestKinesisFirehoseWriteChunkBySize(t *testing.T) {
+	batchLengths := []int{}
+	k := KinesisFirehose{
+		backoffCtor: func() backoff.BackOff {
+			return backoff.NewExponentialBackOff()
+		},
+		session: session.Must(session.NewSession(&aws.Config{
+			Credentials: credentials.NewStaticCredentials("xxxxx", "xxxxx", "xxxxx"),
+		})),
+		firehose: &mockKinesisFirehose{
+			fn: func(input *firehose.PutRecordBatchInput) (*firehose.PutRecordBatchOutput, error) {
+				batchLengths = append(batchLengths, len(input.Records))
+				return &firehose.PutRecordBatchOutput{}, nil
+			},
+		},
+		log: log.Noop(),
+	}
+
+	// Each record is 900 KiB, meaning only four fit within a 4 MiB request.
+	msg := message.New(nil)
+	for i := 0; i < 10; i++ {
+		msg.Append(message.NewPart(make([]byte, 900*1024)))
+	}
+
+	if err := k.Write(msg); err != nil {
+		t.Fatal(err)
+	}
+	if exp, act := []int{4, 4, 2}, batchLengths; !reflect.DeepEqual(exp, act) {
+		t.Errorf("Expected kinesis firehose PutRecordBatch batch sizes %v, got %v", exp, act)
+	}
+
+	msg = message.New([][]byte{make([]byte, firehoseMaxRecordBytes+1)})
+	if err := k.Write(msg); err != types.ErrMessageTooLarge {
+		t.Errorf("Expected message too large error, got %v", err)
+	}
+}
+
 func TestKinesisFirehoseWriteChunkWithThrottling(t *testing.T) {
 	t.Parallel()
 	batchLengths := []int{}
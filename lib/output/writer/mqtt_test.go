@@ -0,0 +1,74 @@
+package writer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMQTTToken is a token that has already completed.
+type fakeMQTTToken struct{}
+
+func (fakeMQTTToken) Wait() bool                     { return true }
+func (fakeMQTTToken) WaitTimeout(time.Duration) bool { return true }
+func (fakeMQTTToken) Error() error                   { return nil }
+
+func (fakeMQTTToken) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+type fakeMQTTPublish struct {
+	topic    string
+	qos      byte
+	retained bool
+	payload  string
+}
+
+// fakeMQTTClient records publishes rather than sending them to an MQTT broker.
+type fakeMQTTClient struct {
+	mqtt.Client
+	publishes []fakeMQTTPublish
+}
+
+func (f *fakeMQTTClient) Publish(topic string, qos byte, retained bool, payload interface{}) mqtt.Token {
+	f.publishes = append(f.publishes, fakeMQTTPublish{
+		topic:    topic,
+		qos:      qos,
+		retained: retained,
+		payload:  string(payload.([]byte)),
+	})
+	return fakeMQTTToken{}
+}
+
+func TestMQTTRetained(t *testing.T) {
+	for _, retained := range []bool{false, true} {
+		conf := NewMQTTConfig()
+		conf.Topic = `${! meta("topic") }`
+		conf.QoS = 2
+		conf.Retained = retained
+
+		m, err := NewMQTT(conf, log.Noop(), metrics.Noop())
+		require.NoError(t, err)
+
+		client := &fakeMQTTClient{}
+		m.client = client
+
+		msg := message.New([][]byte{[]byte("foo"), []byte("bar")})
+		msg.Get(0).Metadata().Set("topic", "a")
+		msg.Get(1).Metadata().Set("topic", "b")
+		require.NoError(t, m.Write(msg))
+
+		assert.Equal(t, []fakeMQTTPublish{
+			{topic: "a", qos: 2, retained: retained, payload: "foo"},
+			{topic: "b", qos: 2, retained: retained, payload: "bar"},
+		}, client.publishes)
+	}
+}
@@ -22,21 +22,23 @@ import (
 
 // NanomsgConfig contains configuration fields for the Nanomsg output type.
 type NanomsgConfig struct {
-	URLs        []string `json:"urls" yaml:"urls"`
-	Bind        bool     `json:"bind" yaml:"bind"`
-	SocketType  string   `json:"socket_type" yaml:"socket_type"`
-	PollTimeout string   `json:"poll_timeout" yaml:"poll_timeout"`
-	MaxInFlight int      `json:"max_in_flight" yaml:"max_in_flight"`
+	URLs          []string `json:"urls" yaml:"urls"`
+	Bind          bool     `json:"bind" yaml:"bind"`
+	SocketType    string   `json:"socket_type" yaml:"socket_type"`
+	PollTimeout   string   `json:"poll_timeout" yaml:"poll_timeout"`
+	MaxInFlight   int      `json:"max_in_flight" yaml:"max_in_flight"`
+	FireAndForget bool     `json:"fire_and_forget" yaml:"fire_and_forget"`
 }
 
 // NewNanomsgConfig creates a new NanomsgConfig with default values.
 func NewNanomsgConfig() NanomsgConfig {
 	return NanomsgConfig{
-		URLs:        []string{"tcp://localhost:5556"},
-		Bind:        false,
-		SocketType:  "PUSH",
-		PollTimeout: "5s",
-		MaxInFlight: 1,
+		URLs:          []string{"tcp://localhost:5556"},
+		Bind:          false,
+		SocketType:    "PUSH",
+		PollTimeout:   "5s",
+		MaxInFlight:   1,
+		FireAndForget: false,
 	}
 }
 
@@ -4,10 +4,12 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/Jeffail/benthos/v3/lib/util/proxy"
 	btls "github.com/Jeffail/benthos/v3/lib/util/tls"
 
 	"github.com/Jeffail/benthos/v3/internal/bloblang"
@@ -25,6 +27,7 @@ type NATSConfig struct {
 	URLs        []string    `json:"urls" yaml:"urls"`
 	Subject     string      `json:"subject" yaml:"subject"`
 	MaxInFlight int         `json:"max_in_flight" yaml:"max_in_flight"`
+	ProxyURL    string      `json:"proxy_url" yaml:"proxy_url"`
 	TLS         btls.Config `json:"tls" yaml:"tls"`
 }
 
@@ -34,6 +37,7 @@ func NewNATSConfig() NATSConfig {
 		URLs:        []string{nats.DefaultURL},
 		Subject:     "benthos_messages",
 		MaxInFlight: 1,
+		ProxyURL:    "",
 		TLS:         btls.NewConfig(),
 	}
 }
@@ -51,6 +55,7 @@ type NATS struct {
 	conf       NATSConfig
 	subjectStr *field.Expression
 	tlsConf    *tls.Config
+	dialer     proxy.Dialer
 }
 
 // NewNATS creates a new NATS output type.
@@ -65,6 +70,14 @@ func NewNATS(conf NATSConfig, log log.Modular, stats metrics.Type) (*NATS, error
 	}
 	n.urls = strings.Join(conf.URLs, ",")
 
+	if conf.ProxyURL != "" {
+		if n.dialer, err = proxy.NewDialer(conf.ProxyURL, &net.Dialer{
+			Timeout: nats.GetDefaultOptions().Timeout,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
 	if conf.TLS.Enabled {
 		if n.tlsConf, err = conf.TLS.Get(); err != nil {
 			return nil, err
@@ -96,6 +109,9 @@ func (n *NATS) Connect() error {
 	if n.tlsConf != nil {
 		opts = append(opts, nats.Secure(n.tlsConf))
 	}
+	if n.dialer != nil {
+		opts = append(opts, nats.SetCustomDialer(n.dialer))
+	}
 
 	if n.natsConn, err = nats.Connect(n.urls, opts...); err != nil {
 		return err
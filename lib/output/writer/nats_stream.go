@@ -12,6 +12,8 @@ import (
 	btls "github.com/Jeffail/benthos/v3/lib/util/tls"
 	"github.com/nats-io/nats.go"
 
+	"github.com/Jeffail/benthos/v3/internal/bloblang"
+	"github.com/Jeffail/benthos/v3/internal/bloblang/field"
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/types"
@@ -55,6 +57,7 @@ type NATSStream struct {
 
 	urls    string
 	conf    NATSStreamConfig
+	subject *field.Expression
 	tlsConf *tls.Config
 }
 
@@ -75,6 +78,9 @@ func NewNATSStream(conf NATSStreamConfig, log log.Modular, stats metrics.Type) (
 	}
 	n.urls = strings.Join(conf.URLs, ",")
 	var err error
+	if n.subject, err = bloblang.NewField(conf.Subject); err != nil {
+		return nil, fmt.Errorf("failed to parse subject expression: %v", err)
+	}
 	if conf.TLS.Enabled {
 		if n.tlsConf, err = conf.TLS.Get(); err != nil {
 			return nil, err
@@ -142,7 +148,7 @@ func (n *NATSStream) Write(msg types.Message) error {
 	}
 
 	return IterateBatchedSend(msg, func(i int, p types.Part) error {
-		err := conn.Publish(n.conf.Subject, p.Get())
+		err := conn.Publish(n.subject.String(i, msg), p.Get())
 		if err == stan.ErrConnectionClosed {
 			conn.Close()
 			n.connMut.Lock()
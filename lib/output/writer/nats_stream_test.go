@@ -0,0 +1,50 @@
+package writer
+
+import (
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/nats-io/stan.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type natsStreamPublish struct {
+	subject string
+	data    string
+}
+
+// fakeStanConn records publishes rather than sending them to a NATS Streaming
+// server.
+type fakeStanConn struct {
+	stan.Conn
+	published []natsStreamPublish
+}
+
+func (f *fakeStanConn) Publish(subject string, data []byte) error {
+	f.published = append(f.published, natsStreamPublish{subject: subject, data: string(data)})
+	return nil
+}
+
+func TestNATSStreamInterpolatedSubject(t *testing.T) {
+	conf := NewNATSStreamConfig()
+	conf.Subject = `${! meta("subject") }`
+
+	w, err := NewNATSStream(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	conn := &fakeStanConn{}
+	w.stanConn = conn
+
+	msg := message.New([][]byte{[]byte("foo"), []byte("bar")})
+	msg.Get(0).Metadata().Set("subject", "subject_a")
+	msg.Get(1).Metadata().Set("subject", "subject_b")
+	require.NoError(t, w.Write(msg))
+
+	assert.Equal(t, []natsStreamPublish{
+		{subject: "subject_a", data: "foo"},
+		{subject: "subject_b", data: "bar"},
+	}, conn.published)
+}
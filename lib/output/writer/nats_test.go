@@ -0,0 +1,174 @@
+package writer
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runNATSTestServer(t *testing.T) string {
+	t.Helper()
+
+	s, err := server.NewServer(&server.Options{
+		Host:   "127.0.0.1",
+		Port:   server.RANDOM_PORT,
+		NoLog:  true,
+		NoSigs: true,
+	})
+	require.NoError(t, err)
+
+	go s.Start()
+	t.Cleanup(s.Shutdown)
+
+	require.True(t, s.ReadyForConnections(time.Second*5))
+	return s.ClientURL()
+}
+
+func TestNATSReplySubject(t *testing.T) {
+	url := runNATSTestServer(t)
+
+	nc, err := nats.Connect(url)
+	require.NoError(t, err)
+	defer nc.Close()
+
+	inbox := nats.NewInbox()
+	sub, err := nc.SubscribeSync(inbox)
+	require.NoError(t, err)
+	require.NoError(t, nc.Flush())
+
+	conf := NewNATSConfig()
+	conf.URLs = []string{url}
+	conf.Subject = `${! meta("nats_reply_subject") }`
+
+	w, err := NewNATS(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+	require.NoError(t, w.Connect())
+	defer func() {
+		w.CloseAsync()
+		require.NoError(t, w.WaitForClose(time.Second))
+	}()
+
+	msg := message.New([][]byte{[]byte("hello world")})
+	msg.Get(0).Metadata().Set("nats_reply_subject", inbox)
+	require.NoError(t, w.Write(msg))
+
+	reply, err := sub.NextMsg(time.Second * 5)
+	require.NoError(t, err)
+	assert.Equal(t, inbox, reply.Subject)
+	assert.Equal(t, "hello world", string(reply.Data))
+}
+
+// runConnectProxy runs an HTTP proxy that tunnels CONNECT requests to their
+// target, and returns its address along with a func listing the targets that
+// have been requested.
+func runConnectProxy(t *testing.T) (string, func() []string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ln.Close()
+	})
+
+	var targetsMut sync.Mutex
+	var targets []string
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+
+				br := bufio.NewReader(conn)
+				req, err := http.ReadRequest(br)
+				if err != nil || req.Method != http.MethodConnect {
+					return
+				}
+
+				targetsMut.Lock()
+				targets = append(targets, req.Host)
+				targetsMut.Unlock()
+
+				target, err := net.Dial("tcp", req.Host)
+				if err != nil {
+					_, _ = conn.Write([]byte("HTTP/1.1 502 Bad Gateway\r\n\r\n"))
+					return
+				}
+				defer target.Close()
+
+				if _, err = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+					return
+				}
+				go func() {
+					_, _ = io.Copy(target, br)
+					target.Close()
+				}()
+				_, _ = io.Copy(conn, target)
+			}()
+		}
+	}()
+
+	return ln.Addr().String(), func() []string {
+		targetsMut.Lock()
+		defer targetsMut.Unlock()
+		return append([]string(nil), targets...)
+	}
+}
+
+func TestNATSProxy(t *testing.T) {
+	url := runNATSTestServer(t)
+	proxyAddr, proxyTargets := runConnectProxy(t)
+
+	nc, err := nats.Connect(url)
+	require.NoError(t, err)
+	defer nc.Close()
+
+	sub, err := nc.SubscribeSync("foo")
+	require.NoError(t, err)
+	require.NoError(t, nc.Flush())
+
+	conf := NewNATSConfig()
+	conf.URLs = []string{url}
+	conf.Subject = "foo"
+	conf.ProxyURL = "http://" + proxyAddr
+
+	w, err := NewNATS(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+	require.NoError(t, w.Connect())
+	defer func() {
+		w.CloseAsync()
+		require.NoError(t, w.WaitForClose(time.Second))
+	}()
+
+	require.NoError(t, w.Write(message.New([][]byte{[]byte("hello world")})))
+
+	msg, err := sub.NextMsg(time.Second * 5)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(msg.Data))
+
+	assert.Equal(t, []string{strings.TrimPrefix(url, "nats://")}, proxyTargets())
+}
+
+func TestNATSBadProxy(t *testing.T) {
+	conf := NewNATSConfig()
+	conf.ProxyURL = "ftp://localhost:21"
+
+	_, err := NewNATS(conf, log.Noop(), metrics.Noop())
+	require.Error(t, err)
+}
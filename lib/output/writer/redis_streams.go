@@ -7,6 +7,8 @@ import (
 	"time"
 
 	ibatch "github.com/Jeffail/benthos/v3/internal/batch"
+	"github.com/Jeffail/benthos/v3/internal/bloblang"
+	"github.com/Jeffail/benthos/v3/internal/bloblang/field"
 	"github.com/Jeffail/benthos/v3/internal/component/output"
 	bredis "github.com/Jeffail/benthos/v3/internal/impl/redis"
 	"github.com/Jeffail/benthos/v3/lib/log"
@@ -50,6 +52,7 @@ type RedisStreams struct {
 	stats metrics.Type
 
 	conf       RedisStreamsConfig
+	stream     *field.Expression
 	metaFilter *output.MetadataFilter
 
 	client  redis.UniversalClient
@@ -70,6 +73,9 @@ func NewRedisStreams(
 	}
 
 	var err error
+	if r.stream, err = bloblang.NewField(conf.Stream); err != nil {
+		return nil, fmt.Errorf("failed to parse stream expression: %v", err)
+	}
 	if r.metaFilter, err = conf.Metadata.Filter(); err != nil {
 		return nil, fmt.Errorf("failed to construct metadata filter: %w", err)
 	}
@@ -136,7 +142,7 @@ func (r *RedisStreams) Write(msg types.Message) error {
 	if msg.Len() == 1 {
 		if err := client.XAdd(&redis.XAddArgs{
 			ID:           "*",
-			Stream:       r.conf.Stream,
+			Stream:       r.stream.String(0, msg),
 			MaxLenApprox: r.conf.MaxLenApprox,
 			Values:       partToMap(msg.Get(0)),
 		}).Err(); err != nil {
@@ -151,7 +157,7 @@ func (r *RedisStreams) Write(msg types.Message) error {
 	msg.Iter(func(i int, p types.Part) error {
 		_ = pipe.XAdd(&redis.XAddArgs{
 			ID:           "*",
-			Stream:       r.conf.Stream,
+			Stream:       r.stream.String(i, msg),
 			MaxLenApprox: r.conf.MaxLenApprox,
 			Values:       partToMap(p),
 		})
@@ -0,0 +1,78 @@
+package writer
+
+import (
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/go-redis/redis/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedisClient records stream additions rather than sending them to a Redis
+// server.
+type fakeRedisClient struct {
+	redis.UniversalClient
+	streamAdds []*redis.XAddArgs
+}
+
+func (f *fakeRedisClient) XAdd(a *redis.XAddArgs) *redis.StringCmd {
+	f.streamAdds = append(f.streamAdds, a)
+	return redis.NewStringResult("0-1", nil)
+}
+
+func (f *fakeRedisClient) Pipeline() redis.Pipeliner {
+	return &fakeRedisPipeline{client: f}
+}
+
+func (f *fakeRedisClient) Close() error {
+	return nil
+}
+
+// fakeRedisPipeline forwards commands to a fakeRedisClient.
+type fakeRedisPipeline struct {
+	redis.Pipeliner
+	client *fakeRedisClient
+	cmds   []redis.Cmder
+}
+
+func (f *fakeRedisPipeline) XAdd(a *redis.XAddArgs) *redis.StringCmd {
+	cmd := f.client.XAdd(a)
+	f.cmds = append(f.cmds, cmd)
+	return cmd
+}
+
+func (f *fakeRedisPipeline) Exec() ([]redis.Cmder, error) {
+	return f.cmds, nil
+}
+
+func TestRedisStreamsInterpolatedStream(t *testing.T) {
+	conf := NewRedisStreamsConfig()
+	conf.Stream = `${! meta("stream") }`
+
+	w, err := NewRedisStreams(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	client := &fakeRedisClient{}
+	w.client = client
+
+	msg := message.New([][]byte{[]byte("foo"), []byte("bar")})
+	msg.Get(0).Metadata().Set("stream", "stream_a")
+	msg.Get(1).Metadata().Set("stream", "stream_b")
+	require.NoError(t, w.Write(msg))
+
+	single := message.New([][]byte{[]byte("baz")})
+	single.Get(0).Metadata().Set("stream", "stream_c")
+	require.NoError(t, w.Write(single))
+
+	var streams []string
+	var bodies []string
+	for _, a := range client.streamAdds {
+		streams = append(streams, a.Stream)
+		bodies = append(bodies, string(a.Values["body"].([]byte)))
+	}
+	assert.Equal(t, []string{"stream_a", "stream_b", "stream_c"}, streams)
+	assert.Equal(t, []string{"foo", "bar", "baz"}, bodies)
+}
@@ -3,6 +3,7 @@ package writer
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"sort"
@@ -37,6 +38,8 @@ type AmazonS3Config struct {
 	StorageClass       string             `json:"storage_class" yaml:"storage_class"`
 	Timeout            string             `json:"timeout" yaml:"timeout"`
 	KMSKeyID           string             `json:"kms_key_id" yaml:"kms_key_id"`
+	UploadPartSize     int64              `json:"upload_part_size" yaml:"upload_part_size"`
+	UploadConcurrency  int                `json:"upload_concurrency" yaml:"upload_concurrency"`
 	MaxInFlight        int                `json:"max_in_flight" yaml:"max_in_flight"`
 	Batching           batch.PolicyConfig `json:"batching" yaml:"batching"`
 }
@@ -55,6 +58,8 @@ func NewAmazonS3Config() AmazonS3Config {
 		StorageClass:       "STANDARD",
 		Timeout:            "5s",
 		KMSKeyID:           "",
+		UploadPartSize:     s3manager.DefaultUploadPartSize,
+		UploadConcurrency:  s3manager.DefaultUploadConcurrency,
 		MaxInFlight:        1,
 		Batching:           batch.NewPolicyConfig(),
 	}
@@ -100,6 +105,12 @@ func NewAmazonS3(
 			return nil, fmt.Errorf("failed to parse timeout period string: %v", err)
 		}
 	}
+	if conf.UploadPartSize < s3manager.MinUploadPartSize {
+		return nil, fmt.Errorf("upload part size must be at least %v bytes", s3manager.MinUploadPartSize)
+	}
+	if conf.UploadConcurrency < 1 {
+		return nil, errors.New("upload concurrency must be greater than zero")
+	}
 	a := &AmazonS3{
 		conf:    conf,
 		log:     log,
@@ -161,7 +172,10 @@ func (a *AmazonS3) Connect() error {
 	}
 
 	a.session = sess
-	a.uploader = s3manager.NewUploader(sess)
+	a.uploader = s3manager.NewUploader(sess, func(u *s3manager.Uploader) {
+		u.PartSize = a.conf.UploadPartSize
+		u.Concurrency = a.conf.UploadConcurrency
+	})
 
 	a.log.Infof("Uploading message parts as objects to Amazon S3 bucket: %v\n", a.conf.Bucket)
 	return nil
@@ -0,0 +1,33 @@
+package writer
+
+import (
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmazonS3UploadConfig(t *testing.T) {
+	conf := NewAmazonS3Config()
+	conf.UploadPartSize = 10 * 1024 * 1024
+	conf.UploadConcurrency = 2
+
+	a, err := NewAmazonS3(conf, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+	require.NoError(t, a.Connect())
+
+	assert.Equal(t, int64(10*1024*1024), a.uploader.PartSize)
+	assert.Equal(t, 2, a.uploader.Concurrency)
+
+	for name, fn := range map[string]func(c *AmazonS3Config){
+		"part size too small": func(c *AmazonS3Config) { c.UploadPartSize = 1024 },
+		"zero concurrency":    func(c *AmazonS3Config) { c.UploadConcurrency = 0 },
+	} {
+		conf := NewAmazonS3Config()
+		fn(&conf)
+		_, err := NewAmazonS3(conf, log.Noop(), metrics.Noop())
+		assert.Error(t, err, name)
+	}
+}
@@ -2,7 +2,9 @@ package writer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"sync"
 	"time"
@@ -11,23 +13,32 @@ import (
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/proxy"
 )
 
 //------------------------------------------------------------------------------
 
 // SocketConfig contains configuration fields for the Socket output type.
 type SocketConfig struct {
-	Network string `json:"network" yaml:"network"`
-	Address string `json:"address" yaml:"address"`
-	Codec   string `json:"codec" yaml:"codec"`
+	Network       string `json:"network" yaml:"network"`
+	Address       string `json:"address" yaml:"address"`
+	ProxyURL      string `json:"proxy_url" yaml:"proxy_url"`
+	WriteTimeout  string `json:"write_timeout" yaml:"write_timeout"`
+	FireAndForget bool   `json:"fire_and_forget" yaml:"fire_and_forget"`
+	MaxPacketSize int    `json:"max_packet_size" yaml:"max_packet_size"`
+	Codec         string `json:"codec" yaml:"codec"`
 }
 
 // NewSocketConfig creates a new SocketConfig with default values.
 func NewSocketConfig() SocketConfig {
 	return SocketConfig{
-		Network: "unix",
-		Address: "/tmp/benthos.sock",
-		Codec:   "lines",
+		Network:       "unix",
+		Address:       "/tmp/benthos.sock",
+		ProxyURL:      "",
+		WriteTimeout:  "",
+		FireAndForget: false,
+		MaxPacketSize: 0,
+		Codec:         "lines",
 	}
 }
 
@@ -36,15 +47,19 @@ func NewSocketConfig() SocketConfig {
 // Socket is an output type that sends messages as a continuous steam of line
 // delimied messages over socket.
 type Socket struct {
-	network   string
-	address   string
-	codec     codec.WriterConstructor
-	codecConf codec.WriterConfig
+	network      string
+	address      string
+	dialer       proxy.Dialer
+	writeTimeout time.Duration
+	maxPacket    int
+	codec        codec.WriterConstructor
+	codecConf    codec.WriterConfig
 
 	stats metrics.Type
 	log   log.Modular
 
 	writer    codec.Writer
+	packer    *packetWriter
 	writerMut sync.Mutex
 }
 
@@ -64,13 +79,37 @@ func NewSocket(
 	if err != nil {
 		return nil, err
 	}
+	var dialer proxy.Dialer = &net.Dialer{}
+	if conf.ProxyURL != "" {
+		if conf.Network != "tcp" {
+			return nil, fmt.Errorf("proxy_url is not supported with the socket network '%v'", conf.Network)
+		}
+		if dialer, err = proxy.NewDialer(conf.ProxyURL, nil); err != nil {
+			return nil, err
+		}
+	}
+	var writeTimeout time.Duration
+	if conf.WriteTimeout != "" {
+		if writeTimeout, err = time.ParseDuration(conf.WriteTimeout); err != nil {
+			return nil, fmt.Errorf("failed to parse write_timeout string: %v", err)
+		}
+	}
+	if conf.MaxPacketSize < 0 {
+		return nil, errors.New("max_packet_size must not be negative")
+	}
+	if conf.MaxPacketSize > 0 && conf.Network != "udp" {
+		return nil, fmt.Errorf("max_packet_size is not supported with the socket network '%v'", conf.Network)
+	}
 	t := Socket{
-		network:   conf.Network,
-		address:   conf.Address,
-		codec:     codec,
-		codecConf: codecConf,
-		stats:     stats,
-		log:       log,
+		network:      conf.Network,
+		address:      conf.Address,
+		dialer:       dialer,
+		writeTimeout: writeTimeout,
+		maxPacket:    conf.MaxPacketSize,
+		codec:        codec,
+		codecConf:    codecConf,
+		stats:        stats,
+		log:          log,
 	}
 	return &t, nil
 }
@@ -90,12 +129,22 @@ func (s *Socket) ConnectWithContext(ctx context.Context) error {
 		return nil
 	}
 
-	conn, err := net.Dial(s.network, s.address)
+	conn, err := s.dialer.DialContext(ctx, s.network, s.address)
 	if err != nil {
 		return err
 	}
+	if s.writeTimeout > 0 {
+		conn = &deadlineConn{Conn: conn, timeout: s.writeTimeout}
+	}
 
-	s.writer, err = s.codec(conn)
+	var out io.WriteCloser = conn
+	s.packer = nil
+	if s.maxPacket > 0 {
+		s.packer = &packetWriter{conn: conn, maxSize: s.maxPacket}
+		out = s.packer
+	}
+
+	s.writer, err = s.codec(out)
 	if err != nil {
 		conn.Close()
 		return err
@@ -113,15 +162,50 @@ func (s *Socket) Write(msg types.Message) error {
 // WriteWithContext attempts to write a message.
 func (s *Socket) WriteWithContext(ctx context.Context, msg types.Message) error {
 	s.writerMut.Lock()
-	w := s.writer
+	w, packer := s.writer, s.packer
 	s.writerMut.Unlock()
 
 	if w == nil {
 		return types.ErrNotConnected
 	}
 
+	// Frames written by the codec are packed into the pending packet once
+	// complete, so that frames are never divided across packets, and the
+	// pending packet is sent at the end of the message.
+	endFrame := func() error {
+		if packer == nil {
+			return nil
+		}
+		return packer.endFrame()
+	}
+	flush := func() error {
+		if packer == nil {
+			return nil
+		}
+		return packer.flush()
+	}
+
+	if mw, ok := w.(codec.MessageWriter); ok {
+		err := mw.WriteMessage(ctx, msg)
+		if err == nil {
+			if err = endFrame(); err == nil {
+				err = flush()
+			}
+		}
+		if err != nil || s.codecConf.CloseAfter {
+			s.writerMut.Lock()
+			s.writer.Close(ctx)
+			s.writer = nil
+			s.writerMut.Unlock()
+		}
+		return err
+	}
+
 	err := msg.Iter(func(i int, part types.Part) error {
 		serr := w.Write(ctx, part)
+		if serr == nil {
+			serr = endFrame()
+		}
 		if serr != nil || s.codecConf.CloseAfter {
 			s.writerMut.Lock()
 			s.writer.Close(ctx)
@@ -131,7 +215,18 @@ func (s *Socket) WriteWithContext(ctx context.Context, msg types.Message) error
 		return serr
 	})
 	if err == nil && msg.Len() > 1 {
-		if err = w.EndBatch(); err != nil {
+		if err = w.EndBatch(); err == nil {
+			err = endFrame()
+		}
+		if err != nil {
+			s.writerMut.Lock()
+			s.writer.Close(ctx)
+			s.writer = nil
+			s.writerMut.Unlock()
+		}
+	}
+	if err == nil {
+		if err = flush(); err != nil {
 			s.writerMut.Lock()
 			s.writer.Close(ctx)
 			s.writer = nil
@@ -157,3 +252,67 @@ func (s *Socket) WaitForClose(timeout time.Duration) error {
 }
 
 //------------------------------------------------------------------------------
+
+// deadlineConn fails writes that do not complete within a timeout, which
+// results in the connection being closed and reestablished.
+type deadlineConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (d *deadlineConn) Write(p []byte) (int, error) {
+	if err := d.Conn.SetWriteDeadline(time.Now().Add(d.timeout)); err != nil {
+		return 0, err
+	}
+	return d.Conn.Write(p)
+}
+
+// packetWriter accumulates the frames written by a codec into packets of up to
+// a maximum size, where each packet is sent with a single write.
+type packetWriter struct {
+	conn    net.Conn
+	maxSize int
+
+	frame  []byte
+	packet []byte
+}
+
+// Write appends to the frame currently being written.
+func (p *packetWriter) Write(b []byte) (int, error) {
+	p.frame = append(p.frame, b...)
+	return len(b), nil
+}
+
+// endFrame adds the current frame to the pending packet, sending the pending
+// packet first if the frame would not fit within it.
+func (p *packetWriter) endFrame() error {
+	defer func() {
+		p.frame = p.frame[:0]
+	}()
+	if len(p.frame) > p.maxSize {
+		return fmt.Errorf("message size %v exceeds the maximum packet size of %v", len(p.frame), p.maxSize)
+	}
+	if len(p.packet)+len(p.frame) > p.maxSize {
+		if err := p.flush(); err != nil {
+			return err
+		}
+	}
+	p.packet = append(p.packet, p.frame...)
+	return nil
+}
+
+// flush sends the pending packet.
+func (p *packetWriter) flush() error {
+	if len(p.packet) == 0 {
+		return nil
+	}
+	_, err := p.conn.Write(p.packet)
+	p.packet = p.packet[:0]
+	return err
+}
+
+func (p *packetWriter) Close() error {
+	return p.conn.Close()
+}
+
+//------------------------------------------------------------------------------
@@ -1,8 +1,10 @@
 package writer
 
 import (
+	"bufio"
 	"bytes"
 	"net"
+	"net/http"
 	"sync"
 	"testing"
 	"time"
@@ -10,6 +12,7 @@ import (
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/message"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/types"
 )
 
 func TestSocketBasic(t *testing.T) {
@@ -447,3 +450,239 @@ func TestSocketCustomDelimeter(t *testing.T) {
 
 	conn.Close()
 }
+
+func TestTCPSocketHTTPProxy(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen on a port: %v", err)
+	}
+	defer ln.Close()
+
+	conf := NewSocketConfig()
+	conf.Network = "tcp"
+	conf.Address = "target.example.com:9000"
+	conf.ProxyURL = "http://foo:bar@" + ln.Addr().String()
+
+	wtr, err := NewSocket(conf, nil, log.Noop(), metrics.Noop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if err := wtr.WaitForClose(time.Second); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	go func() {
+		if cerr := wtr.Connect(); cerr != nil {
+			t.Error(cerr)
+		}
+	}()
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// The proxy accepts the CONNECT request and then plays the part of the
+	// target itself.
+	br := bufio.NewReader(conn)
+	req, err := http.ReadRequest(br)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp, act := http.MethodConnect, req.Method; exp != act {
+		t.Errorf("Wrong method: %v != %v", act, exp)
+	}
+	if exp, act := "target.example.com:9000", req.Host; exp != act {
+		t.Errorf("Wrong host: %v != %v", act, exp)
+	}
+	if exp, act := "Basic Zm9vOmJhcg==", req.Header.Get("Proxy-Authorization"); exp != act {
+		t.Errorf("Wrong proxy authorization: %v != %v", act, exp)
+	}
+	if _, err = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+		buf.ReadFrom(br)
+		wg.Done()
+	}()
+
+	if err = wtr.Write(message.New([][]byte{[]byte("foo")})); err != nil {
+		t.Error(err)
+	}
+	if err = wtr.Write(message.New([][]byte{[]byte("bar")})); err != nil {
+		t.Error(err)
+	}
+	wtr.CloseAsync()
+	wg.Wait()
+
+	exp := "foo\nbar\n"
+	if act := buf.String(); exp != act {
+		t.Errorf("Wrong result: %v != %v", act, exp)
+	}
+}
+
+func TestSocketProxyBadNetwork(t *testing.T) {
+	conf := NewSocketConfig()
+	conf.Network = "udp"
+	conf.Address = "localhost:9000"
+	conf.ProxyURL = "socks5://localhost:1080"
+
+	if _, err := NewSocket(conf, nil, log.Noop(), metrics.Noop()); err == nil {
+		t.Error("Expected error from proxy_url with udp network")
+	}
+
+	conf.Network = "tcp"
+	conf.ProxyURL = "ftp://localhost:21"
+	if _, err := NewSocket(conf, nil, log.Noop(), metrics.Noop()); err == nil {
+		t.Error("Expected error from unsupported proxy scheme")
+	}
+}
+
+func TestTCPSocketWriteTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("failed to listen on address: %v", err)
+	}
+	defer ln.Close()
+
+	conf := NewSocketConfig()
+	conf.Network = "tcp"
+	conf.Address = ln.Addr().String()
+	conf.WriteTimeout = "100ms"
+
+	wtr, err := NewSocket(conf, nil, log.Noop(), metrics.Noop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wtr.CloseAsync()
+
+	connChan := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			connChan <- conn
+		}
+	}()
+
+	if err = wtr.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	conn := <-connChan
+	defer conn.Close()
+
+	// The server never reads and therefore a large enough write blocks.
+	if err = wtr.Write(message.New([][]byte{make([]byte, 64*1024*1024)})); err == nil {
+		t.Fatal("Expected write timeout error")
+	}
+	if err = wtr.Write(message.New([][]byte{[]byte("foo")})); err != types.ErrNotConnected {
+		t.Errorf("Wrong error: %v != %v", err, types.ErrNotConnected)
+	}
+
+	if err = wtr.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	conn = <-connChan
+	defer conn.Close()
+
+	if err = wtr.Write(message.New([][]byte{[]byte("foo")})); err != nil {
+		t.Fatal(err)
+	}
+	conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp, act := "foo\n", line; exp != act {
+		t.Errorf("Wrong result: %v != %v", act, exp)
+	}
+}
+
+func TestSocketBadWriteTimeout(t *testing.T) {
+	conf := NewSocketConfig()
+	conf.WriteTimeout = "nope"
+	if _, err := NewSocket(conf, nil, log.Noop(), metrics.Noop()); err == nil {
+		t.Error("Expected error from bad write_timeout")
+	}
+}
+
+func TestUDPSocketMaxPacketSize(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("failed to listen on a port: %v", err)
+	}
+	defer conn.Close()
+
+	conf := NewSocketConfig()
+	conf.Network = "udp"
+	conf.Address = conn.LocalAddr().String()
+	conf.MaxPacketSize = 8
+
+	wtr, err := NewSocket(conf, nil, log.Noop(), metrics.Noop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cerr := wtr.Connect(); cerr != nil {
+		t.Fatal(cerr)
+	}
+
+	var packets []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b := make([]byte, 1024)
+		for {
+			conn.SetReadDeadline(time.Now().Add(time.Millisecond * 500))
+			n, _, rerr := conn.ReadFrom(b)
+			if rerr != nil {
+				return
+			}
+			packets = append(packets, string(b[:n]))
+		}
+	}()
+
+	if err = wtr.Write(message.New([][]byte{[]byte("foo"), []byte("bar"), []byte("bazqux")})); err != nil {
+		t.Error(err)
+	}
+	if err = wtr.Write(message.New([][]byte{[]byte("quz")})); err != nil {
+		t.Error(err)
+	}
+	if err = wtr.Write(message.New([][]byte{[]byte("too long to fit")})); err == nil {
+		t.Error("Expected error from message exceeding max_packet_size")
+	}
+	wtr.CloseAsync()
+	<-done
+
+	// Messages are never divided across packets, and the final delimiter of
+	// the batch is packed with the message before it.
+	exp := []string{"foo\nbar\n", "bazqux\n\n", "quz\n"}
+	if len(packets) != len(exp) {
+		t.Fatalf("Wrong packets: %q != %q", packets, exp)
+	}
+	for i, p := range exp {
+		if packets[i] != p {
+			t.Errorf("Wrong packet %v: %q != %q", i, packets[i], p)
+		}
+	}
+}
+
+func TestSocketMaxPacketSizeBadNetwork(t *testing.T) {
+	conf := NewSocketConfig()
+	conf.Network = "tcp"
+	conf.MaxPacketSize = 1400
+	if _, err := NewSocket(conf, nil, log.Noop(), metrics.Noop()); err == nil {
+		t.Error("Expected error from max_packet_size with tcp network")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	batchInternal "github.com/Jeffail/benthos/v3/internal/batch"
 	"github.com/Jeffail/benthos/v3/internal/bloblang"
 	"github.com/Jeffail/benthos/v3/internal/bloblang/field"
 	"github.com/Jeffail/benthos/v3/internal/component/output"
@@ -252,7 +253,7 @@ func (a *AmazonSQS) WriteWithContext(ctx context.Context, msg types.Message) err
 			a.log.Warnf("SQS error: %v\n", err)
 			// bail if a message is too large or all retry attempts expired
 			if wait == backoff.Stop {
-				return err
+				return sqsBatchError(msg, err, nil, append(input.Entries, entries...))
 			}
 			select {
 			case <-time.After(wait):
@@ -270,7 +271,7 @@ func (a *AmazonSQS) WriteWithContext(ctx context.Context, msg types.Message) err
 				if *v.SenderFault {
 					err = fmt.Errorf("record failed with code: %v, message: %v", *v.Code, *v.Message)
 					a.log.Errorf("SQS record error: %v\n", err)
-					return err
+					return sqsBatchError(msg, err, unproc, entries)
 				}
 				aMap := attrMap[*v.Id]
 				input.Entries = append(input.Entries, &sqs.SendMessageBatchRequestEntry{
@@ -288,7 +289,7 @@ func (a *AmazonSQS) WriteWithContext(ctx context.Context, msg types.Message) err
 
 		if err != nil {
 			if wait == backoff.Stop {
-				break
+				return sqsBatchError(msg, err, batchResult.Failed, entries)
 			}
 			select {
 			case <-time.After(wait):
@@ -313,6 +314,31 @@ func (a *AmazonSQS) WriteWithContext(ctx context.Context, msg types.Message) err
 	return err
 }
 
+// sqsBatchError returns a batch error where only the messages of failed entries
+// and entries that were never attempted are marked as failed, allowing the
+// remaining messages to be acknowledged.
+func sqsBatchError(msg types.Message, err error, failed []*sqs.BatchResultErrorEntry, unsent []*sqs.SendMessageBatchRequestEntry) error {
+	bErr := batchInternal.NewError(msg, err)
+	markFailed := func(id *string) {
+		if id == nil {
+			return
+		}
+		if i, perr := strconv.Atoi(*id); perr == nil {
+			bErr.Failed(i, err)
+		}
+	}
+	for _, v := range failed {
+		markFailed(v.Id)
+	}
+	for _, v := range unsent {
+		markFailed(v.Id)
+	}
+	if bErr.IndexedErrors() == 0 {
+		return err
+	}
+	return bErr
+}
+
 // CloseAsync begins cleaning up resources used by this reader asynchronously.
 func (a *AmazonSQS) CloseAsync() {
 	a.closer.Do(func() {
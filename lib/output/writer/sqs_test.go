@@ -1,6 +1,17 @@
 package writer
 
-import "testing"
+import (
+	"errors"
+	"testing"
+
+	batchInternal "github.com/Jeffail/benthos/v3/internal/batch"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
 
 func TestSQSHeaderCheck(t *testing.T) {
 	type testCase struct {
@@ -93,3 +104,30 @@ func TestSQSHeaderCheck(t *testing.T) {
 		}
 	}
 }
+
+func TestSQSBatchError(t *testing.T) {
+	msg := message.New([][]byte{[]byte("foo"), []byte("bar"), []byte("baz"), []byte("buz")})
+
+	err := sqsBatchError(msg, errors.New("nope"), []*sqs.BatchResultErrorEntry{
+		{Id: aws.String("1")},
+	}, []*sqs.SendMessageBatchRequestEntry{
+		{Id: aws.String("3")},
+	})
+
+	var bErr *batchInternal.Error
+	require.True(t, errors.As(err, &bErr))
+	assert.Equal(t, 2, bErr.IndexedErrors())
+
+	var failed []string
+	bErr.WalkParts(func(i int, p types.Part, err error) bool {
+		if err != nil {
+			failed = append(failed, string(p.Get()))
+		}
+		return true
+	})
+	assert.Equal(t, []string{"bar", "buz"}, failed)
+
+	err = sqsBatchError(msg, errors.New("nope"), nil, nil)
+	assert.EqualError(t, err, "nope")
+	assert.False(t, errors.As(err, &bErr))
+}
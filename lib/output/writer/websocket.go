@@ -1,6 +1,7 @@
 package writer
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
 	"sync"
@@ -18,14 +19,16 @@ import (
 // WebsocketConfig contains configuration fields for the Websocket output type.
 type WebsocketConfig struct {
 	URL         string `json:"url" yaml:"url"`
+	ProxyURL    string `json:"proxy_url" yaml:"proxy_url"`
 	auth.Config `json:",inline" yaml:",inline"`
 }
 
 // NewWebsocketConfig creates a new WebsocketConfig with default values.
 func NewWebsocketConfig() WebsocketConfig {
 	return WebsocketConfig{
-		URL:    "ws://localhost:4195/post/ws",
-		Config: auth.NewConfig(),
+		URL:      "ws://localhost:4195/post/ws",
+		ProxyURL: "",
+		Config:   auth.NewConfig(),
 	}
 }
 
@@ -39,6 +42,7 @@ type Websocket struct {
 	lock *sync.Mutex
 
 	conf   WebsocketConfig
+	dialer *websocket.Dialer
 	client *websocket.Conn
 }
 
@@ -54,6 +58,16 @@ func NewWebsocket(
 		lock:  &sync.Mutex{},
 		conf:  conf,
 	}
+	ws.dialer = websocket.DefaultDialer
+	if conf.ProxyURL != "" {
+		u, err := url.Parse(conf.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy_url string: %v", err)
+		}
+		dialer := *websocket.DefaultDialer
+		dialer.Proxy = http.ProxyURL(u)
+		ws.dialer = &dialer
+	}
 	return ws, nil
 }
 
@@ -92,7 +106,7 @@ func (w *Websocket) Connect() error {
 	}
 
 	var client *websocket.Conn
-	if client, _, err = websocket.DefaultDialer.Dial(w.conf.URL, headers); err != nil {
+	if client, _, err = w.dialer.Dial(w.conf.URL, headers); err != nil {
 		return err
 	}
 
@@ -3,6 +3,7 @@
 package writer
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -68,6 +69,10 @@ func getZMQType(t string) (zmq4.Type, error) {
 		return zmq4.PUB, nil
 	case "PUSH":
 		return zmq4.PUSH, nil
+	case "ROUTER":
+		return zmq4.ROUTER, nil
+	case "DEALER":
+		return zmq4.DEALER, nil
 	}
 	return zmq4.PULL, types.ErrInvalidZMQType
 }
@@ -103,6 +108,20 @@ func (z *ZMQ4) Connect() error {
 
 	socket.SetSndhwm(z.conf.HighWaterMark)
 
+	if z.conf.Identity != "" {
+		if err = socket.SetIdentity(z.conf.Identity); err != nil {
+			return err
+		}
+	}
+
+	// Messages addressed to peers that are not connected result in an error
+	// rather than being silently dropped.
+	if t == zmq4.ROUTER {
+		if err = socket.SetRouterMandatory(1); err != nil {
+			return err
+		}
+	}
+
 	for _, address := range z.urls {
 		if z.conf.Bind {
 			err = socket.Bind(address)
@@ -127,11 +146,21 @@ func (z *ZMQ4) Write(msg types.Message) error {
 	if z.socket == nil {
 		return types.ErrNotConnected
 	}
-	_, err := z.socket.SendMessageDontwait(message.GetAllBytes(msg))
+	frames := message.GetAllBytes(msg)
+	if z.conf.SocketType == "ROUTER" {
+		// ROUTER sockets route each message to the peer identified by its
+		// first frame.
+		identity := msg.Get(0).Metadata().Get("zmq4_identity")
+		if identity == "" {
+			return errors.New("message has no zmq4_identity metadata to route with")
+		}
+		frames = append([][]byte{[]byte(identity)}, frames...)
+	}
+	_, err := z.socket.SendMessageDontwait(frames)
 	if err != nil {
 		var polled []zmq4.Polled
 		if polled, err = z.poller.Poll(z.pollTimeout); len(polled) == 1 {
-			_, err = z.socket.SendMessage(message.GetAllBytes(msg))
+			_, err = z.socket.SendMessage(frames)
 		} else if err == nil {
 			return types.ErrTimeout
 		}
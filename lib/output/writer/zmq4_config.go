@@ -7,6 +7,7 @@ type ZMQ4Config struct {
 	URLs          []string `json:"urls" yaml:"urls"`
 	Bind          bool     `json:"bind" yaml:"bind"`
 	SocketType    string   `json:"socket_type" yaml:"socket_type"`
+	Identity      string   `json:"identity" yaml:"identity"`
 	HighWaterMark int      `json:"high_water_mark" yaml:"high_water_mark"`
 	PollTimeout   string   `json:"poll_timeout" yaml:"poll_timeout"`
 }
@@ -17,6 +18,7 @@ func NewZMQ4Config() *ZMQ4Config {
 		URLs:          []string{"tcp://*:5556"},
 		Bind:          true,
 		SocketType:    "PUSH",
+		Identity:      "",
 		HighWaterMark: 0,
 		PollTimeout:   "5s",
 	}
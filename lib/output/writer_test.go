@@ -577,3 +577,70 @@ func TestWriterSadPath(t *testing.T) {
 }
 
 //------------------------------------------------------------------------------
+
+func TestWriterThrottleReporter(t *testing.T) {
+	t.Parallel()
+
+	writerImpl := &throttleReportingWriter{mockWriter: newMockWriter()}
+	stats := metrics.NewLocal()
+
+	w, err := NewWriter("foo", writerImpl, log.Noop(), stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msgChan := make(chan types.Transaction)
+	resChan := make(chan types.Response)
+	if err = w.Consume(msgChan); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case writerImpl.connChan <- nil:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+
+	writeMsg := func() {
+		t.Helper()
+		select {
+		case msgChan <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), resChan):
+		case <-time.After(time.Second):
+			t.Fatal("Timed out")
+		}
+		select {
+		case writerImpl.writeChan <- nil:
+		case <-time.After(time.Second):
+			t.Fatal("Timed out")
+		}
+		select {
+		case res := <-resChan:
+			if res.Error() != nil {
+				t.Error(res.Error())
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Timed out")
+		}
+	}
+
+	// The write succeeds but the sink reports that it was throttled.
+	writeMsg()
+	writerImpl.onThrottle(time.Millisecond * 100)
+	throttledAt := time.Now()
+
+	// The next write must not be attempted until the period requested by the
+	// sink has passed.
+	writeMsg()
+	if dur := time.Since(throttledAt); dur < time.Millisecond*90 {
+		t.Errorf("Write was not delayed: %v", dur)
+	}
+
+	w.CloseAsync()
+	if err = w.WaitForClose(time.Second); err != nil {
+		t.Error(err)
+	}
+
+	if exp, act := int64(1), stats.GetCounters()["throttled"]; exp != act {
+		t.Errorf("Wrong throttled count: %v != %v", act, exp)
+	}
+}
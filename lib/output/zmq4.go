@@ -16,8 +16,8 @@ func init() {
 	Constructors[TypeZMQ4] = TypeSpec{
 		constructor: fromSimpleConstructor(NewZMQ4),
 		Summary: `
-The zmq4 output type attempts to send messages to a ZMQ4 port, currently only
-PUSH and PUB sockets are supported.`,
+The zmq4 output type attempts to send messages to a ZMQ4 port, currently PUSH,
+PUB, ROUTER and DEALER sockets are supported.`,
 		Description: `
 ZMQ4 is supported but currently depends on C bindings. Since this is an
 annoyance when building or using Benthos it is not compiled by default.
@@ -30,11 +30,18 @@ machine, then build with the tag:
 
 ` + "```sh" + `
 go install -tags "ZMQ4" github.com/Jeffail/benthos/v3/cmd/benthos
-` + "```" + ``,
+` + "```" + `
+
+A DEALER socket distributes messages across all connected peers in a round-robin
+fashion, which allows requests to be load balanced across ROUTER peers. A ROUTER
+socket sends each message to the peer identified by the metadata field
+` + "`zmq4_identity`" + `, as set by a ` + "`zmq4`" + ` input with a ROUTER socket, and
+messages without it or addressed to peers that are not connected fail to send.`,
 		FieldSpecs: docs.FieldSpecs{
 			docs.FieldCommon("urls", "A list of URLs to connect to. If an item of the list contains commas it will be expanded into multiple URLs.", []string{"tcp://localhost:5556"}),
 			docs.FieldCommon("bind", "Whether the URLs listed should be bind (otherwise they are connected to)."),
-			docs.FieldCommon("socket_type", "The socket type to send with.").HasOptions("PUSH", "PUB"),
+			docs.FieldCommon("socket_type", "The socket type to send with.").HasOptions("PUSH", "PUB", "ROUTER", "DEALER"),
+			docs.FieldAdvanced("identity", "An optional identity of the socket, which ROUTER peers use to address it. When empty an identity is generated by the peer.").HasDefault("").AtVersion("3.54.0"),
 			docs.FieldAdvanced("high_water_mark", "The message high water mark to use."),
 			docs.FieldCommon("poll_timeout", "The maximum period of time to wait for a message to send before the request is abandoned and reattempted."),
 		},
@@ -52,6 +52,7 @@ func NewPool(
 		closed:      make(chan struct{}),
 	}
 
+	stats.GetGauge("threads").Set(int64(threads))
 	for i := range p.workers {
 		procs := 0
 		var err error
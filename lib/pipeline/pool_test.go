@@ -323,3 +323,63 @@ func TestPoolMultiNaturalClose(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestPoolUtilisationMetrics(t *testing.T) {
+	mockProc := &mockMsgProcessor{dropChan: make(chan bool)}
+	stats := metrics.NewLocal()
+
+	constr := func(i *int) (types.Pipeline, error) {
+		return NewProcessor(log.Noop(), stats, mockProc), nil
+	}
+
+	proc, err := NewPool(constr, 2, log.Noop(), stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp, act := int64(2), stats.GetCounters()["threads"]; exp != act {
+		t.Errorf("Wrong threads gauge: %v != %v", act, exp)
+	}
+
+	tChan, resChan := make(chan types.Transaction), make(chan types.Response)
+	if err := proc.Consume(tChan); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case tChan <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), resChan):
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+
+	// The processor blocks until we write to dropChan, during which the thread
+	// is busy.
+	deadline := time.Now().Add(time.Second * 5)
+	for stats.GetCounters()["threads.busy"] != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for busy thread")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	select {
+	case mockProc.dropChan <- false:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+
+	select {
+	case <-proc.TransactionChan():
+	case <-time.After(time.Second):
+		t.Fatal("Timed out")
+	}
+
+	if exp, act := int64(0), stats.GetCounters()["threads.busy"]; exp != act {
+		t.Errorf("Wrong busy threads gauge: %v != %v", act, exp)
+	}
+
+	proc.CloseAsync()
+	if err := proc.WaitForClose(time.Second * 5); err != nil {
+		t.Error(err)
+	}
+}
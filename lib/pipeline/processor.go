@@ -23,6 +23,7 @@ type Processor struct {
 
 	log   log.Modular
 	stats metrics.Type
+	mBusy metrics.StatGauge
 
 	msgProcessors []types.Processor
 
@@ -45,6 +46,7 @@ func NewProcessor(
 		running:       1,
 		msgProcessors: msgProcessors,
 		stats:         stats,
+		mBusy:         stats.GetGauge("threads.busy"),
 		messagesOut:   make(chan types.Transaction),
 		responsesIn:   make(chan types.Response),
 		closeChan:     make(chan struct{}),
@@ -78,7 +80,9 @@ func (p *Processor) loop() {
 			return
 		}
 
+		p.mBusy.Incr(1)
 		resultMsgs, resultRes := processor.ExecuteAll(p.msgProcessors, tran.Payload)
+		p.mBusy.Decr(1)
 		if len(resultMsgs) == 0 {
 			if resultRes == nil {
 				resultRes = response.NewUnack()
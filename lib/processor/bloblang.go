@@ -174,7 +174,7 @@ func NewBloblangFromExecutor(exec *mapping.Executor, log log.Modular, stats metr
 		mErr:       stats.GetCounter("error"),
 		mSent:      stats.GetCounter("sent"),
 		mBatchSent: stats.GetCounter("batch.sent"),
-		mDropped:   stats.GetCounter("dropped"),
+		mDropped:   metrics.NewDroppedCounter(stats, "dropped", metrics.DropReasonFilter),
 	}
 }
 
@@ -65,6 +65,8 @@ type BoundsCheck struct {
 	mDroppedEmpty    metrics.StatCounter
 	mDroppedNumParts metrics.StatCounter
 	mDroppedPartSize metrics.StatCounter
+	mDroppedFilter   metrics.StatCounter
+	mDroppedTooLarge metrics.StatCounter
 	mSent            metrics.StatCounter
 	mBatchSent       metrics.StatCounter
 }
@@ -83,6 +85,8 @@ func NewBoundsCheck(
 		mDroppedEmpty:    stats.GetCounter("dropped_empty"),
 		mDroppedNumParts: stats.GetCounter("dropped_num_parts"),
 		mDroppedPartSize: stats.GetCounter("dropped_part_size"),
+		mDroppedFilter:   metrics.GetDroppedCounter(stats, metrics.DropReasonFilter),
+		mDroppedTooLarge: metrics.GetDroppedCounter(stats, metrics.DropReasonTooLarge),
 		mSent:            stats.GetCounter("sent"),
 		mBatchSent:       stats.GetCounter("batch.sent"),
 	}, nil
@@ -103,6 +107,7 @@ func (m *BoundsCheck) ProcessMessage(msg types.Message) ([]types.Message, types.
 		)
 		m.mDropped.Incr(1)
 		m.mDroppedEmpty.Incr(1)
+		m.mDroppedFilter.Incr(1)
 		return nil, response.NewAck()
 	} else if lParts > m.conf.BoundsCheck.MaxParts {
 		m.log.Debugf(
@@ -111,10 +116,11 @@ func (m *BoundsCheck) ProcessMessage(msg types.Message) ([]types.Message, types.
 		)
 		m.mDropped.Incr(1)
 		m.mDroppedNumParts.Incr(1)
+		m.mDroppedTooLarge.Incr(1)
 		return nil, response.NewAck()
 	}
 
-	var reject bool
+	var reject, tooLarge bool
 	msg.Iter(func(i int, p types.Part) error {
 		if size := len(p.Get()); size > m.conf.BoundsCheck.MaxPartSize ||
 			size < m.conf.BoundsCheck.MinPartSize {
@@ -125,6 +131,7 @@ func (m *BoundsCheck) ProcessMessage(msg types.Message) ([]types.Message, types.
 				size,
 			)
 			reject = true
+			tooLarge = size > m.conf.BoundsCheck.MaxPartSize
 			return errors.New("exit")
 		}
 		return nil
@@ -133,6 +140,11 @@ func (m *BoundsCheck) ProcessMessage(msg types.Message) ([]types.Message, types.
 	if reject {
 		m.mDropped.Incr(1)
 		m.mDroppedPartSize.Incr(1)
+		if tooLarge {
+			m.mDroppedTooLarge.Incr(1)
+		} else {
+			m.mDroppedFilter.Incr(1)
+		}
 		return nil, response.NewAck()
 	}
 
@@ -204,7 +204,7 @@ func NewDedupe(
 		mErrHash:   stats.GetCounter("error.hash"),
 		mErrCache:  stats.GetCounter("error.cache"),
 		mErr:       stats.GetCounter("error"),
-		mDropped:   stats.GetCounter("dropped"),
+		mDropped:   metrics.NewDroppedCounter(stats, "dropped", metrics.DropReasonFilter),
 		mSent:      stats.GetCounter("sent"),
 		mBatchSent: stats.GetCounter("batch.sent"),
 	}, nil
@@ -83,7 +83,7 @@ func NewFilter(
 		condition: cond,
 
 		mCount:     stats.GetCounter("count"),
-		mDropped:   stats.GetCounter("dropped"),
+		mDropped:   metrics.NewDroppedCounter(stats, "dropped", metrics.DropReasonFilter),
 		mSent:      stats.GetCounter("sent"),
 		mBatchSent: stats.GetCounter("batch.sent"),
 	}, nil
@@ -85,7 +85,7 @@ func NewFilterParts(
 		condition: cond,
 
 		mCount:     stats.GetCounter("count"),
-		mDropped:   stats.GetCounter("dropped"),
+		mDropped:   metrics.NewDroppedCounter(stats, "dropped", metrics.DropReasonFilter),
 		mSent:      stats.GetCounter("sent"),
 		mBatchSent: stats.GetCounter("batch.sent"),
 	}, nil
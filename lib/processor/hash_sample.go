@@ -87,7 +87,7 @@ func NewHashSample(
 
 		mCount:     stats.GetCounter("count"),
 		mDropOOB:   stats.GetCounter("dropped_part_out_of_bounds"),
-		mDropped:   stats.GetCounter("dropped"),
+		mDropped:   metrics.NewDroppedCounter(stats, "dropped", metrics.DropReasonFilter),
 		mErr:       stats.GetCounter("error"),
 		mSent:      stats.GetCounter("sent"),
 		mBatchSent: stats.GetCounter("batch.sent"),
@@ -77,7 +77,7 @@ func NewSample(
 		gen:    gen,
 
 		mCount:     stats.GetCounter("count"),
-		mDropped:   stats.GetCounter("dropped"),
+		mDropped:   metrics.NewDroppedCounter(stats, "dropped", metrics.DropReasonFilter),
 		mSent:      stats.GetCounter("sent"),
 		mBatchSent: stats.GetCounter("batch.sent"),
 	}, nil
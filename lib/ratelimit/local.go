@@ -10,6 +10,7 @@ import (
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/clock"
 )
 
 //------------------------------------------------------------------------------
@@ -54,7 +55,8 @@ func NewLocalConfig() LocalConfig {
 type Local struct {
 	mut         sync.Mutex
 	bucket      int
-	lastRefresh time.Time
+	lastRefresh time.Duration
+	clock       clock.Monotonic
 
 	size   int
 	period time.Duration
@@ -81,7 +83,8 @@ func NewLocal(
 	}
 	return &Local{
 		bucket:      conf.Local.Count,
-		lastRefresh: time.Now(),
+		lastRefresh: clock.System.Elapsed(),
+		clock:       clock.System,
 		size:        conf.Local.Count,
 		period:      period,
 
@@ -104,7 +107,8 @@ func (r *Local) Access() (time.Duration, error) {
 
 	if r.bucket < 0 {
 		r.bucket = 0
-		remaining := r.period - time.Since(r.lastRefresh)
+		now := r.clock.Elapsed()
+		remaining := r.period - (now - r.lastRefresh)
 
 		if remaining > 0 {
 			r.mut.Unlock()
@@ -112,7 +116,7 @@ func (r *Local) Access() (time.Duration, error) {
 			return remaining, nil
 		}
 		r.bucket = r.size - 1
-		r.lastRefresh = time.Now()
+		r.lastRefresh = now
 	}
 	r.mut.Unlock()
 	return 0, nil
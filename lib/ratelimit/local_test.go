@@ -7,10 +7,44 @@ import (
 
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/util/clock"
 )
 
 //------------------------------------------------------------------------------
 
+func TestLocalRateLimitMonotonic(t *testing.T) {
+	conf := NewConfig()
+	conf.Local.Count = 2
+	conf.Local.Interval = "1m"
+
+	rl, err := NewLocal(conf, nil, log.Noop(), metrics.Noop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk := clock.NewManual()
+	local := rl.(*Local)
+	local.clock = clk
+	local.lastRefresh = clk.Elapsed()
+
+	for i := 0; i < conf.Local.Count; i++ {
+		if period, _ := rl.Access(); period != 0 {
+			t.Errorf("Rate limited on get %v", i)
+		}
+	}
+
+	// Only the time measured by the monotonic clock counts towards the window,
+	// and so steps of the wall clock cannot refill the bucket early.
+	clk.Advance(time.Second * 45)
+	if period, _ := rl.Access(); period != time.Second*15 {
+		t.Errorf("Wrong period: %v", period)
+	}
+
+	clk.Advance(time.Second * 15)
+	if period, _ := rl.Access(); period != 0 {
+		t.Errorf("Rate limited after window: %v", period)
+	}
+}
+
 func TestLocalRateLimitConfErrors(t *testing.T) {
 	conf := NewConfig()
 	conf.Local.Count = -1
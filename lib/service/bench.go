@@ -0,0 +1,310 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/buffer"
+	"github.com/Jeffail/benthos/v3/lib/config"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/manager"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/pipeline"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/urfave/cli/v2"
+)
+
+//------------------------------------------------------------------------------
+
+// benchSentKey is the metadata key used to carry the time at which a synthetic
+// message was sent through the benchmarked components.
+const benchSentKey = "benthos_bench_sent"
+
+type benchOptions struct {
+	count     int
+	batchSize int
+	inFlight  int
+	size      int
+	payload   string
+}
+
+type benchResult struct {
+	messagesIn  int
+	messagesOut int
+	bytesIn     int
+	errors      int
+	duration    time.Duration
+	latencies   []time.Duration
+	mallocs     uint64
+	allocBytes  uint64
+	gcCycles    uint32
+}
+
+// latency returns a percentile of the measured latencies.
+func (r benchResult) latency(percentile float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	i := int(float64(len(r.latencies))*percentile) - 1
+	if i < 0 {
+		i = 0
+	}
+	return r.latencies[i]
+}
+
+func (r benchResult) print() {
+	seconds := r.duration.Seconds()
+	fmt.Printf("Messages:    %v sent, %v received, %v errors\n", r.messagesIn, r.messagesOut, r.errors)
+	fmt.Printf("Duration:    %v\n", r.duration.Round(time.Millisecond))
+	fmt.Printf("Throughput:  %.1f msgs/sec, %.2f MB/sec\n", float64(r.messagesIn)/seconds, float64(r.bytesIn)/seconds/1024/1024)
+	if len(r.latencies) > 0 {
+		fmt.Printf("Latency:     p50 %v, p99 %v, max %v\n", r.latency(0.5).Round(time.Microsecond), r.latency(0.99).Round(time.Microsecond), r.latency(1).Round(time.Microsecond))
+	} else {
+		fmt.Println("Latency:     unknown, no messages were received with their metadata intact")
+	}
+	if r.messagesIn > 0 {
+		fmt.Printf(
+			"Allocations: %.1f allocs/msg, %.1f KB/msg, %v GC cycles\n",
+			float64(r.mallocs)/float64(r.messagesIn),
+			float64(r.allocBytes)/float64(r.messagesIn)/1024,
+			r.gcCycles,
+		)
+	}
+}
+
+// benchPayloads returns a function that creates the payload of each synthetic
+// message, which is either a static payload or a JSON document of roughly a
+// given size.
+func benchPayloads(opts benchOptions) func(i int) []byte {
+	if opts.payload != "" {
+		payload := []byte(opts.payload)
+		return func(int) []byte {
+			return payload
+		}
+	}
+
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	r := rand.New(rand.NewSource(1))
+	data := make([]byte, opts.size)
+	for i := range data {
+		data[i] = letters[r.Intn(len(letters))]
+	}
+	return func(i int) []byte {
+		doc := make([]byte, 0, len(data)+32)
+		doc = append(doc, `{"id":`...)
+		doc = strconv.AppendInt(doc, int64(i), 10)
+		doc = append(doc, `,"data":"`...)
+		doc = append(doc, data...)
+		return append(doc, `"}`...)
+	}
+}
+
+// runBench sends synthetic messages through the buffer and processors of a
+// config and measures the rate at which they are consumed from the other end.
+func runBench(conf config.Type, opts benchOptions) (*benchResult, error) {
+	if opts.count <= 0 || opts.batchSize <= 0 || opts.inFlight <= 0 {
+		return nil, errors.New("count, batch size and in flight must be greater than zero")
+	}
+
+	logger, stats := log.Noop(), metrics.Noop()
+	mgr, err := manager.NewV2(conf.ResourceConfig, nil, logger, stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resources: %w", err)
+	}
+	defer func() {
+		mgr.CloseAsync()
+		_ = mgr.WaitForClose(time.Second * 30)
+	}()
+
+	tranChan := make(chan types.Transaction)
+	var nextTranChan <-chan types.Transaction = tranChan
+
+	var closables []types.Closable
+	defer func() {
+		for _, c := range closables {
+			c.CloseAsync()
+		}
+		for _, c := range closables {
+			_ = c.WaitForClose(time.Second * 30)
+		}
+	}()
+
+	if conf.Buffer.Type != buffer.TypeNone {
+		buf, err := buffer.New(conf.Buffer, mgr, logger, stats)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create buffer: %w", err)
+		}
+		closables = append(closables, buf)
+		if err = buf.Consume(nextTranChan); err != nil {
+			return nil, err
+		}
+		nextTranChan = buf.TransactionChan()
+	}
+	if len(conf.Pipeline.Processors) > 0 {
+		pipe, err := pipeline.New(conf.Pipeline, mgr, logger, stats)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create pipeline: %w", err)
+		}
+		closables = append(closables, pipe)
+		if err = pipe.Consume(nextTranChan); err != nil {
+			return nil, err
+		}
+		nextTranChan = pipe.TransactionChan()
+	}
+
+	// Latencies are preallocated in order to avoid counting the growth of the
+	// slice in the allocations of the components.
+	res := &benchResult{
+		latencies: make([]time.Duration, 0, opts.count),
+	}
+	payloads := benchPayloads(opts)
+
+	var memBefore, memAfter runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&memBefore)
+	start := time.Now()
+
+	// Consumes messages from the end of the components and acknowledges them.
+	consumerDone := make(chan struct{})
+	go func() {
+		defer close(consumerDone)
+		for tran := range nextTranChan {
+			now := time.Now()
+			_ = tran.Payload.Iter(func(i int, p types.Part) error {
+				if sent, err := strconv.ParseInt(p.Metadata().Get(benchSentKey), 10, 64); err == nil {
+					res.latencies = append(res.latencies, now.Sub(time.Unix(0, sent)))
+				}
+				return nil
+			})
+			res.messagesOut += tran.Payload.Len()
+			tran.ResponseChan <- response.NewAck()
+		}
+	}()
+
+	// Sends synthetic batches, with a bounded number of them awaiting a
+	// response at any given time.
+	var errsMut sync.Mutex
+	var pendingWG sync.WaitGroup
+	inFlight := make(chan struct{}, opts.inFlight)
+	for sent := 0; sent < opts.count; {
+		inFlight <- struct{}{}
+
+		msg := message.New(nil)
+		sentAt := strconv.FormatInt(time.Now().UnixNano(), 10)
+		for ; msg.Len() < opts.batchSize && sent < opts.count; sent++ {
+			part := message.NewPart(payloads(sent))
+			part.Metadata().Set(benchSentKey, sentAt)
+			res.bytesIn += len(part.Get())
+			msg.Append(part)
+		}
+		res.messagesIn += msg.Len()
+
+		resChan := make(chan types.Response)
+		tranChan <- types.NewTransaction(msg, resChan)
+
+		pendingWG.Add(1)
+		go func() {
+			defer pendingWG.Done()
+			if r := <-resChan; r.Error() != nil {
+				errsMut.Lock()
+				res.errors++
+				errsMut.Unlock()
+			}
+			<-inFlight
+		}()
+	}
+	pendingWG.Wait()
+
+	// Closing the input of the components results in them closing once they
+	// have been drained.
+	close(tranChan)
+	<-consumerDone
+
+	res.duration = time.Since(start)
+	runtime.ReadMemStats(&memAfter)
+	res.mallocs = memAfter.Mallocs - memBefore.Mallocs
+	res.allocBytes = memAfter.TotalAlloc - memBefore.TotalAlloc
+	res.gcCycles = memAfter.NumGC - memBefore.NumGC
+
+	sort.Slice(res.latencies, func(i, j int) bool {
+		return res.latencies[i] < res.latencies[j]
+	})
+	return res, nil
+}
+
+func benchCliCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "bench",
+		Usage: "Benchmark the buffer and processors of a config",
+		Description: `
+   Sends synthetic messages through the buffer and pipeline processors of a
+   config, ignoring its input and output, and reports the throughput, the
+   latency of messages from being sent until being received from the last
+   processor, and the memory allocated per message:
+
+   benthos -c ./config.yaml bench
+   benthos -c ./config.yaml bench --count 1000000 --size 512
+   benthos -c ./config.yaml bench --payload '{"doc":{"type":"foo"}}'
+
+   Unless a payload is specified each message is a JSON document containing a
+   numeric field id and a string field data of the specified size. Processors
+   that remove metadata from messages prevent their latency from being
+   measured.`[4:],
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "count",
+				Value: 100000,
+				Usage: "The number of messages to send.",
+			},
+			&cli.IntFlag{
+				Name:  "batch-size",
+				Value: 1,
+				Usage: "The number of messages sent in each batch.",
+			},
+			&cli.IntFlag{
+				Name:  "in-flight",
+				Value: 64,
+				Usage: "The maximum number of batches awaiting acknowledgement at any given time.",
+			},
+			&cli.IntFlag{
+				Name:  "size",
+				Value: 1024,
+				Usage: "The size in bytes of the data field of generated messages.",
+			},
+			&cli.StringFlag{
+				Name:  "payload",
+				Value: "",
+				Usage: "An optional static payload to send instead of generated messages.",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			readConfig(c.String("config"), c.StringSlice("resources"), c.StringSlice("set"))
+
+			res, err := runBench(conf, benchOptions{
+				count:     c.Int("count"),
+				batchSize: c.Int("batch-size"),
+				inFlight:  c.Int("in-flight"),
+				size:      c.Int("size"),
+				payload:   c.String("payload"),
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Benchmark error: %v\n", err)
+				os.Exit(1)
+			}
+			res.print()
+			os.Exit(0)
+			return nil
+		},
+	}
+}
+
+//------------------------------------------------------------------------------
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/buffer"
+	"github.com/Jeffail/benthos/v3/lib/config"
+	"github.com/Jeffail/benthos/v3/lib/processor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBenchPayloads(t *testing.T) {
+	payloads := benchPayloads(benchOptions{size: 5})
+	assert.Regexp(t, `^\{"id":3,"data":"[a-zA-Z0-9]{5}"\}$`, string(payloads(3)))
+
+	payloads = benchPayloads(benchOptions{size: 5, payload: "foo"})
+	assert.Equal(t, "foo", string(payloads(3)))
+}
+
+func TestBenchBufferAndProcessors(t *testing.T) {
+	conf := config.New()
+	conf.Buffer.Type = buffer.TypeMemory
+
+	procConf := processor.NewConfig()
+	procConf.Type = processor.TypeBloblang
+	procConf.Bloblang = `root = this.id`
+	conf.Pipeline.Processors = append(conf.Pipeline.Processors, procConf)
+
+	res, err := runBench(conf, benchOptions{
+		count:     1000,
+		batchSize: 10,
+		inFlight:  4,
+		size:      10,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1000, res.messagesIn)
+	assert.Equal(t, 1000, res.messagesOut)
+	assert.Equal(t, 0, res.errors)
+	assert.Len(t, res.latencies, 1000)
+	assert.True(t, res.latency(0.5) <= res.latency(0.99))
+	assert.True(t, res.latency(0.99) <= res.latency(1))
+}
+
+func TestBenchNoComponents(t *testing.T) {
+	res, err := runBench(config.New(), benchOptions{
+		count:     100,
+		batchSize: 1,
+		inFlight:  1,
+		payload:   "foo",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 100, res.messagesOut)
+	assert.Equal(t, 300, res.bytesIn)
+}
+
+func TestBenchBadOptions(t *testing.T) {
+	_, err := runBench(config.New(), benchOptions{count: 10})
+	assert.Error(t, err)
+}
@@ -122,6 +122,54 @@ func lintMDSnippets(path string) (pathLints []pathLint) {
 	return
 }
 
+// lintTarget lints a single config or markdown file, a panic while doing so is
+// reported as an error of that target rather than crashing the command.
+func lintTarget(target string, lintFn func(string) []pathLint) (lints []pathLint) {
+	defer func() {
+		if r := recover(); r != nil {
+			lints = append(lints, pathLint{
+				source: target,
+				err:    fmt.Sprintf("linter panicked: %v", r),
+			})
+		}
+	}()
+	return lintFn(target)
+}
+
+func lintTargets(targets []string) []pathLint {
+	var pathLintMut sync.Mutex
+	var pathLints []pathLint
+	threads := runtime.NumCPU()
+	var wg sync.WaitGroup
+	wg.Add(threads)
+	for i := 0; i < threads; i++ {
+		go func(threadID int) {
+			defer wg.Done()
+			for j, target := range targets {
+				if j%threads != threadID {
+					continue
+				}
+				if target == "" {
+					continue
+				}
+				var lints []pathLint
+				if path.Ext(target) == ".md" {
+					lints = lintTarget(target, lintMDSnippets)
+				} else {
+					lints = lintTarget(target, lintFile)
+				}
+				if len(lints) > 0 {
+					pathLintMut.Lock()
+					pathLints = append(pathLints, lints...)
+					pathLintMut.Unlock()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	return pathLints
+}
+
 func lintCliCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "lint",
@@ -155,7 +203,7 @@ func lintCliCommand() *cli.Command {
 						return nil
 					}); err != nil {
 						fmt.Fprintf(os.Stderr, "Filesystem walk error: %v\n", err)
-						os.Exit(1)
+						os.Exit(ExitCodeConfigError)
 					}
 				} else {
 					targets = append(targets, p)
@@ -165,38 +213,9 @@ func lintCliCommand() *cli.Command {
 				targets = append(targets, conf)
 			}
 
-			var pathLintMut sync.Mutex
-			var pathLints []pathLint
-			threads := runtime.NumCPU()
-			var wg sync.WaitGroup
-			wg.Add(threads)
-			for i := 0; i < threads; i++ {
-				go func(threadID int) {
-					defer wg.Done()
-					for j, target := range targets {
-						if j%threads != threadID {
-							continue
-						}
-						if target == "" {
-							continue
-						}
-						var lints []pathLint
-						if path.Ext(target) == ".md" {
-							lints = lintMDSnippets(target)
-						} else {
-							lints = lintFile(target)
-						}
-						if len(lints) > 0 {
-							pathLintMut.Lock()
-							pathLints = append(pathLints, lints...)
-							pathLintMut.Unlock()
-						}
-					}
-				}(i)
-			}
-			wg.Wait()
+			pathLints := lintTargets(targets)
 			if len(pathLints) == 0 {
-				os.Exit(0)
+				os.Exit(ExitCodeOK)
 			}
 			for _, lint := range pathLints {
 				message := yellow(lint.lint)
@@ -209,7 +228,7 @@ func lintCliCommand() *cli.Command {
 					fmt.Fprintf(os.Stderr, "%v: %v\n", lint.source, message)
 				}
 			}
-			os.Exit(1)
+			os.Exit(ExitCodeConfigError)
 			return nil
 		},
 	}
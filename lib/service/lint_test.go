@@ -0,0 +1,54 @@
+package service
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintTargets(t *testing.T) {
+	dir := t.TempDir()
+
+	goodPath := filepath.Join(dir, "good.yaml")
+	require.NoError(t, ioutil.WriteFile(goodPath, []byte(`
+input:
+  generate:
+    mapping: 'root = "foo"'
+output:
+  drop: {}
+`), 0644))
+
+	lintedPath := filepath.Join(dir, "linted.yaml")
+	require.NoError(t, ioutil.WriteFile(lintedPath, []byte(`
+input:
+  generate:
+    mapping: 'root = "foo"'
+    not_a_field: nope
+output:
+  drop: {}
+`), 0644))
+
+	assert.Empty(t, lintTargets([]string{goodPath}))
+
+	lints := lintTargets([]string{goodPath, lintedPath, filepath.Join(dir, "missing.yaml")})
+	require.Len(t, lints, 2)
+
+	sources := map[string]pathLint{}
+	for _, l := range lints {
+		sources[l.source] = l
+	}
+	assert.Contains(t, sources[lintedPath].lint, "not_a_field")
+	assert.NotEmpty(t, sources[filepath.Join(dir, "missing.yaml")].err)
+}
+
+func TestLintTargetPanic(t *testing.T) {
+	lints := lintTarget("foo.yaml", func(string) []pathLint {
+		panic("nope")
+	})
+	assert.Equal(t, []pathLint{
+		{source: "foo.yaml", err: "linter panicked: nope"},
+	}, lints)
+}
@@ -28,6 +28,7 @@ import (
 // Build stamps.
 var (
 	Version   string
+	Commit    string
 	DateBuilt string
 )
 
@@ -43,6 +44,16 @@ func OptSetVersionStamp(version, dateBuilt string) func() {
 	}
 }
 
+// OptSetCommitStamp creates an opt func for setting the git commit stamp that
+// Benthos returns via --version and the /version endpoint. The traditional way
+// of setting this value is via the build flag:
+// -X github.com/Jeffail/benthos/v3/lib/service.Commit=$(COMMIT)
+func OptSetCommitStamp(commit string) func() {
+	return func() {
+		Commit = commit
+	}
+}
+
 //------------------------------------------------------------------------------
 
 var customFlags []cli.Flag
@@ -87,7 +98,7 @@ func cmdVersion() {
 			}
 		}
 	}
-	fmt.Printf("Version: %v\nDate: %v\n", version, dateBuilt)
+	fmt.Printf("Version: %v\nCommit: %v\nDate: %v\n", version, Commit, dateBuilt)
 	os.Exit(0)
 }
 
@@ -150,6 +161,11 @@ func Run() {
 			Value: false,
 			Usage: "continue to execute a config containing linter errors",
 		},
+		&cli.BoolFlag{
+			Name:  "schema",
+			Value: false,
+			Usage: "print a JSON schema describing the full configuration, including all components, then exit",
+		},
 	}
 	if len(customFlags) > 0 {
 		flags = append(flags, customFlags...)
@@ -171,12 +187,12 @@ func Run() {
 				vars, err := parser.ParseDotEnvFile(dotEnvFile)
 				if err != nil {
 					fmt.Printf("Failed to read dotenv file: %v\n", err)
-					os.Exit(1)
+					os.Exit(ExitCodeConfigError)
 				}
 				for k, v := range vars {
 					if err = os.Setenv(k, v); err != nil {
 						fmt.Printf("Failed to set env var '%v': %v\n", k, err)
-						os.Exit(1)
+						os.Exit(ExitCodeConfigError)
 					}
 				}
 			}
@@ -184,19 +200,19 @@ func Run() {
 			templatesPaths, err := filepath.Globs(c.StringSlice("templates"))
 			if err != nil {
 				fmt.Printf("Failed to resolve template glob pattern: %v\n", err)
-				os.Exit(1)
+				os.Exit(ExitCodeConfigError)
 			}
 			lints, err := template.InitTemplates(templatesPaths...)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Template file read error: %v\n", err)
-				os.Exit(1)
+				os.Exit(ExitCodeConfigError)
 			}
 			if !c.Bool("chilled") && len(lints) > 0 {
 				for _, lint := range lints {
 					fmt.Fprintln(os.Stderr, lint)
 				}
 				fmt.Println("Shutting down due to linter errors, to prevent shutdown run Benthos with --chilled")
-				os.Exit(1)
+				os.Exit(ExitCodeConfigError)
 			}
 			return nil
 		},
@@ -204,10 +220,13 @@ func Run() {
 			if c.Bool("version") {
 				cmdVersion()
 			}
+			if c.Bool("schema") {
+				os.Exit(cmdSchema())
+			}
 			if c.Args().Len() > 0 {
 				fmt.Fprintf(os.Stderr, "Unrecognised command: %v\n", c.Args().First())
 				cli.ShowAppHelp(c)
-				os.Exit(1)
+				os.Exit(ExitCodeConfigError)
 			}
 			os.Exit(cmdService(
 				c.String("config"),
@@ -254,6 +273,7 @@ func Run() {
 				},
 			},
 			lintCliCommand(),
+			benchCliCommand(),
 			{
 				Name:  "streams",
 				Usage: "Run Benthos in streams mode",
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Jeffail/benthos/v3/internal/bundle"
+	"github.com/Jeffail/benthos/v3/internal/docs"
+	"github.com/Jeffail/benthos/v3/lib/config"
+)
+
+// cmdSchema prints a JSON schema describing the full config of the service,
+// including all registered components, and returns the exit code.
+func cmdSchema() int {
+	var components []docs.ComponentSpec
+	for _, specs := range [][]docs.ComponentSpec{
+		bundle.AllBuffers.Docs(),
+		bundle.AllCaches.Docs(),
+		bundle.AllInputs.Docs(),
+		bundle.AllMetrics.Docs(),
+		bundle.AllOutputs.Docs(),
+		bundle.AllProcessors.Docs(),
+		bundle.AllRateLimits.Docs(),
+		bundle.AllTracers.Docs(),
+	} {
+		components = append(components, specs...)
+	}
+
+	jsonBytes, err := json.Marshal(docs.ConfigJSONSchema(config.Spec(), components))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to generate config schema: %v\n", err)
+		return ExitCodeConfigError
+	}
+	fmt.Println(string(jsonBytes))
+	return ExitCodeOK
+}
@@ -7,28 +7,58 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"runtime/debug"
 	"runtime/pprof"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	iconfig "github.com/Jeffail/benthos/v3/internal/config"
 	"github.com/Jeffail/benthos/v3/internal/docs"
 	"github.com/Jeffail/benthos/v3/internal/filepath"
+	"github.com/Jeffail/benthos/v3/lib/alert"
 	"github.com/Jeffail/benthos/v3/lib/api"
 	"github.com/Jeffail/benthos/v3/lib/config"
+	"github.com/Jeffail/benthos/v3/lib/election"
 	"github.com/Jeffail/benthos/v3/lib/log"
 	"github.com/Jeffail/benthos/v3/lib/manager"
 	"github.com/Jeffail/benthos/v3/lib/metrics"
 	"github.com/Jeffail/benthos/v3/lib/stream"
 	strmmgr "github.com/Jeffail/benthos/v3/lib/stream/manager"
 	"github.com/Jeffail/benthos/v3/lib/tracer"
+	"github.com/Jeffail/benthos/v3/lib/tuning"
 	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/opentracing/opentracing-go"
 	"gopkg.in/yaml.v3"
 )
 
 //------------------------------------------------------------------------------
 
+// Exit codes returned by the Benthos service, which allow process supervisors
+// to distinguish a service that will fail again if restarted from one that
+// encountered a problem whilst running.
+const (
+	// ExitCodeOK is returned when the service shuts down cleanly, either due
+	// to a termination signal or because the pipeline has finished.
+	ExitCodeOK = 0
+
+	// ExitCodeConfigError is returned when the service fails to start due to
+	// an invalid config or a component that could not be constructed.
+	ExitCodeConfigError = 1
+
+	// ExitCodeRuntimeError is returned when the service terminates due to a
+	// fatal error encountered after it has started, such as the HTTP server
+	// failing or a panic.
+	ExitCodeRuntimeError = 2
+
+	// ExitCodeShutdownTimeout is returned when the service fails to shut down
+	// cleanly within the configured shutdown timeout.
+	ExitCodeShutdownTimeout = 3
+)
+
+//------------------------------------------------------------------------------
+
 var conf = config.New()
 var testSuffix = "_benthos_test"
 
@@ -95,6 +125,15 @@ func OptOnManagerInit(fn ManagerInitFunc) func() {
 //------------------------------------------------------------------------------
 
 func readConfig(path string, resourcesPaths, overrides []string) (lints []string) {
+	var err error
+	if lints, err = loadConfig(path, resourcesPaths, overrides); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration file read error: %v\n", err)
+		os.Exit(ExitCodeConfigError)
+	}
+	return
+}
+
+func loadConfig(path string, resourcesPaths, overrides []string) (lints []string, err error) {
 	if path == "" {
 		// Iterate default config paths
 		for _, dpath := range []string{
@@ -110,12 +149,7 @@ func readConfig(path string, resourcesPaths, overrides []string) (lints []string
 		}
 	}
 
-	var err error
-	if lints, err = iconfig.NewReader(path, resourcesPaths, iconfig.OptAddOverrides(overrides...)).Read(&conf); err != nil {
-		fmt.Fprintf(os.Stderr, "Configuration file read error: %v\n", err)
-		os.Exit(1)
-	}
-	return
+	return iconfig.NewReader(path, resourcesPaths, iconfig.OptAddOverrides(overrides...)).Read(&conf)
 }
 
 //------------------------------------------------------------------------------
@@ -128,19 +162,37 @@ func cmdService(
 	strict bool,
 	streamsMode bool,
 	streamsConfigs []string,
-) int {
+) (exitCode int) {
+	// Panics that occur on this goroutine are reported with a distinct exit
+	// code depending on whether the service had finished starting up.
+	started := false
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "Service panicked: %v\n%s\n", r, debug.Stack())
+			if started {
+				exitCode = ExitCodeRuntimeError
+			} else {
+				exitCode = ExitCodeConfigError
+			}
+		}
+	}()
+
 	var err error
 	if resourcesPaths, err = filepath.Globs(resourcesPaths); err != nil {
 		fmt.Printf("Failed to resolve resource glob pattern: %v\n", err)
-		return 1
+		return ExitCodeConfigError
+	}
+	lints, err := loadConfig(confPath, resourcesPaths, confOverrides)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration file read error: %v\n", err)
+		return ExitCodeConfigError
 	}
-	lints := readConfig(confPath, resourcesPaths, confOverrides)
 	if strict && len(lints) > 0 {
 		for _, lint := range lints {
 			fmt.Fprintln(os.Stderr, lint)
 		}
 		fmt.Println("Shutting down due to linter errors, to prevent shutdown run Benthos with --chilled")
-		return 1
+		return ExitCodeConfigError
 	}
 
 	if len(overrideLogLevel) > 0 {
@@ -159,7 +211,7 @@ func cmdService(
 	}
 	if err != nil {
 		fmt.Printf("Failed to create logger: %v\n", err)
-		return 1
+		return ExitCodeConfigError
 	}
 
 	if len(lints) > 0 {
@@ -169,6 +221,9 @@ func cmdService(
 		}
 	}
 
+	// Tune the Go runtime before any components are created.
+	defer tuning.Apply(conf.Runtime, logger.NewModule(".runtime"))()
+
 	// Create our metrics type.
 	var stats metrics.Type
 	stats, err = metrics.New(conf.Metrics, metrics.OptSetLogger(logger))
@@ -183,11 +238,24 @@ func cmdService(
 		}
 	}()
 
+	// Monitor our metrics for alerts.
+	var alertMonitor *alert.Monitor
+	if conf.Alerts.Enabled() {
+		if alertMonitor, err = alert.NewMonitor(conf.Alerts, stats, logger.NewModule(".alerts")); err != nil {
+			logger.Errorf("Failed to initialise alerts: %v\n", err)
+			return ExitCodeConfigError
+		}
+		stats = alertMonitor
+	}
+
+	// Report runtime statistics such as the number of goroutines.
+	defer metrics.ReportRuntime(stats, time.Second*5)()
+
 	// Create our tracer type.
 	var trac tracer.Type
 	if trac, err = tracer.New(conf.Tracer); err != nil {
 		logger.Errorf("Failed to initialise tracer: %v\n", err)
-		return 1
+		return ExitCodeConfigError
 	}
 	defer trac.Close()
 
@@ -203,24 +271,43 @@ func cmdService(
 		logger.Warnf("Failed to generate sanitised config: %v\n", err)
 	}
 	var httpServer *api.Type
-	if httpServer, err = api.New(Version, DateBuilt, conf.HTTP, sanitNode, logger, stats, apiOpts...); err != nil {
+	httpOpts := append([]api.OptFunc{api.OptWithCommit(Commit)}, apiOpts...)
+	if httpServer, err = api.New(Version, DateBuilt, conf.HTTP, sanitNode, logger, stats, httpOpts...); err != nil {
 		logger.Errorf("Failed to initialise API: %v\n", err)
-		return 1
+		return ExitCodeConfigError
+	}
+	if conf.HTTP.DebugEndpoints {
+		debugTracer := tracer.NewDebug(opentracing.GlobalTracer(), logger.NewModule(".debug_trace"))
+		opentracing.SetGlobalTracer(debugTracer)
+		httpServer.RegisterEndpoint(
+			"/debug/trace", "DEBUG: Logs each component that the next messages pass through along with timings. The number of messages to trace is set with the query parameter count, defaulting to 1.",
+			debugTracer.HandlerFunc(),
+		)
 	}
 
 	// Create resource manager.
 	manager, err := manager.NewV2(conf.ResourceConfig, httpServer, logger, stats)
 	if err != nil {
 		logger.Errorf("Failed to create resource: %v\n", err)
-		return 1
+		return ExitCodeConfigError
 	}
 	if err = onManagerInit(manager, logger, stats); err != nil {
 		logger.Errorf("Failed to initialise manager: %v\n", err)
-		return 1
+		return ExitCodeConfigError
+	}
+	if alertMonitor != nil {
+		if err = alertMonitor.Start(manager); err != nil {
+			logger.Errorf("Failed to start alerts: %v\n", err)
+			return ExitCodeConfigError
+		}
 	}
 
 	var dataStream stoppableStreams
+	var elector *election.Elector
 	dataStreamClosedChan := make(chan struct{})
+	dataStreamIdleChan := make(chan struct{})
+	dataStreamStalledChan := make(chan struct{})
+	dataStreamHandoffChan := make(chan struct{})
 
 	strmAPITimeout := 5 * time.Second
 	if cTout := conf.HTTP.ReadTimeout; cTout != "" {
@@ -243,7 +330,7 @@ func cmdService(
 			lints, err := strmmgr.LoadStreamConfigsFromPath(path, testSuffix, streamConfs)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Failed to load stream configs: %v\n", err)
-				return 1
+				return ExitCodeConfigError
 			}
 			streamLints = append(streamLints, lints...)
 		}
@@ -253,7 +340,7 @@ func cmdService(
 				fmt.Fprintln(os.Stderr, lint)
 			}
 			fmt.Println("Shutting down due to linter errors, to prevent shutdown run Benthos with --chilled")
-			return 1
+			return ExitCodeConfigError
 		} else if len(streamLints) > 0 {
 			lintlog := logger.NewModule(".linter")
 			for _, lint := range streamLints {
@@ -265,47 +352,115 @@ func cmdService(
 		for id, conf := range streamConfs {
 			if err = streamMgr.Create(id, conf); err != nil {
 				logger.Errorf("Failed to create stream (%v): %v\n", id, err)
-				return 1
+				return ExitCodeConfigError
 			}
 		}
 		logger.Infoln("Launching benthos in streams mode, use CTRL+C to close.")
 	} else {
-		if dataStream, err = stream.New(
-			conf.Config,
+		streamOpts := []func(*stream.Type){
 			stream.OptSetLogger(logger),
 			stream.OptSetStats(stats),
 			stream.OptSetManager(manager),
 			stream.OptOnClose(func() {
 				close(dataStreamClosedChan)
 			}),
-		); err != nil {
+		}
+		if conf.ShutdownAfterIdle != "" {
+			idleTimeout, err := time.ParseDuration(conf.ShutdownAfterIdle)
+			if err != nil {
+				logger.Errorf("Failed to parse shutdown after idle period string: %v\n", err)
+				return ExitCodeConfigError
+			}
+			streamOpts = append(streamOpts, stream.OptOnIdle(idleTimeout, func() {
+				close(dataStreamIdleChan)
+			}))
+		}
+		if conf.Watchdog.StallTimeout != "" {
+			stallTimeout, err := time.ParseDuration(conf.Watchdog.StallTimeout)
+			if err != nil {
+				logger.Errorf("Failed to parse watchdog stall timeout period string: %v\n", err)
+				return ExitCodeConfigError
+			}
+			var onStall func()
+			switch conf.Watchdog.Action {
+			case stream.WatchdogActionLog:
+			case stream.WatchdogActionExit:
+				var stallOnce sync.Once
+				onStall = func() {
+					stallOnce.Do(func() {
+						close(dataStreamStalledChan)
+					})
+				}
+			default:
+				logger.Errorf("Watchdog action not recognised: %v\n", conf.Watchdog.Action)
+				return ExitCodeConfigError
+			}
+			streamOpts = append(streamOpts, stream.OptOnStall(stallTimeout, onStall))
+		}
+		if conf.BufferHandoff.Enabled() {
+			var handoffOnce sync.Once
+			streamOpts = append(streamOpts, stream.OptBufferHandoff(conf.BufferHandoff, func() {
+				handoffOnce.Do(func() {
+					close(dataStreamHandoffChan)
+				})
+			}))
+		}
+		if conf.Audit.Enabled() {
+			streamOpts = append(streamOpts, stream.OptAudit(conf.Audit))
+		}
+		if conf.LeaderElection.Enabled() {
+			if elector, err = election.NewElector(conf.LeaderElection, logger.NewModule(".leader_election")); err != nil {
+				logger.Errorf("Failed to initialise leader election: %v\n", err)
+				return ExitCodeConfigError
+			}
+			streamOpts = append(streamOpts, stream.OptPausableInput(true))
+		}
+		strm, err := stream.New(conf.Config, streamOpts...)
+		if err != nil {
 			logger.Errorf("Service closing due to: %v\n", err)
-			return 1
+			return ExitCodeConfigError
+		}
+		dataStream = strm
+		if elector != nil {
+			logger.Infoln("Input paused until leadership is acquired.")
+			elector.Start(func(leading bool) {
+				if leading {
+					strm.ResumeInput()
+				} else {
+					strm.PauseInput()
+				}
+			})
 		}
 		logger.Infoln("Launching a benthos instance, use CTRL+C to close.")
 	}
 
+	var exitTimeout time.Duration
+	if tout := conf.SystemCloseTimeout; len(tout) > 0 {
+		var err error
+		if exitTimeout, err = time.ParseDuration(tout); err != nil {
+			logger.Errorf("Failed to parse shutdown timeout period string: %v\n", err)
+			return ExitCodeConfigError
+		}
+	}
+
 	// Start HTTP server.
+	var httpServerErr error
 	httpServerClosedChan := make(chan struct{})
 	go func() {
 		httpErr := httpServer.ListenAndServe()
 		if httpErr != nil && httpErr != http.ErrServerClosed {
 			logger.Errorf("HTTP Server error: %v\n", httpErr)
+			httpServerErr = httpErr
 		}
 		close(httpServerClosedChan)
 	}()
-
-	var exitTimeout time.Duration
-	if tout := conf.SystemCloseTimeout; len(tout) > 0 {
-		var err error
-		if exitTimeout, err = time.ParseDuration(tout); err != nil {
-			logger.Errorf("Failed to parse shutdown timeout period string: %v\n", err)
-			return 1
-		}
-	}
+	started = true
 
 	// Defer clean up.
 	defer func() {
+		cleanedUpChan := make(chan struct{})
+		defer close(cleanedUpChan)
+
 		go func() {
 			httpServer.Shutdown(context.Background())
 			select {
@@ -316,18 +471,31 @@ func cmdService(
 		}()
 
 		go func() {
-			<-time.After(exitTimeout + time.Second)
+			select {
+			case <-cleanedUpChan:
+				return
+			case <-time.After(exitTimeout + time.Second):
+			}
 			logger.Warnln(
 				"Service failed to close cleanly within allocated time." +
 					" Exiting forcefully and dumping stack trace to stderr.",
 			)
 			pprof.Lookup("goroutine").WriteTo(os.Stderr, 1)
-			os.Exit(1)
+			os.Exit(ExitCodeShutdownTimeout)
 		}()
 
 		timesOut := time.Now().Add(exitTimeout)
+		if elector != nil {
+			if err := elector.Close(); err != nil {
+				logger.Warnf("Failed to release leadership: %v\n", err)
+			}
+		}
 		if err := dataStream.Stop(exitTimeout); err != nil {
-			os.Exit(1)
+			logger.Warnf("Service failed to close pipeline cleanly: %v\n", err)
+			if exitCode == ExitCodeOK {
+				exitCode = ExitCodeShutdownTimeout
+			}
+			return
 		}
 		manager.CloseAsync()
 		if err := manager.WaitForClose(time.Until(timesOut)); err != nil {
@@ -336,7 +504,9 @@ func cmdService(
 					" Exiting forcefully and dumping stack trace to stderr.\n", err,
 			)
 			pprof.Lookup("goroutine").WriteTo(os.Stderr, 1)
-			os.Exit(1)
+			if exitCode == ExitCodeOK {
+				exitCode = ExitCodeShutdownTimeout
+			}
 		}
 	}()
 
@@ -349,12 +519,23 @@ func cmdService(
 		logger.Infoln("Received SIGTERM, the service is closing.")
 	case <-dataStreamClosedChan:
 		logger.Infoln("Pipeline has terminated. Shutting down the service.")
+	case <-dataStreamIdleChan:
+		logger.Infof("Pipeline has been idle for %v. Shutting down the service.\n", conf.ShutdownAfterIdle)
+	case <-dataStreamHandoffChan:
+		logger.Infoln("Buffer handoff was requested. Shutting down the service.")
+	case <-dataStreamStalledChan:
+		logger.Errorln("Pipeline has stalled. Shutting down the service.")
+		return ExitCodeRuntimeError
 	case <-httpServerClosedChan:
+		if httpServerErr != nil {
+			logger.Errorln("HTTP Server has failed. Shutting down the service.")
+			return ExitCodeRuntimeError
+		}
 		logger.Infoln("HTTP Server has terminated. Shutting down the service.")
 	case <-optContext.Done():
 		logger.Infoln("Run context was cancelled. Shutting down the service.")
 	}
-	return 0
+	return ExitCodeOK
 }
 
 //------------------------------------------------------------------------------
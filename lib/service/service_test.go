@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/config"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/processor"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeServiceTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+// runServiceTest runs cmdService against a fresh config until it exits, ctx is
+// used as the run context.
+func runServiceTest(t *testing.T, ctx context.Context, confPath string, streamsConfigs []string) int {
+	t.Helper()
+
+	conf = config.New()
+	tmpCtx := optContext
+	optContext = ctx
+	t.Cleanup(func() { optContext = tmpCtx })
+
+	exitCodeChan := make(chan int, 1)
+	go func() {
+		exitCodeChan <- cmdService(confPath, nil, nil, "none", true, len(streamsConfigs) > 0, streamsConfigs)
+	}()
+	select {
+	case exitCode := <-exitCodeChan:
+		return exitCode
+	case <-time.After(time.Second * 30):
+		t.Fatal("timed out waiting for the service to exit")
+	}
+	return -1
+}
+
+func TestServiceExitCodeOK(t *testing.T) {
+	dir := t.TempDir()
+	confPath := writeServiceTestFile(t, dir, "config.yaml", `
+http:
+  address: 127.0.0.1:0
+input:
+  generate:
+    count: 1
+    interval: ""
+    mapping: 'root = "foo"'
+output:
+  drop: {}
+`)
+
+	assert.Equal(t, ExitCodeOK, runServiceTest(t, context.Background(), confPath, nil))
+}
+
+func TestServiceExitCodeStartup(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.Equal(t, ExitCodeConfigError, runServiceTest(t, context.Background(), filepath.Join(dir, "missing.yaml"), nil))
+
+	lintedPath := writeServiceTestFile(t, dir, "linted.yaml", `
+http:
+  address: 127.0.0.1:0
+input:
+  generate:
+    mapping: 'root = "foo"'
+    not_a_field: nope
+output:
+  drop: {}
+`)
+	assert.Equal(t, ExitCodeConfigError, runServiceTest(t, context.Background(), lintedPath, nil))
+
+	badPath := writeServiceTestFile(t, dir, "bad.yaml", `
+http:
+  address: 127.0.0.1:0
+input:
+  generate:
+    mapping: 'root = '
+output:
+  drop: {}
+`)
+	assert.Equal(t, ExitCodeConfigError, runServiceTest(t, context.Background(), badPath, nil))
+}
+
+func TestServiceExitCodeStartupPanic(t *testing.T) {
+	dir := t.TempDir()
+	confPath := writeServiceTestFile(t, dir, "config.yaml", `
+http:
+  address: 127.0.0.1:0
+input:
+  generate:
+    mapping: 'root = "foo"'
+output:
+  drop: {}
+`)
+
+	tmpInit := onManagerInit
+	onManagerInit = func(types.Manager, log.Modular, metrics.Type) error {
+		panic("nope")
+	}
+	t.Cleanup(func() { onManagerInit = tmpInit })
+
+	assert.Equal(t, ExitCodeConfigError, runServiceTest(t, context.Background(), confPath, nil))
+}
+
+func TestServiceExitCodeRuntime(t *testing.T) {
+	// The HTTP server fails once the service has started as its address is
+	// already taken.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	dir := t.TempDir()
+	confPath := writeServiceTestFile(t, dir, "config.yaml", `
+http:
+  address: `+ln.Addr().String()+`
+input:
+  generate:
+    interval: 1s
+    mapping: 'root = "foo"'
+output:
+  drop: {}
+`)
+
+	assert.Equal(t, ExitCodeRuntimeError, runServiceTest(t, context.Background(), confPath, nil))
+}
+
+// blockingProc is a processor that blocks until unblocked regardless of being
+// closed, and therefore prevents a pipeline from shutting down in time.
+type blockingProc struct {
+	blocked func()
+	unblock <-chan struct{}
+}
+
+func (b *blockingProc) ProcessMessage(msg types.Message) ([]types.Message, types.Response) {
+	b.blocked()
+	<-b.unblock
+	return []types.Message{msg}, nil
+}
+
+func (b *blockingProc) CloseAsync() {}
+
+func (b *blockingProc) WaitForClose(time.Duration) error {
+	return nil
+}
+
+func TestServiceExitCodeShutdownTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+
+	// The service is shut down once a message is stuck in the pipeline.
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	processor.RegisterPlugin("service_test_blocking", nil, func(interface{}, types.Manager, log.Modular, metrics.Type) (types.Processor, error) {
+		return &blockingProc{blocked: done, unblock: unblock}, nil
+	})
+
+	dir := t.TempDir()
+	confPath := writeServiceTestFile(t, dir, "config.yaml", `
+http:
+  address: 127.0.0.1:0
+shutdown_timeout: 10ms
+input:
+  generate:
+    interval: 1ms
+    mapping: 'root = "foo"'
+pipeline:
+  processors:
+    - plugin: {}
+      type: service_test_blocking
+output:
+  drop: {}
+`)
+
+	assert.Equal(t, ExitCodeShutdownTimeout, runServiceTest(t, ctx, confPath, nil))
+}
+
+func TestServiceStreamsModeExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	confPath := writeServiceTestFile(t, dir, "config.yaml", `
+http:
+  address: 127.0.0.1:0
+`)
+
+	goodDir := filepath.Join(dir, "good")
+	require.NoError(t, os.Mkdir(goodDir, 0755))
+	writeServiceTestFile(t, goodDir, "foo.yaml", `
+input:
+  generate:
+    interval: 1s
+    mapping: 'root = "foo"'
+output:
+  drop: {}
+`)
+	ctx, done := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer done()
+	assert.Equal(t, ExitCodeOK, runServiceTest(t, ctx, confPath, []string{goodDir}))
+
+	lintedDir := filepath.Join(dir, "linted")
+	require.NoError(t, os.Mkdir(lintedDir, 0755))
+	writeServiceTestFile(t, lintedDir, "foo.yaml", `
+input:
+  generate:
+    mapping: 'root = "foo"'
+    not_a_field: nope
+output:
+  drop: {}
+`)
+	assert.Equal(t, ExitCodeConfigError, runServiceTest(t, context.Background(), confPath, []string{lintedDir}))
+
+	badDir := filepath.Join(dir, "bad")
+	require.NoError(t, os.Mkdir(badDir, 0755))
+	writeServiceTestFile(t, badDir, "foo.yaml", `
+input:
+  generate:
+    mapping: 'root = '
+output:
+  drop: {}
+`)
+	assert.Equal(t, ExitCodeConfigError, runServiceTest(t, context.Background(), confPath, []string{badDir}))
+}
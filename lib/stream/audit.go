@@ -0,0 +1,328 @@
+package stream
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/docs"
+	"github.com/Jeffail/benthos/v3/internal/interop"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/output"
+	"github.com/Jeffail/benthos/v3/lib/types"
+)
+
+//------------------------------------------------------------------------------
+
+// AuditConfig contains configuration fields for recording a summary of each
+// message sent to the output of a stream.
+type AuditConfig struct {
+	Path           string `json:"path" yaml:"path"`
+	MaxSize        int64  `json:"max_size" yaml:"max_size"`
+	MaxFiles       int    `json:"max_files" yaml:"max_files"`
+	OutputResource string `json:"output_resource" yaml:"output_resource"`
+}
+
+// NewAuditConfig creates a new AuditConfig with default values.
+func NewAuditConfig() AuditConfig {
+	return AuditConfig{
+		Path:           "",
+		MaxSize:        100 * 1024 * 1024,
+		MaxFiles:       5,
+		OutputResource: "",
+	}
+}
+
+// Enabled returns true if audit records are written to either a file or an
+// output resource.
+func (c AuditConfig) Enabled() bool {
+	return c.Path != "" || c.OutputResource != ""
+}
+
+// AuditSpec returns a field spec for the audit configuration fields.
+func AuditSpec() docs.FieldSpecs {
+	return docs.FieldSpecs{
+		docs.FieldString("path", "The path of a file that audit records are appended to, one JSON object per line. When empty records are not written to a file.", "./audit.log").HasDefault(""),
+		docs.FieldInt("max_size", "The size in bytes that the file reaches before it is rotated, at which point it is renamed with the suffix `.1`, and any previously rotated files have their suffix incremented.").HasDefault(100 * 1024 * 1024),
+		docs.FieldInt("max_files", "The maximum number of rotated files to keep, the oldest of which are deleted.").HasDefault(5),
+		docs.FieldString("output_resource", "The name of an [output resource](/docs/configuration/resources) that audit records are written to, each record as a message. When empty records are not written to an output.").HasDefault(""),
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// auditRecord is a summary of the outcome of delivering a message part.
+type auditRecord struct {
+	Timestamp    string   `json:"timestamp"`
+	Size         int      `json:"size"`
+	Hash         string   `json:"hash"`
+	Source       string   `json:"source"`
+	Destinations []string `json:"destinations"`
+	Outcome      string   `json:"outcome"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// auditor records a summary of each message part sent to the output layer of a
+// stream once the output has responded, which is written before the response
+// is passed upstream so that an acknowledged message always has a record.
+type auditor struct {
+	source       string
+	destinations []string
+
+	file   *rotatingFile
+	output string
+	mgr    types.Manager
+	log    log.Modular
+}
+
+func newAuditor(conf AuditConfig, source string, destinations []string, mgr types.Manager, log log.Modular) (*auditor, error) {
+	a := &auditor{
+		source:       source,
+		destinations: destinations,
+		output:       conf.OutputResource,
+		mgr:          mgr,
+		log:          log,
+	}
+	if conf.Path != "" {
+		if conf.MaxSize <= 0 {
+			return nil, errors.New("audit max_size must be greater than zero")
+		}
+		var err error
+		if a.file, err = newRotatingFile(conf.Path, conf.MaxSize, conf.MaxFiles); err != nil {
+			return nil, fmt.Errorf("failed to open audit file: %w", err)
+		}
+	}
+	if a.output != "" {
+		if err := interop.ProbeOutput(context.Background(), mgr, a.output); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// auditSource returns the name of the input of a stream as it appears within
+// audit records.
+func auditSource(conf Config) string {
+	if conf.Input.Label != "" {
+		return conf.Input.Label
+	}
+	return conf.Input.Type
+}
+
+// auditDestinations returns the names of the outputs of a stream as they
+// appear within audit records, which are the child outputs of brokers and
+// switches.
+func auditDestinations(conf output.Config) []string {
+	var children []output.Config
+	switch conf.Type {
+	case output.TypeBroker:
+		children = conf.Broker.Outputs
+	case output.TypeSwitch:
+		for _, c := range conf.Switch.Cases {
+			children = append(children, c.Output)
+		}
+	}
+	if len(children) == 0 {
+		if conf.Label != "" {
+			return []string{conf.Label}
+		}
+		return []string{conf.Type}
+	}
+	var names []string
+	for _, c := range children {
+		names = append(names, auditDestinations(c)...)
+	}
+	return names
+}
+
+func (a *auditor) records(msg types.Message, res types.Response) [][]byte {
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	outcome, errStr := "delivered", ""
+	if err := res.Error(); err != nil {
+		outcome, errStr = "failed", err.Error()
+	}
+	lines := make([][]byte, 0, msg.Len())
+	_ = msg.Iter(func(i int, p types.Part) error {
+		sum := sha256.Sum256(p.Get())
+		line, err := json.Marshal(auditRecord{
+			Timestamp:    now,
+			Size:         len(p.Get()),
+			Hash:         "sha256:" + hex.EncodeToString(sum[:]),
+			Source:       a.source,
+			Destinations: a.destinations,
+			Outcome:      outcome,
+			Error:        errStr,
+		})
+		if err == nil {
+			lines = append(lines, line)
+		}
+		return nil
+	})
+	return lines
+}
+
+func (a *auditor) record(msg types.Message, res types.Response) {
+	lines := a.records(msg, res)
+	if a.file != nil {
+		if err := a.file.writeLines(lines); err != nil {
+			a.log.Errorf("Failed to write audit records: %v\n", err)
+		}
+	}
+	if a.output != "" {
+		if err := a.writeOutput(lines); err != nil {
+			a.log.Errorf("Failed to write audit records to output resource '%v': %v\n", a.output, err)
+		}
+	}
+}
+
+func (a *auditor) writeOutput(lines [][]byte) error {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	resChan := make(chan types.Response, 1)
+
+	var err error
+	if aerr := interop.AccessOutput(ctx, a.mgr, a.output, func(o types.OutputWriter) {
+		err = o.WriteTransaction(ctx, types.NewTransaction(message.New(lines), resChan))
+	}); aerr != nil {
+		return aerr
+	}
+	if err != nil {
+		return err
+	}
+
+	select {
+	case res := <-resChan:
+		return res.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// observe returns a hook for the transactions sent to the output layer, which
+// records each once a response has been received.
+func (a *auditor) observe() tranHook {
+	return tranHook{
+		resolved: func(tran types.Transaction, res types.Response) {
+			a.record(tran.Payload, res)
+		},
+	}
+}
+
+func (a *auditor) close() {
+	if a.file != nil {
+		if err := a.file.close(); err != nil {
+			a.log.Errorf("Failed to close audit file: %v\n", err)
+		}
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// rotatingFile appends to a file, renaming it with an incrementing numerical
+// suffix once it reaches a maximum size.
+type rotatingFile struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mut    sync.Mutex
+	file   *os.File
+	size   int64
+	closed bool
+}
+
+func newRotatingFile(path string, maxSize int64, maxFiles int) (*rotatingFile, error) {
+	r := &rotatingFile{
+		path:     path,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file, r.size = f, info.Size()
+	return nil
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.file = nil
+	if r.maxFiles <= 0 {
+		if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return r.open()
+	}
+	_ = os.Remove(fmt.Sprintf("%v.%v", r.path, r.maxFiles))
+	for i := r.maxFiles - 1; i > 0; i-- {
+		if err := os.Rename(fmt.Sprintf("%v.%v", r.path, i), fmt.Sprintf("%v.%v", r.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) writeLines(lines [][]byte) error {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if r.closed {
+		return types.ErrTypeClosed
+	}
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return err
+		}
+	}
+	for _, line := range lines {
+		if r.size > 0 && r.size+int64(len(line))+1 > r.maxSize {
+			if err := r.rotate(); err != nil {
+				return err
+			}
+		}
+		n, err := r.file.Write(append(line, '\n'))
+		r.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *rotatingFile) close() error {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	r.closed = true
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
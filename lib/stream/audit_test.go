@@ -0,0 +1,141 @@
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/output"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAuditRecords(t *testing.T, path string) []auditRecord {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var records []auditRecord
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var r auditRecord
+		require.NoError(t, json.Unmarshal([]byte(line), &r))
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestAuditFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	conf := NewAuditConfig()
+	conf.Path = path
+
+	a, err := newAuditor(conf, "foo_input", []string{"bar", "baz"}, types.NoopMgr(), log.Noop())
+	require.NoError(t, err)
+
+	in := make(chan types.Transaction)
+	obs := newTranObserver()
+	defer obs.close()
+	out := obs.tap(in, a.observe())
+
+	send := func(res types.Response, parts ...string) types.Response {
+		var contents [][]byte
+		for _, p := range parts {
+			contents = append(contents, []byte(p))
+		}
+		resChan := make(chan types.Response)
+		in <- types.NewTransaction(message.New(contents), resChan)
+		tran := <-out
+		tran.ResponseChan <- res
+		return <-resChan
+	}
+
+	assert.NoError(t, send(response.NewAck(), "hello", "world!").Error())
+	assert.EqualError(t, send(response.NewError(errors.New("nope")), "hello").Error(), "nope")
+
+	close(in)
+	a.close()
+
+	records := readAuditRecords(t, path)
+	require.Len(t, records, 3)
+
+	assert.Equal(t, 5, records[0].Size)
+	assert.Equal(t, "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", records[0].Hash)
+	assert.Equal(t, "foo_input", records[0].Source)
+	assert.Equal(t, []string{"bar", "baz"}, records[0].Destinations)
+	assert.Equal(t, "delivered", records[0].Outcome)
+	assert.Empty(t, records[0].Error)
+	assert.NotEmpty(t, records[0].Timestamp)
+
+	assert.Equal(t, 6, records[1].Size)
+	assert.Equal(t, "delivered", records[1].Outcome)
+
+	assert.Equal(t, records[0].Hash, records[2].Hash)
+	assert.Equal(t, "failed", records[2].Outcome)
+	assert.Equal(t, "nope", records[2].Error)
+}
+
+func TestAuditFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	r, err := newRotatingFile(path, 10, 2)
+	require.NoError(t, err)
+
+	for _, line := range []string{"aaaa", "bbbb", "cccc", "dddd", "eeee"} {
+		require.NoError(t, r.writeLines([][]byte{[]byte(line)}))
+	}
+	require.NoError(t, r.close())
+	assert.Error(t, r.writeLines([][]byte{[]byte("ffff")}))
+
+	for suffix, exp := range map[string]string{
+		"":   "eeee\n",
+		".1": "cccc\ndddd\n",
+		".2": "aaaa\nbbbb\n",
+	} {
+		b, err := ioutil.ReadFile(path + suffix)
+		require.NoError(t, err)
+		assert.Equal(t, exp, string(b), suffix)
+	}
+
+	// Rotated files beyond the maximum are deleted.
+	r, err = newRotatingFile(path, 10, 2)
+	require.NoError(t, err)
+	require.NoError(t, r.writeLines([][]byte{[]byte("ffff"), []byte("gggg")}))
+	require.NoError(t, r.close())
+
+	b, err := ioutil.ReadFile(path + ".2")
+	require.NoError(t, err)
+	assert.Equal(t, "cccc\ndddd\n", string(b))
+
+	matches, err := filepath.Glob(path + "*")
+	require.NoError(t, err)
+	assert.Len(t, matches, 3)
+}
+
+func TestAuditDestinations(t *testing.T) {
+	conf := output.NewConfig()
+	conf.Type = output.TypeBroker
+
+	child := output.NewConfig()
+	child.Type = output.TypeSTDOUT
+	conf.Broker.Outputs = append(conf.Broker.Outputs, child)
+
+	child = output.NewConfig()
+	child.Type = output.TypeFile
+	child.Label = "archive"
+	conf.Broker.Outputs = append(conf.Broker.Outputs, child)
+
+	assert.Equal(t, []string{"stdout", "archive"}, auditDestinations(conf))
+
+	conf = output.NewConfig()
+	conf.Type = output.TypeDrop
+	assert.Equal(t, []string{"drop"}, auditDestinations(conf))
+}
@@ -0,0 +1,170 @@
+package stream
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/broker"
+	"github.com/Jeffail/benthos/v3/lib/input"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/metrics"
+	"github.com/Jeffail/benthos/v3/lib/types"
+)
+
+//------------------------------------------------------------------------------
+
+const pausableInputIdent = "input"
+
+// pausedInputDrainTimeout is the period of time that the messages already
+// consumed from a paused input have to be acknowledged before closing the
+// input is logged as failed and retried.
+const pausedInputDrainTimeout = time.Second * 30
+
+// pausableInput is an input layer that only runs its input while resumed. The
+// input is built when resumed and closed when paused, so that a paused input
+// holds no resources such as consumer group memberships or listening ports.
+// Before a paused input is closed the messages it has already sent are given
+// the chance to be acknowledged.
+type pausableInput struct {
+	ctor         func() (input.Type, error)
+	drainTimeout time.Duration
+	log          log.Modular
+	fanIn        *broker.DynamicFanIn
+
+	mut     sync.Mutex
+	paused  bool
+	current input.Type
+	changed chan struct{}
+
+	closeOnce sync.Once
+	closeChan chan struct{}
+	loopDone  chan struct{}
+}
+
+func newPausableInput(
+	ctor func() (input.Type, error),
+	paused bool,
+	drainTimeout time.Duration,
+	log log.Modular,
+) (*pausableInput, error) {
+	fanIn, err := broker.NewDynamicFanIn(nil, log, metrics.Noop())
+	if err != nil {
+		return nil, err
+	}
+	p := &pausableInput{
+		ctor:         ctor,
+		drainTimeout: drainTimeout,
+		log:          log,
+		fanIn:        fanIn,
+		paused:       paused,
+		changed:      make(chan struct{}, 1),
+		closeChan:    make(chan struct{}),
+		loopDone:     make(chan struct{}),
+	}
+	if !paused {
+		if err := p.apply(); err != nil {
+			fanIn.CloseAsync()
+			return nil, err
+		}
+	}
+	go p.loop()
+	return p, nil
+}
+
+// set pauses or resumes the input, which is applied asynchronously.
+func (p *pausableInput) set(paused bool) {
+	p.mut.Lock()
+	p.paused = paused
+	p.mut.Unlock()
+
+	select {
+	case p.changed <- struct{}{}:
+	default:
+	}
+}
+
+// apply builds or closes the input in order to match whether it is paused.
+func (p *pausableInput) apply() error {
+	p.mut.Lock()
+	paused, current := p.paused, p.current
+	p.mut.Unlock()
+
+	if paused && current != nil {
+		if err := p.fanIn.SetInput(pausableInputIdent, nil, p.drainTimeout); err != nil {
+			return fmt.Errorf("failed to close paused input: %w", err)
+		}
+		p.log.Infoln("Input closed while paused.")
+		current = nil
+	} else if !paused && current == nil {
+		in, err := p.ctor()
+		if err != nil {
+			return fmt.Errorf("failed to create input: %w", err)
+		}
+		if err = p.fanIn.SetInput(pausableInputIdent, in, p.drainTimeout); err != nil {
+			in.CloseAsync()
+			return fmt.Errorf("failed to start input: %w", err)
+		}
+		current = in
+	}
+
+	p.mut.Lock()
+	p.current = current
+	p.mut.Unlock()
+	return nil
+}
+
+func (p *pausableInput) loop() {
+	defer close(p.loopDone)
+
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-p.changed:
+		case <-retry:
+		case <-p.closeChan:
+			return
+		}
+		retry = nil
+		if err := p.apply(); err != nil {
+			p.log.Errorf("%v, retrying\n", err)
+			retry = time.After(time.Second)
+		}
+	}
+}
+
+// TransactionChan returns a transactions channel for consuming messages from
+// the input while it is resumed.
+func (p *pausableInput) TransactionChan() <-chan types.Transaction {
+	return p.fanIn.TransactionChan()
+}
+
+// Connected returns whether the input is connected to its source, which is
+// always true while paused as there is no input to connect.
+func (p *pausableInput) Connected() bool {
+	p.mut.Lock()
+	current := p.current
+	p.mut.Unlock()
+	if current == nil {
+		return true
+	}
+	return current.Connected()
+}
+
+// CloseAsync shuts down the input.
+func (p *pausableInput) CloseAsync() {
+	p.closeOnce.Do(func() {
+		close(p.closeChan)
+	})
+	p.fanIn.CloseAsync()
+}
+
+// WaitForClose blocks until the input has closed down.
+func (p *pausableInput) WaitForClose(timeout time.Duration) error {
+	select {
+	case <-p.loopDone:
+	case <-time.After(timeout):
+		return types.ErrTimeout
+	}
+	return p.fanIn.WaitForClose(timeout)
+}
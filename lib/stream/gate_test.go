@@ -0,0 +1,160 @@
+package stream
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/input"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockGateInput struct {
+	tChan     chan types.Transaction
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newMockGateInput() *mockGateInput {
+	return &mockGateInput{
+		tChan:  make(chan types.Transaction),
+		closed: make(chan struct{}),
+	}
+}
+
+func (m *mockGateInput) TransactionChan() <-chan types.Transaction {
+	return m.tChan
+}
+
+func (m *mockGateInput) Connected() bool {
+	return true
+}
+
+func (m *mockGateInput) CloseAsync() {
+	m.closeOnce.Do(func() {
+		close(m.closed)
+		close(m.tChan)
+	})
+}
+
+func (m *mockGateInput) WaitForClose(time.Duration) error {
+	return nil
+}
+
+func TestPausableInput(t *testing.T) {
+	built := make(chan *mockGateInput, 1)
+	p, err := newPausableInput(func() (input.Type, error) {
+		in := newMockGateInput()
+		built <- in
+		return in, nil
+	}, true, time.Second*5, log.Noop())
+	require.NoError(t, err)
+
+	select {
+	case <-built:
+		t.Fatal("input built while paused")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	p.set(false)
+	var in *mockGateInput
+	select {
+	case in = <-built:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for input to be built")
+	}
+
+	resChan := make(chan types.Response)
+	select {
+	case in.tChan <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), resChan):
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	var tran types.Transaction
+	select {
+	case tran = <-p.TransactionChan():
+		assert.Equal(t, "foo", string(tran.Payload.Get(0).Get()))
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	// Pausing stops the input from consuming but only completes once the
+	// message it has already sent is acknowledged.
+	p.set(true)
+	select {
+	case <-in.closed:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for input to be closed")
+	}
+	<-time.After(time.Millisecond * 50)
+	assert.False(t, p.isPaused())
+
+	go func() {
+		tran.ResponseChan <- response.NewAck()
+	}()
+	select {
+	case res := <-resChan:
+		require.NoError(t, res.Error())
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+	assert.Eventually(t, p.isPaused, time.Second, time.Millisecond*10)
+	assert.True(t, p.Connected())
+
+	// Resuming builds a new input.
+	p.set(false)
+	select {
+	case in = <-built:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for input to be built")
+	}
+
+	p.CloseAsync()
+	require.NoError(t, p.WaitForClose(time.Second*5))
+
+	select {
+	case <-in.closed:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for input to be closed")
+	}
+	_, open := <-p.TransactionChan()
+	assert.False(t, open)
+}
+
+func TestPausableInputStartResumed(t *testing.T) {
+	in := newMockGateInput()
+	p, err := newPausableInput(func() (input.Type, error) {
+		return in, nil
+	}, false, time.Second*5, log.Noop())
+	require.NoError(t, err)
+
+	resChan := make(chan types.Response)
+	select {
+	case in.tChan <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), resChan):
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+	select {
+	case tran := <-p.TransactionChan():
+		assert.Equal(t, "foo", string(tran.Payload.Get(0).Get()))
+		tran.ResponseChan <- response.NewAck()
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+	require.NoError(t, (<-resChan).Error())
+
+	p.CloseAsync()
+	require.NoError(t, p.WaitForClose(time.Second*5))
+}
+
+func (p *pausableInput) isPaused() bool {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	return p.current == nil
+}
@@ -0,0 +1,287 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/docs"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/throttle"
+)
+
+//------------------------------------------------------------------------------
+
+// HandoffPath is the path of the endpoint that receives the buffer backlog of
+// a peer instance.
+const HandoffPath = "/buffer/handoff"
+
+// HandoffConfig contains configuration fields for handing off the backlog of a
+// buffer between instances.
+type HandoffConfig struct {
+	Receive    bool   `json:"receive" yaml:"receive"`
+	PeerURL    string `json:"peer_url" yaml:"peer_url"`
+	OnShutdown bool   `json:"on_shutdown" yaml:"on_shutdown"`
+}
+
+// NewHandoffConfig creates a new HandoffConfig with default values.
+func NewHandoffConfig() HandoffConfig {
+	return HandoffConfig{
+		Receive:    false,
+		PeerURL:    "",
+		OnShutdown: false,
+	}
+}
+
+// Enabled returns true if the instance either receives or sends a backlog.
+func (c HandoffConfig) Enabled() bool {
+	return c.Receive || c.PeerURL != ""
+}
+
+// HandoffSpec returns a field spec for the buffer handoff configuration fields.
+func HandoffSpec() docs.FieldSpecs {
+	return docs.FieldSpecs{
+		docs.FieldBool("receive", "Whether to accept the backlog of peer instances at the endpoint `"+HandoffPath+"`, where received messages are added to the buffer of this instance, or passed to the pipeline when there is no buffer.").HasDefault(false),
+		docs.FieldString("peer_url", "The base URL of the HTTP server of a peer instance that the backlog of this instance is sent to. When set a handoff can be requested with a POST request to the endpoint `"+HandoffPath+"/send`, which shuts down this instance once the backlog has been sent.", "http://benthos-peer:4195").HasDefault(""),
+		docs.FieldBool("on_shutdown", "Whether to send the backlog to the peer each time this instance shuts down, rather than draining it to the output.").HasDefault(false),
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// handoffPart is a message part as it is sent to a peer.
+type handoffPart struct {
+	Content  []byte            `json:"content"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+func handoffParts(msg types.Message) []handoffPart {
+	parts := make([]handoffPart, 0, msg.Len())
+	_ = msg.Iter(func(i int, p types.Part) error {
+		part := handoffPart{Content: p.Get()}
+		_ = p.Metadata().Iter(func(k, v string) error {
+			if part.Metadata == nil {
+				part.Metadata = map[string]string{}
+			}
+			part.Metadata[k] = v
+			return nil
+		})
+		parts = append(parts, part)
+		return nil
+	})
+	return parts
+}
+
+func handoffMessage(parts []handoffPart) types.Message {
+	msg := message.New(nil)
+	for _, p := range parts {
+		part := message.NewPart(p.Content)
+		for k, v := range p.Metadata {
+			part.Metadata().Set(k, v)
+		}
+		msg.Append(part)
+	}
+	return msg
+}
+
+//------------------------------------------------------------------------------
+
+// handoffReceiver merges the batches received from peers into the transactions
+// of the input layer, such that they are added to the buffer.
+type handoffReceiver struct {
+	tranChan   chan types.Transaction
+	closedChan chan struct{}
+}
+
+func newHandoffReceiver() *handoffReceiver {
+	return &handoffReceiver{
+		tranChan:   make(chan types.Transaction),
+		closedChan: make(chan struct{}),
+	}
+}
+
+func (h *handoffReceiver) merge(in <-chan types.Transaction) <-chan types.Transaction {
+	out := make(chan types.Transaction)
+	go func() {
+		defer close(out)
+		defer close(h.closedChan)
+		for {
+			var tran types.Transaction
+			select {
+			case t, open := <-in:
+				if !open {
+					return
+				}
+				tran = t
+			case tran = <-h.tranChan:
+			}
+			out <- tran
+		}
+	}()
+	return out
+}
+
+func (h *handoffReceiver) handler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var parts []handoffPart
+	if err := json.NewDecoder(r.Body).Decode(&parts); err != nil {
+		http.Error(w, fmt.Sprintf("Bad request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(parts) == 0 {
+		return
+	}
+
+	resChan := make(chan types.Response, 1)
+	select {
+	case h.tranChan <- types.NewTransaction(handoffMessage(parts), resChan):
+	case <-h.closedChan:
+		http.Error(w, "Service unavailable: the stream is shutting down", http.StatusServiceUnavailable)
+		return
+	case <-r.Context().Done():
+		return
+	}
+
+	select {
+	case res := <-resChan:
+		if err := res.Error(); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to store messages: %v", err), http.StatusBadGateway)
+		}
+	case <-r.Context().Done():
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// handoffSender sits between a buffer and the layer that consumes from it, and
+// once activated sends the transactions read from the buffer to a peer rather
+// than passing them on. Each batch is retried until it is accepted by the peer
+// or the sender is closed.
+type handoffSender struct {
+	url    string
+	client *http.Client
+	log    log.Modular
+
+	active int32
+	sent   int64
+
+	closeOnce sync.Once
+	closeChan chan struct{}
+}
+
+func newHandoffSender(peerURL string, log log.Modular) *handoffSender {
+	return &handoffSender{
+		url:       strings.TrimSuffix(peerURL, "/") + HandoffPath,
+		client:    &http.Client{Timeout: time.Second * 30},
+		log:       log,
+		closeChan: make(chan struct{}),
+	}
+}
+
+// activate diverts all subsequent transactions to the peer, and returns false
+// if the sender was already active.
+func (h *handoffSender) activate() bool {
+	if !atomic.CompareAndSwapInt32(&h.active, 0, 1) {
+		return false
+	}
+	h.log.Infof("Handing off the buffer backlog to peer: %v\n", h.url)
+	return true
+}
+
+func (h *handoffSender) send(msg types.Message) error {
+	body, err := json.Marshal(handoffParts(msg))
+	if err != nil {
+		return err
+	}
+
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+	go func() {
+		select {
+		case <-h.closeChan:
+			done()
+		case <-ctx.Done():
+		}
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", h.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		resBody, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("peer returned status %v: %s", res.StatusCode, bytes.TrimSpace(resBody))
+	}
+	return nil
+}
+
+func (h *handoffSender) tap(in <-chan types.Transaction) <-chan types.Transaction {
+	out := make(chan types.Transaction)
+	go func() {
+		defer close(out)
+
+		errThrottle := throttle.New(throttle.OptCloseChan(h.closeChan))
+		for {
+			tran, open := <-in
+			if !open {
+				return
+			}
+			if atomic.LoadInt32(&h.active) == 0 {
+				out <- tran
+				continue
+			}
+
+			var err error
+			for {
+				if err = h.send(tran.Payload); err == nil {
+					errThrottle.Reset()
+					atomic.AddInt64(&h.sent, int64(tran.Payload.Len()))
+					break
+				}
+				h.log.Errorf("Failed to hand off messages to peer: %v\n", err)
+				if !errThrottle.Retry() {
+					break
+				}
+			}
+			if err != nil {
+				tran.ResponseChan <- response.NewError(types.ErrTypeClosed)
+			} else {
+				tran.ResponseChan <- response.NewAck()
+			}
+		}
+	}()
+	return out
+}
+
+func (h *handoffSender) close() {
+	h.closeOnce.Do(func() {
+		if atomic.LoadInt32(&h.active) == 1 {
+			h.log.Infof("Handed off %v messages to peer.\n", atomic.LoadInt64(&h.sent))
+		}
+		close(h.closeChan)
+	})
+}
+
+var errHandoffNoBuffer = errors.New("a buffer handoff peer requires a buffer")
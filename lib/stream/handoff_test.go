@@ -0,0 +1,86 @@
+package stream
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBufferHandoff(t *testing.T) {
+	receiver := newHandoffReceiver()
+	peerIn := make(chan types.Transaction)
+	peerOut := receiver.merge(peerIn)
+
+	var failures int32 = 1
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, HandoffPath, r.URL.Path)
+		if atomic.AddInt32(&failures, -1) >= 0 {
+			http.Error(w, "nope", http.StatusServiceUnavailable)
+			return
+		}
+		receiver.handler(w, r)
+	}))
+	defer ts.Close()
+
+	sender := newHandoffSender(ts.URL+"/", log.Noop())
+	defer sender.close()
+
+	bufOut := make(chan types.Transaction)
+	pipeIn := sender.tap(bufOut)
+
+	newTran := func(content string) (types.Transaction, chan types.Response) {
+		msg := message.New([][]byte{[]byte(content)})
+		msg.Get(0).Metadata().Set("foo", content)
+		resChan := make(chan types.Response)
+		return types.NewTransaction(msg, resChan), resChan
+	}
+
+	// Transactions pass through until the sender is activated.
+	tran, resChan := newTran("first")
+	bufOut <- tran
+	fwd := <-pipeIn
+	assert.Equal(t, "first", string(fwd.Payload.Get(0).Get()))
+	go func() {
+		fwd.ResponseChan <- response.NewAck()
+	}()
+	require.NoError(t, (<-resChan).Error())
+
+	assert.True(t, sender.activate())
+	assert.False(t, sender.activate())
+
+	tran, resChan = newTran("second")
+	bufOut <- tran
+
+	// The first attempt fails and is retried.
+	select {
+	case received := <-peerOut:
+		assert.Equal(t, "second", string(received.Payload.Get(0).Get()))
+		assert.Equal(t, "second", received.Payload.Get(0).Metadata().Get("foo"))
+		received.ResponseChan <- response.NewAck()
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+	require.NoError(t, (<-resChan).Error())
+
+	// Once the peer input closes handoffs are rejected.
+	close(peerIn)
+	_, open := <-peerOut
+	require.False(t, open)
+
+	tran, resChan = newTran("third")
+	bufOut <- tran
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		sender.close()
+	}()
+	assert.Equal(t, types.ErrTypeClosed, (<-resChan).Error())
+}
@@ -0,0 +1,126 @@
+package stream
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/clock"
+)
+
+//------------------------------------------------------------------------------
+
+// idleTracker observes the transactions flowing between the layers of a stream
+// and calls a closure once the stream has been idle for a period of time,
+// meaning no transactions have been consumed or delivered, none are in flight
+// and no messages remain within a buffer.
+type idleTracker struct {
+	timeout time.Duration
+	onIdle  func()
+	clock   clock.Monotonic
+
+	inFlight   int64
+	buffered   int64
+	lastActive int64 // A reading of clock
+
+	closeOnce sync.Once
+	closeChan chan struct{}
+}
+
+func newIdleTracker(timeout time.Duration, onIdle func()) *idleTracker {
+	return &idleTracker{
+		timeout:    timeout,
+		onIdle:     onIdle,
+		clock:      clock.System,
+		lastActive: int64(clock.System.Elapsed()),
+		closeChan:  make(chan struct{}),
+	}
+}
+
+func (i *idleTracker) markActive() {
+	atomic.StoreInt64(&i.lastActive, int64(i.clock.Elapsed()))
+}
+
+// observe returns a hook that counts each transaction as in flight until a
+// response has been received. When buffered is true successfully acknowledged
+// messages are counted as stored within a buffer.
+func (i *idleTracker) observe(buffered bool) tranHook {
+	return tranHook{
+		sent: func(types.Transaction) {
+			i.markActive()
+			atomic.AddInt64(&i.inFlight, 1)
+		},
+		resolved: func(tran types.Transaction, res types.Response) {
+			if buffered && res.Error() == nil {
+				atomic.AddInt64(&i.buffered, int64(tran.Payload.Len()))
+			}
+			i.markActive()
+			atomic.AddInt64(&i.inFlight, -1)
+		},
+	}
+}
+
+// observeDrain returns a hook for the transactions read from a buffer, which
+// counts the messages of each as no longer stored within it.
+func (i *idleTracker) observeDrain() tranHook {
+	return tranHook{
+		sent: func(tran types.Transaction) {
+			i.unbuffer(int64(tran.Payload.Len()))
+		},
+	}
+}
+
+// unbuffer counts n messages as no longer stored within the buffer. A buffer
+// can hold messages that were never counted, such as those persisted by a
+// previous run, and so the count is clamped at zero rather than going negative
+// and hiding messages that are counted later on.
+func (i *idleTracker) unbuffer(n int64) {
+	for {
+		buffered := atomic.LoadInt64(&i.buffered)
+		remaining := buffered - n
+		if remaining < 0 {
+			remaining = 0
+		}
+		if atomic.CompareAndSwapInt64(&i.buffered, buffered, remaining) {
+			return
+		}
+	}
+}
+
+func (i *idleTracker) isIdle() bool {
+	if atomic.LoadInt64(&i.inFlight) > 0 || atomic.LoadInt64(&i.buffered) > 0 {
+		return false
+	}
+	return i.clock.Elapsed()-time.Duration(atomic.LoadInt64(&i.lastActive)) >= i.timeout
+}
+
+func (i *idleTracker) loop() {
+	interval := i.timeout / 10
+	if interval < time.Millisecond*10 {
+		interval = time.Millisecond * 10
+	} else if interval > time.Second {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if i.isIdle() {
+				i.onIdle()
+				return
+			}
+		case <-i.closeChan:
+			return
+		}
+	}
+}
+
+func (i *idleTracker) close() {
+	i.closeOnce.Do(func() {
+		close(i.closeChan)
+	})
+}
@@ -0,0 +1,162 @@
+package stream
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newManualIdleTracker(timeout time.Duration, onIdle func()) (*idleTracker, *clock.Manual) {
+	clk := clock.NewManual()
+	tracker := newIdleTracker(timeout, onIdle)
+	tracker.clock = clk
+	tracker.markActive()
+	return tracker, clk
+}
+
+func TestIdleTrackerInFlight(t *testing.T) {
+	tracker, clk := newManualIdleTracker(time.Millisecond*100, func() {})
+	defer tracker.close()
+
+	obs := newTranObserver()
+	defer obs.close()
+
+	in := make(chan types.Transaction)
+	out := obs.tap(in, tracker.observe(false))
+
+	resChan := make(chan types.Response)
+	go func() {
+		in <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), resChan)
+	}()
+
+	tran := <-out
+	clk.Advance(time.Hour)
+	assert.False(t, tracker.isIdle(), "in flight")
+
+	go func() {
+		tran.ResponseChan <- response.NewAck()
+	}()
+	require.NoError(t, (<-resChan).Error())
+
+	assert.False(t, tracker.isIdle(), "recently active")
+	clk.Advance(time.Second)
+	assert.True(t, tracker.isIdle())
+
+	close(in)
+	_, open := <-out
+	assert.False(t, open)
+}
+
+func TestIdleTrackerBuffered(t *testing.T) {
+	tracker, clk := newManualIdleTracker(time.Millisecond*100, func() {})
+	defer tracker.close()
+
+	obs := newTranObserver()
+	defer obs.close()
+
+	in := make(chan types.Transaction)
+	out := obs.tap(in, tracker.observe(true))
+
+	resChan := make(chan types.Response)
+	go func() {
+		in <- types.NewTransaction(message.New([][]byte{[]byte("foo"), []byte("bar")}), resChan)
+	}()
+
+	tran := <-out
+	go func() {
+		tran.ResponseChan <- response.NewAck()
+	}()
+	require.NoError(t, (<-resChan).Error())
+	clk.Advance(time.Hour)
+	assert.False(t, tracker.isIdle(), "messages buffered")
+
+	bufIn := make(chan types.Transaction)
+	bufOut := obs.tap(bufIn, tracker.observeDrain())
+	go func() {
+		bufIn <- tran
+	}()
+	<-bufOut
+	assert.True(t, tracker.isIdle())
+}
+
+func TestIdleTrackerBufferedUncounted(t *testing.T) {
+	tracker, clk := newManualIdleTracker(time.Millisecond*100, func() {})
+	defer tracker.close()
+
+	obs := newTranObserver()
+	defer obs.close()
+
+	// Messages that were already stored within the buffer when the tracker
+	// started are read without ever being counted.
+	bufIn := make(chan types.Transaction)
+	bufOut := obs.tap(bufIn, tracker.observeDrain())
+	go func() {
+		bufIn <- types.NewTransaction(message.New([][]byte{[]byte("foo"), []byte("bar")}), nil)
+	}()
+	<-bufOut
+	assert.Equal(t, int64(0), atomic.LoadInt64(&tracker.buffered))
+
+	in := make(chan types.Transaction)
+	out := obs.tap(in, tracker.observe(true))
+
+	resChan := make(chan types.Response)
+	go func() {
+		in <- types.NewTransaction(message.New([][]byte{[]byte("baz")}), resChan)
+	}()
+
+	tran := <-out
+	go func() {
+		tran.ResponseChan <- response.NewAck()
+	}()
+	require.NoError(t, (<-resChan).Error())
+	clk.Advance(time.Hour)
+	assert.False(t, tracker.isIdle(), "messages buffered")
+}
+
+func TestIdleTrackerLoop(t *testing.T) {
+	idleChan := make(chan struct{})
+	tracker := newIdleTracker(time.Millisecond*50, func() {
+		close(idleChan)
+	})
+	defer tracker.close()
+
+	go tracker.loop()
+
+	select {
+	case <-idleChan:
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for idle")
+	}
+}
+
+func TestIdleTrackerLoopMonotonic(t *testing.T) {
+	idleChan := make(chan struct{})
+	tracker, clk := newManualIdleTracker(time.Millisecond*10, func() {
+		close(idleChan)
+	})
+	defer tracker.close()
+
+	go tracker.loop()
+
+	// Time passing outside of the clock of the tracker, which is what a step of
+	// the wall clock looks like, does not count towards the timeout.
+	select {
+	case <-idleChan:
+		t.Fatal("unexpected idle")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	clk.Advance(time.Millisecond * 10)
+	select {
+	case <-idleChan:
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for idle")
+	}
+}
@@ -898,3 +898,52 @@ func TestTypeAPISetResources(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, `{"id":"second","content":"hello world 2"}`, string(file2Bytes))
 }
+
+type muxAPIReg struct {
+	mut *mux.Router
+}
+
+func (m muxAPIReg) RegisterEndpoint(path, desc string, h http.HandlerFunc) {
+	m.mut.HandleFunc(path, h)
+}
+
+func TestTypeAPIStreamEndpointPrefix(t *testing.T) {
+	reg := muxAPIReg{mut: mux.NewRouter()}
+	mgr, err := bmanager.NewV2(bmanager.NewResourceConfig(), reg, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	smgr := manager.New(
+		manager.OptSetLogger(log.Noop()),
+		manager.OptSetStats(metrics.Noop()),
+		manager.OptSetManager(mgr),
+		manager.OptSetAPITimeout(time.Millisecond*100),
+	)
+
+	conf := harmlessConf()
+	conf.Input.Type = "generate"
+	conf.Input.Generate.Mapping = `root = "hello world"`
+	conf.Input.Generate.Interval = ""
+	conf.Input.Generate.Count = 1
+	conf.Buffer.Type = "memory"
+	conf.Buffer.Memory.InspectEndpoint = true
+	conf.Output.Type = "http_server"
+	require.NoError(t, smgr.Create("foo", conf))
+	defer func() {
+		assert.NoError(t, smgr.Stop(time.Second))
+	}()
+
+	// The buffer endpoint of a stream is mounted under the ID of the stream.
+	request := genRequest("GET", "/buffer/messages", nil)
+	response := httptest.NewRecorder()
+	reg.mut.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	request = genRequest("GET", "/foo/buffer/messages", nil)
+	response = httptest.NewRecorder()
+	reg.mut.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	res, err := gabs.ParseJSON(response.Body.Bytes())
+	require.NoError(t, err)
+	assert.Contains(t, res.ChildrenMap(), "total")
+}
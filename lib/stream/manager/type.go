@@ -298,6 +298,8 @@ func (m *Type) Delete(id string, timeout time.Duration) error {
 	delete(m.streams, id)
 	m.lock.Unlock()
 
+	manager.RemoveStreamComponents(m.manager, id)
+
 	return nil
 }
 
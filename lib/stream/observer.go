@@ -0,0 +1,119 @@
+package stream
+
+import (
+	"sync"
+
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+)
+
+//------------------------------------------------------------------------------
+
+// tranHook contains callbacks for observing the transactions that flow through
+// a tap, either of which may be nil.
+type tranHook struct {
+	// sent is called with each transaction before it is forwarded.
+	sent func(tran types.Transaction)
+
+	// resolved is called with each transaction and its response before the
+	// response is forwarded.
+	resolved func(tran types.Transaction, res types.Response)
+}
+
+// tranObserver taps the transaction channels between the layers of a stream,
+// where each tap relays transactions through a single goroutine regardless of
+// the number of hooks observing them, and all taps stop relaying once the
+// observer is closed.
+type tranObserver struct {
+	closeOnce sync.Once
+	closeChan chan struct{}
+}
+
+func newTranObserver() *tranObserver {
+	return &tranObserver{
+		closeChan: make(chan struct{}),
+	}
+}
+
+// tap returns a transaction channel that forwards the transactions of another,
+// calling the hooks as each transaction is sent and resolved. When no hooks are
+// given the channel is returned as is. Once the observer is closed the
+// transactions that could not be forwarded, or that are awaiting a response,
+// are answered with ErrTypeClosed.
+func (o *tranObserver) tap(in <-chan types.Transaction, hooks ...tranHook) <-chan types.Transaction {
+	if len(hooks) == 0 {
+		return in
+	}
+
+	var sent []func(types.Transaction)
+	var resolved []func(types.Transaction, types.Response)
+	for _, h := range hooks {
+		if h.sent != nil {
+			sent = append(sent, h.sent)
+		}
+		if h.resolved != nil {
+			resolved = append(resolved, h.resolved)
+		}
+	}
+
+	out := make(chan types.Transaction)
+	go func() {
+		defer close(out)
+		for {
+			var tran types.Transaction
+			var open bool
+			select {
+			case tran, open = <-in:
+				if !open {
+					return
+				}
+			case <-o.closeChan:
+				return
+			}
+
+			for _, fn := range sent {
+				fn(tran)
+			}
+
+			next := tran
+			if len(resolved) > 0 {
+				resChan := make(chan types.Response)
+				go o.resolve(tran, resChan, resolved)
+				next = types.NewTransaction(tran.Payload, resChan)
+			}
+
+			select {
+			case out <- next:
+			case <-o.closeChan:
+				if len(resolved) == 0 {
+					go func() {
+						tran.ResponseChan <- response.NewError(types.ErrTypeClosed)
+					}()
+				}
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// resolve awaits the response of a transaction that has been forwarded, calls
+// the hooks and then forwards the response.
+func (o *tranObserver) resolve(tran types.Transaction, resChan <-chan types.Response, resolved []func(types.Transaction, types.Response)) {
+	var res types.Response
+	select {
+	case res = <-resChan:
+	case <-o.closeChan:
+		res = response.NewError(types.ErrTypeClosed)
+	}
+	for _, fn := range resolved {
+		fn(tran, res)
+	}
+	tran.ResponseChan <- res
+}
+
+func (o *tranObserver) close() {
+	o.closeOnce.Do(func() {
+		close(o.closeChan)
+	})
+}
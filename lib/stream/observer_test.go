@@ -0,0 +1,127 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranObserverHooks(t *testing.T) {
+	obs := newTranObserver()
+	defer obs.close()
+
+	var calls []string
+	in := make(chan types.Transaction)
+	out := obs.tap(in, tranHook{
+		sent: func(tran types.Transaction) {
+			calls = append(calls, "first sent "+string(tran.Payload.Get(0).Get()))
+		},
+	}, tranHook{
+		sent: func(types.Transaction) {
+			calls = append(calls, "second sent")
+		},
+		resolved: func(_ types.Transaction, res types.Response) {
+			calls = append(calls, "second resolved "+res.Error().Error())
+		},
+	})
+
+	resChan := make(chan types.Response)
+	go func() {
+		in <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), resChan)
+	}()
+
+	tran := <-out
+	assert.Equal(t, "foo", string(tran.Payload.Get(0).Get()))
+	go func() {
+		tran.ResponseChan <- response.NewError(types.ErrTimeout)
+	}()
+	assert.Equal(t, types.ErrTimeout, (<-resChan).Error())
+
+	assert.Equal(t, []string{
+		"first sent foo",
+		"second sent",
+		"second resolved " + types.ErrTimeout.Error(),
+	}, calls)
+
+	close(in)
+	_, open := <-out
+	assert.False(t, open)
+}
+
+func TestTranObserverNoHooks(t *testing.T) {
+	obs := newTranObserver()
+	defer obs.close()
+
+	in := make(chan types.Transaction)
+	assert.Equal(t, (<-chan types.Transaction)(in), obs.tap(in))
+}
+
+func TestTranObserverCloseBlockedSend(t *testing.T) {
+	for _, hook := range []tranHook{
+		{sent: func(types.Transaction) {}},
+		{resolved: func(types.Transaction, types.Response) {}},
+	} {
+		obs := newTranObserver()
+
+		in := make(chan types.Transaction)
+		out := obs.tap(in, hook)
+
+		resChan := make(chan types.Response)
+		in <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), resChan)
+
+		// Nothing reads from the tap, which must not block shutdown.
+		obs.close()
+
+		select {
+		case res := <-resChan:
+			assert.Equal(t, types.ErrTypeClosed, res.Error())
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for response")
+		}
+		select {
+		case _, open := <-out:
+			require.False(t, open)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for tap to close")
+		}
+	}
+}
+
+func TestTranObserverCloseAwaitingResponse(t *testing.T) {
+	obs := newTranObserver()
+
+	resolved := make(chan types.Response, 1)
+	in := make(chan types.Transaction)
+	out := obs.tap(in, tranHook{
+		resolved: func(_ types.Transaction, res types.Response) {
+			resolved <- res
+		},
+	})
+
+	resChan := make(chan types.Response)
+	go func() {
+		in <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), resChan)
+	}()
+	<-out
+
+	// The output never responds to the transaction it received.
+	obs.close()
+
+	select {
+	case res := <-resolved:
+		assert.Equal(t, types.ErrTypeClosed, res.Error())
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for resolved hook")
+	}
+	select {
+	case res := <-resChan:
+		assert.Equal(t, types.ErrTypeClosed, res.Error())
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for response")
+	}
+}
@@ -38,6 +38,28 @@ type Type struct {
 	logger  log.Modular
 
 	onClose func()
+
+	observer *tranObserver
+
+	idleTimeout time.Duration
+	onIdle      func()
+	idle        *idleTracker
+
+	stallTimeout time.Duration
+	onStall      func()
+	watchdog     *watchdog
+
+	inputPausable bool
+	inputPaused   bool
+	pausable      *pausableInput
+
+	handoffConf      HandoffConfig
+	onHandoffRequest func()
+	handoffReceiver  *handoffReceiver
+	handoffSender    *handoffSender
+
+	auditConf AuditConfig
+	auditor   *auditor
 }
 
 // New creates a new stream.Type.
@@ -130,8 +152,93 @@ func OptOnClose(onClose func()) func(*Type) {
 	}
 }
 
+// OptOnIdle sets a closure to be called once the stream has been idle for a
+// period of time, meaning no messages have been consumed or delivered and none
+// remain within a buffer. The closure is called at most once.
+func OptOnIdle(timeout time.Duration, onIdle func()) func(*Type) {
+	return func(t *Type) {
+		t.idleTimeout = timeout
+		t.onIdle = onIdle
+	}
+}
+
+// OptOnStall enables a watchdog that logs diagnostics once messages have
+// stopped flowing through the output of the stream for a period of time despite
+// messages remaining in flight or within a buffer and the output being
+// connected. The closure, which may be nil, is called each time the stream
+// stalls.
+func OptOnStall(timeout time.Duration, onStall func()) func(*Type) {
+	return func(t *Type) {
+		t.stallTimeout = timeout
+		t.onStall = onStall
+	}
+}
+
+// OptPausableInput allows the input layer of the stream to be paused and
+// resumed with PauseInput and ResumeInput, and when paused is true the stream
+// starts with its input paused.
+func OptPausableInput(paused bool) func(*Type) {
+	return func(t *Type) {
+		t.inputPausable = true
+		t.inputPaused = paused
+	}
+}
+
+// OptBufferHandoff enables handing off the backlog of the buffer of the stream
+// to a peer instance and receiving the backlog of peers. The closure, which may
+// be nil, is called when a handoff is requested via the HTTP endpoint, and is
+// expected to stop the stream.
+func OptBufferHandoff(conf HandoffConfig, onRequest func()) func(*Type) {
+	return func(t *Type) {
+		t.handoffConf = conf
+		t.onHandoffRequest = onRequest
+	}
+}
+
+// OptAudit enables recording a summary of each message sent to the output of
+// the stream, including the outcome of the delivery, to a rotated file or an
+// output resource.
+func OptAudit(conf AuditConfig) func(*Type) {
+	return func(t *Type) {
+		t.auditConf = conf
+	}
+}
+
 //------------------------------------------------------------------------------
 
+// PauseInput closes the input layer of the stream until ResumeInput is called,
+// once the messages already consumed from it have been acknowledged, which
+// continue to be processed and delivered. The input is closed asynchronously.
+// This has no effect unless the stream was created with OptPausableInput.
+func (t *Type) PauseInput() {
+	if t.pausable != nil {
+		t.pausable.set(true)
+	}
+}
+
+// ResumeInput creates the input layer of the stream again after it was paused,
+// which is done asynchronously.
+func (t *Type) ResumeInput() {
+	if t.pausable != nil {
+		t.pausable.set(false)
+	}
+}
+
+func (t *Type) handoffRequestHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !t.handoffSender.activate() {
+		w.Write([]byte("Handoff already in progress\n"))
+		return
+	}
+	if t.onHandoffRequest != nil {
+		t.onHandoffRequest()
+	}
+	w.Write([]byte("Handoff started\n"))
+}
+
 // IsReady returns a boolean indicating whether both the input and output layers
 // of the stream are connected.
 func (t *Type) IsReady() bool {
@@ -141,7 +248,14 @@ func (t *Type) IsReady() bool {
 func (t *Type) start() (err error) {
 	// Constructors
 	iMgr, iLog, iStats := interop.LabelChild("input", t.manager, t.logger, t.stats)
-	if t.inputLayer, err = input.New(t.conf.Input, iMgr, iLog, iStats); err != nil {
+	if t.inputPausable {
+		if t.pausable, err = newPausableInput(func() (input.Type, error) {
+			return input.New(t.conf.Input, iMgr, iLog, iStats)
+		}, t.inputPaused, pausedInputDrainTimeout, iLog); err != nil {
+			return
+		}
+		t.inputLayer = t.pausable
+	} else if t.inputLayer, err = input.New(t.conf.Input, iMgr, iLog, iStats); err != nil {
 		return
 	}
 	if t.conf.Buffer.Type != buffer.TypeNone {
@@ -161,29 +275,121 @@ func (t *Type) start() (err error) {
 		return
 	}
 
+	t.observer = newTranObserver()
+	if t.idleTimeout > 0 {
+		t.idle = newIdleTracker(t.idleTimeout, t.onIdle)
+	}
+	if t.stallTimeout > 0 {
+		t.watchdog = newWatchdog(t.stallTimeout, t.outputLayer.Connected, t.onStall, t.logger)
+	}
+	if t.handoffConf.Receive {
+		t.handoffReceiver = newHandoffReceiver()
+		t.manager.RegisterEndpoint(
+			HandoffPath,
+			"Receives the buffer backlog of a peer instance, adding it to the buffer of this stream.",
+			t.handoffReceiver.handler,
+		)
+	}
+	if t.handoffConf.PeerURL != "" {
+		if t.bufferLayer == nil {
+			return errHandoffNoBuffer
+		}
+		t.handoffSender = newHandoffSender(t.handoffConf.PeerURL, t.logger)
+		t.manager.RegisterEndpoint(
+			HandoffPath+"/send",
+			"Hands off the buffer backlog of this stream to the configured peer instance and then shuts down.",
+			t.handoffRequestHandler,
+		)
+	}
+
+	if t.auditConf.Enabled() {
+		if t.auditor, err = newAuditor(
+			t.auditConf, auditSource(t.conf), auditDestinations(t.conf.Output),
+			t.manager, t.logger.NewModule(".audit"),
+		); err != nil {
+			return
+		}
+	}
+
 	// Start chaining components
 	var nextTranChan <-chan types.Transaction
 
 	nextTranChan = t.inputLayer.TransactionChan()
+	if t.handoffReceiver != nil {
+		nextTranChan = t.handoffReceiver.merge(nextTranChan)
+	}
+
+	// Transactions are observed by the idle tracker, watchdog and auditor with
+	// a single tap in front of each layer.
+	var hooks []tranHook
+	if t.idle != nil {
+		hooks = append(hooks, t.idle.observe(t.bufferLayer != nil))
+	}
 	if t.bufferLayer != nil {
+		if t.watchdog != nil {
+			hooks = append(hooks, t.watchdog.observe("buffer", true, false))
+		}
+		nextTranChan = t.observer.tap(nextTranChan, hooks...)
+		hooks = nil
 		if err = t.bufferLayer.Consume(nextTranChan); err != nil {
 			return
 		}
 		nextTranChan = t.bufferLayer.TransactionChan()
+		if t.handoffSender != nil {
+			nextTranChan = t.handoffSender.tap(nextTranChan)
+		}
+		if t.idle != nil {
+			hooks = append(hooks, t.idle.observe(false), t.idle.observeDrain())
+		}
+		if t.watchdog != nil {
+			hooks = append(hooks, t.watchdog.observeDrain())
+		}
 	}
 	if t.pipelineLayer != nil {
+		if t.watchdog != nil {
+			hooks = append(hooks, t.watchdog.observe("pipeline", false, false))
+		}
+		nextTranChan = t.observer.tap(nextTranChan, hooks...)
+		hooks = nil
 		if err = t.pipelineLayer.Consume(nextTranChan); err != nil {
 			return
 		}
 		nextTranChan = t.pipelineLayer.TransactionChan()
 	}
+	if t.watchdog != nil {
+		hooks = append(hooks, t.watchdog.observe("output", false, true))
+	}
+	if t.auditor != nil {
+		hooks = append(hooks, t.auditor.observe())
+	}
+	nextTranChan = t.observer.tap(nextTranChan, hooks...)
 	if err = t.outputLayer.Consume(nextTranChan); err != nil {
 		return
 	}
 
+	if t.idle != nil {
+		go t.idle.loop()
+	}
+	if t.watchdog != nil {
+		go t.watchdog.loop()
+	}
+
 	go func(out output.Type) {
 		for {
 			if err := out.WaitForClose(time.Second); err == nil {
+				t.observer.close()
+				if t.idle != nil {
+					t.idle.close()
+				}
+				if t.watchdog != nil {
+					t.watchdog.close()
+				}
+				if t.handoffSender != nil {
+					t.handoffSender.close()
+				}
+				if t.auditor != nil {
+					t.auditor.close()
+				}
 				t.onClose()
 				return
 			}
@@ -345,6 +551,10 @@ func (t *Type) stopUnordered(timeout time.Duration) (err error) {
 // Initially the attempt is graceful, but as the timeout draws close the attempt
 // becomes progressively less graceful.
 func (t *Type) Stop(timeout time.Duration) error {
+	if t.handoffSender != nil && t.handoffConf.OnShutdown {
+		t.handoffSender.activate()
+	}
+
 	tOutUnordered := timeout / 4
 	tOutGraceful := timeout - tOutUnordered
 
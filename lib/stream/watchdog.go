@@ -0,0 +1,225 @@
+package stream
+
+import (
+	"bytes"
+	"fmt"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/internal/docs"
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/clock"
+)
+
+//------------------------------------------------------------------------------
+
+// Watchdog actions.
+const (
+	WatchdogActionLog  = "log"
+	WatchdogActionExit = "exit"
+)
+
+// WatchdogConfig contains configuration fields for detecting a stalled stream.
+type WatchdogConfig struct {
+	StallTimeout string `json:"stall_timeout" yaml:"stall_timeout"`
+	Action       string `json:"action" yaml:"action"`
+}
+
+// NewWatchdogConfig creates a new WatchdogConfig with default values.
+func NewWatchdogConfig() WatchdogConfig {
+	return WatchdogConfig{
+		StallTimeout: "",
+		Action:       WatchdogActionLog,
+	}
+}
+
+// WatchdogSpec returns a field spec for the watchdog configuration fields.
+func WatchdogSpec() docs.FieldSpecs {
+	return docs.FieldSpecs{
+		docs.FieldString("stall_timeout", "An optional period of time after which the stream is considered stalled when no messages have been delivered to or acknowledged by the output, despite messages remaining in flight or within a buffer and the output being connected. When empty the watchdog is disabled.", "1m", "10m").HasDefault(""),
+		docs.FieldString("action", "The action to take once the stream has stalled. Diagnostics are always logged, including the component that appears to be stuck and a dump of all goroutines, and with `exit` Benthos then shuts down with a non-zero exit code so that it can be restarted by a process supervisor.").HasOptions(WatchdogActionLog, WatchdogActionExit).HasDefault(WatchdogActionLog),
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// watchdogStage counts the transactions sent to a layer of a stream that have
+// not yet been resolved.
+type watchdogStage struct {
+	name     string
+	inFlight int64
+}
+
+// watchdog observes the transactions flowing between the layers of a stream
+// and reports when messages have stopped flowing into and out of the output
+// layer for a period of time despite messages remaining in flight or within a
+// buffer, which usually indicates a deadlock or a stuck goroutine.
+type watchdog struct {
+	timeout   time.Duration
+	connected func() bool
+	onStall   func()
+	log       log.Modular
+	clock     clock.Monotonic
+
+	buffered     int64
+	lastProgress int64 // A reading of clock
+	stages       []*watchdogStage
+
+	closeOnce sync.Once
+	closeChan chan struct{}
+}
+
+func newWatchdog(timeout time.Duration, connected func() bool, onStall func(), log log.Modular) *watchdog {
+	return &watchdog{
+		timeout:      timeout,
+		connected:    connected,
+		onStall:      onStall,
+		log:          log,
+		clock:        clock.System,
+		lastProgress: int64(clock.System.Elapsed()),
+		closeChan:    make(chan struct{}),
+	}
+}
+
+func (w *watchdog) markProgress() {
+	atomic.StoreInt64(&w.lastProgress, int64(w.clock.Elapsed()))
+}
+
+func (w *watchdog) pending() bool {
+	if atomic.LoadInt64(&w.buffered) > 0 {
+		return true
+	}
+	for _, s := range w.stages {
+		if atomic.LoadInt64(&s.inFlight) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// observe returns a hook for the transactions sent to a layer of the stream,
+// which counts each transaction as in flight until a response has been
+// received. When buffered is true successfully acknowledged messages are
+// counted as stored within a buffer, and when output is true the transactions
+// and their responses are counted as progress.
+//
+// Hooks must be created in the order of the layers of the stream, starting
+// with the layer closest to the input.
+func (w *watchdog) observe(name string, buffered, output bool) tranHook {
+	stage := &watchdogStage{name: name}
+	w.stages = append(w.stages, stage)
+
+	return tranHook{
+		sent: func(types.Transaction) {
+			// The stall period begins at the first message that arrives at an
+			// otherwise empty stream.
+			if output || !w.pending() {
+				w.markProgress()
+			}
+			atomic.AddInt64(&stage.inFlight, 1)
+		},
+		resolved: func(tran types.Transaction, res types.Response) {
+			if buffered && res.Error() == nil {
+				atomic.AddInt64(&w.buffered, int64(tran.Payload.Len()))
+			}
+			if output {
+				w.markProgress()
+			}
+			atomic.AddInt64(&stage.inFlight, -1)
+		},
+	}
+}
+
+// observeDrain returns a hook for the transactions read from a buffer, which
+// counts the messages of each as no longer stored within it.
+func (w *watchdog) observeDrain() tranHook {
+	return tranHook{
+		sent: func(tran types.Transaction) {
+			atomic.AddInt64(&w.buffered, -int64(tran.Payload.Len()))
+		},
+	}
+}
+
+func (w *watchdog) isStalled() bool {
+	if w.clock.Elapsed()-time.Duration(atomic.LoadInt64(&w.lastProgress)) < w.timeout {
+		return false
+	}
+	return w.pending() && w.connected()
+}
+
+// diagnose returns a description of the state of a stalled stream, naming the
+// layer furthest downstream that holds unresolved transactions as the one that
+// appears to be stuck.
+func (w *watchdog) diagnose() string {
+	stuck := ""
+	counts := make([]string, 0, len(w.stages))
+	for _, s := range w.stages {
+		n := atomic.LoadInt64(&s.inFlight)
+		if n > 0 {
+			stuck = s.name
+		}
+		counts = append(counts, fmt.Sprintf("%v: %v", s.name, n))
+	}
+	buffered := atomic.LoadInt64(&w.buffered)
+	if stuck == "" && buffered > 0 {
+		stuck = "buffer"
+	}
+	return fmt.Sprintf(
+		"no messages have flowed through the output for %v despite %v messages within the buffer and transactions in flight (%v), the %v layer appears to be stuck",
+		w.timeout, buffered, strings.Join(counts, ", "), stuck,
+	)
+}
+
+func (w *watchdog) loop() {
+	interval := w.timeout / 10
+	if interval < time.Millisecond*10 {
+		interval = time.Millisecond * 10
+	} else if interval > time.Second {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	stalled := false
+	for {
+		select {
+		case <-ticker.C:
+			if !w.isStalled() {
+				if stalled {
+					w.log.Infoln("Messages are flowing through the pipeline again.")
+					stalled = false
+				}
+				continue
+			}
+			if stalled {
+				continue
+			}
+			stalled = true
+
+			w.log.Errorf("Pipeline has stalled: %v\n", w.diagnose())
+
+			dumpBuf := bytes.NewBuffer(nil)
+			_ = pprof.Lookup("goroutine").WriteTo(dumpBuf, 1)
+			w.log.Warnf("Goroutine dump of stalled pipeline:\n%v\n", dumpBuf.String())
+
+			if w.onStall != nil {
+				w.onStall()
+			}
+		case <-w.closeChan:
+			return
+		}
+	}
+}
+
+func (w *watchdog) close() {
+	w.closeOnce.Do(func() {
+		close(w.closeChan)
+	})
+}
+
+//------------------------------------------------------------------------------
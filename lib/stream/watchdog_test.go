@@ -0,0 +1,124 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/response"
+	"github.com/Jeffail/benthos/v3/lib/types"
+	"github.com/Jeffail/benthos/v3/lib/util/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newManualWatchdog(timeout time.Duration, connected func() bool, onStall func()) (*watchdog, *clock.Manual) {
+	clk := clock.NewManual()
+	dog := newWatchdog(timeout, connected, onStall, log.Noop())
+	dog.clock = clk
+	dog.markProgress()
+	return dog, clk
+}
+
+func TestWatchdogStalledOutput(t *testing.T) {
+	connected := true
+	dog, clk := newManualWatchdog(time.Millisecond*100, func() bool { return connected }, nil)
+	defer dog.close()
+
+	obs := newTranObserver()
+	defer obs.close()
+
+	in := make(chan types.Transaction)
+	pipeOut := obs.tap(in, dog.observe("pipeline", false, false))
+	out := obs.tap(pipeOut, dog.observe("output", false, true))
+
+	clk.Advance(time.Hour)
+	assert.False(t, dog.isStalled(), "nothing pending")
+
+	resChan := make(chan types.Response)
+	go func() {
+		in <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), resChan)
+	}()
+	tran := <-out
+
+	assert.False(t, dog.isStalled(), "recent progress")
+	clk.Advance(time.Second)
+	assert.True(t, dog.isStalled())
+	assert.Contains(t, dog.diagnose(), "pipeline: 1, output: 1")
+	assert.Contains(t, dog.diagnose(), "the output layer appears to be stuck")
+
+	connected = false
+	assert.False(t, dog.isStalled(), "output disconnected")
+	connected = true
+
+	go func() {
+		tran.ResponseChan <- response.NewAck()
+	}()
+	require.NoError(t, (<-resChan).Error())
+	clk.Advance(time.Hour)
+	assert.False(t, dog.isStalled(), "nothing pending")
+}
+
+func TestWatchdogStalledPipeline(t *testing.T) {
+	dog, clk := newManualWatchdog(time.Millisecond*100, func() bool { return true }, nil)
+	defer dog.close()
+
+	obs := newTranObserver()
+	defer obs.close()
+
+	in := make(chan types.Transaction)
+	bufOut := obs.tap(in, dog.observe("buffer", true, false))
+
+	resChan := make(chan types.Response)
+	go func() {
+		in <- types.NewTransaction(message.New([][]byte{[]byte("foo"), []byte("bar")}), resChan)
+	}()
+	tran := <-bufOut
+	go func() {
+		tran.ResponseChan <- response.NewAck()
+	}()
+	require.NoError(t, (<-resChan).Error())
+
+	clk.Advance(time.Second)
+	assert.True(t, dog.isStalled(), "messages buffered")
+	assert.Contains(t, dog.diagnose(), "2 messages within the buffer")
+	assert.Contains(t, dog.diagnose(), "the buffer layer appears to be stuck")
+
+	drainIn := make(chan types.Transaction)
+	pipeOut := obs.tap(drainIn, dog.observeDrain(), dog.observe("pipeline", false, false))
+	go func() {
+		drainIn <- types.NewTransaction(tran.Payload, make(chan types.Response, 1))
+	}()
+	<-pipeOut
+
+	clk.Advance(time.Second)
+	assert.True(t, dog.isStalled(), "pipeline in flight")
+	assert.Contains(t, dog.diagnose(), "the pipeline layer appears to be stuck")
+}
+
+func TestWatchdogLoop(t *testing.T) {
+	stallChan := make(chan struct{})
+	dog := newWatchdog(time.Millisecond*50, func() bool { return true }, func() {
+		close(stallChan)
+	}, log.Noop())
+	defer dog.close()
+
+	obs := newTranObserver()
+	defer obs.close()
+
+	in := make(chan types.Transaction)
+	out := obs.tap(in, dog.observe("output", false, true))
+	go func() {
+		in <- types.NewTransaction(message.New([][]byte{[]byte("foo")}), make(chan types.Response, 1))
+	}()
+	<-out
+
+	go dog.loop()
+
+	select {
+	case <-stallChan:
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for stall")
+	}
+}
@@ -56,6 +56,8 @@ input:
     queue_declare:
       durable: true
       enabled: true
+    exchanges_declare:
+      - name: exchange-$ID
     bindings_declare:
       - exchange: exchange-$ID
         key: benthos-key
@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/beanstalkd/go-beanstalk"
+	"github.com/ory/dockertest/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ = registerIntegrationTest("beanstalkd", func(t *testing.T) {
+	t.Parallel()
+
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+
+	pool.MaxWait = time.Second * 30
+	resource, err := pool.Run("schickling/beanstalkd", "latest", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, pool.Purge(resource))
+	})
+
+	resource.Expire(900)
+	require.NoError(t, pool.Retry(func() error {
+		conn, err := beanstalk.Dial("tcp", fmt.Sprintf("localhost:%v", resource.GetPort("11300/tcp")))
+		if err != nil {
+			return err
+		}
+		_, err = conn.Stats()
+		conn.Close()
+		return err
+	}))
+
+	template := `
+output:
+  beanstalkd:
+    address: localhost:$PORT
+    tube: tube-$ID
+    max_in_flight: $MAX_IN_FLIGHT
+
+input:
+  beanstalkd:
+    address: localhost:$PORT
+    tubes: [ tube-$ID ]
+`
+	suite := integrationTests(
+		integrationTestOpenClose(),
+		integrationTestSendBatch(10),
+		integrationTestStreamSequential(1000),
+		integrationTestStreamParallel(1000),
+		integrationTestStreamParallelLossy(1000),
+		integrationTestAtLeastOnceDelivery(),
+	)
+	suite.Run(
+		t, template,
+		testOptSleepAfterInput(100*time.Millisecond),
+		testOptSleepAfterOutput(100*time.Millisecond),
+		testOptPort(resource.GetPort("11300/tcp")),
+	)
+	t.Run("with max in flight", func(t *testing.T) {
+		t.Parallel()
+		suite.Run(
+			t, template,
+			testOptSleepAfterInput(100*time.Millisecond),
+			testOptSleepAfterOutput(100*time.Millisecond),
+			testOptPort(resource.GetPort("11300/tcp")),
+			testOptMaxInFlight(10),
+		)
+	})
+})
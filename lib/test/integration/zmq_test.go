@@ -49,4 +49,14 @@ input:
 			testOptVarThree(`""`),
 		)
 	})
+	t.Run("with dealer router", func(t *testing.T) {
+		t.Parallel()
+		suite.Run(
+			t, template,
+			testOptSleepAfterInput(500*time.Millisecond),
+			testOptSleepAfterOutput(500*time.Millisecond),
+			testOptVarOne("DEALER"),
+			testOptVarTwo("ROUTER"),
+		)
+	})
 })
@@ -0,0 +1,194 @@
+package tracer
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/opentracing/opentracing-go"
+)
+
+//------------------------------------------------------------------------------
+
+// Debug is an opentracing.Tracer that wraps another tracer and, once
+// triggered, tags the next N messages to enter the pipeline and logs each span
+// recorded for them, which includes every component that they pass through
+// along with how long each took. This allows the path of a specific message to
+// be reconstructed from logs without an opentracing collector.
+type Debug struct {
+	wrapped opentracing.Tracer
+	log     log.Modular
+
+	remaining int64
+	lastID    uint64
+}
+
+// NewDebug creates a debug tracer that wraps another tracer.
+func NewDebug(wrapped opentracing.Tracer, log log.Modular) *Debug {
+	return &Debug{
+		wrapped: wrapped,
+		log:     log,
+	}
+}
+
+// TraceNext enables the tracing of the next n messages to enter the pipeline,
+// replacing any remaining count from a previous call.
+func (d *Debug) TraceNext(n int) {
+	atomic.StoreInt64(&d.remaining, int64(n))
+}
+
+// HandlerFunc returns an HTTP handler that enables the tracing of the next
+// messages, where the number of messages is specified with the query parameter
+// count, defaulting to 1.
+func (d *Debug) HandlerFunc() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		count := 1
+		if countStr := r.URL.Query().Get("count"); countStr != "" {
+			var err error
+			if count, err = strconv.Atoi(countStr); err != nil || count < 0 {
+				http.Error(w, fmt.Sprintf("invalid count: %v", countStr), http.StatusBadRequest)
+				return
+			}
+		}
+		d.TraceNext(count)
+		d.log.Infof("Tracing the next %v messages\n", count)
+		fmt.Fprintf(w, "Tracing the next %v messages\n", count)
+	}
+}
+
+func (d *Debug) claimID() uint64 {
+	for {
+		remaining := atomic.LoadInt64(&d.remaining)
+		if remaining <= 0 {
+			return 0
+		}
+		if atomic.CompareAndSwapInt64(&d.remaining, remaining, remaining-1) {
+			return atomic.AddUint64(&d.lastID, 1)
+		}
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// StartSpan creates a span, which is traced when it is either the child of a
+// traced span, or a root span created whilst tracing is enabled.
+func (d *Debug) StartSpan(operationName string, opts ...opentracing.StartSpanOption) opentracing.Span {
+	var sso opentracing.StartSpanOptions
+	for _, o := range opts {
+		o.Apply(&sso)
+	}
+
+	var traceID uint64
+	for i, ref := range sso.References {
+		if dCtx, ok := ref.ReferencedContext.(debugSpanContext); ok {
+			if traceID == 0 {
+				traceID = dCtx.traceID
+			}
+			sso.References[i].ReferencedContext = dCtx.SpanContext
+		}
+	}
+	if traceID == 0 && len(sso.References) == 0 {
+		traceID = d.claimID()
+	}
+
+	wrappedOpts := make([]opentracing.StartSpanOption, 0, len(sso.Tags)+len(sso.References)+1)
+	for _, ref := range sso.References {
+		wrappedOpts = append(wrappedOpts, ref)
+	}
+	if !sso.StartTime.IsZero() {
+		wrappedOpts = append(wrappedOpts, opentracing.StartTime(sso.StartTime))
+	}
+	for k, v := range sso.Tags {
+		wrappedOpts = append(wrappedOpts, opentracing.Tag{Key: k, Value: v})
+	}
+
+	span := d.wrapped.StartSpan(operationName, wrappedOpts...)
+	if traceID == 0 {
+		return span
+	}
+
+	start := sso.StartTime
+	if start.IsZero() {
+		start = time.Now()
+	}
+	d.log.Infof("Traced message %v entered %v\n", traceID, operationName)
+	return &debugSpan{
+		Span:    span,
+		tracer:  d,
+		traceID: traceID,
+		opName:  operationName,
+		start:   start,
+	}
+}
+
+// Inject delegates to the wrapped tracer.
+func (d *Debug) Inject(sm opentracing.SpanContext, format, carrier interface{}) error {
+	if dCtx, ok := sm.(debugSpanContext); ok {
+		sm = dCtx.SpanContext
+	}
+	return d.wrapped.Inject(sm, format, carrier)
+}
+
+// Extract delegates to the wrapped tracer.
+func (d *Debug) Extract(format, carrier interface{}) (opentracing.SpanContext, error) {
+	return d.wrapped.Extract(format, carrier)
+}
+
+//------------------------------------------------------------------------------
+
+type debugSpanContext struct {
+	opentracing.SpanContext
+	traceID uint64
+}
+
+type debugSpan struct {
+	opentracing.Span
+
+	tracer  *Debug
+	traceID uint64
+	opName  string
+	start   time.Time
+}
+
+func (s *debugSpan) Finish() {
+	s.FinishWithOptions(opentracing.FinishOptions{})
+}
+
+func (s *debugSpan) FinishWithOptions(opts opentracing.FinishOptions) {
+	finish := opts.FinishTime
+	if finish.IsZero() {
+		finish = time.Now()
+	}
+	s.tracer.log.Infof("Traced message %v left %v after %v\n", s.traceID, s.opName, finish.Sub(s.start))
+	s.Span.FinishWithOptions(opts)
+}
+
+func (s *debugSpan) Context() opentracing.SpanContext {
+	return debugSpanContext{
+		SpanContext: s.Span.Context(),
+		traceID:     s.traceID,
+	}
+}
+
+func (s *debugSpan) SetOperationName(operationName string) opentracing.Span {
+	s.opName = operationName
+	s.Span.SetOperationName(operationName)
+	return s
+}
+
+func (s *debugSpan) SetTag(key string, value interface{}) opentracing.Span {
+	s.Span.SetTag(key, value)
+	return s
+}
+
+func (s *debugSpan) SetBaggageItem(restrictedKey, value string) opentracing.Span {
+	s.Span.SetBaggageItem(restrictedKey, value)
+	return s
+}
+
+func (s *debugSpan) Tracer() opentracing.Tracer {
+	return s.tracer
+}
@@ -0,0 +1,60 @@
+package tracer_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/Jeffail/benthos/v3/lib/tracer"
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugTracer(t *testing.T) {
+	var buf bytes.Buffer
+	logConf := log.NewConfig()
+	logConf.Format = "logfmt"
+	logger, err := log.NewV2(&buf, logConf)
+	require.NoError(t, err)
+
+	d := tracer.NewDebug(opentracing.NoopTracer{}, logger)
+
+	// Spans are not traced until triggered.
+	d.StartSpan("input_before").Finish()
+	assert.Equal(t, "", buf.String())
+
+	req := httptest.NewRequest(http.MethodPost, "/debug/trace?count=1", nil)
+	res := httptest.NewRecorder()
+	d.HandlerFunc()(res, req)
+	require.Equal(t, http.StatusOK, res.Code)
+	buf.Reset()
+
+	root := d.StartSpan("input_foo")
+	child := d.StartSpan("bloblang", opentracing.ChildOf(root.Context()))
+	child.Finish()
+	root.Finish()
+
+	// Only the next message is traced.
+	d.StartSpan("input_bar").Finish()
+
+	logs := buf.String()
+	assert.Contains(t, logs, "Traced message 1 entered input_foo")
+	assert.Contains(t, logs, "Traced message 1 entered bloblang")
+	assert.Contains(t, logs, "Traced message 1 left bloblang after")
+	assert.Contains(t, logs, "Traced message 1 left input_foo after")
+	assert.NotContains(t, logs, "input_bar")
+	assert.Equal(t, 4, strings.Count(logs, "Traced message"))
+}
+
+func TestDebugTracerBadCount(t *testing.T) {
+	d := tracer.NewDebug(opentracing.NoopTracer{}, log.Noop())
+
+	req := httptest.NewRequest(http.MethodPost, "/debug/trace?count=nope", nil)
+	res := httptest.NewRecorder()
+	d.HandlerFunc()(res, req)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
@@ -0,0 +1,17 @@
+package tuning
+
+// Config contains configuration fields for tuning the Go runtime.
+type Config struct {
+	MaxProcs    int   `json:"max_procs" yaml:"max_procs"`
+	GCPercent   int   `json:"gc_percent" yaml:"gc_percent"`
+	BallastSize int64 `json:"ballast_size" yaml:"ballast_size"`
+}
+
+// NewConfig creates a new Config with default values.
+func NewConfig() Config {
+	return Config{
+		MaxProcs:    0,
+		GCPercent:   0,
+		BallastSize: 0,
+	}
+}
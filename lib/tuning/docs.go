@@ -0,0 +1,12 @@
+package tuning
+
+import "github.com/Jeffail/benthos/v3/internal/docs"
+
+// Spec returns a field spec for the runtime tuning configuration fields.
+func Spec() docs.FieldSpecs {
+	return docs.FieldSpecs{
+		docs.FieldInt("max_procs", "The maximum number of OS threads that can execute goroutines simultaneously, overriding `GOMAXPROCS`. By default this is the number of CPU cores of the host, which is often far higher than the CPU limit of a container. A value of zero leaves the default unchanged.", 2).HasDefault(0),
+		docs.FieldInt("gc_percent", "The percentage of heap growth since the previous garbage collection that triggers the next one, overriding `GOGC`. Higher values reduce the CPU spent on garbage collection at the cost of memory usage. A value of zero leaves the default unchanged, and a negative value disables garbage collection entirely.", 200).HasDefault(0),
+		docs.FieldInt("ballast_size", "The size in bytes of a memory ballast to allocate at startup. A ballast is never used but counts towards the heap size when pacing garbage collection, and therefore reduces the frequency of collections for services with a small live heap and a high allocation rate without consuming physical memory. A value of zero disables the ballast.", 1073741824).HasDefault(0),
+	}
+}
@@ -0,0 +1,4 @@
+// Package tuning provides options for tuning the Go runtime of a Benthos
+// service, such as the number of OS threads executing goroutines and the
+// aggressiveness of the garbage collector, which are applied at startup.
+package tuning
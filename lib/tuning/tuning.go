@@ -0,0 +1,47 @@
+package tuning
+
+import (
+	"runtime"
+	"runtime/debug"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+)
+
+// Apply tunes the Go runtime according to a config, and returns a function that
+// restores the previous settings and releases the memory ballast, which should
+// be called once the service has stopped.
+func Apply(conf Config, logger log.Modular) func() {
+	var restore []func()
+
+	if conf.MaxProcs > 0 {
+		prev := runtime.GOMAXPROCS(conf.MaxProcs)
+		restore = append(restore, func() {
+			runtime.GOMAXPROCS(prev)
+		})
+		logger.Infof("Set maximum procs to %v (previously %v)\n", conf.MaxProcs, prev)
+	}
+
+	if conf.GCPercent != 0 {
+		prev := debug.SetGCPercent(conf.GCPercent)
+		restore = append(restore, func() {
+			debug.SetGCPercent(prev)
+		})
+		logger.Infof("Set GC percent to %v (previously %v)\n", conf.GCPercent, prev)
+	}
+
+	if conf.BallastSize > 0 {
+		// The ballast is never written to and therefore the pages it spans are
+		// not backed by physical memory.
+		ballast := make([]byte, conf.BallastSize)
+		restore = append(restore, func() {
+			runtime.KeepAlive(ballast)
+		})
+		logger.Infof("Allocated a memory ballast of %v bytes\n", conf.BallastSize)
+	}
+
+	return func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			restore[i]()
+		}
+	}
+}
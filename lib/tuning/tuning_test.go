@@ -0,0 +1,38 @@
+package tuning
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/lib/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	procs := runtime.GOMAXPROCS(0)
+
+	release := Apply(NewConfig(), log.Noop())
+	assert.Equal(t, procs, runtime.GOMAXPROCS(0))
+	release()
+	assert.Equal(t, procs, runtime.GOMAXPROCS(0))
+}
+
+func TestApply(t *testing.T) {
+	procs := runtime.GOMAXPROCS(0)
+	gcPercent := debug.SetGCPercent(100)
+	debug.SetGCPercent(gcPercent)
+
+	conf := NewConfig()
+	conf.MaxProcs = procs + 1
+	conf.GCPercent = gcPercent + 50
+	conf.BallastSize = 1024
+
+	release := Apply(conf, log.Noop())
+	assert.Equal(t, procs+1, runtime.GOMAXPROCS(0))
+	assert.Equal(t, gcPercent+50, debug.SetGCPercent(gcPercent+50))
+
+	release()
+	assert.Equal(t, procs, runtime.GOMAXPROCS(0))
+	assert.Equal(t, gcPercent, debug.SetGCPercent(gcPercent))
+}
@@ -12,6 +12,7 @@ func FieldSpecs() docs.FieldSpecs {
 			docs.FieldAdvanced("id", "The ID of credentials to use."),
 			docs.FieldAdvanced("secret", "The secret for the credentials being used."),
 			docs.FieldAdvanced("token", "The token for the credentials being used, required when using short term credentials."),
+			docs.FieldAdvanced("from_ec2_role", "Use the credentials of a host EC2 machine configured to assume [an IAM role associated with the instance](https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/iam-roles-for-amazon-ec2.html).").HasType(docs.FieldTypeBool).AtVersion("3.54.0"),
 			docs.FieldAdvanced("role", "A role ARN to assume."),
 			docs.FieldAdvanced("role_external_id", "An external ID to provide when assuming a role."),
 		),
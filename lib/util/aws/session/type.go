@@ -3,6 +3,7 @@ package session
 import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 )
@@ -11,12 +12,13 @@ import (
 
 // CredentialsConfig contains configuration params for AWS credentials.
 type CredentialsConfig struct {
-	Profile    string `json:"profile" yaml:"profile"`
-	ID         string `json:"id" yaml:"id"`
-	Secret     string `json:"secret" yaml:"secret"`
-	Token      string `json:"token" yaml:"token"`
-	Role       string `json:"role" yaml:"role"`
-	ExternalID string `json:"role_external_id" yaml:"role_external_id"`
+	Profile     string `json:"profile" yaml:"profile"`
+	ID          string `json:"id" yaml:"id"`
+	Secret      string `json:"secret" yaml:"secret"`
+	Token       string `json:"token" yaml:"token"`
+	UseEC2Creds bool   `json:"from_ec2_role" yaml:"from_ec2_role"`
+	Role        string `json:"role" yaml:"role"`
+	ExternalID  string `json:"role_external_id" yaml:"role_external_id"`
 }
 
 // Config contains configuration fields for an AWS session. This config is
@@ -31,12 +33,13 @@ type Config struct {
 func NewConfig() Config {
 	return Config{
 		Credentials: CredentialsConfig{
-			Profile:    "",
-			ID:         "",
-			Secret:     "",
-			Token:      "",
-			Role:       "",
-			ExternalID: "",
+			Profile:     "",
+			ID:          "",
+			Secret:      "",
+			Token:       "",
+			UseEC2Creds: false,
+			Role:        "",
+			ExternalID:  "",
 		},
 		Endpoint: "",
 		Region:   "eu-west-1", // TODO: V4 empty by default
@@ -77,6 +80,10 @@ func (c Config) GetSession(opts ...func(*aws.Config)) (*session.Session, error)
 		return nil, err
 	}
 
+	if c.Credentials.UseEC2Creds {
+		sess.Config = sess.Config.WithCredentials(ec2rolecreds.NewCredentials(sess))
+	}
+
 	if len(c.Credentials.Role) > 0 {
 		var opts []func(*stscreds.AssumeRoleProvider)
 		if len(c.Credentials.ExternalID) > 0 {
@@ -0,0 +1,64 @@
+package session
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSessionStaticCredentials(t *testing.T) {
+	conf := NewConfig()
+	conf.Region = "us-east-1"
+	conf.Endpoint = "http://localhost:4566"
+	conf.Credentials.ID = "foo"
+	conf.Credentials.Secret = "bar"
+
+	sess, err := conf.GetSession()
+	require.NoError(t, err)
+
+	assert.Equal(t, "us-east-1", *sess.Config.Region)
+	assert.Equal(t, "http://localhost:4566", *sess.Config.Endpoint)
+
+	creds, err := sess.Config.Credentials.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "foo", creds.AccessKeyID)
+	assert.Equal(t, "bar", creds.SecretAccessKey)
+}
+
+func TestGetSessionEC2Role(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest/api/token":
+			_, _ = w.Write([]byte("footoken"))
+		case "/latest/meta-data/iam/security-credentials/":
+			_, _ = w.Write([]byte("foorole"))
+		case "/latest/meta-data/iam/security-credentials/foorole":
+			_, _ = fmt.Fprintf(w, `{"Code":"Success","AccessKeyId":"ec2id","SecretAccessKey":"ec2secret","Token":"ec2token","Expiration":%q}`,
+				time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	os.Setenv("AWS_EC2_METADATA_SERVICE_ENDPOINT", server.URL)
+	defer os.Unsetenv("AWS_EC2_METADATA_SERVICE_ENDPOINT")
+
+	conf := NewConfig()
+	conf.Credentials.UseEC2Creds = true
+
+	sess, err := conf.GetSession()
+	require.NoError(t, err)
+
+	creds, err := sess.Config.Credentials.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "ec2id", creds.AccessKeyID)
+	assert.Equal(t, "ec2secret", creds.SecretAccessKey)
+	assert.Equal(t, "ec2token", creds.SessionToken)
+}
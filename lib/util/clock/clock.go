@@ -0,0 +1,65 @@
+package clock
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+//------------------------------------------------------------------------------
+
+// Monotonic is a clock that measures time as the duration elapsed since a fixed
+// point. Unlike the wall clock its readings only ever move forward at a steady
+// rate, and therefore corrections to the system time (by NTP, for example)
+// cannot cause windows to close early or TTLs to expire en masse.
+//
+// Readings are plain durations so that they can be stored and compared
+// atomically, which is not possible with a time.Time without discarding its
+// monotonic component.
+type Monotonic interface {
+	// Elapsed returns the current reading of the clock.
+	Elapsed() time.Duration
+}
+
+//------------------------------------------------------------------------------
+
+type system struct {
+	epoch time.Time
+}
+
+func (s system) Elapsed() time.Duration {
+	// A time.Time obtained from time.Now carries a monotonic reading, which is
+	// what time.Since uses when both times have one.
+	return time.Since(s.epoch)
+}
+
+// System is a Monotonic clock backed by the monotonic clock of the operating
+// system, with readings measured from the moment the process started.
+var System Monotonic = system{epoch: time.Now()}
+
+//------------------------------------------------------------------------------
+
+// Manual is a Monotonic clock that only moves when advanced explicitly, which
+// is useful for testing time based behaviour deterministically.
+type Manual struct {
+	elapsed int64
+}
+
+// NewManual creates a Manual clock with a reading of zero.
+func NewManual() *Manual {
+	return &Manual{}
+}
+
+// Elapsed returns the current reading of the clock.
+func (m *Manual) Elapsed() time.Duration {
+	return time.Duration(atomic.LoadInt64(&m.elapsed))
+}
+
+// Advance moves the clock forward by a duration. Negative durations are
+// ignored as a monotonic clock never moves backwards.
+func (m *Manual) Advance(d time.Duration) {
+	if d > 0 {
+		atomic.AddInt64(&m.elapsed, int64(d))
+	}
+}
+
+//------------------------------------------------------------------------------
@@ -0,0 +1,33 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemClock(t *testing.T) {
+	first := System.Elapsed()
+	time.Sleep(time.Millisecond * 10)
+	second := System.Elapsed()
+	assert.GreaterOrEqual(t, int64(second-first), int64(time.Millisecond*10))
+}
+
+func TestSystemClockIgnoresWallClock(t *testing.T) {
+	// A time without a monotonic reading is subject to wall clock steps, and
+	// therefore the system clock must be based on an epoch that has one.
+	epoch := System.(system).epoch
+	assert.NotEqual(t, epoch.String(), epoch.Round(0).String())
+}
+
+func TestManualClock(t *testing.T) {
+	c := NewManual()
+	assert.Equal(t, time.Duration(0), c.Elapsed())
+
+	c.Advance(time.Second)
+	assert.Equal(t, time.Second, c.Elapsed())
+
+	c.Advance(-time.Hour)
+	assert.Equal(t, time.Second, c.Elapsed())
+}
@@ -0,0 +1,3 @@
+// Package clock implements a monotonic clock for measuring windows, delays and
+// TTLs in a way that is unaffected by steps of the wall clock.
+package clock
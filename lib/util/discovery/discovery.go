@@ -0,0 +1,308 @@
+// Package discovery resolves lists of addresses where each address is either
+// static or a reference to a service discovery system, which allows configs to
+// avoid embedding hard coded hosts.
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schemes of addresses that are resolved using service discovery.
+const (
+	SchemeDNSSRV = "dnssrv"
+	SchemeConsul = "consul"
+	SchemeEtcd   = "etcd"
+)
+
+// Description is a markdown description of the address formats supported by
+// this package, which can be included within the documentation of components.
+const Description = `Addresses can be resolved using service discovery:
+
+- ` + "`dnssrv://_service._proto.example.com`" + ` resolves a DNS SRV record.
+- ` + "`consul://127.0.0.1:8500/service-name`" + ` resolves the healthy instances of a Consul service, where the query parameters ` + "`tag`" + ` and ` + "`dc`" + ` can be used to filter instances. When the host is omitted (` + "`consul:///service-name`" + `) the environment variable ` + "`CONSUL_HTTP_ADDR`" + ` is used, otherwise ` + "`127.0.0.1:8500`" + `.
+- ` + "`etcd://127.0.0.1:2379/services/foo/`" + ` resolves the values of all etcd keys prefixed with the path (here ` + "`/services/foo/`" + `), where each value is an address.
+
+The query parameter ` + "`scheme=https`" + ` connects to Consul and etcd over HTTPS.`
+
+//------------------------------------------------------------------------------
+
+// Resolver resolves a list of addresses.
+type Resolver struct {
+	static  []string
+	dynamic []*url.URL
+
+	client    *http.Client
+	lookupSRV func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// NewResolver creates a resolver for a list of addresses, where each address
+// is either static or a URL with a service discovery scheme.
+func NewResolver(addrs []string) (*Resolver, error) {
+	r := &Resolver{
+		client:    &http.Client{Timeout: time.Second * 10},
+		lookupSRV: net.DefaultResolver.LookupSRV,
+	}
+	for _, addr := range addrs {
+		i := strings.Index(addr, "://")
+		if i < 0 {
+			r.static = append(r.static, addr)
+			continue
+		}
+		switch addr[:i] {
+		case SchemeDNSSRV, SchemeConsul, SchemeEtcd:
+		default:
+			r.static = append(r.static, addr)
+			continue
+		}
+		u, err := url.Parse(addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse discovery address '%v': %w", addr, err)
+		}
+		if u.Scheme == SchemeDNSSRV && u.Host == "" {
+			return nil, fmt.Errorf("discovery address '%v' is missing a name", addr)
+		}
+		if u.Scheme != SchemeDNSSRV && strings.Trim(u.Path, "/") == "" {
+			return nil, fmt.Errorf("discovery address '%v' is missing a path", addr)
+		}
+		r.dynamic = append(r.dynamic, u)
+	}
+	return r, nil
+}
+
+// IsDynamic returns true if any of the addresses are resolved using service
+// discovery.
+func (r *Resolver) IsDynamic() bool {
+	return len(r.dynamic) > 0
+}
+
+// Resolve returns the list of static addresses followed by the sorted
+// addresses resolved using service discovery. An error is returned if any
+// resolution fails or if no addresses are found.
+func (r *Resolver) Resolve(ctx context.Context) ([]string, error) {
+	addrs := append([]string{}, r.static...)
+	for _, u := range r.dynamic {
+		var resolved []string
+		var err error
+		switch u.Scheme {
+		case SchemeDNSSRV:
+			resolved, err = r.resolveSRV(ctx, u)
+		case SchemeConsul:
+			resolved, err = r.resolveConsul(ctx, u)
+		case SchemeEtcd:
+			resolved, err = r.resolveEtcd(ctx, u)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve '%v': %w", u.Redacted(), err)
+		}
+		sort.Strings(resolved)
+		addrs = append(addrs, resolved...)
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("no addresses were resolved")
+	}
+	return addrs, nil
+}
+
+// Watch resolves the addresses periodically until the context is cancelled,
+// calling onChange whenever they differ from the previous resolution, starting
+// with last. Resolution errors are passed to onErr and otherwise ignored, so
+// that an outage of a discovery system does not disrupt connections.
+func (r *Resolver) Watch(ctx context.Context, interval time.Duration, last []string, onChange func([]string), onErr func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		addrs, err := r.Resolve(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				onErr(err)
+			}
+			continue
+		}
+		if !equal(addrs, last) {
+			last = addrs
+			onChange(addrs)
+		}
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+//------------------------------------------------------------------------------
+
+func (r *Resolver) resolveSRV(ctx context.Context, u *url.URL) ([]string, error) {
+	_, srvs, err := r.lookupSRV(ctx, "", "", u.Host)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]string, 0, len(srvs))
+	for _, srv := range srvs {
+		addrs = append(addrs, net.JoinHostPort(strings.TrimSuffix(srv.Target, "."), strconv.Itoa(int(srv.Port))))
+	}
+	return addrs, nil
+}
+
+func apiURL(u *url.URL, defaultHost string) *url.URL {
+	api := &url.URL{
+		Scheme: "http",
+		Host:   u.Host,
+		User:   u.User,
+	}
+	if s := u.Query().Get("scheme"); s != "" {
+		api.Scheme = s
+	}
+	if api.Host == "" {
+		api.Host = defaultHost
+	}
+	return api
+}
+
+func (r *Resolver) do(req *http.Request, resBody interface{}) error {
+	res, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %v: %s", res.StatusCode, bytes.TrimSpace(body))
+	}
+	return json.Unmarshal(body, resBody)
+}
+
+func (r *Resolver) resolveConsul(ctx context.Context, u *url.URL) ([]string, error) {
+	defaultHost := os.Getenv("CONSUL_HTTP_ADDR")
+	if i := strings.Index(defaultHost, "://"); i >= 0 {
+		defaultHost = defaultHost[i+3:]
+	}
+	if defaultHost == "" {
+		defaultHost = "127.0.0.1:8500"
+	}
+
+	api := apiURL(u, defaultHost)
+	api.Path = "/v1/health/service/" + strings.Trim(u.Path, "/")
+
+	query := url.Values{}
+	query.Set("passing", "1")
+	for _, k := range []string{"tag", "dc"} {
+		if v := u.Query().Get(k); v != "" {
+			query.Set(k, v)
+		}
+	}
+	api.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []struct {
+		Node struct {
+			Address string
+		}
+		Service struct {
+			Address string
+			Port    int
+		}
+	}
+	if err := r.do(req, &entries); err != nil {
+		return nil, err
+	}
+
+	addrs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		host := e.Service.Address
+		if host == "" {
+			host = e.Node.Address
+		}
+		addrs = append(addrs, net.JoinHostPort(host, strconv.Itoa(e.Service.Port)))
+	}
+	return addrs, nil
+}
+
+// prefixRangeEnd returns the smallest key that is greater than all keys with
+// a prefix, as expected by etcd range requests.
+func prefixRangeEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// The prefix is all 0xff bytes, and therefore the range has no end.
+	return []byte{0}
+}
+
+func (r *Resolver) resolveEtcd(ctx context.Context, u *url.URL) ([]string, error) {
+	api := apiURL(u, "127.0.0.1:2379")
+	api.Path = "/v3/kv/range"
+
+	prefix := []byte(u.Path)
+	reqBody, err := json.Marshal(map[string]string{
+		"key":       base64.StdEncoding.EncodeToString(prefix),
+		"range_end": base64.StdEncoding.EncodeToString(prefixRangeEnd(prefix)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, api.String(), bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var resBody struct {
+		KVs []struct {
+			Value string `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := r.do(req, &resBody); err != nil {
+		return nil, err
+	}
+
+	addrs := make([]string, 0, len(resBody.KVs))
+	for _, kv := range resBody.KVs {
+		v, err := base64.StdEncoding.DecodeString(kv.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode value: %w", err)
+		}
+		if addr := strings.TrimSpace(string(v)); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs, nil
+}
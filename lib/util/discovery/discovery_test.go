@@ -0,0 +1,165 @@
+package discovery
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolverStatic(t *testing.T) {
+	r, err := NewResolver([]string{"localhost:9092", "http://foo:4195"})
+	require.NoError(t, err)
+	assert.False(t, r.IsDynamic())
+
+	addrs, err := r.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"localhost:9092", "http://foo:4195"}, addrs)
+}
+
+func TestResolverBadAddresses(t *testing.T) {
+	for _, addr := range []string{"dnssrv://", "consul://localhost:8500", "etcd://localhost:2379/"} {
+		_, err := NewResolver([]string{addr})
+		assert.Error(t, err, addr)
+	}
+}
+
+func TestResolverSRV(t *testing.T) {
+	r, err := NewResolver([]string{"static:1234", "dnssrv://_kafka._tcp.example.com"})
+	require.NoError(t, err)
+	assert.True(t, r.IsDynamic())
+
+	r.lookupSRV = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		assert.Equal(t, "_kafka._tcp.example.com", name)
+		return "", []*net.SRV{
+			{Target: "kafka2.example.com.", Port: 9092},
+			{Target: "kafka1.example.com.", Port: 9093},
+		}, nil
+	}
+
+	addrs, err := r.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"static:1234", "kafka1.example.com:9093", "kafka2.example.com:9092"}, addrs)
+}
+
+func TestResolverConsul(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/health/service/foo", r.URL.Path)
+		assert.Equal(t, "1", r.URL.Query().Get("passing"))
+		assert.Equal(t, "bar", r.URL.Query().Get("tag"))
+		_, _ = w.Write([]byte(`[
+  {"Node":{"Address":"10.0.0.1"},"Service":{"Address":"","Port":8080}},
+  {"Node":{"Address":"10.0.0.2"},"Service":{"Address":"10.0.1.2","Port":8081}}
+]`))
+	}))
+	defer ts.Close()
+
+	r, err := NewResolver([]string{"consul://" + strings.TrimPrefix(ts.URL, "http://") + "/foo?tag=bar"})
+	require.NoError(t, err)
+
+	addrs, err := r.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1:8080", "10.0.1.2:8081"}, addrs)
+}
+
+func TestResolverEtcd(t *testing.T) {
+	b64 := base64.StdEncoding.EncodeToString
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/kv/range", r.URL.Path)
+
+		var reqBody map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reqBody))
+		assert.Equal(t, b64([]byte("/services/foo/")), reqBody["key"])
+		assert.Equal(t, b64([]byte("/services/foo0")), reqBody["range_end"])
+
+		_, _ = w.Write([]byte(`{"kvs":[{"value":"` + b64([]byte("foo2:80")) + `"},{"value":"` + b64([]byte("foo1:80\n")) + `"}]}`))
+	}))
+	defer ts.Close()
+
+	r, err := NewResolver([]string{"etcd://" + strings.TrimPrefix(ts.URL, "http://") + "/services/foo/"})
+	require.NoError(t, err)
+
+	addrs, err := r.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo1:80", "foo2:80"}, addrs)
+}
+
+func TestResolverEmpty(t *testing.T) {
+	r, err := NewResolver([]string{"dnssrv://_kafka._tcp.example.com"})
+	require.NoError(t, err)
+
+	r.lookupSRV = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		return "", nil, nil
+	}
+
+	_, err = r.Resolve(context.Background())
+	require.EqualError(t, err, "no addresses were resolved")
+}
+
+func TestResolverWatch(t *testing.T) {
+	r, err := NewResolver([]string{"dnssrv://_kafka._tcp.example.com"})
+	require.NoError(t, err)
+
+	var mut sync.Mutex
+	srvs := []*net.SRV{{Target: "foo", Port: 1}}
+	var lookupErr error
+	r.lookupSRV = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		mut.Lock()
+		defer mut.Unlock()
+		return "", srvs, lookupErr
+	}
+
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	changes := make(chan []string)
+	errs := make(chan error, 10)
+	go r.Watch(ctx, time.Millisecond, []string{"foo:1"}, func(addrs []string) {
+		changes <- addrs
+	}, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	select {
+	case addrs := <-changes:
+		t.Fatalf("unexpected change: %v", addrs)
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	// Failed resolutions are not treated as a change.
+	mut.Lock()
+	lookupErr = errors.New("nope")
+	mut.Unlock()
+
+	select {
+	case err := <-errs:
+		assert.Contains(t, err.Error(), "nope")
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	mut.Lock()
+	lookupErr = nil
+	srvs = []*net.SRV{{Target: "foo", Port: 1}, {Target: "bar", Port: 2}}
+	mut.Unlock()
+
+	select {
+	case addrs := <-changes:
+		assert.Equal(t, []string{"bar:2", "foo:1"}, addrs)
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+}
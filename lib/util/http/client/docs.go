@@ -2,6 +2,7 @@ package client
 
 import (
 	"github.com/Jeffail/benthos/v3/internal/docs"
+	"github.com/Jeffail/benthos/v3/lib/util/discovery"
 	"github.com/Jeffail/benthos/v3/lib/util/http/auth"
 	"github.com/Jeffail/benthos/v3/lib/util/tls"
 )
@@ -29,6 +30,18 @@ func FieldSpecs() docs.FieldSpecs {
 		docs.FieldInt("drop_on", "A list of status codes whereby the request should be considered to have failed but retries should not be attempted. This is useful for preventing wasted retries for requests that will never succeed. Note that with these status codes the _request_ is dropped, but _message_ that caused the request will not be dropped.").Array().Advanced(),
 		docs.FieldInt("successful_on", "A list of status codes whereby the attempt should be considered successful, this is useful for dropping requests that return non-2XX codes indicating that the message has been dealt with, such as a 303 See Other or a 409 Conflict. All 2XX codes are considered successful unless they are present within `backoff_on` or `drop_on`, regardless of this field.").Array().Advanced(),
 		docs.FieldString("proxy_url", "An optional HTTP proxy URL.").Advanced(),
+		docs.FieldAdvanced(
+			"transport", "Customise the pool of connections used for requests. Connections are shared between all components with matching `tls`, `proxy_url` and `transport` fields, including components of different types, and the pool is closed once none of those components remain.",
+		).WithChildren(
+			docs.FieldInt("max_idle_connections_per_host", "The maximum number of idle connections to keep open to each host, which should be at least the number of requests that are in flight to a host at any given time in order to avoid creating a new connection for each request. When zero the Go default of two connections per host is used."),
+			docs.FieldString("idle_connection_timeout", "The maximum period of time that an idle connection remains open."),
+			docs.FieldString("dns_cache_ttl", "An optional period of time to cache the results of DNS lookups for, which reduces the cost of establishing new connections. If empty DNS lookups are not cached.", "30s"),
+			docs.FieldString(
+				"discovery", "An optional list of addresses to connect to instead of the host of the URL, which is still used for the `Host` header and TLS verification. Each new connection is made to the next address of the list in turn. "+discovery.Description,
+				[]string{"10.0.0.1:8080", "10.0.0.2:8080"}, []string{"consul:///api"},
+			).Array().AtVersion("3.54.0"),
+			docs.FieldString("discovery_interval", "When any of the `discovery` addresses are resolved using service discovery, the period of time after which they are resolved again. Existing connections to addresses that are no longer resolved are closed once idle.").AtVersion("3.54.0"),
+		).AtVersion("3.54.0"),
 	)
 
 	return httpSpecs
@@ -48,10 +48,32 @@ type Config struct {
 	SuccessfulOn        []int             `json:"successful_on" yaml:"successful_on"`
 	TLS                 tls.Config        `json:"tls" yaml:"tls"`
 	ProxyURL            string            `json:"proxy_url" yaml:"proxy_url"`
+	Transport           TransportConfig   `json:"transport" yaml:"transport"`
 	auth.Config         `json:",inline" yaml:",inline"`
 	OAuth2              auth.OAuth2Config `json:"oauth2" yaml:"oauth2"`
 }
 
+// TransportConfig contains configuration fields for the connection pool of an
+// HTTP client.
+type TransportConfig struct {
+	MaxIdleConnsPerHost int      `json:"max_idle_connections_per_host" yaml:"max_idle_connections_per_host"`
+	IdleConnTimeout     string   `json:"idle_connection_timeout" yaml:"idle_connection_timeout"`
+	DNSCacheTTL         string   `json:"dns_cache_ttl" yaml:"dns_cache_ttl"`
+	Discovery           []string `json:"discovery" yaml:"discovery"`
+	DiscoveryInterval   string   `json:"discovery_interval" yaml:"discovery_interval"`
+}
+
+// NewTransportConfig creates a new TransportConfig with default values.
+func NewTransportConfig() TransportConfig {
+	return TransportConfig{
+		MaxIdleConnsPerHost: 0,
+		IdleConnTimeout:     "90s",
+		DNSCacheTTL:         "",
+		Discovery:           []string{},
+		DiscoveryInterval:   "30s",
+	}
+}
+
 // NewConfig creates a new Config with default values.
 func NewConfig() Config {
 	return Config{
@@ -70,6 +92,7 @@ func NewConfig() Config {
 		DropOn:              []int{},
 		SuccessfulOn:        []int{},
 		TLS:                 tls.NewConfig(),
+		Transport:           NewTransportConfig(),
 		Config:              auth.NewConfig(),
 		OAuth2:              auth.NewOAuth2Config(),
 	}
@@ -0,0 +1,94 @@
+// Package eventhub provides the settings required to connect to Azure Event
+// Hubs through the Kafka compatible endpoint of a namespace.
+package eventhub
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/Jeffail/benthos/v3/lib/util/kafka/sasl"
+	btls "github.com/Jeffail/benthos/v3/lib/util/tls"
+	"github.com/Shopify/sarama"
+)
+
+// kafkaPort is the port of the Kafka compatible endpoint of a namespace.
+const kafkaPort = "9093"
+
+// connectionStringUser is the SASL user that indicates that the password is a
+// connection string.
+const connectionStringUser = "$ConnectionString"
+
+// Endpoint contains the Kafka connection details parsed from a connection
+// string of an Event Hubs namespace or event hub.
+type Endpoint struct {
+	// Address is the address of the Kafka endpoint of the namespace.
+	Address string
+
+	// EventHub is the event hub named by the EntityPath of the connection
+	// string, which is empty for a connection string of a namespace.
+	EventHub string
+
+	connectionString string
+}
+
+// ParseConnectionString parses a connection string of the form
+// `Endpoint=sb://<namespace>.servicebus.windows.net/;SharedAccessKeyName=<name>;SharedAccessKey=<key>`,
+// optionally followed by `;EntityPath=<event hub>`.
+func ParseConnectionString(connStr string) (Endpoint, error) {
+	e := Endpoint{connectionString: connStr}
+	for _, field := range strings.Split(connStr, ";") {
+		// Shared access keys are base64 encoded and may therefore contain '='.
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch strings.ToLower(kv[0]) {
+		case "endpoint":
+			u, err := url.Parse(kv[1])
+			if err != nil || u.Hostname() == "" {
+				return Endpoint{}, fmt.Errorf("connection string endpoint '%v' is not a valid URL", kv[1])
+			}
+			e.Address = net.JoinHostPort(u.Hostname(), kafkaPort)
+		case "entitypath":
+			e.EventHub = kv[1]
+		}
+	}
+	if e.Address == "" {
+		return Endpoint{}, errors.New("connection string does not contain an Endpoint")
+	}
+	return e, nil
+}
+
+// Topic returns the Kafka topic of an event hub, which is the configured event
+// hub when not empty and otherwise the event hub of the connection string.
+func (e Endpoint) Topic(eventHub string) (string, error) {
+	if eventHub == "" {
+		if e.EventHub == "" {
+			return "", errors.New("an event hub must be specified when the connection string does not contain an EntityPath")
+		}
+		return e.EventHub, nil
+	}
+	if e.EventHub != "" && e.EventHub != eventHub {
+		return "", fmt.Errorf("event hub '%v' does not match the EntityPath '%v' of the connection string", eventHub, e.EventHub)
+	}
+	return eventHub, nil
+}
+
+// TLS returns the TLS settings required by the endpoint.
+func (e Endpoint) TLS() btls.Config {
+	conf := btls.NewConfig()
+	conf.Enabled = true
+	return conf
+}
+
+// SASL returns the SASL settings that authenticate with the connection string.
+func (e Endpoint) SASL() sasl.Config {
+	conf := sasl.NewConfig()
+	conf.Mechanism = sarama.SASLTypePlaintext
+	conf.User = connectionStringUser
+	conf.Password = e.connectionString
+	return conf
+}
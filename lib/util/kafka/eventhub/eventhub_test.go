@@ -0,0 +1,58 @@
+package eventhub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConnectionString(t *testing.T) {
+	connStr := "Endpoint=sb://foo.servicebus.windows.net/;SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=YmFyYmF6=;EntityPath=bar"
+
+	e, err := ParseConnectionString(connStr)
+	require.NoError(t, err)
+	assert.Equal(t, "foo.servicebus.windows.net:9093", e.Address)
+	assert.Equal(t, "bar", e.EventHub)
+
+	topic, err := e.Topic("")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", topic)
+
+	topic, err = e.Topic("bar")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", topic)
+
+	_, err = e.Topic("baz")
+	require.Error(t, err)
+
+	assert.True(t, e.TLS().Enabled)
+	s := e.SASL()
+	assert.Equal(t, "PLAIN", s.Mechanism)
+	assert.Equal(t, "$ConnectionString", s.User)
+	assert.Equal(t, connStr, s.Password)
+}
+
+func TestParseConnectionStringNamespace(t *testing.T) {
+	e, err := ParseConnectionString("Endpoint=sb://foo.servicebus.windows.net/;SharedAccessKeyName=a;SharedAccessKey=b")
+	require.NoError(t, err)
+	assert.Equal(t, "", e.EventHub)
+
+	_, err = e.Topic("")
+	require.Error(t, err)
+
+	topic, err := e.Topic("baz")
+	require.NoError(t, err)
+	assert.Equal(t, "baz", topic)
+}
+
+func TestParseConnectionStringErrors(t *testing.T) {
+	for _, connStr := range []string{
+		"",
+		"SharedAccessKeyName=a;SharedAccessKey=b",
+		"Endpoint=;SharedAccessKeyName=a",
+	} {
+		_, err := ParseConnectionString(connStr)
+		assert.Error(t, err, connStr)
+	}
+}
@@ -19,18 +19,37 @@ var (
 // Config contains configuration for SASL based authentication.
 // TODO: V4 Remove "enabled" and set a default mechanism
 type Config struct {
-	Enabled     bool   `json:"enabled" yaml:"enabled"` // DEPRECATED
-	Mechanism   string `json:"mechanism" yaml:"mechanism"`
-	User        string `json:"user" yaml:"user"`
-	Password    string `json:"password" yaml:"password"`
-	AccessToken string `json:"access_token" yaml:"access_token"`
-	TokenCache  string `json:"token_cache" yaml:"token_cache"`
-	TokenKey    string `json:"token_key" yaml:"token_key"`
+	Enabled     bool           `json:"enabled" yaml:"enabled"` // DEPRECATED
+	Mechanism   string         `json:"mechanism" yaml:"mechanism"`
+	User        string         `json:"user" yaml:"user"`
+	Password    string         `json:"password" yaml:"password"`
+	AccessToken string         `json:"access_token" yaml:"access_token"`
+	TokenCache  string         `json:"token_cache" yaml:"token_cache"`
+	TokenKey    string         `json:"token_key" yaml:"token_key"`
+	Kerberos    KerberosConfig `json:"kerberos" yaml:"kerberos"`
+}
+
+// KerberosConfig contains configuration for the GSSAPI mechanism, where
+// authentication is performed with Kerberos.
+type KerberosConfig struct {
+	ServiceName     string `json:"service_name" yaml:"service_name"`
+	Realm           string `json:"realm" yaml:"realm"`
+	ConfigPath      string `json:"config_path" yaml:"config_path"`
+	KeyTabPath      string `json:"keytab_path" yaml:"keytab_path"`
+	DisablePAFXFAST bool   `json:"disable_pafxfast" yaml:"disable_pafxfast"`
 }
 
 // NewConfig returns a new SASL config for Kafka with default values.
 func NewConfig() Config {
-	return Config{} // Well, that seemed pointless.
+	return Config{
+		Kerberos: KerberosConfig{
+			ServiceName:     "kafka",
+			Realm:           "",
+			ConfigPath:      "/etc/krb5.conf",
+			KeyTabPath:      "",
+			DisablePAFXFAST: false,
+		},
+	}
 }
 
 // FieldSpec returns specs for SASL fields.
@@ -42,12 +61,20 @@ func FieldSpec() docs.FieldSpec {
 			sarama.SASLTypeOAuth, "OAuth Bearer based authentication.",
 			sarama.SASLTypeSCRAMSHA256, "Authentication using the SCRAM-SHA-256 mechanism.",
 			sarama.SASLTypeSCRAMSHA512, "Authentication using the SCRAM-SHA-512 mechanism.",
+			sarama.SASLTypeGSSAPI, "Kerberos authentication, configured with the `kerberos` field.",
 		),
-		docs.FieldCommon("user", "A `"+sarama.SASLTypePlaintext+"` username. It is recommended that you use environment variables to populate this field.", "${USER}"),
-		docs.FieldCommon("password", "A `"+sarama.SASLTypePlaintext+"` password. It is recommended that you use environment variables to populate this field.", "${PASSWORD}"),
+		docs.FieldCommon("user", "A username to authenticate with. For the `"+sarama.SASLTypeGSSAPI+"` mechanism this is the Kerberos principal. It is recommended that you use environment variables to populate this field.", "${USER}"),
+		docs.FieldCommon("password", "A password to authenticate with, which is not required for the `"+sarama.SASLTypeGSSAPI+"` mechanism when a `kerberos.keytab_path` is specified. It is recommended that you use environment variables to populate this field.", "${PASSWORD}"),
 		docs.FieldAdvanced("access_token", "A static `"+sarama.SASLTypeOAuth+"` access token"),
 		docs.FieldAdvanced("token_cache", "Instead of using a static `access_token` allows you to query a [`cache`](/docs/components/caches/about) resource to fetch `"+sarama.SASLTypeOAuth+"` tokens from"),
 		docs.FieldAdvanced("token_key", "Required when using a `token_cache`, the key to query the cache with for tokens."),
+		docs.FieldAdvanced("kerberos", "Configuration for the `"+sarama.SASLTypeGSSAPI+"` mechanism, where the principal is authenticated with either the `password` or the keys of a keytab file.").WithChildren(
+			docs.FieldAdvanced("service_name", "The service name of the Kafka brokers."),
+			docs.FieldAdvanced("realm", "The Kerberos realm of the principal.", "EXAMPLE.COM"),
+			docs.FieldAdvanced("config_path", "The path of a Kerberos configuration file."),
+			docs.FieldAdvanced("keytab_path", "An optional path of a keytab file containing the keys of the principal, which are used instead of the `password` when specified.", "/etc/security/kafka.keytab"),
+			docs.FieldAdvanced("disable_pafxfast", "Whether to disable the PA-FX-FAST pre-authentication type, which is required by some Active Directory servers."),
+		).AtVersion("3.54.0"),
 	)
 }
 
@@ -88,6 +115,20 @@ func (s Config) Apply(mgr types.Manager, conf *sarama.Config) error {
 	case sarama.SASLTypePlaintext:
 		conf.Net.SASL.User = s.User
 		conf.Net.SASL.Password = s.Password
+	case sarama.SASLTypeGSSAPI:
+		conf.Net.SASL.GSSAPI = sarama.GSSAPIConfig{
+			AuthType:           sarama.KRB5_USER_AUTH,
+			KerberosConfigPath: s.Kerberos.ConfigPath,
+			ServiceName:        s.Kerberos.ServiceName,
+			Username:           s.User,
+			Password:           s.Password,
+			Realm:              s.Kerberos.Realm,
+			DisablePAFXFAST:    s.Kerberos.DisablePAFXFAST,
+		}
+		if s.Kerberos.KeyTabPath != "" {
+			conf.Net.SASL.GSSAPI.AuthType = sarama.KRB5_KEYTAB_AUTH
+			conf.Net.SASL.GSSAPI.KeyTabPath = s.Kerberos.KeyTabPath
+		}
 	case "":
 		return nil
 	default:
@@ -97,6 +97,64 @@ func TestApplyPlaintextDeprecated(t *testing.T) {
 	}
 }
 
+func TestApplyGSSAPIPassword(t *testing.T) {
+	conf := &sarama.Config{}
+
+	saslConf := NewConfig()
+	saslConf.Mechanism = sarama.SASLTypeGSSAPI
+	saslConf.User = "foo"
+	saslConf.Password = "bar"
+	saslConf.Kerberos.Realm = "EXAMPLE.COM"
+
+	err := saslConf.Apply(types.NoopMgr(), conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !conf.Net.SASL.Enable {
+		t.Errorf("SASL not enabled")
+	}
+
+	if conf.Net.SASL.Mechanism != sarama.SASLTypeGSSAPI {
+		t.Errorf("Wrong SASL mechanism: %v != %v", conf.Net.SASL.Mechanism, sarama.SASLTypeGSSAPI)
+	}
+
+	exp := sarama.GSSAPIConfig{
+		AuthType:           sarama.KRB5_USER_AUTH,
+		KerberosConfigPath: "/etc/krb5.conf",
+		ServiceName:        "kafka",
+		Username:           "foo",
+		Password:           "bar",
+		Realm:              "EXAMPLE.COM",
+	}
+	if act := conf.Net.SASL.GSSAPI; act != exp {
+		t.Errorf("Wrong GSSAPI config: %+v != %+v", act, exp)
+	}
+}
+
+func TestApplyGSSAPIKeyTab(t *testing.T) {
+	conf := &sarama.Config{}
+
+	saslConf := NewConfig()
+	saslConf.Mechanism = sarama.SASLTypeGSSAPI
+	saslConf.User = "foo"
+	saslConf.Kerberos.Realm = "EXAMPLE.COM"
+	saslConf.Kerberos.KeyTabPath = "/etc/foo.keytab"
+
+	err := saslConf.Apply(types.NoopMgr(), conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.Net.SASL.GSSAPI.AuthType != sarama.KRB5_KEYTAB_AUTH {
+		t.Errorf("Wrong GSSAPI auth type: %v != %v", conf.Net.SASL.GSSAPI.AuthType, sarama.KRB5_KEYTAB_AUTH)
+	}
+
+	if conf.Net.SASL.GSSAPI.KeyTabPath != "/etc/foo.keytab" {
+		t.Errorf("Wrong GSSAPI keytab path: %v != %v", conf.Net.SASL.GSSAPI.KeyTabPath, "/etc/foo.keytab")
+	}
+}
+
 func TestApplyOAuthBearerStaticProvider(t *testing.T) {
 	conf := &sarama.Config{}
 
@@ -0,0 +1,119 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	xproxy "golang.org/x/net/proxy"
+)
+
+//------------------------------------------------------------------------------
+
+// Dialer establishes TCP connections through a proxy.
+type Dialer interface {
+	Dial(network, address string) (net.Conn, error)
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// NewDialer creates a dialer that establishes TCP connections through either a
+// SOCKS5 proxy or an HTTP proxy that supports the CONNECT method. Connections
+// to the proxy itself are established with the forward dialer.
+func NewDialer(proxyURL string, forward *net.Dialer) (Dialer, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proxy_url string: %v", err)
+	}
+	if forward == nil {
+		forward = &net.Dialer{}
+	}
+	switch u.Scheme {
+	case "http":
+		return &httpConnectDialer{proxyURL: u, forward: forward}, nil
+	case "socks5", "socks5h":
+		d, err := xproxy.FromURL(u, forward)
+		if err != nil {
+			return nil, err
+		}
+		pd, ok := d.(Dialer)
+		if !ok {
+			return nil, fmt.Errorf("unable to apply proxy_url, unexpected dialer type %T", d)
+		}
+		return pd, nil
+	}
+	return nil, fmt.Errorf("proxy_url scheme '%v' is not supported", u.Scheme)
+}
+
+//------------------------------------------------------------------------------
+
+// httpConnectDialer establishes connections through an HTTP proxy by issuing
+// a CONNECT request for the target address.
+type httpConnectDialer struct {
+	proxyURL *url.URL
+	forward  *net.Dialer
+}
+
+func (h *httpConnectDialer) Dial(network, address string) (net.Conn, error) {
+	return h.DialContext(context.Background(), network, address)
+}
+
+func (h *httpConnectDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := h.forward.DialContext(ctx, "tcp", h.proxyURL.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+	if u := h.proxyURL.User; u != nil {
+		password, _ := u.Password()
+		creds := base64.StdEncoding.EncodeToString([]byte(u.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+creds)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy refused connection: %v", res.Status)
+	}
+	if br.Buffered() > 0 {
+		// The target has already sent data that was consumed by the reader.
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (b *bufferedConn) Read(p []byte) (int, error) {
+	return b.r.Read(p)
+}
+
+//------------------------------------------------------------------------------
@@ -0,0 +1,4 @@
+// Package proxy provides dialers that establish TCP connections through HTTP
+// or SOCKS5 proxies, allowing components built upon network clients that
+// accept custom dialers to connect through a proxy.
+package proxy
@@ -0,0 +1,146 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+//------------------------------------------------------------------------------
+
+// Adaptive paces an activity shared by any number of goroutines, where the
+// interval between each permitted attempt grows multiplicatively whenever the
+// target signals that it is being throttled, and shrinks gradually with each
+// success until attempts are no longer paced at all.
+type Adaptive struct {
+	mut      sync.Mutex
+	interval time.Duration
+	next     time.Time
+
+	minInterval time.Duration
+	maxInterval time.Duration
+	recovery    float64
+}
+
+// NewAdaptive creates a new adaptive throttle, which does not pace attempts
+// until a throttling signal is received.
+func NewAdaptive(options ...func(*Adaptive)) *Adaptive {
+	a := &Adaptive{
+		minInterval: time.Millisecond * 50,
+		maxInterval: time.Second * 10,
+		recovery:    0.95,
+	}
+	for _, option := range options {
+		option(a)
+	}
+	return a
+}
+
+//------------------------------------------------------------------------------
+
+// OptAdaptiveMinInterval sets the interval between attempts after the first
+// throttling signal, below which a recovering throttle stops pacing attempts.
+func OptAdaptiveMinInterval(period time.Duration) func(*Adaptive) {
+	return func(a *Adaptive) {
+		a.minInterval = period
+	}
+}
+
+// OptAdaptiveMaxInterval sets the maximum interval between attempts.
+func OptAdaptiveMaxInterval(period time.Duration) func(*Adaptive) {
+	return func(a *Adaptive) {
+		a.maxInterval = period
+	}
+}
+
+// OptAdaptiveRecovery sets the factor, between zero and one, that the interval
+// between attempts is multiplied by after each success.
+func OptAdaptiveRecovery(factor float64) func(*Adaptive) {
+	return func(a *Adaptive) {
+		a.recovery = factor
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// Wait blocks until the next attempt is permitted (returning true), or the
+// context is cancelled (returning false).
+func (a *Adaptive) Wait(ctx context.Context) bool {
+	a.mut.Lock()
+	now := time.Now()
+	if a.interval == 0 && !a.next.After(now) {
+		a.mut.Unlock()
+		return true
+	}
+	at := a.next
+	if at.Before(now) {
+		at = now
+	}
+	a.next = at.Add(a.interval)
+	a.mut.Unlock()
+
+	if wait := time.Until(at); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// Throttled signals that an attempt was throttled by the target, which doubles
+// the interval between attempts. When the target provides a period of time to
+// wait before retrying then no attempts are permitted until it has passed.
+func (a *Adaptive) Throttled(retryAfter time.Duration) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if a.interval *= 2; a.interval < a.minInterval {
+		a.interval = a.minInterval
+	}
+	if a.interval > a.maxInterval {
+		a.interval = a.maxInterval
+	}
+	if retryAfter > 0 {
+		if retryAfter > a.maxInterval {
+			retryAfter = a.maxInterval
+		}
+		if at := time.Now().Add(retryAfter); at.After(a.next) {
+			a.next = at
+		}
+	}
+}
+
+// Succeeded signals that an attempt was successful, which gradually reduces the
+// interval between attempts.
+func (a *Adaptive) Succeeded() {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if a.interval == 0 {
+		return
+	}
+	if a.interval = time.Duration(float64(a.interval) * a.recovery); a.interval < a.minInterval {
+		a.interval = 0
+	}
+}
+
+// Paced returns whether attempts are currently paced, either by an interval
+// between them or by a period of time to wait that hasn't yet passed. When it
+// returns false a call to Wait would return immediately.
+func (a *Adaptive) Paced() bool {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	return a.interval > 0 || a.next.After(time.Now())
+}
+
+// Interval returns the current interval between attempts, which is zero when
+// attempts are not paced.
+func (a *Adaptive) Interval() time.Duration {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	return a.interval
+}
+
+//------------------------------------------------------------------------------
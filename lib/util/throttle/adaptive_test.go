@@ -0,0 +1,123 @@
+package throttle
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveUnthrottled(t *testing.T) {
+	throt := NewAdaptive()
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		if !throt.Wait(context.Background()) {
+			t.Fatal("Wait returned false")
+		}
+	}
+	if dur := time.Since(start); dur > time.Millisecond*50 {
+		t.Errorf("Unthrottled waits took too long: %v", dur)
+	}
+	if exp, act := time.Duration(0), throt.Interval(); exp != act {
+		t.Errorf("Wrong interval: %v != %v", act, exp)
+	}
+}
+
+func TestAdaptiveBackoffAndRecovery(t *testing.T) {
+	throt := NewAdaptive(
+		OptAdaptiveMinInterval(time.Millisecond*10),
+		OptAdaptiveMaxInterval(time.Millisecond*50),
+		OptAdaptiveRecovery(0.5),
+	)
+
+	throt.Throttled(0)
+	if exp, act := time.Millisecond*10, throt.Interval(); exp != act {
+		t.Errorf("Wrong interval: %v != %v", act, exp)
+	}
+	throt.Throttled(0)
+	if exp, act := time.Millisecond*20, throt.Interval(); exp != act {
+		t.Errorf("Wrong interval: %v != %v", act, exp)
+	}
+	throt.Throttled(0)
+	throt.Throttled(0)
+	if exp, act := time.Millisecond*50, throt.Interval(); exp != act {
+		t.Errorf("Wrong interval: %v != %v", act, exp)
+	}
+
+	throt.Succeeded()
+	if exp, act := time.Millisecond*25, throt.Interval(); exp != act {
+		t.Errorf("Wrong interval: %v != %v", act, exp)
+	}
+	throt.Succeeded()
+	if exp, act := time.Millisecond*12+time.Microsecond*500, throt.Interval(); exp != act {
+		t.Errorf("Wrong interval: %v != %v", act, exp)
+	}
+	throt.Succeeded()
+	if exp, act := time.Duration(0), throt.Interval(); exp != act {
+		t.Errorf("Wrong interval: %v != %v", act, exp)
+	}
+}
+
+func TestAdaptivePacing(t *testing.T) {
+	throt := NewAdaptive(
+		OptAdaptiveMinInterval(time.Millisecond*20),
+		OptAdaptiveRecovery(1),
+	)
+	throt.Throttled(0)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if !throt.Wait(context.Background()) {
+			t.Fatal("Wait returned false")
+		}
+	}
+	if dur := time.Since(start); dur < time.Millisecond*60 {
+		t.Errorf("Throttled waits were not paced: %v", dur)
+	}
+}
+
+func TestAdaptiveRetryAfter(t *testing.T) {
+	throt := NewAdaptive()
+	throt.Throttled(time.Millisecond * 100)
+
+	start := time.Now()
+	if !throt.Wait(context.Background()) {
+		t.Fatal("Wait returned false")
+	}
+	if dur := time.Since(start); dur < time.Millisecond*90 {
+		t.Errorf("Retry after period was not respected: %v", dur)
+	}
+}
+
+func TestAdaptiveCancelled(t *testing.T) {
+	throt := NewAdaptive()
+	throt.Throttled(time.Hour)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer done()
+
+	if throt.Wait(ctx) {
+		t.Error("Wait returned true after cancellation")
+	}
+}
+
+func TestAdaptivePaced(t *testing.T) {
+	throt := NewAdaptive(OptAdaptiveMinInterval(time.Millisecond * 10))
+	if throt.Paced() {
+		t.Error("Expected unthrottled attempts to not be paced")
+	}
+
+	throt.Throttled(time.Millisecond * 50)
+	throt.Succeeded()
+	if exp, act := time.Duration(0), throt.Interval(); exp != act {
+		t.Errorf("Wrong interval: %v != %v", act, exp)
+	}
+	if !throt.Paced() {
+		t.Error("Expected attempts to be paced until the retry period passes")
+	}
+
+	<-time.After(time.Millisecond * 60)
+	if throt.Paced() {
+		t.Error("Expected attempts to not be paced after the retry period")
+	}
+}
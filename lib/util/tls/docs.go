@@ -19,6 +19,10 @@ func FieldSpec() docs.FieldSpec {
 			"enable_renegotiation", "Whether to allow the remote server to repeatedly request renegotiation. Enable this option if you're seeing the error message `local error: tls: no renegotiation`.",
 		).AtVersion("3.45.0").HasType(docs.FieldTypeBool).HasDefault(false),
 
+		docs.FieldAdvanced(
+			"server_name", "An optional server name to send with the TLS handshake (SNI) and to verify the certificate of the server against, which overrides the host name of the address connected to. This is useful when connecting through a proxy or to an address that doesn't match the certificate of the server.", "kafka.example.com",
+		).AtVersion("3.54.0").HasType(docs.FieldTypeString).HasDefault(""),
+
 		docs.FieldString(
 			"root_cas", "An optional root certificate authority to use. This is a string, representing a certificate chain from the parent trusted root certificate, to possible intermediate signing certificates, to the host certificate.", "-----BEGIN CERTIFICATE-----\n...\n-----END CERTIFICATE-----",
 		).HasDefault(""),
@@ -9,12 +9,20 @@ import (
 
 	// Import new service packages.
 	_ "github.com/Jeffail/benthos/v3/internal/impl/aws"
+	_ "github.com/Jeffail/benthos/v3/internal/impl/beanstalkd"
 	_ "github.com/Jeffail/benthos/v3/internal/impl/confluent"
+	_ "github.com/Jeffail/benthos/v3/internal/impl/docker"
+	_ "github.com/Jeffail/benthos/v3/internal/impl/fifo"
 	_ "github.com/Jeffail/benthos/v3/internal/impl/gcp"
 	_ "github.com/Jeffail/benthos/v3/internal/impl/generic"
+	_ "github.com/Jeffail/benthos/v3/internal/impl/ldap"
+	_ "github.com/Jeffail/benthos/v3/internal/impl/modbus"
 	_ "github.com/Jeffail/benthos/v3/internal/impl/mongodb"
 	_ "github.com/Jeffail/benthos/v3/internal/impl/nats"
+	_ "github.com/Jeffail/benthos/v3/internal/impl/postgresql"
 	_ "github.com/Jeffail/benthos/v3/internal/impl/pulsar"
+	_ "github.com/Jeffail/benthos/v3/internal/impl/shm"
+	_ "github.com/Jeffail/benthos/v3/internal/impl/snmp"
 	"github.com/Jeffail/benthos/v3/internal/template"
 )
 
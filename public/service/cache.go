@@ -93,7 +93,11 @@ func newReverseAirGapCache(c types.Cache) *reverseAirGapCache {
 }
 
 func (r *reverseAirGapCache) Get(ctx context.Context, key string) ([]byte, error) {
-	return r.c.Get(key)
+	b, err := r.c.Get(key)
+	if errors.Is(err, types.ErrKeyNotFound) {
+		err = ErrKeyNotFound
+	}
+	return b, err
 }
 
 func (r *reverseAirGapCache) Set(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
@@ -104,10 +108,16 @@ func (r *reverseAirGapCache) Set(ctx context.Context, key string, value []byte,
 }
 
 func (r *reverseAirGapCache) Add(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
+	var err error
 	if cttl, ok := r.c.(types.CacheWithTTL); ok {
-		return cttl.AddWithTTL(key, value, ttl)
+		err = cttl.AddWithTTL(key, value, ttl)
+	} else {
+		err = r.c.Add(key, value)
 	}
-	return r.c.Add(key, value)
+	if errors.Is(err, types.ErrKeyAlreadyExists) {
+		err = ErrKeyAlreadyExists
+	}
+	return err
 }
 
 func (r *reverseAirGapCache) Delete(ctx context.Context, key string) error {
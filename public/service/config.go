@@ -65,6 +65,15 @@ func NewObjectField(name string, fields ...*ConfigField) *ConfigField {
 	}
 }
 
+// NewObjectListField describes a new list type config field consisting of
+// objects with one or more child fields.
+func NewObjectListField(name string, fields ...*ConfigField) *ConfigField {
+	objField := NewObjectField(name, fields...)
+	return &ConfigField{
+		field: objField.field.Array(),
+	}
+}
+
 // Description adds a description to the field which will be shown when printing
 // documentation for the component config spec.
 func (c *ConfigField) Description(d string) *ConfigField {
@@ -392,6 +401,37 @@ func (p *ParsedConfig) FieldStringList(path ...string) ([]string, error) {
 	return sList, nil
 }
 
+// FieldObjectList accesses a field that is a list of objects from the parsed
+// config by its name and returns the value as an array of *ParsedConfig types,
+// where each one represents an object in the list. Returns an error if the
+// field is not found, or is not a list of objects.
+//
+// This method is not valid when the configuration spec was built around a
+// config constructor.
+func (p *ParsedConfig) FieldObjectList(path ...string) ([]*ParsedConfig, error) {
+	v, exists := p.field(path...)
+	if !exists {
+		return nil, fmt.Errorf("field '%v' was not found in the config", p.fullDotPath(path...))
+	}
+	iList, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected field '%v' to be a list, got %T", p.fullDotPath(path...), v)
+	}
+	sList := make([]*ParsedConfig, len(iList))
+	for i, ev := range iList {
+		obj, ok := ev.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected field '%v' to be an object list, found an element of type %T", p.fullDotPath(path...), ev)
+		}
+		sList[i] = &ParsedConfig{
+			env:     p.env,
+			mgr:     p.mgr,
+			generic: obj,
+		}
+	}
+	return sList, nil
+}
+
 // FieldInt accesses an int field from the parsed config by its name and returns
 // the value. Returns an error if the field is not found or is not an int.
 //
@@ -310,6 +310,51 @@ c:
 	assert.Equal(t, 23.1, f)
 }
 
+func TestConfigObjectListField(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewObjectListField("a",
+			NewStringField("b"),
+			NewIntField("c").Default(10),
+		))
+
+	node, err := getYAMLNode([]byte(`
+a:
+  - b: first
+  - b: second
+    c: 20
+`))
+	require.NoError(t, err)
+
+	parsedConfig, err := spec.configFromNode(NewEnvironment(), nil, node)
+	require.NoError(t, err)
+
+	objs, err := parsedConfig.FieldObjectList("a")
+	require.NoError(t, err)
+	require.Len(t, objs, 2)
+
+	s, err := objs[0].FieldString("b")
+	assert.NoError(t, err)
+	assert.Equal(t, "first", s)
+
+	i, err := objs[0].FieldInt("c")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, i)
+
+	s, err = objs[1].FieldString("b")
+	assert.NoError(t, err)
+	assert.Equal(t, "second", s)
+
+	i, err = objs[1].FieldInt("c")
+	assert.NoError(t, err)
+	assert.Equal(t, 20, i)
+
+	_, err = objs[1].FieldString("d")
+	assert.Error(t, err)
+
+	_, err = parsedConfig.FieldObjectList("z")
+	assert.Error(t, err)
+}
+
 func TestConfigBatching(t *testing.T) {
 	spec := NewConfigSpec().
 		Field(NewBatchPolicyField("a"))
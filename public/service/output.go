@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/Jeffail/benthos/v3/internal/shutdown"
+	"github.com/Jeffail/benthos/v3/lib/message"
 	"github.com/Jeffail/benthos/v3/lib/output"
 	"github.com/Jeffail/benthos/v3/lib/types"
 )
@@ -153,3 +154,40 @@ func (a *airGapBatchWriter) WaitForClose(tout time.Duration) error {
 	}
 	return nil
 }
+
+//------------------------------------------------------------------------------
+
+// ResourceOutput provides access to an output resource.
+type ResourceOutput struct {
+	o types.OutputWriter
+}
+
+func newResourceOutput(o types.OutputWriter) *ResourceOutput {
+	return &ResourceOutput{o: o}
+}
+
+// Write a message to the output, and block until it has either been delivered
+// or delivery has failed, or the context is cancelled.
+func (o *ResourceOutput) Write(ctx context.Context, m *Message) error {
+	return o.WriteBatch(ctx, MessageBatch{m})
+}
+
+// WriteBatch writes a batch of messages to the output, and blocks until it has
+// either been delivered or delivery has failed, or the context is cancelled.
+func (o *ResourceOutput) WriteBatch(ctx context.Context, b MessageBatch) error {
+	tMsg := message.New(nil)
+	for _, m := range b {
+		tMsg.Append(m.part)
+	}
+
+	resChan := make(chan types.Response, 1)
+	if err := o.o.WriteTransaction(ctx, types.NewTransaction(tMsg, resChan)); err != nil {
+		return err
+	}
+	select {
+	case res := <-resChan:
+		return res.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"time"
 
 	"github.com/Jeffail/benthos/v3/lib/message"
+	"github.com/Jeffail/benthos/v3/lib/response"
 	"github.com/Jeffail/benthos/v3/lib/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type fnOutput struct {
@@ -176,3 +178,46 @@ func TestBatchOutputAirGapHappy(t *testing.T) {
 
 	assert.Equal(t, "hello world", wroteMsg)
 }
+
+type fnOutputWriter struct {
+	write func(msg types.Message) error
+}
+
+func (f *fnOutputWriter) WriteTransaction(ctx context.Context, ts types.Transaction) error {
+	ts.ResponseChan <- response.NewError(f.write(ts.Payload))
+	return nil
+}
+
+func (f *fnOutputWriter) Connected() bool {
+	return true
+}
+
+func (f *fnOutputWriter) CloseAsync() {}
+
+func (f *fnOutputWriter) WaitForClose(time.Duration) error {
+	return nil
+}
+
+func TestResourceOutput(t *testing.T) {
+	var written [][]byte
+	o := newResourceOutput(&fnOutputWriter{
+		write: func(msg types.Message) error {
+			written = append(written, message.GetAllBytes(msg)...)
+			return nil
+		},
+	})
+
+	require.NoError(t, o.Write(context.Background(), NewMessage([]byte("foo"))))
+	require.NoError(t, o.WriteBatch(context.Background(), MessageBatch{
+		NewMessage([]byte("bar")),
+		NewMessage([]byte("baz")),
+	}))
+	assert.Equal(t, [][]byte{[]byte("foo"), []byte("bar"), []byte("baz")}, written)
+
+	o = newResourceOutput(&fnOutputWriter{
+		write: func(msg types.Message) error {
+			return errors.New("nope")
+		},
+	})
+	assert.EqualError(t, o.Write(context.Background(), NewMessage([]byte("foo"))), "nope")
+}
@@ -42,6 +42,19 @@ func (r *Resources) AccessCache(ctx context.Context, name string, fn func(c Cach
 	})
 }
 
+// HasOutput confirms whether an output resource with a given name exists.
+func (r *Resources) HasOutput(name string) bool {
+	return r.mgr.AccessOutput(context.Background(), name, func(types.OutputWriter) {}) == nil
+}
+
+// AccessOutput attempts to access an output resource by name. This action can
+// block if CRUD operations are being actively performed on the resource.
+func (r *Resources) AccessOutput(ctx context.Context, name string, fn func(o *ResourceOutput)) error {
+	return r.mgr.AccessOutput(ctx, name, func(o types.OutputWriter) {
+		fn(newResourceOutput(o))
+	})
+}
+
 // AccessRateLimit attempts to access a rate limit resource by name. This action
 // can block if CRUD operations are being actively performed on the resource.
 func (r *Resources) AccessRateLimit(ctx context.Context, name string, fn func(r RateLimit)) error {
@@ -302,6 +302,13 @@ func TestStreamBuilderSetYAMLBrokers(t *testing.T) {
         copies: 1
         pattern: fan_out
         max_in_flight: 1
+        mirror_percentage: 100
+        shadow_queue_size: 100
+        buffer_size: 100
+        failover_dedupe:
+            cache: ""
+            key: ""
+            window: 5m
         outputs:`,
 		`            - label: ""
               nats:`,
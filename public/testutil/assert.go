@@ -0,0 +1,95 @@
+package testutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+func contentOf(t testing.TB, msg *service.Message) (string, bool) {
+	t.Helper()
+	b, err := msg.AsBytes()
+	if err != nil {
+		t.Errorf("Failed to read message contents: %v", err)
+		return "", false
+	}
+	return string(b), true
+}
+
+// AssertContents checks that the contents of a list of messages match the
+// expected contents in order, and returns false if they do not.
+func AssertContents(t testing.TB, msgs []*service.Message, expected ...string) bool {
+	t.Helper()
+	actual := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		c, ok := contentOf(t, msg)
+		if !ok {
+			return false
+		}
+		actual = append(actual, c)
+	}
+	equal := len(actual) == len(expected)
+	for i := 0; equal && i < len(actual); i++ {
+		equal = actual[i] == expected[i]
+	}
+	if !equal {
+		t.Errorf("Wrong message contents:\nexpected: %q\nactual:   %q", expected, actual)
+		return false
+	}
+	return true
+}
+
+// AssertJSON checks that the contents of a message are a JSON document equal
+// to an expected JSON document, ignoring formatting and the order of object
+// keys, and returns false if they are not.
+func AssertJSON(t testing.TB, msg *service.Message, expected string) bool {
+	t.Helper()
+	var expectedV interface{}
+	if err := json.Unmarshal([]byte(expected), &expectedV); err != nil {
+		t.Errorf("Failed to parse expected JSON: %v", err)
+		return false
+	}
+	c, ok := contentOf(t, msg)
+	if !ok {
+		return false
+	}
+	var actualV interface{}
+	if err := json.Unmarshal([]byte(c), &actualV); err != nil {
+		t.Errorf("Failed to parse message contents as JSON: %v: %s", err, c)
+		return false
+	}
+	if !reflect.DeepEqual(expectedV, actualV) {
+		t.Errorf("Wrong message contents:\nexpected: %s\nactual:   %s", expected, c)
+		return false
+	}
+	return true
+}
+
+// AssertMetadata checks that a message has a metadata key with an expected
+// value, and returns false if it does not.
+func AssertMetadata(t testing.TB, msg *service.Message, key, expected string) bool {
+	t.Helper()
+	actual, exists := msg.MetaGet(key)
+	if !exists {
+		t.Errorf("Metadata key %q does not exist", key)
+		return false
+	}
+	if actual != expected {
+		t.Errorf("Wrong value of metadata key %q:\nexpected: %q\nactual:   %q", key, expected, actual)
+		return false
+	}
+	return true
+}
+
+// AssertNoError checks that a message has not been flagged as failed by a
+// processor, and returns false if it has.
+func AssertNoError(t testing.TB, msg *service.Message) bool {
+	t.Helper()
+	if err := msg.GetError(); err != nil {
+		t.Errorf("Message has failed: %v", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package testutil
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+// Collector stores the messages it receives, and can be made to reject
+// messages in order to test how a pipeline handles delivery errors. The Handle
+// method can be used anywhere a service.MessageHandlerFunc is accepted.
+type Collector struct {
+	mut      sync.Mutex
+	msgs     []*service.Message
+	failures []error
+	changed  chan struct{}
+}
+
+// NewCollector creates an empty Collector.
+func NewCollector() *Collector {
+	return &Collector{
+		changed: make(chan struct{}),
+	}
+}
+
+// Handle stores a message, or rejects it with the next error provided to
+// FailNext.
+func (c *Collector) Handle(ctx context.Context, msg *service.Message) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if len(c.failures) > 0 {
+		err := c.failures[0]
+		c.failures = c.failures[1:]
+		return err
+	}
+
+	c.msgs = append(c.msgs, msg)
+	close(c.changed)
+	c.changed = make(chan struct{})
+	return nil
+}
+
+// FailNext causes the next messages handled to be rejected with the provided
+// errors, one message per error.
+func (c *Collector) FailNext(errs ...error) {
+	c.mut.Lock()
+	c.failures = append(c.failures, errs...)
+	c.mut.Unlock()
+}
+
+// Messages returns the messages stored so far.
+func (c *Collector) Messages() []*service.Message {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return append([]*service.Message{}, c.msgs...)
+}
+
+// Reset removes all stored messages and pending failures.
+func (c *Collector) Reset() {
+	c.mut.Lock()
+	c.msgs = nil
+	c.failures = nil
+	c.mut.Unlock()
+}
+
+// WaitForN blocks until at least n messages have been stored and returns them,
+// or returns an error along with the messages stored so far if the context is
+// cancelled first.
+func (c *Collector) WaitForN(ctx context.Context, n int) ([]*service.Message, error) {
+	for {
+		c.mut.Lock()
+		msgs, changed := append([]*service.Message{}, c.msgs...), c.changed
+		c.mut.Unlock()
+
+		if len(msgs) >= n {
+			return msgs, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return msgs, ctx.Err()
+		}
+	}
+}
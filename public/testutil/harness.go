@@ -0,0 +1,187 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+// testLogger writes the logs of a stream to a test, and discards logs once the
+// test has completed as writing to it would panic.
+type testLogger struct {
+	t    testing.TB
+	mut  sync.Mutex
+	done bool
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	if !l.done {
+		l.t.Logf(format, v...)
+	}
+}
+
+func (l *testLogger) Println(v ...interface{}) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	if !l.done {
+		l.t.Log(v...)
+	}
+}
+
+func (l *testLogger) finish() {
+	l.mut.Lock()
+	l.done = true
+	l.mut.Unlock()
+}
+
+//------------------------------------------------------------------------------
+
+// Harness runs a stream where messages are sent directly to its input and are
+// collected from its output in memory. The processors, buffer and resources of
+// the stream are added to the stream builder of the harness before it is
+// started, and any inputs or outputs added are composed with the in-memory
+// ones within brokers.
+//
+// The stream does not run an HTTP server, instead its endpoints are registered
+// with a multiplexer that can be accessed with Mux.
+type Harness struct {
+	t       testing.TB
+	builder *service.StreamBuilder
+	produce service.MessageHandlerFunc
+	output  *Collector
+	mux     *http.ServeMux
+	logger  *testLogger
+
+	stream  *service.Stream
+	runErr  chan error
+	stopped bool
+}
+
+// NewHarness creates a harness with a stream builder from an environment,
+// where a nil environment uses the global environment containing all plugins.
+// The stream is stopped when the test completes.
+func NewHarness(t testing.TB, env *service.Environment) *Harness {
+	t.Helper()
+
+	h := &Harness{
+		t:      t,
+		output: NewCollector(),
+		mux:    http.NewServeMux(),
+		logger: &testLogger{t: t},
+	}
+	if env != nil {
+		h.builder = env.NewStreamBuilder()
+	} else {
+		h.builder = service.NewStreamBuilder()
+	}
+	h.builder.SetHTTPMux(h.mux)
+	h.builder.SetPrintLogger(h.logger)
+
+	var err error
+	if h.produce, err = h.builder.AddProducerFunc(); err != nil {
+		t.Fatalf("Failed to add producer: %v", err)
+	}
+	if err = h.builder.AddConsumerFunc(h.output.Handle); err != nil {
+		t.Fatalf("Failed to add consumer: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := h.Stop(); err != nil {
+			t.Errorf("Failed to stop stream: %v", err)
+		}
+		h.logger.finish()
+	})
+	return h
+}
+
+// Builder returns the stream builder of the harness, which can be used to add
+// components to the stream before it is started.
+func (h *Harness) Builder() *service.StreamBuilder {
+	return h.builder
+}
+
+// Output returns the collector of the messages that reach the output of the
+// stream, which can also be used to reject messages.
+func (h *Harness) Output() *Collector {
+	return h.output
+}
+
+// Mux returns the multiplexer that the HTTP endpoints of the stream are
+// registered with.
+func (h *Harness) Mux() *http.ServeMux {
+	return h.mux
+}
+
+// Start builds the stream and runs it in the background, failing the test if
+// the stream cannot be built.
+func (h *Harness) Start() {
+	h.t.Helper()
+	if h.stream != nil {
+		h.t.Fatal("Harness has already been started")
+	}
+
+	var err error
+	if h.stream, err = h.builder.Build(); err != nil {
+		h.t.Fatalf("Failed to build stream: %v", err)
+	}
+
+	h.runErr = make(chan error, 1)
+	go func() {
+		h.runErr <- h.stream.Run(context.Background())
+	}()
+}
+
+// Send writes messages to the input of the stream in order, blocking until
+// each has been delivered to the output or rejected. The first error
+// encountered is returned.
+func (h *Harness) Send(ctx context.Context, msgs ...*service.Message) error {
+	if h.stream == nil {
+		return errors.New("harness has not been started")
+	}
+	for i, msg := range msgs {
+		if err := h.produce(ctx, msg); err != nil {
+			return fmt.Errorf("message %v: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// SendContents writes a message for each of the provided contents to the input
+// of the stream, and fails the test if any are not delivered within a timeout.
+func (h *Harness) SendContents(timeout time.Duration, contents ...string) {
+	h.t.Helper()
+	ctx, done := context.WithTimeout(context.Background(), timeout)
+	defer done()
+	if err := h.Send(ctx, Messages(contents...)...); err != nil {
+		h.t.Fatalf("Failed to send messages: %v", err)
+	}
+}
+
+// Stop stops the stream, waiting for messages in flight to be delivered. This
+// is called automatically when the test completes.
+func (h *Harness) Stop() error {
+	if h.stream == nil || h.stopped {
+		return nil
+	}
+	h.stopped = true
+	if err := h.stream.StopWithin(time.Second * 5); err != nil {
+		return err
+	}
+	select {
+	case err := <-h.runErr:
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+	case <-time.After(time.Second * 5):
+		return errors.New("timed out waiting for stream to stop")
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package testutil
+
+import (
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+// Messages creates a message for each of the provided contents.
+func Messages(contents ...string) []*service.Message {
+	msgs := make([]*service.Message, len(contents))
+	for i, c := range contents {
+		msgs[i] = service.NewMessage([]byte(c))
+	}
+	return msgs
+}
+
+// StructuredMessages creates a message for each of the provided values, which
+// are set as the structured contents of the messages.
+func StructuredMessages(values ...interface{}) []*service.Message {
+	msgs := make([]*service.Message, len(values))
+	for i, v := range values {
+		msgs[i] = service.NewMessage(nil)
+		msgs[i].SetStructured(v)
+	}
+	return msgs
+}
+
+// GenerateMessages creates n messages with a closure that is called with the
+// index of each message.
+func GenerateMessages(n int, fn func(i int) *service.Message) []*service.Message {
+	msgs := make([]*service.Message, n)
+	for i := range msgs {
+		msgs[i] = fn(i)
+	}
+	return msgs
+}
+
+// WithMetadata returns the messages after setting metadata key/value pairs on
+// each, where the pairs are provided as alternating keys and values.
+func WithMetadata(msgs []*service.Message, keyValues ...string) []*service.Message {
+	for _, msg := range msgs {
+		for i := 0; i+1 < len(keyValues); i += 2 {
+			msg.MetaSet(keyValues[i], keyValues[i+1])
+		}
+	}
+	return msgs
+}
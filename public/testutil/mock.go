@@ -0,0 +1,207 @@
+package testutil
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+)
+
+// MockInput is a service.Input that emits a list of messages and records
+// whether each was acknowledged. Messages that are rejected are emitted again,
+// as with a source that supports at-least-once delivery. Once all messages
+// have been acknowledged reads return service.ErrEndOfInput.
+type MockInput struct {
+	mut        sync.Mutex
+	pending    []*service.Message
+	inFlight   int
+	acked      []*service.Message
+	nacks      []error
+	connectErr error
+	connected  bool
+	closed     bool
+	changed    chan struct{}
+}
+
+// NewMockInput creates a MockInput that emits the provided messages in order.
+func NewMockInput(msgs ...*service.Message) *MockInput {
+	return &MockInput{
+		pending: msgs,
+		changed: make(chan struct{}),
+	}
+}
+
+// Add appends messages to be emitted by the input.
+func (m *MockInput) Add(msgs ...*service.Message) {
+	m.mut.Lock()
+	m.pending = append(m.pending, msgs...)
+	m.notify()
+	m.mut.Unlock()
+}
+
+// FailConnect causes subsequent calls to Connect to return an error, a nil
+// error allows connections again.
+func (m *MockInput) FailConnect(err error) {
+	m.mut.Lock()
+	m.connectErr = err
+	m.mut.Unlock()
+}
+
+func (m *MockInput) notify() {
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+// Connect marks the input as connected, or returns the error provided to
+// FailConnect.
+func (m *MockInput) Connect(ctx context.Context) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if m.connectErr != nil {
+		return m.connectErr
+	}
+	m.connected = true
+	return nil
+}
+
+// Read emits the next message. While messages remain in flight and none are
+// pending the read blocks, as a rejected message may need to be emitted again.
+func (m *MockInput) Read(ctx context.Context) (*service.Message, service.AckFunc, error) {
+	for {
+		m.mut.Lock()
+		if !m.connected {
+			m.mut.Unlock()
+			return nil, nil, service.ErrNotConnected
+		}
+		if len(m.pending) > 0 {
+			msg := m.pending[0]
+			m.pending = m.pending[1:]
+			m.inFlight++
+			m.mut.Unlock()
+			return msg.Copy(), func(ctx context.Context, err error) error {
+				m.mut.Lock()
+				defer m.mut.Unlock()
+				m.inFlight--
+				if err != nil {
+					m.nacks = append(m.nacks, err)
+					m.pending = append([]*service.Message{msg}, m.pending...)
+				} else {
+					m.acked = append(m.acked, msg)
+				}
+				m.notify()
+				return nil
+			}, nil
+		}
+		if m.inFlight == 0 {
+			m.mut.Unlock()
+			return nil, nil, service.ErrEndOfInput
+		}
+		changed := m.changed
+		m.mut.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+// Close marks the input as closed.
+func (m *MockInput) Close(ctx context.Context) error {
+	m.mut.Lock()
+	m.connected = false
+	m.closed = true
+	m.mut.Unlock()
+	return nil
+}
+
+// Acked returns the messages that have been acknowledged, in the order that
+// they were acknowledged.
+func (m *MockInput) Acked() []*service.Message {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return append([]*service.Message{}, m.acked...)
+}
+
+// Nacks returns the errors that messages were rejected with.
+func (m *MockInput) Nacks() []error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return append([]error{}, m.nacks...)
+}
+
+// Closed returns true if the input has been closed.
+func (m *MockInput) Closed() bool {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return m.closed
+}
+
+//------------------------------------------------------------------------------
+
+// MockOutput is a service.Output that stores the messages written to it with
+// an embedded Collector.
+type MockOutput struct {
+	*Collector
+
+	mut        sync.Mutex
+	connectErr error
+	connected  bool
+	closed     bool
+}
+
+// NewMockOutput creates an empty MockOutput.
+func NewMockOutput() *MockOutput {
+	return &MockOutput{
+		Collector: NewCollector(),
+	}
+}
+
+// FailConnect causes subsequent calls to Connect to return an error, a nil
+// error allows connections again.
+func (m *MockOutput) FailConnect(err error) {
+	m.mut.Lock()
+	m.connectErr = err
+	m.mut.Unlock()
+}
+
+// Connect marks the output as connected, or returns the error provided to
+// FailConnect.
+func (m *MockOutput) Connect(ctx context.Context) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if m.connectErr != nil {
+		return m.connectErr
+	}
+	m.connected = true
+	return nil
+}
+
+// Write stores a message, or rejects it with the next error provided to
+// FailNext.
+func (m *MockOutput) Write(ctx context.Context, msg *service.Message) error {
+	m.mut.Lock()
+	connected := m.connected
+	m.mut.Unlock()
+	if !connected {
+		return service.ErrNotConnected
+	}
+	return m.Handle(ctx, msg)
+}
+
+// Close marks the output as closed.
+func (m *MockOutput) Close(ctx context.Context) error {
+	m.mut.Lock()
+	m.connected = false
+	m.closed = true
+	m.mut.Unlock()
+	return nil
+}
+
+// Closed returns true if the output has been closed.
+func (m *MockOutput) Closed() bool {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return m.closed
+}
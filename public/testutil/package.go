@@ -0,0 +1,4 @@
+// Package testutil provides in-memory inputs and outputs, message generators
+// and assertion helpers for testing plugins and pipelines built with the
+// ./public/service package, without depending on external services.
+package testutil
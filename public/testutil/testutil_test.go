@@ -0,0 +1,106 @@
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/benthos/v3/public/service"
+	"github.com/Jeffail/benthos/v3/public/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/Jeffail/benthos/v3/public/components/all"
+)
+
+func TestHarnessProcessors(t *testing.T) {
+	h := testutil.NewHarness(t, nil)
+	require.NoError(t, h.Builder().AddProcessorYAML(`bloblang: 'root = content().uppercase()'`))
+	h.Start()
+
+	h.SendContents(time.Second*5, "foo", "bar")
+	testutil.AssertContents(t, h.Output().Messages(), "FOO", "BAR")
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	msgs := testutil.StructuredMessages(map[string]interface{}{"a": "b"})
+	testutil.WithMetadata(msgs, "foo", "bar")
+	require.NoError(t, h.Send(ctx, msgs...))
+
+	out, err := h.Output().WaitForN(ctx, 3)
+	require.NoError(t, err)
+	testutil.AssertJSON(t, out[2], `{ "A": "B" }`)
+	testutil.AssertMetadata(t, out[2], "foo", "bar")
+	testutil.AssertNoError(t, out[2])
+
+	rec := httptest.NewRecorder()
+	h.Mux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestHarnessRejected(t *testing.T) {
+	h := testutil.NewHarness(t, nil)
+	h.Start()
+
+	h.Output().FailNext(errors.New("nope"))
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	require.Error(t, h.Send(ctx, testutil.Messages("foo")...))
+	require.NoError(t, h.Send(ctx, testutil.Messages("bar")...))
+	testutil.AssertContents(t, h.Output().Messages(), "bar")
+}
+
+func TestMockInputOutput(t *testing.T) {
+	in := testutil.NewMockInput(testutil.GenerateMessages(3, func(i int) *service.Message {
+		return service.NewMessage([]byte{byte('a' + i)})
+	})...)
+	out := testutil.NewMockOutput()
+	out.FailNext(errors.New("nope"))
+
+	env := service.NewEnvironment()
+	require.NoError(t, env.RegisterInput("mock", service.NewConfigSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Input, error) {
+			return in, nil
+		}))
+	require.NoError(t, env.RegisterOutput("mock", service.NewConfigSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Output, int, error) {
+			return out, 1, nil
+		}))
+
+	builder := env.NewStreamBuilder()
+	require.NoError(t, builder.SetYAML(`
+input:
+  mock: {}
+output:
+  mock: {}
+logger:
+  level: none
+`))
+	strm, err := builder.Build()
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+	require.NoError(t, strm.Run(ctx))
+
+	// The rejected message is emitted again after the others.
+	contents := func(msgs []*service.Message) (c []string) {
+		for _, m := range msgs {
+			b, err := m.AsBytes()
+			require.NoError(t, err)
+			c = append(c, string(b))
+		}
+		return
+	}
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, contents(out.Messages()))
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, contents(in.Acked()))
+	assert.Len(t, in.Nacks(), 1)
+	assert.True(t, in.Closed())
+	assert.True(t, out.Closed())
+}
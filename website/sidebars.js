@@ -103,6 +103,7 @@ module.exports = {
         'guides/sync_responses',
         'guides/aws',
         'guides/gcp',
+        'guides/azure',
         {
           type: 'category',
           label: 'Serverless',